@@ -23,21 +23,27 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
+	"time"
 
-	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
-	"gorm.io/gorm"
 
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
 	appsvc "github.com/telemetryflow/telemetryflow-go-mcp/internal/application/services"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
-	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/claude"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/bootstrap"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
-	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/llm"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/upstream"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/resources"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/server"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/tools"
+	"github.com/telemetryflow/telemetryflow-go-mcp/pkg/telemetry"
 )
 
 var (
@@ -51,6 +57,9 @@ var (
 	debug      bool
 )
 
+// localModelRefreshInterval is how often local providers are asked for their installed models
+const localModelRefreshInterval = time.Minute
+
 func main() {
 	rootCmd := &cobra.Command{
 		Use:     "tfo-mcp",
@@ -94,25 +103,43 @@ func runServer(cmd *cobra.Command, args []string) error {
 		Str("transport", cfg.Server.Transport).
 		Msg("Starting TelemetryFlow GO MCP Server")
 
-	// Create Claude client
-	claudeClient, err := claude.NewClient(&cfg.Claude, logger)
+	// Create LLM provider backends
+	llmRegistry, err := bootstrap.NewLLMRegistry(cfg, logger)
 	if err != nil {
-		return fmt.Errorf("failed to create Claude client: %w", err)
+		return fmt.Errorf("failed to create LLM client: %w", err)
 	}
 
+	// Expand model aliases to fallback chains and take failing models out of rotation
+	llmMetrics, err := telemetry.NewMetrics(cfg.Telemetry.ServiceName)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to create LLM metrics")
+	}
+	llmRouter := llm.NewRouter(llmRegistry, cfg.Routing, llmMetrics, logger)
+
 	// Create repositories
-	sessionRepo, conversationRepo, toolRepo, cleanup, err := initRepositories(cfg, logger)
+	repos, err := bootstrap.NewRepositories(cfg, logger)
 	if err != nil {
 		return fmt.Errorf("failed to init repositories: %w", err)
 	}
-	if cleanup != nil {
-		defer cleanup()
+	defer repos.Close()
+	sessionRepo, toolRepo := repos.Sessions, repos.Tools
+
+	// Enforce per-organization LLM budgets on every LLM call
+	var llmService services.IClaudeService = llmRouter
+	var budgetHandler *handlers.BudgetHandler
+	if cfg.Budgets.Enabled {
+		guard, budgetCleanup := bootstrap.NewBudgetGuard(cfg, llmRouter, repos.Budgets, logger)
+		defer budgetCleanup()
+		llmService = guard
+		budgetHandler = handlers.NewBudgetHandler(repos.Budgets, guard)
 	}
 
 	// Create context collector for telemetry data access
 	var contextCollector *appsvc.ContextCollector
 	if cfg.Database.Enabled || cfg.Clickhouse.Enabled {
-		contextCollector = initContextCollector(cfg, logger)
+		stores := bootstrap.ConnectTelemetryStores(cfg, logger)
+		provider := appsvc.NewDefaultDBProvider(stores.DB, stores.ClickHouse, stores.ClickHouseDatabase)
+		contextCollector = appsvc.NewContextCollector(provider, logger)
 	}
 
 	// Create event publisher (simple implementation)
@@ -120,17 +147,133 @@ func runServer(cmd *cobra.Command, args []string) error {
 
 	// Create handlers
 	sessionHandler := handlers.NewSessionHandler(sessionRepo, eventPublisher)
+	sessionHandler.SetAPIKeys(cfg.Security.AllowedAPIKeys, cfg.Security.RequireAPIKey)
 	toolHandler := handlers.NewToolHandler(sessionRepo, toolRepo, eventPublisher)
-	conversationHandler := handlers.NewConversationHandler(sessionRepo, conversationRepo, claudeClient, eventPublisher)
+	conversationHandler := handlers.NewConversationHandler(sessionRepo, repos.Conversations, llmService, eventPublisher)
+	conversationHandler.SetLimits(handlers.ConversationLimits{
+		MaxConversations: cfg.MCP.MaxConversations,
+		MaxMessages:      cfg.MCP.MaxMessagesPerConv,
+	})
+	conversationHandler.SetModelAliases(llmRouter.Aliases())
+
+	// Compact conversations that outgrow their model's context window
+	var compactor *appsvc.Compactor
+	if cfg.Compaction.Enabled {
+		compactor = appsvc.NewCompactor(llmService, appsvc.CompactionOptions{
+			Threshold:        cfg.Compaction.Threshold,
+			KeepMessages:     cfg.Compaction.KeepMessages,
+			SummaryModel:     vo.Model(cfg.Compaction.SummaryModel),
+			SummaryMaxTokens: cfg.Compaction.SummaryMaxTokens,
+			ModelChain:       llmRouter.Chain,
+		})
+		conversationHandler.SetCompactor(compactor)
+	}
+
+	// Record every tool call in the audit trail
+	var auditHandler *handlers.AuditHandler
+	if cfg.Audit.Enabled {
+		trail, auditCleanup := bootstrap.NewAuditTrail(cfg, repos.Executions, logger)
+		defer auditCleanup()
+		toolHandler.SetAuditor(trail, handlers.AuditOptions{
+			RedactArguments: cfg.Audit.RedactArguments,
+			MaxValueLength:  cfg.Audit.MaxValueLength,
+		})
+		auditHandler = handlers.NewAuditHandler(repos.Executions)
+	}
+
+	// Meter every LLM call: tokens, latency and cost per organization, session and model
+	var usageHandler *handlers.UsageHandler
+	if cfg.Usage.Enabled {
+		recorder, usageRepo, usageCleanup := bootstrap.NewUsageRecorder(cfg, logger)
+		defer usageCleanup()
+		llmRouter.SetUsageRecorder(recorder)
+		usageHandler = handlers.NewUsageHandler(usageRepo)
+	}
+
+	// Cache results of idempotent tools that have a cache policy
+	if len(cfg.ToolCache.Tools) > 0 {
+		resultCache, cacheCleanup := bootstrap.NewToolResultCache(cfg, logger)
+		defer cacheCleanup()
+		toolHandler.SetResultCache(resultCache)
+	}
+
+	// Let the LLM call the server's tools from conversations and analyze_telemetry
+	var agent *appsvc.AgentService
+	if cfg.Agent.Enabled {
+		agent = appsvc.NewAgentService(llmService, toolHandler, appsvc.AgentOptions{
+			MaxIterations:    cfg.Agent.MaxIterations,
+			TokenBudget:      cfg.Agent.TokenBudget,
+			MaxParallelTools: cfg.Agent.MaxParallelTools,
+			AllowedTools:     cfg.Agent.AllowedTools,
+		})
+		agent.SetCompactor(compactor)
+		conversationHandler.SetAgent(agent)
+	}
+
+	// Create background task handler
+	var taskHandler *handlers.TaskHandler
+	if cfg.Tasks.Enabled {
+		taskQueue := bootstrap.NewTaskQueue(cfg.Tasks)
+		taskHandler = handlers.NewTaskHandler(toolHandler, taskQueue.Dispatcher(), handlers.TaskOptions{
+			DefaultTTL:   cfg.Tasks.DefaultTTL,
+			MaxTTL:       cfg.Tasks.MaxTTL,
+			Timeout:      cfg.Tasks.Timeout,
+			PollInterval: cfg.Tasks.PollInterval,
+			MaxTasks:     cfg.Tasks.MaxTasks,
+		})
+		taskQueue.Start(taskHandler.RunTask, logger)
+		defer func() { _ = taskQueue.Close() }()
+	}
 
 	// Create and register built-in tools
 	var toolRegistry *tools.ToolRegistry
 	if contextCollector != nil {
-		toolRegistry = tools.NewToolRegistryWithCollector(claudeClient, contextCollector)
+		toolRegistry = tools.NewToolRegistryWithCollector(llmService, contextCollector)
 	} else {
-		toolRegistry = tools.NewToolRegistry(claudeClient)
+		toolRegistry = tools.NewToolRegistry(llmService)
+	}
+	toolRegistry.SetModelAliases(llmRouter.Aliases())
+	toolRegistry.SetResourceHandler(resources.NewResourceHandler(cfg.MCP.AllowedPaths, cfg.MCP.MaxFileSize))
+	toolRegistry.SetCommandPolicy(cfg.Security.Command)
+	if taskHandler != nil {
+		toolRegistry.SetTaskHandler(taskHandler)
+	}
+	if auditHandler != nil {
+		toolRegistry.SetAuditHandler(auditHandler)
+	}
+	if usageHandler != nil {
+		toolRegistry.SetUsageHandler(usageHandler)
+	}
+	if budgetHandler != nil {
+		toolRegistry.SetBudgetHandler(budgetHandler)
 	}
-	for _, tool := range toolRegistry.GetTools() {
+	if agent != nil {
+		agentModel := vo.Model(cfg.Agent.Model)
+		if agentModel == "" {
+			agentModel = vo.Model(cfg.Claude.DefaultModel)
+		}
+		toolRegistry.SetAgent(agent, agentModel, cfg.Agent.Timeout)
+	}
+	toolRegistry.SetConversationHandler(conversationHandler)
+
+	// Offer the installed local models in the claude_conversation model enum
+	hasLocalModels := slices.ContainsFunc(llmRegistry.Providers(), vo.Provider.IsLocal)
+	if hasLocalModels {
+		if _, _, err := toolRegistry.DiscoverLocalModels(context.Background(), llmRegistry); err != nil {
+			logger.Warn().Err(err).Msg("Failed to discover local models")
+		}
+	}
+	registeredTools := toolRegistry.GetTools()
+
+	// Connect upstream MCP servers and re-export their tools
+	var proxy *upstream.Proxy
+	if len(cfg.Upstreams.Servers) > 0 {
+		proxy = bootstrap.NewUpstreamProxy(cfg, logger)
+		defer func() { _ = proxy.Close() }()
+		registeredTools = append(registeredTools, proxy.Tools()...)
+	}
+
+	for _, tool := range registeredTools {
 		ctx := context.Background()
 		if err := toolRepo.Register(ctx, tool); err != nil {
 			logger.Warn().Err(err).Str("tool", tool.Name().String()).Msg("Failed to register tool")
@@ -139,8 +282,27 @@ func runServer(cmd *cobra.Command, args []string) error {
 		toolHandler.RegisterToolHandler(tool.Name().String(), tool.Handler())
 	}
 
+	// Register HTTP tools and pipelines from the config file; they are re-synced on SIGHUP
+	configTools := tools.NewConfigToolSet()
+	if err := configTools.Load(context.Background(), cfg, toolRepo); err != nil {
+		logger.Warn().Err(err).Msg("Failed to load some config tools")
+	}
+
 	// Create server
 	srv := server.NewServer(cfg, logger, sessionHandler, toolHandler, conversationHandler)
+	if taskHandler != nil {
+		srv.SetTaskHandler(taskHandler)
+	}
+	srv.SetLLMProviders(bootstrap.ProviderNames(llmRegistry))
+	if proxy != nil {
+		for _, resource := range proxy.Resources() {
+			srv.RegisterResource(resource)
+			toolRegistry.RegisterResource(resource)
+		}
+		for _, prompt := range proxy.Prompts() {
+			srv.RegisterPrompt(prompt)
+		}
+	}
 
 	// Setup graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -156,6 +318,14 @@ func runServer(cmd *cobra.Command, args []string) error {
 		srv.Stop()
 	}()
 
+	reloadChan := make(chan os.Signal, 1)
+	signal.Notify(reloadChan, syscall.SIGHUP)
+	defer signal.Stop(reloadChan)
+	go reloadConfigTools(ctx, reloadChan, configTools, toolRepo, srv, logger)
+	if hasLocalModels {
+		go refreshLocalModels(ctx, llmRegistry, toolRegistry, toolRepo, srv, logger)
+	}
+
 	// Run server
 	if err := srv.Run(ctx); err != nil {
 		if err == server.ErrServerClosed || err == context.Canceled {
@@ -168,72 +338,6 @@ func runServer(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func initRepositories(cfg *config.Config, logger zerolog.Logger) (
-	repositories.ISessionRepository,
-	repositories.IConversationRepository,
-	repositories.IToolRepository,
-	func(),
-	error,
-) {
-	if cfg.Database.Enabled {
-		logger.Info().Msg("PostgreSQL enabled, initializing GORM repositories")
-
-		dbCfg := &persistence.DatabaseConfig{
-			Host:         cfg.Database.Host,
-			Port:         cfg.Database.Port,
-			User:         cfg.Database.User,
-			Password:     cfg.Database.Password,
-			Database:     cfg.Database.Database,
-			SSLMode:      cfg.Database.SSLMode,
-			MaxIdleConns: cfg.Database.MaxIdleConns,
-			MaxOpenConns: cfg.Database.MaxOpenConns,
-		}
-		if cfg.Database.URL != "" {
-			dbCfg = nil
-		}
-
-		db, err := persistence.NewDatabase(dbCfg)
-		if err != nil {
-			return nil, nil, nil, nil, fmt.Errorf("failed to connect PostgreSQL: %w", err)
-		}
-
-		ctx := context.Background()
-		if err := db.Ping(ctx); err != nil {
-			_ = db.Close()
-			return nil, nil, nil, nil, fmt.Errorf("failed to ping PostgreSQL: %w", err)
-		}
-		logger.Info().Msg("PostgreSQL connection established")
-
-		gormDB := db.DB()
-		if cfg.Database.AutoMigrate {
-			if err := gormDB.AutoMigrate(persistence.AllModels()...); err != nil {
-				_ = db.Close()
-				return nil, nil, nil, nil, fmt.Errorf("failed to auto-migrate: %w", err)
-			}
-			logger.Info().Msg("PostgreSQL schema migration complete")
-		}
-
-		sessionRepo := persistence.NewGormSessionRepository(gormDB)
-		conversationRepo := persistence.NewGormConversationRepository(gormDB)
-		toolRepo := persistence.NewGormToolRepository(gormDB)
-
-		cleanup := func() {
-			logger.Info().Msg("Closing PostgreSQL connection")
-			_ = db.Close()
-		}
-
-		return sessionRepo, conversationRepo, toolRepo, cleanup, nil
-	}
-
-	logger.Info().Msg("Using in-memory repositories (PostgreSQL disabled)")
-
-	return persistence.NewInMemorySessionRepository(),
-		persistence.NewInMemoryConversationRepository(),
-		persistence.NewInMemoryToolRepository(),
-		nil,
-		nil
-}
-
 func setupLogger(cfg *config.Config) zerolog.Logger {
 	// Set log level
 	level, err := zerolog.ParseLevel(cfg.Logging.Level)
@@ -275,6 +379,9 @@ func versionCmd() *cobra.Command {
 			fmt.Printf("Version:    %s\n", version)
 			fmt.Printf("Commit:     %s\n", commit)
 			fmt.Printf("Build Date: %s\n", buildDate)
+			if cfg, err := config.Load(configFile); err == nil {
+				fmt.Printf("Providers:  %s\n", strings.Join(cfg.ActiveProviders(), ", "))
+			}
 		},
 	}
 }
@@ -288,10 +395,46 @@ func validateCmd() *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("configuration is invalid: %w", err)
 			}
+			registry, err := bootstrap.NewLLMRegistry(cfg, zerolog.Nop())
+			if err != nil {
+				return fmt.Errorf("configuration is invalid: %w", err)
+			}
 			fmt.Printf("Configuration is valid!\n")
 			fmt.Printf("Server:    %s:%d\n", cfg.Server.Host, cfg.Server.Port)
 			fmt.Printf("Transport: %s\n", cfg.Server.Transport)
-			fmt.Printf("Model:     %s\n", cfg.Claude.DefaultModel)
+			fmt.Printf("Providers:\n")
+			for _, name := range cfg.ActiveProviders() {
+				provider := vo.Provider(name)
+				if !slices.Contains(registry.Providers(), provider) {
+					fmt.Printf("  %-10s skipped (unknown provider)\n", name)
+					continue
+				}
+				if model, ok := registry.DefaultModel(provider); ok {
+					fmt.Printf("  %-10s default model %s\n", name, model)
+				} else {
+					fmt.Printf("  %s\n", name)
+				}
+			}
+
+			router := llm.NewRouter(registry, cfg.Routing, nil, zerolog.Nop())
+			if aliases := router.Aliases(); len(aliases) > 0 {
+				fmt.Printf("Aliases:\n")
+			}
+			for _, alias := range router.Aliases() {
+				chain := router.Chain(alias)
+				names := make([]string, 0, len(chain))
+				var warnings []error
+				for _, model := range chain {
+					names = append(names, model.String())
+					if _, err := registry.Backend(registry.ResolveModel(model)); err != nil {
+						warnings = append(warnings, err)
+					}
+				}
+				fmt.Printf("  %-16s %s\n", alias, strings.Join(names, " -> "))
+				for _, warning := range warnings {
+					fmt.Printf("    warning: %v\n", warning)
+				}
+			}
 			return nil
 		},
 	}
@@ -307,59 +450,55 @@ func (p *simpleEventPublisher) Publish(ctx context.Context, event interface{}) e
 	return nil
 }
 
-func initContextCollector(cfg *config.Config, logger zerolog.Logger) *appsvc.ContextCollector {
-	var gormDB interface{ DB() *gorm.DB }
-	var chConn driver.Conn
-	var chDB string
-
-	if cfg.Database.Enabled {
-		dbCfg := &persistence.DatabaseConfig{
-			Host:     cfg.Database.Host,
-			Port:     cfg.Database.Port,
-			User:     cfg.Database.User,
-			Password: cfg.Database.Password,
-			Database: cfg.Database.Database,
-			SSLMode:  cfg.Database.SSLMode,
+// reloadConfigTools re-reads the config file on each signal and reloads the HTTP tools and pipelines
+func reloadConfigTools(ctx context.Context, signals <-chan os.Signal, set *tools.ConfigToolSet, toolRepo repositories.IToolRepository, srv *server.Server, logger zerolog.Logger) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
 		}
-		if cfg.Database.URL != "" {
-			dbCfg = nil
-		}
-		db, err := persistence.NewDatabase(dbCfg)
+
+		cfg, err := config.Load(configFile)
 		if err != nil {
-			logger.Warn().Err(err).Msg("Failed to connect PostgreSQL for context collector")
-		} else {
-			gormDB = db
-			logger.Info().Msg("Context collector: PostgreSQL connected")
+			logger.Error().Err(err).Msg("Config reload failed; keeping current config tools")
+			continue
+		}
+		if err := set.Load(ctx, cfg, toolRepo); err != nil {
+			logger.Warn().Err(err).Msg("Failed to load some config tools")
+		}
+		logger.Info().Strs("tools", set.Names()).Msg("Config tools reloaded")
+
+		if err := srv.NotifyToolsChanged(); err != nil {
+			logger.Debug().Err(err).Msg("Failed to send tools/list_changed")
 		}
 	}
+}
+
+// refreshLocalModels re-discovers the local models periodically and re-registers claude_conversation when they change
+func refreshLocalModels(ctx context.Context, registry *llm.Registry, toolRegistry *tools.ToolRegistry, toolRepo repositories.IToolRepository, srv *server.Server, logger zerolog.Logger) {
+	ticker := time.NewTicker(localModelRefreshInterval)
+	defer ticker.Stop()
 
-	if cfg.Clickhouse.Enabled {
-		chCfg := &persistence.ClickHouseConfig{
-			Host:     cfg.Clickhouse.Host,
-			Port:     cfg.Clickhouse.Port,
-			Database: cfg.Clickhouse.Database,
-			Username: cfg.Clickhouse.Username,
-			Password: cfg.Clickhouse.Password,
-			Secure:   cfg.Clickhouse.Secure,
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
-		ch, err := persistence.NewClickHouse(chCfg)
+
+		changed, err := toolRegistry.RefreshLocalModels(ctx, registry, toolRepo)
 		if err != nil {
-			logger.Warn().Err(err).Msg("Failed to connect ClickHouse for context collector")
-		} else {
-			chConn = ch.Conn()
-			chDB = cfg.Clickhouse.Database
-			if chDB == "" {
-				chDB = "telemetryflow_analytics"
-			}
-			logger.Info().Msg("Context collector: ClickHouse connected")
+			logger.Debug().Err(err).Msg("Failed to refresh local models")
+			continue
 		}
-	}
+		if !changed {
+			continue
+		}
+		logger.Info().Msg("Local models changed")
 
-	var db *gorm.DB
-	if gormDB != nil {
-		db = gormDB.DB()
+		if err := srv.NotifyToolsChanged(); err != nil {
+			logger.Debug().Err(err).Msg("Failed to send tools/list_changed")
+		}
 	}
-
-	provider := appsvc.NewDefaultDBProvider(db, chConn, chDB)
-	return appsvc.NewContextCollector(provider, logger)
 }
@@ -19,7 +19,10 @@
 package commands
 
 import (
+	"time"
+
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
 	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
 )
 
@@ -36,6 +39,7 @@ type InitializeSessionCommand struct {
 	ClientVersion   string
 	ProtocolVersion string
 	Capabilities    map[string]interface{}
+	APIKey          string // presented by the client; only its ID is kept
 }
 
 func (c *InitializeSessionCommand) CommandName() string {
@@ -70,6 +74,8 @@ type CreateConversationCommand struct {
 	SystemPrompt string
 	MaxTokens    int
 	Temperature  float64
+	Reasoning    *vo.Reasoning  // nil leaves reasoning off
+	ContextType  vo.ContextType // recorded in the conversation metadata
 }
 
 func (c *CreateConversationCommand) CommandName() string {
@@ -80,7 +86,13 @@ func (c *CreateConversationCommand) CommandName() string {
 type SendMessageCommand struct {
 	ConversationID vo.ConversationID
 	Content        string
+	Attachments    []entities.ContentBlock // images and documents sent with Content
 	Stream         bool
+	OnText         services.StreamTextHandler // receives text deltas when Stream is set
+
+	// RunTools runs the tools the model calls and feeds their results back until it ends its turn
+	RunTools     bool
+	AllowedTools []string // narrows the agent's allowed tools
 }
 
 func (c *SendMessageCommand) CommandName() string {
@@ -139,12 +151,39 @@ type ExecuteToolCommand struct {
 	SessionID vo.SessionID
 	Name      string
 	Arguments map[string]interface{}
+	Timeout   time.Duration // Overrides the tool timeout when positive
+	// BypassCache runs the tool even when a cached result exists, refreshing the cache
+	BypassCache bool
 }
 
 func (c *ExecuteToolCommand) CommandName() string {
 	return "ExecuteTool"
 }
 
+// Task Commands
+
+// SubmitToolTaskCommand starts a tool call as a background task
+type SubmitToolTaskCommand struct {
+	SessionID vo.SessionID
+	Name      string
+	Arguments map[string]interface{}
+	TTL       time.Duration
+}
+
+func (c *SubmitToolTaskCommand) CommandName() string {
+	return "SubmitToolTask"
+}
+
+// CancelTaskCommand cancels a background task
+type CancelTaskCommand struct {
+	SessionID vo.SessionID
+	TaskID    string
+}
+
+func (c *CancelTaskCommand) CommandName() string {
+	return "CancelTask"
+}
+
 // Resource Commands
 
 // RegisterResourceCommand registers a new resource
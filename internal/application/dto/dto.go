@@ -36,14 +36,17 @@ type SessionDTO struct {
 
 // ConversationDTO represents a conversation data transfer object
 type ConversationDTO struct {
-	ID           string       `json:"id"`
-	SessionID    string       `json:"sessionId"`
-	Model        string       `json:"model"`
-	SystemPrompt string       `json:"systemPrompt,omitempty"`
-	Messages     []MessageDTO `json:"messages"`
-	IsActive     bool         `json:"isActive"`
-	CreatedAt    time.Time    `json:"createdAt"`
-	UpdatedAt    time.Time    `json:"updatedAt"`
+	ID           string        `json:"id"`
+	SessionID    string        `json:"sessionId"`
+	Model        string        `json:"model"`
+	SystemPrompt string        `json:"systemPrompt,omitempty"`
+	ContextType  string        `json:"contextType,omitempty"`
+	Reasoning    *vo.Reasoning `json:"reasoning,omitempty"`
+	MessageCount int           `json:"messageCount"`
+	Messages     []MessageDTO  `json:"messages,omitempty"`
+	IsActive     bool          `json:"isActive"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	UpdatedAt    time.Time     `json:"updatedAt"`
 }
 
 // MessageDTO represents a message data transfer object
@@ -64,6 +67,7 @@ type ContentBlockDTO struct {
 	ToolUseID string                 `json:"tool_use_id,omitempty"`
 	Content   string                 `json:"content,omitempty"`
 	IsError   bool                   `json:"is_error,omitempty"`
+	MediaType string                 `json:"media_type,omitempty"` // of an image or document, whose data is left out
 }
 
 // ToolDTO represents a tool data transfer object
@@ -0,0 +1,198 @@
+// Package handlers contains CQRS handlers for the TelemetryFlow GO MCP service
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/queries"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+)
+
+// Audit handler errors
+var (
+	ErrInvalidAuditStatus = errors.New("invalid audit status")
+	ErrInvalidTimeRange   = errors.New("since must be before until")
+)
+
+// MaxAuditSearchLimit caps the records returned by one audit search
+const MaxAuditSearchLimit = 500
+
+// SessionMetadataAPIKeyID is the session metadata key holding the caller's API key ID
+const SessionMetadataAPIKeyID = "api_key_id"
+
+// APIKeyID identifies an API key by its hex SHA-256 hash, as stored in api_keys.key_hash
+func APIKeyID(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// redactedValue replaces the values of sensitive arguments in the audit trail
+const redactedValue = "[REDACTED]"
+
+// DefaultRedactedArguments lists argument names whose values are never stored in the audit trail
+var DefaultRedactedArguments = []string{
+	"password", "passwd", "secret", "token", "api_key", "access_key", "private_key",
+	"authorization", "credential", "credentials", "cookie",
+}
+
+// ToolAuditor records tool calls in the audit trail
+type ToolAuditor interface {
+	Record(ctx context.Context, execution *entities.ToolExecution)
+}
+
+// AuditOptions controls what the audit trail stores about tool arguments
+type AuditOptions struct {
+	RedactArguments []string // argument names whose values are replaced; empty uses DefaultRedactedArguments
+	MaxValueLength  int      // longer string values are truncated; zero keeps them whole
+}
+
+// AuditHandler handles audit trail queries
+type AuditHandler struct {
+	repo repositories.IToolExecutionRepository
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(repo repositories.IToolExecutionRepository) *AuditHandler {
+	return &AuditHandler{repo: repo}
+}
+
+// HandleSearchToolExecutions handles SearchToolExecutionsQuery
+func (h *AuditHandler) HandleSearchToolExecutions(ctx context.Context, query *queries.SearchToolExecutionsQuery) ([]*entities.ToolExecution, error) {
+	status := entities.ToolExecutionStatus(query.Status)
+	switch status {
+	case "", entities.ToolExecutionSuccess, entities.ToolExecutionError, entities.ToolExecutionTimeout,
+		entities.ToolExecutionCancelled, entities.ToolExecutionRejected:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAuditStatus, query.Status)
+	}
+	if !query.Since.IsZero() && !query.Until.IsZero() && !query.Since.Before(query.Until) {
+		return nil, ErrInvalidTimeRange
+	}
+
+	limit := query.Limit
+	if limit <= 0 || limit > MaxAuditSearchLimit {
+		limit = MaxAuditSearchLimit
+	}
+
+	return h.repo.Search(ctx, repositories.ToolExecutionFilter{
+		SessionID: query.SessionID,
+		ToolName:  query.ToolName,
+		APIKeyID:  query.APIKeyID,
+		Status:    status,
+		Since:     query.Since,
+		Until:     query.Until,
+		Limit:     limit,
+	})
+}
+
+// RedactArguments returns a copy of args with sensitive values replaced and long strings truncated.
+// An argument is sensitive when its name contains one of names as whole words,
+// so "db_password" and "X-Api-Key" match "password" and "api_key" but "max_tokens" does not match "token".
+func RedactArguments(args map[string]interface{}, names []string, maxValueLength int) map[string]interface{} {
+	patterns := make([][]string, 0, len(names))
+	for _, name := range names {
+		if words := argumentWords(name); len(words) > 0 {
+			patterns = append(patterns, words)
+		}
+	}
+	redacted, _ := redactValue(args, patterns, maxValueLength).(map[string]interface{})
+	return redacted
+}
+
+func redactValue(value interface{}, patterns [][]string, maxValueLength int) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return map[string]interface{}(nil)
+		}
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if isSensitiveArgument(key, patterns) {
+				out[key] = redactedValue
+				continue
+			}
+			out[key] = redactValue(item, patterns, maxValueLength)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = redactValue(item, patterns, maxValueLength)
+		}
+		return out
+	case string:
+		if maxValueLength > 0 && len(v) > maxValueLength {
+			return v[:maxValueLength] + "...(truncated)"
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// isSensitiveArgument reports whether any pattern appears as consecutive words of name
+func isSensitiveArgument(name string, patterns [][]string) bool {
+	words := argumentWords(name)
+	for _, pattern := range patterns {
+		for i := 0; i+len(pattern) <= len(words); i++ {
+			match := true
+			for j, word := range pattern {
+				if words[i+j] != word {
+					match = false
+					break
+				}
+			}
+			if match {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// argumentWordSeparator splits snake, kebab and dotted names
+var argumentWordSeparator = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// argumentWords splits an argument name into lower-case words, including camelCase boundaries
+func argumentWords(name string) []string {
+	var words []string
+	for _, part := range argumentWordSeparator.Split(name, -1) {
+		start := 0
+		runes := []rune(part)
+		for i := 1; i < len(runes); i++ {
+			if unicode.IsUpper(runes[i]) && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				words = append(words, strings.ToLower(string(runes[start:i])))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			words = append(words, strings.ToLower(string(runes[start:])))
+		}
+	}
+	return words
+}
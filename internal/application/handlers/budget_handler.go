@@ -0,0 +1,85 @@
+// Package handlers contains CQRS command and query handlers
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/queries"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+)
+
+// BudgetUsageReader reads what an organization has spent of a budget in the current period
+type BudgetUsageReader interface {
+	Usage(ctx context.Context, budget *entities.LLMBudget) (entities.BudgetUsage, error)
+}
+
+// LLMBudgetStatus is a budget and what its organization has spent of it in the current period
+type LLMBudgetStatus struct {
+	Budget      *entities.LLMBudget
+	Usage       entities.BudgetUsage
+	Used        float64 // largest share of a limit used
+	WindowStart time.Time
+	ResetsAt    time.Time
+}
+
+// BudgetHandler handles LLM budget queries
+type BudgetHandler struct {
+	repo  repositories.ILLMBudgetRepository
+	usage BudgetUsageReader
+}
+
+// NewBudgetHandler creates a new BudgetHandler
+func NewBudgetHandler(repo repositories.ILLMBudgetRepository, usage BudgetUsageReader) *BudgetHandler {
+	return &BudgetHandler{repo: repo, usage: usage}
+}
+
+// HandleGetLLMBudgets handles GetLLMBudgetsQuery
+func (h *BudgetHandler) HandleGetLLMBudgets(ctx context.Context, query *queries.GetLLMBudgetsQuery) ([]*LLMBudgetStatus, error) {
+	var budgets []*entities.LLMBudget
+	var err error
+	if query.OrganizationID != "" {
+		budgets, err = h.repo.FindByOrganization(ctx, query.OrganizationID)
+	} else {
+		budgets, err = h.repo.List(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	statuses := make([]*LLMBudgetStatus, 0, len(budgets))
+	for _, budget := range budgets {
+		usage, err := h.usage.Usage(ctx, budget)
+		if err != nil {
+			return nil, err
+		}
+		start, end := budget.Period.Window(now)
+		statuses = append(statuses, &LLMBudgetStatus{
+			Budget:      budget,
+			Usage:       usage,
+			Used:        budget.Used(usage),
+			WindowStart: start,
+			ResetsAt:    end,
+		})
+	}
+	return statuses, nil
+}
@@ -21,9 +21,13 @@ package handlers
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"sync"
 
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/commands"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/queries"
+	appsvc "github.com/telemetryflow/telemetryflow-go-mcp/internal/application/services"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
@@ -35,14 +39,27 @@ import (
 var (
 	ErrConversationNotFound = errors.New("conversation not found")
 	ErrMessageEmpty         = errors.New("message cannot be empty")
+	ErrAgentDisabled        = errors.New("tool use is not enabled")
+	ErrMaxConversations     = errors.New("maximum active conversations reached")
 )
 
+// ConversationLimits bound the conversations of a session; zero disables a limit
+type ConversationLimits struct {
+	MaxConversations int // active conversations per session
+	MaxMessages      int // messages per conversation
+}
+
 // ConversationHandler handles conversation-related commands and queries
 type ConversationHandler struct {
 	sessionRepo      repositories.ISessionRepository
 	conversationRepo repositories.IConversationRepository
 	claudeService    services.IClaudeService
 	eventPublisher   EventPublisher
+	agent            *appsvc.AgentService
+	compactor        *appsvc.Compactor
+	limits           ConversationLimits
+	modelAliases     []vo.Model
+	turns            turnLocks
 }
 
 // NewConversationHandler creates a new ConversationHandler
@@ -60,6 +77,26 @@ func NewConversationHandler(
 	}
 }
 
+// SetAgent lets SendMessageCommand.RunTools run the tools the model calls
+func (h *ConversationHandler) SetAgent(agent *appsvc.AgentService) {
+	h.agent = agent
+}
+
+// SetCompactor keeps conversations within their model's context window
+func (h *ConversationHandler) SetCompactor(compactor *appsvc.Compactor) {
+	h.compactor = compactor
+}
+
+// SetLimits sets the conversation limits of each session
+func (h *ConversationHandler) SetLimits(limits ConversationLimits) {
+	h.limits = limits
+}
+
+// SetModelAliases lets conversations use the routing aliases as their model
+func (h *ConversationHandler) SetModelAliases(aliases []vo.Model) {
+	h.modelAliases = aliases
+}
+
 // HandleCreateConversation handles CreateConversationCommand
 func (h *ConversationHandler) HandleCreateConversation(ctx context.Context, cmd *commands.CreateConversationCommand) (*aggregates.Conversation, error) {
 	// Verify session exists
@@ -71,9 +108,19 @@ func (h *ConversationHandler) HandleCreateConversation(ctx context.Context, cmd
 		return nil, ErrSessionNotFound
 	}
 
+	if h.limits.MaxConversations > 0 {
+		active, err := h.countActiveConversations(ctx, cmd.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		if active >= h.limits.MaxConversations {
+			return nil, ErrMaxConversations
+		}
+	}
+
 	// Validate model
 	model := cmd.Model
-	if !model.IsValid() {
+	if !model.IsValid() && !slices.Contains(h.modelAliases, model) {
 		model = vo.DefaultModel
 	}
 
@@ -101,6 +148,12 @@ func (h *ConversationHandler) HandleCreateConversation(ctx context.Context, cmd
 	if cmd.Temperature >= 0 {
 		conversation.SetTemperature(cmd.Temperature)
 	}
+	if cmd.Reasoning.IsEnabled() {
+		conversation.SetReasoning(cmd.Reasoning)
+	}
+	if cmd.ContextType != "" {
+		conversation.SetMetadata("context_type", cmd.ContextType.String())
+	}
 
 	// Save session and conversation
 	if err := h.sessionRepo.Save(ctx, session); err != nil {
@@ -123,6 +176,9 @@ type SendMessageResult struct {
 	Response   *services.ClaudeResponse
 	ToolUses   []entities.ContentBlock
 	HasToolUse bool
+
+	// Agent is set when the message ran tools
+	Agent *appsvc.AgentResult
 }
 
 // HandleSendMessage handles SendMessageCommand
@@ -132,6 +188,13 @@ func (h *ConversationHandler) HandleSendMessage(ctx context.Context, cmd *comman
 		return nil, ErrMessageEmpty
 	}
 
+	// Turns of one conversation run one at a time, since tools/call runs concurrently
+	endTurn, err := h.turns.acquire(ctx, cmd.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	defer endTurn()
+
 	// Get conversation
 	conversation, err := h.conversationRepo.FindByID(ctx, cmd.ConversationID)
 	if err != nil {
@@ -146,22 +209,55 @@ func (h *ConversationHandler) HandleSendMessage(ctx context.Context, cmd *comman
 		return nil, aggregates.ErrConversationClosed
 	}
 
+	// A turn that fails before it is saved leaves the conversation as it was
+	checkpoint := conversation.Checkpoint()
+	saved := false
+	defer func() {
+		if !saved {
+			conversation.Rollback(checkpoint)
+		}
+	}()
+
+	// A turn adds at least the message and a response
+	if h.limits.MaxMessages > 0 && conversation.MessageCount()+2 > h.limits.MaxMessages {
+		return nil, aggregates.ErrMaxMessagesExceeded
+	}
+
+	// Attachments the model cannot take are refused before they join the history
+	for _, attachment := range cmd.Attachments {
+		if !conversation.Model().Accepts(attachment.Type) {
+			return nil, fmt.Errorf("%w: %s does not accept %s input", vo.ErrUnsupportedInput, conversation.Model(), attachment.Type)
+		}
+	}
+
+	if cmd.RunTools {
+		result, err := h.runAgent(ctx, conversation, cmd)
+		saved = result != nil
+		return result, err
+	}
+
 	// Add user message
-	_, err = conversation.AddUserMessage(cmd.Content)
+	_, err = conversation.AddUserMessage(cmd.Content, cmd.Attachments...)
 	if err != nil {
 		return nil, err
 	}
 
 	// Build Claude request
 	request := h.buildClaudeRequest(conversation)
+	llmCtx := withConversationUsage(ctx, conversation)
+	if h.compactor != nil {
+		if _, err := h.compactor.Fit(llmCtx, conversation, request); err != nil {
+			return nil, err
+		}
+	}
 
 	// Call Claude API
 	var response *services.ClaudeResponse
 	if cmd.Stream {
 		// For streaming, we collect events and build response
-		response, err = h.handleStreamingRequest(ctx, request)
+		response, err = h.handleStreamingRequest(llmCtx, request, cmd.OnText)
 	} else {
-		response, err = h.claudeService.CreateMessage(ctx, request)
+		response, err = h.claudeService.CreateMessage(llmCtx, request)
 	}
 
 	if err != nil {
@@ -178,6 +274,7 @@ func (h *ConversationHandler) HandleSendMessage(ctx context.Context, cmd *comman
 	if err := h.conversationRepo.Save(ctx, conversation); err != nil {
 		return nil, err
 	}
+	saved = true
 
 	// Publish events (best-effort, don't fail on publish errors)
 	for _, event := range conversation.Events() {
@@ -201,8 +298,47 @@ func (h *ConversationHandler) HandleSendMessage(ctx context.Context, cmd *comman
 	}, nil
 }
 
+// runAgent sends the message through the agent loop. The conversation is saved even when the run
+// stops at a limit, and the result then holds the run so far
+func (h *ConversationHandler) runAgent(ctx context.Context, conversation *aggregates.Conversation, cmd *commands.SendMessageCommand) (*SendMessageResult, error) {
+	if h.agent == nil {
+		return nil, ErrAgentDisabled
+	}
+
+	agentResult, runErr := h.agent.Run(withConversationUsage(ctx, conversation), conversation, cmd.Content, appsvc.AgentOptions{AllowedTools: cmd.AllowedTools, Attachments: cmd.Attachments})
+	if agentResult == nil {
+		return nil, runErr
+	}
+
+	if err := h.conversationRepo.Save(ctx, conversation); err != nil {
+		return nil, err
+	}
+
+	// Publish events (best-effort, don't fail on publish errors)
+	for _, event := range conversation.Events() {
+		_ = h.eventPublisher.Publish(ctx, event)
+	}
+
+	result := &SendMessageResult{Response: agentResult.Response, Agent: agentResult}
+	if agentResult.Response != nil {
+		for _, block := range agentResult.Response.Content {
+			if block.Type == vo.ContentTypeToolUse {
+				result.ToolUses = append(result.ToolUses, block)
+			}
+		}
+	}
+	result.HasToolUse = len(result.ToolUses) > 0
+	return result, runErr
+}
+
 // HandleAddToolResult handles AddToolResultCommand
 func (h *ConversationHandler) HandleAddToolResult(ctx context.Context, cmd *commands.AddToolResultCommand) error {
+	endTurn, err := h.turns.acquire(ctx, cmd.ConversationID)
+	if err != nil {
+		return err
+	}
+	defer endTurn()
+
 	// Get conversation
 	conversation, err := h.conversationRepo.FindByID(ctx, cmd.ConversationID)
 	if err != nil {
@@ -211,6 +347,9 @@ func (h *ConversationHandler) HandleAddToolResult(ctx context.Context, cmd *comm
 	if conversation == nil {
 		return ErrConversationNotFound
 	}
+	if h.limits.MaxMessages > 0 && conversation.MessageCount() >= h.limits.MaxMessages {
+		return aggregates.ErrMaxMessagesExceeded
+	}
 
 	// Create tool result message
 	toolResultBlock := entities.ContentBlock{
@@ -225,12 +364,14 @@ func (h *ConversationHandler) HandleAddToolResult(ctx context.Context, cmd *comm
 		return err
 	}
 
+	checkpoint := conversation.Checkpoint()
 	if err := conversation.AddMessage(msg); err != nil {
 		return err
 	}
 
 	// Save conversation
 	if err := h.conversationRepo.Save(ctx, conversation); err != nil {
+		conversation.Rollback(checkpoint)
 		return err
 	}
 
@@ -244,6 +385,13 @@ func (h *ConversationHandler) HandleAddToolResult(ctx context.Context, cmd *comm
 
 // HandleCloseConversation handles CloseConversationCommand
 func (h *ConversationHandler) HandleCloseConversation(ctx context.Context, cmd *commands.CloseConversationCommand) error {
+	// A turn in progress finishes first, so that its user message is not saved without a reply
+	endTurn, err := h.turns.acquire(ctx, cmd.ConversationID)
+	if err != nil {
+		return err
+	}
+	defer endTurn()
+
 	conversation, err := h.conversationRepo.FindByID(ctx, cmd.ConversationID)
 	if err != nil {
 		return err
@@ -302,6 +450,15 @@ func (h *ConversationHandler) HandleListConversations(ctx context.Context, query
 		return nil, err
 	}
 
+	if query.ActiveOnly {
+		conversations = slices.DeleteFunc(conversations, func(c *aggregates.Conversation) bool { return !c.IsActive() })
+	}
+
+	// Most recently updated first
+	slices.SortStableFunc(conversations, func(a, b *aggregates.Conversation) int {
+		return b.UpdatedAt().Compare(a.UpdatedAt())
+	})
+
 	// Apply pagination
 	if query.Limit > 0 && len(conversations) > query.Limit {
 		conversations = conversations[:query.Limit]
@@ -336,6 +493,21 @@ func (h *ConversationHandler) HandleGetConversationMessages(ctx context.Context,
 	return messages, nil
 }
 
+// countActiveConversations counts the open conversations of a session
+func (h *ConversationHandler) countActiveConversations(ctx context.Context, sessionID vo.SessionID) (int, error) {
+	conversations, err := h.conversationRepo.FindBySessionID(ctx, sessionID)
+	if err != nil {
+		return 0, err
+	}
+	active := 0
+	for _, conversation := range conversations {
+		if conversation.IsActive() {
+			active++
+		}
+	}
+	return active, nil
+}
+
 // buildClaudeRequest builds a Claude API request from a conversation
 func (h *ConversationHandler) buildClaudeRequest(conversation *aggregates.Conversation) *services.ClaudeRequest {
 	messages := make([]services.ClaudeMessage, len(conversation.Messages()))
@@ -366,35 +538,77 @@ func (h *ConversationHandler) buildClaudeRequest(conversation *aggregates.Conver
 		TopK:          conversation.TopK(),
 		StopSequences: conversation.StopSequences(),
 		Tools:         tools,
+		Reasoning:     conversation.Reasoning(),
 	}
 }
 
-// handleStreamingRequest handles streaming response
-func (h *ConversationHandler) handleStreamingRequest(ctx context.Context, request *services.ClaudeRequest) (*services.ClaudeResponse, error) {
+// handleStreamingRequest streams the response, passing text deltas to onText. Returning early
+// cancels the stream, which aborts the upstream request
+func (h *ConversationHandler) handleStreamingRequest(ctx context.Context, request *services.ClaudeRequest, onText services.StreamTextHandler) (*services.ClaudeResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	request.Stream = true
 	eventChan, err := h.claudeService.CreateMessageStream(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
-	var response *services.ClaudeResponse
-	var contentBlocks []entities.ContentBlock
+	response, err := services.CollectStream(eventChan, onText)
+	if err == nil {
+		// A cancelled stream may simply end, leaving a truncated response
+		err = ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
 
-	for event := range eventChan {
-		if event.Error != nil {
-			return nil, event.Error
-		}
-		if event.Message != nil {
-			response = event.Message
-		}
-		if event.ContentBlock != nil {
-			contentBlocks = append(contentBlocks, *event.ContentBlock)
+// turnLocks serializes the turns of each conversation
+type turnLocks struct {
+	mu    sync.Mutex
+	turns map[string]*turnLock
+}
+
+// turnLock is held by the running turn of one conversation
+type turnLock struct {
+	held  chan struct{}
+	users int // turns running or waiting
+}
+
+// acquire waits until no other turn of the conversation runs, returning the function that ends
+// the turn. It gives up when ctx is done
+func (l *turnLocks) acquire(ctx context.Context, id vo.ConversationID) (func(), error) {
+	key := id.String()
+	l.mu.Lock()
+	if l.turns == nil {
+		l.turns = make(map[string]*turnLock)
+	}
+	turn, ok := l.turns[key]
+	if !ok {
+		turn = &turnLock{held: make(chan struct{}, 1)}
+		l.turns[key] = turn
+	}
+	turn.users++
+	l.mu.Unlock()
+
+	leave := func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if turn.users--; turn.users == 0 {
+			delete(l.turns, key)
 		}
 	}
 
-	if response != nil {
-		response.Content = contentBlocks
+	select {
+	case turn.held <- struct{}{}:
+		return func() {
+			<-turn.held
+			leave()
+		}, nil
+	case <-ctx.Done():
+		leave()
+		return nil, ctx.Err()
 	}
-
-	return response, nil
 }
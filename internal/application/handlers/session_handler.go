@@ -20,6 +20,7 @@ package handlers
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/commands"
@@ -34,12 +35,17 @@ var (
 	ErrSessionAlreadyExists = errors.New("session already exists")
 	ErrInvalidCommand       = errors.New("invalid command")
 	ErrInvalidQuery         = errors.New("invalid query")
+	ErrInvalidAPIKey        = errors.New("invalid API key")
 )
 
 // SessionHandler handles session-related commands and queries
 type SessionHandler struct {
 	sessionRepo    repositories.ISessionRepository
 	eventPublisher EventPublisher
+
+	// API keys accepted at initialize
+	allowedAPIKeys []string
+	requireAPIKey  bool
 }
 
 // EventPublisher is the interface for publishing events
@@ -55,10 +61,25 @@ func NewSessionHandler(sessionRepo repositories.ISessionRepository, eventPublish
 	}
 }
 
+// SetAPIKeys sets the API keys that clients may present at initialize. With required set,
+// sessions without one of them are refused
+func (h *SessionHandler) SetAPIKeys(allowed []string, required bool) {
+	h.allowedAPIKeys = allowed
+	h.requireAPIKey = required
+}
+
 // HandleInitializeSession handles InitializeSessionCommand
 func (h *SessionHandler) HandleInitializeSession(ctx context.Context, cmd *commands.InitializeSessionCommand) (*aggregates.Session, error) {
+	apiKeyID, err := h.authenticate(cmd.APIKey)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create new session
 	session := aggregates.NewSession()
+	if apiKeyID != "" {
+		session.SetMetadata(SessionMetadataAPIKeyID, apiKeyID)
+	}
 
 	// Initialize with client info
 	clientInfo := &aggregates.ClientInfo{
@@ -86,6 +107,21 @@ func (h *SessionHandler) HandleInitializeSession(ctx context.Context, cmd *comma
 	return session, nil
 }
 
+// authenticate returns the ID of key when it is an allowed API key
+func (h *SessionHandler) authenticate(key string) (string, error) {
+	if key != "" {
+		for _, allowed := range h.allowedAPIKeys {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(allowed)) == 1 {
+				return APIKeyID(key), nil
+			}
+		}
+	}
+	if h.requireAPIKey {
+		return "", ErrInvalidAPIKey
+	}
+	return "", nil
+}
+
 // HandleCloseSession handles CloseSessionCommand
 func (h *SessionHandler) HandleCloseSession(ctx context.Context, cmd *commands.CloseSessionCommand) error {
 	session, err := h.sessionRepo.FindByID(ctx, cmd.SessionID)
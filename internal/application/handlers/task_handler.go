@@ -0,0 +1,352 @@
+// Package handlers contains CQRS handlers for the TelemetryFlow GO MCP service
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/commands"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/queries"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// Task handler errors
+var (
+	ErrTaskNotFound        = errors.New("task not found")
+	ErrTaskAlreadyFinished = errors.New("task has already finished")
+	ErrTooManyTasks        = errors.New("too many background tasks")
+)
+
+// Task list and status limits
+const (
+	defaultTaskListLimit   = 50
+	maxTaskStatusMessage   = 256
+	taskCancelledByRequest = "cancelled by request"
+)
+
+// TaskDispatcher queues background tasks; workers execute them through TaskHandler.RunTask
+type TaskDispatcher interface {
+	Dispatch(ctx context.Context, taskID string) error
+}
+
+// TaskOptions configures background task handling
+type TaskOptions struct {
+	DefaultTTL   time.Duration
+	MaxTTL       time.Duration
+	Timeout      time.Duration
+	PollInterval time.Duration
+	MaxTasks     int
+}
+
+// TaskHandler handles background tool tasks
+type TaskHandler struct {
+	toolHandler *ToolHandler
+	dispatcher  TaskDispatcher
+	options     TaskOptions
+	mu          sync.Mutex
+	tasks       map[string]*trackedTask
+}
+
+// trackedTask holds the runtime state of a task
+type trackedTask struct {
+	task    *entities.Task
+	running bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewTaskHandler creates a new TaskHandler
+func NewTaskHandler(toolHandler *ToolHandler, dispatcher TaskDispatcher, options TaskOptions) *TaskHandler {
+	return &TaskHandler{
+		toolHandler: toolHandler,
+		dispatcher:  dispatcher,
+		options:     options,
+		tasks:       make(map[string]*trackedTask),
+	}
+}
+
+// HandleSubmitToolTask handles SubmitToolTaskCommand
+func (h *TaskHandler) HandleSubmitToolTask(ctx context.Context, cmd *commands.SubmitToolTaskCommand) (*entities.Task, error) {
+	// Verify session exists
+	session, err := h.toolHandler.sessionRepo.FindByID(ctx, cmd.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrSessionNotFound
+	}
+
+	// Fail fast on unknown or disabled tools
+	tool, err := h.toolHandler.HandleGetTool(ctx, &queries.GetToolQuery{SessionID: cmd.SessionID, Name: cmd.Name})
+	if err != nil {
+		return nil, err
+	}
+	if !tool.IsEnabled() {
+		return nil, ErrToolDisabled
+	}
+
+	task := entities.NewTask(cmd.SessionID, cmd.Name, cmd.Arguments, h.taskTTL(cmd.TTL), h.options.PollInterval)
+
+	h.mu.Lock()
+	h.purgeExpiredLocked(time.Now())
+	if h.options.MaxTasks > 0 && len(h.tasks) >= h.options.MaxTasks {
+		h.mu.Unlock()
+		return nil, ErrTooManyTasks
+	}
+	h.tasks[task.ID()] = &trackedTask{task: task, done: make(chan struct{})}
+	snapshot := *task
+	h.mu.Unlock()
+
+	if err := h.dispatcher.Dispatch(ctx, task.ID()); err != nil {
+		h.mu.Lock()
+		delete(h.tasks, task.ID())
+		h.mu.Unlock()
+		return nil, fmt.Errorf("failed to queue task: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+// taskTTL clamps a requested TTL to the configured bounds
+func (h *TaskHandler) taskTTL(requested time.Duration) time.Duration {
+	ttl := requested
+	if ttl <= 0 {
+		ttl = h.options.DefaultTTL
+	}
+	if h.options.MaxTTL > 0 && ttl > h.options.MaxTTL {
+		ttl = h.options.MaxTTL
+	}
+	return ttl
+}
+
+// RunTask executes a queued task; duplicate or stale deliveries are ignored
+func (h *TaskHandler) RunTask(ctx context.Context, taskID string) error {
+	h.mu.Lock()
+	tracked, ok := h.tasks[taskID]
+	if !ok || tracked.running || tracked.task.IsTerminal() {
+		h.mu.Unlock()
+		return nil
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	tracked.running = true
+	tracked.cancel = cancel
+	task := tracked.task
+	h.mu.Unlock()
+
+	runCtx = entities.WithProgressReporter(runCtx, func(update entities.ToolProgress) {
+		message := statusLine(update.Message)
+		if message == "" {
+			return
+		}
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		task.SetStatusMessage(message)
+	})
+
+	result, err := h.toolHandler.HandleExecuteTool(runCtx, &commands.ExecuteToolCommand{
+		SessionID: task.SessionID(),
+		Name:      task.ToolName(),
+		Arguments: task.Arguments(),
+		Timeout:   h.options.Timeout,
+	})
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var finished bool
+	if err != nil {
+		finished = task.Fail(err.Error())
+	} else {
+		finished = task.Complete(result)
+	}
+	if finished {
+		close(tracked.done)
+	}
+	return nil
+}
+
+// HandleGetTask handles GetTaskQuery
+func (h *TaskHandler) HandleGetTask(ctx context.Context, query *queries.GetTaskQuery) (*entities.Task, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	tracked, err := h.lookupLocked(query.SessionID, query.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := *tracked.task
+	return &snapshot, nil
+}
+
+// HandleGetTaskResult handles GetTaskResultQuery, waiting up to query.Wait for the task to finish
+func (h *TaskHandler) HandleGetTaskResult(ctx context.Context, query *queries.GetTaskResultQuery) (*entities.Task, error) {
+	h.mu.Lock()
+	tracked, err := h.lookupLocked(query.SessionID, query.TaskID)
+	h.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Wait != 0 {
+		var timeout <-chan time.Time
+		if query.Wait > 0 {
+			timer := time.NewTimer(query.Wait)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		select {
+		case <-tracked.done:
+		case <-timeout:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	snapshot := *tracked.task
+	return &snapshot, nil
+}
+
+// HandleCancelTask handles CancelTaskCommand
+func (h *TaskHandler) HandleCancelTask(ctx context.Context, cmd *commands.CancelTaskCommand) (*entities.Task, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	tracked, err := h.lookupLocked(cmd.SessionID, cmd.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if !tracked.task.Cancel(taskCancelledByRequest) {
+		return nil, ErrTaskAlreadyFinished
+	}
+	close(tracked.done)
+	if tracked.cancel != nil {
+		tracked.cancel()
+	}
+
+	snapshot := *tracked.task
+	return &snapshot, nil
+}
+
+// TaskListResult represents the result of listing tasks
+type TaskListResult struct {
+	Tasks      []*entities.Task
+	NextCursor string
+}
+
+// HandleListTasks handles ListTasksQuery
+func (h *TaskHandler) HandleListTasks(ctx context.Context, query *queries.ListTasksQuery) (*TaskListResult, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.purgeExpiredLocked(time.Now())
+
+	tasks := make([]*entities.Task, 0, len(h.tasks))
+	for _, tracked := range h.tasks {
+		if tracked.task.SessionID().Equals(query.SessionID) {
+			snapshot := *tracked.task
+			tasks = append(tasks, &snapshot)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].CreatedAt().Equal(tasks[j].CreatedAt()) {
+			return tasks[i].CreatedAt().Before(tasks[j].CreatedAt())
+		}
+		return tasks[i].ID() < tasks[j].ID()
+	})
+
+	// The cursor is the ID of the last task on the previous page
+	if query.Cursor != "" {
+		for i, task := range tasks {
+			if task.ID() == query.Cursor {
+				tasks = tasks[i+1:]
+				break
+			}
+		}
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultTaskListLimit
+	}
+	result := &TaskListResult{Tasks: tasks}
+	if len(tasks) > limit {
+		result.Tasks = tasks[:limit]
+		result.NextCursor = tasks[limit-1].ID()
+	}
+	return result, nil
+}
+
+// ToMCPTaskList converts tasks to MCP format
+func (r *TaskListResult) ToMCPTaskList() map[string]interface{} {
+	tasks := make([]map[string]interface{}, len(r.Tasks))
+	for i, task := range r.Tasks {
+		tasks[i] = task.ToMCPTask()
+	}
+
+	result := map[string]interface{}{
+		"tasks": tasks,
+	}
+	if r.NextCursor != "" {
+		result["nextCursor"] = r.NextCursor
+	}
+	return result
+}
+
+// lookupLocked finds a task owned by the session
+func (h *TaskHandler) lookupLocked(sessionID vo.SessionID, taskID string) (*trackedTask, error) {
+	h.purgeExpiredLocked(time.Now())
+	tracked, ok := h.tasks[taskID]
+	if !ok || !tracked.task.SessionID().Equals(sessionID) {
+		return nil, ErrTaskNotFound
+	}
+	return tracked, nil
+}
+
+// purgeExpiredLocked drops finished tasks whose TTL has elapsed
+func (h *TaskHandler) purgeExpiredLocked(now time.Time) {
+	for id, tracked := range h.tasks {
+		if tracked.task.IsTerminal() && tracked.task.IsExpired(now) {
+			delete(h.tasks, id)
+		}
+	}
+}
+
+// statusLine reduces a progress message to its last line, capped for status display
+func statusLine(message string) string {
+	message = strings.TrimRight(message, "\n")
+	if i := strings.LastIndexByte(message, '\n'); i >= 0 {
+		message = message[i+1:]
+	}
+	if len(message) > maxTaskStatusMessage {
+		message = message[:maxTaskStatusMessage]
+		for !utf8.ValidString(message) {
+			message = message[:len(message)-1]
+		}
+	}
+	return message
+}
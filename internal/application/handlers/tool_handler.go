@@ -25,6 +25,7 @@ import (
 
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/commands"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/queries"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/events"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
@@ -40,12 +41,27 @@ var (
 	ErrToolExecution     = errors.New("tool execution failed")
 )
 
+// ToolResultCache serves repeated calls to idempotent tools from cached results, scoped to a session
+type ToolResultCache interface {
+	Execute(
+		ctx context.Context,
+		scope string,
+		toolName string,
+		args map[string]interface{},
+		bypass bool,
+		run func(context.Context) (*entities.ToolResult, error),
+	) (*entities.ToolResult, error)
+}
+
 // ToolHandler handles tool-related commands and queries
 type ToolHandler struct {
 	sessionRepo    repositories.ISessionRepository
 	toolRepo       repositories.IToolRepository
 	eventPublisher EventPublisher
 	toolRegistry   map[string]entities.ToolHandler
+	resultCache    ToolResultCache
+	auditor        ToolAuditor
+	auditOptions   AuditOptions
 }
 
 // NewToolHandler creates a new ToolHandler
@@ -67,6 +83,20 @@ func (h *ToolHandler) RegisterToolHandler(name string, handler entities.ToolHand
 	h.toolRegistry[name] = handler
 }
 
+// SetResultCache enables result caching for tools with a cache policy
+func (h *ToolHandler) SetResultCache(cache ToolResultCache) {
+	h.resultCache = cache
+}
+
+// SetAuditor records every tool call in the audit trail
+func (h *ToolHandler) SetAuditor(auditor ToolAuditor, options AuditOptions) {
+	if len(options.RedactArguments) == 0 {
+		options.RedactArguments = DefaultRedactedArguments
+	}
+	h.auditor = auditor
+	h.auditOptions = options
+}
+
 // HandleRegisterTool handles RegisterToolCommand
 func (h *ToolHandler) HandleRegisterTool(ctx context.Context, cmd *commands.RegisterToolCommand) (*entities.Tool, error) {
 	// Verify session exists
@@ -185,6 +215,7 @@ func (h *ToolHandler) HandleExecuteTool(ctx context.Context, cmd *commands.Execu
 	// Create tool name value object
 	name, err := vo.NewToolName(cmd.Name)
 	if err != nil {
+		h.audit(ctx, session, cmd, startTime, entities.ToolExecutionRejected, err.Error())
 		return nil, err
 	}
 
@@ -194,19 +225,34 @@ func (h *ToolHandler) HandleExecuteTool(ctx context.Context, cmd *commands.Execu
 		return nil, err
 	}
 	if tool == nil {
+		h.audit(ctx, session, cmd, startTime, entities.ToolExecutionRejected, ErrToolNotFound.Error())
 		return nil, ErrToolNotFound
 	}
 
 	// Check if tool is enabled
 	if !tool.IsEnabled() {
+		h.audit(ctx, session, cmd, startTime, entities.ToolExecutionRejected, ErrToolDisabled.Error())
 		return nil, ErrToolDisabled
 	}
 
 	// Execute tool with timeout
-	execCtx, cancel := context.WithTimeout(ctx, tool.Timeout())
+	timeout := tool.Timeout()
+	if cmd.Timeout > 0 {
+		timeout = cmd.Timeout
+	}
+	execCtx := context.WithValue(WithSessionID(ctx, cmd.SessionID), nestedToolExecutorKey{}, NestedToolExecutor(h.executeNestedTool))
+	execCtx, cancel := context.WithTimeout(withSessionUsage(execCtx, session), timeout)
 	defer cancel()
 
-	result, err := h.executeToolWithContext(execCtx, tool, cmd.Arguments)
+	var result *entities.ToolResult
+	if h.resultCache != nil {
+		result, err = h.resultCache.Execute(execCtx, cmd.SessionID.String(), cmd.Name, cmd.Arguments, cmd.BypassCache,
+			func(ctx context.Context) (*entities.ToolResult, error) {
+				return h.executeToolWithContext(ctx, tool, cmd.Arguments)
+			})
+	} else {
+		result, err = h.executeToolWithContext(execCtx, tool, cmd.Arguments)
+	}
 	duration := time.Since(startTime)
 
 	// Publish execution event (best-effort, don't fail on publish errors)
@@ -214,6 +260,19 @@ func (h *ToolHandler) HandleExecuteTool(ctx context.Context, cmd *commands.Execu
 	event := events.NewToolExecutedEvent(cmd.SessionID, cmd.Name, success, duration)
 	_ = h.eventPublisher.Publish(ctx, event)
 
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		h.audit(ctx, session, cmd, startTime, entities.ToolExecutionTimeout, err.Error())
+	case errors.Is(err, context.Canceled):
+		h.audit(ctx, session, cmd, startTime, entities.ToolExecutionCancelled, err.Error())
+	case err != nil:
+		h.audit(ctx, session, cmd, startTime, entities.ToolExecutionError, err.Error())
+	case result != nil && result.IsError:
+		h.audit(ctx, session, cmd, startTime, entities.ToolExecutionError, resultErrorMessage(result))
+	default:
+		h.audit(ctx, session, cmd, startTime, entities.ToolExecutionSuccess, "")
+	}
+
 	if err != nil {
 		return entities.NewErrorToolResult(err), nil
 	}
@@ -221,13 +280,121 @@ func (h *ToolHandler) HandleExecuteTool(ctx context.Context, cmd *commands.Execu
 	return result, nil
 }
 
+// maxAuditErrorLength caps error messages stored in the audit trail
+const maxAuditErrorLength = 1024
+
+// audit records the call in the audit trail, if configured
+func (h *ToolHandler) audit(
+	ctx context.Context,
+	session *aggregates.Session,
+	cmd *commands.ExecuteToolCommand,
+	startTime time.Time,
+	status entities.ToolExecutionStatus,
+	errMessage string,
+) {
+	if h.auditor == nil {
+		return
+	}
+
+	args := RedactArguments(cmd.Arguments, h.auditOptions.RedactArguments, h.auditOptions.MaxValueLength)
+	execution := entities.NewToolExecution(session.ID().String(), cmd.Name, args)
+	execution.ExecutedAt = startTime.UTC()
+	execution.Duration = time.Since(startTime)
+	execution.Status = status
+	if len(errMessage) > maxAuditErrorLength {
+		errMessage = errMessage[:maxAuditErrorLength]
+	}
+	execution.ErrorMessage = errMessage
+	if client := session.ClientInfo(); client != nil {
+		execution.ClientName = client.Name
+		execution.ClientVersion = client.Version
+	}
+	if value, ok := session.GetMetadata(SessionMetadataAPIKeyID); ok {
+		execution.APIKeyID, _ = value.(string)
+	}
+
+	h.auditor.Record(ctx, execution)
+}
+
+// resultErrorMessage returns the text of an error result
+func resultErrorMessage(result *entities.ToolResult) string {
+	for _, content := range result.Content {
+		if content.Type == "text" && content.Text != "" {
+			return content.Text
+		}
+	}
+	return "tool returned an error result"
+}
+
+// sessionIDKey is the context key for the calling session
+type sessionIDKey struct{}
+
+// WithSessionID returns a context carrying the calling session ID
+func WithSessionID(ctx context.Context, sessionID vo.SessionID) context.Context {
+	return context.WithValue(ctx, sessionIDKey{}, sessionID)
+}
+
+// SessionIDFromContext returns the calling session ID, if any
+func SessionIDFromContext(ctx context.Context) (vo.SessionID, bool) {
+	sessionID, ok := ctx.Value(sessionIDKey{}).(vo.SessionID)
+	return sessionID, ok
+}
+
+// nestedToolExecutorKey is the context key for the executor of tools called by the running tool
+type nestedToolExecutorKey struct{}
+
+// NestedToolExecutor runs a tool called by another tool, such as a pipeline step. A positive
+// timeout replaces the called tool's own
+type NestedToolExecutor func(ctx context.Context, name string, input map[string]interface{}, timeout time.Duration) (*entities.ToolResult, error)
+
+// NestedToolExecutorFromContext returns the executor for tools called by the tool running under
+// ctx, if it was started by a ToolHandler
+func NestedToolExecutorFromContext(ctx context.Context) (NestedToolExecutor, bool) {
+	execute, ok := ctx.Value(nestedToolExecutorKey{}).(NestedToolExecutor)
+	return execute, ok
+}
+
+// executeNestedTool runs a tool called by another tool on behalf of the session in ctx, with the
+// same enabled check, cache and audit handling as a client call
+func (h *ToolHandler) executeNestedTool(ctx context.Context, name string, input map[string]interface{}, timeout time.Duration) (*entities.ToolResult, error) {
+	sessionID, ok := SessionIDFromContext(ctx)
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return h.HandleExecuteTool(ctx, &commands.ExecuteToolCommand{
+		SessionID: sessionID,
+		Name:      name,
+		Arguments: input,
+		Timeout:   timeout,
+	})
+}
+
+// AgentTools returns the enabled tools, for an LLM agent to choose from
+func (h *ToolHandler) AgentTools(ctx context.Context) ([]*entities.Tool, error) {
+	return h.toolRepo.FindEnabled(ctx)
+}
+
+// ExecuteAgentTool runs a tool called by an LLM agent on behalf of the session in ctx, with the
+// same timeout, cache and audit handling as a client call
+func (h *ToolHandler) ExecuteAgentTool(ctx context.Context, name string, input map[string]interface{}) (*entities.ToolResult, error) {
+	sessionID, ok := SessionIDFromContext(ctx)
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return h.HandleExecuteTool(ctx, &commands.ExecuteToolCommand{
+		SessionID: sessionID,
+		Name:      name,
+		Arguments: input,
+	})
+}
+
 // executeToolWithContext executes a tool with context
 func (h *ToolHandler) executeToolWithContext(ctx context.Context, tool *entities.Tool, input map[string]interface{}) (*entities.ToolResult, error) {
 	resultChan := make(chan *entities.ToolResult, 1)
 	errChan := make(chan error, 1)
 
 	go func() {
-		result, err := tool.Execute(input)
+		result, err := tool.ExecuteContext(ctx, input)
 		if err != nil {
 			errChan <- err
 			return
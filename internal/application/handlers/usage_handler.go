@@ -0,0 +1,139 @@
+// Package handlers contains CQRS command and query handlers
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/queries"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// Usage handler errors
+var (
+	ErrInvalidUsageGroup = errors.New("invalid usage group")
+)
+
+// SessionMetadataOrganizationID is the session metadata key holding the caller's organization ID
+const SessionMetadataOrganizationID = "organization_id"
+
+// LLMUsageGroups are the dimensions LLM usage can be grouped by
+var LLMUsageGroups = []repositories.LLMUsageGroup{
+	repositories.LLMUsageByModel, repositories.LLMUsageByProvider, repositories.LLMUsageBySession,
+	repositories.LLMUsageByConversation, repositories.LLMUsageByOrganization, repositories.LLMUsageByDay,
+}
+
+// LLMUsageReport is the usage of the LLM calls matching a query
+type LLMUsageReport struct {
+	Groups []*repositories.LLMUsageSummary // empty when the query sets no group
+	Total  repositories.LLMUsageSummary
+}
+
+// UsageHandler handles LLM usage queries
+type UsageHandler struct {
+	repo repositories.ILLMUsageRepository
+}
+
+// NewUsageHandler creates a new UsageHandler
+func NewUsageHandler(repo repositories.ILLMUsageRepository) *UsageHandler {
+	return &UsageHandler{repo: repo}
+}
+
+// HandleGetLLMUsage handles GetLLMUsageQuery
+func (h *UsageHandler) HandleGetLLMUsage(ctx context.Context, query *queries.GetLLMUsageQuery) (*LLMUsageReport, error) {
+	group := repositories.LLMUsageGroup(query.GroupBy)
+	if group != "" && !isLLMUsageGroup(group) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidUsageGroup, query.GroupBy)
+	}
+	if !query.Since.IsZero() && !query.Until.IsZero() && !query.Since.Before(query.Until) {
+		return nil, ErrInvalidTimeRange
+	}
+
+	summaries, err := h.repo.Summarize(ctx, repositories.LLMUsageFilter{
+		SessionID:      query.SessionID,
+		ConversationID: query.ConversationID,
+		OrganizationID: query.OrganizationID,
+		Model:          vo.Model(query.Model),
+		Provider:       vo.Provider(query.Provider),
+		Since:          query.Since,
+		Until:          query.Until,
+		GroupBy:        group,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LLMUsageReport{}
+	var latency time.Duration
+	for _, s := range summaries {
+		report.Total.Requests += s.Requests
+		report.Total.Errors += s.Errors
+		report.Total.UnpricedRequests += s.UnpricedRequests
+		report.Total.InputTokens += s.InputTokens
+		report.Total.OutputTokens += s.OutputTokens
+		report.Total.CacheReadTokens += s.CacheReadTokens
+		report.Total.CacheWriteTokens += s.CacheWriteTokens
+		report.Total.ReasoningTokens += s.ReasoningTokens
+		report.Total.CostUSD += s.CostUSD
+		latency += s.AvgLatency * time.Duration(s.Requests)
+	}
+	if report.Total.Requests > 0 {
+		report.Total.AvgLatency = latency / time.Duration(report.Total.Requests)
+	}
+	if group != "" {
+		report.Groups = summaries
+	}
+	return report, nil
+}
+
+func isLLMUsageGroup(group repositories.LLMUsageGroup) bool {
+	for _, g := range LLMUsageGroups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// withSessionUsage scopes the LLM calls made under ctx to session and the session's organization
+func withSessionUsage(ctx context.Context, session *aggregates.Session) context.Context {
+	scope := entities.UsageScopeFromContext(ctx)
+	scope.SessionID = session.ID().String()
+	if value, ok := session.GetMetadata(SessionMetadataOrganizationID); ok {
+		scope.OrganizationID, _ = value.(string)
+	}
+	return entities.WithUsageScope(ctx, scope)
+}
+
+// withConversationUsage scopes the LLM calls made under ctx to conversation, keeping the calling
+// session when there is one
+func withConversationUsage(ctx context.Context, conversation *aggregates.Conversation) context.Context {
+	scope := entities.UsageScopeFromContext(ctx)
+	scope.ConversationID = conversation.ID().String()
+	if scope.SessionID == "" {
+		scope.SessionID = conversation.SessionID().String()
+	}
+	return entities.WithUsageScope(ctx, scope)
+}
@@ -19,6 +19,8 @@
 package queries
 
 import (
+	"time"
+
 	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
 )
 
@@ -118,6 +120,57 @@ func (q *ListToolsQuery) QueryName() string {
 	return "ListTools"
 }
 
+// Task Queries
+
+// GetTaskQuery retrieves a background task by ID
+type GetTaskQuery struct {
+	SessionID vo.SessionID
+	TaskID    string
+}
+
+func (q *GetTaskQuery) QueryName() string {
+	return "GetTask"
+}
+
+// GetTaskResultQuery retrieves the result of a background task
+type GetTaskResultQuery struct {
+	SessionID vo.SessionID
+	TaskID    string
+	Wait      time.Duration // Negative waits until the task finishes
+}
+
+func (q *GetTaskResultQuery) QueryName() string {
+	return "GetTaskResult"
+}
+
+// ListTasksQuery lists background tasks
+type ListTasksQuery struct {
+	SessionID vo.SessionID
+	Cursor    string
+	Limit     int
+}
+
+func (q *ListTasksQuery) QueryName() string {
+	return "ListTasks"
+}
+
+// Audit Queries
+
+// SearchToolExecutionsQuery searches the tool call audit trail
+type SearchToolExecutionsQuery struct {
+	SessionID string
+	ToolName  string
+	APIKeyID  string
+	Status    string
+	Since     time.Time
+	Until     time.Time
+	Limit     int
+}
+
+func (q *SearchToolExecutionsQuery) QueryName() string {
+	return "SearchToolExecutions"
+}
+
 // Resource Queries
 
 // GetResourceQuery retrieves a resource by URI
@@ -218,3 +271,30 @@ type GetMetricsQuery struct {
 func (q *GetMetricsQuery) QueryName() string {
 	return "GetMetrics"
 }
+
+// Usage Queries
+
+// GetLLMUsageQuery summarizes the token usage and cost of LLM calls
+type GetLLMUsageQuery struct {
+	SessionID      string
+	ConversationID string
+	OrganizationID string
+	Model          string
+	Provider       string
+	Since          time.Time
+	Until          time.Time
+	GroupBy        string
+}
+
+func (q *GetLLMUsageQuery) QueryName() string {
+	return "GetLLMUsage"
+}
+
+// GetLLMBudgetsQuery reports the LLM budgets of an organization and what it has spent of them
+type GetLLMBudgetsQuery struct {
+	OrganizationID string // empty reports every organization
+}
+
+func (q *GetLLMBudgetsQuery) QueryName() string {
+	return "GetLLMBudgets"
+}
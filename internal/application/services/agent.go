@@ -0,0 +1,500 @@
+// Package services contains application services for the TelemetryFlow GO MCP service
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// Agent errors
+var (
+	ErrAgentMaxIterations = errors.New("agent reached its iteration limit")
+	ErrAgentTokenBudget   = errors.New("agent exhausted its token budget")
+	ErrAgentNested        = errors.New("agent runs cannot be nested")
+	ErrAgentToolNotFound  = errors.New("tool is not available to the agent")
+)
+
+// maxAgentToolResultSize caps the tool output fed back to the LLM
+const maxAgentToolResultSize = 32 * 1024
+
+// AllowAllTools in AgentOptions.AllowedTools lets the agent call every enabled tool
+const AllowAllTools = "*"
+
+// AgentToolExecutor lists and runs the tools an agent may call
+type AgentToolExecutor interface {
+	AgentTools(ctx context.Context) ([]*entities.Tool, error)
+	ExecuteAgentTool(ctx context.Context, name string, input map[string]interface{}) (*entities.ToolResult, error)
+}
+
+// AgentOptions bound an agent run
+type AgentOptions struct {
+	MaxIterations    int      // LLM calls per run
+	TokenBudget      int      // input plus output tokens per run, 0 for no limit
+	MaxParallelTools int      // tool calls of one turn run at once
+	AllowedTools     []string // tools the LLM may call, AllowAllTools for every enabled tool
+
+	// Attachments, such as images, are sent with the user turn of a run
+	Attachments []entities.ContentBlock
+}
+
+// AgentToolCall records one tool call made during an agent run
+type AgentToolCall struct {
+	Iteration  int    `json:"iteration"`
+	Tool       string `json:"tool"`
+	IsError    bool   `json:"is_error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// AgentResult is the outcome of an agent run; on a limit error it holds the run so far
+type AgentResult struct {
+	Response   *services.ClaudeResponse `json:"-"` // the last LLM response
+	Iterations int                      `json:"iterations"`
+	ToolCalls  []AgentToolCall          `json:"tool_calls"`
+	Usage      services.ClaudeUsage     `json:"usage"` // summed over all iterations
+	Reasoning  []string                 `json:"-"`     // the reasoning text of each iteration that reasoned
+}
+
+// Text returns the text of the last response
+func (r *AgentResult) Text() string {
+	if r.Response == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, block := range r.Response.Content {
+		if block.Type == vo.ContentTypeText {
+			sb.WriteString(block.Text)
+		}
+	}
+	return sb.String()
+}
+
+// AgentService runs the tool-use loop: it sends the conversation to the LLM, runs the tools the
+// model calls, appends their results and repeats until the model ends its turn
+type AgentService struct {
+	claudeService services.IClaudeService
+	tools         AgentToolExecutor
+	options       AgentOptions
+	compactor     *Compactor
+}
+
+var _ services.IConversationService = (*AgentService)(nil)
+
+// NewAgentService creates an agent that calls tools through executor within options
+func NewAgentService(claudeService services.IClaudeService, executor AgentToolExecutor, options AgentOptions) *AgentService {
+	return &AgentService{
+		claudeService: claudeService,
+		tools:         executor,
+		options:       options,
+	}
+}
+
+// SetCompactor keeps conversations within their model's context window before each LLM call
+func (s *AgentService) SetCompactor(compactor *Compactor) {
+	s.compactor = compactor
+}
+
+// Options returns the limits every run is held to
+func (s *AgentService) Options() AgentOptions {
+	return s.options
+}
+
+// agentRunKey marks a context inside an agent run
+type agentRunKey struct{}
+
+// Run adds text as a user turn and runs the loop. Tools attached to the conversation are offered,
+// or else every enabled tool; either way only allowed tools. options can only tighten the
+// service's limits
+func (s *AgentService) Run(ctx context.Context, conversation *aggregates.Conversation, text string, options AgentOptions) (*AgentResult, error) {
+	if ctx.Value(agentRunKey{}) != nil {
+		return nil, ErrAgentNested
+	}
+	attachments := options.Attachments
+	options = s.limit(options)
+
+	tools := conversation.Tools()
+	if len(tools) == 0 {
+		var err error
+		if tools, err = s.tools.AgentTools(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := addUserTurn(conversation, text, attachments...); err != nil {
+		return nil, err
+	}
+	return s.loop(ctx, conversation, allowedTools(tools, options.AllowedTools), options, &AgentResult{})
+}
+
+// SendMessage sends a message and runs the tools the model calls until it ends its turn
+func (s *AgentService) SendMessage(ctx context.Context, conversation *aggregates.Conversation, text string) (*services.ClaudeResponse, error) {
+	result, err := s.Run(ctx, conversation, text, AgentOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return result.Response, nil
+}
+
+// SendMessageWithTools sends a message and lets the model call the allowed tools among tools
+func (s *AgentService) SendMessageWithTools(ctx context.Context, conversation *aggregates.Conversation, text string, tools []*entities.Tool) (*services.ClaudeResponse, error) {
+	if ctx.Value(agentRunKey{}) != nil {
+		return nil, ErrAgentNested
+	}
+	if err := addUserTurn(conversation, text); err != nil {
+		return nil, err
+	}
+	result, err := s.loop(ctx, conversation, allowedTools(tools, s.options.AllowedTools), s.options, &AgentResult{})
+	if err != nil {
+		return nil, err
+	}
+	return result.Response, nil
+}
+
+// SendMessageStream sends a message and streams the response; tools the model calls are not run
+func (s *AgentService) SendMessageStream(ctx context.Context, conversation *aggregates.Conversation, text string) (<-chan *services.ClaudeStreamEvent, error) {
+	if err := addUserTurn(conversation, text); err != nil {
+		return nil, err
+	}
+	request, err := s.BuildRequest(conversation)
+	if err != nil {
+		return nil, err
+	}
+	request.Stream = true
+	return s.claudeService.CreateMessageStream(ctx, request)
+}
+
+// ProcessToolUse runs tool calls from the conversation's last assistant turn, appends their results
+// and continues the loop until the model ends its turn
+func (s *AgentService) ProcessToolUse(ctx context.Context, conversation *aggregates.Conversation, blocks []entities.ContentBlock) (*services.ClaudeResponse, error) {
+	if ctx.Value(agentRunKey{}) != nil {
+		return nil, ErrAgentNested
+	}
+	ctx = context.WithValue(ctx, agentRunKey{}, true)
+
+	tools := conversation.Tools()
+	if len(tools) == 0 {
+		var err error
+		if tools, err = s.tools.AgentTools(ctx); err != nil {
+			return nil, err
+		}
+	}
+	tools = allowedTools(tools, s.options.AllowedTools)
+
+	result := &AgentResult{}
+	if err := s.runTools(ctx, conversation, tools, toolUseBlocks(blocks), s.options, result); err != nil {
+		return nil, err
+	}
+	result, err := s.loop(ctx, conversation, tools, s.options, result)
+	if err != nil {
+		return nil, err
+	}
+	return result.Response, nil
+}
+
+// BuildRequest builds an LLM request from a conversation and the tools attached to it
+func (s *AgentService) BuildRequest(conversation *aggregates.Conversation) (*services.ClaudeRequest, error) {
+	if conversation == nil {
+		return nil, aggregates.ErrConversationNotFound
+	}
+	return buildRequest(conversation, conversation.Tools()), nil
+}
+
+// loop calls the LLM and runs the tools it asks for until it answers without tool calls
+func (s *AgentService) loop(ctx context.Context, conversation *aggregates.Conversation, tools []*entities.Tool, options AgentOptions, result *AgentResult) (*AgentResult, error) {
+	ctx = context.WithValue(ctx, agentRunKey{}, true)
+
+	for {
+		request := buildRequest(conversation, tools)
+		if options.TokenBudget > 0 {
+			remaining := options.TokenBudget - usedTokens(result.Usage)
+			if remaining <= 0 {
+				return result, fmt.Errorf("%w (%d tokens)", ErrAgentTokenBudget, options.TokenBudget)
+			}
+			request.MaxTokens = min(request.MaxTokens, remaining)
+		}
+		if s.compactor != nil {
+			if _, err := s.compactor.Fit(ctx, conversation, request); err != nil {
+				return result, err
+			}
+		}
+
+		result.Iterations++
+		response, err := s.claudeService.CreateMessage(ctx, request)
+		if err != nil {
+			return result, err
+		}
+		result.Response = response
+		if response.Usage != nil {
+			result.Usage.InputTokens += response.Usage.InputTokens
+			result.Usage.OutputTokens += response.Usage.OutputTokens
+			result.Usage.CacheCreationInputTokens += response.Usage.CacheCreationInputTokens
+			result.Usage.CacheReadInputTokens += response.Usage.CacheReadInputTokens
+			result.Usage.ReasoningTokens += response.Usage.ReasoningTokens
+		}
+		if reasoning := entities.ReasoningText(response.Content); reasoning != "" {
+			result.Reasoning = append(result.Reasoning, reasoning)
+		}
+		if _, err := conversation.AddAssistantMessage(response.Content); err != nil {
+			return result, err
+		}
+
+		toolUses := toolUseBlocks(response.Content)
+		if len(toolUses) == 0 {
+			return result, nil
+		}
+
+		// Tool calls left unanswered are answered with an error on the next user turn
+		if result.Iterations >= options.MaxIterations {
+			return result, fmt.Errorf("%w (%d)", ErrAgentMaxIterations, options.MaxIterations)
+		}
+		if options.TokenBudget > 0 && usedTokens(result.Usage) >= options.TokenBudget {
+			return result, fmt.Errorf("%w (%d tokens)", ErrAgentTokenBudget, options.TokenBudget)
+		}
+
+		if err := s.runTools(ctx, conversation, tools, toolUses, options, result); err != nil {
+			return result, err
+		}
+	}
+}
+
+// runTools runs tool calls, at most MaxParallelTools at once, and appends their results as a user turn
+func (s *AgentService) runTools(
+	ctx context.Context,
+	conversation *aggregates.Conversation,
+	tools []*entities.Tool,
+	toolUses []entities.ContentBlock,
+	options AgentOptions,
+	result *AgentResult,
+) error {
+	names := make([]string, len(toolUses))
+	for i, use := range toolUses {
+		names[i] = use.Name
+	}
+	entities.ReportProgress(ctx, entities.ToolProgress{
+		Progress: float64(result.Iterations),
+		Total:    float64(options.MaxIterations),
+		Message:  "Calling " + strings.Join(names, ", "),
+	})
+
+	results := make([]entities.ContentBlock, len(toolUses))
+	calls := make([]AgentToolCall, len(toolUses))
+	sem := make(chan struct{}, max(options.MaxParallelTools, 1))
+	var wg sync.WaitGroup
+	for i, use := range toolUses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			start := time.Now()
+			results[i] = s.runTool(ctx, tools, use)
+			calls[i] = AgentToolCall{
+				Iteration:  result.Iterations,
+				Tool:       use.Name,
+				IsError:    results[i].IsError,
+				DurationMs: time.Since(start).Milliseconds(),
+			}
+		}()
+	}
+	wg.Wait()
+	result.ToolCalls = append(result.ToolCalls, calls...)
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	message, err := entities.NewMessage(vo.RoleUser, results)
+	if err != nil {
+		return err
+	}
+	return conversation.AddMessage(message)
+}
+
+// runTool runs one tool call; failures become error results the model can react to
+func (s *AgentService) runTool(ctx context.Context, tools []*entities.Tool, use entities.ContentBlock) entities.ContentBlock {
+	block := entities.ContentBlock{Type: vo.ContentTypeToolResult, ToolUseID: use.ID}
+
+	if !slices.ContainsFunc(tools, func(t *entities.Tool) bool { return t.Name().String() == use.Name }) {
+		block.Content = fmt.Sprintf("%s: %s", ErrAgentToolNotFound, use.Name)
+		block.IsError = true
+		return block
+	}
+
+	input := use.Input
+	if input == nil {
+		input = map[string]interface{}{}
+	}
+	toolResult, err := s.tools.ExecuteAgentTool(ctx, use.Name, input)
+	if err != nil {
+		toolResult = entities.NewErrorToolResult(err)
+	}
+	block.Content = toolResultText(toolResult)
+	block.IsError = toolResult.IsError
+	return block
+}
+
+// limit tightens the service's limits with the positive values in options; allowed tools must be
+// allowed by both
+func (s *AgentService) limit(options AgentOptions) AgentOptions {
+	limited := s.options
+	if options.MaxIterations > 0 && options.MaxIterations < limited.MaxIterations {
+		limited.MaxIterations = options.MaxIterations
+	}
+	if options.TokenBudget > 0 && (limited.TokenBudget == 0 || options.TokenBudget < limited.TokenBudget) {
+		limited.TokenBudget = options.TokenBudget
+	}
+	if options.MaxParallelTools > 0 && options.MaxParallelTools < limited.MaxParallelTools {
+		limited.MaxParallelTools = options.MaxParallelTools
+	}
+	if len(options.AllowedTools) > 0 {
+		switch {
+		case slices.Contains(limited.AllowedTools, AllowAllTools):
+			limited.AllowedTools = options.AllowedTools
+		case !slices.Contains(options.AllowedTools, AllowAllTools):
+			var both []string
+			for _, name := range options.AllowedTools {
+				if slices.Contains(limited.AllowedTools, name) {
+					both = append(both, name)
+				}
+			}
+			limited.AllowedTools = both
+		}
+	}
+	return limited
+}
+
+// addUserTurn adds a user message. Tool calls of a run cut short by a limit are answered in the
+// same turn, since every tool_use needs a tool_result before the conversation can go on
+func addUserTurn(conversation *aggregates.Conversation, text string, attachments ...entities.ContentBlock) error {
+	var content []entities.ContentBlock
+	if last := conversation.LastMessage(); last != nil && last.Role() == vo.RoleAssistant {
+		for _, use := range toolUseBlocks(last.Content()) {
+			content = append(content, entities.ContentBlock{
+				Type:      vo.ContentTypeToolResult,
+				ToolUseID: use.ID,
+				Content:   "Not run: the previous agent run stopped at its limit",
+				IsError:   true,
+			})
+		}
+	}
+	if len(content) == 0 {
+		_, err := conversation.AddUserMessage(text, attachments...)
+		return err
+	}
+
+	content = append(content, attachments...)
+	content = append(content, entities.ContentBlock{Type: vo.ContentTypeText, Text: text})
+	message, err := entities.NewMessage(vo.RoleUser, content)
+	if err != nil {
+		return err
+	}
+	return conversation.AddMessage(message)
+}
+
+// allowedTools returns the enabled tools on the allowlist
+func allowedTools(tools []*entities.Tool, allowed []string) []*entities.Tool {
+	all := slices.Contains(allowed, AllowAllTools)
+	var result []*entities.Tool
+	for _, tool := range tools {
+		if tool.IsEnabled() && (all || slices.Contains(allowed, tool.Name().String())) {
+			result = append(result, tool)
+		}
+	}
+	return result
+}
+
+// buildRequest builds an LLM request from a conversation offering tools
+func buildRequest(conversation *aggregates.Conversation, tools []*entities.Tool) *services.ClaudeRequest {
+	messages := make([]services.ClaudeMessage, 0, conversation.MessageCount())
+	for _, msg := range conversation.Messages() {
+		messages = append(messages, services.ClaudeMessage{Role: msg.Role(), Content: msg.Content()})
+	}
+
+	claudeTools := make([]services.ClaudeTool, 0, len(tools))
+	for _, tool := range tools {
+		claudeTools = append(claudeTools, services.ClaudeTool{
+			Name:        tool.Name().String(),
+			Description: tool.Description().String(),
+			InputSchema: tool.InputSchema(),
+		})
+	}
+
+	return &services.ClaudeRequest{
+		Model:         conversation.Model(),
+		SystemPrompt:  conversation.SystemPrompt(),
+		Messages:      messages,
+		MaxTokens:     conversation.MaxTokens(),
+		Temperature:   conversation.Temperature(),
+		TopP:          conversation.TopP(),
+		TopK:          conversation.TopK(),
+		StopSequences: conversation.StopSequences(),
+		Tools:         claudeTools,
+		Reasoning:     conversation.Reasoning(),
+	}
+}
+
+// toolUseBlocks returns the tool calls in content
+func toolUseBlocks(content []entities.ContentBlock) []entities.ContentBlock {
+	var uses []entities.ContentBlock
+	for _, block := range content {
+		if block.Type == vo.ContentTypeToolUse {
+			uses = append(uses, block)
+		}
+	}
+	return uses
+}
+
+// usedTokens returns the input, including cached input, plus output tokens of usage
+func usedTokens(usage services.ClaudeUsage) int {
+	return usage.TotalInputTokens() + usage.OutputTokens
+}
+
+// toolResultText flattens a tool result into the text of a tool_result block
+func toolResultText(result *entities.ToolResult) string {
+	var sb strings.Builder
+	for _, content := range result.Content {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		switch {
+		case content.Text != "":
+			sb.WriteString(content.Text)
+		case content.Type == "image":
+			fmt.Fprintf(&sb, "[image %s]", content.MimeType)
+		case content.URI != "":
+			fmt.Fprintf(&sb, "[resource %s]", content.URI)
+		}
+	}
+
+	text := sb.String()
+	if len(text) > maxAgentToolResultSize {
+		text = strings.ToValidUTF8(text[:maxAgentToolResultSize], "") + "\n... (truncated)"
+	}
+	return text
+}
@@ -0,0 +1,343 @@
+// Package services contains application services for the TelemetryFlow GO MCP service
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// Compaction errors
+var (
+	ErrCompactionSummary = errors.New("conversation summary failed")
+)
+
+// CompactionMetadataKey is the conversation metadata key listing its compactions
+const CompactionMetadataKey = "compactions"
+
+const (
+	// compactedToolResult replaces the content of tool results dropped from the history
+	compactedToolResult = "[Tool result removed to save context]"
+
+	// summaryHeader introduces the summary folded into the first kept message
+	summaryHeader = "[Summary of the earlier conversation]\n"
+
+	// maxCompactionRecords bounds the compactions kept in conversation metadata
+	maxCompactionRecords = 20
+
+	// maxTranscriptValueSize caps each tool input and result in the transcript sent for summary
+	maxTranscriptValueSize = 2000
+)
+
+// summarySystemPrompt instructs the model that writes compaction summaries
+const summarySystemPrompt = "You compress conversations between a user and an observability assistant. " +
+	"Summarize the transcript so the assistant can continue without it: keep every fact, figure, " +
+	"service name, time range, tool finding, decision and open question. Write concise plain prose without preamble."
+
+// CompactionOptions configure context-window management
+type CompactionOptions struct {
+	Threshold        float64  // share of the model's input budget that triggers compaction
+	KeepMessages     int      // most recent messages that are never compacted
+	SummaryModel     vo.Model // writes summaries; the conversation's model when empty
+	SummaryMaxTokens int      // output tokens of a summary
+
+	// ModelChain returns the models an alias may be served by; nil treats every model as itself
+	ModelChain func(model vo.Model) []vo.Model
+}
+
+// CompactionRecord describes one compaction, as kept in conversation metadata
+type CompactionRecord struct {
+	At                 time.Time `json:"at"`
+	ToolResults        int       `json:"tool_results,omitempty"`        // tool results whose content was dropped
+	SummarizedMessages int       `json:"summarized_messages,omitempty"` // messages folded into a summary
+	TokensBefore       int       `json:"tokens_before"`
+	TokensAfter        int       `json:"tokens_after"`
+}
+
+// Compactor keeps conversations within the context window of their model. Over the threshold,
+// it drops the content of older tool results and then summarizes older turns through the LLM
+type Compactor struct {
+	claudeService services.IClaudeService
+	options       CompactionOptions
+}
+
+// NewCompactor creates a compactor, filling unset options with defaults
+func NewCompactor(claudeService services.IClaudeService, options CompactionOptions) *Compactor {
+	if options.Threshold <= 0 || options.Threshold > 1 {
+		options.Threshold = 0.8
+	}
+	if options.KeepMessages <= 0 {
+		options.KeepMessages = 6
+	}
+	if options.SummaryMaxTokens <= 0 {
+		options.SummaryMaxTokens = 1024
+	}
+	return &Compactor{claudeService: claudeService, options: options}
+}
+
+// Fit compacts conversation when request, built from it, exceeds the threshold of the model's
+// input budget, and then rebuilds the request messages. It returns the compaction made, if any
+func (c *Compactor) Fit(ctx context.Context, conversation *aggregates.Conversation, request *services.ClaudeRequest) (*CompactionRecord, error) {
+	capabilities, known := c.capabilities(request.Model)
+	if known {
+		request.MaxTokens = min(request.MaxTokens, capabilities.MaxOutputTokens)
+	}
+	limit := int(float64(capabilities.InputBudget(request.MaxTokens)) * c.options.Threshold)
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	tokens := c.countTokens(ctx, request, limit)
+	if tokens <= limit {
+		return nil, nil
+	}
+	record := &CompactionRecord{At: time.Now().UTC(), TokensBefore: tokens}
+
+	// Old tool results are large and rarely needed again, so they go first
+	messages := conversation.Messages()
+	keepFrom := max(len(messages)-c.options.KeepMessages, 0)
+	if trimmed, dropped := dropToolResults(messages, keepFrom); dropped > 0 {
+		if err := conversation.CompactHistory(trimmed); err != nil {
+			return nil, err
+		}
+		messages = trimmed
+		record.ToolResults = dropped
+		setRequestMessages(request, messages)
+		tokens = c.countTokens(ctx, request, limit)
+	}
+
+	if cut := turnStart(messages, keepFrom); tokens > limit && cut > 0 {
+		summary, err := c.summarize(ctx, request.Model, messages[:cut])
+		if err != nil {
+			return nil, err
+		}
+		compacted := append([]*entities.Message{foldSummary(summary, messages[cut])}, messages[cut+1:]...)
+		if err := conversation.CompactHistory(compacted); err != nil {
+			return nil, err
+		}
+		record.SummarizedMessages = cut
+		setRequestMessages(request, compacted)
+		tokens = c.countTokens(ctx, request, limit)
+	}
+
+	if record.ToolResults == 0 && record.SummarizedMessages == 0 {
+		return nil, nil
+	}
+	record.TokensAfter = tokens
+	recordCompaction(conversation, record)
+	return record, nil
+}
+
+// capabilities returns the capabilities a request can rely on: for an alias, the smallest limits
+// of its chain. It reports whether every model is in the catalog
+func (c *Compactor) capabilities(model vo.Model) (vo.ModelCapabilities, bool) {
+	chain := []vo.Model{model}
+	if c.options.ModelChain != nil {
+		chain = c.options.ModelChain(model)
+	}
+
+	var result vo.ModelCapabilities
+	known := true
+	for i, m := range chain {
+		capabilities, ok := m.CatalogCapabilities()
+		if !ok {
+			capabilities, known = vo.DefaultModelCapabilities, false
+		}
+		if i == 0 {
+			result = capabilities
+			continue
+		}
+		result.ContextWindow = min(result.ContextWindow, capabilities.ContextWindow)
+		result.MaxOutputTokens = min(result.MaxOutputTokens, capabilities.MaxOutputTokens)
+	}
+	return result, known
+}
+
+// countTokens counts the input tokens of request. Requests well under the limit by estimate are
+// not sent to the provider, nor are they when it cannot count
+func (c *Compactor) countTokens(ctx context.Context, request *services.ClaudeRequest, limit int) int {
+	estimate := request.EstimateInputTokens()
+	if estimate < limit/2 {
+		return estimate
+	}
+	tokens, err := c.claudeService.CountTokens(ctx, request)
+	if err != nil || tokens <= 0 {
+		return estimate
+	}
+	return tokens
+}
+
+// summarize asks the LLM for a summary of messages
+func (c *Compactor) summarize(ctx context.Context, model vo.Model, messages []*entities.Message) (string, error) {
+	if c.options.SummaryModel != "" {
+		model = c.options.SummaryModel
+	}
+
+	// The transcript must fit the summary model; its most recent part matters most
+	transcript := renderTranscript(messages)
+	capabilities, _ := c.capabilities(model)
+	if maxChars := (capabilities.InputBudget(c.options.SummaryMaxTokens) - 1000) * 3; maxChars > 0 && len(transcript) > maxChars {
+		transcript = "...\n" + transcript[len(transcript)-maxChars:]
+	}
+
+	systemPrompt, _ := vo.NewSystemPrompt(summarySystemPrompt)
+	response, err := c.claudeService.CreateMessage(ctx, &services.ClaudeRequest{
+		Model:        model,
+		SystemPrompt: systemPrompt,
+		Messages: []services.ClaudeMessage{{
+			Role: vo.RoleUser,
+			Content: []entities.ContentBlock{{
+				Type: vo.ContentTypeText,
+				Text: "Summarize this conversation:\n\n<conversation>\n" + transcript + "</conversation>",
+			}},
+		}},
+		MaxTokens: c.options.SummaryMaxTokens,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrCompactionSummary, err)
+	}
+
+	var summary strings.Builder
+	for _, block := range response.Content {
+		if block.Type == vo.ContentTypeText {
+			summary.WriteString(block.Text)
+		}
+	}
+	if strings.TrimSpace(summary.String()) == "" {
+		return "", fmt.Errorf("%w: empty summary", ErrCompactionSummary)
+	}
+	return strings.TrimSpace(summary.String()), nil
+}
+
+// dropToolResults replaces the content of tool results before keepFrom, returning the new
+// history and the number of results dropped
+func dropToolResults(messages []*entities.Message, keepFrom int) ([]*entities.Message, int) {
+	result := make([]*entities.Message, len(messages))
+	copy(result, messages)
+
+	dropped := 0
+	for i, message := range messages[:keepFrom] {
+		var content []entities.ContentBlock
+		for j, block := range message.Content() {
+			if block.Type != vo.ContentTypeToolResult || len(block.Content) <= len(compactedToolResult) {
+				continue
+			}
+			if content == nil {
+				content = append([]entities.ContentBlock(nil), message.Content()...)
+			}
+			content[j].Content = compactedToolResult
+			dropped++
+		}
+		if content != nil {
+			result[i] = entities.RestoreMessage(message.ID(), message.Role(), content, message.CreatedAt())
+		}
+	}
+	return result, dropped
+}
+
+// turnStart returns the last user turn at or before keepFrom, so that the history cut there keeps
+// every tool_use with its tool_result; 0 when there is none
+func turnStart(messages []*entities.Message, keepFrom int) int {
+	for i := min(keepFrom, len(messages)-1); i > 0; i-- {
+		if messages[i].Role() != vo.RoleUser {
+			continue
+		}
+		isTurn := true
+		for _, block := range messages[i].Content() {
+			if block.Type == vo.ContentTypeToolResult {
+				isTurn = false
+				break
+			}
+		}
+		if isTurn {
+			return i
+		}
+	}
+	return 0
+}
+
+// foldSummary prepends the summary to the first kept message
+func foldSummary(summary string, message *entities.Message) *entities.Message {
+	content := append([]entities.ContentBlock{{Type: vo.ContentTypeText, Text: summaryHeader + summary}}, message.Content()...)
+	return entities.RestoreMessage(message.ID(), message.Role(), content, message.CreatedAt())
+}
+
+// renderTranscript renders messages as plain text for summarizing
+func renderTranscript(messages []*entities.Message) string {
+	var sb strings.Builder
+	for _, message := range messages {
+		speaker := "User"
+		if message.Role() == vo.RoleAssistant {
+			speaker = "Assistant"
+		}
+		for _, block := range message.Content() {
+			switch block.Type {
+			case vo.ContentTypeText:
+				fmt.Fprintf(&sb, "%s: %s\n", speaker, block.Text)
+			case vo.ContentTypeToolUse:
+				input, _ := json.Marshal(block.Input)
+				fmt.Fprintf(&sb, "Assistant called %s with %s\n", block.Name, truncateTranscript(string(input)))
+			case vo.ContentTypeToolResult:
+				fmt.Fprintf(&sb, "Tool result: %s\n", truncateTranscript(block.Content))
+			case vo.ContentTypeImage:
+				fmt.Fprintf(&sb, "%s: [image]\n", speaker)
+			case vo.ContentTypeDocument:
+				fmt.Fprintf(&sb, "%s: [%s]\n", speaker, strings.TrimSpace("document "+block.Name))
+			}
+		}
+	}
+	return sb.String()
+}
+
+func truncateTranscript(value string) string {
+	if len(value) <= maxTranscriptValueSize {
+		return value
+	}
+	return value[:maxTranscriptValueSize] + "..."
+}
+
+// setRequestMessages rebuilds the request messages from a compacted history
+func setRequestMessages(request *services.ClaudeRequest, messages []*entities.Message) {
+	request.Messages = make([]services.ClaudeMessage, len(messages))
+	for i, message := range messages {
+		request.Messages[i] = services.ClaudeMessage{Role: message.Role(), Content: message.Content()}
+	}
+}
+
+// recordCompaction appends record to the conversation's compactions, keeping the latest ones
+func recordCompaction(conversation *aggregates.Conversation, record *CompactionRecord) {
+	var records []interface{}
+	if existing, ok := conversation.GetMetadata(CompactionMetadataKey); ok {
+		records, _ = existing.([]interface{})
+	}
+	records = append(records, *record)
+	if len(records) > maxCompactionRecords {
+		records = records[len(records)-maxCompactionRecords:]
+	}
+	conversation.SetMetadata(CompactionMetadataKey, records)
+}
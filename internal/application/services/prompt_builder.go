@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
 	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
 )
 
@@ -79,6 +80,12 @@ func (pb *PromptBuilder) BuildContextPrompt(ctx *vo.TelemetryContext) string {
 	)
 }
 
+// BuildContextBlock returns the context prompt as a message block marked as a cache breakpoint, so
+// that providers can cache it across the questions asked about the same context
+func (pb *PromptBuilder) BuildContextBlock(ctx *vo.TelemetryContext) entities.ContentBlock {
+	return entities.ContentBlock{Type: vo.ContentTypeText, Text: pb.BuildContextPrompt(ctx), CacheBreakpoint: true}
+}
+
 type InsightType string
 
 const (
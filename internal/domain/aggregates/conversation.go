@@ -20,6 +20,8 @@ package aggregates
 
 import (
 	"errors"
+	"maps"
+	"slices"
 	"sync"
 	"time"
 
@@ -66,6 +68,7 @@ type Conversation struct {
 	topP          float64
 	topK          int
 	stopSequences []string
+	reasoning     *vo.Reasoning
 	tools         []*entities.Tool
 	createdAt     time.Time
 	updatedAt     time.Time
@@ -198,9 +201,62 @@ func (c *Conversation) AddMessage(message *entities.Message) error {
 	return nil
 }
 
-// AddUserMessage adds a user message
-func (c *Conversation) AddUserMessage(text string) (*entities.Message, error) {
-	msg, err := entities.NewTextMessage(vo.RoleUser, text)
+// CompactHistory replaces the message history with a compacted one, such as older turns folded
+// into a summary. The compacted history must still start with a user message
+func (c *Conversation) CompactHistory(messages []*entities.Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.status == ConversationStatusClosed {
+		return ErrConversationClosed
+	}
+	if len(messages) == 0 || messages[0].Role() != vo.RoleUser {
+		return ErrInvalidMessageOrder
+	}
+
+	removed := len(c.messages) - len(messages)
+	c.messages = append([]*entities.Message(nil), messages...)
+	c.updatedAt = time.Now().UTC()
+
+	c.addEvent(events.NewConversationCompactedEvent(c.id, removed))
+	return nil
+}
+
+// ConversationCheckpoint is the history, metadata and pending events of a conversation at one
+// point, taken before a turn so that a failed turn can be undone
+type ConversationCheckpoint struct {
+	messages  []*entities.Message
+	metadata  map[string]interface{}
+	events    int
+	updatedAt time.Time
+}
+
+// Checkpoint records the current state for Rollback
+func (c *Conversation) Checkpoint() ConversationCheckpoint {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return ConversationCheckpoint{
+		messages:  slices.Clone(c.messages),
+		metadata:  maps.Clone(c.metadata),
+		events:    len(c.events),
+		updatedAt: c.updatedAt,
+	}
+}
+
+// Rollback restores the state recorded by checkpoint, dropping the messages and events added since
+func (c *Conversation) Rollback(checkpoint ConversationCheckpoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = slices.Clone(checkpoint.messages)
+	c.metadata = maps.Clone(checkpoint.metadata)
+	c.events = c.events[:min(checkpoint.events, len(c.events))]
+	c.updatedAt = checkpoint.updatedAt
+}
+
+// AddUserMessage adds a user message, with attachments such as images placed before the text
+func (c *Conversation) AddUserMessage(text string, attachments ...entities.ContentBlock) (*entities.Message, error) {
+	content := append(slices.Clone(attachments), entities.ContentBlock{Type: vo.ContentTypeText, Text: text})
+	msg, err := entities.NewMessage(vo.RoleUser, content)
 	if err != nil {
 		return nil, err
 	}
@@ -355,6 +411,21 @@ func (c *Conversation) SetStopSequences(sequences []string) {
 	c.updatedAt = time.Now().UTC()
 }
 
+// Reasoning returns the reasoning setting, nil when the model is not asked to reason
+func (c *Conversation) Reasoning() *vo.Reasoning {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.reasoning
+}
+
+// SetReasoning sets the reasoning of later requests; nil stops asking for it
+func (c *Conversation) SetReasoning(reasoning *vo.Reasoning) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reasoning = reasoning
+	c.updatedAt = time.Now().UTC()
+}
+
 // Tools returns the available tools
 func (c *Conversation) Tools() []*entities.Tool {
 	c.mu.RLock()
@@ -487,6 +558,11 @@ func (c *Conversation) GetMessagesForAPI() []map[string]interface{} {
 				if block.IsError {
 					contentBlock["is_error"] = block.IsError
 				}
+			case vo.ContentTypeThinking:
+				contentBlock["thinking"] = block.Thinking
+				contentBlock["signature"] = block.Signature
+			case vo.ContentTypeRedactedThinking:
+				contentBlock["data"] = block.Data
 			}
 			content[j] = contentBlock
 		}
@@ -523,3 +599,53 @@ func RestoreConversation(
 		events:    make([]events.DomainEvent, 0),
 	}
 }
+
+// ConversationSettings are the persisted request settings of a conversation
+type ConversationSettings struct {
+	SystemPrompt  vo.SystemPrompt
+	MaxTokens     int
+	Temperature   float64
+	TopP          float64
+	TopK          int
+	StopSequences []string
+	Reasoning     *vo.Reasoning
+	Metadata      map[string]interface{}
+}
+
+// Settings returns the request settings of the conversation
+func (c *Conversation) Settings() ConversationSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	metadata := make(map[string]interface{}, len(c.metadata))
+	for key, value := range c.metadata {
+		metadata[key] = value
+	}
+	return ConversationSettings{
+		SystemPrompt:  c.systemPrompt,
+		MaxTokens:     c.maxTokens,
+		Temperature:   c.temperature,
+		TopP:          c.topP,
+		TopK:          c.topK,
+		StopSequences: c.stopSequences,
+		Reasoning:     c.reasoning,
+		Metadata:      metadata,
+	}
+}
+
+// RestoreState sets the persisted settings and messages of a restored conversation, without
+// validating message order or recording events
+func (c *Conversation) RestoreState(settings ConversationSettings, messages []*entities.Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.systemPrompt = settings.SystemPrompt
+	c.maxTokens = settings.MaxTokens
+	c.temperature = settings.Temperature
+	c.topP = settings.TopP
+	c.topK = settings.TopK
+	c.stopSequences = settings.StopSequences
+	c.reasoning = settings.Reasoning
+	for key, value := range settings.Metadata {
+		c.metadata[key] = value
+	}
+	c.messages = append(c.messages[:0], messages...)
+}
@@ -87,6 +87,7 @@ type SessionCapabilities struct {
 	Resources    *ResourcesCapability   `json:"resources,omitempty"`
 	Prompts      *PromptsCapability     `json:"prompts,omitempty"`
 	Logging      *LoggingCapability     `json:"logging,omitempty"`
+	Tasks        *TasksCapability       `json:"tasks,omitempty"`
 	Experimental map[string]interface{} `json:"experimental,omitempty"`
 }
 
@@ -109,6 +110,13 @@ type PromptsCapability struct {
 // LoggingCapability represents logging capability
 type LoggingCapability struct{}
 
+// TasksCapability represents tasks capability
+type TasksCapability struct {
+	List     struct{}               `json:"list"`
+	Cancel   struct{}               `json:"cancel"`
+	Requests map[string]interface{} `json:"requests"`
+}
+
 // NewSession creates a new Session aggregate
 func NewSession() *Session {
 	now := time.Now().UTC()
@@ -172,6 +180,27 @@ func (s *Session) Capabilities() *SessionCapabilities {
 	return s.capabilities
 }
 
+// EnableTasks advertises task-augmented tools/call support
+func (s *Session) EnableTasks() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.capabilities.Tasks = &TasksCapability{
+		Requests: map[string]interface{}{
+			"tools": map[string]interface{}{"call": map[string]interface{}{}},
+		},
+	}
+}
+
+// SetExperimental advertises a non-standard capability under capabilities.experimental
+func (s *Session) SetExperimental(name string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.capabilities.Experimental == nil {
+		s.capabilities.Experimental = make(map[string]interface{})
+	}
+	s.capabilities.Experimental[name] = value
+}
+
 // Initialize initializes the session with client info
 func (s *Session) Initialize(clientInfo *ClientInfo, protocolVersion string) error {
 	s.mu.Lock()
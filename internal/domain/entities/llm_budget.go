@@ -0,0 +1,230 @@
+// Package entities contains domain entities for the TelemetryFlow GO MCP service
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// LLM budget errors
+var (
+	ErrBudgetExceeded            = errors.New("LLM budget exceeded")
+	ErrBudgetWithoutOrganization = errors.New("budget organization_id is required")
+	ErrInvalidBudgetPeriod       = errors.New("budget period must be daily or monthly")
+	ErrBudgetWithoutLimit        = errors.New("budget must limit tokens or cost")
+	ErrInvalidBudgetLimit        = errors.New("budget limits must not be negative")
+	ErrInvalidBudgetWarnAt       = errors.New("budget warn_at must be between 0 and 1")
+)
+
+// BudgetPeriod is the window after which an LLM budget starts over
+type BudgetPeriod string
+
+// Budget periods; windows start at midnight UTC
+const (
+	BudgetDaily   BudgetPeriod = "daily"
+	BudgetMonthly BudgetPeriod = "monthly"
+)
+
+// IsValid reports whether the period is supported
+func (p BudgetPeriod) IsValid() bool {
+	return p == BudgetDaily || p == BudgetMonthly
+}
+
+// Window returns the start and end of the period containing t
+func (p BudgetPeriod) Window(t time.Time) (start, end time.Time) {
+	t = t.UTC()
+	if p == BudgetMonthly {
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	}
+	start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// BudgetLimit names the limit of a budget
+type BudgetLimit string
+
+// Budget limits
+const (
+	BudgetLimitTokens BudgetLimit = "tokens"
+	BudgetLimitCost   BudgetLimit = "cost"
+)
+
+// BudgetUsage is what an organization has spent of a budget in the current period
+type BudgetUsage struct {
+	Tokens  int64
+	CostUSD float64
+}
+
+// Add returns the sum of two usages
+func (u BudgetUsage) Add(other BudgetUsage) BudgetUsage {
+	return BudgetUsage{Tokens: u.Tokens + other.Tokens, CostUSD: u.CostUSD + other.CostUSD}
+}
+
+// Sub returns the difference of two usages
+func (u BudgetUsage) Sub(other BudgetUsage) BudgetUsage {
+	return BudgetUsage{Tokens: u.Tokens - other.Tokens, CostUSD: u.CostUSD - other.CostUSD}
+}
+
+// LLMBudget caps the LLM tokens and cost an organization may spend per period
+type LLMBudget struct {
+	ID             string
+	OrganizationID string
+	Period         BudgetPeriod
+	TokenLimit     int64   // 0 leaves tokens unlimited
+	CostLimitUSD   float64 // 0 leaves cost unlimited
+	WarnAt         float64 // share of a limit that sends a warning; 0 sends none
+	HardLimit      bool    // reject calls over the limit; otherwise only notify
+	WebhookURL     string  // receives warning and exceeded events
+	NotifyEmails   []string
+	Enabled        bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// NewLLMBudget creates an enabled hard budget that warns at 80%
+func NewLLMBudget(organizationID string, period BudgetPeriod) *LLMBudget {
+	now := time.Now().UTC()
+	return &LLMBudget{
+		ID:             uuid.New().String(),
+		OrganizationID: organizationID,
+		Period:         period,
+		WarnAt:         0.8,
+		HardLimit:      true,
+		Enabled:        true,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// Validate checks the budget definition
+func (b *LLMBudget) Validate() error {
+	switch {
+	case b.OrganizationID == "":
+		return ErrBudgetWithoutOrganization
+	case !b.Period.IsValid():
+		return ErrInvalidBudgetPeriod
+	case b.TokenLimit < 0 || b.CostLimitUSD < 0:
+		return ErrInvalidBudgetLimit
+	case b.TokenLimit == 0 && b.CostLimitUSD == 0:
+		return ErrBudgetWithoutLimit
+	case b.WarnAt < 0 || b.WarnAt >= 1:
+		return ErrInvalidBudgetWarnAt
+	}
+	return nil
+}
+
+// Exceeded returns the first limit that usage is over, or "" when it is within the budget
+func (b *LLMBudget) Exceeded(usage BudgetUsage) BudgetLimit {
+	if b.TokenLimit > 0 && usage.Tokens > b.TokenLimit {
+		return BudgetLimitTokens
+	}
+	if b.CostLimitUSD > 0 && usage.CostUSD > b.CostLimitUSD {
+		return BudgetLimitCost
+	}
+	return ""
+}
+
+// Used returns the largest share of a limit that usage takes up
+func (b *LLMBudget) Used(usage BudgetUsage) float64 {
+	var used float64
+	if b.TokenLimit > 0 {
+		used = float64(usage.Tokens) / float64(b.TokenLimit)
+	}
+	if b.CostLimitUSD > 0 {
+		used = max(used, usage.CostUSD/b.CostLimitUSD)
+	}
+	return used
+}
+
+// Max returns the value of a limit: tokens or USD
+func (b *LLMBudget) Max(limit BudgetLimit) float64 {
+	if limit == BudgetLimitTokens {
+		return float64(b.TokenLimit)
+	}
+	return b.CostLimitUSD
+}
+
+// BudgetExceededError rejects an LLM call that would take an organization over a hard budget
+type BudgetExceededError struct {
+	OrganizationID string
+	Period         BudgetPeriod
+	Limit          BudgetLimit
+	Used           float64 // spent before the call, in tokens or USD
+	Requested      float64 // estimated for the call
+	Max            float64
+	ResetsAt       time.Time
+}
+
+// NewBudgetExceededError describes the rejection of a call estimated at requested by budget
+func NewBudgetExceededError(budget *LLMBudget, limit BudgetLimit, used, requested BudgetUsage, resetsAt time.Time) *BudgetExceededError {
+	e := &BudgetExceededError{
+		OrganizationID: budget.OrganizationID,
+		Period:         budget.Period,
+		Limit:          limit,
+		Max:            budget.Max(limit),
+		ResetsAt:       resetsAt.UTC(),
+	}
+	if limit == BudgetLimitTokens {
+		e.Used, e.Requested = float64(used.Tokens), float64(requested.Tokens)
+	} else {
+		e.Used, e.Requested = used.CostUSD, requested.CostUSD
+	}
+	return e
+}
+
+// Error returns the message shown to the client
+func (e *BudgetExceededError) Error() string {
+	format := func(v float64) string { return fmt.Sprintf("%.0f tokens", v) }
+	if e.Limit == BudgetLimitCost {
+		format = func(v float64) string { return fmt.Sprintf("$%.2f", v) }
+	}
+	return fmt.Sprintf("%s: organization %s has used %s of its %s %s budget and this call needs up to %s; the budget resets at %s",
+		ErrBudgetExceeded, e.OrganizationID, format(e.Used), e.Period, format(e.Max), format(e.Requested),
+		e.ResetsAt.Format(time.RFC3339))
+}
+
+// Unwrap lets errors.Is match ErrBudgetExceeded
+func (e *BudgetExceededError) Unwrap() error {
+	return ErrBudgetExceeded
+}
+
+// MCPErrorCode returns the error code reported to MCP clients
+func (e *BudgetExceededError) MCPErrorCode() vo.MCPErrorCode {
+	return vo.ErrorCodeBudgetExceeded
+}
+
+// ErrorDetails returns the budget state for the error data of MCP clients
+func (e *BudgetExceededError) ErrorDetails() map[string]interface{} {
+	return map[string]interface{}{
+		"organizationId": e.OrganizationID,
+		"period":         string(e.Period),
+		"limit":          string(e.Limit),
+		"used":           e.Used,
+		"requested":      e.Requested,
+		"max":            e.Max,
+		"resetsAt":       e.ResetsAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,108 @@
+// Package entities contains domain entities for the TelemetryFlow GO MCP service
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// LLMCallStatus is the outcome of a metered LLM call
+type LLMCallStatus string
+
+// LLM call statuses
+const (
+	LLMCallSuccess   LLMCallStatus = "success"
+	LLMCallError     LLMCallStatus = "error"
+	LLMCallCancelled LLMCallStatus = "cancelled"
+)
+
+// LLMUsage is the usage record of a single LLM call
+type LLMUsage struct {
+	ID             string
+	SessionID      string
+	ConversationID string
+	OrganizationID string
+	RequestedModel vo.Model // model or alias named by the caller
+	Model          vo.Model // model that was called
+	Provider       vo.Provider
+
+	InputTokens      int // input tokens outside the prompt cache
+	OutputTokens     int
+	CacheReadTokens  int
+	CacheWriteTokens int
+	ReasoningTokens  int // output tokens spent reasoning, when the provider reports them
+
+	Latency      time.Duration
+	Status       LLMCallStatus
+	StatusCode   int // HTTP status of the provider, 0 when unknown
+	ErrorMessage string
+	StopReason   string
+	Streaming    bool
+
+	CostUSD        float64
+	PricingVersion string // price table the cost was computed with; empty when the model has no price
+	CalledAt       time.Time
+}
+
+// NewLLMUsage creates a usage record for a call to model starting now
+func NewLLMUsage(requested, model vo.Model) *LLMUsage {
+	return &LLMUsage{
+		ID:             uuid.New().String(),
+		RequestedModel: requested,
+		Model:          model,
+		Provider:       model.Provider(),
+		Status:         LLMCallSuccess,
+		CalledAt:       time.Now().UTC(),
+	}
+}
+
+// TotalTokens returns the input, cache and output tokens of the call
+func (u *LLMUsage) TotalTokens() int {
+	return u.InputTokens + u.CacheReadTokens + u.CacheWriteTokens + u.OutputTokens
+}
+
+// IsError reports whether the call did not succeed
+func (u *LLMUsage) IsError() bool {
+	return u.Status != LLMCallSuccess
+}
+
+// UsageScope identifies whom LLM calls are made for
+type UsageScope struct {
+	SessionID      string
+	ConversationID string
+	OrganizationID string
+}
+
+type usageScopeKey struct{}
+
+// WithUsageScope returns a context carrying the given usage scope
+func WithUsageScope(ctx context.Context, scope UsageScope) context.Context {
+	return context.WithValue(ctx, usageScopeKey{}, scope)
+}
+
+// UsageScopeFromContext returns the usage scope in ctx; the zero scope when there is none
+func UsageScopeFromContext(ctx context.Context) UsageScope {
+	scope, _ := ctx.Value(usageScopeKey{}).(UsageScope)
+	return scope
+}
@@ -19,6 +19,8 @@
 package entities
 
 import (
+	"encoding/base64"
+	"strings"
 	"time"
 
 	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
@@ -38,15 +40,24 @@ type ContentBlock struct {
 	Type      vo.ContentType         `json:"type"`
 	Text      string                 `json:"text,omitempty"`
 	ID        string                 `json:"id,omitempty"`          // For tool_use
-	Name      string                 `json:"name,omitempty"`        // For tool_use
+	Name      string                 `json:"name,omitempty"`        // For tool_use, or the file name of a document
 	Input     map[string]interface{} `json:"input,omitempty"`       // For tool_use
 	ToolUseID string                 `json:"tool_use_id,omitempty"` // For tool_result
 	Content   string                 `json:"content,omitempty"`     // For tool_result
 	IsError   bool                   `json:"is_error,omitempty"`    // For tool_result
-	Source    *ImageSource           `json:"source,omitempty"`      // For image
+	Source    *ImageSource           `json:"source,omitempty"`      // For image and document
+	Thinking  string                 `json:"thinking,omitempty"`    // For thinking
+	Data      string                 `json:"data,omitempty"`        // For redacted_thinking, encrypted by the provider
+
+	// Signature lets the provider verify reasoning sent back to it: the signature of a thinking block,
+	// or the thought signature of the reasoning that led to a tool_use
+	Signature string `json:"signature,omitempty"`
+
+	// CacheBreakpoint marks the end of a stable prefix, such as telemetry context, that providers may cache
+	CacheBreakpoint bool `json:"cache_breakpoint,omitempty"`
 }
 
-// ImageSource represents an image source
+// ImageSource represents the source of an image or document
 type ImageSource struct {
 	Type      string `json:"type"` // "base64" or "url"
 	MediaType string `json:"media_type,omitempty"`
@@ -54,6 +65,23 @@ type ImageSource struct {
 	URL       string `json:"url,omitempty"`
 }
 
+// NewMediaBlock creates an image or document block holding data, after checking its media type and
+// size. name is the file name of a document
+func NewMediaBlock(mediaType string, data []byte, name string) (ContentBlock, error) {
+	contentType, err := vo.CheckMedia(mediaType, len(data))
+	if err != nil {
+		return ContentBlock{}, err
+	}
+	block := ContentBlock{
+		Type:   contentType,
+		Source: &ImageSource{Type: "base64", MediaType: mediaType, Data: base64.StdEncoding.EncodeToString(data)},
+	}
+	if contentType == vo.ContentTypeDocument {
+		block.Name = name
+	}
+	return block, nil
+}
+
 // NewMessage creates a new Message entity
 func NewMessage(role vo.Role, content []ContentBlock) (*Message, error) {
 	if !role.IsValid() {
@@ -69,6 +97,17 @@ func NewMessage(role vo.Role, content []ContentBlock) (*Message, error) {
 	}, nil
 }
 
+// RestoreMessage reconstructs a Message entity from persisted data
+func RestoreMessage(id vo.MessageID, role vo.Role, content []ContentBlock, createdAt time.Time) *Message {
+	return &Message{
+		id:        id,
+		role:      role,
+		content:   content,
+		createdAt: createdAt,
+		metadata:  make(map[string]interface{}),
+	}
+}
+
 // NewTextMessage creates a new text message
 func NewTextMessage(role vo.Role, text string) (*Message, error) {
 	content := []ContentBlock{
@@ -148,6 +187,22 @@ func (m *Message) GetToolUseBlocks() []ContentBlock {
 	return toolUses
 }
 
+// GetReasoning returns the thinking text of the message; redacted reasoning is left out
+func (m *Message) GetReasoning() string {
+	return ReasoningText(m.content)
+}
+
+// ReasoningText joins the thinking text of blocks; redacted reasoning is left out
+func ReasoningText(blocks []ContentBlock) string {
+	var thinking []string
+	for _, block := range blocks {
+		if block.Type == vo.ContentTypeThinking && block.Thinking != "" {
+			thinking = append(thinking, block.Thinking)
+		}
+	}
+	return strings.Join(thinking, "\n\n")
+}
+
 // AddContent adds a content block to the message
 func (m *Message) AddContent(block ContentBlock) {
 	m.content = append(m.content, block)
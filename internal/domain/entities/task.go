@@ -0,0 +1,181 @@
+// Package entities contains domain entities for the TelemetryFlow GO MCP service
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// Task represents a tool call running in the background
+type Task struct {
+	id            string
+	sessionID     vo.SessionID
+	toolName      string
+	arguments     map[string]interface{}
+	status        vo.MCPTaskStatus
+	statusMessage string
+	result        *ToolResult
+	ttl           time.Duration
+	pollInterval  time.Duration
+	createdAt     time.Time
+	updatedAt     time.Time
+}
+
+// NewTask creates a new working Task for a tool call
+func NewTask(sessionID vo.SessionID, toolName string, arguments map[string]interface{}, ttl, pollInterval time.Duration) *Task {
+	now := time.Now().UTC()
+	return &Task{
+		id:           uuid.New().String(),
+		sessionID:    sessionID,
+		toolName:     toolName,
+		arguments:    arguments,
+		status:       vo.TaskStatusWorking,
+		ttl:          ttl,
+		pollInterval: pollInterval,
+		createdAt:    now,
+		updatedAt:    now,
+	}
+}
+
+// ID returns the task ID
+func (t *Task) ID() string {
+	return t.id
+}
+
+// SessionID returns the session that created the task
+func (t *Task) SessionID() vo.SessionID {
+	return t.sessionID
+}
+
+// ToolName returns the name of the tool being called
+func (t *Task) ToolName() string {
+	return t.toolName
+}
+
+// Arguments returns the tool call arguments
+func (t *Task) Arguments() map[string]interface{} {
+	return t.arguments
+}
+
+// Status returns the task status
+func (t *Task) Status() vo.MCPTaskStatus {
+	return t.status
+}
+
+// StatusMessage returns the latest human-readable status message
+func (t *Task) StatusMessage() string {
+	return t.statusMessage
+}
+
+// SetStatusMessage updates the status message while the task is running
+func (t *Task) SetStatusMessage(message string) {
+	if t.status.IsTerminal() {
+		return
+	}
+	t.statusMessage = message
+	t.updatedAt = time.Now().UTC()
+}
+
+// Result returns the tool result once the task has finished
+func (t *Task) Result() *ToolResult {
+	return t.result
+}
+
+// TTL returns how long the task is retained after creation
+func (t *Task) TTL() time.Duration {
+	return t.ttl
+}
+
+// PollInterval returns the suggested polling interval
+func (t *Task) PollInterval() time.Duration {
+	return t.pollInterval
+}
+
+// CreatedAt returns the creation timestamp
+func (t *Task) CreatedAt() time.Time {
+	return t.createdAt
+}
+
+// UpdatedAt returns the last update timestamp
+func (t *Task) UpdatedAt() time.Time {
+	return t.updatedAt
+}
+
+// IsTerminal checks if the task has finished
+func (t *Task) IsTerminal() bool {
+	return t.status.IsTerminal()
+}
+
+// IsExpired checks if the task has outlived its TTL
+func (t *Task) IsExpired(now time.Time) bool {
+	return t.ttl > 0 && now.Sub(t.createdAt) > t.ttl
+}
+
+// Complete records the tool result; error results mark the task as failed
+func (t *Task) Complete(result *ToolResult) bool {
+	status := vo.TaskStatusCompleted
+	if result != nil && result.IsError {
+		status = vo.TaskStatusFailed
+	}
+	return t.finish(status, result, "")
+}
+
+// Fail marks the task as failed with a reason
+func (t *Task) Fail(reason string) bool {
+	return t.finish(vo.TaskStatusFailed, nil, reason)
+}
+
+// Cancel marks the task as cancelled
+func (t *Task) Cancel(reason string) bool {
+	return t.finish(vo.TaskStatusCancelled, nil, reason)
+}
+
+// finish moves the task to a terminal status, reporting false if it already finished
+func (t *Task) finish(status vo.MCPTaskStatus, result *ToolResult, message string) bool {
+	if t.status.IsTerminal() {
+		return false
+	}
+	t.status = status
+	t.result = result
+	t.statusMessage = message
+	t.updatedAt = time.Now().UTC()
+	return true
+}
+
+// ToMCPTask converts the task to MCP format
+func (t *Task) ToMCPTask() map[string]interface{} {
+	task := map[string]interface{}{
+		"taskId":        t.id,
+		"status":        t.status.String(),
+		"createdAt":     t.createdAt.Format(time.RFC3339Nano),
+		"lastUpdatedAt": t.updatedAt.Format(time.RFC3339Nano),
+		"ttl":           t.ttl.Milliseconds(),
+	}
+	if t.pollInterval > 0 {
+		task["pollInterval"] = t.pollInterval.Milliseconds()
+	}
+	if t.statusMessage != "" {
+		task["statusMessage"] = t.statusMessage
+	}
+	return task
+}
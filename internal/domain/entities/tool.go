@@ -19,7 +19,9 @@
 package entities
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"time"
 
 	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
@@ -31,6 +33,7 @@ type Tool struct {
 	description vo.ToolDescription
 	inputSchema *JSONSchema
 	handler     ToolHandler
+	ctxHandler  ContextToolHandler
 	category    string
 	tags        []string
 	isEnabled   bool
@@ -44,6 +47,9 @@ type Tool struct {
 // ToolHandler is the function signature for tool execution
 type ToolHandler func(input map[string]interface{}) (*ToolResult, error)
 
+// ContextToolHandler is a tool handler that observes cancellation and can report progress
+type ContextToolHandler func(ctx context.Context, input map[string]interface{}) (*ToolResult, error)
+
 // JSONSchema represents a JSON Schema for tool input validation
 type JSONSchema struct {
 	Type                 string                 `json:"type"`
@@ -64,8 +70,9 @@ type JSONSchema struct {
 
 // ToolResult represents the result of a tool execution
 type ToolResult struct {
-	Content []ToolResultContent `json:"content"`
-	IsError bool                `json:"isError,omitempty"`
+	Content []ToolResultContent    `json:"content"`
+	IsError bool                   `json:"isError,omitempty"`
+	Meta    map[string]interface{} `json:"_meta,omitempty"`
 }
 
 // ToolResultContent represents content in a tool result
@@ -75,6 +82,7 @@ type ToolResultContent struct {
 	Data     string `json:"data,omitempty"`     // For image (base64)
 	MimeType string `json:"mimeType,omitempty"` // For image
 	URI      string `json:"uri,omitempty"`      // For resource
+	Blob     string `json:"blob,omitempty"`     // For binary resource (base64)
 }
 
 // RateLimit represents rate limiting configuration for a tool
@@ -122,6 +130,7 @@ func (t *Tool) Handler() ToolHandler {
 // SetHandler sets the tool handler
 func (t *Tool) SetHandler(handler ToolHandler) {
 	t.handler = handler
+	t.ctxHandler = nil
 	t.updatedAt = time.Now().UTC()
 }
 
@@ -213,6 +222,23 @@ func (t *Tool) SetMetadata(key string, value interface{}) {
 	t.updatedAt = time.Now().UTC()
 }
 
+// SetContextHandler sets a context-aware handler; Handler() falls back to a background context
+func (t *Tool) SetContextHandler(handler ContextToolHandler) {
+	t.ctxHandler = handler
+	t.handler = func(input map[string]interface{}) (*ToolResult, error) {
+		return handler(context.Background(), input)
+	}
+	t.updatedAt = time.Now().UTC()
+}
+
+// ExecuteContext executes the tool, passing ctx to context-aware handlers
+func (t *Tool) ExecuteContext(ctx context.Context, input map[string]interface{}) (*ToolResult, error) {
+	if t.ctxHandler != nil {
+		return t.ctxHandler(ctx, input)
+	}
+	return t.Execute(input)
+}
+
 // Execute executes the tool with the given input
 func (t *Tool) Execute(input map[string]interface{}) (*ToolResult, error) {
 	if t.handler == nil {
@@ -250,14 +276,33 @@ func NewTextToolResult(text string) *ToolResult {
 	}
 }
 
-// NewErrorToolResult creates an error tool result
+// CodedError is implemented by errors that carry an MCP error code and details for clients
+type CodedError interface {
+	error
+	MCPErrorCode() vo.MCPErrorCode
+	ErrorDetails() map[string]interface{}
+}
+
+// NewErrorToolResult creates an error tool result; the code and details of a CodedError are
+// reported in _meta.error
 func NewErrorToolResult(err error) *ToolResult {
-	return &ToolResult{
+	result := &ToolResult{
 		Content: []ToolResultContent{
 			{Type: "text", Text: err.Error()},
 		},
 		IsError: true,
 	}
+	var coded CodedError
+	if errors.As(err, &coded) {
+		result.Meta = map[string]interface{}{
+			"error": map[string]interface{}{
+				"code":    int(coded.MCPErrorCode()),
+				"message": coded.MCPErrorCode().Message(),
+				"data":    coded.ErrorDetails(),
+			},
+		}
+	}
+	return result
 }
 
 // NewImageToolResult creates an image tool result
@@ -269,6 +314,15 @@ func NewImageToolResult(data, mimeType string) *ToolResult {
 	}
 }
 
+// NewBlobToolResult creates a binary resource tool result from base64 data
+func NewBlobToolResult(uri, blob, mimeType string) *ToolResult {
+	return &ToolResult{
+		Content: []ToolResultContent{
+			{Type: "resource", URI: uri, Blob: blob, MimeType: mimeType},
+		},
+	}
+}
+
 // NewResourceToolResult creates a resource tool result
 func NewResourceToolResult(uri, text, mimeType string) *ToolResult {
 	return &ToolResult{
@@ -277,3 +331,11 @@ func NewResourceToolResult(uri, text, mimeType string) *ToolResult {
 		},
 	}
 }
+
+// SetMeta sets a result metadata value returned to the client in _meta
+func (r *ToolResult) SetMeta(key string, value interface{}) {
+	if r.Meta == nil {
+		r.Meta = make(map[string]interface{})
+	}
+	r.Meta[key] = value
+}
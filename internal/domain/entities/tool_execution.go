@@ -0,0 +1,69 @@
+// Package entities contains domain entities for the TelemetryFlow GO MCP service
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ToolExecutionStatus is the outcome of an audited tool call
+type ToolExecutionStatus string
+
+// Tool execution statuses
+const (
+	ToolExecutionSuccess   ToolExecutionStatus = "success"
+	ToolExecutionError     ToolExecutionStatus = "error"
+	ToolExecutionTimeout   ToolExecutionStatus = "timeout"
+	ToolExecutionCancelled ToolExecutionStatus = "cancelled"
+	ToolExecutionRejected  ToolExecutionStatus = "rejected" // unknown or disabled tool
+)
+
+// ToolExecution is an audit record of a single tool call
+type ToolExecution struct {
+	ID            string
+	SessionID     string
+	ClientName    string
+	ClientVersion string
+	APIKeyID      string // identifies the caller's API key, never the key itself
+	ToolName      string
+	Arguments     map[string]interface{}
+	Status        ToolExecutionStatus
+	ErrorMessage  string
+	Duration      time.Duration
+	ExecutedAt    time.Time
+}
+
+// NewToolExecution creates an audit record for a call starting now
+func NewToolExecution(sessionID, toolName string, arguments map[string]interface{}) *ToolExecution {
+	return &ToolExecution{
+		ID:         uuid.New().String(),
+		SessionID:  sessionID,
+		ToolName:   toolName,
+		Arguments:  arguments,
+		Status:     ToolExecutionSuccess,
+		ExecutedAt: time.Now().UTC(),
+	}
+}
+
+// IsError reports whether the call did not succeed
+func (e *ToolExecution) IsError() bool {
+	return e.Status != ToolExecutionSuccess
+}
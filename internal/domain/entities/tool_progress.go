@@ -0,0 +1,51 @@
+// Package entities contains domain entities for the TelemetryFlow GO MCP service
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import "context"
+
+// ToolProgress is a single progress update emitted by a running tool
+type ToolProgress struct {
+	Progress float64
+	Total    float64
+	Message  string
+}
+
+// ProgressReporter receives progress updates from a running tool
+type ProgressReporter func(update ToolProgress)
+
+type progressReporterKey struct{}
+
+// WithProgressReporter returns a context carrying the given progress reporter
+func WithProgressReporter(ctx context.Context, reporter ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressReporterKey{}, reporter)
+}
+
+// ProgressReporterFromContext returns the progress reporter in ctx, if any
+func ProgressReporterFromContext(ctx context.Context) (ProgressReporter, bool) {
+	reporter, ok := ctx.Value(progressReporterKey{}).(ProgressReporter)
+	return reporter, ok && reporter != nil
+}
+
+// ReportProgress sends a progress update if the caller requested progress; otherwise it is a no-op
+func ReportProgress(ctx context.Context, update ToolProgress) {
+	if reporter, ok := ProgressReporterFromContext(ctx); ok {
+		reporter(update)
+	}
+}
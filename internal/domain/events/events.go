@@ -192,6 +192,26 @@ func NewConversationClosedEvent(conversationID vo.ConversationID) *ConversationC
 
 // Message Events
 
+// ConversationCompactedEvent is emitted when a conversation's history is compacted
+type ConversationCompactedEvent struct {
+	BaseEvent
+}
+
+// NewConversationCompactedEvent creates a new ConversationCompactedEvent
+func NewConversationCompactedEvent(conversationID vo.ConversationID, removedMessages int) *ConversationCompactedEvent {
+	return &ConversationCompactedEvent{
+		BaseEvent: newBaseEvent(
+			"conversation.compacted",
+			conversationID.String(),
+			"Conversation",
+			map[string]interface{}{
+				"conversationId":  conversationID.String(),
+				"removedMessages": removedMessages,
+			},
+		),
+	}
+}
+
 // MessageAddedEvent is emitted when a message is added to a conversation
 type MessageAddedEvent struct {
 	BaseEvent
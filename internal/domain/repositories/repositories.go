@@ -20,6 +20,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
@@ -107,6 +108,95 @@ type IToolRepository interface {
 	Count(ctx context.Context) (int, error)
 }
 
+// ToolExecutionFilter selects audited tool calls; zero fields match everything
+type ToolExecutionFilter struct {
+	SessionID string
+	ToolName  string
+	APIKeyID  string
+	Status    entities.ToolExecutionStatus
+	Since     time.Time
+	Until     time.Time
+	Limit     int
+}
+
+// IToolExecutionRepository defines the interface for the tool call audit trail
+type IToolExecutionRepository interface {
+	// Save persists a tool execution record
+	Save(ctx context.Context, execution *entities.ToolExecution) error
+
+	// Search retrieves records matching the filter, newest first
+	Search(ctx context.Context, filter ToolExecutionFilter) ([]*entities.ToolExecution, error)
+
+	// DeleteBefore removes records executed before the given time and returns how many were removed
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// LLMUsageGroup is the dimension LLM usage is summarized by
+type LLMUsageGroup string
+
+// LLM usage groups
+const (
+	LLMUsageByModel        LLMUsageGroup = "model"
+	LLMUsageByProvider     LLMUsageGroup = "provider"
+	LLMUsageBySession      LLMUsageGroup = "session"
+	LLMUsageByConversation LLMUsageGroup = "conversation"
+	LLMUsageByOrganization LLMUsageGroup = "organization"
+	LLMUsageByDay          LLMUsageGroup = "day" // UTC calendar day, as YYYY-MM-DD
+)
+
+// LLMUsageFilter selects metered LLM calls; zero fields match everything
+type LLMUsageFilter struct {
+	SessionID      string
+	ConversationID string
+	OrganizationID string
+	Model          vo.Model
+	Provider       vo.Provider
+	Since          time.Time
+	Until          time.Time
+
+	// GroupBy splits the summary by a dimension; empty gives a single total
+	GroupBy LLMUsageGroup
+}
+
+// LLMUsageSummary aggregates the usage records of one group
+type LLMUsageSummary struct {
+	Group            string // value of the grouped dimension; empty for the total
+	Requests         int64
+	Errors           int64
+	InputTokens      int64
+	OutputTokens     int64
+	CacheReadTokens  int64
+	CacheWriteTokens int64
+	ReasoningTokens  int64 // part of OutputTokens
+	CostUSD          float64
+	UnpricedRequests int64 // calls to models without a price, which add no cost
+	AvgLatency       time.Duration
+}
+
+// ILLMUsageRepository defines the interface for LLM usage and cost accounting
+type ILLMUsageRepository interface {
+	// Save persists a usage record
+	Save(ctx context.Context, usage *entities.LLMUsage) error
+
+	// Summarize aggregates the records matching the filter, most expensive group first
+	Summarize(ctx context.Context, filter LLMUsageFilter) ([]*LLMUsageSummary, error)
+}
+
+// ILLMBudgetRepository defines the interface for per-organization LLM budgets
+type ILLMBudgetRepository interface {
+	// Save creates or replaces the budget of an organization for its period
+	Save(ctx context.Context, budget *entities.LLMBudget) error
+
+	// FindByOrganization retrieves the budgets of an organization
+	FindByOrganization(ctx context.Context, organizationID string) ([]*entities.LLMBudget, error)
+
+	// List retrieves all budgets ordered by organization and period
+	List(ctx context.Context) ([]*entities.LLMBudget, error)
+
+	// Delete removes a budget
+	Delete(ctx context.Context, id string) error
+}
+
 // IResourceRepository defines the interface for resource registry
 type IResourceRepository interface {
 	// Register registers a resource
@@ -20,6 +20,9 @@ package services
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
@@ -39,6 +42,129 @@ type ClaudeRequest struct {
 	Tools         []ClaudeTool
 	Stream        bool
 	Metadata      map[string]interface{}
+
+	// Reasoning asks the model to reason before it answers; models the catalog lists without
+	// reasoning ignore it
+	Reasoning *vo.Reasoning
+}
+
+// ReasoningEnabled reports whether the request asks for reasoning from a model that may reason
+func (r *ClaudeRequest) ReasoningEnabled() bool {
+	if !r.Reasoning.IsEnabled() {
+		return false
+	}
+	capabilities, ok := r.Model.CatalogCapabilities()
+	return !ok || capabilities.Reasoning
+}
+
+// OutputTokens returns the output tokens to ask the provider for and the reasoning budget within
+// them. Reasoning counts toward the output, so its budget is added to MaxTokens, within the
+// model's output limit, leaving MaxTokens for the answer
+func (r *ClaudeRequest) OutputTokens() (int, int) {
+	if !r.ReasoningEnabled() {
+		return r.MaxTokens, 0
+	}
+	budget := max(r.Reasoning.Budget(), vo.MinReasoningBudgetTokens)
+	maxTokens := r.MaxTokens + budget
+	if capabilities, ok := r.Model.CatalogCapabilities(); ok && maxTokens > capabilities.MaxOutputTokens {
+		maxTokens = capabilities.MaxOutputTokens
+		// The budget gives way to the answer, down to half the output limit
+		budget = min(budget, max(maxTokens-r.MaxTokens, maxTokens/2))
+	}
+	return maxTokens, budget
+}
+
+// imageTokenEstimate is the token estimate of an image block
+const imageTokenEstimate = 1600
+
+// A document page costs its text plus an image of the page. Pages are estimated from the size of
+// the document, as counting them would mean parsing it
+const (
+	documentPageTokens = 3000
+	documentPageBytes  = 64 * 1024
+)
+
+// CheckInputs reports an error when a message carries images or documents that the model does not accept
+func (r *ClaudeRequest) CheckInputs() error {
+	for _, message := range r.Messages {
+		for _, block := range message.Content {
+			if !r.Model.Accepts(block.Type) {
+				return fmt.Errorf("%w: %s does not accept %s input", vo.ErrUnsupportedInput, r.Model, block.Type)
+			}
+		}
+	}
+	return nil
+}
+
+// EstimateMediaTokens returns the base64 characters of the request's images and documents, which
+// estimates from the size of a request body should leave out, and the tokens estimated for them
+func (r *ClaudeRequest) EstimateMediaTokens() (int, int) {
+	chars, tokens := 0, 0
+	for _, message := range r.Messages {
+		for _, block := range message.Content {
+			if block.Type.IsMedia() && block.Source != nil {
+				chars += len(block.Source.Data)
+				_, t := blockSize(block)
+				tokens += t
+			}
+		}
+	}
+	return chars, tokens
+}
+
+// EstimateInputTokens estimates the input tokens of the request at four characters per token
+func (r *ClaudeRequest) EstimateInputTokens() int {
+	chars := len(r.SystemPrompt.String())
+	tokens := 0
+	for _, message := range r.Messages {
+		for _, block := range message.Content {
+			c, t := blockSize(block)
+			chars += c
+			tokens += t
+		}
+	}
+	for _, tool := range r.Tools {
+		chars += toolChars(tool)
+	}
+	return tokens + chars/4
+}
+
+// EstimateBlockTokens estimates the tokens of a content block at four characters per token
+func EstimateBlockTokens(block entities.ContentBlock) int {
+	chars, tokens := blockSize(block)
+	return tokens + chars/4
+}
+
+// EstimateToolTokens estimates the tokens of a tool definition at four characters per token
+func EstimateToolTokens(tool ClaudeTool) int {
+	return toolChars(tool) / 4
+}
+
+// blockSize returns the characters of a block's text and the tokens of its images and documents
+func blockSize(block entities.ContentBlock) (int, int) {
+	switch block.Type {
+	case vo.ContentTypeToolUse:
+		input, _ := json.Marshal(block.Input)
+		return len(block.Name) + len(input), 0
+	case vo.ContentTypeImage:
+		return 0, imageTokenEstimate
+	case vo.ContentTypeDocument:
+		if block.Source == nil {
+			return 0, documentPageTokens
+		}
+		pages := base64.StdEncoding.DecodedLen(len(block.Source.Data))/documentPageBytes + 1
+		return 0, pages * documentPageTokens
+	case vo.ContentTypeThinking, vo.ContentTypeRedactedThinking:
+		return len(block.Thinking) + len(block.Data), 0
+	default:
+		return len(block.Text) + len(block.Content), 0
+	}
+}
+
+// toolChars returns the characters of a tool definition
+func toolChars(tool ClaudeTool) int {
+	schema, _ := json.Marshal(tool.InputSchema)
+	return len(tool.Name) + len(tool.Description) + len(schema)
 }
 
 // ClaudeMessage represents a message in the Claude API format
@@ -64,12 +190,25 @@ type ClaudeResponse struct {
 	StopReason   string
 	StopSequence string
 	Usage        *ClaudeUsage
+
+	// ServedBy is the model that answered, after alias and fallback resolution
+	ServedBy vo.Model
 }
 
-// ClaudeUsage represents token usage information
+// ClaudeUsage represents token usage information. InputTokens excludes the tokens read from
+// or written to the prompt cache, which are counted separately. OutputTokens includes the
+// reasoning tokens, which are broken out when the provider reports them
 type ClaudeUsage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
+	ReasoningTokens          int `json:"reasoning_tokens,omitempty"`
+}
+
+// TotalInputTokens returns the input tokens including those read from or written to the prompt cache
+func (u *ClaudeUsage) TotalInputTokens() int {
+	return u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
 }
 
 // ClaudeStreamEvent represents a streaming event from the Claude API
@@ -88,6 +227,8 @@ type ClaudeDelta struct {
 	Type         string `json:"type"`
 	Text         string `json:"text,omitempty"`
 	PartialJSON  string `json:"partial_json,omitempty"`
+	Thinking     string `json:"thinking,omitempty"`
+	Signature    string `json:"signature,omitempty"`
 	StopReason   string `json:"stop_reason,omitempty"`
 	StopSequence string `json:"stop_sequence,omitempty"`
 }
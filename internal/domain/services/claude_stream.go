@@ -0,0 +1,127 @@
+// Package services contains domain services for the TelemetryFlow GO MCP service
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// StreamTextHandler receives each text delta of a stream as it arrives
+type StreamTextHandler func(text string)
+
+// CollectStream assembles streamed events into a complete response, passing text deltas, but not
+// reasoning, to onText. On an error event it returns the response assembled so far with the error
+func CollectStream(events <-chan *ClaudeStreamEvent, onText StreamTextHandler) (*ClaudeResponse, error) {
+	response := &ClaudeResponse{Type: "message", Role: vo.RoleAssistant}
+	var blocks []entities.ContentBlock
+	var inputs []strings.Builder
+	positions := make(map[int]int)
+
+	for event := range events {
+		if event.Error != nil {
+			response.Content = blocks
+			return response, event.Error
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				response.ID = event.Message.ID
+				response.Model = event.Message.Model
+				response.ServedBy = event.Message.ServedBy
+			}
+		case "content_block_start":
+			if event.ContentBlock != nil {
+				positions[event.Index] = len(blocks)
+				blocks = append(blocks, *event.ContentBlock)
+				inputs = append(inputs, strings.Builder{})
+			}
+		case "content_block_delta":
+			i, ok := positions[event.Index]
+			if !ok || event.Delta == nil {
+				continue
+			}
+			if event.Delta.Text != "" {
+				blocks[i].Text += event.Delta.Text
+				if onText != nil {
+					onText(event.Delta.Text)
+				}
+			}
+			blocks[i].Thinking += event.Delta.Thinking
+			blocks[i].Signature += event.Delta.Signature
+			inputs[i].WriteString(event.Delta.PartialJSON)
+		case "content_block_stop":
+			if i, ok := positions[event.Index]; ok {
+				finishToolInput(&blocks[i], inputs[i].String())
+			}
+		case "message_delta":
+			if event.Delta != nil {
+				response.StopReason = event.Delta.StopReason
+				response.StopSequence = event.Delta.StopSequence
+			}
+		}
+		response.Usage = MergeUsage(response.Usage, event.Usage)
+	}
+
+	response.Content = blocks
+	return response, nil
+}
+
+// finishToolInput decodes the streamed JSON input of a tool_use block
+func finishToolInput(block *entities.ContentBlock, input string) {
+	if block.Type != vo.ContentTypeToolUse || block.Input != nil {
+		return
+	}
+	parsed := map[string]interface{}{}
+	if input != "" {
+		_ = json.Unmarshal([]byte(input), &parsed)
+	}
+	block.Input = parsed
+}
+
+// MergeUsage combines stream usage reports; message_start carries input tokens and message_delta
+// the cumulative output tokens
+func MergeUsage(total, update *ClaudeUsage) *ClaudeUsage {
+	if update == nil {
+		return total
+	}
+	if total == nil {
+		total = &ClaudeUsage{}
+	}
+	if update.InputTokens > 0 {
+		total.InputTokens = update.InputTokens
+	}
+	if update.OutputTokens > 0 {
+		total.OutputTokens = update.OutputTokens
+	}
+	if update.CacheCreationInputTokens > 0 {
+		total.CacheCreationInputTokens = update.CacheCreationInputTokens
+	}
+	if update.CacheReadInputTokens > 0 {
+		total.CacheReadInputTokens = update.CacheReadInputTokens
+	}
+	if update.ReasoningTokens > 0 {
+		total.ReasoningTokens = update.ReasoningTokens
+	}
+	return total
+}
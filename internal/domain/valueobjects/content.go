@@ -36,21 +36,35 @@ var (
 type ContentType string
 
 const (
-	ContentTypeText       ContentType = "text"
-	ContentTypeImage      ContentType = "image"
-	ContentTypeToolUse    ContentType = "tool_use"
-	ContentTypeToolResult ContentType = "tool_result"
+	ContentTypeText             ContentType = "text"
+	ContentTypeImage            ContentType = "image"
+	ContentTypeDocument         ContentType = "document"
+	ContentTypeToolUse          ContentType = "tool_use"
+	ContentTypeToolResult       ContentType = "tool_result"
+	ContentTypeThinking         ContentType = "thinking"
+	ContentTypeRedactedThinking ContentType = "redacted_thinking"
 )
 
 // IsValid checks if the content type is valid
 func (c ContentType) IsValid() bool {
 	switch c {
-	case ContentTypeText, ContentTypeImage, ContentTypeToolUse, ContentTypeToolResult:
+	case ContentTypeText, ContentTypeImage, ContentTypeDocument, ContentTypeToolUse, ContentTypeToolResult,
+		ContentTypeThinking, ContentTypeRedactedThinking:
 		return true
 	}
 	return false
 }
 
+// IsReasoning checks if the content type holds model reasoning rather than an answer
+func (c ContentType) IsReasoning() bool {
+	return c == ContentTypeThinking || c == ContentTypeRedactedThinking
+}
+
+// IsMedia checks if the content type carries an image or document rather than text
+func (c ContentType) IsMedia() bool {
+	return c == ContentTypeImage || c == ContentTypeDocument
+}
+
 // String returns the string representation
 func (c ContentType) String() string {
 	return string(c)
@@ -228,39 +242,31 @@ const DefaultModel = ModelClaudeOpus47
 
 // IsValid checks if the model is a known TFO-Platform model
 func (m Model) IsValid() bool {
-	switch m {
-	case ModelClaudeOpus47, ModelClaudeOpus47Fast, ModelClaudeOpus46, ModelClaudeOpus46Fast,
-		ModelClaudeSonnet46, ModelClaudeOpus45, ModelClaudeSonnet45, ModelClaudeHaiku45,
-		ModelClaudeHaiku45Oct, ModelClaudeSonnet4, ModelClaudeMythosPrev,
-		ModelGemini35Flash, ModelGemini31FlashLite, ModelGemini31ProPreview,
-		ModelGemini3FlashPreview, ModelGemini25Pro, ModelGemini25Flash,
-		ModelGemini25FlashLite, ModelGemini20Flash, ModelGemini20FlashLite, ModelGemini15Pro,
-		ModelGPT55Pro, ModelGPT55, ModelGPT54Pro, ModelGPT54, ModelGPT54Mini,
-		ModelGPT54Nano, ModelGPT53Chat, ModelGPT5, ModelGPT41, ModelO3,
-		ModelDeepSeekV4Pro, ModelDeepSeekV4Flash, ModelDeepSeekV32Speciale,
-		ModelDeepSeekChat, ModelDeepSeekV32, ModelDeepSeekV32Exp,
-		ModelDeepSeekV31Terminus, ModelDeepSeekChatV31,
-		ModelDeepSeekR10528, ModelDeepSeekReasoner,
-		ModelQwen36MaxPreview, ModelQwen36Plus, ModelQwen36Flash,
-		ModelQwen3635BA3B, ModelQwen3627B, ModelQwen35Plus, ModelQwen359B,
-		ModelQwen3535BA3B, ModelQwen3527B, ModelQwen35122BA10B,
-		ModelMistralMedium35, ModelMistralSmall4, ModelMistralLarge3,
-		ModelMistralDevstral2, ModelMistralMinistral314B, ModelMistralMinistral38B,
-		ModelMistralMinistral33B, ModelMistralMedium31, ModelMistralCodestral, ModelMistralLarge21,
-		ModelGrok43, ModelGrok420MultiAgent, ModelGrok420Reasoning, ModelGrok420NonReasoning,
-		ModelGrok41FastReasoning, ModelGrok41FastNonReason,
-		ModelGrok3, ModelGrok3Mini, ModelGrok2, ModelGrok2Mini,
-		ModelKimiK26, ModelKimiK25, ModelKimiK2Thinking, ModelKimiK20905,
-		ModelKimiK2TurboPreview, ModelKimiK2,
-		ModelMoonshotV1128K, ModelMoonshotV132K, ModelMoonshotV18K, ModelMoonshotV1Auto,
-		ModelGLM51, ModelGLM5Turbo, ModelGLM5, ModelGLM47Flash, ModelGLM47,
-		ModelGLM46, ModelGLM45, ModelGLM45Air, ModelGLM4Flash, ModelGLM4,
-		ModelMiMoV25Pro, ModelMiMoV25, ModelMiMoV2Omni, ModelMiMoV2Pro,
-		ModelMiMoV2Flash, ModelMiMoV2TTS, ModelMiMo7B, ModelMiMoVL7B,
-		ModelMiMoV25Lite, ModelMiMo7B0321:
+	if _, ok := modelCapabilities[m]; ok {
 		return true
 	}
-	return false
+	return m.IsLocal()
+}
+
+// IsLocal checks if the model is served by a local provider, such as ollama/llama3.2.
+// Local models are discovered at runtime, so any name in a local namespace is accepted
+func (m Model) IsLocal() bool {
+	provider, name, ok := strings.Cut(string(m), "/")
+	return ok && name != "" && Provider(provider).IsLocal()
+}
+
+// ProviderModelName returns the name the provider's API uses for the model, without the local namespace
+func (m Model) ProviderModelName() string {
+	if m.IsLocal() {
+		_, name, _ := strings.Cut(string(m), "/")
+		return name
+	}
+	return string(m)
+}
+
+// LocalModel returns the model named name on a local provider
+func LocalModel(provider Provider, name string) Model {
+	return Model(string(provider) + "/" + name)
 }
 
 // String returns the string representation
@@ -268,6 +274,74 @@ func (m Model) String() string {
 	return string(m)
 }
 
+// Provider returns the LLM provider that serves the model, or an empty provider when unknown
+func (m Model) Provider() Provider {
+	name := string(m)
+	if m.IsLocal() {
+		provider, _, _ := strings.Cut(name, "/")
+		return Provider(provider)
+	}
+	for _, p := range modelProviderPrefixes {
+		if strings.HasPrefix(name, p.prefix) {
+			return p.provider
+		}
+	}
+	if m == ModelO3 {
+		return ProviderOpenAI
+	}
+	return ""
+}
+
+// Provider identifies an LLM API provider
+type Provider string
+
+// LLM providers
+const (
+	ProviderAnthropic Provider = "anthropic"
+	ProviderGoogle    Provider = "google"
+	ProviderOpenAI    Provider = "openai"
+	ProviderDeepSeek  Provider = "deepseek"
+	ProviderQwen      Provider = "qwen"
+	ProviderMistral   Provider = "mistral"
+	ProviderXAI       Provider = "xai"
+	ProviderMoonshot  Provider = "moonshot"
+	ProviderZhipu     Provider = "zhipu"
+	ProviderMiMo      Provider = "mimo"
+	ProviderOllama    Provider = "ollama" // Ollama native API
+	ProviderLocal     Provider = "local"  // Any OpenAI-compatible local server (vLLM, llama.cpp, ...)
+)
+
+// modelProviderPrefixes maps model name prefixes to their provider
+var modelProviderPrefixes = []struct {
+	prefix   string
+	provider Provider
+}{
+	{"claude-", ProviderAnthropic},
+	{"gemini-", ProviderGoogle},
+	{"gpt-", ProviderOpenAI},
+	{"deepseek-", ProviderDeepSeek},
+	{"qwen", ProviderQwen},
+	{"mistral-", ProviderMistral},
+	{"ministral-", ProviderMistral},
+	{"devstral-", ProviderMistral},
+	{"codestral-", ProviderMistral},
+	{"grok-", ProviderXAI},
+	{"kimi-", ProviderMoonshot},
+	{"moonshot-", ProviderMoonshot},
+	{"glm-", ProviderZhipu},
+	{"mimo-", ProviderMiMo},
+}
+
+// IsLocal checks if the provider serves self-hosted models
+func (p Provider) IsLocal() bool {
+	return p == ProviderOllama || p == ProviderLocal
+}
+
+// String returns the string representation
+func (p Provider) String() string {
+	return string(p)
+}
+
 // TextContent represents text content value object
 type TextContent struct {
 	value string
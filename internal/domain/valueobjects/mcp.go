@@ -78,6 +78,12 @@ const (
 	// Logging methods
 	MethodLoggingSetLevel MCPMethod = "logging/setLevel"
 
+	// Task methods
+	MethodTasksGet    MCPMethod = "tasks/get"
+	MethodTasksResult MCPMethod = "tasks/result"
+	MethodTasksList   MCPMethod = "tasks/list"
+	MethodTasksCancel MCPMethod = "tasks/cancel"
+
 	// Notification methods
 	MethodNotificationsCancelled            MCPMethod = "notifications/cancelled"
 	MethodNotificationsProgress             MCPMethod = "notifications/progress"
@@ -96,6 +102,7 @@ func (m MCPMethod) IsValid() bool {
 		MethodResourcesList, MethodResourcesRead, MethodResourcesSubscribe, MethodResourcesUnsubscribe,
 		MethodPromptsList, MethodPromptsGet,
 		MethodCompletionComplete, MethodLoggingSetLevel,
+		MethodTasksGet, MethodTasksResult, MethodTasksList, MethodTasksCancel,
 		MethodNotificationsCancelled, MethodNotificationsProgress, MethodNotificationsMessage,
 		MethodNotificationsResourcesUpdated, MethodNotificationsResourcesListChanged,
 		MethodNotificationsToolsListChanged, MethodNotificationsPromptsListChanged:
@@ -125,6 +132,7 @@ const (
 	CapabilityLogging      MCPCapability = "logging"
 	CapabilitySampling     MCPCapability = "sampling"
 	CapabilityRoots        MCPCapability = "roots"
+	CapabilityTasks        MCPCapability = "tasks"
 	CapabilityExperimental MCPCapability = "experimental"
 )
 
@@ -132,7 +140,7 @@ const (
 func (c MCPCapability) IsValid() bool {
 	switch c {
 	case CapabilityTools, CapabilityResources, CapabilityPrompts,
-		CapabilityLogging, CapabilitySampling, CapabilityRoots, CapabilityExperimental:
+		CapabilityLogging, CapabilitySampling, CapabilityRoots, CapabilityTasks, CapabilityExperimental:
 		return true
 	}
 	return false
@@ -143,6 +151,38 @@ func (c MCPCapability) String() string {
 	return string(c)
 }
 
+// MCPTaskStatus represents the status of an MCP task
+type MCPTaskStatus string
+
+// MCP Task Statuses
+const (
+	TaskStatusWorking       MCPTaskStatus = "working"
+	TaskStatusInputRequired MCPTaskStatus = "input_required"
+	TaskStatusCompleted     MCPTaskStatus = "completed"
+	TaskStatusFailed        MCPTaskStatus = "failed"
+	TaskStatusCancelled     MCPTaskStatus = "cancelled"
+)
+
+// IsValid checks if the task status is valid
+func (s MCPTaskStatus) IsValid() bool {
+	switch s {
+	case TaskStatusWorking, TaskStatusInputRequired,
+		TaskStatusCompleted, TaskStatusFailed, TaskStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// IsTerminal checks if the task can no longer change status
+func (s MCPTaskStatus) IsTerminal() bool {
+	return s == TaskStatusCompleted || s == TaskStatusFailed || s == TaskStatusCancelled
+}
+
+// String returns the string representation
+func (s MCPTaskStatus) String() string {
+	return string(s)
+}
+
 // MCPLogLevel represents an MCP log level
 type MCPLogLevel string
 
@@ -244,6 +284,7 @@ const (
 	ErrorCodeRateLimited        MCPErrorCode = -32007
 	ErrorCodeTimeout            MCPErrorCode = -32008
 	ErrorCodeCancelled          MCPErrorCode = -32009
+	ErrorCodeBudgetExceeded     MCPErrorCode = -32010
 )
 
 // IsStandardError checks if the error is a standard JSON-RPC error
@@ -287,6 +328,8 @@ func (e MCPErrorCode) Message() string {
 		return "Request timeout"
 	case ErrorCodeCancelled:
 		return "Request cancelled"
+	case ErrorCodeBudgetExceeded:
+		return "Budget exceeded"
 	}
 	return "Unknown error"
 }
@@ -0,0 +1,68 @@
+// Package valueobjects contains immutable, self-validating value objects
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package valueobjects
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Media errors
+var (
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	ErrMediaTooLarge        = errors.New("media exceeds maximum size")
+	ErrUnsupportedInput     = errors.New("model does not accept this input")
+)
+
+// Limits of the media sent to an LLM, in decoded bytes; the strictest of the supported providers
+const (
+	MaxImageBytes    = 5 * 1024 * 1024
+	MaxDocumentBytes = 32 * 1024 * 1024
+)
+
+// MediaContentType returns the content type that carries media of mediaType: image for PNG, JPEG,
+// GIF and WebP, document for PDF
+func MediaContentType(mediaType string) (ContentType, error) {
+	switch mediaType {
+	case MimeTypePNG, MimeTypeJPEG, MimeTypeGIF, MimeTypeWebP:
+		return ContentTypeImage, nil
+	case MimeTypePDF:
+		return ContentTypeDocument, nil
+	}
+	return "", fmt.Errorf("%w: %q (supported: png, jpeg, gif, webp images and pdf documents)", ErrUnsupportedMediaType, mediaType)
+}
+
+// CheckMedia checks the type and size of media and returns the content type that carries it
+func CheckMedia(mediaType string, size int) (ContentType, error) {
+	contentType, err := MediaContentType(mediaType)
+	if err != nil {
+		return "", err
+	}
+	limit := MaxImageBytes
+	if contentType == ContentTypeDocument {
+		limit = MaxDocumentBytes
+	}
+	if size == 0 {
+		return "", fmt.Errorf("%w: empty %s", ErrEmptyContent, contentType)
+	}
+	if size > limit {
+		return "", fmt.Errorf("%w: %s of %d bytes, limit %d", ErrMediaTooLarge, contentType, size, limit)
+	}
+	return contentType, nil
+}
@@ -0,0 +1,192 @@
+// Package valueobjects contains immutable, self-validating value objects
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package valueobjects
+
+// ModelCapabilities describes the limits and features of a model
+type ModelCapabilities struct {
+	ContextWindow   int  `json:"context_window"`    // input plus output tokens
+	MaxOutputTokens int  `json:"max_output_tokens"` // tokens one response may hold
+	Vision          bool `json:"vision"`            // accepts image input
+	Documents       bool `json:"documents"`         // accepts PDF input
+	Tools           bool `json:"tools"`             // calls tools natively
+	Reasoning       bool `json:"reasoning"`         // reasons before answering, on request or always
+}
+
+// DefaultModelCapabilities apply to models outside the catalog, such as local models. Tool calls
+// of such models are emulated when they lack native support
+var DefaultModelCapabilities = ModelCapabilities{ContextWindow: 32768, MaxOutputTokens: 4096, Tools: true}
+
+// modelCapabilities is the catalog of known models
+var modelCapabilities = map[Model]ModelCapabilities{
+	// Anthropic Claude
+	ModelClaudeOpus47:     {ContextWindow: 1000000, MaxOutputTokens: 128000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelClaudeOpus47Fast: {ContextWindow: 1000000, MaxOutputTokens: 128000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelClaudeOpus46:     {ContextWindow: 1000000, MaxOutputTokens: 128000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelClaudeOpus46Fast: {ContextWindow: 1000000, MaxOutputTokens: 128000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelClaudeSonnet46:   {ContextWindow: 1000000, MaxOutputTokens: 64000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelClaudeOpus45:     {ContextWindow: 200000, MaxOutputTokens: 64000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelClaudeSonnet45:   {ContextWindow: 200000, MaxOutputTokens: 64000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelClaudeHaiku45:    {ContextWindow: 200000, MaxOutputTokens: 64000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelClaudeHaiku45Oct: {ContextWindow: 200000, MaxOutputTokens: 64000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelClaudeSonnet4:    {ContextWindow: 200000, MaxOutputTokens: 64000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelClaudeMythosPrev: {ContextWindow: 1000000, MaxOutputTokens: 128000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+
+	// Google Gemini
+	ModelGemini35Flash:       {ContextWindow: 1048576, MaxOutputTokens: 65536, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelGemini31FlashLite:   {ContextWindow: 1048576, MaxOutputTokens: 65536, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelGemini31ProPreview:  {ContextWindow: 1048576, MaxOutputTokens: 65536, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelGemini3FlashPreview: {ContextWindow: 1048576, MaxOutputTokens: 65536, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelGemini25Pro:         {ContextWindow: 1048576, MaxOutputTokens: 65536, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelGemini25Flash:       {ContextWindow: 1048576, MaxOutputTokens: 65536, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelGemini25FlashLite:   {ContextWindow: 1048576, MaxOutputTokens: 65536, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelGemini20Flash:       {ContextWindow: 1048576, MaxOutputTokens: 8192, Vision: true, Documents: true, Tools: true},
+	ModelGemini20FlashLite:   {ContextWindow: 1048576, MaxOutputTokens: 8192, Vision: true, Documents: true, Tools: true},
+	ModelGemini15Pro:         {ContextWindow: 2097152, MaxOutputTokens: 8192, Vision: true, Documents: true, Tools: true},
+
+	// OpenAI
+	ModelGPT55Pro:  {ContextWindow: 1050000, MaxOutputTokens: 128000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelGPT55:     {ContextWindow: 1050000, MaxOutputTokens: 128000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelGPT54Pro:  {ContextWindow: 1050000, MaxOutputTokens: 128000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelGPT54:     {ContextWindow: 1050000, MaxOutputTokens: 128000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelGPT54Mini: {ContextWindow: 400000, MaxOutputTokens: 128000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelGPT54Nano: {ContextWindow: 400000, MaxOutputTokens: 128000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelGPT53Chat: {ContextWindow: 128000, MaxOutputTokens: 16384, Vision: true, Documents: true, Tools: true},
+	ModelGPT5:      {ContextWindow: 400000, MaxOutputTokens: 128000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+	ModelGPT41:     {ContextWindow: 1047576, MaxOutputTokens: 32768, Vision: true, Documents: true, Tools: true},
+	ModelO3:        {ContextWindow: 200000, MaxOutputTokens: 100000, Vision: true, Documents: true, Tools: true, Reasoning: true},
+
+	// DeepSeek
+	ModelDeepSeekV4Pro:       {ContextWindow: 1000000, MaxOutputTokens: 65536, Tools: true, Reasoning: true},
+	ModelDeepSeekV4Flash:     {ContextWindow: 1000000, MaxOutputTokens: 65536, Tools: true, Reasoning: true},
+	ModelDeepSeekV32Speciale: {ContextWindow: 131072, MaxOutputTokens: 65536, Reasoning: true},
+	ModelDeepSeekChat:        {ContextWindow: 131072, MaxOutputTokens: 8192, Tools: true},
+	ModelDeepSeekV32:         {ContextWindow: 131072, MaxOutputTokens: 65536, Tools: true, Reasoning: true},
+	ModelDeepSeekV32Exp:      {ContextWindow: 131072, MaxOutputTokens: 65536, Tools: true, Reasoning: true},
+	ModelDeepSeekV31Terminus: {ContextWindow: 131072, MaxOutputTokens: 65536, Tools: true, Reasoning: true},
+	ModelDeepSeekChatV31:     {ContextWindow: 131072, MaxOutputTokens: 8192, Tools: true},
+	ModelDeepSeekR10528:      {ContextWindow: 131072, MaxOutputTokens: 65536, Reasoning: true},
+	ModelDeepSeekReasoner:    {ContextWindow: 131072, MaxOutputTokens: 65536, Tools: true, Reasoning: true},
+
+	// Alibaba Qwen
+	ModelQwen36MaxPreview: {ContextWindow: 262144, MaxOutputTokens: 65536, Tools: true, Reasoning: true},
+	ModelQwen36Plus:       {ContextWindow: 1000000, MaxOutputTokens: 65536, Vision: true, Tools: true, Reasoning: true},
+	ModelQwen36Flash:      {ContextWindow: 1000000, MaxOutputTokens: 65536, Vision: true, Tools: true, Reasoning: true},
+	ModelQwen3635BA3B:     {ContextWindow: 262144, MaxOutputTokens: 65536, Vision: true, Tools: true, Reasoning: true},
+	ModelQwen3627B:        {ContextWindow: 262144, MaxOutputTokens: 65536, Vision: true, Tools: true, Reasoning: true},
+	ModelQwen35Plus:       {ContextWindow: 1000000, MaxOutputTokens: 65536, Vision: true, Tools: true, Reasoning: true},
+	ModelQwen359B:         {ContextWindow: 262144, MaxOutputTokens: 32768, Vision: true, Tools: true, Reasoning: true},
+	ModelQwen3535BA3B:     {ContextWindow: 262144, MaxOutputTokens: 65536, Vision: true, Tools: true, Reasoning: true},
+	ModelQwen3527B:        {ContextWindow: 262144, MaxOutputTokens: 65536, Vision: true, Tools: true, Reasoning: true},
+	ModelQwen35122BA10B:   {ContextWindow: 262144, MaxOutputTokens: 65536, Vision: true, Tools: true, Reasoning: true},
+
+	// Mistral
+	ModelMistralMedium35:      {ContextWindow: 262144, MaxOutputTokens: 32768, Vision: true, Tools: true},
+	ModelMistralSmall4:        {ContextWindow: 262144, MaxOutputTokens: 32768, Vision: true, Tools: true},
+	ModelMistralLarge3:        {ContextWindow: 262144, MaxOutputTokens: 32768, Vision: true, Tools: true},
+	ModelMistralDevstral2:     {ContextWindow: 262144, MaxOutputTokens: 32768, Tools: true},
+	ModelMistralMinistral314B: {ContextWindow: 262144, MaxOutputTokens: 32768, Vision: true, Tools: true},
+	ModelMistralMinistral38B:  {ContextWindow: 262144, MaxOutputTokens: 32768, Vision: true, Tools: true},
+	ModelMistralMinistral33B:  {ContextWindow: 131072, MaxOutputTokens: 32768, Vision: true, Tools: true},
+	ModelMistralMedium31:      {ContextWindow: 131072, MaxOutputTokens: 32768, Vision: true, Tools: true},
+	ModelMistralCodestral:     {ContextWindow: 262144, MaxOutputTokens: 32768, Tools: true},
+	ModelMistralLarge21:       {ContextWindow: 131072, MaxOutputTokens: 32768, Tools: true},
+
+	// xAI Grok
+	ModelGrok43:              {ContextWindow: 1000000, MaxOutputTokens: 65536, Vision: true, Tools: true, Reasoning: true},
+	ModelGrok420MultiAgent:   {ContextWindow: 2000000, MaxOutputTokens: 65536, Vision: true, Tools: true, Reasoning: true},
+	ModelGrok420Reasoning:    {ContextWindow: 2000000, MaxOutputTokens: 65536, Vision: true, Tools: true, Reasoning: true},
+	ModelGrok420NonReasoning: {ContextWindow: 2000000, MaxOutputTokens: 65536, Vision: true, Tools: true},
+	ModelGrok41FastReasoning: {ContextWindow: 2000000, MaxOutputTokens: 65536, Vision: true, Tools: true, Reasoning: true},
+	ModelGrok41FastNonReason: {ContextWindow: 2000000, MaxOutputTokens: 65536, Vision: true, Tools: true},
+	ModelGrok3:               {ContextWindow: 131072, MaxOutputTokens: 16384, Tools: true},
+	ModelGrok3Mini:           {ContextWindow: 131072, MaxOutputTokens: 16384, Tools: true, Reasoning: true},
+	ModelGrok2:               {ContextWindow: 131072, MaxOutputTokens: 8192, Tools: true},
+	ModelGrok2Mini:           {ContextWindow: 131072, MaxOutputTokens: 8192, Tools: true},
+
+	// Moonshot Kimi
+	ModelKimiK26:            {ContextWindow: 262144, MaxOutputTokens: 32768, Vision: true, Tools: true, Reasoning: true},
+	ModelKimiK25:            {ContextWindow: 262144, MaxOutputTokens: 32768, Vision: true, Tools: true, Reasoning: true},
+	ModelKimiK2Thinking:     {ContextWindow: 262144, MaxOutputTokens: 32768, Tools: true, Reasoning: true},
+	ModelKimiK20905:         {ContextWindow: 262144, MaxOutputTokens: 32768, Tools: true},
+	ModelKimiK2TurboPreview: {ContextWindow: 262144, MaxOutputTokens: 32768, Tools: true},
+	ModelKimiK2:             {ContextWindow: 131072, MaxOutputTokens: 16384, Tools: true},
+	ModelMoonshotV1128K:     {ContextWindow: 131072, MaxOutputTokens: 8192, Tools: true},
+	ModelMoonshotV132K:      {ContextWindow: 32768, MaxOutputTokens: 8192, Tools: true},
+	ModelMoonshotV18K:       {ContextWindow: 8192, MaxOutputTokens: 4096, Tools: true},
+	ModelMoonshotV1Auto:     {ContextWindow: 131072, MaxOutputTokens: 8192, Tools: true},
+
+	// Zhipu GLM
+	ModelGLM51:      {ContextWindow: 200000, MaxOutputTokens: 128000, Tools: true, Reasoning: true},
+	ModelGLM5Turbo:  {ContextWindow: 200000, MaxOutputTokens: 128000, Tools: true, Reasoning: true},
+	ModelGLM5:       {ContextWindow: 200000, MaxOutputTokens: 128000, Tools: true, Reasoning: true},
+	ModelGLM47Flash: {ContextWindow: 200000, MaxOutputTokens: 128000, Tools: true, Reasoning: true},
+	ModelGLM47:      {ContextWindow: 200000, MaxOutputTokens: 128000, Tools: true, Reasoning: true},
+	ModelGLM46:      {ContextWindow: 200000, MaxOutputTokens: 128000, Tools: true, Reasoning: true},
+	ModelGLM45:      {ContextWindow: 131072, MaxOutputTokens: 98304, Tools: true, Reasoning: true},
+	ModelGLM45Air:   {ContextWindow: 131072, MaxOutputTokens: 98304, Tools: true, Reasoning: true},
+	ModelGLM4Flash:  {ContextWindow: 131072, MaxOutputTokens: 4096, Tools: true},
+	ModelGLM4:       {ContextWindow: 131072, MaxOutputTokens: 4096, Tools: true},
+
+	// Xiaomi MiMo
+	ModelMiMoV25Pro:  {ContextWindow: 1048576, MaxOutputTokens: 131072, Tools: true, Reasoning: true},
+	ModelMiMoV25:     {ContextWindow: 1048576, MaxOutputTokens: 131072, Vision: true, Tools: true, Reasoning: true},
+	ModelMiMoV2Omni:  {ContextWindow: 262144, MaxOutputTokens: 65536, Vision: true, Tools: true, Reasoning: true},
+	ModelMiMoV2Pro:   {ContextWindow: 1048576, MaxOutputTokens: 131072, Tools: true, Reasoning: true},
+	ModelMiMoV2Flash: {ContextWindow: 262144, MaxOutputTokens: 65536, Tools: true, Reasoning: true},
+	ModelMiMoV2TTS:   {ContextWindow: 8192, MaxOutputTokens: 8192},
+	ModelMiMo7B:      {ContextWindow: 32768, MaxOutputTokens: 8192, Reasoning: true},
+	ModelMiMoVL7B:    {ContextWindow: 32768, MaxOutputTokens: 8192, Vision: true, Reasoning: true},
+	ModelMiMoV25Lite: {ContextWindow: 262144, MaxOutputTokens: 65536, Tools: true, Reasoning: true},
+	ModelMiMo7B0321:  {ContextWindow: 32768, MaxOutputTokens: 8192, Reasoning: true},
+}
+
+// CatalogCapabilities returns the catalog entry of the model, reporting false for models outside it
+func (m Model) CatalogCapabilities() (ModelCapabilities, bool) {
+	capabilities, ok := modelCapabilities[m]
+	return capabilities, ok
+}
+
+// Capabilities returns the catalog entry of the model, or DefaultModelCapabilities
+func (m Model) Capabilities() ModelCapabilities {
+	if capabilities, ok := modelCapabilities[m]; ok {
+		return capabilities
+	}
+	return DefaultModelCapabilities
+}
+
+// Accepts reports whether the model takes content of type t as input. Only images and documents
+// are restricted; models outside the catalog are left to their provider
+func (m Model) Accepts(t ContentType) bool {
+	capabilities, ok := modelCapabilities[m]
+	if !ok {
+		return true
+	}
+	switch t {
+	case ContentTypeImage:
+		return capabilities.Vision
+	case ContentTypeDocument:
+		return capabilities.Documents
+	}
+	return true
+}
+
+// InputBudget returns the tokens left for input once maxTokens are reserved for the response
+func (c ModelCapabilities) InputBudget(maxTokens int) int {
+	return max(c.ContextWindow-min(maxTokens, c.MaxOutputTokens), 0)
+}
@@ -0,0 +1,220 @@
+// Package valueobjects contains domain value objects for the TelemetryFlow GO MCP service
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package valueobjects
+
+// PricingVersion identifies the built-in price table; costs are stored with the version they were priced with
+const PricingVersion = "2026-10-01"
+
+// tokensPerPricingUnit is the number of tokens a ModelPrice is quoted for
+const tokensPerPricingUnit = 1_000_000
+
+// ModelPrice is the price of a model in USD per million tokens
+type ModelPrice struct {
+	Input      float64 `json:"input"`       // uncached input tokens
+	Output     float64 `json:"output"`      // output tokens, including reasoning
+	CacheRead  float64 `json:"cache_read"`  // input tokens read from the prompt cache
+	CacheWrite float64 `json:"cache_write"` // input tokens written to the prompt cache
+}
+
+// Cost returns the USD cost of a call with the given token counts
+func (p ModelPrice) Cost(input, output, cacheRead, cacheWrite int) float64 {
+	return (float64(input)*p.Input +
+		float64(output)*p.Output +
+		float64(cacheRead)*p.CacheRead +
+		float64(cacheWrite)*p.CacheWrite) / tokensPerPricingUnit
+}
+
+// PriceTable holds model prices under a version
+type PriceTable struct {
+	Version string
+	Prices  map[Model]ModelPrice
+}
+
+// DefaultPriceTable returns the built-in list prices
+func DefaultPriceTable() PriceTable {
+	prices := make(map[Model]ModelPrice, len(modelPrices))
+	for model, price := range modelPrices {
+		prices[model] = price
+	}
+	return PriceTable{Version: PricingVersion, Prices: prices}
+}
+
+// Override returns a copy of the table with the given prices replaced, under version
+func (t PriceTable) Override(version string, prices map[Model]ModelPrice) PriceTable {
+	merged := make(map[Model]ModelPrice, len(t.Prices)+len(prices))
+	for model, price := range t.Prices {
+		merged[model] = price
+	}
+	for model, price := range prices {
+		merged[model] = price
+	}
+	return PriceTable{Version: version, Prices: merged}
+}
+
+// Price returns the price of the model. Local models are free unless the table prices them;
+// false is reported for other models the table does not know
+func (t PriceTable) Price(m Model) (ModelPrice, bool) {
+	if price, ok := t.Prices[m]; ok {
+		return price, true
+	}
+	if m.IsLocal() {
+		return ModelPrice{}, true
+	}
+	return ModelPrice{}, false
+}
+
+// anthropicPrice applies Anthropic's prompt cache rates: reads at a tenth of the input price,
+// five-minute writes at a quarter more
+func anthropicPrice(input, output float64) ModelPrice {
+	return ModelPrice{Input: input, Output: output, CacheRead: input / 10, CacheWrite: input * 1.25}
+}
+
+// cachedPrice is the price of a model whose provider bills cache reads at cacheRead and cache writes as input
+func cachedPrice(input, output, cacheRead float64) ModelPrice {
+	return ModelPrice{Input: input, Output: output, CacheRead: cacheRead, CacheWrite: input}
+}
+
+// flatPrice is the price of a model without prompt caching discounts
+func flatPrice(input, output float64) ModelPrice {
+	return cachedPrice(input, output, input)
+}
+
+// modelPrices are the public list prices of the model catalog as of PricingVersion
+var modelPrices = map[Model]ModelPrice{
+	// Anthropic Claude
+	ModelClaudeOpus47:     anthropicPrice(5, 25),
+	ModelClaudeOpus47Fast: anthropicPrice(30, 150),
+	ModelClaudeOpus46:     anthropicPrice(5, 25),
+	ModelClaudeOpus46Fast: anthropicPrice(30, 150),
+	ModelClaudeSonnet46:   anthropicPrice(3, 15),
+	ModelClaudeOpus45:     anthropicPrice(5, 25),
+	ModelClaudeSonnet45:   anthropicPrice(3, 15),
+	ModelClaudeHaiku45:    anthropicPrice(1, 5),
+	ModelClaudeHaiku45Oct: anthropicPrice(1, 5),
+	ModelClaudeSonnet4:    anthropicPrice(3, 15),
+	ModelClaudeMythosPrev: anthropicPrice(25, 125),
+
+	// Google Gemini
+	ModelGemini35Flash:       cachedPrice(0.50, 3.00, 0.05),
+	ModelGemini31FlashLite:   cachedPrice(0.25, 1.50, 0.025),
+	ModelGemini31ProPreview:  cachedPrice(2.00, 12.00, 0.20),
+	ModelGemini3FlashPreview: cachedPrice(0.50, 3.00, 0.05),
+	ModelGemini25Pro:         cachedPrice(1.25, 10.00, 0.125),
+	ModelGemini25Flash:       cachedPrice(0.30, 2.50, 0.03),
+	ModelGemini25FlashLite:   cachedPrice(0.10, 0.40, 0.01),
+	ModelGemini20Flash:       cachedPrice(0.10, 0.40, 0.025),
+	ModelGemini20FlashLite:   flatPrice(0.075, 0.30),
+	ModelGemini15Pro:         cachedPrice(1.25, 5.00, 0.3125),
+
+	// OpenAI
+	ModelGPT55Pro:  flatPrice(30, 180),
+	ModelGPT55:     cachedPrice(5.00, 30.00, 0.50),
+	ModelGPT54Pro:  flatPrice(30, 180),
+	ModelGPT54:     cachedPrice(2.50, 15.00, 0.25),
+	ModelGPT54Mini: cachedPrice(0.75, 4.50, 0.075),
+	ModelGPT54Nano: cachedPrice(0.20, 1.25, 0.02),
+	ModelGPT53Chat: cachedPrice(1.75, 14.00, 0.175),
+	ModelGPT5:      cachedPrice(1.25, 10.00, 0.125),
+	ModelGPT41:     cachedPrice(2.00, 8.00, 0.50),
+	ModelO3:        cachedPrice(2.00, 8.00, 0.50),
+
+	// DeepSeek
+	ModelDeepSeekV4Pro:       cachedPrice(1.74, 3.48, 0.145),
+	ModelDeepSeekV4Flash:     cachedPrice(0.14, 0.28, 0.028),
+	ModelDeepSeekV32Speciale: cachedPrice(0.28, 0.42, 0.028),
+	ModelDeepSeekChat:        cachedPrice(0.28, 0.42, 0.028),
+	ModelDeepSeekV32:         cachedPrice(0.28, 0.42, 0.028),
+	ModelDeepSeekV32Exp:      cachedPrice(0.28, 0.42, 0.028),
+	ModelDeepSeekV31Terminus: cachedPrice(0.56, 1.68, 0.07),
+	ModelDeepSeekChatV31:     cachedPrice(0.56, 1.68, 0.07),
+	ModelDeepSeekR10528:      cachedPrice(0.55, 2.19, 0.14),
+	ModelDeepSeekReasoner:    cachedPrice(0.28, 0.42, 0.028),
+
+	// Alibaba Qwen
+	ModelQwen36MaxPreview: cachedPrice(1.20, 6.00, 0.24),
+	ModelQwen36Plus:       cachedPrice(0.50, 3.00, 0.10),
+	ModelQwen36Flash:      cachedPrice(0.10, 0.40, 0.02),
+	ModelQwen3635BA3B:     flatPrice(0.20, 1.00),
+	ModelQwen3627B:        flatPrice(0.30, 2.40),
+	ModelQwen35Plus:       cachedPrice(0.40, 2.40, 0.08),
+	ModelQwen359B:         flatPrice(0.10, 0.15),
+	ModelQwen3535BA3B:     flatPrice(0.25, 2.00),
+	ModelQwen3527B:        flatPrice(0.30, 2.40),
+	ModelQwen35122BA10B:   flatPrice(0.40, 3.20),
+
+	// Mistral
+	ModelMistralMedium35:      flatPrice(0.40, 2.00),
+	ModelMistralSmall4:        flatPrice(0.10, 0.30),
+	ModelMistralLarge3:        flatPrice(0.50, 1.50),
+	ModelMistralDevstral2:     flatPrice(0.40, 2.00),
+	ModelMistralMinistral314B: flatPrice(0.20, 0.20),
+	ModelMistralMinistral38B:  flatPrice(0.15, 0.15),
+	ModelMistralMinistral33B:  flatPrice(0.10, 0.10),
+	ModelMistralMedium31:      flatPrice(0.40, 2.00),
+	ModelMistralCodestral:     flatPrice(0.30, 0.90),
+	ModelMistralLarge21:       flatPrice(2.00, 6.00),
+
+	// xAI Grok
+	ModelGrok43:              cachedPrice(3.00, 15.00, 0.75),
+	ModelGrok420MultiAgent:   cachedPrice(2.00, 6.00, 0.20),
+	ModelGrok420Reasoning:    cachedPrice(2.00, 6.00, 0.20),
+	ModelGrok420NonReasoning: cachedPrice(2.00, 6.00, 0.20),
+	ModelGrok41FastReasoning: cachedPrice(0.20, 0.50, 0.05),
+	ModelGrok41FastNonReason: cachedPrice(0.20, 0.50, 0.05),
+	ModelGrok3:               cachedPrice(3.00, 15.00, 0.75),
+	ModelGrok3Mini:           cachedPrice(0.30, 0.50, 0.075),
+	ModelGrok2:               flatPrice(2.00, 10.00),
+	ModelGrok2Mini:           flatPrice(0.20, 1.00),
+
+	// Moonshot Kimi
+	ModelKimiK26:            cachedPrice(0.95, 4.00, 0.16),
+	ModelKimiK25:            cachedPrice(0.60, 3.00, 0.10),
+	ModelKimiK2Thinking:     cachedPrice(0.60, 2.50, 0.15),
+	ModelKimiK20905:         cachedPrice(0.60, 2.50, 0.15),
+	ModelKimiK2TurboPreview: cachedPrice(1.15, 8.00, 0.15),
+	ModelKimiK2:             cachedPrice(0.60, 2.50, 0.15),
+	ModelMoonshotV1128K:     flatPrice(2.00, 5.00),
+	ModelMoonshotV132K:      flatPrice(1.00, 3.00),
+	ModelMoonshotV18K:       flatPrice(0.20, 2.00),
+	ModelMoonshotV1Auto:     flatPrice(2.00, 5.00), // billed at the window it selects; priced at the largest
+
+	// Zhipu GLM
+	ModelGLM51:      cachedPrice(1.40, 4.40, 0.26),
+	ModelGLM5Turbo:  cachedPrice(1.20, 4.00, 0.24),
+	ModelGLM5:       cachedPrice(1.00, 3.20, 0.20),
+	ModelGLM47Flash: {},
+	ModelGLM47:      cachedPrice(0.60, 2.20, 0.11),
+	ModelGLM46:      cachedPrice(0.60, 2.20, 0.11),
+	ModelGLM45:      cachedPrice(0.60, 2.20, 0.11),
+	ModelGLM45Air:   cachedPrice(0.20, 1.10, 0.03),
+	ModelGLM4Flash:  {},
+	ModelGLM4:       flatPrice(1.40, 1.40),
+
+	// Xiaomi MiMo
+	ModelMiMoV25Pro:  cachedPrice(1.00, 3.00, 0.20),
+	ModelMiMoV25:     cachedPrice(0.40, 2.00, 0.08),
+	ModelMiMoV2Omni:  cachedPrice(0.40, 2.00, 0.08),
+	ModelMiMoV2Pro:   cachedPrice(1.00, 3.00, 0.20),
+	ModelMiMoV2Flash: cachedPrice(0.10, 0.30, 0.01),
+	ModelMiMoV2TTS:   flatPrice(0.10, 0.30),
+	ModelMiMo7B:      flatPrice(0.05, 0.10),
+	ModelMiMoVL7B:    flatPrice(0.05, 0.10),
+	ModelMiMoV25Lite: cachedPrice(0.10, 0.30, 0.01),
+	ModelMiMo7B0321:  flatPrice(0.05, 0.10),
+}
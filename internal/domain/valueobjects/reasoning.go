@@ -0,0 +1,130 @@
+// Package valueobjects contains immutable, self-validating value objects
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package valueobjects
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Reasoning errors
+var (
+	ErrInvalidReasoningEffort = errors.New("invalid reasoning effort")
+	ErrInvalidReasoningBudget = errors.New("invalid reasoning budget")
+)
+
+// MinReasoningBudgetTokens is the smallest reasoning budget a request may ask for
+const MinReasoningBudgetTokens = 1024
+
+// ReasoningEffort is a provider-neutral level of reasoning
+type ReasoningEffort string
+
+// Reasoning efforts
+const (
+	ReasoningEffortLow    ReasoningEffort = "low"
+	ReasoningEffortMedium ReasoningEffort = "medium"
+	ReasoningEffortHigh   ReasoningEffort = "high"
+)
+
+// reasoningBudgets are the budgets in tokens that an effort stands for
+var reasoningBudgets = map[ReasoningEffort]int{
+	ReasoningEffortLow:    2048,
+	ReasoningEffortMedium: 8192,
+	ReasoningEffortHigh:   24576,
+}
+
+// AllReasoningEfforts returns the reasoning efforts from low to high
+func AllReasoningEfforts() []ReasoningEffort {
+	return []ReasoningEffort{ReasoningEffortLow, ReasoningEffortMedium, ReasoningEffortHigh}
+}
+
+// IsValid checks if the effort is valid
+func (e ReasoningEffort) IsValid() bool {
+	_, ok := reasoningBudgets[e]
+	return ok
+}
+
+// String returns the string representation
+func (e ReasoningEffort) String() string {
+	return string(e)
+}
+
+// Reasoning asks a model to reason before it answers. Providers take either a budget of
+// reasoning tokens or an effort level, so each is derived from the other when only one is set
+type Reasoning struct {
+	Effort       ReasoningEffort `json:"effort,omitempty"`
+	BudgetTokens int             `json:"budget_tokens,omitempty"`
+}
+
+// NewReasoning creates reasoning settings from an effort, a budget or both; it returns nil when neither is set
+func NewReasoning(effort string, budgetTokens int) (*Reasoning, error) {
+	if effort == "" && budgetTokens == 0 {
+		return nil, nil
+	}
+	reasoning := &Reasoning{Effort: ReasoningEffort(effort), BudgetTokens: budgetTokens}
+	if err := reasoning.Validate(); err != nil {
+		return nil, err
+	}
+	return reasoning, nil
+}
+
+// Validate checks the effort and that the budget is zero or at least MinReasoningBudgetTokens
+func (r *Reasoning) Validate() error {
+	if r.Effort != "" && !r.Effort.IsValid() {
+		return fmt.Errorf("%w: %s", ErrInvalidReasoningEffort, r.Effort)
+	}
+	if r.BudgetTokens < 0 || r.BudgetTokens > 0 && r.BudgetTokens < MinReasoningBudgetTokens {
+		return fmt.Errorf("%w: %d tokens, the minimum is %d", ErrInvalidReasoningBudget, r.BudgetTokens, MinReasoningBudgetTokens)
+	}
+	return nil
+}
+
+// IsEnabled reports whether reasoning was asked for; a nil Reasoning is disabled
+func (r *Reasoning) IsEnabled() bool {
+	return r != nil && (r.Effort != "" || r.BudgetTokens > 0)
+}
+
+// Budget returns the reasoning budget in tokens, derived from the effort when no budget is set
+func (r *Reasoning) Budget() int {
+	if !r.IsEnabled() {
+		return 0
+	}
+	if r.BudgetTokens > 0 {
+		return r.BudgetTokens
+	}
+	return reasoningBudgets[r.Effort]
+}
+
+// Level returns the reasoning effort, derived from the budget when no effort is set
+func (r *Reasoning) Level() ReasoningEffort {
+	if !r.IsEnabled() {
+		return ""
+	}
+	if r.Effort != "" {
+		return r.Effort
+	}
+	switch {
+	case r.BudgetTokens <= reasoningBudgets[ReasoningEffortLow]:
+		return ReasoningEffortLow
+	case r.BudgetTokens <= reasoningBudgets[ReasoningEffortMedium]:
+		return ReasoningEffortMedium
+	default:
+		return ReasoningEffortHigh
+	}
+}
@@ -0,0 +1,231 @@
+// Package audit persists the tool call audit trail.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+)
+
+const (
+	// writeTimeout bounds a single repository write or analytics flush
+	writeTimeout = 10 * time.Second
+
+	// retentionInterval is how often expired records are deleted
+	retentionInterval = time.Hour
+
+	// nilUUID fills UUID columns in ClickHouse when a value is missing
+	nilUUID = "00000000-0000-0000-0000-000000000000"
+)
+
+// AnalyticsBatch buffers tool call events for ClickHouse; persistence.BatchInsert implements it
+type AnalyticsBatch interface {
+	Add(event interface{}) error
+	Flush(ctx context.Context) error
+}
+
+// Options configures a Trail
+type Options struct {
+	BufferSize    int           // records waiting to be written before new ones are dropped
+	FlushInterval time.Duration // how often buffered analytics events are sent
+	Retention     time.Duration // records older than this are deleted from the repository; zero keeps them
+}
+
+// Trail writes audited tool calls to a repository and, optionally, to ClickHouse in batches.
+// Records are written by a background goroutine so tool calls never wait on the database.
+type Trail struct {
+	repo      repositories.IToolExecutionRepository
+	analytics AnalyticsBatch
+	opts      Options
+	logger    zerolog.Logger
+
+	mu      sync.RWMutex
+	closed  bool
+	records chan *entities.ToolExecution
+	done    chan struct{}
+	dropped atomic.Int64
+}
+
+// NewTrail creates a trail; analytics may be nil
+func NewTrail(repo repositories.IToolExecutionRepository, analytics AnalyticsBatch, opts Options, logger zerolog.Logger) *Trail {
+	if opts.BufferSize < 1 {
+		opts.BufferSize = 1
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	return &Trail{
+		repo:      repo,
+		analytics: analytics,
+		opts:      opts,
+		logger:    logger,
+		records:   make(chan *entities.ToolExecution, opts.BufferSize),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start runs the writer until Close is called
+func (t *Trail) Start() {
+	go t.run()
+}
+
+// Record queues a tool call for writing; it never blocks, dropping the record when the buffer is full
+func (t *Trail) Record(_ context.Context, execution *entities.ToolExecution) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.closed {
+		return
+	}
+
+	select {
+	case t.records <- execution:
+	default:
+		if t.dropped.Add(1) == 1 {
+			t.logger.Warn().Msg("Audit trail buffer full, dropping tool call records")
+		}
+	}
+}
+
+// Dropped returns how many records were dropped because the buffer was full
+func (t *Trail) Dropped() int64 {
+	return t.dropped.Load()
+}
+
+// Close writes the queued records, flushes analytics and stops the writer
+func (t *Trail) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	close(t.records)
+	t.mu.Unlock()
+
+	<-t.done
+	return nil
+}
+
+func (t *Trail) run() {
+	defer close(t.done)
+
+	flush := time.NewTicker(t.opts.FlushInterval)
+	defer flush.Stop()
+	retention := time.NewTicker(retentionInterval)
+	defer retention.Stop()
+
+	t.applyRetention()
+	for {
+		select {
+		case execution, ok := <-t.records:
+			if !ok {
+				t.flushAnalytics()
+				return
+			}
+			t.write(execution)
+		case <-flush.C:
+			t.flushAnalytics()
+		case <-retention.C:
+			t.applyRetention()
+		}
+	}
+}
+
+// write saves one record to the repository and queues its analytics event
+func (t *Trail) write(execution *entities.ToolExecution) {
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+
+	if err := t.repo.Save(ctx, execution); err != nil {
+		t.logger.Warn().Err(err).Str("tool", execution.ToolName).Msg("Failed to save tool call audit record")
+	}
+
+	if t.analytics != nil {
+		// BatchInsert sends on its own once the batch is full
+		if err := t.analytics.Add(ToolCallEvent(execution)); err != nil {
+			t.logger.Warn().Err(err).Msg("Failed to send tool call analytics batch")
+		}
+	}
+}
+
+func (t *Trail) flushAnalytics() {
+	if t.analytics == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	if err := t.analytics.Flush(ctx); err != nil {
+		t.logger.Warn().Err(err).Msg("Failed to send tool call analytics batch")
+	}
+}
+
+func (t *Trail) applyRetention() {
+	if t.opts.Retention <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+
+	removed, err := t.repo.DeleteBefore(ctx, time.Now().Add(-t.opts.Retention))
+	if err != nil {
+		t.logger.Warn().Err(err).Msg("Failed to apply audit trail retention")
+		return
+	}
+	if removed > 0 {
+		t.logger.Info().Int64("removed", removed).Msg("Expired tool call audit records deleted")
+	}
+}
+
+// ToolCallEvent converts an audit record to a ClickHouse tool call analytics event
+func ToolCallEvent(execution *entities.ToolExecution) *persistence.ToolCallEvent {
+	input, _ := json.Marshal(execution.Arguments)
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"execution_id":   execution.ID,
+		"status":         execution.Status,
+		"client_name":    execution.ClientName,
+		"client_version": execution.ClientVersion,
+		"api_key_id":     execution.APIKeyID,
+		"arguments":      execution.Arguments,
+	})
+
+	sessionID := execution.SessionID
+	if sessionID == "" {
+		sessionID = nilUUID
+	}
+	return &persistence.ToolCallEvent{
+		Timestamp:      execution.ExecutedAt,
+		SessionID:      sessionID,
+		ConversationID: nilUUID,
+		ToolName:       execution.ToolName,
+		DurationMs:     uint64(execution.Duration.Milliseconds()),
+		IsError:        execution.IsError(),
+		ErrorMessage:   execution.ErrorMessage,
+		InputSize:      uint32(len(input)),
+		Metadata:       string(metadata),
+	}
+}
@@ -0,0 +1,270 @@
+// Package bootstrap builds the server's infrastructure components from configuration.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/budget"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/cache"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/claude"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/gemini"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/llm"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/ollama"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/openai"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/queue"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/usage"
+)
+
+// redisConnectTimeout bounds the initial Redis connection of the budget counters and tool cache
+const redisConnectTimeout = 5 * time.Second
+
+// NewLLMRegistry registers the Anthropic client and every configured provider that is enabled.
+// Unknown providers are logged and skipped
+func NewLLMRegistry(cfg *config.Config, logger zerolog.Logger) (*llm.Registry, error) {
+	registry := llm.NewRegistry()
+
+	if cfg.Claude.IsEnabled() {
+		claudeClient, err := claude.NewClient(&cfg.Claude, logger)
+		if err != nil {
+			return nil, err
+		}
+		registry.Register(vo.ProviderAnthropic, claudeClient)
+		if cfg.Claude.DefaultModel != "" {
+			registry.SetDefaultModel(vo.ProviderAnthropic, vo.Model(cfg.Claude.DefaultModel))
+		}
+	}
+
+	for name, providerCfg := range cfg.Providers {
+		provider := vo.Provider(name)
+		if !isKnownProvider(provider) {
+			logger.Warn().Str("provider", name).Msg("Unknown LLM provider in config, skipping")
+			continue
+		}
+		if !cfg.Providers.IsEnabled(name) {
+			continue
+		}
+
+		var backend services.IClaudeService
+		var err error
+		switch provider {
+		case vo.ProviderOllama:
+			backend = llm.WithToolCalling(ollama.NewClient(providerCfg, logger), providerCfg.ToolCalling)
+		case vo.ProviderLocal:
+			var client *openai.Client
+			if client, err = openai.NewClient(provider, providerCfg, logger); err == nil {
+				backend = llm.WithToolCalling(client, providerCfg.ToolCalling)
+			}
+		case vo.ProviderGoogle:
+			backend, err = gemini.NewClient(providerCfg, logger)
+		default:
+			backend, err = openai.NewClient(provider, providerCfg, logger)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("provider %s: %w", name, err)
+		}
+		registry.Register(provider, backend)
+		if providerCfg.DefaultModel != "" {
+			registry.SetDefaultModel(provider, vo.Model(providerCfg.DefaultModel))
+		}
+	}
+
+	logger.Info().Strs("providers", ProviderNames(registry)).Msg("LLM providers configured")
+
+	return registry, nil
+}
+
+// ProviderNames returns the names of the registered LLM providers, sorted
+func ProviderNames(registry *llm.Registry) []string {
+	names := make([]string, 0)
+	for _, p := range registry.Providers() {
+		names = append(names, p.String())
+	}
+	return names
+}
+
+// isKnownProvider reports whether a provider has a backend
+func isKnownProvider(provider vo.Provider) bool {
+	_, ok := openai.DefaultBaseURL(provider)
+	return ok || provider == vo.ProviderGoogle || provider.IsLocal()
+}
+
+// PriceTable returns the built-in model prices with the configured overrides
+func PriceTable(cfg config.PricingConfig) vo.PriceTable {
+	table := vo.DefaultPriceTable()
+	if cfg.Version == "" && len(cfg.Models) == 0 {
+		return table
+	}
+	version := cfg.Version
+	if version == "" {
+		version = table.Version
+	}
+	overrides := make(map[vo.Model]vo.ModelPrice, len(cfg.Models))
+	for _, price := range cfg.Models {
+		overrides[vo.Model(price.Model)] = vo.ModelPrice{
+			Input:      price.Input,
+			Output:     price.Output,
+			CacheRead:  price.CacheRead,
+			CacheWrite: price.CacheWrite,
+		}
+	}
+	return table.Override(version, overrides)
+}
+
+// NewUsageRecorder starts the LLM usage recorder, writing to ClickHouse when it is enabled and reachable
+// and to memory otherwise. The returned func stops the recorder
+func NewUsageRecorder(cfg *config.Config, logger zerolog.Logger) (*usage.Recorder, repositories.ILLMUsageRepository, func()) {
+	var repo repositories.ILLMUsageRepository
+	var ch *persistence.ClickHouse
+	if cfg.Usage.ClickHouse && cfg.Clickhouse.Enabled {
+		var err error
+		ch, err = ConnectClickHouse(cfg)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to connect ClickHouse for LLM usage, keeping usage in memory")
+		} else {
+			repo = persistence.NewClickHouseLLMUsageRepository(ch, cfg.Usage.BatchSize)
+		}
+	}
+	if repo == nil {
+		repo = persistence.NewInMemoryLLMUsageRepository(cfg.Usage.MaxRecords)
+	}
+
+	prices := PriceTable(cfg.Usage.Pricing)
+	recorder := usage.NewRecorder(repo, prices, usage.Options{
+		BufferSize:     cfg.Usage.BufferSize,
+		FlushInterval:  cfg.Usage.FlushInterval,
+		OrganizationID: cfg.Usage.OrganizationID,
+	}, logger)
+	recorder.Start()
+	logger.Info().
+		Bool("clickhouse", ch != nil).
+		Str("pricing_version", prices.Version).
+		Msg("LLM usage accounting enabled")
+
+	return recorder, repo, func() {
+		_ = recorder.Close()
+		if ch != nil {
+			_ = ch.Close()
+		}
+	}
+}
+
+// NewBudgetGuard saves the configured budgets to repo and returns the guard enforcing them on next's calls.
+// Usage counters are kept in Redis when configured and reachable, in memory otherwise. The returned func
+// stops the notification workers and closes the counters
+func NewBudgetGuard(cfg *config.Config, next *llm.Router, repo repositories.ILLMBudgetRepository, logger zerolog.Logger) (*budget.Guard, func()) {
+	ctx := context.Background()
+	for _, limit := range cfg.Budgets.Limits {
+		b := entities.NewLLMBudget(limit.OrganizationID, entities.BudgetPeriod(limit.Period))
+		b.TokenLimit = limit.Tokens
+		b.CostLimitUSD = limit.CostUSD
+		if limit.WarnAt != nil {
+			b.WarnAt = *limit.WarnAt
+		}
+		if limit.HardLimit != nil {
+			b.HardLimit = *limit.HardLimit
+		}
+		b.WebhookURL = limit.WebhookURL
+		b.NotifyEmails = limit.Emails
+		if err := repo.Save(ctx, b); err != nil {
+			logger.Warn().Err(err).Str("organization_id", b.OrganizationID).Msg("Failed to save LLM budget")
+		}
+	}
+
+	var counter budget.Counter
+	closeCounter := func() {}
+	if cfg.Budgets.Backend == "redis" {
+		redisCfg := cache.DefaultRedisCacheConfig()
+		redisCfg.URL = cfg.Budgets.RedisURL
+
+		redisCache, err := connectRedis(redisCfg)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to connect LLM budget counters to Redis, counting in memory")
+		} else {
+			counter = budget.NewRedisCounter(redisCache.Client(), cfg.Budgets.RedisPrefix)
+			closeCounter = func() { _ = redisCache.Close() }
+		}
+	}
+	if counter == nil {
+		counter = budget.NewMemoryCounter()
+	}
+
+	// Warnings are delivered by a small worker pool of their own so they never wait on tool tasks
+	notifications := cfg.Budgets.Notifications
+	var smtpCfg *queue.SMTPConfig
+	if notifications.SMTP.Host != "" {
+		smtpCfg = &queue.SMTPConfig{
+			Host:     notifications.SMTP.Host,
+			Port:     notifications.SMTP.Port,
+			Username: notifications.SMTP.Username,
+			Password: notifications.SMTP.Password,
+			From:     notifications.SMTP.From,
+		}
+	}
+	pool := queue.NewWorkerPool(2, 100)
+	queue.RegisterNotificationHandlers(pool, nil, smtpCfg)
+	pool.Start(ctx)
+	notifier := budget.NewTaskNotifier(pool, budget.NotifierOptions{
+		WebhookURL: notifications.WebhookURL,
+		Emails:     notifications.Emails,
+		SendEmail:  smtpCfg != nil,
+		MaxRetries: notifications.MaxRetries,
+	}, logger)
+
+	guard := budget.NewGuard(next, repo, counter, PriceTable(cfg.Usage.Pricing), notifier, budget.Options{
+		OrganizationID:  cfg.Usage.OrganizationID,
+		ModelChain:      next.Chain,
+		RefreshInterval: cfg.Budgets.RefreshInterval,
+	}, logger)
+	logger.Info().
+		Str("backend", cfg.Budgets.Backend).
+		Int("configured", len(cfg.Budgets.Limits)).
+		Bool("email", smtpCfg != nil).
+		Msg("LLM budgets enabled")
+
+	return guard, func() {
+		_ = pool.Close()
+		closeCounter()
+	}
+}
+
+// connectRedis opens and checks a Redis connection, closing it again on failure
+func connectRedis(redisCfg *cache.RedisCacheConfig) (*cache.RedisCache, error) {
+	redisCache, err := cache.NewRedisCache(redisCfg)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisConnectTimeout)
+	defer cancel()
+	if err := redisCache.Initialize(ctx); err != nil {
+		_ = redisCache.Close()
+		return nil, err
+	}
+	return redisCache, nil
+}
@@ -0,0 +1,174 @@
+// Package bootstrap builds the server's infrastructure components from configuration.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+)
+
+// Repositories holds the server's repositories, backed by PostgreSQL or memory
+type Repositories struct {
+	Sessions      repositories.ISessionRepository
+	Conversations repositories.IConversationRepository
+	Tools         repositories.IToolRepository
+	Executions    repositories.IToolExecutionRepository
+	Budgets       repositories.ILLMBudgetRepository
+
+	db     *persistence.Database
+	logger zerolog.Logger
+}
+
+// NewRepositories creates GORM repositories when the database is enabled, migrating the schema
+// if configured, and in-memory repositories otherwise
+func NewRepositories(cfg *config.Config, logger zerolog.Logger) (*Repositories, error) {
+	if !cfg.Database.Enabled {
+		logger.Info().Msg("Using in-memory repositories (PostgreSQL disabled)")
+		return &Repositories{
+			Sessions:      persistence.NewInMemorySessionRepository(),
+			Conversations: persistence.NewInMemoryConversationRepository(),
+			Tools:         persistence.NewInMemoryToolRepository(),
+			Executions:    persistence.NewInMemoryToolExecutionRepository(cfg.Audit.MaxRecords),
+			Budgets:       persistence.NewInMemoryLLMBudgetRepository(),
+			logger:        logger,
+		}, nil
+	}
+
+	logger.Info().Msg("PostgreSQL enabled, initializing GORM repositories")
+
+	dbCfg := databaseConfig(cfg)
+	dbCfg.MaxIdleConns = cfg.Database.MaxIdleConns
+	dbCfg.MaxOpenConns = cfg.Database.MaxOpenConns
+	if cfg.Database.URL != "" {
+		dbCfg = nil
+	}
+
+	db, err := persistence.NewDatabase(dbCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect PostgreSQL: %w", err)
+	}
+
+	if err := db.Ping(context.Background()); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to ping PostgreSQL: %w", err)
+	}
+	logger.Info().Msg("PostgreSQL connection established")
+
+	gormDB := db.DB()
+	if cfg.Database.AutoMigrate {
+		if err := gormDB.AutoMigrate(persistence.AllModels()...); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("failed to auto-migrate: %w", err)
+		}
+		logger.Info().Msg("PostgreSQL schema migration complete")
+	}
+
+	return &Repositories{
+		Sessions:      persistence.NewGormSessionRepository(gormDB),
+		Conversations: persistence.NewGormConversationRepository(gormDB),
+		Tools:         persistence.NewGormToolRepository(gormDB),
+		Executions:    persistence.NewGormToolExecutionRepository(gormDB),
+		Budgets:       persistence.NewGormLLMBudgetRepository(gormDB),
+		db:            db,
+		logger:        logger,
+	}, nil
+}
+
+// Close closes the database connection, if any
+func (r *Repositories) Close() {
+	if r.db == nil {
+		return
+	}
+	r.logger.Info().Msg("Closing PostgreSQL connection")
+	_ = r.db.Close()
+}
+
+// ConnectClickHouse opens a connection to the configured ClickHouse server
+func ConnectClickHouse(cfg *config.Config) (*persistence.ClickHouse, error) {
+	return persistence.NewClickHouse(&persistence.ClickHouseConfig{
+		Host:     cfg.Clickhouse.Host,
+		Port:     cfg.Clickhouse.Port,
+		Database: cfg.Clickhouse.Database,
+		Username: cfg.Clickhouse.Username,
+		Password: cfg.Clickhouse.Password,
+		Secure:   cfg.Clickhouse.Secure,
+	})
+}
+
+// TelemetryStores are the databases the context collector reads telemetry from; either may be nil
+type TelemetryStores struct {
+	DB                 *gorm.DB
+	ClickHouse         driver.Conn
+	ClickHouseDatabase string
+}
+
+// ConnectTelemetryStores connects the enabled telemetry databases; failures are logged and the store is left out
+func ConnectTelemetryStores(cfg *config.Config, logger zerolog.Logger) TelemetryStores {
+	var stores TelemetryStores
+
+	if cfg.Database.Enabled {
+		dbCfg := databaseConfig(cfg)
+		if cfg.Database.URL != "" {
+			dbCfg = nil
+		}
+		db, err := persistence.NewDatabase(dbCfg)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to connect PostgreSQL for context collector")
+		} else {
+			stores.DB = db.DB()
+			logger.Info().Msg("Context collector: PostgreSQL connected")
+		}
+	}
+
+	if cfg.Clickhouse.Enabled {
+		ch, err := ConnectClickHouse(cfg)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to connect ClickHouse for context collector")
+		} else {
+			stores.ClickHouse = ch.Conn()
+			stores.ClickHouseDatabase = cfg.Clickhouse.Database
+			if stores.ClickHouseDatabase == "" {
+				stores.ClickHouseDatabase = "telemetryflow_analytics"
+			}
+			logger.Info().Msg("Context collector: ClickHouse connected")
+		}
+	}
+
+	return stores
+}
+
+// databaseConfig returns the PostgreSQL connection settings
+func databaseConfig(cfg *config.Config) *persistence.DatabaseConfig {
+	return &persistence.DatabaseConfig{
+		Host:     cfg.Database.Host,
+		Port:     cfg.Database.Port,
+		User:     cfg.Database.User,
+		Password: cfg.Database.Password,
+		Database: cfg.Database.Database,
+		SSLMode:  cfg.Database.SSLMode,
+	}
+}
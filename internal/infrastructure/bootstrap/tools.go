@@ -0,0 +1,230 @@
+// Package bootstrap builds the server's infrastructure components from configuration.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"context"
+	"os"
+
+	"github.com/rs/zerolog"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/audit"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/cache"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/queue"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/upstream"
+	"github.com/telemetryflow/telemetryflow-go-mcp/pkg/telemetry"
+)
+
+// NewToolResultCache builds the tool result cache, falling back to the in-memory store when Redis is unreachable.
+// The returned func closes the store
+func NewToolResultCache(cfg *config.Config, logger zerolog.Logger) (*cache.ToolResultCache, func()) {
+	policies := make(map[string]cache.ToolCachePolicy, len(cfg.ToolCache.Tools))
+	for _, policy := range cfg.ToolCache.Tools {
+		maxEntrySize := policy.MaxEntrySize
+		if maxEntrySize == 0 {
+			maxEntrySize = cfg.ToolCache.MaxEntrySize
+		}
+		policies[policy.Tool] = cache.ToolCachePolicy{
+			TTL:          policy.TTL,
+			MaxEntrySize: maxEntrySize,
+			KeyArgs:      policy.KeyArgs,
+			TimeBucket:   policy.TimeBucket,
+			Shared:       policy.Shared,
+		}
+	}
+
+	metrics, err := telemetry.NewMetrics(cfg.Telemetry.ServiceName)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to create tool cache metrics")
+	}
+
+	var store cache.JSONStore
+	cleanup := func() {}
+	if cfg.ToolCache.Backend == "redis" {
+		redisCfg := cache.DefaultRedisCacheConfig()
+		redisCfg.URL = cfg.ToolCache.RedisURL
+		redisCfg.Prefix = cfg.ToolCache.RedisPrefix
+
+		redisCache, err := connectRedis(redisCfg)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to connect tool cache to Redis, using in-memory cache")
+		} else {
+			store = redisCache
+			cleanup = func() { _ = redisCache.Close() }
+			logger.Info().Int("tools", len(policies)).Msg("Tool result cache: Redis")
+		}
+	}
+	if store == nil {
+		store = cache.NewMemoryCache(cfg.ToolCache.MaxEntries, 0)
+		logger.Info().Int("tools", len(policies)).Int("max_entries", cfg.ToolCache.MaxEntries).Msg("Tool result cache: in-memory LRU")
+	}
+
+	return cache.NewToolResultCache(store, policies, metrics), cleanup
+}
+
+// NewAuditTrail starts the audit trail writer, batching into ClickHouse when it is enabled and reachable.
+// The returned func flushes and stops the writer
+func NewAuditTrail(cfg *config.Config, repo repositories.IToolExecutionRepository, logger zerolog.Logger) (*audit.Trail, func()) {
+	var batch audit.AnalyticsBatch
+	var ch *persistence.ClickHouse
+	if cfg.Audit.ClickHouse && cfg.Clickhouse.Enabled {
+		var err error
+		ch, err = ConnectClickHouse(cfg)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to connect ClickHouse for the audit trail")
+		} else {
+			batch = ch.NewBatchInsert("tool_call_analytics", cfg.Audit.BatchSize)
+		}
+	}
+
+	trail := audit.NewTrail(repo, batch, audit.Options{
+		BufferSize:    cfg.Audit.BufferSize,
+		FlushInterval: cfg.Audit.FlushInterval,
+		Retention:     cfg.Audit.Retention,
+	}, logger)
+	trail.Start()
+	logger.Info().
+		Bool("clickhouse", batch != nil).
+		Dur("retention", cfg.Audit.Retention).
+		Msg("Tool call audit trail enabled")
+
+	return trail, func() {
+		_ = trail.Close()
+		if ch != nil {
+			_ = ch.Close()
+		}
+	}
+}
+
+// NewUpstreamProxy connects the configured upstream MCP servers; failures are logged and skipped
+func NewUpstreamProxy(cfg *config.Config, logger zerolog.Logger) *upstream.Proxy {
+	proxy := upstream.NewProxy(cfg.Server.Name, cfg.Server.Version, logger)
+
+	for _, server := range cfg.Upstreams.Servers {
+		if server.Disabled {
+			continue
+		}
+
+		ctx := context.Background()
+		var cancel context.CancelFunc
+		if cfg.Upstreams.ConnectTimeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, cfg.Upstreams.ConnectTimeout)
+		}
+		err := proxy.Connect(ctx, upstream.Options{
+			Name:      server.Name,
+			Transport: server.Transport,
+			Stdio: upstream.StdioOptions{
+				Command:        server.Command,
+				Args:           server.Args,
+				Env:            server.Env,
+				EnvPassthrough: server.EnvPassthrough,
+				WorkingDir:     server.WorkingDir,
+			},
+			HTTP: upstream.HTTPOptions{
+				URL:     server.URL,
+				Headers: server.Headers,
+			},
+			Timeout: server.Timeout,
+		})
+		if cancel != nil {
+			cancel()
+		}
+		if err != nil {
+			logger.Warn().Err(err).Str("upstream", server.Name).Msg("Failed to connect upstream MCP server")
+		}
+	}
+
+	return proxy
+}
+
+// TaskQueue is the worker pool behind background tool tasks, fed through NATS when configured
+type TaskQueue struct {
+	cfg        config.TasksConfig
+	pool       *queue.WorkerPool
+	dispatcher *queue.ToolTaskDispatcher
+	nats       *queue.NATSQueue
+}
+
+// NewTaskQueue creates the worker pool and dispatcher; tasks run once Start is called
+func NewTaskQueue(cfg config.TasksConfig) *TaskQueue {
+	pool := queue.NewWorkerPool(cfg.Workers, cfg.QueueSize)
+	return &TaskQueue{cfg: cfg, pool: pool, dispatcher: queue.NewToolTaskDispatcher(pool)}
+}
+
+// Dispatcher returns the dispatcher tasks are queued through
+func (q *TaskQueue) Dispatcher() *queue.ToolTaskDispatcher {
+	return q.dispatcher
+}
+
+// Start runs dispatched tasks with run. When the NATS backend is configured but unreachable,
+// the failure is logged and tasks go straight to the in-process pool
+func (q *TaskQueue) Start(run queue.ToolTaskRunner, logger zerolog.Logger) {
+	q.dispatcher.Handle(run)
+
+	ctx := context.Background()
+	q.pool.Start(ctx)
+
+	if q.cfg.Backend != "nats" {
+		logger.Info().Int("workers", q.cfg.Workers).Msg("Background tasks: in-process worker pool")
+		return
+	}
+
+	instanceID := q.cfg.InstanceID
+	if instanceID == "" {
+		instanceID, _ = os.Hostname()
+	}
+
+	natsCfg := queue.DefaultNATSConfig()
+	natsCfg.URL = q.cfg.NATSURL
+	natsCfg.Token = q.cfg.NATSToken
+	natsCfg.Username = q.cfg.NATSUsername
+	natsCfg.Password = q.cfg.NATSPassword
+
+	natsQueue, err := queue.NewNATSQueue(natsCfg)
+	if err == nil {
+		err = natsQueue.Initialize(ctx)
+	}
+	if err == nil {
+		err = q.dispatcher.UseNATS(ctx, natsQueue, instanceID)
+	}
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to start NATS task queue, using in-process worker pool")
+		if natsQueue != nil {
+			_ = natsQueue.Close()
+		}
+		return
+	}
+
+	q.nats = natsQueue
+	logger.Info().
+		Str("nats_url", q.cfg.NATSURL).
+		Str("instance_id", instanceID).
+		Msg("Background tasks: NATS queue")
+}
+
+// Close stops the NATS consumer, if any, and the worker pool
+func (q *TaskQueue) Close() error {
+	if q.nats != nil {
+		_ = q.nats.Close()
+	}
+	return q.pool.Close()
+}
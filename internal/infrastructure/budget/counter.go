@@ -0,0 +1,169 @@
+// Package budget enforces per-organization LLM token and cost budgets.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package budget
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+)
+
+// Counter keeps what organizations have spent of their budgets in the current period
+type Counter interface {
+	// Add adds delta to the usage under key, which expires at expiresAt, and returns the new usage
+	Add(ctx context.Context, key string, delta entities.BudgetUsage, expiresAt time.Time) (entities.BudgetUsage, error)
+
+	// Get returns the usage under key
+	Get(ctx context.Context, key string) (entities.BudgetUsage, error)
+
+	// MarkOnce marks key until expiresAt, reporting whether it was not marked yet
+	MarkOnce(ctx context.Context, key string, expiresAt time.Time) (bool, error)
+}
+
+// MemoryCounter is a Counter for a single server instance
+type MemoryCounter struct {
+	mu      sync.Mutex
+	entries map[string]*counterEntry
+	now     func() time.Time
+}
+
+type counterEntry struct {
+	usage     entities.BudgetUsage
+	expiresAt time.Time
+}
+
+// NewMemoryCounter creates an in-memory counter
+func NewMemoryCounter() *MemoryCounter {
+	return &MemoryCounter{entries: make(map[string]*counterEntry), now: time.Now}
+}
+
+// Add adds delta to the usage under key and returns the new usage
+func (c *MemoryCounter) Add(_ context.Context, key string, delta entities.BudgetUsage, expiresAt time.Time) (entities.BudgetUsage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := c.entry(key, expiresAt)
+	entry.usage = entry.usage.Add(delta)
+	return entry.usage, nil
+}
+
+// Get returns the usage under key
+func (c *MemoryCounter) Get(_ context.Context, key string) (entities.BudgetUsage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !c.now().Before(entry.expiresAt) {
+		return entities.BudgetUsage{}, nil
+	}
+	return entry.usage, nil
+}
+
+// MarkOnce marks key until expiresAt, reporting whether it was not marked yet
+func (c *MemoryCounter) MarkOnce(_ context.Context, key string, expiresAt time.Time) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[key]; ok && c.now().Before(entry.expiresAt) {
+		return false, nil
+	}
+	c.entry(key, expiresAt)
+	return true, nil
+}
+
+// entry returns the live entry under key, creating it and dropping expired ones when there is none
+func (c *MemoryCounter) entry(key string, expiresAt time.Time) *counterEntry {
+	now := c.now()
+	if entry, ok := c.entries[key]; ok && now.Before(entry.expiresAt) {
+		return entry
+	}
+	for k, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	entry := &counterEntry{expiresAt: expiresAt}
+	c.entries[key] = entry
+	return entry
+}
+
+var _ Counter = (*MemoryCounter)(nil)
+
+// Redis hash fields of a usage counter
+const (
+	tokensField = "tokens"
+	costField   = "cost_usd"
+)
+
+// RedisCounter is a Counter shared by every server instance using the same Redis
+type RedisCounter struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisCounter creates a counter storing usage in Redis hashes under prefix
+func NewRedisCounter(client *redis.Client, prefix string) *RedisCounter {
+	return &RedisCounter{client: client, prefix: prefix}
+}
+
+// Add adds delta to the usage under key and returns the new usage
+func (c *RedisCounter) Add(ctx context.Context, key string, delta entities.BudgetUsage, expiresAt time.Time) (entities.BudgetUsage, error) {
+	key = c.prefix + key
+	var tokens *redis.IntCmd
+	var cost *redis.FloatCmd
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		tokens = pipe.HIncrBy(ctx, key, tokensField, delta.Tokens)
+		cost = pipe.HIncrByFloat(ctx, key, costField, delta.CostUSD)
+		pipe.ExpireAt(ctx, key, expiresAt)
+		return nil
+	})
+	if err != nil {
+		return entities.BudgetUsage{}, err
+	}
+	return entities.BudgetUsage{Tokens: tokens.Val(), CostUSD: cost.Val()}, nil
+}
+
+// Get returns the usage under key
+func (c *RedisCounter) Get(ctx context.Context, key string) (entities.BudgetUsage, error) {
+	values, err := c.client.HMGet(ctx, c.prefix+key, tokensField, costField).Result()
+	if err != nil {
+		return entities.BudgetUsage{}, err
+	}
+	var usage entities.BudgetUsage
+	if s, ok := values[0].(string); ok {
+		usage.Tokens, _ = strconv.ParseInt(s, 10, 64)
+	}
+	if s, ok := values[1].(string); ok {
+		usage.CostUSD, _ = strconv.ParseFloat(s, 64)
+	}
+	return usage, nil
+}
+
+// MarkOnce marks key until expiresAt, reporting whether it was not marked yet
+func (c *RedisCounter) MarkOnce(ctx context.Context, key string, expiresAt time.Time) (bool, error) {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return false, nil
+	}
+	return c.client.SetNX(ctx, c.prefix+key, 1, ttl).Result()
+}
+
+var _ Counter = (*RedisCounter)(nil)
@@ -0,0 +1,341 @@
+// Package budget enforces per-organization LLM token and cost budgets.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package budget
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/queue"
+)
+
+const (
+	// settleTimeout bounds reconciling a call with the counters after it finished
+	settleTimeout = 5 * time.Second
+
+	// counterGrace keeps a period's counters past its end so late reconciliations still find them
+	counterGrace = time.Hour
+)
+
+// Options configures a Guard
+type Options struct {
+	OrganizationID  string                    // organization of calls made outside an organization's session
+	ModelChain      func(vo.Model) []vo.Model // resolves a requested model or alias to the models it may be served by
+	RefreshInterval time.Duration             // how long an organization's budgets are cached
+}
+
+// Guard implements IClaudeService on top of another service, enforcing the budgets of the calling
+// organization. Before a call it reserves the estimated tokens and cost against every budget of the
+// organization, rejecting the call when a hard budget would be exceeded; after the call the
+// reservation is reconciled with the reported usage. Budgets are not enforced when the counters are
+// unavailable, so an outage of Redis does not stop LLM calls
+type Guard struct {
+	next     services.IClaudeService
+	budgets  repositories.ILLMBudgetRepository
+	counter  Counter
+	prices   vo.PriceTable
+	notifier Notifier
+	opts     Options
+	logger   zerolog.Logger
+	now      func() time.Time
+
+	mu     sync.Mutex
+	cached map[string]*cachedBudgets
+}
+
+type cachedBudgets struct {
+	budgets  []*entities.LLMBudget
+	loadedAt time.Time
+}
+
+// NewGuard creates a guard over next; notifier may be nil
+func NewGuard(next services.IClaudeService, budgets repositories.ILLMBudgetRepository, counter Counter, prices vo.PriceTable,
+	notifier Notifier, opts Options, logger zerolog.Logger) *Guard {
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = time.Minute
+	}
+	return &Guard{
+		next:     next,
+		budgets:  budgets,
+		counter:  counter,
+		prices:   prices,
+		notifier: notifier,
+		opts:     opts,
+		logger:   logger.With().Str("component", "llm-budget").Logger(),
+		now:      time.Now,
+		cached:   make(map[string]*cachedBudgets),
+	}
+}
+
+// CreateMessage makes the call when the organization's budgets allow it
+func (g *Guard) CreateMessage(ctx context.Context, request *services.ClaudeRequest) (*services.ClaudeResponse, error) {
+	held, err := g.reserve(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	response, err := g.next.CreateMessage(ctx, request)
+	if response != nil {
+		g.settle(ctx, held, request.Model, response.ServedBy, response.Usage)
+	} else {
+		g.settle(ctx, held, request.Model, "", nil)
+	}
+	return response, err
+}
+
+// CreateMessageStream starts the stream when the organization's budgets allow it; the reservation is
+// reconciled when the stream ends or is abandoned
+func (g *Guard) CreateMessageStream(ctx context.Context, request *services.ClaudeRequest) (<-chan *services.ClaudeStreamEvent, error) {
+	held, err := g.reserve(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	events, err := g.next.CreateMessageStream(ctx, request)
+	if err != nil {
+		g.settle(ctx, held, request.Model, "", nil)
+		return nil, err
+	}
+	if held == nil {
+		return events, nil
+	}
+
+	out := make(chan *services.ClaudeStreamEvent)
+	go func() {
+		defer close(out)
+		var usage *services.ClaudeUsage
+		var servedBy vo.Model
+		defer func() { g.settle(ctx, held, request.Model, servedBy, usage) }()
+
+		for event := range events {
+			usage = services.MergeUsage(usage, event.Usage)
+			if event.Type == "message_start" && event.Message != nil {
+				servedBy = event.Message.ServedBy
+			}
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// CountTokens counts tokens with the underlying service
+func (g *Guard) CountTokens(ctx context.Context, request *services.ClaudeRequest) (int, error) {
+	return g.next.CountTokens(ctx, request)
+}
+
+// ValidateRequest validates a request with the underlying service
+func (g *Guard) ValidateRequest(request *services.ClaudeRequest) error {
+	return g.next.ValidateRequest(request)
+}
+
+var _ services.IClaudeService = (*Guard)(nil)
+
+// Usage returns what the organization of budget has spent of it in the current period
+func (g *Guard) Usage(ctx context.Context, budget *entities.LLMBudget) (entities.BudgetUsage, error) {
+	start, _ := budget.Period.Window(g.now())
+	return g.counter.Get(ctx, counterKey(budget, start))
+}
+
+// heldBudget is a budget with a reservation in its current period
+type heldBudget struct {
+	budget     *entities.LLMBudget
+	key        string
+	start, end time.Time
+}
+
+// reservation is the estimate of a call held against the budgets of its organization
+type reservation struct {
+	estimate entities.BudgetUsage
+	held     []heldBudget
+}
+
+// reserve holds the estimate of the call against every budget of the calling organization. It
+// returns nil when no budget applies
+func (g *Guard) reserve(ctx context.Context, request *services.ClaudeRequest) (*reservation, error) {
+	organizationID := entities.UsageScopeFromContext(ctx).OrganizationID
+	if organizationID == "" {
+		organizationID = g.opts.OrganizationID
+	}
+	if organizationID == "" {
+		return nil, nil
+	}
+	budgets := g.budgetsFor(ctx, organizationID)
+	if len(budgets) == 0 {
+		return nil, nil
+	}
+
+	res := &reservation{estimate: g.estimate(ctx, request)}
+	now := g.now()
+	for _, budget := range budgets {
+		start, end := budget.Period.Window(now)
+		h := heldBudget{budget: budget, key: counterKey(budget, start), start: start, end: end}
+		usage, err := g.counter.Add(ctx, h.key, res.estimate, end.Add(counterGrace))
+		if err != nil {
+			g.logger.Warn().Err(err).Str("organization_id", organizationID).Msg("Failed to reserve LLM budget, not enforcing it")
+			continue
+		}
+		res.held = append(res.held, h)
+
+		limit := budget.Exceeded(usage)
+		if limit == "" || !budget.HardLimit {
+			continue
+		}
+		g.release(ctx, res)
+		before := usage.Sub(res.estimate)
+		g.notify(ctx, queue.EventTypeBudgetExceeded, h, before)
+		return nil, entities.NewBudgetExceededError(budget, limit, before, res.estimate, end)
+	}
+	if len(res.held) == 0 {
+		return nil, nil
+	}
+	return res, nil
+}
+
+// settle replaces the estimate of a finished call with its reported usage and sends the
+// notifications of budgets that reached a threshold
+func (g *Guard) settle(ctx context.Context, res *reservation, requested, servedBy vo.Model, reported *services.ClaudeUsage) {
+	if res == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), settleTimeout)
+	defer cancel()
+
+	actual := g.cost(g.pricedModel(requested, servedBy), reported)
+	delta := actual.Sub(res.estimate)
+	for _, h := range res.held {
+		usage, err := g.counter.Add(ctx, h.key, delta, h.end.Add(counterGrace))
+		if err != nil {
+			g.logger.Warn().Err(err).Str("organization_id", h.budget.OrganizationID).Msg("Failed to reconcile LLM budget")
+			continue
+		}
+		switch {
+		case h.budget.Exceeded(usage) != "":
+			g.notify(ctx, queue.EventTypeBudgetExceeded, h, usage)
+		case h.budget.WarnAt > 0 && h.budget.Used(usage) >= h.budget.WarnAt:
+			g.notify(ctx, queue.EventTypeBudgetWarning, h, usage)
+		}
+	}
+}
+
+// release returns the estimate held by res
+func (g *Guard) release(ctx context.Context, res *reservation) {
+	refund := entities.BudgetUsage{}.Sub(res.estimate)
+	for _, h := range res.held {
+		if _, err := g.counter.Add(ctx, h.key, refund, h.end.Add(counterGrace)); err != nil {
+			g.logger.Warn().Err(err).Str("organization_id", h.budget.OrganizationID).Msg("Failed to release LLM budget reservation")
+		}
+	}
+}
+
+// notify sends an event once per budget, event type and period
+func (g *Guard) notify(ctx context.Context, eventType string, h heldBudget, usage entities.BudgetUsage) {
+	if g.notifier == nil {
+		return
+	}
+	first, err := g.counter.MarkOnce(ctx, h.key+":"+eventType, h.end.Add(counterGrace))
+	if err != nil || !first {
+		return
+	}
+	g.notifier.Notify(ctx, h.budget, newEvent(eventType, h.budget, usage, h.start, h.end))
+}
+
+// estimate returns the most a call may use: its counted input tokens and max_tokens of output,
+// including any reasoning budget, priced with the first model it may be served by
+func (g *Guard) estimate(ctx context.Context, request *services.ClaudeRequest) entities.BudgetUsage {
+	input, err := g.next.CountTokens(ctx, request)
+	if err != nil || input <= 0 {
+		input = request.EstimateInputTokens()
+	}
+	output, _ := request.OutputTokens()
+	usage := entities.BudgetUsage{Tokens: int64(input + output)}
+	if price, ok := g.prices.Price(g.pricedModel(request.Model, "")); ok {
+		usage.CostUSD = price.Cost(input, output, 0, 0)
+	}
+	return usage
+}
+
+// cost returns the tokens and cost of reported usage
+func (g *Guard) cost(model vo.Model, reported *services.ClaudeUsage) entities.BudgetUsage {
+	if reported == nil {
+		return entities.BudgetUsage{}
+	}
+	usage := entities.BudgetUsage{Tokens: int64(reported.TotalInputTokens() + reported.OutputTokens)}
+	if price, ok := g.prices.Price(model); ok {
+		usage.CostUSD = price.Cost(reported.InputTokens, reported.OutputTokens, reported.CacheReadInputTokens, reported.CacheCreationInputTokens)
+	}
+	return usage
+}
+
+// pricedModel returns the model that served a call, or the first one it may be served by
+func (g *Guard) pricedModel(requested, servedBy vo.Model) vo.Model {
+	if servedBy != "" {
+		return servedBy
+	}
+	if g.opts.ModelChain != nil {
+		if chain := g.opts.ModelChain(requested); len(chain) > 0 {
+			return chain[0]
+		}
+	}
+	return requested
+}
+
+// budgetsFor returns the enabled budgets of an organization, re-reading them once the cache is stale.
+// When they cannot be read the cached budgets are kept until the next refresh
+func (g *Guard) budgetsFor(ctx context.Context, organizationID string) []*entities.LLMBudget {
+	now := g.now()
+	g.mu.Lock()
+	cached, ok := g.cached[organizationID]
+	g.mu.Unlock()
+	if ok && now.Sub(cached.loadedAt) < g.opts.RefreshInterval {
+		return cached.budgets
+	}
+
+	var budgets []*entities.LLMBudget
+	all, err := g.budgets.FindByOrganization(ctx, organizationID)
+	if err != nil {
+		g.logger.Warn().Err(err).Str("organization_id", organizationID).Msg("Failed to load LLM budgets")
+		if ok {
+			budgets = cached.budgets
+		}
+	}
+	for _, budget := range all {
+		if budget.Enabled {
+			budgets = append(budgets, budget)
+		}
+	}
+
+	g.mu.Lock()
+	g.cached[organizationID] = &cachedBudgets{budgets: budgets, loadedAt: now}
+	g.mu.Unlock()
+	return budgets
+}
+
+// counterKey names the counter of budget for the period starting at start
+func counterKey(budget *entities.LLMBudget, start time.Time) string {
+	return "llm-budget:" + budget.OrganizationID + ":" + string(budget.Period) + ":" + start.Format("2006-01-02")
+}
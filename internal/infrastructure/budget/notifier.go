@@ -0,0 +1,186 @@
+// Package budget enforces per-organization LLM token and cost budgets.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package budget
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/queue"
+)
+
+// notificationTimeout bounds the delivery of a notification, retries included
+const notificationTimeout = 2 * time.Minute
+
+// Event reports a budget reaching its warning threshold or its limit
+type Event struct {
+	Type           string    `json:"type"` // queue.EventTypeBudgetWarning or queue.EventTypeBudgetExceeded
+	OrganizationID string    `json:"organizationId"`
+	BudgetID       string    `json:"budgetId"`
+	Period         string    `json:"period"`
+	Tokens         int64     `json:"tokens"`
+	TokenLimit     int64     `json:"tokenLimit,omitempty"`
+	CostUSD        float64   `json:"costUsd"`
+	CostLimitUSD   float64   `json:"costLimitUsd,omitempty"`
+	Used           float64   `json:"used"` // largest share of a limit used
+	HardLimit      bool      `json:"hardLimit"`
+	WindowStart    time.Time `json:"windowStart"`
+	ResetsAt       time.Time `json:"resetsAt"`
+	OccurredAt     time.Time `json:"occurredAt"`
+}
+
+// newEvent describes the usage of budget in the window starting at start
+func newEvent(eventType string, budget *entities.LLMBudget, usage entities.BudgetUsage, start, end time.Time) *Event {
+	return &Event{
+		Type:           eventType,
+		OrganizationID: budget.OrganizationID,
+		BudgetID:       budget.ID,
+		Period:         string(budget.Period),
+		Tokens:         usage.Tokens,
+		TokenLimit:     budget.TokenLimit,
+		CostUSD:        usage.CostUSD,
+		CostLimitUSD:   budget.CostLimitUSD,
+		Used:           budget.Used(usage),
+		HardLimit:      budget.HardLimit,
+		WindowStart:    start,
+		ResetsAt:       end,
+		OccurredAt:     time.Now().UTC(),
+	}
+}
+
+// Notifier sends budget events to the people responsible for a budget
+type Notifier interface {
+	Notify(ctx context.Context, budget *entities.LLMBudget, event *Event)
+}
+
+// NotifierOptions configures a TaskNotifier
+type NotifierOptions struct {
+	WebhookURL string   // for budgets without a webhook_url
+	Emails     []string // for budgets without notify_emails
+	SendEmail  bool     // whether an email notification handler is registered
+	MaxRetries int      // webhook delivery retries
+}
+
+// TaskNotifier logs budget events and publishes them as webhook delivery and email notification tasks
+type TaskNotifier struct {
+	queue  queue.TaskQueue
+	opts   NotifierOptions
+	logger zerolog.Logger
+}
+
+// NewTaskNotifier creates a notifier publishing to q
+func NewTaskNotifier(q queue.TaskQueue, opts NotifierOptions, logger zerolog.Logger) *TaskNotifier {
+	return &TaskNotifier{queue: q, opts: opts, logger: logger}
+}
+
+// Notify logs the event and queues its deliveries; it never blocks on delivery
+func (n *TaskNotifier) Notify(ctx context.Context, budget *entities.LLMBudget, event *Event) {
+	n.logger.Warn().
+		Str("event", event.Type).
+		Str("organization_id", event.OrganizationID).
+		Str("period", event.Period).
+		Int64("tokens", event.Tokens).
+		Float64("cost_usd", event.CostUSD).
+		Float64("used", event.Used).
+		Msg("LLM budget notification")
+
+	webhookURL := budget.WebhookURL
+	if webhookURL == "" {
+		webhookURL = n.opts.WebhookURL
+	}
+	if webhookURL != "" {
+		body, err := eventBody(event)
+		if err == nil {
+			n.publish(ctx, queue.NewTaskBuilder(queue.TaskTypeWebhookDelivery).
+				WithPayload(queue.WebhookDeliveryPayload{
+					URL:        webhookURL,
+					Method:     "POST",
+					Headers:    map[string]string{"X-TelemetryFlow-Event": event.Type},
+					Body:       body,
+					MaxRetries: n.opts.MaxRetries,
+				}).
+				WithTimeout(notificationTimeout).
+				Build())
+		}
+	}
+
+	emails := budget.NotifyEmails
+	if len(emails) == 0 {
+		emails = n.opts.Emails
+	}
+	if n.opts.SendEmail && len(emails) > 0 {
+		subject, text := emailMessage(event)
+		n.publish(ctx, queue.NewTaskBuilder(queue.TaskTypeEmailNotification).
+			WithPayload(queue.EmailNotificationPayload{
+				To:       emails,
+				Subject:  subject,
+				Body:     text,
+				Metadata: map[string]interface{}{"event": event.Type, "organizationId": event.OrganizationID},
+			}).
+			WithTimeout(notificationTimeout).
+			Build())
+	}
+}
+
+func (n *TaskNotifier) publish(ctx context.Context, task *queue.Task) {
+	if _, err := n.queue.Publish(ctx, task); err != nil {
+		n.logger.Warn().Err(err).Str("task", task.Type).Msg("Failed to queue LLM budget notification")
+	}
+}
+
+var _ Notifier = (*TaskNotifier)(nil)
+
+// eventBody returns the event as a webhook body
+func eventBody(event *Event) (map[string]interface{}, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	var body map[string]interface{}
+	return body, json.Unmarshal(data, &body)
+}
+
+// emailMessage returns the subject and text of the email for event
+func emailMessage(event *Event) (string, string) {
+	state := "has reached its warning threshold"
+	if event.Type == queue.EventTypeBudgetExceeded {
+		state = "is exhausted"
+	}
+	subject := fmt.Sprintf("LLM budget of %s %s (%.0f%% used)", event.OrganizationID, state, event.Used*100)
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "The %s LLM budget of organization %s %s.\n\n", event.Period, event.OrganizationID, state)
+	if event.TokenLimit > 0 {
+		fmt.Fprintf(&text, "Tokens: %d of %d\n", event.Tokens, event.TokenLimit)
+	}
+	if event.CostLimitUSD > 0 {
+		fmt.Fprintf(&text, "Cost:   $%.2f of $%.2f\n", event.CostUSD, event.CostLimitUSD)
+	}
+	if event.Type == queue.EventTypeBudgetExceeded && event.HardLimit {
+		text.WriteString("\nFurther LLM calls are rejected until the budget resets.\n")
+	}
+	fmt.Fprintf(&text, "\nThe budget resets at %s.\n", event.ResetsAt.Format(time.RFC3339))
+	return subject, text.String()
+}
@@ -0,0 +1,189 @@
+// Package cache provides Redis-based caching for the MCP server.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MemoryCache is an in-process LRU cache with per-entry TTLs.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	defaultTTL time.Duration
+	order      *list.List // front is most recently used
+	entries    map[string]*list.Element
+	now        func() time.Time
+}
+
+// memoryEntry is a cached value and its expiry
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // zero means no expiry
+}
+
+// NewMemoryCache creates an LRU cache holding at most maxEntries values.
+func NewMemoryCache(maxEntries int, defaultTTL time.Duration) *MemoryCache {
+	if maxEntries < 1 {
+		maxEntries = 1
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		defaultTTL: defaultTTL,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+// Get retrieves a value from the cache.
+func (c *MemoryCache) Get(_ context.Context, key string) ([]byte, error) {
+	if key == "" {
+		return nil, ErrInvalidKey
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	entry := elem.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt) {
+		c.removeElement(elem)
+		return nil, ErrCacheMiss
+	}
+	c.order.MoveToFront(elem)
+	return entry.value, nil
+}
+
+// GetJSON retrieves and unmarshals a JSON value from the cache.
+func (c *MemoryCache) GetJSON(ctx context.Context, key string, dest interface{}) error {
+	data, err := c.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dest)
+}
+
+// Set stores a value with the default TTL.
+func (c *MemoryCache) Set(ctx context.Context, key string, value []byte) error {
+	return c.SetWithTTL(ctx, key, value, c.defaultTTL)
+}
+
+// SetWithTTL stores a value with a specific TTL, evicting the least recently used entry when full.
+func (c *MemoryCache) SetWithTTL(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	if key == "" {
+		return ErrInvalidKey
+	}
+
+	entry := &memoryEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expiresAt = c.now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		c.removeElement(c.order.Back())
+	}
+	return nil
+}
+
+// SetJSON marshals and stores a value with the default TTL.
+func (c *MemoryCache) SetJSON(ctx context.Context, key string, value interface{}) error {
+	return c.SetJSONWithTTL(ctx, key, value, c.defaultTTL)
+}
+
+// SetJSONWithTTL marshals and stores a value with a specific TTL.
+func (c *MemoryCache) SetJSONWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSerializeFailed, err)
+	}
+	return c.SetWithTTL(ctx, key, data, ttl)
+}
+
+// GetOrSetJSON retrieves a JSON value from cache or sets it using the provided function.
+func (c *MemoryCache) GetOrSetJSON(ctx context.Context, key string, ttl time.Duration, dest interface{}, fn func() (interface{}, error)) error {
+	err := c.GetJSON(ctx, key, dest)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, ErrCacheMiss) {
+		return err
+	}
+
+	val, err := fn()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSerializeFailed, err)
+	}
+	_ = c.SetWithTTL(ctx, key, data, ttl)
+
+	return json.Unmarshal(data, dest)
+}
+
+// Delete removes a value from the cache.
+func (c *MemoryCache) Delete(_ context.Context, key string) error {
+	if key == "" {
+		return ErrInvalidKey
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
+	}
+	return nil
+}
+
+// Len returns the number of entries, including expired entries not yet evicted.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// removeElement drops an entry; the caller must hold the lock
+func (c *MemoryCache) removeElement(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*memoryEntry).key)
+}
@@ -0,0 +1,231 @@
+// Package cache provides Redis-based caching for the MCP server.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/pkg/telemetry"
+)
+
+// Tool result cache status values, reported in the result _meta under ToolCacheMetaKey
+const (
+	ToolCacheHit    = "hit"
+	ToolCacheMiss   = "miss"
+	ToolCacheBypass = "bypass"
+
+	// ToolCacheMetaKey is the result _meta key carrying the cache status
+	ToolCacheMetaKey = "cache"
+
+	toolResultKeyPrefix = "tool-result:"
+)
+
+// errNotCacheable aborts GetOrSetJSON so the result is returned without being stored
+var errNotCacheable = errors.New("tool result is not cacheable")
+
+// JSONStore is the key-value store behind a ToolResultCache; both RedisCache and MemoryCache implement it.
+type JSONStore interface {
+	GetOrSetJSON(ctx context.Context, key string, ttl time.Duration, dest interface{}, fn func() (interface{}, error)) error
+	SetJSONWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+}
+
+// ToolCachePolicy controls caching for one tool
+type ToolCachePolicy struct {
+	TTL          time.Duration
+	MaxEntrySize int           // bytes of encoded result; zero means unlimited
+	KeyArgs      []string      // arguments that form the key; empty means all
+	TimeBucket   time.Duration // truncation applied to RFC 3339 timestamp arguments
+
+	// Shared serves entries to every session; otherwise entries are only served to the session that stored them
+	Shared bool
+}
+
+// ToolResultCache serves repeated calls to idempotent tools from a store.
+type ToolResultCache struct {
+	store    JSONStore
+	policies map[string]ToolCachePolicy
+	metrics  *telemetry.Metrics
+}
+
+// NewToolResultCache creates a cache for the tools in policies; metrics may be nil.
+func NewToolResultCache(store JSONStore, policies map[string]ToolCachePolicy, metrics *telemetry.Metrics) *ToolResultCache {
+	return &ToolResultCache{store: store, policies: policies, metrics: metrics}
+}
+
+// Execute returns the cached result for toolName and args, calling run on a miss.
+// Entries are scoped to scope, usually the session ID, unless the policy is Shared. Tools without a policy always run. With bypass set, run is called and its result replaces the cached entry.
+// Error results and results larger than the policy's MaxEntrySize are never stored.
+func (c *ToolResultCache) Execute(
+	ctx context.Context,
+	scope string,
+	toolName string,
+	args map[string]interface{},
+	bypass bool,
+	run func(context.Context) (*entities.ToolResult, error),
+) (*entities.ToolResult, error) {
+	policy, ok := c.policies[toolName]
+	if !ok {
+		return run(ctx)
+	}
+
+	if policy.Shared {
+		scope = ""
+	}
+	key, err := ToolResultKey(scope, toolName, args, policy)
+	if err != nil {
+		return run(ctx)
+	}
+
+	if bypass {
+		result, err := run(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if data, ok := cacheable(result, policy); ok {
+			_ = c.store.SetJSONWithTTL(ctx, key, data, policy.TTL)
+		}
+		result.SetMeta(ToolCacheMetaKey, ToolCacheBypass)
+		return result, nil
+	}
+
+	var (
+		fresh  *entities.ToolResult
+		runErr error
+		called bool
+	)
+	var cached entities.ToolResult
+	err = c.store.GetOrSetJSON(ctx, key, policy.TTL, &cached, func() (interface{}, error) {
+		called = true
+		fresh, runErr = run(ctx)
+		if runErr != nil {
+			return nil, runErr
+		}
+		data, ok := cacheable(fresh, policy)
+		if !ok {
+			return nil, errNotCacheable
+		}
+		return data, nil
+	})
+
+	if !called {
+		if err != nil {
+			// The store is unavailable; serve the call uncached
+			return run(ctx)
+		}
+		c.record(ctx, toolName, true)
+		cached.SetMeta(ToolCacheMetaKey, ToolCacheHit)
+		return &cached, nil
+	}
+
+	c.record(ctx, toolName, false)
+	if runErr != nil {
+		return nil, runErr
+	}
+	fresh.SetMeta(ToolCacheMetaKey, ToolCacheMiss)
+	return fresh, nil
+}
+
+// record reports a lookup to the metrics, if configured
+func (c *ToolResultCache) record(ctx context.Context, toolName string, hit bool) {
+	if c.metrics != nil {
+		c.metrics.RecordToolCacheLookup(ctx, toolName, hit)
+	}
+}
+
+// cacheable returns the encoded result when it may be stored under policy
+func cacheable(result *entities.ToolResult, policy ToolCachePolicy) (json.RawMessage, bool) {
+	if result == nil || result.IsError {
+		return nil, false
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, false
+	}
+	if policy.MaxEntrySize > 0 && len(data) > policy.MaxEntrySize {
+		return nil, false
+	}
+	return data, true
+}
+
+// ToolResultKey builds the cache key for a call in scope from its normalized arguments; an empty scope is shared.
+// Keys are independent of map order, omit null arguments and apply the policy's KeyArgs and TimeBucket.
+func ToolResultKey(scope, toolName string, args map[string]interface{}, policy ToolCachePolicy) (string, error) {
+	normalized := make(map[string]interface{}, len(args))
+	if len(policy.KeyArgs) > 0 {
+		for _, name := range policy.KeyArgs {
+			if value, ok := args[name]; ok && value != nil {
+				normalized[name] = normalizeArg(value, policy.TimeBucket)
+			}
+		}
+	} else {
+		for name, value := range args {
+			if value != nil {
+				normalized[name] = normalizeArg(value, policy.TimeBucket)
+			}
+		}
+	}
+
+	// encoding/json sorts map keys, so equal arguments always encode identically
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	if scope == "" {
+		return toolResultKeyPrefix + toolName + ":" + hex.EncodeToString(sum[:]), nil
+	}
+	return toolResultKeyPrefix + scope + ":" + toolName + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// normalizeArg truncates timestamps to bucket and drops nulls from nested objects
+func normalizeArg(value interface{}, bucket time.Duration) interface{} {
+	switch v := value.(type) {
+	case string:
+		if bucket <= 0 {
+			return v
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return v
+		}
+		return t.UTC().Truncate(bucket).Format(time.RFC3339)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if item != nil {
+				out[key] = normalizeArg(item, bucket)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = normalizeArg(item, bucket)
+		}
+		return out
+	default:
+		return v
+	}
+}
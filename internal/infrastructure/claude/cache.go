@@ -0,0 +1,127 @@
+// Package claude contains the Claude API client implementation
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package claude
+
+import (
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// The API caches the prefix of a request up to each block marked with cache_control. The prefix runs
+// through the tool definitions, the system prompt and then the messages, and a request may mark at
+// most four blocks
+const maxCacheBreakpoints = 4
+
+// Prefixes shorter than these are not cached by the API
+const (
+	defaultCacheMinTokens = 1024
+	haikuCacheMinTokens   = 2048
+)
+
+// ephemeralCache marks the end of a cached prefix
+var ephemeralCache = anthropic.CacheControlEphemeralParam{Type: "ephemeral"}
+
+// blockRef names a content block of a request message
+type blockRef struct {
+	message, block int
+}
+
+// cachePlan says which parts of a request end with a cache breakpoint
+type cachePlan struct {
+	tools  bool
+	system bool
+	blocks map[blockRef]bool
+}
+
+// count returns the number of breakpoints in the plan
+func (p cachePlan) count() int {
+	n := len(p.blocks)
+	if p.tools {
+		n++
+	}
+	if p.system {
+		n++
+	}
+	return n
+}
+
+// planCache places up to four breakpoints, each where the prefix before it reaches minTokens, in
+// order of priority:
+//  1. after the system prompt, which is resent unchanged with the tool definitions on every call
+//  2. after the last message of a conversation with history, so the next turn reads it from the cache
+//  3. after blocks marked as cache breakpoints, such as telemetry context, newest first
+//  4. after the tool definitions, which keeps them cached when the system prompt changes
+func planCache(request *services.ClaudeRequest, minTokens int) cachePlan {
+	plan := cachePlan{blocks: make(map[blockRef]bool)}
+
+	toolTokens := 0
+	for _, tool := range request.Tools {
+		toolTokens += services.EstimateToolTokens(tool)
+	}
+	prefix := toolTokens + len(request.SystemPrompt.String())/4
+	if !request.SystemPrompt.IsEmpty() && prefix >= minTokens {
+		plan.system = true
+	}
+
+	var marked []blockRef
+	var last *blockRef
+	for i, message := range request.Messages {
+		for j, block := range message.Content {
+			prefix += services.EstimateBlockTokens(block)
+			// Empty text blocks cannot carry a breakpoint
+			if prefix < minTokens || (block.Type == vo.ContentTypeText && block.Text == "") {
+				continue
+			}
+			ref := blockRef{message: i, block: j}
+			if block.CacheBreakpoint {
+				marked = append(marked, ref)
+			}
+			if i == len(request.Messages)-1 && j == len(message.Content)-1 {
+				last = &ref
+			}
+		}
+	}
+	if last != nil && len(request.Messages) > 1 {
+		plan.blocks[*last] = true
+	}
+	for i := len(marked) - 1; i >= 0 && plan.count() < maxCacheBreakpoints; i-- {
+		plan.blocks[marked[i]] = true
+	}
+
+	if len(request.Tools) > 0 && toolTokens >= minTokens && plan.count() < maxCacheBreakpoints {
+		plan.tools = true
+	}
+	return plan
+}
+
+// cacheMinTokens returns the smallest prefix worth caching for model; configured overrides it
+func cacheMinTokens(model vo.Model, configured int) int {
+	switch {
+	case configured > 0:
+		return configured
+	case strings.Contains(model.String(), "haiku"):
+		return haikuCacheMinTokens
+	default:
+		return defaultCacheMinTokens
+	}
+}
@@ -27,12 +27,14 @@ import (
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/anthropics/anthropic-sdk-go/packages/ssestream"
 	"github.com/rs/zerolog"
 
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
 	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/llm"
 )
 
 // Client errors
@@ -49,6 +51,7 @@ var (
 type Client struct {
 	client anthropic.Client
 	config *config.ClaudeConfig
+	retry  llm.RetryPolicy
 	logger zerolog.Logger
 }
 
@@ -58,19 +61,25 @@ func NewClient(cfg *config.ClaudeConfig, logger zerolog.Logger) (*Client, error)
 		return nil, ErrAPIKeyRequired
 	}
 
+	// Retries are left to the shared retry engine rather than the SDK
 	opts := []option.RequestOption{
 		option.WithAPIKey(cfg.APIKey),
+		option.WithMaxRetries(0),
 	}
 
 	if cfg.BaseURL != "" {
 		opts = append(opts, option.WithBaseURL(cfg.BaseURL))
 	}
+	if cfg.Timeout > 0 {
+		opts = append(opts, option.WithRequestTimeout(cfg.Timeout))
+	}
 
 	client := anthropic.NewClient(opts...)
 
 	return &Client{
 		client: client,
 		config: cfg,
+		retry:  llm.NewRetryPolicy(cfg.MaxRetries, cfg.RetryDelay),
 		logger: logger.With().Str("component", "claude-client").Logger(),
 	}, nil
 }
@@ -92,27 +101,13 @@ func (c *Client) CreateMessage(ctx context.Context, request *services.ClaudeRequ
 
 	// Execute with retry
 	var response *anthropic.Message
-	var err error
-
-	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
-		if attempt > 0 {
-			c.logger.Debug().Int("attempt", attempt).Msg("Retrying API request")
-			time.Sleep(c.config.RetryDelay * time.Duration(attempt))
-		}
-
+	err := c.retry.Do(ctx, c.logger, func() error {
+		var err error
 		response, err = c.client.Messages.New(ctx, params)
-		if err == nil {
-			break
-		}
-
-		// Check if error is retryable
-		if !c.isRetryableError(err) {
-			return nil, fmt.Errorf("%w: %v", ErrAPIError, err)
-		}
-	}
-
+		return statusError(err)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrMaxRetriesExceeded, err)
+		return nil, c.apiError(err)
 	}
 
 	return c.convertResponse(response), nil
@@ -136,23 +131,48 @@ func (c *Client) CreateMessageStream(ctx context.Context, request *services.Clau
 	go func() {
 		defer close(eventChan)
 
-		stream := c.client.Messages.NewStreaming(ctx, params)
+		// send delivers an event unless the caller has gone away
+		send := func(event *services.ClaudeStreamEvent) bool {
+			select {
+			case eventChan <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// Opening the stream is retried until the first event arrives; later failures end the stream
+		var stream *ssestream.Stream[anthropic.MessageStreamEventUnion]
+		var started bool
+		err := c.retry.Do(ctx, c.logger, func() error {
+			stream = c.client.Messages.NewStreaming(ctx, params)
+			if started = stream.Next(); started || stream.Err() == nil {
+				return nil
+			}
+			_ = stream.Close()
+			return statusError(stream.Err())
+		})
+		if err != nil {
+			send(&services.ClaudeStreamEvent{Error: err})
+			return
+		}
+		defer func() { _ = stream.Close() }()
 
-		for stream.Next() {
+		for ok := started; ok; ok = stream.Next() {
 			event := stream.Current()
 			streamEvent := c.convertStreamEvent(event)
-			if streamEvent != nil {
+			if streamEvent != nil && !send(streamEvent) {
+				// Returning closes the upstream stream; the caller may no longer be reading
 				select {
-				case eventChan <- streamEvent:
-				case <-ctx.Done():
-					eventChan <- &services.ClaudeStreamEvent{Error: ctx.Err()}
-					return
+				case eventChan <- &services.ClaudeStreamEvent{Error: ctx.Err()}:
+				default:
 				}
+				return
 			}
 		}
 
 		if err := stream.Err(); err != nil {
-			eventChan <- &services.ClaudeStreamEvent{Error: err}
+			send(&services.ClaudeStreamEvent{Error: err})
 		}
 	}()
 
@@ -166,7 +186,7 @@ func (c *Client) CountTokens(ctx context.Context, request *services.ClaudeReques
 	}
 
 	// Build messages for token counting
-	messages := c.buildMessages(request.Messages)
+	messages := c.buildMessages(request.Messages, nil)
 
 	params := anthropic.MessageCountTokensParams{
 		Model:    anthropic.Model(request.Model.String()),
@@ -181,9 +201,14 @@ func (c *Client) CountTokens(ctx context.Context, request *services.ClaudeReques
 		}
 	}
 
-	result, err := c.client.Messages.CountTokens(ctx, params)
+	var result *anthropic.MessageTokensCount
+	err := c.retry.Do(ctx, c.logger, func() error {
+		var err error
+		result, err = c.client.Messages.CountTokens(ctx, params)
+		return statusError(err)
+	})
 	if err != nil {
-		return 0, fmt.Errorf("%w: %v", ErrAPIError, err)
+		return 0, c.apiError(err)
 	}
 
 	return int(result.InputTokens), nil
@@ -210,13 +235,19 @@ func (c *Client) ValidateRequest(request *services.ClaudeRequest) error {
 	return nil
 }
 
-// buildMessageParams builds the API request parameters
+// buildMessageParams builds the API request parameters, with cache breakpoints when prompt caching
+// is enabled and extended thinking when reasoning is asked for
 func (c *Client) buildMessageParams(request *services.ClaudeRequest) anthropic.MessageNewParams {
-	messages := c.buildMessages(request.Messages)
+	var plan cachePlan
+	if c.config.PromptCaching.Enabled {
+		plan = planCache(request, cacheMinTokens(request.Model, c.config.PromptCaching.MinTokens))
+	}
+	messages := c.buildMessages(request.Messages, plan.blocks)
 
+	maxTokens, thinkingBudget := request.OutputTokens()
 	params := anthropic.MessageNewParams{
 		Model:     anthropic.Model(request.Model.String()),
-		MaxTokens: int64(request.MaxTokens),
+		MaxTokens: int64(maxTokens),
 		Messages:  messages,
 	}
 
@@ -225,21 +256,29 @@ func (c *Client) buildMessageParams(request *services.ClaudeRequest) anthropic.M
 		params.System = []anthropic.TextBlockParam{
 			{Text: request.SystemPrompt.String()},
 		}
+		if plan.system {
+			params.System[0].CacheControl = ephemeralCache
+		}
 	}
 
-	// Temperature (only set if not default)
-	if request.Temperature > 0 && request.Temperature != 1.0 {
-		params.Temperature = anthropic.Float(request.Temperature)
-	}
+	// Extended thinking does not allow changing the sampling settings
+	if thinkingBudget > 0 {
+		params.Thinking = anthropic.ThinkingConfigParamOfThinkingConfigEnabled(int64(thinkingBudget))
+	} else {
+		// Temperature (only set if not default)
+		if request.Temperature > 0 && request.Temperature != 1.0 {
+			params.Temperature = anthropic.Float(request.Temperature)
+		}
 
-	// Top P
-	if request.TopP > 0 && request.TopP < 1.0 {
-		params.TopP = anthropic.Float(request.TopP)
-	}
+		// Top P
+		if request.TopP > 0 && request.TopP < 1.0 {
+			params.TopP = anthropic.Float(request.TopP)
+		}
 
-	// Top K
-	if request.TopK > 0 {
-		params.TopK = anthropic.Int(int64(request.TopK))
+		// Top K
+		if request.TopK > 0 {
+			params.TopK = anthropic.Int(int64(request.TopK))
+		}
 	}
 
 	// Stop sequences
@@ -250,23 +289,32 @@ func (c *Client) buildMessageParams(request *services.ClaudeRequest) anthropic.M
 	// Tools
 	if len(request.Tools) > 0 {
 		params.Tools = c.buildTools(request.Tools)
+		if plan.tools {
+			*params.Tools[len(params.Tools)-1].GetCacheControl() = ephemeralCache
+		}
 	}
 
 	return params
 }
 
-// buildMessages builds API messages from domain messages
-func (c *Client) buildMessages(messages []services.ClaudeMessage) []anthropic.MessageParam {
+// buildMessages builds API messages from domain messages, ending the blocks in breakpoints with a cache breakpoint
+func (c *Client) buildMessages(messages []services.ClaudeMessage, breakpoints map[blockRef]bool) []anthropic.MessageParam {
 	result := make([]anthropic.MessageParam, len(messages))
 
 	for i, msg := range messages {
 		var content []anthropic.ContentBlockParamUnion
 
-		for _, block := range msg.Content {
+		for j, block := range msg.Content {
+			built := len(content)
 			switch block.Type {
 			case vo.ContentTypeText:
 				content = append(content, anthropic.NewTextBlock(block.Text))
 
+			case vo.ContentTypeImage, vo.ContentTypeDocument:
+				if media, ok := mediaBlock(block); ok {
+					content = append(content, media)
+				}
+
 			case vo.ContentTypeToolUse:
 				// Tool use blocks are only in assistant responses
 				content = append(content, anthropic.ContentBlockParamOfRequestToolUseBlock(
@@ -281,6 +329,22 @@ func (c *Client) buildMessages(messages []services.ClaudeMessage) []anthropic.Me
 					block.Content,
 					block.IsError,
 				))
+
+			case vo.ContentTypeThinking:
+				// Thinking must be sent back with its signature; reasoning of other providers has none
+				if block.Signature != "" {
+					content = append(content, anthropic.ContentBlockParamOfRequestThinkingBlock(block.Signature, block.Thinking))
+				}
+
+			case vo.ContentTypeRedactedThinking:
+				if block.Data != "" {
+					content = append(content, anthropic.ContentBlockParamOfRequestRedactedThinkingBlock(block.Data))
+				}
+			}
+			if breakpoints[blockRef{message: i, block: j}] && len(content) > built {
+				if cacheControl := content[len(content)-1].GetCacheControl(); cacheControl != nil {
+					*cacheControl = ephemeralCache
+				}
 			}
 		}
 
@@ -293,6 +357,32 @@ func (c *Client) buildMessages(messages []services.ClaudeMessage) []anthropic.Me
 	return result
 }
 
+// mediaBlock builds an image or PDF document block from its base64 data or URL
+func mediaBlock(block entities.ContentBlock) (anthropic.ContentBlockParamUnion, bool) {
+	source := block.Source
+	if source == nil {
+		return anthropic.ContentBlockParamUnion{}, false
+	}
+
+	if block.Type == vo.ContentTypeImage {
+		if source.Type == "url" {
+			return anthropic.ContentBlockParamOfRequestImageBlock(anthropic.URLImageSourceParam{URL: source.URL}), true
+		}
+		return anthropic.NewImageBlockBase64(source.MediaType, source.Data), true
+	}
+
+	var document anthropic.ContentBlockParamUnion
+	if source.Type == "url" {
+		document = anthropic.ContentBlockParamOfRequestDocumentBlock(anthropic.URLPDFSourceParam{URL: source.URL})
+	} else {
+		document = anthropic.ContentBlockParamOfRequestDocumentBlock(anthropic.Base64PDFSourceParam{Data: source.Data})
+	}
+	if block.Name != "" {
+		document.OfRequestDocumentBlock.Title = anthropic.String(block.Name)
+	}
+	return document, true
+}
+
 // buildTools builds API tools from domain tools
 func (c *Client) buildTools(tools []services.ClaudeTool) []anthropic.ToolUnionParam {
 	result := make([]anthropic.ToolUnionParam, len(tools))
@@ -371,6 +461,19 @@ func (c *Client) convertResponse(msg *anthropic.Message) *services.ClaudeRespons
 				Name:  block.Name,
 				Input: input,
 			})
+
+		case "thinking":
+			content = append(content, entities.ContentBlock{
+				Type:      vo.ContentTypeThinking,
+				Thinking:  block.Thinking,
+				Signature: block.Signature,
+			})
+
+		case "redacted_thinking":
+			content = append(content, entities.ContentBlock{
+				Type: vo.ContentTypeRedactedThinking,
+				Data: block.Data,
+			})
 		}
 	}
 
@@ -382,8 +485,10 @@ func (c *Client) convertResponse(msg *anthropic.Message) *services.ClaudeRespons
 		Model:      string(msg.Model),
 		StopReason: string(msg.StopReason),
 		Usage: &services.ClaudeUsage{
-			InputTokens:  int(msg.Usage.InputTokens),
-			OutputTokens: int(msg.Usage.OutputTokens),
+			InputTokens:              int(msg.Usage.InputTokens),
+			OutputTokens:             int(msg.Usage.OutputTokens),
+			CacheCreationInputTokens: int(msg.Usage.CacheCreationInputTokens),
+			CacheReadInputTokens:     int(msg.Usage.CacheReadInputTokens),
 		},
 	}
 }
@@ -397,9 +502,16 @@ func (c *Client) convertStreamEvent(event anthropic.MessageStreamEventUnion) *se
 				Type: event.Type,
 				Message: &services.ClaudeResponse{
 					ID:    event.Message.ID,
+					Type:  "message",
 					Model: string(event.Message.Model),
 					Role:  vo.RoleAssistant,
 				},
+				Usage: &services.ClaudeUsage{
+					InputTokens:              int(event.Message.Usage.InputTokens),
+					OutputTokens:             int(event.Message.Usage.OutputTokens),
+					CacheCreationInputTokens: int(event.Message.Usage.CacheCreationInputTokens),
+					CacheReadInputTokens:     int(event.Message.Usage.CacheReadInputTokens),
+				},
 			}
 		}
 
@@ -425,6 +537,25 @@ func (c *Client) convertStreamEvent(event anthropic.MessageStreamEventUnion) *se
 					Name: block.Name,
 				},
 			}
+		case "thinking":
+			return &services.ClaudeStreamEvent{
+				Type:  event.Type,
+				Index: int(event.Index),
+				ContentBlock: &entities.ContentBlock{
+					Type:      vo.ContentTypeThinking,
+					Thinking:  block.Thinking,
+					Signature: block.Signature,
+				},
+			}
+		case "redacted_thinking":
+			return &services.ClaudeStreamEvent{
+				Type:  event.Type,
+				Index: int(event.Index),
+				ContentBlock: &entities.ContentBlock{
+					Type: vo.ContentTypeRedactedThinking,
+					Data: block.Data,
+				},
+			}
 		}
 
 	case "content_block_delta":
@@ -433,8 +564,11 @@ func (c *Client) convertStreamEvent(event anthropic.MessageStreamEventUnion) *se
 			Type:  event.Type,
 			Index: int(event.Index),
 			Delta: &services.ClaudeDelta{
-				Type: delta.Type,
-				Text: delta.Text,
+				Type:        delta.Type,
+				Text:        delta.Text,
+				PartialJSON: delta.PartialJSON,
+				Thinking:    delta.Thinking,
+				Signature:   delta.Signature,
 			},
 		}
 
@@ -442,7 +576,8 @@ func (c *Client) convertStreamEvent(event anthropic.MessageStreamEventUnion) *se
 		return &services.ClaudeStreamEvent{
 			Type: event.Type,
 			Delta: &services.ClaudeDelta{
-				StopReason: string(event.Delta.StopReason),
+				StopReason:   string(event.Delta.StopReason),
+				StopSequence: event.Delta.StopSequence,
 			},
 			Usage: &services.ClaudeUsage{
 				OutputTokens: int(event.Usage.OutputTokens),
@@ -458,9 +593,23 @@ func (c *Client) convertStreamEvent(event anthropic.MessageStreamEventUnion) *se
 	return nil
 }
 
-// isRetryableError checks if an error is retryable
-func (c *Client) isRetryableError(err error) bool {
-	// Check for rate limiting or temporary errors
-	// This would need to inspect the actual error type from the SDK
-	return false
+// apiError wraps a failed call in ErrAPIError, and in ErrMaxRetriesExceeded when it was retried
+func (c *Client) apiError(err error) error {
+	if c.retry.MaxRetries > 0 && llm.IsRetryable(err) {
+		return fmt.Errorf("%w: %w: %w", ErrAPIError, ErrMaxRetriesExceeded, err)
+	}
+	return fmt.Errorf("%w: %w", ErrAPIError, err)
+}
+
+// statusError attaches the status code and retry-after headers of an API error for the retry engine
+func statusError(err error) error {
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	statusErr := &llm.StatusError{StatusCode: apiErr.StatusCode, Err: err}
+	if apiErr.Response != nil {
+		statusErr.RetryAfter = llm.ParseRetryAfter(apiErr.Response.Header, time.Now())
+	}
+	return statusErr
 }
@@ -21,23 +21,43 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
 // Config holds all configuration for the MCP server
 type Config struct {
 	Server     ServerConfig     `mapstructure:"server"`
 	Claude     ClaudeConfig     `mapstructure:"claude"`
+	Providers  ProvidersConfig  `mapstructure:"providers"`
+	Routing    RoutingConfig    `mapstructure:"routing"`
+	Agent      AgentConfig      `mapstructure:"agent"`
+	Compaction CompactionConfig `mapstructure:"compaction"`
 	MCP        MCPConfig        `mapstructure:"mcp"`
 	Logging    LoggingConfig    `mapstructure:"logging"`
 	Telemetry  TelemetryConfig  `mapstructure:"telemetry"`
 	Security   SecurityConfig   `mapstructure:"security"`
+	Tasks      TasksConfig      `mapstructure:"tasks"`
+	Upstreams  UpstreamsConfig  `mapstructure:"upstreams"`
+	ToolCache  ToolCacheConfig  `mapstructure:"tool_cache"`
+	Audit      AuditConfig      `mapstructure:"audit"`
+	Usage      UsageConfig      `mapstructure:"usage"`
+	Budgets    BudgetsConfig    `mapstructure:"budgets"`
 	Database   DatabaseConfig   `mapstructure:"database"`
 	Clickhouse ClickHouseConfig `mapstructure:"clickhouse"`
+
+	// HTTPTools and Pipelines are read from the config file separately so map keys keep their case
+	HTTPTools []HTTPToolConfig `mapstructure:"-"`
+	Pipelines []PipelineConfig `mapstructure:"-"`
 }
 
 type DatabaseConfig struct {
@@ -89,7 +109,9 @@ type ServerConfig struct {
 
 // ClaudeConfig holds Claude API configuration
 type ClaudeConfig struct {
+	Enabled        *bool         `mapstructure:"enabled"` // nil enables Anthropic when an API key is set
 	APIKey         string        `mapstructure:"api_key"`
+	APIKeyFile     string        `mapstructure:"api_key_file"` // read at load time when api_key is empty
 	BaseURL        string        `mapstructure:"base_url"`
 	DefaultModel   string        `mapstructure:"default_model"`
 	MaxTokens      int           `mapstructure:"max_tokens"`
@@ -100,6 +122,224 @@ type ClaudeConfig struct {
 	MaxRetries     int           `mapstructure:"max_retries"`
 	RetryDelay     time.Duration `mapstructure:"retry_delay"`
 	EnableBatching bool          `mapstructure:"enable_batching"`
+
+	PromptCaching PromptCachingConfig `mapstructure:"prompt_caching"`
+}
+
+// PromptCachingConfig controls provider-side caching of the stable prefix of requests: the
+// system prompt, tool definitions, context blocks and conversation history
+type PromptCachingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// MinTokens is the smallest prefix worth a cache breakpoint, 0 uses the model's minimum
+	MinTokens int `mapstructure:"min_tokens"`
+}
+
+// IsEnabled reports whether the Anthropic backend is configured for use
+func (c ClaudeConfig) IsEnabled() bool {
+	if c.Enabled != nil && !*c.Enabled {
+		return false
+	}
+	return c.APIKey != ""
+}
+
+// ProvidersConfig holds the non-Anthropic LLM backends, keyed by provider name (openai, deepseek, ollama, ...)
+type ProvidersConfig map[string]ProviderConfig
+
+// ProviderConfig holds the settings of one LLM provider backend
+type ProviderConfig struct {
+	Enabled      *bool         `mapstructure:"enabled"` // nil enables the provider once it is configured
+	APIKey       string        `mapstructure:"api_key"`
+	APIKeyFile   string        `mapstructure:"api_key_file"`  // read at load time when api_key is empty
+	BaseURL      string        `mapstructure:"base_url"`      // empty uses the provider's public endpoint
+	Organization string        `mapstructure:"organization"`  // sent as the OpenAI-Organization header
+	Project      string        `mapstructure:"project"`       // sent as the OpenAI-Project header
+	DefaultModel string        `mapstructure:"default_model"` // served when a request names only the provider
+	Timeout      time.Duration `mapstructure:"timeout"`       // per request, 0 waits indefinitely
+	MaxRetries   int           `mapstructure:"max_retries"`
+	RetryDelay   time.Duration `mapstructure:"retry_delay"`
+	ToolCalling  string        `mapstructure:"tool_calling"` // local providers: auto, native or emulated
+
+	// PromptCaching sends a prompt_cache_key to OpenAI-compatible providers, so that requests with
+	// the same prefix are routed to the same cache; other providers cache without one
+	PromptCaching PromptCachingConfig `mapstructure:"prompt_caching"`
+}
+
+// IsEnabled reports whether a provider is configured for use: cloud providers need an API key,
+// a providers.ollama section is enough for Ollama, and other local servers need a base URL.
+// Setting enabled: false turns a configured provider off
+func (p ProvidersConfig) IsEnabled(name string) bool {
+	provider, ok := p[name]
+	switch {
+	case !ok:
+		return false
+	case provider.Enabled != nil && !*provider.Enabled:
+		return false
+	case name == "ollama":
+		return true
+	case name == "local":
+		return provider.BaseURL != ""
+	default:
+		return provider.APIKey != ""
+	}
+}
+
+// Validate validates the provider settings
+func (p ProvidersConfig) Validate() error {
+	for name, provider := range p {
+		switch provider.ToolCalling {
+		case "", "auto", "native", "emulated":
+		default:
+			return fmt.Errorf("providers.%s.tool_calling must be auto, native or emulated", name)
+		}
+		if provider.Enabled != nil && *provider.Enabled && !p.IsEnabled(name) {
+			if name == "local" {
+				return fmt.Errorf("providers.%s.base_url is required when the provider is enabled", name)
+			}
+			return fmt.Errorf("providers.%s.api_key is required when the provider is enabled", name)
+		}
+		if provider.Timeout < 0 || provider.MaxRetries < 0 || provider.RetryDelay < 0 {
+			return fmt.Errorf("providers.%s: timeout, max_retries and retry_delay must not be negative", name)
+		}
+		if provider.PromptCaching.MinTokens < 0 {
+			return fmt.Errorf("providers.%s.prompt_caching.min_tokens must not be negative", name)
+		}
+		if provider.BaseURL == "" {
+			continue
+		}
+		if u, err := url.Parse(provider.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("providers.%s.base_url must be an absolute URL", name)
+		}
+	}
+	return nil
+}
+
+// RoutingConfig holds model aliases with their fallback chains and the per-model circuit breaker
+type RoutingConfig struct {
+	// Aliases maps a logical model name to the models tried in order, e.g. analyst-default
+	Aliases map[string][]string `mapstructure:"aliases"`
+
+	// AttemptTimeout bounds each model of a chain except the last, 0 leaves the call's own deadline
+	AttemptTimeout time.Duration `mapstructure:"attempt_timeout"`
+
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+}
+
+// CircuitBreakerConfig holds when a model is taken out of rotation
+type CircuitBreakerConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// The breaker opens when ErrorRate of at least MinRequests calls in Window failed
+	Window      time.Duration `mapstructure:"window"`
+	MinRequests int           `mapstructure:"min_requests"`
+	ErrorRate   float64       `mapstructure:"error_rate"`
+
+	// Calls slower than LatencyThreshold count as failures, 0 disables the check
+	LatencyThreshold time.Duration `mapstructure:"latency_threshold"`
+
+	// Cooldown is how long an open breaker waits before letting a trial call through
+	Cooldown time.Duration `mapstructure:"cooldown"`
+}
+
+// Validate validates the routing settings
+func (r RoutingConfig) Validate() error {
+	for alias, models := range r.Aliases {
+		if len(models) == 0 {
+			return fmt.Errorf("routing.aliases.%s must list at least one model", alias)
+		}
+		for _, model := range models {
+			if model == "" || model == alias {
+				return fmt.Errorf("routing.aliases.%s: invalid model %q", alias, model)
+			}
+			if _, ok := r.Aliases[model]; ok {
+				return fmt.Errorf("routing.aliases.%s: %s is an alias, list its models instead", alias, model)
+			}
+		}
+	}
+	if r.AttemptTimeout < 0 {
+		return errors.New("routing.attempt_timeout must not be negative")
+	}
+
+	cb := r.CircuitBreaker
+	if !cb.Enabled {
+		return nil
+	}
+	if cb.Window <= 0 || cb.Cooldown <= 0 {
+		return errors.New("routing.circuit_breaker.window and cooldown must be positive")
+	}
+	if cb.MinRequests < 1 {
+		return errors.New("routing.circuit_breaker.min_requests must be positive")
+	}
+	if cb.ErrorRate <= 0 || cb.ErrorRate > 1 {
+		return errors.New("routing.circuit_breaker.error_rate must be between 0 and 1")
+	}
+	if cb.LatencyThreshold < 0 {
+		return errors.New("routing.circuit_breaker.latency_threshold must not be negative")
+	}
+	return nil
+}
+
+// AgentConfig bounds the agent loop that lets an LLM call the server's own tools
+type AgentConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Model runs analyze_telemetry when the call names none; empty uses claude.default_model
+	Model string `mapstructure:"model"`
+
+	MaxIterations    int           `mapstructure:"max_iterations"`     // LLM calls per run
+	TokenBudget      int           `mapstructure:"token_budget"`       // input plus output tokens per run, 0 for no limit
+	MaxParallelTools int           `mapstructure:"max_parallel_tools"` // tool calls of one turn run at once
+	Timeout          time.Duration `mapstructure:"timeout"`            // per analyze_telemetry call
+
+	// AllowedTools lists the tools the LLM may call; "*" allows every enabled tool
+	AllowedTools []string `mapstructure:"allowed_tools"`
+}
+
+// DefaultAgentTools are the read-only tools an agent may call by default. The file tools are left
+// out: they are not limited to mcp.allowed_paths, and the agent reads untrusted telemetry and tool output
+var DefaultAgentTools = []string{
+	"collect_telemetry_context", "list_context_types", "system_info",
+	"search_audit_trail", "get_llm_usage",
+}
+
+// Validate validates the agent settings
+func (a AgentConfig) Validate() error {
+	if !a.Enabled {
+		return nil
+	}
+	if a.MaxIterations < 1 || a.MaxParallelTools < 1 {
+		return errors.New("agent.max_iterations and agent.max_parallel_tools must be positive")
+	}
+	if a.TokenBudget < 0 || a.Timeout < 0 {
+		return errors.New("agent.token_budget and agent.timeout must not be negative")
+	}
+	return nil
+}
+
+// CompactionConfig keeps conversations within their model's context window
+type CompactionConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	Threshold    float64 `mapstructure:"threshold"`     // share of the model's input budget that triggers compaction
+	KeepMessages int     `mapstructure:"keep_messages"` // most recent messages that are never compacted
+
+	// SummaryModel writes summaries of older turns; empty uses the conversation's model
+	SummaryModel     string `mapstructure:"summary_model"`
+	SummaryMaxTokens int    `mapstructure:"summary_max_tokens"`
+}
+
+// Validate checks the compaction settings
+func (c CompactionConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Threshold <= 0 || c.Threshold > 1 {
+		return errors.New("compaction.threshold must be greater than 0 and at most 1")
+	}
+	if c.KeepMessages < 1 || c.SummaryMaxTokens < 1 {
+		return errors.New("compaction.keep_messages and compaction.summary_max_tokens must be positive")
+	}
+	return nil
 }
 
 // MCPConfig holds MCP protocol configuration
@@ -122,6 +362,273 @@ type MCPConfig struct {
 
 	// Tool execution
 	ToolTimeout time.Duration `mapstructure:"tool_timeout"`
+
+	// File tools
+	MaxFileSize int64 `mapstructure:"max_file_size"`
+
+	// Directories whose files may be read as file:// resources and sent to LLMs as attachments;
+	// empty allows none
+	AllowedPaths []string `mapstructure:"allowed_paths"`
+}
+
+// TasksConfig holds background tool task configuration
+type TasksConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Backend: "memory" (in-process worker pool) or "nats"
+	Backend string `mapstructure:"backend"`
+
+	// Worker pool
+	Workers   int `mapstructure:"workers"`
+	QueueSize int `mapstructure:"queue_size"`
+	MaxTasks  int `mapstructure:"max_tasks"`
+
+	// Lifetimes
+	Timeout      time.Duration `mapstructure:"timeout"`
+	DefaultTTL   time.Duration `mapstructure:"default_ttl"`
+	MaxTTL       time.Duration `mapstructure:"max_ttl"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+
+	// NATS backend (instance ID defaults to the hostname)
+	InstanceID   string `mapstructure:"instance_id"`
+	NATSURL      string `mapstructure:"nats_url"`
+	NATSToken    string `mapstructure:"nats_token"`
+	NATSUsername string `mapstructure:"nats_username"`
+	NATSPassword string `mapstructure:"nats_password"`
+}
+
+// ToolCacheConfig holds the opt-in cache for idempotent tool results
+type ToolCacheConfig struct {
+	// Backend: "memory" (LRU, per process) or "redis" (shared)
+	Backend      string `mapstructure:"backend"`
+	MaxEntries   int    `mapstructure:"max_entries"`    // memory backend capacity
+	MaxEntrySize int    `mapstructure:"max_entry_size"` // bytes; results above this are not cached
+
+	RedisURL    string `mapstructure:"redis_url"`
+	RedisPrefix string `mapstructure:"redis_prefix"`
+
+	// Tools lists the cached tools; tools not listed are never cached
+	Tools []ToolCachePolicyConfig `mapstructure:"tools"`
+}
+
+// ToolCachePolicyConfig is the cache policy for one tool
+type ToolCachePolicyConfig struct {
+	Tool         string        `mapstructure:"tool"`
+	TTL          time.Duration `mapstructure:"ttl"`
+	MaxEntrySize int           `mapstructure:"max_entry_size"` // overrides tool_cache.max_entry_size
+
+	// KeyArgs limits the cache key to these arguments; empty uses all arguments
+	KeyArgs []string `mapstructure:"key_args"`
+	// TimeBucket truncates RFC 3339 timestamp arguments in the key so nearby time ranges share entries
+	TimeBucket time.Duration `mapstructure:"time_bucket"`
+	// Shared serves entries to every session; by default a session only sees results it cached itself
+	Shared bool `mapstructure:"shared"`
+}
+
+// uncacheableTools are built-in tools that read the filesystem, run commands, run an LLM or
+// report state that changes between calls, so their results are never cached
+var uncacheableTools = map[string]bool{
+	// Filesystem and commands
+	"read_file":       true,
+	"write_file":      true,
+	"edit_file":       true,
+	"list_directory":  true,
+	"search_files":    true,
+	"execute_command": true,
+	// LLM calls
+	"claude_conversation":   true,
+	"analyze_telemetry":     true,
+	"investigate_telemetry": true,
+	// Conversations
+	"start_conversation":        true,
+	"send_conversation_message": true,
+	"close_conversation":        true,
+	"get_conversation":          true,
+	"list_conversations":        true,
+	// Background tasks
+	"start_background_task": true,
+	"get_task_status":       true,
+	"get_task_result":       true,
+	"cancel_task":           true,
+	// Usage, budgets and audit
+	"get_llm_usage":      true,
+	"get_llm_budget":     true,
+	"search_audit_trail": true,
+}
+
+// AuditConfig holds the tool call audit trail configuration
+type AuditConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	BufferSize int           `mapstructure:"buffer_size"` // records waiting to be written before new ones are dropped
+	Retention  time.Duration `mapstructure:"retention"`   // zero keeps records forever
+	MaxRecords int           `mapstructure:"max_records"` // in-memory store capacity when PostgreSQL is disabled
+
+	// ClickHouse batches records into tool_call_analytics when clickhouse.enabled is set
+	ClickHouse    bool          `mapstructure:"clickhouse"`
+	BatchSize     int           `mapstructure:"batch_size"`
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+
+	// RedactArguments replaces the built-in list of argument names whose values are not stored
+	RedactArguments []string `mapstructure:"redact_arguments"`
+	MaxValueLength  int      `mapstructure:"max_value_length"` // longer string arguments are truncated; 0 disables
+}
+
+// UsageConfig holds the LLM usage and cost accounting configuration
+type UsageConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`
+	OrganizationID string `mapstructure:"organization_id"` // for calls from sessions that carry no organization_id
+	BufferSize     int    `mapstructure:"buffer_size"`     // records waiting to be written before new ones are dropped
+	MaxRecords     int    `mapstructure:"max_records"`     // in-memory store capacity when ClickHouse is not used
+
+	// ClickHouse writes records to api_request_analytics when clickhouse.enabled is set
+	ClickHouse    bool          `mapstructure:"clickhouse"`
+	BatchSize     int           `mapstructure:"batch_size"`
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+
+	Pricing PricingConfig `mapstructure:"pricing"`
+}
+
+// PricingConfig overrides the built-in model prices
+type PricingConfig struct {
+	// Version names the price table that costs are recorded with; required when Models is set
+	Version string             `mapstructure:"version"`
+	Models  []ModelPriceConfig `mapstructure:"models"`
+}
+
+// ModelPriceConfig is the price of a model in USD per million tokens
+type ModelPriceConfig struct {
+	Model      string  `mapstructure:"model"`
+	Input      float64 `mapstructure:"input"`
+	Output     float64 `mapstructure:"output"`
+	CacheRead  float64 `mapstructure:"cache_read"`
+	CacheWrite float64 `mapstructure:"cache_write"`
+}
+
+// BudgetsConfig holds the per-organization LLM budget configuration
+type BudgetsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Backend keeps the usage counters: "memory" (per process) or "redis" (shared)
+	Backend     string `mapstructure:"backend"`
+	RedisURL    string `mapstructure:"redis_url"`
+	RedisPrefix string `mapstructure:"redis_prefix"`
+
+	// RefreshInterval is how long an organization's budgets are cached before they are re-read
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+
+	// Limits are saved to the budget store at startup, replacing the stored budget of the same
+	// organization and period; budgets may also be managed in the llm_budgets table
+	Limits []BudgetLimitConfig `mapstructure:"limits"`
+
+	Notifications BudgetNotificationsConfig `mapstructure:"notifications"`
+}
+
+// BudgetLimitConfig is the budget of one organization for one period
+type BudgetLimitConfig struct {
+	OrganizationID string   `mapstructure:"organization_id"`
+	Period         string   `mapstructure:"period"`     // daily or monthly
+	Tokens         int64    `mapstructure:"tokens"`     // 0 leaves tokens unlimited
+	CostUSD        float64  `mapstructure:"cost_usd"`   // 0 leaves cost unlimited
+	WarnAt         *float64 `mapstructure:"warn_at"`    // share of a limit that sends a warning; nil is 0.8, 0 sends none
+	HardLimit      *bool    `mapstructure:"hard_limit"` // nil rejects calls over the limit
+	WebhookURL     string   `mapstructure:"webhook_url"`
+	Emails         []string `mapstructure:"emails"`
+}
+
+// BudgetNotificationsConfig holds where budget warnings are sent
+type BudgetNotificationsConfig struct {
+	WebhookURL string     `mapstructure:"webhook_url"` // for budgets without a webhook_url
+	Emails     []string   `mapstructure:"emails"`      // for budgets without emails
+	MaxRetries int        `mapstructure:"max_retries"` // webhook delivery retries
+	SMTP       SMTPConfig `mapstructure:"smtp"`
+}
+
+// SMTPConfig holds the mail server for email notifications; an empty host sends no email
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+}
+
+// UpstreamsConfig holds the upstream MCP servers re-exported by this server
+type UpstreamsConfig struct {
+	// ConnectTimeout bounds startup and the initialize handshake of each upstream
+	ConnectTimeout time.Duration          `mapstructure:"connect_timeout"`
+	Servers        []UpstreamServerConfig `mapstructure:"servers"`
+}
+
+// UpstreamServerConfig describes a single upstream MCP server
+type UpstreamServerConfig struct {
+	// Name prefixes re-exported tools and prompts, e.g. "github" gives "github__create_issue"
+	Name     string `mapstructure:"name"`
+	Disabled bool   `mapstructure:"disabled"`
+
+	// Transport: "stdio" (subprocess) or "http" (streamable HTTP)
+	Transport string `mapstructure:"transport"`
+
+	// stdio: NAME=value entries in env are expanded against this server's environment
+	Command        string   `mapstructure:"command"`
+	Args           []string `mapstructure:"args"`
+	Env            []string `mapstructure:"env"`
+	EnvPassthrough []string `mapstructure:"env_passthrough"`
+	WorkingDir     string   `mapstructure:"working_dir"`
+
+	// http: header values are expanded against this server's environment
+	URL     string            `mapstructure:"url"`
+	Headers map[string]string `mapstructure:"headers"`
+
+	// Timeout bounds forwarded tool calls, resource reads and prompt renders
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// HTTPToolConfig declares a tool backed by an HTTP endpoint
+type HTTPToolConfig struct {
+	Name        string                 `yaml:"name"`
+	Description string                 `yaml:"description"`
+	Category    string                 `yaml:"category"`
+	Tags        []string               `yaml:"tags"`
+	InputSchema map[string]interface{} `yaml:"input_schema"`
+
+	// Request: url, query values and body are Go templates over the tool arguments
+	Method  string            `yaml:"method"`
+	URL     string            `yaml:"url"`
+	Query   map[string]string `yaml:"query"`
+	Headers map[string]string `yaml:"headers"` // ${VAR} references are expanded at call time
+	Body    string            `yaml:"body"`
+
+	// ResponsePath is a JSONPath expression applied to a JSON response; empty returns the body
+	ResponsePath string        `yaml:"response_path"`
+	Timeout      time.Duration `yaml:"timeout"`
+}
+
+// PipelineConfig declares a composite tool that runs a DAG of other tools
+type PipelineConfig struct {
+	Name        string                 `yaml:"name"`
+	Description string                 `yaml:"description"`
+	Category    string                 `yaml:"category"`
+	Tags        []string               `yaml:"tags"`
+	InputSchema map[string]interface{} `yaml:"input_schema"`
+	Steps       []PipelineStepConfig   `yaml:"steps"`
+
+	// Output is a template rendering the tool result; empty returns the last successful step's text
+	Output  string        `yaml:"output"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// PipelineStepConfig is one tool call in a pipeline
+type PipelineStepConfig struct {
+	ID   string `yaml:"id"`
+	Tool string `yaml:"tool"`
+	// Arguments are passed to the tool; string values are templates over .input and .steps
+	Arguments map[string]interface{} `yaml:"arguments"`
+	DependsOn []string               `yaml:"depends_on"`
+	// When is a template; the step is skipped if it renders empty, "false" or "0"
+	When    string        `yaml:"when"`
+	OnError string        `yaml:"on_error"` // "fail" (default) or "continue"
+	Retries int           `yaml:"retries"`
+	Timeout time.Duration `yaml:"timeout"`
 }
 
 // LoggingConfig holds logging configuration
@@ -162,6 +669,48 @@ type SecurityConfig struct {
 	// CORS (for SSE transport)
 	CORSEnabled        bool     `mapstructure:"cors_enabled"`
 	CORSAllowedOrigins []string `mapstructure:"cors_allowed_origins"`
+
+	// Command execution policy (execute_command tool)
+	Command CommandPolicyConfig `mapstructure:"command"`
+}
+
+// CommandPolicyConfig holds the execute_command policy
+type CommandPolicyConfig struct {
+	// UseShell runs commands via "sh -c"; when false argv is executed directly
+	UseShell bool `mapstructure:"use_shell"`
+
+	// Glob patterns matched against argv[0] (full path or base name); deny wins
+	AllowedCommands []string `mapstructure:"allowed_commands"`
+	DeniedCommands  []string `mapstructure:"denied_commands"`
+
+	// Directories commands may start in, with cd refused; empty allows any directory.
+	// Commands can still name paths outside them, so this is not a filesystem sandbox
+	AllowedDirs []string `mapstructure:"allowed_dirs"`
+
+	// Environment variable names (glob patterns) passed to commands
+	EnvAllowlist []string `mapstructure:"env_allowlist"`
+
+	// Per-stream output cap in bytes; 0 disables the cap
+	MaxOutputBytes int64 `mapstructure:"max_output_bytes"`
+
+	DefaultTimeout time.Duration `mapstructure:"default_timeout"`
+	MaxTimeout     time.Duration `mapstructure:"max_timeout"`
+
+	// Minimum interval between streamed output progress notifications
+	ProgressInterval time.Duration `mapstructure:"progress_interval"`
+}
+
+// DefaultCommandPolicy returns the default execute_command policy
+func DefaultCommandPolicy() CommandPolicyConfig {
+	return CommandPolicyConfig{
+		UseShell:     true,
+		EnvAllowlist: []string{"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TERM", "TZ", "TMPDIR", "LANG", "LC_*"},
+		// 1MB per stream
+		MaxOutputBytes:   1024 * 1024,
+		DefaultTimeout:   30 * time.Second,
+		MaxTimeout:       5 * time.Minute,
+		ProgressInterval: 250 * time.Millisecond,
+	}
 }
 
 // DefaultConfig returns the default configuration
@@ -189,6 +738,7 @@ func DefaultConfig() *Config {
 			MaxRetries:     3,
 			RetryDelay:     1 * time.Second,
 			EnableBatching: false,
+			PromptCaching:  PromptCachingConfig{Enabled: true},
 		},
 		MCP: MCPConfig{
 			ProtocolVersion:        "2024-11-05",
@@ -203,6 +753,7 @@ func DefaultConfig() *Config {
 			MaxConversations:       10,
 			MaxMessagesPerConv:     1000,
 			ToolTimeout:            30 * time.Second,
+			MaxFileSize:            10 * 1024 * 1024,
 		},
 		Logging: LoggingConfig{
 			Level:      "info",
@@ -227,6 +778,79 @@ func DefaultConfig() *Config {
 			RateLimitPerMinute: 100,
 			CORSEnabled:        true,
 			CORSAllowedOrigins: []string{"*"},
+			Command:            DefaultCommandPolicy(),
+		},
+		Tasks: TasksConfig{
+			Enabled:      true,
+			Backend:      "memory",
+			Workers:      4,
+			QueueSize:    100,
+			MaxTasks:     1000,
+			Timeout:      30 * time.Minute,
+			DefaultTTL:   1 * time.Hour,
+			MaxTTL:       24 * time.Hour,
+			PollInterval: 2 * time.Second,
+			NATSURL:      "nats://localhost:4222",
+		},
+		Upstreams: UpstreamsConfig{
+			ConnectTimeout: 30 * time.Second,
+		},
+		Routing: RoutingConfig{
+			CircuitBreaker: CircuitBreakerConfig{
+				Enabled:     true,
+				Window:      time.Minute,
+				MinRequests: 5,
+				ErrorRate:   0.5,
+				Cooldown:    30 * time.Second,
+			},
+		},
+		Agent: AgentConfig{
+			Enabled:          true,
+			MaxIterations:    10,
+			TokenBudget:      200000,
+			MaxParallelTools: 4,
+			Timeout:          5 * time.Minute,
+			AllowedTools:     slices.Clone(DefaultAgentTools),
+		},
+		Compaction: CompactionConfig{
+			Enabled:          true,
+			Threshold:        0.8,
+			KeepMessages:     6,
+			SummaryMaxTokens: 1024,
+		},
+		ToolCache: ToolCacheConfig{
+			Backend:      "memory",
+			MaxEntries:   1000,
+			MaxEntrySize: 256 * 1024,
+			RedisPrefix:  "tfo-mcp:",
+		},
+		Audit: AuditConfig{
+			Enabled:        true,
+			BufferSize:     1000,
+			Retention:      30 * 24 * time.Hour,
+			MaxRecords:     10000,
+			ClickHouse:     true,
+			BatchSize:      100,
+			FlushInterval:  5 * time.Second,
+			MaxValueLength: 1024,
+		},
+		Usage: UsageConfig{
+			Enabled:       true,
+			BufferSize:    1000,
+			MaxRecords:    10000,
+			ClickHouse:    true,
+			BatchSize:     100,
+			FlushInterval: 5 * time.Second,
+		},
+		Budgets: BudgetsConfig{
+			Enabled:         false,
+			Backend:         "memory",
+			RedisPrefix:     "tfo-mcp:",
+			RefreshInterval: time.Minute,
+			Notifications: BudgetNotificationsConfig{
+				MaxRetries: 3,
+				SMTP:       SMTPConfig{Port: 587},
+			},
 		},
 		Database: DatabaseConfig{
 			Enabled:      false,
@@ -293,6 +917,20 @@ func Load(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
 
+	// Unmarshal writes a shorter list over the default element by element, keeping the rest
+	if v.IsSet("agent.allowed_tools") {
+		config.Agent.AllowedTools = v.GetStringSlice("agent.allowed_tools")
+	}
+
+	if file := v.ConfigFileUsed(); file != "" {
+		sections, err := readToolSections(file)
+		if err != nil {
+			return nil, err
+		}
+		config.HTTPTools = sections.HTTPTools
+		config.Pipelines = sections.Pipelines
+	}
+
 	// Override with environment variables for sensitive data
 	if apiKey := os.Getenv("ANTHROPIC_API_KEY"); apiKey != "" {
 		config.Claude.APIKey = apiKey
@@ -301,6 +939,10 @@ func Load(configPath string) (*Config, error) {
 		config.Claude.APIKey = apiKey
 	}
 
+	if err := config.readAPIKeyFiles(); err != nil {
+		return nil, err
+	}
+
 	if dbURL := os.Getenv("TELEMETRYFLOW_MCP_POSTGRES_URL"); dbURL != "" && config.Database.URL == "" {
 		config.Database.URL = dbURL
 		config.Database.Enabled = true
@@ -318,12 +960,123 @@ func Load(configPath string) (*Config, error) {
 	return config, nil
 }
 
+// toolSections holds the config file sections that are decoded without viper
+type toolSections struct {
+	HTTPTools []HTTPToolConfig `yaml:"http_tools"`
+	Pipelines []PipelineConfig `yaml:"pipelines"`
+}
+
+// readToolSections decodes the http_tools and pipelines sections of a YAML config file.
+// Viper lowercases map keys, which would corrupt input schemas and arguments, so they are decoded directly.
+func readToolSections(path string) (*toolSections, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	var sections toolSections
+	if err := yaml.Unmarshal(data, &sections); err != nil {
+		return nil, fmt.Errorf("error reading tool definitions: %w", err)
+	}
+	return &sections, nil
+}
+
+// LoadHTTPTools reads the http_tools section of a YAML config file
+func LoadHTTPTools(path string) ([]HTTPToolConfig, error) {
+	sections, err := readToolSections(path)
+	if err != nil {
+		return nil, err
+	}
+	return sections.HTTPTools, nil
+}
+
+// LoadPipelines reads the pipelines section of a YAML config file
+func LoadPipelines(path string) ([]PipelineConfig, error) {
+	sections, err := readToolSections(path)
+	if err != nil {
+		return nil, err
+	}
+	return sections.Pipelines, nil
+}
+
+// providerAPIKeyEnv maps provider names to the API key variables each vendor documents
+var providerAPIKeyEnv = map[string][]string{
+	"google":   {"GEMINI_API_KEY", "GOOGLE_API_KEY"},
+	"openai":   {"OPENAI_API_KEY"},
+	"deepseek": {"DEEPSEEK_API_KEY"},
+	"qwen":     {"QWEN_API_KEY"},
+	"mistral":  {"MISTRAL_API_KEY"},
+	"xai":      {"XAI_API_KEY"},
+	"moonshot": {"MOONSHOT_API_KEY"},
+	"zhipu":    {"ZHIPU_API_KEY"},
+	"mimo":     {"MIMO_API_KEY"},
+	"ollama":   nil,
+	"local":    nil,
+}
+
+// providerEnvFields are the provider settings bound to TELEMETRYFLOW_MCP_<PROVIDER>_<FIELD>
+var providerEnvFields = []string{
+	"enabled", "api_key_file", "base_url", "organization", "project",
+	"default_model", "timeout", "max_retries", "retry_delay", "tool_calling",
+}
+
+// readAPIKeyFiles fills empty API keys from their api_key_file, so keys can be mounted as secrets
+func (c *Config) readAPIKeyFiles() error {
+	if c.Claude.APIKey == "" && c.Claude.APIKeyFile != "" {
+		key, err := readAPIKeyFile(c.Claude.APIKeyFile)
+		if err != nil {
+			return fmt.Errorf("claude.api_key_file: %w", err)
+		}
+		c.Claude.APIKey = key
+	}
+	for name, provider := range c.Providers {
+		if provider.APIKey != "" || provider.APIKeyFile == "" {
+			continue
+		}
+		key, err := readAPIKeyFile(provider.APIKeyFile)
+		if err != nil {
+			return fmt.Errorf("providers.%s.api_key_file: %w", name, err)
+		}
+		provider.APIKey = key
+		c.Providers[name] = provider
+	}
+	return nil
+}
+
+func readAPIKeyFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return key, nil
+}
+
 // bindEnvVars binds environment variables to config keys
 func bindEnvVars(v *viper.Viper) {
 	// Claude API (errors ignored as BindEnv only fails on empty key names)
 	_ = v.BindEnv("claude.api_key", "ANTHROPIC_API_KEY", "TELEMETRYFLOW_MCP_CLAUDE_API_KEY")
 	_ = v.BindEnv("claude.base_url", "TELEMETRYFLOW_MCP_CLAUDE_BASE_URL")
 	_ = v.BindEnv("claude.default_model", "TELEMETRYFLOW_MCP_CLAUDE_DEFAULT_MODEL")
+	_ = v.BindEnv("claude.enabled", "TELEMETRYFLOW_MCP_CLAUDE_ENABLED")
+	_ = v.BindEnv("claude.api_key_file", "TELEMETRYFLOW_MCP_CLAUDE_API_KEY_FILE")
+	_ = v.BindEnv("claude.timeout", "TELEMETRYFLOW_MCP_CLAUDE_TIMEOUT")
+	_ = v.BindEnv("claude.max_retries", "TELEMETRYFLOW_MCP_CLAUDE_MAX_RETRIES")
+	_ = v.BindEnv("claude.retry_delay", "TELEMETRYFLOW_MCP_CLAUDE_RETRY_DELAY")
+	_ = v.BindEnv("claude.prompt_caching.enabled", "TELEMETRYFLOW_MCP_CLAUDE_PROMPT_CACHING_ENABLED")
+
+	// Additional LLM providers
+	for provider, keyEnvs := range providerAPIKeyEnv {
+		prefix := "TELEMETRYFLOW_MCP_" + strings.ToUpper(provider) + "_"
+		keyEnvs = append(keyEnvs, prefix+"API_KEY")
+		_ = v.BindEnv(append([]string{"providers." + provider + ".api_key"}, keyEnvs...)...)
+		for _, field := range providerEnvFields {
+			_ = v.BindEnv("providers."+provider+"."+field, prefix+strings.ToUpper(field))
+		}
+	}
 
 	// Server
 	_ = v.BindEnv("server.host", "TELEMETRYFLOW_MCP_SERVER_HOST")
@@ -340,6 +1093,44 @@ func bindEnvVars(v *viper.Viper) {
 	_ = v.BindEnv("telemetry.otlp_endpoint", "TELEMETRYFLOW_ENDPOINT", "TELEMETRYFLOW_MCP_OTLP_ENDPOINT")
 	_ = v.BindEnv("telemetry.service_name", "TELEMETRYFLOW_SERVICE_NAME", "TELEMETRYFLOW_MCP_SERVICE_NAME")
 
+	// Background tasks
+	_ = v.BindEnv("tasks.enabled", "TELEMETRYFLOW_MCP_TASKS_ENABLED")
+	_ = v.BindEnv("tasks.backend", "TELEMETRYFLOW_MCP_TASKS_BACKEND")
+	_ = v.BindEnv("tasks.workers", "TELEMETRYFLOW_MCP_TASKS_WORKERS")
+	_ = v.BindEnv("tasks.nats_url", "TELEMETRYFLOW_MCP_NATS_URL")
+	_ = v.BindEnv("tasks.nats_token", "TELEMETRYFLOW_MCP_NATS_TOKEN")
+
+	// Agent loop
+	_ = v.BindEnv("agent.enabled", "TELEMETRYFLOW_MCP_AGENT_ENABLED")
+	_ = v.BindEnv("agent.model", "TELEMETRYFLOW_MCP_AGENT_MODEL")
+	_ = v.BindEnv("agent.token_budget", "TELEMETRYFLOW_MCP_AGENT_TOKEN_BUDGET")
+
+	// Conversation compaction
+	_ = v.BindEnv("compaction.enabled", "TELEMETRYFLOW_MCP_COMPACTION_ENABLED")
+	_ = v.BindEnv("compaction.summary_model", "TELEMETRYFLOW_MCP_COMPACTION_SUMMARY_MODEL")
+
+	// Audit trail
+	_ = v.BindEnv("audit.enabled", "TELEMETRYFLOW_MCP_AUDIT_ENABLED")
+	_ = v.BindEnv("audit.retention", "TELEMETRYFLOW_MCP_AUDIT_RETENTION")
+
+	// LLM usage accounting
+	_ = v.BindEnv("usage.enabled", "TELEMETRYFLOW_MCP_USAGE_ENABLED")
+	_ = v.BindEnv("usage.organization_id", "TELEMETRYFLOW_MCP_USAGE_ORGANIZATION_ID")
+	_ = v.BindEnv("usage.pricing.version", "TELEMETRYFLOW_MCP_USAGE_PRICING_VERSION")
+
+	// LLM budgets
+	_ = v.BindEnv("budgets.enabled", "TELEMETRYFLOW_MCP_BUDGETS_ENABLED")
+	_ = v.BindEnv("budgets.backend", "TELEMETRYFLOW_MCP_BUDGETS_BACKEND")
+	_ = v.BindEnv("budgets.redis_url", "TELEMETRYFLOW_MCP_REDIS_URL")
+	_ = v.BindEnv("budgets.notifications.webhook_url", "TELEMETRYFLOW_MCP_BUDGETS_WEBHOOK_URL")
+	_ = v.BindEnv("budgets.notifications.smtp.host", "TELEMETRYFLOW_MCP_SMTP_HOST")
+	_ = v.BindEnv("budgets.notifications.smtp.username", "TELEMETRYFLOW_MCP_SMTP_USERNAME")
+	_ = v.BindEnv("budgets.notifications.smtp.password", "TELEMETRYFLOW_MCP_SMTP_PASSWORD")
+
+	// Tool result cache
+	_ = v.BindEnv("tool_cache.backend", "TELEMETRYFLOW_MCP_TOOL_CACHE_BACKEND")
+	_ = v.BindEnv("tool_cache.redis_url", "TELEMETRYFLOW_MCP_REDIS_URL")
+
 	// Database (PostgreSQL)
 	_ = v.BindEnv("database.enabled", "TELEMETRYFLOW_MCP_DATABASE_ENABLED")
 	_ = v.BindEnv("database.url", "TELEMETRYFLOW_MCP_POSTGRES_URL")
@@ -363,10 +1154,28 @@ func bindEnvVars(v *viper.Viper) {
 	_ = v.BindEnv("clickhouse.auto_migrate", "TELEMETRYFLOW_MCP_CLICKHOUSE_AUTO_MIGRATE")
 }
 
+// ActiveProviders returns the enabled LLM providers, Anthropic included, sorted by name
+func (c *Config) ActiveProviders() []string {
+	var active []string
+	if c.Claude.IsEnabled() {
+		active = append(active, "anthropic")
+	}
+	for name := range c.Providers {
+		if c.Providers.IsEnabled(name) {
+			active = append(active, name)
+		}
+	}
+	sort.Strings(active)
+	return active
+}
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
-	if c.Claude.APIKey == "" {
-		return errors.New("claude.api_key is required (set ANTHROPIC_API_KEY environment variable)")
+	if c.Claude.Enabled != nil && *c.Claude.Enabled && c.Claude.APIKey == "" {
+		return errors.New("claude.api_key is required when claude.enabled is true (set ANTHROPIC_API_KEY environment variable)")
+	}
+	if len(c.ActiveProviders()) == 0 {
+		return errors.New("claude.api_key is required (set ANTHROPIC_API_KEY environment variable) unless another LLM provider is configured")
 	}
 
 	if c.Server.Port < 1 || c.Server.Port > 65535 {
@@ -378,6 +1187,10 @@ func (c *Config) Validate() error {
 		return errors.New("server.transport must be 'stdio', 'sse', or 'websocket'")
 	}
 
+	if c.Security.RequireAPIKey && len(c.Security.AllowedAPIKeys) == 0 {
+		return errors.New("security.allowed_api_keys must not be empty when security.require_api_key is true")
+	}
+
 	if c.Claude.MaxTokens < 1 {
 		return errors.New("claude.max_tokens must be positive")
 	}
@@ -386,10 +1199,493 @@ func (c *Config) Validate() error {
 		return errors.New("claude.temperature must be between 0 and 2")
 	}
 
+	if c.Claude.PromptCaching.MinTokens < 0 {
+		return errors.New("claude.prompt_caching.min_tokens must not be negative")
+	}
+
+	if err := c.Providers.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Routing.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Agent.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Compaction.Validate(); err != nil {
+		return err
+	}
+
 	if c.Telemetry.TraceSampleRate < 0 || c.Telemetry.TraceSampleRate > 1 {
 		return errors.New("telemetry.trace_sample_rate must be between 0 and 1")
 	}
 
+	if err := c.Security.Command.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Tasks.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Upstreams.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.ToolCache.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Audit.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Usage.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Budgets.Validate(); err != nil {
+		return err
+	}
+
+	if err := ValidateHTTPTools(c.HTTPTools); err != nil {
+		return err
+	}
+
+	if err := ValidatePipelines(c.Pipelines); err != nil {
+		return err
+	}
+	for _, pipeline := range c.Pipelines {
+		for _, tool := range c.HTTPTools {
+			if tool.Name == pipeline.Name {
+				return fmt.Errorf("pipelines: name %q is already used by an http tool", pipeline.Name)
+			}
+		}
+	}
+	for _, policy := range c.ToolCache.Tools {
+		for _, tool := range c.HTTPTools {
+			method := strings.ToUpper(tool.Method)
+			if tool.Name == policy.Tool && method != "" && method != "GET" && method != "HEAD" {
+				return fmt.Errorf("tool_cache.tools: http tool %s uses %s and cannot be cached", policy.Tool, method)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the command policy
+func (p CommandPolicyConfig) Validate() error {
+	for _, patterns := range [][]string{p.AllowedCommands, p.DeniedCommands, p.EnvAllowlist} {
+		for _, pattern := range patterns {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("security.command: invalid pattern %q: %w", pattern, err)
+			}
+		}
+	}
+
+	if p.MaxOutputBytes < 0 {
+		return errors.New("security.command.max_output_bytes must not be negative")
+	}
+
+	if p.DefaultTimeout < 0 || p.MaxTimeout < 0 || p.ProgressInterval < 0 {
+		return errors.New("security.command timeouts must not be negative")
+	}
+
+	if p.MaxTimeout > 0 && p.DefaultTimeout > p.MaxTimeout {
+		return errors.New("security.command.default_timeout must not exceed max_timeout")
+	}
+
+	return nil
+}
+
+// Validate validates the background task configuration
+func (t TasksConfig) Validate() error {
+	if !t.Enabled {
+		return nil
+	}
+
+	switch t.Backend {
+	case "memory":
+	case "nats":
+		if t.NATSURL == "" {
+			return errors.New("tasks.nats_url is required when tasks.backend is 'nats'")
+		}
+	default:
+		return errors.New("tasks.backend must be 'memory' or 'nats'")
+	}
+
+	if t.Workers < 1 || t.QueueSize < 1 {
+		return errors.New("tasks.workers and tasks.queue_size must be positive")
+	}
+
+	if t.MaxTasks < 0 {
+		return errors.New("tasks.max_tasks must not be negative")
+	}
+
+	if t.Timeout < 0 || t.DefaultTTL < 0 || t.MaxTTL < 0 || t.PollInterval < 0 {
+		return errors.New("tasks durations must not be negative")
+	}
+
+	if t.MaxTTL > 0 && t.DefaultTTL > t.MaxTTL {
+		return errors.New("tasks.default_ttl must not exceed max_ttl")
+	}
+
+	return nil
+}
+
+// upstreamNamePattern restricts upstream names so namespaced tool names stay valid
+var upstreamNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]{0,31}$`)
+
+// Validate validates the upstream server configuration
+func (u UpstreamsConfig) Validate() error {
+	if u.ConnectTimeout < 0 {
+		return errors.New("upstreams.connect_timeout must not be negative")
+	}
+
+	seen := make(map[string]bool, len(u.Servers))
+	for _, server := range u.Servers {
+		if !upstreamNamePattern.MatchString(server.Name) || strings.Contains(server.Name, "__") {
+			return fmt.Errorf("upstreams: invalid name %q (letters, digits, '-' and '_', max 32, no '__')", server.Name)
+		}
+		if seen[server.Name] {
+			return fmt.Errorf("upstreams: duplicate name %q", server.Name)
+		}
+		seen[server.Name] = true
+
+		switch server.Transport {
+		case "stdio":
+			if server.Command == "" {
+				return fmt.Errorf("upstreams.%s: command is required for stdio transport", server.Name)
+			}
+		case "http":
+			if server.URL == "" {
+				return fmt.Errorf("upstreams.%s: url is required for http transport", server.Name)
+			}
+		default:
+			return fmt.Errorf("upstreams.%s: transport must be 'stdio' or 'http'", server.Name)
+		}
+
+		for _, pattern := range server.EnvPassthrough {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("upstreams.%s: invalid env_passthrough pattern %q: %w", server.Name, pattern, err)
+			}
+		}
+		for _, kv := range server.Env {
+			if !strings.Contains(kv, "=") {
+				return fmt.Errorf("upstreams.%s: env entry %q must be NAME=value", server.Name, kv)
+			}
+		}
+
+		if server.Timeout < 0 {
+			return fmt.Errorf("upstreams.%s: timeout must not be negative", server.Name)
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the tool result cache configuration
+func (t ToolCacheConfig) Validate() error {
+	if len(t.Tools) == 0 {
+		return nil
+	}
+
+	switch t.Backend {
+	case "memory":
+		if t.MaxEntries < 1 {
+			return errors.New("tool_cache.max_entries must be positive")
+		}
+	case "redis":
+		if t.RedisURL == "" {
+			return errors.New("tool_cache.redis_url is required when tool_cache.backend is 'redis'")
+		}
+	default:
+		return errors.New("tool_cache.backend must be 'memory' or 'redis'")
+	}
+
+	if t.MaxEntrySize < 0 {
+		return errors.New("tool_cache.max_entry_size must not be negative")
+	}
+
+	seen := make(map[string]bool, len(t.Tools))
+	for _, policy := range t.Tools {
+		if policy.Tool == "" {
+			return errors.New("tool_cache.tools: tool is required")
+		}
+		if seen[policy.Tool] {
+			return fmt.Errorf("tool_cache.tools: duplicate tool %q", policy.Tool)
+		}
+		if uncacheableTools[policy.Tool] {
+			return fmt.Errorf("tool_cache.tools: %s has side effects or reports changing state and cannot be cached", policy.Tool)
+		}
+		seen[policy.Tool] = true
+
+		if policy.TTL <= 0 {
+			return fmt.Errorf("tool_cache.tools.%s: ttl must be positive", policy.Tool)
+		}
+		if policy.MaxEntrySize < 0 || policy.TimeBucket < 0 {
+			return fmt.Errorf("tool_cache.tools.%s: max_entry_size and time_bucket must not be negative", policy.Tool)
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the audit trail configuration
+func (a AuditConfig) Validate() error {
+	if !a.Enabled {
+		return nil
+	}
+	if a.BufferSize < 1 {
+		return errors.New("audit.buffer_size must be positive")
+	}
+	if a.MaxRecords < 1 {
+		return errors.New("audit.max_records must be positive")
+	}
+	if a.ClickHouse && a.BatchSize < 1 {
+		return errors.New("audit.batch_size must be positive")
+	}
+	if a.Retention < 0 || a.FlushInterval < 0 || a.MaxValueLength < 0 {
+		return errors.New("audit.retention, audit.flush_interval and audit.max_value_length must not be negative")
+	}
+	return nil
+}
+
+// Validate validates the LLM usage configuration
+func (u UsageConfig) Validate() error {
+	if !u.Enabled {
+		return nil
+	}
+	if u.BufferSize < 1 {
+		return errors.New("usage.buffer_size must be positive")
+	}
+	if u.MaxRecords < 1 {
+		return errors.New("usage.max_records must be positive")
+	}
+	if u.ClickHouse && u.BatchSize < 1 {
+		return errors.New("usage.batch_size must be positive")
+	}
+	if u.FlushInterval < 0 {
+		return errors.New("usage.flush_interval must not be negative")
+	}
+	return u.Pricing.Validate()
+}
+
+// Validate checks the price overrides
+func (p PricingConfig) Validate() error {
+	if len(p.Models) > 0 && strings.TrimSpace(p.Version) == "" {
+		return errors.New("usage.pricing.version is required when usage.pricing.models is set")
+	}
+	seen := make(map[string]bool, len(p.Models))
+	for i, price := range p.Models {
+		if price.Model == "" {
+			return fmt.Errorf("usage.pricing.models[%d]: model is required", i)
+		}
+		if seen[price.Model] {
+			return fmt.Errorf("usage.pricing.models[%d]: duplicate price for %s", i, price.Model)
+		}
+		seen[price.Model] = true
+		if price.Input < 0 || price.Output < 0 || price.CacheRead < 0 || price.CacheWrite < 0 {
+			return fmt.Errorf("usage.pricing.models[%d]: prices of %s must not be negative", i, price.Model)
+		}
+	}
+	return nil
+}
+
+// Validate validates the LLM budget configuration
+func (b BudgetsConfig) Validate() error {
+	if !b.Enabled {
+		return nil
+	}
+
+	switch b.Backend {
+	case "memory":
+	case "redis":
+		if b.RedisURL == "" {
+			return errors.New("budgets.redis_url is required when budgets.backend is 'redis'")
+		}
+	default:
+		return errors.New("budgets.backend must be 'memory' or 'redis'")
+	}
+	if b.RefreshInterval < 0 {
+		return errors.New("budgets.refresh_interval must not be negative")
+	}
+
+	seen := make(map[string]bool, len(b.Limits))
+	for i, limit := range b.Limits {
+		if limit.OrganizationID == "" {
+			return fmt.Errorf("budgets.limits[%d]: organization_id is required", i)
+		}
+		if limit.Period != "daily" && limit.Period != "monthly" {
+			return fmt.Errorf("budgets.limits[%d]: period must be 'daily' or 'monthly'", i)
+		}
+		key := limit.OrganizationID + "/" + limit.Period
+		if seen[key] {
+			return fmt.Errorf("budgets.limits[%d]: duplicate %s budget for %s", i, limit.Period, limit.OrganizationID)
+		}
+		seen[key] = true
+		if limit.Tokens < 0 || limit.CostUSD < 0 {
+			return fmt.Errorf("budgets.limits[%d]: tokens and cost_usd must not be negative", i)
+		}
+		if limit.Tokens == 0 && limit.CostUSD == 0 {
+			return fmt.Errorf("budgets.limits[%d]: tokens or cost_usd is required", i)
+		}
+		if limit.WarnAt != nil && (*limit.WarnAt < 0 || *limit.WarnAt >= 1) {
+			return fmt.Errorf("budgets.limits[%d]: warn_at must be at least 0 and below 1", i)
+		}
+	}
+	return b.Notifications.Validate()
+}
+
+// Validate checks the budget notification settings
+func (n BudgetNotificationsConfig) Validate() error {
+	if n.MaxRetries < 0 {
+		return errors.New("budgets.notifications.max_retries must not be negative")
+	}
+	if n.SMTP.Host != "" {
+		if n.SMTP.Port < 1 || n.SMTP.Port > 65535 {
+			return errors.New("budgets.notifications.smtp.port must be between 1 and 65535")
+		}
+		if n.SMTP.From == "" {
+			return errors.New("budgets.notifications.smtp.from is required when smtp.host is set")
+		}
+	}
+	return nil
+}
+
+// httpToolNamePattern matches valid MCP tool names
+var httpToolNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]{0,63}$`)
+
+// httpToolMethods lists the supported HTTP tool methods
+var httpToolMethods = map[string]bool{"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "HEAD": true}
+
+// ValidateHTTPTools validates declarative HTTP tool definitions
+func ValidateHTTPTools(tools []HTTPToolConfig) error {
+	seen := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		if !httpToolNamePattern.MatchString(tool.Name) {
+			return fmt.Errorf("http_tools: invalid name %q", tool.Name)
+		}
+		if seen[tool.Name] {
+			return fmt.Errorf("http_tools: duplicate name %q", tool.Name)
+		}
+		seen[tool.Name] = true
+
+		if tool.Method != "" && !httpToolMethods[strings.ToUpper(tool.Method)] {
+			return fmt.Errorf("http_tools.%s: unsupported method %q", tool.Name, tool.Method)
+		}
+		if !strings.HasPrefix(tool.URL, "http://") && !strings.HasPrefix(tool.URL, "https://") {
+			return fmt.Errorf("http_tools.%s: url must start with http:// or https://", tool.Name)
+		}
+		if tool.ResponsePath != "" && !strings.HasPrefix(tool.ResponsePath, "$") {
+			return fmt.Errorf("http_tools.%s: response_path must start with '$'", tool.Name)
+		}
+		if tool.Timeout < 0 {
+			return fmt.Errorf("http_tools.%s: timeout must not be negative", tool.Name)
+		}
+	}
+	return nil
+}
+
+// pipelineStepIDPattern matches step IDs usable as template fields
+var pipelineStepIDPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,63}$`)
+
+// MaxPipelineStepRetries caps per-step retries
+const MaxPipelineStepRetries = 5
+
+// ValidatePipelines validates composite tool definitions, including that steps form a DAG
+func ValidatePipelines(pipelines []PipelineConfig) error {
+	seen := make(map[string]bool, len(pipelines))
+	for _, pipeline := range pipelines {
+		if !httpToolNamePattern.MatchString(pipeline.Name) {
+			return fmt.Errorf("pipelines: invalid name %q", pipeline.Name)
+		}
+		if seen[pipeline.Name] {
+			return fmt.Errorf("pipelines: duplicate name %q", pipeline.Name)
+		}
+		seen[pipeline.Name] = true
+
+		if err := validatePipelineSteps(pipeline); err != nil {
+			return fmt.Errorf("pipelines.%s: %w", pipeline.Name, err)
+		}
+		if pipeline.Timeout < 0 {
+			return fmt.Errorf("pipelines.%s: timeout must not be negative", pipeline.Name)
+		}
+	}
+	return nil
+}
+
+// validatePipelineSteps checks step fields and rejects unknown or cyclic dependencies
+func validatePipelineSteps(pipeline PipelineConfig) error {
+	if len(pipeline.Steps) == 0 {
+		return fmt.Errorf("at least one step is required")
+	}
+
+	steps := make(map[string]PipelineStepConfig, len(pipeline.Steps))
+	for _, step := range pipeline.Steps {
+		if !pipelineStepIDPattern.MatchString(step.ID) {
+			return fmt.Errorf("invalid step id %q (letters, digits and '_' only)", step.ID)
+		}
+		if _, dup := steps[step.ID]; dup {
+			return fmt.Errorf("duplicate step id %q", step.ID)
+		}
+		steps[step.ID] = step
+
+		if step.Tool == "" {
+			return fmt.Errorf("steps.%s: tool is required", step.ID)
+		}
+		if step.Tool == pipeline.Name {
+			return fmt.Errorf("steps.%s: a pipeline cannot call itself", step.ID)
+		}
+		switch step.OnError {
+		case "", "fail", "continue":
+		default:
+			return fmt.Errorf("steps.%s: on_error must be 'fail' or 'continue'", step.ID)
+		}
+		if step.Retries < 0 || step.Retries > MaxPipelineStepRetries {
+			return fmt.Errorf("steps.%s: retries must be between 0 and %d", step.ID, MaxPipelineStepRetries)
+		}
+		if step.Timeout < 0 {
+			return fmt.Errorf("steps.%s: timeout must not be negative", step.ID)
+		}
+	}
+
+	for _, step := range pipeline.Steps {
+		for _, dep := range step.DependsOn {
+			if _, ok := steps[dep]; !ok {
+				return fmt.Errorf("steps.%s: unknown dependency %q", step.ID, dep)
+			}
+		}
+	}
+
+	// Depth-first search for cycles: 1 = visiting, 2 = done
+	state := make(map[string]int, len(steps))
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case 1:
+			return fmt.Errorf("dependency cycle through step %q", id)
+		case 2:
+			return nil
+		}
+		state[id] = 1
+		for _, dep := range steps[id].DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[id] = 2
+		return nil
+	}
+	for _, step := range pipeline.Steps {
+		if err := visit(step.ID); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
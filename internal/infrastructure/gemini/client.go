@@ -0,0 +1,775 @@
+// Package gemini provides an LLM backend for the Google Gemini API.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gemini
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/llm"
+)
+
+// Client errors
+var (
+	ErrAPIKeyRequired = errors.New("API key is required")
+	ErrInvalidRequest = errors.New("invalid request")
+	ErrAPIError       = errors.New("API error")
+	ErrRateLimited    = errors.New("rate limited")
+	ErrPromptBlocked  = errors.New("prompt blocked")
+)
+
+const (
+	// DefaultBaseURL is the public Gemini API endpoint
+	DefaultBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
+	// DefaultMaxTokens applies when a request sets no max_tokens
+	DefaultMaxTokens = 4096
+
+	// maxErrorBodySize bounds how much of an error response is read
+	maxErrorBodySize = 64 * 1024
+
+	// maxStreamLineSize bounds a single server-sent event line
+	maxStreamLineSize = 1024 * 1024
+
+	// skipThoughtSignature is the documented placeholder for function calls that carry
+	// no signature, such as calls replayed from conversation history
+	skipThoughtSignature = "skip_thought_signature_validator"
+
+	// maxThinkingBudget is the largest thinking budget every thinking Gemini model accepts
+	maxThinkingBudget = 24576
+)
+
+// Client implements IClaudeService on the Gemini generateContent API
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	retry      llm.RetryPolicy
+	logger     zerolog.Logger
+}
+
+// NewClient creates a Gemini client; an empty base URL uses DefaultBaseURL
+func NewClient(cfg config.ProviderConfig, logger zerolog.Logger) (*Client, error) {
+	if cfg.APIKey == "" {
+		return nil, ErrAPIKeyRequired
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     cfg.APIKey,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		retry:      llm.NewRetryPolicy(cfg.MaxRetries, cfg.RetryDelay),
+		logger:     logger.With().Str("component", "gemini-client").Logger(),
+	}, nil
+}
+
+// CreateMessage creates a message (non-streaming)
+func (c *Client) CreateMessage(ctx context.Context, request *services.ClaudeRequest) (*services.ClaudeResponse, error) {
+	if err := c.ValidateRequest(request); err != nil {
+		return nil, err
+	}
+
+	c.logger.Debug().
+		Str("model", request.Model.String()).
+		Int("max_tokens", request.MaxTokens).
+		Int("message_count", len(request.Messages)).
+		Msg("Creating message")
+
+	body, err := buildGenerateRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.post(ctx, request.Model, "generateContent", body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var generated generateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&generated); err != nil {
+		return nil, fmt.Errorf("%w: decoding response: %v", ErrAPIError, err)
+	}
+
+	return convertResponse(request.Model, &generated)
+}
+
+// CreateMessageStream creates a message with streaming
+func (c *Client) CreateMessageStream(ctx context.Context, request *services.ClaudeRequest) (<-chan *services.ClaudeStreamEvent, error) {
+	if err := c.ValidateRequest(request); err != nil {
+		return nil, err
+	}
+
+	c.logger.Debug().
+		Str("model", request.Model.String()).
+		Int("max_tokens", request.MaxTokens).
+		Msg("Creating streaming message")
+
+	body, err := buildGenerateRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.post(ctx, request.Model, "streamGenerateContent", body)
+	if err != nil {
+		return nil, err
+	}
+
+	eventChan := make(chan *services.ClaudeStreamEvent, 100)
+
+	go func() {
+		defer close(eventChan)
+		defer resp.Body.Close()
+
+		send := func(event *services.ClaudeStreamEvent) bool {
+			select {
+			case eventChan <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		if err := newStreamConverter(request.Model).run(resp.Body, send); err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			select {
+			case eventChan <- &services.ClaudeStreamEvent{Error: err}:
+			default:
+			}
+		}
+	}()
+
+	return eventChan, nil
+}
+
+// CountTokens counts tokens for a message
+func (c *Client) CountTokens(ctx context.Context, request *services.ClaudeRequest) (int, error) {
+	if err := c.ValidateRequest(request); err != nil {
+		return 0, err
+	}
+
+	body, err := buildGenerateRequest(request)
+	if err != nil {
+		return 0, err
+	}
+	// countTokens rejects generation settings
+	body.GenerationConfig = nil
+
+	resp, err := c.post(ctx, request.Model, "countTokens", &countTokensRequest{
+		GenerateContentRequest: &countedRequest{Model: "models/" + request.Model.String(), generateRequest: *body},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var counted countTokensResponse
+	if err := json.NewDecoder(resp.Body).Decode(&counted); err != nil {
+		return 0, fmt.Errorf("%w: decoding response: %v", ErrAPIError, err)
+	}
+	return counted.TotalTokens, nil
+}
+
+// ValidateRequest validates a Claude request
+func (c *Client) ValidateRequest(request *services.ClaudeRequest) error {
+	if request == nil {
+		return ErrInvalidRequest
+	}
+
+	if !request.Model.IsValid() {
+		return fmt.Errorf("%w: invalid model", ErrInvalidRequest)
+	}
+
+	if request.Model.Provider() != vo.ProviderGoogle {
+		return fmt.Errorf("%w: model %s is not served by %s", ErrInvalidRequest, request.Model, vo.ProviderGoogle)
+	}
+
+	if len(request.Messages) == 0 {
+		return fmt.Errorf("%w: messages required", ErrInvalidRequest)
+	}
+
+	if request.MaxTokens <= 0 {
+		request.MaxTokens = DefaultMaxTokens
+	}
+
+	return nil
+}
+
+// post calls a model method, retrying failed attempts, and returns the successful response
+func (c *Client) post(ctx context.Context, model vo.Model, method string, body interface{}) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	endpoint := fmt.Sprintf("%s/models/%s:%s", c.baseURL, url.PathEscape(model.String()), method)
+	if method == "streamGenerateContent" {
+		endpoint += "?alt=sse"
+	}
+
+	var resp *http.Response
+	err = c.retry.Do(ctx, c.logger, func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+		}
+		req.Header.Set("x-goog-api-key", c.apiKey)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err = c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrAPIError, err)
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			defer resp.Body.Close()
+			return responseError(resp)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// responseError converts an unsuccessful response to an error carrying its status
+func responseError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+
+	message := strings.TrimSpace(string(data))
+	var body apiError
+	if json.Unmarshal(data, &body) == nil && body.Error.Message != "" {
+		message = body.Error.Message
+		if body.Error.Status != "" {
+			message = body.Error.Status + ": " + message
+		}
+	}
+
+	sentinel := ErrAPIError
+	if resp.StatusCode == http.StatusTooManyRequests {
+		sentinel = ErrRateLimited
+	}
+	return llm.NewStatusError(resp, fmt.Errorf("%w: google returned HTTP %d: %s", sentinel, resp.StatusCode, message))
+}
+
+// buildGenerateRequest converts a Claude request to a generateContent request
+func buildGenerateRequest(request *services.ClaudeRequest) (*generateRequest, error) {
+	contents, err := buildContents(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Thinking counts toward the output tokens
+	maxTokens, thinkingBudget := request.OutputTokens()
+	body := &generateRequest{
+		Contents: contents,
+		GenerationConfig: &generationConfig{
+			MaxOutputTokens: maxTokens,
+			TopK:            request.TopK,
+			StopSequences:   request.StopSequences,
+		},
+	}
+	if thinkingBudget > 0 {
+		body.GenerationConfig.ThinkingConfig = &thinkingConfig{
+			ThinkingBudget:  min(thinkingBudget, maxThinkingBudget),
+			IncludeThoughts: true,
+		}
+	}
+
+	if !request.SystemPrompt.IsEmpty() {
+		body.SystemInstruction = &content{Parts: []part{{Text: request.SystemPrompt.String()}}}
+	}
+
+	// Temperature (only set if not default)
+	if request.Temperature > 0 && request.Temperature != 1.0 {
+		temperature := request.Temperature
+		body.GenerationConfig.Temperature = &temperature
+	}
+
+	// Top P
+	if request.TopP > 0 && request.TopP < 1.0 {
+		topP := request.TopP
+		body.GenerationConfig.TopP = &topP
+	}
+
+	if len(request.Tools) > 0 {
+		declarations := make([]functionDeclaration, 0, len(request.Tools))
+		for _, t := range request.Tools {
+			declarations = append(declarations, functionDeclaration{
+				Name:        t.Name,
+				Description: t.Description,
+				Parameters:  convertSchema(t.InputSchema),
+			})
+		}
+		body.Tools = []tool{{FunctionDeclarations: declarations}}
+	}
+
+	return body, nil
+}
+
+// buildContents converts domain messages to Gemini contents
+func buildContents(request *services.ClaudeRequest) ([]content, error) {
+	// Gemini matches function responses to calls by name, so remember the name of each tool_use ID
+	toolNames := make(map[string]string)
+	for _, msg := range request.Messages {
+		for _, block := range msg.Content {
+			if block.Type == vo.ContentTypeToolUse {
+				toolNames[block.ID] = block.Name
+			}
+		}
+	}
+	needsSignature := strings.HasPrefix(request.Model.String(), "gemini-3")
+
+	contents := make([]content, 0, len(request.Messages))
+	for _, msg := range request.Messages {
+		role := "user"
+		if msg.Role == vo.RoleAssistant {
+			role = "model"
+		}
+
+		parts := make([]part, 0, len(msg.Content))
+		signed := false
+		for _, block := range msg.Content {
+			switch block.Type {
+			case vo.ContentTypeText:
+				if block.Text != "" {
+					parts = append(parts, part{Text: block.Text})
+				}
+
+			case vo.ContentTypeImage, vo.ContentTypeDocument:
+				// Images and PDFs alike are parts of the media type they hold
+				switch source := block.Source; {
+				case source == nil:
+				case source.Type == "url":
+					parts = append(parts, part{FileData: &fileData{MimeType: source.MediaType, FileURI: source.URL}})
+				default:
+					parts = append(parts, part{InlineData: &blob{MimeType: source.MediaType, Data: source.Data}})
+				}
+
+			case vo.ContentTypeToolUse:
+				// The signature of the thoughts behind the call lets the model resume its reasoning.
+				// Gemini 3 requires one on the first function call of each step
+				call := part{FunctionCall: &functionCall{Name: block.Name, Args: block.Input}, ThoughtSignature: block.Signature}
+				if needsSignature && !signed && call.ThoughtSignature == "" {
+					call.ThoughtSignature = skipThoughtSignature
+				}
+				signed = true
+				parts = append(parts, call)
+
+			case vo.ContentTypeToolResult:
+				name, ok := toolNames[block.ToolUseID]
+				if !ok {
+					return nil, fmt.Errorf("%w: tool_result %s has no matching tool_use", ErrInvalidRequest, block.ToolUseID)
+				}
+				response := map[string]interface{}{"content": block.Content}
+				if block.IsError {
+					response = map[string]interface{}{"error": block.Content}
+				}
+				parts = append(parts, part{FunctionResponse: &functionResponse{Name: name, Response: response}})
+			}
+		}
+
+		if len(parts) > 0 {
+			contents = append(contents, content{Role: role, Parts: parts})
+		}
+	}
+
+	return contents, nil
+}
+
+// convertSchema converts a tool input schema to the OpenAPI subset Gemini accepts.
+// Objects without properties are omitted, since Gemini rejects them.
+func convertSchema(schema *entities.JSONSchema) map[string]interface{} {
+	if schema == nil || (schema.Type == "object" && len(schema.Properties) == 0) {
+		return nil
+	}
+	return schemaMap(schema)
+}
+
+func schemaMap(schema *entities.JSONSchema) map[string]interface{} {
+	result := map[string]interface{}{}
+	if schema.Type != "" {
+		result["type"] = schema.Type
+	}
+	if schema.Description != "" {
+		result["description"] = schema.Description
+	}
+	if schema.Format != "" {
+		result["format"] = schema.Format
+	}
+	if schema.Pattern != "" {
+		result["pattern"] = schema.Pattern
+	}
+	if schema.Minimum != nil {
+		result["minimum"] = *schema.Minimum
+	}
+	if schema.Maximum != nil {
+		result["maximum"] = *schema.Maximum
+	}
+	if schema.MinLength != nil {
+		result["minLength"] = *schema.MinLength
+	}
+	if schema.MaxLength != nil {
+		result["maxLength"] = *schema.MaxLength
+	}
+	if schema.Default != nil {
+		result["default"] = schema.Default
+	}
+	if len(schema.Enum) > 0 {
+		// Gemini enums are string-only
+		values := make([]string, 0, len(schema.Enum))
+		for _, v := range schema.Enum {
+			values = append(values, fmt.Sprint(v))
+		}
+		result["enum"] = values
+		if schema.Type == "string" {
+			result["format"] = "enum"
+		}
+	}
+	if len(schema.Properties) > 0 {
+		properties := make(map[string]interface{}, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			if prop != nil {
+				properties[name] = schemaMap(prop)
+			}
+		}
+		result["properties"] = properties
+	}
+	if len(schema.Required) > 0 {
+		result["required"] = schema.Required
+	}
+	if schema.Items != nil {
+		result["items"] = schemaMap(schema.Items)
+	}
+	return result
+}
+
+// convertResponse converts a generateContent result to a domain response
+func convertResponse(model vo.Model, generated *generateResponse) (*services.ClaudeResponse, error) {
+	if len(generated.Candidates) == 0 {
+		if generated.PromptFeedback != nil && generated.PromptFeedback.BlockReason != "" {
+			return nil, blockedError(generated.PromptFeedback)
+		}
+		return nil, fmt.Errorf("%w: response has no candidates", ErrAPIError)
+	}
+
+	candidate := generated.Candidates[0]
+	contentBlocks := make([]entities.ContentBlock, 0)
+	hasToolUse := false
+	if candidate.Content != nil {
+		var text, thoughts strings.Builder
+		flush := func() {
+			if thoughts.Len() > 0 {
+				contentBlocks = append(contentBlocks, entities.ContentBlock{Type: vo.ContentTypeThinking, Thinking: thoughts.String()})
+				thoughts.Reset()
+			}
+			if text.Len() > 0 {
+				contentBlocks = append(contentBlocks, entities.ContentBlock{Type: vo.ContentTypeText, Text: text.String()})
+				text.Reset()
+			}
+		}
+		for _, p := range candidate.Content.Parts {
+			switch {
+			case p.Thought:
+				if text.Len() > 0 {
+					flush()
+				}
+				thoughts.WriteString(p.Text)
+			case p.FunctionCall != nil:
+				flush()
+				block := toolUseBlock(p.FunctionCall)
+				block.Signature = p.ThoughtSignature
+				contentBlocks = append(contentBlocks, block)
+				hasToolUse = true
+			default:
+				text.WriteString(p.Text)
+			}
+		}
+		flush()
+	}
+
+	modelName := generated.ModelVersion
+	if modelName == "" {
+		modelName = model.String()
+	}
+	return &services.ClaudeResponse{
+		ID:         generated.ResponseID,
+		Type:       "message",
+		Role:       vo.RoleAssistant,
+		Content:    contentBlocks,
+		Model:      modelName,
+		StopReason: stopReason(candidate.FinishReason, hasToolUse),
+		Usage:      convertUsage(generated.UsageMetadata),
+	}, nil
+}
+
+// toolUseBlock converts a function call, generating an ID when Gemini sent none
+func toolUseBlock(call *functionCall) entities.ContentBlock {
+	id := call.ID
+	if id == "" {
+		id = "toolu_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	}
+	input := call.Args
+	if input == nil {
+		input = map[string]interface{}{}
+	}
+	return entities.ContentBlock{Type: vo.ContentTypeToolUse, ID: id, Name: call.Name, Input: input}
+}
+
+func convertUsage(usage *usageMetadata) *services.ClaudeUsage {
+	if usage == nil {
+		return &services.ClaudeUsage{}
+	}
+	// The prompt count includes the tokens served from cached content
+	cached := min(usage.CachedContentTokenCount, usage.PromptTokenCount)
+	return &services.ClaudeUsage{
+		InputTokens:          usage.PromptTokenCount - cached,
+		OutputTokens:         usage.CandidatesTokenCount + usage.ThoughtsTokenCount,
+		CacheReadInputTokens: cached,
+		ReasoningTokens:      usage.ThoughtsTokenCount,
+	}
+}
+
+func blockedError(feedback *promptFeedback) error {
+	if feedback.BlockReasonMessage != "" {
+		return fmt.Errorf("%w: %s: %s", ErrPromptBlocked, feedback.BlockReason, feedback.BlockReasonMessage)
+	}
+	return fmt.Errorf("%w: %s", ErrPromptBlocked, feedback.BlockReason)
+}
+
+// stopReason maps a Gemini finish reason to the Anthropic stop reason
+func stopReason(finishReason string, hasToolUse bool) string {
+	switch finishReason {
+	case "STOP", "":
+		if hasToolUse {
+			return "tool_use"
+		}
+		return "end_turn"
+	case "MAX_TOKENS":
+		return "max_tokens"
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+		return "refusal"
+	default:
+		return strings.ToLower(finishReason)
+	}
+}
+
+// streamConverter turns streamed generateContent chunks into Anthropic-style stream events
+type streamConverter struct {
+	model         vo.Model
+	started       bool
+	nextIndex     int
+	thinkingIndex int // block index of the open thinking block, -1 when none
+	textIndex     int // block index of the open text block, -1 when none
+	openBlocks    []int
+	hasToolUse    bool
+	finishReason  string
+	usage         *usageMetadata
+}
+
+func newStreamConverter(model vo.Model) *streamConverter {
+	return &streamConverter{model: model, thinkingIndex: -1, textIndex: -1}
+}
+
+// run reads server-sent events from body until its end or a send failure
+func (s *streamConverter) run(body io.Reader, send func(*services.ClaudeStreamEvent) bool) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "" {
+			continue
+		}
+
+		var chunk generateResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			var failure apiError
+			if json.Unmarshal([]byte(data), &failure) == nil && failure.Error.Message != "" {
+				return fmt.Errorf("%w: %s", ErrAPIError, failure.Error.Message)
+			}
+			return fmt.Errorf("%w: decoding stream chunk: %v", ErrAPIError, err)
+		}
+		events, err := s.convert(&chunk)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if !send(event) {
+				return context.Canceled
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: reading stream: %v", ErrAPIError, err)
+	}
+
+	for _, event := range s.finish() {
+		if !send(event) {
+			return context.Canceled
+		}
+	}
+	return nil
+}
+
+// convert returns the events for one chunk
+func (s *streamConverter) convert(chunk *generateResponse) ([]*services.ClaudeStreamEvent, error) {
+	if len(chunk.Candidates) == 0 && chunk.PromptFeedback != nil && chunk.PromptFeedback.BlockReason != "" {
+		return nil, blockedError(chunk.PromptFeedback)
+	}
+
+	var events []*services.ClaudeStreamEvent
+	if !s.started {
+		s.started = true
+		model := chunk.ModelVersion
+		if model == "" {
+			model = s.model.String()
+		}
+		events = append(events, &services.ClaudeStreamEvent{
+			Type:    "message_start",
+			Message: &services.ClaudeResponse{ID: chunk.ResponseID, Type: "message", Model: model, Role: vo.RoleAssistant},
+		})
+	}
+	if chunk.UsageMetadata != nil {
+		s.usage = chunk.UsageMetadata
+	}
+	if len(chunk.Candidates) == 0 {
+		return events, nil
+	}
+
+	candidate := chunk.Candidates[0]
+	if candidate.Content != nil {
+		for _, p := range candidate.Content.Parts {
+			switch {
+			case p.Thought:
+				if p.Text == "" {
+					continue
+				}
+				if s.thinkingIndex < 0 {
+					s.thinkingIndex = s.startBlock()
+					s.textIndex = -1
+					events = append(events, &services.ClaudeStreamEvent{
+						Type:         "content_block_start",
+						Index:        s.thinkingIndex,
+						ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeThinking},
+					})
+				}
+				events = append(events, &services.ClaudeStreamEvent{
+					Type:  "content_block_delta",
+					Index: s.thinkingIndex,
+					Delta: &services.ClaudeDelta{Type: "thinking_delta", Thinking: p.Text},
+				})
+
+			case p.FunctionCall != nil:
+				// Function calls arrive whole, so each is a complete block
+				block := toolUseBlock(p.FunctionCall)
+				input, _ := json.Marshal(block.Input)
+				index := s.startBlock()
+				s.thinkingIndex, s.textIndex = -1, -1
+				s.hasToolUse = true
+				events = append(events,
+					&services.ClaudeStreamEvent{
+						Type:  "content_block_start",
+						Index: index,
+						ContentBlock: &entities.ContentBlock{
+							Type: vo.ContentTypeToolUse, ID: block.ID, Name: block.Name, Signature: p.ThoughtSignature,
+						},
+					},
+					&services.ClaudeStreamEvent{
+						Type:  "content_block_delta",
+						Index: index,
+						Delta: &services.ClaudeDelta{Type: "input_json_delta", PartialJSON: string(input)},
+					},
+				)
+
+			case p.Text != "":
+				if s.textIndex < 0 {
+					s.textIndex = s.startBlock()
+					s.thinkingIndex = -1
+					events = append(events, &services.ClaudeStreamEvent{
+						Type:         "content_block_start",
+						Index:        s.textIndex,
+						ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeText},
+					})
+				}
+				events = append(events, &services.ClaudeStreamEvent{
+					Type:  "content_block_delta",
+					Index: s.textIndex,
+					Delta: &services.ClaudeDelta{Type: "text_delta", Text: p.Text},
+				})
+			}
+		}
+	}
+	if candidate.FinishReason != "" {
+		s.finishReason = candidate.FinishReason
+	}
+
+	return events, nil
+}
+
+// finish closes the open blocks and ends the message
+func (s *streamConverter) finish() []*services.ClaudeStreamEvent {
+	events := make([]*services.ClaudeStreamEvent, 0, len(s.openBlocks)+2)
+	for _, index := range s.openBlocks {
+		events = append(events, &services.ClaudeStreamEvent{Type: "content_block_stop", Index: index})
+	}
+	return append(events,
+		&services.ClaudeStreamEvent{
+			Type:  "message_delta",
+			Delta: &services.ClaudeDelta{StopReason: stopReason(s.finishReason, s.hasToolUse)},
+			Usage: convertUsage(s.usage),
+		},
+		&services.ClaudeStreamEvent{Type: "message_stop"},
+	)
+}
+
+func (s *streamConverter) startBlock() int {
+	index := s.nextIndex
+	s.nextIndex++
+	s.openBlocks = append(s.openBlocks, index)
+	return index
+}
+
+var _ services.IClaudeService = (*Client)(nil)
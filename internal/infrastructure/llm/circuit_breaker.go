@@ -0,0 +1,153 @@
+// Package llm routes LLM requests to provider backends.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llm
+
+import (
+	"sync"
+	"time"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+)
+
+// BreakerState is the state of a model's circuit breaker
+type BreakerState string
+
+// Circuit breaker states
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// ModelHealth is a snapshot of one model's circuit breaker
+type ModelHealth struct {
+	State       BreakerState  `json:"state"`
+	Requests    int           `json:"requests"`
+	Failures    int           `json:"failures"`
+	ErrorRate   float64       `json:"error_rate"`
+	LastLatency time.Duration `json:"last_latency"`
+}
+
+// circuitBreaker tracks the error rate and latency of one model over a fixed window
+type circuitBreaker struct {
+	cfg config.CircuitBreakerConfig
+
+	mu          sync.Mutex
+	state       BreakerState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	trialActive bool
+	lastLatency time.Duration
+}
+
+func newCircuitBreaker(cfg config.CircuitBreakerConfig) *circuitBreaker {
+	return &circuitBreaker{cfg: cfg, state: BreakerClosed}
+}
+
+// allow reports whether a call may go through. An open breaker lets one trial call through once
+// the cooldown has passed
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if now.Sub(b.openedAt) < b.cfg.Cooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.trialActive = true
+		return true
+	case BreakerHalfOpen:
+		if b.trialActive {
+			return false
+		}
+		b.trialActive = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record counts the outcome of a call; calls slower than the latency threshold count as failures
+func (b *circuitBreaker) record(now time.Time, latency time.Duration, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastLatency = latency
+	if b.cfg.LatencyThreshold > 0 && latency > b.cfg.LatencyThreshold {
+		failed = true
+	}
+
+	if b.state == BreakerHalfOpen {
+		b.trialActive = false
+		if failed {
+			b.open(now)
+		} else {
+			b.state = BreakerClosed
+			b.resetWindow(now)
+		}
+		return
+	}
+	if b.state == BreakerOpen {
+		return
+	}
+
+	if now.Sub(b.windowStart) >= b.cfg.Window {
+		b.resetWindow(now)
+	}
+	b.requests++
+	if failed {
+		b.failures++
+	}
+	if b.requests >= b.cfg.MinRequests && float64(b.failures)/float64(b.requests) >= b.cfg.ErrorRate {
+		b.open(now)
+	}
+}
+
+// release frees a half-open trial whose call ended without an outcome, such as a caller cancellation
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trialActive = false
+}
+
+func (b *circuitBreaker) open(now time.Time) {
+	b.state = BreakerOpen
+	b.openedAt = now
+	b.resetWindow(now)
+}
+
+func (b *circuitBreaker) resetWindow(now time.Time) {
+	b.windowStart = now
+	b.requests = 0
+	b.failures = 0
+}
+
+func (b *circuitBreaker) health() ModelHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	h := ModelHealth{State: b.state, Requests: b.requests, Failures: b.failures, LastLatency: b.lastLatency}
+	if b.requests > 0 {
+		h.ErrorRate = float64(b.failures) / float64(b.requests)
+	}
+	return h
+}
@@ -0,0 +1,206 @@
+// Package llm routes LLM requests to provider backends.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// Registry errors
+var (
+	ErrInvalidRequest        = errors.New("invalid request")
+	ErrUnknownModelProvider  = errors.New("model has no known provider")
+	ErrProviderNotConfigured = errors.New("provider not configured")
+)
+
+// ModelLister is implemented by backends whose models are discovered at runtime
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]vo.Model, error)
+}
+
+// Registry implements IClaudeService by sending each request to the backend of its model's provider
+type Registry struct {
+	mu       sync.RWMutex
+	backends map[vo.Provider]services.IClaudeService
+	defaults map[vo.Provider]vo.Model
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		backends: make(map[vo.Provider]services.IClaudeService),
+		defaults: make(map[vo.Provider]vo.Model),
+	}
+}
+
+// SetDefaultModel sets the model served when a request names only the provider, such as "openai"
+func (r *Registry) SetDefaultModel(provider vo.Provider, model vo.Model) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.defaults[provider] = model
+}
+
+// DefaultModel returns the model served when a request names only the provider
+func (r *Registry) DefaultModel(provider vo.Provider) (vo.Model, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	model, ok := r.defaults[provider]
+	return model, ok
+}
+
+// ResolveModel returns the provider's default model when model is a bare provider name
+func (r *Registry) ResolveModel(model vo.Model) vo.Model {
+	if resolved, ok := r.DefaultModel(vo.Provider(model)); ok {
+		return resolved
+	}
+	return model
+}
+
+// Register sets the backend for a provider, replacing any previous one
+func (r *Registry) Register(provider vo.Provider, backend services.IClaudeService) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.backends[provider] = backend
+}
+
+// Providers returns the providers with a registered backend, sorted by name
+func (r *Registry) Providers() []vo.Provider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	providers := make([]vo.Provider, 0, len(r.backends))
+	for p := range r.backends {
+		providers = append(providers, p)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i] < providers[j] })
+	return providers
+}
+
+// LocalModels returns the models discovered on the local providers, sorted by name.
+// Providers that fail to list their models are skipped and reported in the error
+func (r *Registry) LocalModels(ctx context.Context) ([]vo.Model, error) {
+	r.mu.RLock()
+	listers := make(map[vo.Provider]ModelLister)
+	for provider, backend := range r.backends {
+		if lister, ok := backend.(ModelLister); ok && provider.IsLocal() {
+			listers[provider] = lister
+		}
+	}
+	r.mu.RUnlock()
+
+	var models []vo.Model
+	var errs []error
+	for provider, lister := range listers {
+		discovered, err := lister.ListModels(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", provider, err))
+			continue
+		}
+		models = append(models, discovered...)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i] < models[j] })
+	return models, errors.Join(errs...)
+}
+
+// Backend returns the backend that serves model
+func (r *Registry) Backend(model vo.Model) (services.IClaudeService, error) {
+	provider := model.Provider()
+	if provider == "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownModelProvider, model)
+	}
+
+	r.mu.RLock()
+	backend, ok := r.backends[provider]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s (model %s)", ErrProviderNotConfigured, provider, model)
+	}
+	return backend, nil
+}
+
+// CreateMessage creates a message (non-streaming)
+func (r *Registry) CreateMessage(ctx context.Context, request *services.ClaudeRequest) (*services.ClaudeResponse, error) {
+	backend, request, err := r.backendFor(request)
+	if err == nil {
+		err = request.CheckInputs()
+	}
+	if err != nil {
+		return nil, err
+	}
+	response, err := backend.CreateMessage(ctx, request)
+	if err == nil && response.ServedBy == "" {
+		response.ServedBy = request.Model
+	}
+	return response, err
+}
+
+// CreateMessageStream creates a message with streaming
+func (r *Registry) CreateMessageStream(ctx context.Context, request *services.ClaudeRequest) (<-chan *services.ClaudeStreamEvent, error) {
+	backend, request, err := r.backendFor(request)
+	if err == nil {
+		err = request.CheckInputs()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return backend.CreateMessageStream(ctx, request)
+}
+
+// CountTokens counts tokens for a message
+func (r *Registry) CountTokens(ctx context.Context, request *services.ClaudeRequest) (int, error) {
+	backend, request, err := r.backendFor(request)
+	if err != nil {
+		return 0, err
+	}
+	return backend.CountTokens(ctx, request)
+}
+
+// ValidateRequest validates a request with the backend that would serve it
+func (r *Registry) ValidateRequest(request *services.ClaudeRequest) error {
+	backend, request, err := r.backendFor(request)
+	if err == nil {
+		err = request.CheckInputs()
+	}
+	if err != nil {
+		return err
+	}
+	return backend.ValidateRequest(request)
+}
+
+// backendFor returns the backend of a request, with a bare provider name replaced by its default model
+func (r *Registry) backendFor(request *services.ClaudeRequest) (services.IClaudeService, *services.ClaudeRequest, error) {
+	if request == nil {
+		return nil, nil, ErrInvalidRequest
+	}
+	if model := r.ResolveModel(request.Model); model != request.Model {
+		resolved := *request
+		resolved.Model = model
+		request = &resolved
+	}
+	backend, err := r.Backend(request.Model)
+	return backend, request, err
+}
+
+var _ services.IClaudeService = (*Registry)(nil)
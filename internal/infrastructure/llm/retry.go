@@ -0,0 +1,215 @@
+// Package llm routes LLM requests to provider backends.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/pkg/claude"
+)
+
+// Retry defaults, matching claude.DefaultConfig
+const (
+	DefaultRetryDelay      = time.Second
+	DefaultMaxRetryDelay   = 30 * time.Second
+	DefaultRetryMultiplier = 2.0
+)
+
+// StatusCodeOverloaded is the non-standard status Anthropic returns when its API is overloaded
+const StatusCodeOverloaded = 529
+
+// StatusError is an unsuccessful HTTP response from a provider. It wraps the provider's own error,
+// so sentinels such as ErrRateLimited still match
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // from the retry-after headers, 0 when absent
+	Err        error
+}
+
+// Error implements the error interface
+func (e *StatusError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the provider error
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// NewStatusError wraps err with the status code and retry-after headers of resp
+func NewStatusError(resp *http.Response, err error) *StatusError {
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: ParseRetryAfter(resp.Header, time.Now()),
+		Err:        err,
+	}
+}
+
+// RetryableStatus reports whether a response status is worth retrying: 408, 429, 5xx and 529.
+// Other 4xx responses mean the request itself is wrong and would fail again
+func RetryableStatus(code int) bool {
+	return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500
+}
+
+// IsRetryable classifies an LLM call error. Provider status errors and claude.APIError are judged
+// by status, and network errors and timeouts are retried. Cancellation, validation and decoding
+// errors are not
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return RetryableStatus(statusErr.StatusCode)
+	}
+	var apiErr *claude.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// RetryAfter returns the delay a provider asked for before the next attempt
+func RetryAfter(err error) (time.Duration, bool) {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		return statusErr.RetryAfter, true
+	}
+	var apiErr *claude.APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter, true
+	}
+	return 0, false
+}
+
+// ParseRetryAfter reads the retry-after-ms header, or retry-after in seconds or as an HTTP date
+func ParseRetryAfter(header http.Header, now time.Time) time.Duration {
+	if value := header.Get("Retry-After-Ms"); value != "" {
+		if ms, err := strconv.ParseFloat(value, 64); err == nil && ms > 0 {
+			return time.Duration(ms * float64(time.Millisecond))
+		}
+	}
+
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// RetryPolicy is the retry engine shared by the LLM provider clients: exponential backoff with
+// jitter, honoring retry-after and context cancellation
+type RetryPolicy struct {
+	MaxRetries   int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+}
+
+// NewRetryPolicy creates a policy from the max_retries and retry_delay settings of a provider;
+// a zero delay uses DefaultRetryDelay
+func NewRetryPolicy(maxRetries int, initialDelay time.Duration) RetryPolicy {
+	if initialDelay <= 0 {
+		initialDelay = DefaultRetryDelay
+	}
+	return RetryPolicy{
+		MaxRetries:   maxRetries,
+		InitialDelay: initialDelay,
+		MaxDelay:     max(DefaultMaxRetryDelay, initialDelay),
+		Multiplier:   DefaultRetryMultiplier,
+	}
+}
+
+// Backoff returns the delay before retry attempt (starting at 1): InitialDelay grown by
+// Multiplier per attempt and capped at MaxDelay, of which a random half is taken off
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(p.InitialDelay) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	half := time.Duration(delay / 2)
+	if half <= 0 {
+		return time.Duration(delay)
+	}
+	return half + rand.N(half+1)
+}
+
+// Do calls call until it succeeds, returns an error that is not retryable, or runs out of retries.
+// A retry waits for the backoff or the provider's retry-after, whichever is longer; a retry-after
+// beyond MaxDelay ends the retries. The last error is returned as is
+func (p RetryPolicy) Do(ctx context.Context, logger zerolog.Logger, call func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := call()
+		if err == nil || attempt >= p.MaxRetries || ctx.Err() != nil || !IsRetryable(err) {
+			return err
+		}
+
+		delay := p.Backoff(attempt + 1)
+		if retryAfter, ok := RetryAfter(err); ok {
+			if p.MaxDelay > 0 && retryAfter > p.MaxDelay {
+				return err
+			}
+			delay = max(delay, retryAfter)
+		}
+
+		logger.Debug().
+			Err(err).
+			Int("attempt", attempt+1).
+			Dur("delay", delay).
+			Msg("Retrying LLM request")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
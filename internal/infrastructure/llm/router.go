@@ -0,0 +1,501 @@
+// Package llm routes LLM requests to provider backends.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/pkg/telemetry"
+)
+
+// Router errors
+var (
+	ErrCircuitOpen     = errors.New("circuit breaker open")
+	ErrAllModelsFailed = errors.New("all models of the fallback chain failed")
+	ErrEmptyStream     = errors.New("stream ended before its first event")
+)
+
+// routerTracerName identifies LLM routing spans
+const routerTracerName = "github.com/telemetryflow/telemetryflow-go-mcp/llm"
+
+// Fallback reasons reported in telemetry
+const (
+	fallbackCircuitOpen   = "circuit_open"
+	fallbackNotConfigured = "not_configured"
+	fallbackUnsupported   = "unsupported_input"
+	fallbackTimeout       = "timeout"
+	fallbackError         = "error"
+)
+
+// UsageRecorder receives the usage record of every LLM call made by the router
+type UsageRecorder interface {
+	RecordUsage(ctx context.Context, usage *entities.LLMUsage)
+}
+
+// Router implements IClaudeService on top of another backend. A model alias expands to its
+// fallback chain, each model is tried in turn, and models whose circuit breaker is open are skipped
+type Router struct {
+	backend        services.IClaudeService
+	aliases        map[vo.Model][]vo.Model
+	attemptTimeout time.Duration
+	breakerCfg     config.CircuitBreakerConfig
+	metrics        *telemetry.Metrics
+	usage          UsageRecorder
+	logger         zerolog.Logger
+	now            func() time.Time
+
+	mu       sync.Mutex
+	breakers map[vo.Model]*circuitBreaker
+}
+
+// NewRouter creates a router over backend; metrics may be nil
+func NewRouter(backend services.IClaudeService, cfg config.RoutingConfig, metrics *telemetry.Metrics, logger zerolog.Logger) *Router {
+	aliases := make(map[vo.Model][]vo.Model, len(cfg.Aliases))
+	for alias, models := range cfg.Aliases {
+		chain := make([]vo.Model, 0, len(models))
+		for _, model := range models {
+			chain = append(chain, vo.Model(model))
+		}
+		aliases[vo.Model(alias)] = chain
+	}
+
+	return &Router{
+		backend:        backend,
+		aliases:        aliases,
+		attemptTimeout: cfg.AttemptTimeout,
+		breakerCfg:     cfg.CircuitBreaker,
+		metrics:        metrics,
+		logger:         logger.With().Str("component", "llm-router").Logger(),
+		now:            time.Now,
+		breakers:       make(map[vo.Model]*circuitBreaker),
+	}
+}
+
+// SetUsageRecorder meters every call, including the failed attempts of a fallback chain
+func (r *Router) SetUsageRecorder(recorder UsageRecorder) {
+	r.usage = recorder
+}
+
+// Aliases returns the configured model aliases, sorted by name
+func (r *Router) Aliases() []vo.Model {
+	aliases := make([]vo.Model, 0, len(r.aliases))
+	for alias := range r.aliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i] < aliases[j] })
+	return aliases
+}
+
+// Chain returns the models tried for model: the fallback chain of an alias, or the model itself
+func (r *Router) Chain(model vo.Model) []vo.Model {
+	if chain, ok := r.aliases[model]; ok {
+		return chain
+	}
+	return []vo.Model{model}
+}
+
+// Health returns the circuit breaker state of every model called so far
+func (r *Router) Health() map[vo.Model]ModelHealth {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	health := make(map[vo.Model]ModelHealth, len(r.breakers))
+	for model, breaker := range r.breakers {
+		health[model] = breaker.health()
+	}
+	return health
+}
+
+// CreateMessage creates a message (non-streaming), falling back along the model's chain
+func (r *Router) CreateMessage(ctx context.Context, request *services.ClaudeRequest) (*services.ClaudeResponse, error) {
+	if request == nil {
+		return nil, ErrInvalidRequest
+	}
+
+	ctx, span := r.startSpan(ctx, request.Model)
+	defer span.End()
+
+	chain := r.Chain(request.Model)
+	var errs []error
+	for i, model := range chain {
+		breaker := r.breaker(model)
+		last := i == len(chain)-1
+		if breaker != nil && !breaker.allow(r.now()) {
+			errs = append(errs, r.fallback(ctx, span, request.Model, model, fallbackCircuitOpen, last, fmt.Errorf("%w: %s", ErrCircuitOpen, model)))
+			continue
+		}
+
+		attemptCtx, cancel := r.attemptContext(ctx, last)
+		start := r.now()
+		response, err := r.backend.CreateMessage(attemptCtx, r.requestFor(request, model))
+		latency := r.now().Sub(start)
+		cancel()
+
+		r.recordRequest(ctx, request.Model, model, response, latency, err, false)
+		if err == nil {
+			if breaker != nil {
+				breaker.record(r.now(), latency, false)
+			}
+			if response.ServedBy == "" {
+				response.ServedBy = model
+			}
+			r.served(span, response.ServedBy, i)
+			return response, nil
+		}
+
+		if ctx.Err() != nil {
+			// The caller gave up; this says nothing about the model's health
+			if breaker != nil {
+				breaker.release()
+			}
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		errs = append(errs, r.fail(ctx, span, breaker, request.Model, model, last, attemptCtx, latency, err))
+	}
+
+	return nil, r.chainError(span, request.Model, chain, errs)
+}
+
+// CreateMessageStream creates a message with streaming. A model that fails before its first event
+// falls back to the next one; once events are flowing, errors are passed to the caller
+func (r *Router) CreateMessageStream(ctx context.Context, request *services.ClaudeRequest) (<-chan *services.ClaudeStreamEvent, error) {
+	if request == nil {
+		return nil, ErrInvalidRequest
+	}
+
+	ctx, span := r.startSpan(ctx, request.Model)
+
+	chain := r.Chain(request.Model)
+	var errs []error
+	for i, model := range chain {
+		breaker := r.breaker(model)
+		last := i == len(chain)-1
+		if breaker != nil && !breaker.allow(r.now()) {
+			errs = append(errs, r.fallback(ctx, span, request.Model, model, fallbackCircuitOpen, last, fmt.Errorf("%w: %s", ErrCircuitOpen, model)))
+			continue
+		}
+
+		streamCtx, cancel := context.WithCancel(ctx)
+		start := r.now()
+		first, events, err := r.openStream(streamCtx, r.requestFor(request, model), last)
+		latency := r.now().Sub(start)
+		if err == nil {
+			r.served(span, model, i)
+			return r.forward(ctx, span, cancel, breaker, request.Model, model, first, events, start), nil
+		}
+		cancel()
+
+		if ctx.Err() != nil {
+			if breaker != nil {
+				breaker.release()
+			}
+			span.SetStatus(codes.Error, err.Error())
+			span.End()
+			return nil, err
+		}
+		r.recordRequest(ctx, request.Model, model, nil, latency, err, true)
+		errs = append(errs, r.fail(ctx, span, breaker, request.Model, model, last, streamCtx, latency, err))
+	}
+
+	err := r.chainError(span, request.Model, chain, errs)
+	span.End()
+	return nil, err
+}
+
+// CountTokens counts tokens with the first model of the chain
+func (r *Router) CountTokens(ctx context.Context, request *services.ClaudeRequest) (int, error) {
+	if request == nil {
+		return 0, ErrInvalidRequest
+	}
+	return r.backend.CountTokens(ctx, r.requestFor(request, r.Chain(request.Model)[0]))
+}
+
+// ValidateRequest validates a request with the first model of the chain
+func (r *Router) ValidateRequest(request *services.ClaudeRequest) error {
+	if request == nil {
+		return ErrInvalidRequest
+	}
+	return r.backend.ValidateRequest(r.requestFor(request, r.Chain(request.Model)[0]))
+}
+
+// openStream starts a stream and waits for its first event, which must arrive within the attempt
+// timeout unless this is the last model of the chain
+func (r *Router) openStream(ctx context.Context, request *services.ClaudeRequest, last bool) (*services.ClaudeStreamEvent, <-chan *services.ClaudeStreamEvent, error) {
+	events, err := r.backend.CreateMessageStream(ctx, request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var timeout <-chan time.Time
+	if r.attemptTimeout > 0 && !last {
+		timer := time.NewTimer(r.attemptTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case first, ok := <-events:
+		switch {
+		case !ok:
+			return nil, nil, ErrEmptyStream
+		case first.Error != nil:
+			return nil, nil, first.Error
+		}
+		return first, events, nil
+	case <-timeout:
+		return nil, nil, context.DeadlineExceeded
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+// forward relays a started stream, marking which model serves it and recording its outcome
+func (r *Router) forward(ctx context.Context, span trace.Span, cancel context.CancelFunc, breaker *circuitBreaker, requested, model vo.Model,
+	first *services.ClaudeStreamEvent, events <-chan *services.ClaudeStreamEvent, start time.Time) <-chan *services.ClaudeStreamEvent {
+	out := make(chan *services.ClaudeStreamEvent)
+	firstLatency := r.now().Sub(start)
+
+	go func() {
+		defer close(out)
+		defer span.End()
+		defer cancel()
+
+		var streamErr error
+		summary := &services.ClaudeResponse{ServedBy: model}
+		for event := first; event != nil; event = <-events {
+			if event.Type == "message_start" && event.Message != nil && event.Message.ServedBy == "" {
+				message := *event.Message
+				message.ServedBy = model
+				event = &services.ClaudeStreamEvent{Type: event.Type, Index: event.Index, Message: &message, Usage: event.Usage}
+			}
+			summary.Usage = services.MergeUsage(summary.Usage, event.Usage)
+			if event.Delta != nil && event.Delta.StopReason != "" {
+				summary.StopReason = event.Delta.StopReason
+			}
+			if event.Error != nil {
+				streamErr = event.Error
+			}
+
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				if breaker != nil {
+					breaker.release()
+				}
+				// The tokens of an abandoned stream are billed up to where it stopped
+				r.recordUsage(ctx, requested, model, summary, r.now().Sub(start), ctx.Err(), true)
+				return
+			}
+		}
+
+		// Time to first event is what matters for a stream's latency
+		if breaker != nil {
+			breaker.record(r.now(), firstLatency, streamErr != nil)
+		}
+		r.recordRequest(ctx, requested, model, summary, r.now().Sub(start), streamErr, true)
+		if streamErr != nil {
+			span.SetStatus(codes.Error, streamErr.Error())
+		}
+	}()
+	return out
+}
+
+// fail records a failed attempt and reports the fallback to the next model
+func (r *Router) fail(ctx context.Context, span trace.Span, breaker *circuitBreaker, requested, model vo.Model, last bool,
+	attemptCtx context.Context, latency time.Duration, err error) error {
+	reason := fallbackError
+	switch {
+	case errors.Is(err, ErrProviderNotConfigured), errors.Is(err, ErrUnknownModelProvider):
+		// A configuration problem, not a health signal
+		reason = fallbackNotConfigured
+		if breaker != nil {
+			breaker.release()
+		}
+	case errors.Is(err, vo.ErrUnsupportedInput):
+		// The model cannot take the request's images or documents; another model of the chain may
+		reason = fallbackUnsupported
+		if breaker != nil {
+			breaker.release()
+		}
+	default:
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+			reason = fallbackTimeout
+		}
+		if breaker != nil {
+			breaker.record(r.now(), latency, true)
+		}
+	}
+	return r.fallback(ctx, span, requested, model, reason, last, fmt.Errorf("%s: %w", model, err))
+}
+
+// fallback reports that model was passed over and returns err for the chain error.
+// Nothing is reported for the last model, which has no model to fall back to
+func (r *Router) fallback(ctx context.Context, span trace.Span, requested, model vo.Model, reason string, last bool, err error) error {
+	if last {
+		return err
+	}
+	span.AddEvent("llm.fallback", trace.WithAttributes(
+		attribute.String("llm.model", model.String()),
+		attribute.String("llm.fallback.reason", reason),
+		attribute.String("error.message", err.Error()),
+	))
+	if r.metrics != nil {
+		r.metrics.RecordClaudeFallback(ctx, requested.String(), model.String(), reason)
+	}
+	r.logger.Warn().
+		Err(err).
+		Str("requested_model", requested.String()).
+		Str("model", model.String()).
+		Str("reason", reason).
+		Msg("LLM call passed to the next model")
+	return err
+}
+
+// chainError builds the error returned when no model of the chain could serve the request
+func (r *Router) chainError(span trace.Span, requested vo.Model, chain []vo.Model, errs []error) error {
+	if len(chain) == 1 && len(errs) == 1 {
+		span.SetStatus(codes.Error, errs[0].Error())
+		return errs[0]
+	}
+	err := fmt.Errorf("%w (%s): %w", ErrAllModelsFailed, requested, errors.Join(errs...))
+	span.SetStatus(codes.Error, err.Error())
+	return err
+}
+
+// served records the model that answered on the call's span
+func (r *Router) served(span trace.Span, model vo.Model, fallbacks int) {
+	span.SetAttributes(
+		attribute.String("llm.model.served", model.String()),
+		attribute.Int("llm.fallbacks", fallbacks),
+	)
+}
+
+func (r *Router) startSpan(ctx context.Context, requested vo.Model) (context.Context, trace.Span) {
+	return otel.Tracer(routerTracerName).Start(ctx, "llm.call "+requested.String(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("llm.model.requested", requested.String())))
+}
+
+func (r *Router) recordRequest(ctx context.Context, requested, model vo.Model, response *services.ClaudeResponse, latency time.Duration,
+	err error, streaming bool) {
+	r.recordUsage(ctx, requested, model, response, latency, err, streaming)
+	if r.metrics == nil {
+		return
+	}
+	var input, output int
+	if response != nil && response.Usage != nil {
+		input, output = response.Usage.TotalInputTokens(), response.Usage.OutputTokens
+	}
+	r.metrics.RecordClaudeRequest(ctx, model.String(), input, output, latency, err)
+}
+
+// recordUsage passes the usage record of a call to the usage recorder
+func (r *Router) recordUsage(ctx context.Context, requested, model vo.Model, response *services.ClaudeResponse, latency time.Duration,
+	err error, streaming bool) {
+	if r.usage == nil {
+		return
+	}
+	if response != nil && response.ServedBy != "" {
+		model = response.ServedBy
+	}
+
+	usage := entities.NewLLMUsage(requested, model)
+	usage.CalledAt = usage.CalledAt.Add(-latency)
+	usage.Latency = latency
+	usage.Streaming = streaming
+	scope := entities.UsageScopeFromContext(ctx)
+	usage.SessionID, usage.ConversationID, usage.OrganizationID = scope.SessionID, scope.ConversationID, scope.OrganizationID
+	if response != nil {
+		usage.StopReason = response.StopReason
+		if response.Usage != nil {
+			usage.InputTokens = response.Usage.InputTokens
+			usage.OutputTokens = response.Usage.OutputTokens
+			usage.CacheReadTokens = response.Usage.CacheReadInputTokens
+			usage.CacheWriteTokens = response.Usage.CacheCreationInputTokens
+			usage.ReasoningTokens = response.Usage.ReasoningTokens
+		}
+	}
+
+	var statusErr *StatusError
+	switch {
+	case err == nil:
+		usage.StatusCode = http.StatusOK
+	case ctx.Err() != nil || errors.Is(err, context.Canceled):
+		usage.Status = entities.LLMCallCancelled
+		usage.ErrorMessage = err.Error()
+	default:
+		usage.Status = entities.LLMCallError
+		usage.ErrorMessage = err.Error()
+		if errors.As(err, &statusErr) {
+			usage.StatusCode = statusErr.StatusCode
+		}
+	}
+	r.usage.RecordUsage(ctx, usage)
+}
+
+// attemptContext bounds an attempt by the attempt timeout, except for the last model of a chain
+func (r *Router) attemptContext(ctx context.Context, last bool) (context.Context, context.CancelFunc) {
+	if r.attemptTimeout <= 0 || last {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.attemptTimeout)
+}
+
+// breaker returns the circuit breaker of model, or nil when breakers are disabled
+func (r *Router) breaker(model vo.Model) *circuitBreaker {
+	if !r.breakerCfg.Enabled {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	breaker, ok := r.breakers[model]
+	if !ok {
+		breaker = newCircuitBreaker(r.breakerCfg)
+		r.breakers[model] = breaker
+	}
+	return breaker
+}
+
+// requestFor returns request addressed to model, copying it when the model differs
+func (r *Router) requestFor(request *services.ClaudeRequest, model vo.Model) *services.ClaudeRequest {
+	if request.Model == model {
+		return request
+	}
+	attempt := *request
+	attempt.Model = model
+	return &attempt
+}
+
+var _ services.IClaudeService = (*Router)(nil)
@@ -0,0 +1,577 @@
+// Package llm routes LLM requests to provider backends.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// Tool calling modes of local providers
+const (
+	ToolCallingAuto     = "auto"     // emulate when the backend reports the model lacks tool support
+	ToolCallingNative   = "native"   // always use the backend's tool calling
+	ToolCallingEmulated = "emulated" // always emulate
+)
+
+// maxHeldToolCallText bounds the text an emulated stream holds back while it may be a tool call;
+// longer text is released and the rest of the answer streams as plain text
+const maxHeldToolCallText = 1 << 20
+
+// codeFence opens and closes the code block a tool call may be wrapped in
+const codeFence = "```"
+
+// ToolSupporter is implemented by backends that know which models support native tool calling
+type ToolSupporter interface {
+	SupportsTools(ctx context.Context, model vo.Model) (bool, error)
+}
+
+// WithToolCalling applies a tool calling mode to backend. An empty mode is auto, which only
+// emulates for backends implementing ToolSupporter
+func WithToolCalling(backend services.IClaudeService, mode string) services.IClaudeService {
+	switch mode {
+	case ToolCallingNative:
+		return backend
+	case ToolCallingEmulated:
+		return NewToolEmulator(backend, func(context.Context, vo.Model) bool { return true })
+	}
+
+	supporter, ok := backend.(ToolSupporter)
+	if !ok {
+		return backend
+	}
+	return NewToolEmulator(backend, func(ctx context.Context, model vo.Model) bool {
+		// When support is unknown, let the backend report its own error
+		supported, err := supporter.SupportsTools(ctx, model)
+		return err == nil && !supported
+	})
+}
+
+// ToolEmulator gives models without native tool calling the same tool_use contract. Tools are
+// described in the system prompt, the model answers with a JSON tool_calls object, and that
+// answer is converted back to tool_use blocks. Emulated streams pass text through until it may
+// start a tool call, then hold it back until the call can be parsed
+type ToolEmulator struct {
+	backend services.IClaudeService
+	emulate func(ctx context.Context, model vo.Model) bool
+}
+
+// NewToolEmulator wraps backend, emulating tool calls for the models emulate selects
+func NewToolEmulator(backend services.IClaudeService, emulate func(ctx context.Context, model vo.Model) bool) *ToolEmulator {
+	return &ToolEmulator{backend: backend, emulate: emulate}
+}
+
+// CreateMessage creates a message (non-streaming)
+func (e *ToolEmulator) CreateMessage(ctx context.Context, request *services.ClaudeRequest) (*services.ClaudeResponse, error) {
+	if !e.emulating(ctx, request) {
+		return e.backend.CreateMessage(ctx, request)
+	}
+
+	emulated, err := emulatedRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	response, err := e.backend.CreateMessage(ctx, emulated)
+	if err != nil {
+		return nil, err
+	}
+	return parseToolCalls(response, request.Tools), nil
+}
+
+// CreateMessageStream creates a message with streaming
+func (e *ToolEmulator) CreateMessageStream(ctx context.Context, request *services.ClaudeRequest) (<-chan *services.ClaudeStreamEvent, error) {
+	if !e.emulating(ctx, request) {
+		return e.backend.CreateMessageStream(ctx, request)
+	}
+
+	emulated, err := emulatedRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	upstream, err := e.backend.CreateMessageStream(ctx, emulated)
+	if err != nil {
+		return nil, err
+	}
+
+	eventChan := make(chan *services.ClaudeStreamEvent, 100)
+
+	go func() {
+		defer close(eventChan)
+
+		send := func(event *services.ClaudeStreamEvent) bool {
+			select {
+			case eventChan <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		stream := newEmulatedStream(request.Tools)
+		for event := range upstream {
+			if event.Error != nil {
+				send(event)
+				return
+			}
+			for _, converted := range stream.convert(event) {
+				if !send(converted) {
+					return
+				}
+			}
+		}
+		for _, converted := range stream.finish() {
+			if !send(converted) {
+				return
+			}
+		}
+	}()
+
+	return eventChan, nil
+}
+
+// CountTokens counts tokens for a message
+func (e *ToolEmulator) CountTokens(ctx context.Context, request *services.ClaudeRequest) (int, error) {
+	if !e.emulating(ctx, request) {
+		return e.backend.CountTokens(ctx, request)
+	}
+	emulated, err := emulatedRequest(request)
+	if err != nil {
+		return 0, err
+	}
+	return e.backend.CountTokens(ctx, emulated)
+}
+
+// ValidateRequest validates a request
+func (e *ToolEmulator) ValidateRequest(request *services.ClaudeRequest) error {
+	return e.backend.ValidateRequest(request)
+}
+
+// ListModels lists the backend's models when it supports discovery
+func (e *ToolEmulator) ListModels(ctx context.Context) ([]vo.Model, error) {
+	lister, ok := e.backend.(ModelLister)
+	if !ok {
+		return nil, nil
+	}
+	return lister.ListModels(ctx)
+}
+
+// emulating reports whether request uses tools on a model that needs emulation
+func (e *ToolEmulator) emulating(ctx context.Context, request *services.ClaudeRequest) bool {
+	if request == nil || !usesTools(request) {
+		return false
+	}
+	return e.emulate(ctx, request.Model)
+}
+
+func usesTools(request *services.ClaudeRequest) bool {
+	if len(request.Tools) > 0 {
+		return true
+	}
+	for _, msg := range request.Messages {
+		for _, block := range msg.Content {
+			if block.Type == vo.ContentTypeToolUse || block.Type == vo.ContentTypeToolResult {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// emulatedToolCall is the JSON shape the model is asked to answer with
+type emulatedToolCall struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+type emulatedToolCalls struct {
+	ToolCalls []emulatedToolCall `json:"tool_calls"`
+}
+
+// emulatedRequest copies request with the tools moved into the system prompt and
+// tool_use/tool_result blocks rewritten as text; images and documents are kept
+func emulatedRequest(request *services.ClaudeRequest) (*services.ClaudeRequest, error) {
+	emulated := *request
+	emulated.Tools = nil
+
+	prompt := request.SystemPrompt.String()
+	if len(request.Tools) > 0 {
+		if prompt != "" {
+			prompt += "\n\n"
+		}
+		prompt += toolInstructions(request.Tools)
+	}
+	systemPrompt, err := vo.NewSystemPrompt(prompt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: tool descriptions: %v", ErrInvalidRequest, err)
+	}
+	emulated.SystemPrompt = systemPrompt
+
+	toolNames := make(map[string]string)
+	emulated.Messages = make([]services.ClaudeMessage, 0, len(request.Messages))
+	for _, msg := range request.Messages {
+		var texts []string
+		var calls []emulatedToolCall
+		var content []entities.ContentBlock
+		for _, block := range msg.Content {
+			switch block.Type {
+			case vo.ContentTypeText:
+				texts = append(texts, block.Text)
+			case vo.ContentTypeImage, vo.ContentTypeDocument:
+				content = append(content, block)
+			case vo.ContentTypeToolUse:
+				toolNames[block.ID] = block.Name
+				calls = append(calls, emulatedToolCall{Name: block.Name, Arguments: block.Input})
+			case vo.ContentTypeToolResult:
+				name := toolNames[block.ToolUseID]
+				if block.IsError {
+					texts = append(texts, fmt.Sprintf("Tool %s failed:\n%s", name, block.Content))
+				} else {
+					texts = append(texts, fmt.Sprintf("Result of tool %s:\n%s", name, block.Content))
+				}
+			}
+		}
+		if len(calls) > 0 {
+			data, _ := json.Marshal(emulatedToolCalls{ToolCalls: calls})
+			texts = append(texts, string(data))
+		}
+		if len(texts) > 0 {
+			content = append(content, entities.ContentBlock{Type: vo.ContentTypeText, Text: strings.Join(texts, "\n\n")})
+		}
+		if len(content) == 0 {
+			continue
+		}
+		emulated.Messages = append(emulated.Messages, services.ClaudeMessage{Role: msg.Role, Content: content})
+	}
+
+	return &emulated, nil
+}
+
+// toolInstructions describes the tools and the tool call answer format
+func toolInstructions(tools []services.ClaudeTool) string {
+	var b strings.Builder
+	b.WriteString("You can call tools. To call tools, answer with only a JSON object of this form and nothing else:\n")
+	b.WriteString(`{"tool_calls": [{"name": "<tool name>", "arguments": {<arguments matching the tool parameters>}}]}`)
+	b.WriteString("\nTool results are sent back to you in the next message. When you need no tool, answer normally.\n\nAvailable tools:\n")
+	for _, tool := range tools {
+		fmt.Fprintf(&b, "\n- %s: %s\n", tool.Name, tool.Description)
+		if tool.InputSchema != nil {
+			parameters, _ := json.Marshal(tool.InputSchema)
+			fmt.Fprintf(&b, "  Parameters (JSON Schema): %s\n", parameters)
+		}
+	}
+	return b.String()
+}
+
+// parseToolCalls converts a tool_calls answer to tool_use blocks. Answers that are not a
+// tool call, or call unknown tools, are returned unchanged
+func parseToolCalls(response *services.ClaudeResponse, tools []services.ClaudeTool) *services.ClaudeResponse {
+	var text strings.Builder
+	for _, block := range response.Content {
+		if block.Type == vo.ContentTypeText {
+			text.WriteString(block.Text)
+		}
+	}
+
+	prefix, calls, ok := findToolCalls(text.String())
+	if !ok {
+		return response
+	}
+	known := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		known[tool.Name] = true
+	}
+	for _, call := range calls {
+		if !known[call.Name] {
+			return response
+		}
+	}
+
+	// The reasoning behind the answer stays ahead of the calls it led to
+	converted := *response
+	converted.Content = make([]entities.ContentBlock, 0, len(calls)+2)
+	for _, block := range response.Content {
+		if block.Type.IsReasoning() {
+			converted.Content = append(converted.Content, block)
+		}
+	}
+	if prefix != "" {
+		converted.Content = append(converted.Content, entities.ContentBlock{Type: vo.ContentTypeText, Text: prefix})
+	}
+	for _, call := range calls {
+		input := call.Arguments
+		if input == nil {
+			input = map[string]interface{}{}
+		}
+		converted.Content = append(converted.Content, entities.ContentBlock{
+			Type:  vo.ContentTypeToolUse,
+			ID:    "toolu_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+			Name:  call.Name,
+			Input: input,
+		})
+	}
+	converted.StopReason = "tool_use"
+	return &converted
+}
+
+// findToolCalls finds a tool_calls object that is the whole answer or its last code block,
+// returning the text before it
+func findToolCalls(text string) (string, []emulatedToolCall, bool) {
+	text = strings.TrimSpace(text)
+	if calls, ok := decodeToolCalls(text); ok {
+		return "", calls, true
+	}
+
+	if !strings.HasSuffix(text, "```") {
+		return "", nil, false
+	}
+	start := strings.LastIndex(strings.TrimSuffix(text, "```"), "```")
+	if start < 0 {
+		return "", nil, false
+	}
+	block := strings.TrimSuffix(text[start+3:], "```")
+	block = strings.TrimPrefix(block, "json")
+	calls, ok := decodeToolCalls(strings.TrimSpace(block))
+	if !ok {
+		return "", nil, false
+	}
+	return strings.TrimSpace(text[:start]), calls, true
+}
+
+func decodeToolCalls(text string) ([]emulatedToolCall, bool) {
+	if !strings.HasPrefix(text, "{") {
+		return nil, false
+	}
+	var answer emulatedToolCalls
+	if err := json.Unmarshal([]byte(text), &answer); err != nil || len(answer.ToolCalls) == 0 {
+		return nil, false
+	}
+	for _, call := range answer.ToolCalls {
+		if call.Name == "" {
+			return nil, false
+		}
+	}
+	return answer.ToolCalls, true
+}
+
+// emulatedStream converts an emulated answer stream into tool_use events. Text is passed
+// through until it may start a tool call: a leading '{' holds the whole answer, and a code
+// fence holds the block until it is closed and followed by more text. Held text is parsed
+// as tool calls when the answer ends, or released as text. Reasoning blocks pass through
+type emulatedStream struct {
+	tools map[string]bool
+
+	nextIndex  int
+	textIndex  int         // -1 until text is released
+	reasoning  map[int]int // upstream index to emitted index of open reasoning blocks
+	held       string
+	started    bool // the answer has non-space text
+	wholeJSON  bool // the answer starts with '{' and is held entirely
+	passing    bool // held text exceeded maxHeldToolCallText; no tool call is parsed
+	stopReason string
+	usage      *services.ClaudeUsage
+}
+
+func newEmulatedStream(tools []services.ClaudeTool) *emulatedStream {
+	known := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		known[tool.Name] = true
+	}
+	return &emulatedStream{tools: known, textIndex: -1, reasoning: make(map[int]int)}
+}
+
+// convert returns the events for one upstream event
+func (s *emulatedStream) convert(event *services.ClaudeStreamEvent) []*services.ClaudeStreamEvent {
+	switch event.Type {
+	case "message_start":
+		return []*services.ClaudeStreamEvent{event}
+	case "content_block_start":
+		if event.ContentBlock != nil && event.ContentBlock.Type.IsReasoning() {
+			index := s.startBlock()
+			s.reasoning[event.Index] = index
+			return []*services.ClaudeStreamEvent{{Type: event.Type, Index: index, ContentBlock: event.ContentBlock}}
+		}
+	case "content_block_delta":
+		if index, ok := s.reasoning[event.Index]; ok {
+			return []*services.ClaudeStreamEvent{{Type: event.Type, Index: index, Delta: event.Delta}}
+		}
+		if event.Delta != nil && event.Delta.Type == "text_delta" {
+			return s.text(s.write(event.Delta.Text))
+		}
+	case "content_block_stop":
+		if index, ok := s.reasoning[event.Index]; ok {
+			delete(s.reasoning, event.Index)
+			return []*services.ClaudeStreamEvent{{Type: event.Type, Index: index}}
+		}
+	case "message_delta":
+		if event.Delta != nil {
+			s.stopReason = event.Delta.StopReason
+		}
+		s.usage = event.Usage
+	}
+	// Text blocks are merged into one, and the message ends in finish
+	return nil
+}
+
+// finish releases or parses the held text and ends the message
+func (s *emulatedStream) finish() []*services.ClaudeStreamEvent {
+	var events []*services.ClaudeStreamEvent
+	prefix, calls := s.toolCalls()
+	if calls == nil {
+		prefix = s.held
+	}
+	events = append(events, s.text(prefix)...)
+	if s.textIndex >= 0 {
+		events = append(events, &services.ClaudeStreamEvent{Type: "content_block_stop", Index: s.textIndex})
+	}
+
+	for _, call := range calls {
+		input := call.Arguments
+		if input == nil {
+			input = map[string]interface{}{}
+		}
+		data, _ := json.Marshal(input)
+		index := s.startBlock()
+		events = append(events,
+			&services.ClaudeStreamEvent{Type: "content_block_start", Index: index, ContentBlock: &entities.ContentBlock{
+				Type: vo.ContentTypeToolUse,
+				ID:   "toolu_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+				Name: call.Name,
+			}},
+			&services.ClaudeStreamEvent{Type: "content_block_delta", Index: index, Delta: &services.ClaudeDelta{Type: "input_json_delta", PartialJSON: string(data)}},
+			&services.ClaudeStreamEvent{Type: "content_block_stop", Index: index},
+		)
+	}
+	for _, index := range slices.Sorted(maps.Values(s.reasoning)) {
+		events = append(events, &services.ClaudeStreamEvent{Type: "content_block_stop", Index: index})
+	}
+
+	stopReason := s.stopReason
+	if calls != nil {
+		stopReason = "tool_use"
+	}
+	return append(events,
+		&services.ClaudeStreamEvent{Type: "message_delta", Delta: &services.ClaudeDelta{StopReason: stopReason}, Usage: s.usage},
+		&services.ClaudeStreamEvent{Type: "message_stop"},
+	)
+}
+
+// toolCalls parses the held text, returning nil calls when it is not a call of known tools
+func (s *emulatedStream) toolCalls() (string, []emulatedToolCall) {
+	if s.passing || s.held == "" {
+		return "", nil
+	}
+	prefix, calls, ok := findToolCalls(s.held)
+	if !ok {
+		return "", nil
+	}
+	for _, call := range calls {
+		if !s.tools[call.Name] {
+			return "", nil
+		}
+	}
+	return prefix, calls
+}
+
+// write adds answer text and returns the text that can no longer be part of a tool call
+func (s *emulatedStream) write(text string) string {
+	if s.passing {
+		return text
+	}
+	s.held += text
+
+	if !s.started {
+		trimmed := strings.TrimSpace(s.held)
+		if trimmed == "" {
+			return ""
+		}
+		s.started = true
+		s.wholeJSON = strings.HasPrefix(trimmed, "{")
+	}
+
+	var released string
+	if !s.wholeJSON {
+		released = s.release()
+	}
+	if len(s.held) > maxHeldToolCallText {
+		released += s.held
+		s.held = ""
+		s.passing = true
+	}
+	return released
+}
+
+// release removes the held text ahead of the last code block that may still be a tool call
+func (s *emulatedStream) release() string {
+	var released strings.Builder
+	for {
+		open := strings.Index(s.held, codeFence)
+		if open < 0 {
+			// Trailing backticks may be the start of a fence
+			keep := len(s.held) - len(strings.TrimRight(s.held, "`"))
+			released.WriteString(s.held[:len(s.held)-keep])
+			s.held = s.held[len(s.held)-keep:]
+			return released.String()
+		}
+		released.WriteString(s.held[:open])
+		s.held = s.held[open:]
+
+		closing := strings.Index(s.held[len(codeFence):], codeFence)
+		if closing < 0 {
+			return released.String()
+		}
+		end := len(codeFence) + closing + len(codeFence)
+		if strings.TrimSpace(s.held[end:]) == "" {
+			// A tool call must be the last block, and this one still is
+			return released.String()
+		}
+		released.WriteString(s.held[:end])
+		s.held = s.held[end:]
+	}
+}
+
+// text returns the events adding text to the answer's text block
+func (s *emulatedStream) text(text string) []*services.ClaudeStreamEvent {
+	if text == "" {
+		return nil
+	}
+	var events []*services.ClaudeStreamEvent
+	if s.textIndex < 0 {
+		s.textIndex = s.startBlock()
+		events = append(events, &services.ClaudeStreamEvent{Type: "content_block_start", Index: s.textIndex, ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeText}})
+	}
+	return append(events, &services.ClaudeStreamEvent{Type: "content_block_delta", Index: s.textIndex, Delta: &services.ClaudeDelta{Type: "text_delta", Text: text}})
+}
+
+func (s *emulatedStream) startBlock() int {
+	index := s.nextIndex
+	s.nextIndex++
+	return index
+}
+
+var _ services.IClaudeService = (*ToolEmulator)(nil)
@@ -0,0 +1,694 @@
+// Package ollama provides an LLM backend for the native Ollama API.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/llm"
+)
+
+// Client errors
+var (
+	ErrInvalidRequest = errors.New("invalid request")
+	ErrAPIError       = errors.New("API error")
+	ErrModelNotFound  = errors.New("model not found")
+)
+
+const (
+	// DefaultBaseURL is the address Ollama listens on by default
+	DefaultBaseURL = "http://localhost:11434"
+
+	// DefaultMaxTokens applies when a request sets no max_tokens
+	DefaultMaxTokens = 4096
+
+	// maxErrorBodySize bounds how much of an error response is read
+	maxErrorBodySize = 64 * 1024
+
+	// maxStreamLineSize bounds a single streamed JSON line
+	maxStreamLineSize = 1024 * 1024
+)
+
+// Client implements IClaudeService on the Ollama /api/chat endpoint
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	retry      llm.RetryPolicy
+	logger     zerolog.Logger
+
+	mu           sync.Mutex
+	capabilities map[string][]string // model name -> capabilities reported by /api/show
+}
+
+// NewClient creates an Ollama client; an empty base URL uses DefaultBaseURL.
+// The API key is optional and only needed behind an authenticating proxy
+func NewClient(cfg config.ProviderConfig, logger zerolog.Logger) *Client {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &Client{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		apiKey:       cfg.APIKey,
+		httpClient:   &http.Client{Timeout: cfg.Timeout},
+		retry:        llm.NewRetryPolicy(cfg.MaxRetries, cfg.RetryDelay),
+		logger:       logger.With().Str("component", "ollama-client").Logger(),
+		capabilities: make(map[string][]string),
+	}
+}
+
+// CreateMessage creates a message (non-streaming)
+func (c *Client) CreateMessage(ctx context.Context, request *services.ClaudeRequest) (*services.ClaudeResponse, error) {
+	if err := c.ValidateRequest(request); err != nil {
+		return nil, err
+	}
+	if err := c.checkInputs(ctx, request); err != nil {
+		return nil, err
+	}
+
+	c.logger.Debug().
+		Str("model", request.Model.String()).
+		Int("max_tokens", request.MaxTokens).
+		Int("message_count", len(request.Messages)).
+		Msg("Creating message")
+
+	resp, err := c.post(ctx, "/api/chat", buildChatRequest(request, false))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var chat chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chat); err != nil {
+		return nil, fmt.Errorf("%w: decoding response: %v", ErrAPIError, err)
+	}
+	if chat.Error != "" {
+		return nil, fmt.Errorf("%w: %s", ErrAPIError, chat.Error)
+	}
+
+	return convertResponse(request.Model, &chat), nil
+}
+
+// CreateMessageStream creates a message with streaming
+func (c *Client) CreateMessageStream(ctx context.Context, request *services.ClaudeRequest) (<-chan *services.ClaudeStreamEvent, error) {
+	if err := c.ValidateRequest(request); err != nil {
+		return nil, err
+	}
+	if err := c.checkInputs(ctx, request); err != nil {
+		return nil, err
+	}
+
+	c.logger.Debug().
+		Str("model", request.Model.String()).
+		Int("max_tokens", request.MaxTokens).
+		Msg("Creating streaming message")
+
+	resp, err := c.post(ctx, "/api/chat", buildChatRequest(request, true))
+	if err != nil {
+		return nil, err
+	}
+
+	eventChan := make(chan *services.ClaudeStreamEvent, 100)
+
+	go func() {
+		defer close(eventChan)
+		defer resp.Body.Close()
+
+		send := func(event *services.ClaudeStreamEvent) bool {
+			select {
+			case eventChan <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		if err := newStreamConverter(request.Model).run(resp.Body, send); err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			select {
+			case eventChan <- &services.ClaudeStreamEvent{Error: err}:
+			default:
+			}
+		}
+	}()
+
+	return eventChan, nil
+}
+
+// CountTokens estimates the tokens of a message; Ollama has no token counting endpoint
+func (c *Client) CountTokens(ctx context.Context, request *services.ClaudeRequest) (int, error) {
+	if err := c.ValidateRequest(request); err != nil {
+		return 0, err
+	}
+
+	data, err := json.Marshal(buildChatRequest(request, false))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	// Roughly four bytes of JSON per token for English text and code; images are estimated apart
+	// from their base64 data
+	mediaChars, mediaTokens := request.EstimateMediaTokens()
+	return (max(len(data)-mediaChars, 0)+3)/4 + mediaTokens, nil
+}
+
+// ValidateRequest validates a Claude request
+func (c *Client) ValidateRequest(request *services.ClaudeRequest) error {
+	if request == nil {
+		return ErrInvalidRequest
+	}
+
+	if request.Model.Provider() != vo.ProviderOllama {
+		return fmt.Errorf("%w: model %s is not served by %s", ErrInvalidRequest, request.Model, vo.ProviderOllama)
+	}
+
+	if len(request.Messages) == 0 {
+		return fmt.Errorf("%w: messages required", ErrInvalidRequest)
+	}
+
+	if request.MaxTokens <= 0 {
+		request.MaxTokens = DefaultMaxTokens
+	}
+
+	return nil
+}
+
+// checkInputs rejects documents, which Ollama cannot read, and images sent to a model that Ollama
+// does not list with vision
+func (c *Client) checkInputs(ctx context.Context, request *services.ClaudeRequest) error {
+	images := false
+	for _, msg := range request.Messages {
+		for _, block := range msg.Content {
+			switch block.Type {
+			case vo.ContentTypeDocument:
+				return fmt.Errorf("%w: %s does not accept document input", vo.ErrUnsupportedInput, request.Model)
+			case vo.ContentTypeImage:
+				images = true
+			}
+		}
+	}
+	if !images {
+		return nil
+	}
+
+	capabilities, err := c.modelCapabilities(ctx, request.Model.ProviderModelName())
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(capabilities, "vision") {
+		return fmt.Errorf("%w: %s does not accept image input", vo.ErrUnsupportedInput, request.Model)
+	}
+	return nil
+}
+
+// ListModels returns the installed models, namespaced as ollama/<name>
+func (c *Client) ListModels(ctx context.Context) ([]vo.Model, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/api/tags", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tags tagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("%w: decoding response: %v", ErrAPIError, err)
+	}
+
+	models := make([]vo.Model, 0, len(tags.Models))
+	for _, m := range tags.Models {
+		name := m.Name
+		if name == "" {
+			name = m.Model
+		}
+		models = append(models, vo.LocalModel(vo.ProviderOllama, name))
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i] < models[j] })
+	return models, nil
+}
+
+// SupportsTools reports whether Ollama lists native tool calling among the model's capabilities
+func (c *Client) SupportsTools(ctx context.Context, model vo.Model) (bool, error) {
+	capabilities, err := c.modelCapabilities(ctx, model.ProviderModelName())
+	if err != nil {
+		return false, err
+	}
+	for _, capability := range capabilities {
+		if capability == "tools" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// modelCapabilities returns the capabilities of a model, caching them per model
+func (c *Client) modelCapabilities(ctx context.Context, name string) ([]string, error) {
+	c.mu.Lock()
+	capabilities, ok := c.capabilities[name]
+	c.mu.Unlock()
+	if ok {
+		return capabilities, nil
+	}
+
+	resp, err := c.post(ctx, "/api/show", &showRequest{Model: name})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var show showResponse
+	if err := json.NewDecoder(resp.Body).Decode(&show); err != nil {
+		return nil, fmt.Errorf("%w: decoding response: %v", ErrAPIError, err)
+	}
+
+	c.mu.Lock()
+	c.capabilities[name] = show.Capabilities
+	c.mu.Unlock()
+	return show.Capabilities, nil
+}
+
+// post sends a JSON body and returns the successful response
+func (c *Client) post(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	return c.do(ctx, http.MethodPost, path, data)
+}
+
+// do sends a request, retrying failed attempts, and returns the successful response
+func (c *Client) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	var resp *http.Response
+	err := c.retry.Do(ctx, c.logger, func() error {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		}
+
+		resp, err = c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrAPIError, err)
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			defer resp.Body.Close()
+			return responseError(resp)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// responseError converts an unsuccessful response to an error carrying its status
+func responseError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+
+	message := strings.TrimSpace(string(data))
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		message = body.Error
+	}
+
+	sentinel := ErrAPIError
+	if resp.StatusCode == http.StatusNotFound {
+		sentinel = ErrModelNotFound
+	}
+	return llm.NewStatusError(resp, fmt.Errorf("%w: ollama returned HTTP %d: %s", sentinel, resp.StatusCode, message))
+}
+
+// buildChatRequest converts a Claude request to an /api/chat request
+func buildChatRequest(request *services.ClaudeRequest, stream bool) *chatRequest {
+	// Ollama takes no thinking budget, but the thinking still counts toward num_predict
+	maxTokens, thinkingBudget := request.OutputTokens()
+	chat := &chatRequest{
+		Model:    request.Model.ProviderModelName(),
+		Messages: buildMessages(request),
+		Stream:   stream,
+		Think:    thinkingBudget > 0,
+		Options: &options{
+			NumPredict: maxTokens,
+			TopK:       request.TopK,
+			Stop:       request.StopSequences,
+		},
+	}
+
+	// Temperature (only set if not default)
+	if request.Temperature > 0 && request.Temperature != 1.0 {
+		temperature := request.Temperature
+		chat.Options.Temperature = &temperature
+	}
+
+	// Top P
+	if request.TopP > 0 && request.TopP < 1.0 {
+		topP := request.TopP
+		chat.Options.TopP = &topP
+	}
+
+	for _, tool := range request.Tools {
+		parameters := tool.InputSchema
+		if parameters == nil {
+			parameters = &entities.JSONSchema{Type: "object", Properties: map[string]*entities.JSONSchema{}}
+		}
+		chat.Tools = append(chat.Tools, chatTool{
+			Type:     "function",
+			Function: chatFunction{Name: tool.Name, Description: tool.Description, Parameters: parameters},
+		})
+	}
+
+	return chat
+}
+
+// buildMessages converts domain messages to chat messages. Tool results become "tool" messages
+// named after the tool, since Ollama tool calls carry no IDs
+func buildMessages(request *services.ClaudeRequest) []chatMessage {
+	toolNames := make(map[string]string)
+	for _, msg := range request.Messages {
+		for _, block := range msg.Content {
+			if block.Type == vo.ContentTypeToolUse {
+				toolNames[block.ID] = block.Name
+			}
+		}
+	}
+
+	messages := make([]chatMessage, 0, len(request.Messages)+1)
+	if !request.SystemPrompt.IsEmpty() {
+		messages = append(messages, chatMessage{Role: "system", Content: request.SystemPrompt.String()})
+	}
+
+	for _, msg := range request.Messages {
+		if msg.Role == vo.RoleAssistant {
+			assistant := chatMessage{Role: "assistant"}
+			var text strings.Builder
+			for _, block := range msg.Content {
+				switch block.Type {
+				case vo.ContentTypeText:
+					text.WriteString(block.Text)
+				case vo.ContentTypeThinking:
+					assistant.Thinking += block.Thinking
+				case vo.ContentTypeToolUse:
+					assistant.ToolCalls = append(assistant.ToolCalls, toolCall{
+						Function: toolCallFunction{Name: block.Name, Arguments: block.Input},
+					})
+				}
+			}
+			assistant.Content = text.String()
+			// Thinking is only worth replaying while the model is still working through tool calls
+			if len(assistant.ToolCalls) == 0 {
+				assistant.Thinking = ""
+			}
+			messages = append(messages, assistant)
+			continue
+		}
+
+		var texts, images []string
+		for _, block := range msg.Content {
+			switch block.Type {
+			case vo.ContentTypeText:
+				texts = append(texts, block.Text)
+			case vo.ContentTypeImage:
+				// Ollama takes images as base64 only
+				if block.Source != nil && block.Source.Data != "" {
+					images = append(images, block.Source.Data)
+				}
+			case vo.ContentTypeToolResult:
+				content := block.Content
+				if block.IsError {
+					content = "Error: " + content
+				}
+				messages = append(messages, chatMessage{Role: "tool", Content: content, ToolName: toolNames[block.ToolUseID]})
+			}
+		}
+		if len(texts) > 0 || len(images) > 0 {
+			messages = append(messages, chatMessage{Role: msg.Role.String(), Content: strings.Join(texts, "\n\n"), Images: images})
+		}
+	}
+
+	return messages
+}
+
+// convertResponse converts a chat result to a domain response
+func convertResponse(model vo.Model, chat *chatResponse) *services.ClaudeResponse {
+	content := make([]entities.ContentBlock, 0, 2+len(chat.Message.ToolCalls))
+	if chat.Message.Thinking != "" {
+		content = append(content, entities.ContentBlock{Type: vo.ContentTypeThinking, Thinking: chat.Message.Thinking})
+	}
+	if chat.Message.Content != "" {
+		content = append(content, entities.ContentBlock{Type: vo.ContentTypeText, Text: chat.Message.Content})
+	}
+	for _, call := range chat.Message.ToolCalls {
+		content = append(content, toolUseBlock(call))
+	}
+
+	return &services.ClaudeResponse{
+		ID:         newMessageID(),
+		Type:       "message",
+		Role:       vo.RoleAssistant,
+		Content:    content,
+		Model:      model.String(),
+		StopReason: stopReason(chat.DoneReason, len(chat.Message.ToolCalls) > 0),
+		Usage:      &services.ClaudeUsage{InputTokens: chat.PromptEvalCount, OutputTokens: chat.EvalCount},
+	}
+}
+
+// toolUseBlock converts a tool call, generating the ID Ollama does not send
+func toolUseBlock(call toolCall) entities.ContentBlock {
+	input := call.Function.Arguments
+	if input == nil {
+		input = map[string]interface{}{}
+	}
+	return entities.ContentBlock{
+		Type:  vo.ContentTypeToolUse,
+		ID:    "toolu_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Name:  call.Function.Name,
+		Input: input,
+	}
+}
+
+func newMessageID() string {
+	return "msg_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+}
+
+// stopReason maps an Ollama done reason to the Anthropic stop reason
+func stopReason(doneReason string, hasToolUse bool) string {
+	if hasToolUse {
+		return "tool_use"
+	}
+	switch doneReason {
+	case "stop", "":
+		return "end_turn"
+	case "length":
+		return "max_tokens"
+	default:
+		return doneReason
+	}
+}
+
+// streamConverter turns streamed /api/chat lines into Anthropic-style stream events
+type streamConverter struct {
+	model         vo.Model
+	started       bool
+	nextIndex     int
+	thinkingIndex int // block index of the open thinking block, -1 when none
+	textIndex     int // block index of the open text block, -1 when none
+	openBlocks    []int
+	hasToolUse    bool
+	done          *chatResponse
+}
+
+func newStreamConverter(model vo.Model) *streamConverter {
+	return &streamConverter{model: model, thinkingIndex: -1, textIndex: -1}
+}
+
+// run reads JSON lines from body until the final line or a send failure
+func (s *streamConverter) run(body io.Reader, send func(*services.ClaudeStreamEvent) bool) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk chatResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return fmt.Errorf("%w: decoding stream chunk: %v", ErrAPIError, err)
+		}
+		if chunk.Error != "" {
+			return fmt.Errorf("%w: %s", ErrAPIError, chunk.Error)
+		}
+		for _, event := range s.convert(&chunk) {
+			if !send(event) {
+				return context.Canceled
+			}
+		}
+		if chunk.Done {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: reading stream: %v", ErrAPIError, err)
+	}
+
+	for _, event := range s.finish() {
+		if !send(event) {
+			return context.Canceled
+		}
+	}
+	return nil
+}
+
+// convert returns the events for one line
+func (s *streamConverter) convert(chunk *chatResponse) []*services.ClaudeStreamEvent {
+	var events []*services.ClaudeStreamEvent
+	if !s.started {
+		s.started = true
+		events = append(events, &services.ClaudeStreamEvent{
+			Type:    "message_start",
+			Message: &services.ClaudeResponse{ID: newMessageID(), Type: "message", Model: s.model.String(), Role: vo.RoleAssistant},
+		})
+	}
+
+	if chunk.Message.Thinking != "" {
+		if s.thinkingIndex < 0 {
+			s.thinkingIndex = s.startBlock()
+			s.textIndex = -1
+			events = append(events, &services.ClaudeStreamEvent{
+				Type:         "content_block_start",
+				Index:        s.thinkingIndex,
+				ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeThinking},
+			})
+		}
+		events = append(events, &services.ClaudeStreamEvent{
+			Type:  "content_block_delta",
+			Index: s.thinkingIndex,
+			Delta: &services.ClaudeDelta{Type: "thinking_delta", Thinking: chunk.Message.Thinking},
+		})
+	}
+
+	if chunk.Message.Content != "" {
+		if s.textIndex < 0 {
+			s.textIndex = s.startBlock()
+			s.thinkingIndex = -1
+			events = append(events, &services.ClaudeStreamEvent{
+				Type:         "content_block_start",
+				Index:        s.textIndex,
+				ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeText},
+			})
+		}
+		events = append(events, &services.ClaudeStreamEvent{
+			Type:  "content_block_delta",
+			Index: s.textIndex,
+			Delta: &services.ClaudeDelta{Type: "text_delta", Text: chunk.Message.Content},
+		})
+	}
+
+	// Tool calls arrive whole, so each is a complete block
+	for _, call := range chunk.Message.ToolCalls {
+		block := toolUseBlock(call)
+		input, _ := json.Marshal(block.Input)
+		index := s.startBlock()
+		s.thinkingIndex, s.textIndex = -1, -1
+		s.hasToolUse = true
+		events = append(events,
+			&services.ClaudeStreamEvent{
+				Type:         "content_block_start",
+				Index:        index,
+				ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeToolUse, ID: block.ID, Name: block.Name},
+			},
+			&services.ClaudeStreamEvent{
+				Type:  "content_block_delta",
+				Index: index,
+				Delta: &services.ClaudeDelta{Type: "input_json_delta", PartialJSON: string(input)},
+			},
+		)
+	}
+
+	if chunk.Done {
+		s.done = chunk
+	}
+	return events
+}
+
+// finish closes the open blocks and ends the message
+func (s *streamConverter) finish() []*services.ClaudeStreamEvent {
+	events := make([]*services.ClaudeStreamEvent, 0, len(s.openBlocks)+2)
+	for _, index := range s.openBlocks {
+		events = append(events, &services.ClaudeStreamEvent{Type: "content_block_stop", Index: index})
+	}
+
+	usage := &services.ClaudeUsage{}
+	doneReason := ""
+	if s.done != nil {
+		usage.InputTokens = s.done.PromptEvalCount
+		usage.OutputTokens = s.done.EvalCount
+		doneReason = s.done.DoneReason
+	}
+	return append(events,
+		&services.ClaudeStreamEvent{
+			Type:  "message_delta",
+			Delta: &services.ClaudeDelta{StopReason: stopReason(doneReason, s.hasToolUse)},
+			Usage: usage,
+		},
+		&services.ClaudeStreamEvent{Type: "message_stop"},
+	)
+}
+
+func (s *streamConverter) startBlock() int {
+	index := s.nextIndex
+	s.nextIndex++
+	s.openBlocks = append(s.openBlocks, index)
+	return index
+}
+
+var _ services.IClaudeService = (*Client)(nil)
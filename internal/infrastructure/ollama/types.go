@@ -0,0 +1,106 @@
+// Package ollama provides an LLM backend for the native Ollama API.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ollama
+
+import (
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+)
+
+// chatRequest is the body of POST /api/chat
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Tools    []chatTool    `json:"tools,omitempty"`
+	Stream   bool          `json:"stream"`
+	Think    bool          `json:"think,omitempty"`
+	Options  *options      `json:"options,omitempty"`
+}
+
+// options holds sampling settings
+type options struct {
+	NumPredict  int      `json:"num_predict,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	TopK        int      `json:"top_k,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+}
+
+// chatMessage is one message of the conversation
+type chatMessage struct {
+	Role      string     `json:"role"`
+	Content   string     `json:"content"`
+	Images    []string   `json:"images,omitempty"` // base64
+	Thinking  string     `json:"thinking,omitempty"`
+	ToolCalls []toolCall `json:"tool_calls,omitempty"`
+	ToolName  string     `json:"tool_name,omitempty"`
+}
+
+// toolCall is a function call requested by the model
+type toolCall struct {
+	Function toolCallFunction `json:"function"`
+}
+
+// toolCallFunction names the function and carries its arguments as an object
+type toolCallFunction struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// chatTool is a function the model may call
+type chatTool struct {
+	Type     string       `json:"type"`
+	Function chatFunction `json:"function"`
+}
+
+// chatFunction describes a callable function
+type chatFunction struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description,omitempty"`
+	Parameters  *entities.JSONSchema `json:"parameters"`
+}
+
+// chatResponse is a chat result or, when streaming, one line of it
+type chatResponse struct {
+	Model           string      `json:"model"`
+	CreatedAt       string      `json:"created_at"`
+	Message         chatMessage `json:"message"`
+	Done            bool        `json:"done"`
+	DoneReason      string      `json:"done_reason"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+	EvalCount       int         `json:"eval_count"`
+	Error           string      `json:"error"`
+}
+
+// tagsResponse is the GET /api/tags result
+type tagsResponse struct {
+	Models []struct {
+		Name  string `json:"name"`
+		Model string `json:"model"`
+	} `json:"models"`
+}
+
+// showRequest is the body of POST /api/show
+type showRequest struct {
+	Model string `json:"model"`
+}
+
+// showResponse is the part of the POST /api/show result used here
+type showResponse struct {
+	Capabilities []string `json:"capabilities"`
+}
@@ -0,0 +1,860 @@
+// Package openai provides an LLM backend for OpenAI-compatible chat completions APIs.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/llm"
+)
+
+// Client errors
+var (
+	ErrAPIKeyRequired  = errors.New("API key is required")
+	ErrUnknownProvider = errors.New("unknown provider")
+	ErrInvalidRequest  = errors.New("invalid request")
+	ErrAPIError        = errors.New("API error")
+	ErrRateLimited     = errors.New("rate limited")
+)
+
+const (
+	// DefaultMaxTokens applies when a request sets no max_tokens
+	DefaultMaxTokens = 4096
+
+	// maxErrorBodySize bounds how much of an error response is read
+	maxErrorBodySize = 64 * 1024
+
+	// maxStreamLineSize bounds a single server-sent event line
+	maxStreamLineSize = 1024 * 1024
+)
+
+// defaultBaseURLs holds the public chat completions endpoint of each supported provider
+var defaultBaseURLs = map[vo.Provider]string{
+	vo.ProviderOpenAI:   "https://api.openai.com/v1",
+	vo.ProviderDeepSeek: "https://api.deepseek.com/v1",
+	vo.ProviderQwen:     "https://dashscope-intl.aliyuncs.com/compatible-mode/v1",
+	vo.ProviderMistral:  "https://api.mistral.ai/v1",
+	vo.ProviderXAI:      "https://api.x.ai/v1",
+	vo.ProviderMoonshot: "https://api.moonshot.ai/v1",
+	vo.ProviderZhipu:    "https://open.bigmodel.cn/api/paas/v4",
+	vo.ProviderMiMo:     "https://api.xiaomimimo.com/v1",
+}
+
+// Providers returns the providers with a known OpenAI-compatible endpoint, sorted by name
+func Providers() []vo.Provider {
+	providers := make([]vo.Provider, 0, len(defaultBaseURLs))
+	for p := range defaultBaseURLs {
+		providers = append(providers, p)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i] < providers[j] })
+	return providers
+}
+
+// DefaultBaseURL returns the public endpoint of a provider
+func DefaultBaseURL(provider vo.Provider) (string, bool) {
+	baseURL, ok := defaultBaseURLs[provider]
+	return baseURL, ok
+}
+
+// Client implements IClaudeService on an OpenAI-compatible chat completions API
+type Client struct {
+	provider     vo.Provider
+	baseURL      string
+	apiKey       string
+	organization string
+	project      string
+	cacheKeys    bool // send a prompt_cache_key derived from the stable prefix
+	httpClient   *http.Client
+	retry        llm.RetryPolicy
+	logger       zerolog.Logger
+}
+
+// NewClient creates a client for provider; an empty base URL uses the provider's public endpoint.
+// Local providers need a base URL but no API key
+func NewClient(provider vo.Provider, cfg config.ProviderConfig, logger zerolog.Logger) (*Client, error) {
+	if cfg.APIKey == "" && !provider.IsLocal() {
+		return nil, ErrAPIKeyRequired
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		var ok bool
+		if baseURL, ok = DefaultBaseURL(provider); !ok {
+			return nil, fmt.Errorf("%w: %s has no default endpoint, set base_url", ErrUnknownProvider, provider)
+		}
+	}
+
+	return &Client{
+		provider:     provider,
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		apiKey:       cfg.APIKey,
+		organization: cfg.Organization,
+		project:      cfg.Project,
+		cacheKeys:    cfg.PromptCaching.Enabled,
+		httpClient:   &http.Client{Timeout: cfg.Timeout},
+		retry:        llm.NewRetryPolicy(cfg.MaxRetries, cfg.RetryDelay),
+		logger:       logger.With().Str("component", "openai-client").Str("provider", provider.String()).Logger(),
+	}, nil
+}
+
+// Provider returns the provider this client calls
+func (c *Client) Provider() vo.Provider {
+	return c.provider
+}
+
+// CreateMessage creates a message (non-streaming)
+func (c *Client) CreateMessage(ctx context.Context, request *services.ClaudeRequest) (*services.ClaudeResponse, error) {
+	if err := c.ValidateRequest(request); err != nil {
+		return nil, err
+	}
+
+	c.logger.Debug().
+		Str("model", request.Model.String()).
+		Int("max_tokens", request.MaxTokens).
+		Int("message_count", len(request.Messages)).
+		Msg("Creating message")
+
+	resp, err := c.post(ctx, c.buildChatRequest(request, false))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var completion chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return nil, fmt.Errorf("%w: decoding response: %v", ErrAPIError, err)
+	}
+	if len(completion.Choices) == 0 {
+		return nil, fmt.Errorf("%w: response has no choices", ErrAPIError)
+	}
+
+	return c.convertResponse(&completion), nil
+}
+
+// CreateMessageStream creates a message with streaming
+func (c *Client) CreateMessageStream(ctx context.Context, request *services.ClaudeRequest) (<-chan *services.ClaudeStreamEvent, error) {
+	if err := c.ValidateRequest(request); err != nil {
+		return nil, err
+	}
+
+	c.logger.Debug().
+		Str("model", request.Model.String()).
+		Int("max_tokens", request.MaxTokens).
+		Msg("Creating streaming message")
+
+	resp, err := c.post(ctx, c.buildChatRequest(request, true))
+	if err != nil {
+		return nil, err
+	}
+
+	eventChan := make(chan *services.ClaudeStreamEvent, 100)
+
+	go func() {
+		defer close(eventChan)
+		defer resp.Body.Close()
+
+		send := func(event *services.ClaudeStreamEvent) bool {
+			select {
+			case eventChan <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		if err := newStreamConverter(request.Model).run(resp.Body, send); err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			select {
+			case eventChan <- &services.ClaudeStreamEvent{Error: err}:
+			default:
+			}
+		}
+	}()
+
+	return eventChan, nil
+}
+
+// CountTokens estimates the input tokens of a request; OpenAI-compatible APIs have no counting endpoint
+func (c *Client) CountTokens(ctx context.Context, request *services.ClaudeRequest) (int, error) {
+	if err := c.ValidateRequest(request); err != nil {
+		return 0, err
+	}
+
+	chat := c.buildChatRequest(request, false)
+	data, err := json.Marshal(struct {
+		Messages []chatMessage `json:"messages"`
+		Tools    []chatTool    `json:"tools,omitempty"`
+	}{chat.Messages, chat.Tools})
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	// Roughly four bytes of JSON per token for English text and code; images and documents are
+	// estimated apart from their base64 data
+	mediaChars, mediaTokens := request.EstimateMediaTokens()
+	return (len(data)-mediaChars+3)/4 + mediaTokens, nil
+}
+
+// ValidateRequest validates a Claude request
+func (c *Client) ValidateRequest(request *services.ClaudeRequest) error {
+	if request == nil {
+		return ErrInvalidRequest
+	}
+
+	if !request.Model.IsValid() {
+		return fmt.Errorf("%w: invalid model", ErrInvalidRequest)
+	}
+
+	if request.Model.Provider() != c.provider {
+		return fmt.Errorf("%w: model %s is not served by %s", ErrInvalidRequest, request.Model, c.provider)
+	}
+
+	if len(request.Messages) == 0 {
+		return fmt.Errorf("%w: messages required", ErrInvalidRequest)
+	}
+
+	if request.MaxTokens <= 0 {
+		request.MaxTokens = DefaultMaxTokens
+	}
+
+	return nil
+}
+
+// post sends a chat completions request, retrying failed attempts, and returns the successful response
+func (c *Client) post(ctx context.Context, chat *chatRequest) (*http.Response, error) {
+	body, err := json.Marshal(chat)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	var resp *http.Response
+	err = c.retry.Do(ctx, c.logger, func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+		}
+		c.setHeaders(req)
+		req.Header.Set("Content-Type", "application/json")
+		if chat.Stream {
+			req.Header.Set("Accept", "text/event-stream")
+		} else {
+			req.Header.Set("Accept", "application/json")
+		}
+
+		resp, err = c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrAPIError, err)
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			defer resp.Body.Close()
+			return c.responseError(resp)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListModels returns the models the endpoint serves; models of local providers are namespaced by provider
+func (c *Client) ListModels(ctx context.Context) ([]vo.Model, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	c.setHeaders(req)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAPIError, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, c.responseError(resp)
+	}
+
+	var list modelList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("%w: decoding response: %v", ErrAPIError, err)
+	}
+
+	models := make([]vo.Model, 0, len(list.Data))
+	for _, m := range list.Data {
+		if c.provider.IsLocal() {
+			models = append(models, vo.LocalModel(c.provider, m.ID))
+		} else {
+			models = append(models, vo.Model(m.ID))
+		}
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i] < models[j] })
+	return models, nil
+}
+
+// setHeaders sets the credential and account headers of a request
+func (c *Client) setHeaders(req *http.Request) {
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	if c.organization != "" {
+		req.Header.Set("OpenAI-Organization", c.organization)
+	}
+	if c.project != "" {
+		req.Header.Set("OpenAI-Project", c.project)
+	}
+}
+
+// responseError converts an unsuccessful response to an error carrying its status
+func (c *Client) responseError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+
+	message := strings.TrimSpace(string(data))
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(data, &body) == nil && len(body.Error) > 0 {
+		var detail struct {
+			Message string `json:"message"`
+		}
+		var text string
+		switch {
+		case json.Unmarshal(body.Error, &detail) == nil && detail.Message != "":
+			message = detail.Message
+		case json.Unmarshal(body.Error, &text) == nil && text != "":
+			message = text
+		}
+	}
+
+	sentinel := ErrAPIError
+	if resp.StatusCode == http.StatusTooManyRequests {
+		sentinel = ErrRateLimited
+	}
+	return llm.NewStatusError(resp, fmt.Errorf("%w: %s returned HTTP %d: %s", sentinel, c.provider, resp.StatusCode, message))
+}
+
+// buildChatRequest builds the chat completions request body
+func (c *Client) buildChatRequest(request *services.ClaudeRequest, stream bool) *chatRequest {
+	chat := &chatRequest{
+		Model:    request.Model.ProviderModelName(),
+		Messages: buildMessages(request, c.echoesReasoning()),
+		Stop:     request.StopSequences,
+		Stream:   stream,
+	}
+
+	// OpenAI reasoning models only accept max_completion_tokens; other providers expect max_tokens.
+	// Both count reasoning tokens
+	maxTokens, reasoningBudget := request.OutputTokens()
+	if c.provider == vo.ProviderOpenAI {
+		chat.MaxCompletionTokens = maxTokens
+	} else {
+		chat.MaxTokens = maxTokens
+	}
+
+	if reasoningBudget > 0 {
+		c.setReasoning(chat, request, reasoningBudget)
+	}
+
+	// OpenAI reasoning models reject sampling settings
+	if reasoningBudget == 0 || c.provider != vo.ProviderOpenAI {
+		// Temperature (only set if not default)
+		if request.Temperature > 0 && request.Temperature != 1.0 {
+			temperature := request.Temperature
+			chat.Temperature = &temperature
+		}
+
+		// Top P
+		if request.TopP > 0 && request.TopP < 1.0 {
+			topP := request.TopP
+			chat.TopP = &topP
+		}
+	}
+
+	if stream {
+		chat.StreamOptions = &streamOptions{IncludeUsage: true}
+	}
+
+	for _, tool := range request.Tools {
+		parameters := tool.InputSchema
+		if parameters == nil {
+			parameters = &entities.JSONSchema{Type: "object", Properties: map[string]*entities.JSONSchema{}}
+		}
+		chat.Tools = append(chat.Tools, chatTool{
+			Type: "function",
+			Function: chatFunction{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  parameters,
+			},
+		})
+	}
+
+	if c.cacheKeys {
+		chat.PromptCacheKey = promptCacheKey(chat)
+	}
+
+	return chat
+}
+
+// setReasoning asks for reasoning in the dialect of the provider. Mistral has no reasoning option;
+// its reasoning models always reason
+func (c *Client) setReasoning(chat *chatRequest, request *services.ClaudeRequest, budget int) {
+	switch c.provider {
+	case vo.ProviderOpenAI, vo.ProviderLocal:
+		chat.ReasoningEffort = request.Reasoning.Level().String()
+	case vo.ProviderXAI:
+		// Only grok-3-mini takes an effort, low or high; Grok 4 models always reason and reject it
+		if request.Model == vo.ModelGrok3Mini {
+			chat.ReasoningEffort = string(vo.ReasoningEffortHigh)
+			if request.Reasoning.Level() == vo.ReasoningEffortLow {
+				chat.ReasoningEffort = string(vo.ReasoningEffortLow)
+			}
+		}
+	case vo.ProviderQwen:
+		enabled := true
+		chat.EnableThinking = &enabled
+		chat.ThinkingBudget = budget
+	case vo.ProviderDeepSeek, vo.ProviderMoonshot, vo.ProviderZhipu, vo.ProviderMiMo:
+		chat.Thinking = &thinkingOption{Type: "enabled"}
+	}
+}
+
+// echoesReasoning reports whether the provider takes reasoning back as reasoning_content;
+// OpenAI and Mistral do not accept the field
+func (c *Client) echoesReasoning() bool {
+	return c.provider != vo.ProviderOpenAI && c.provider != vo.ProviderMistral
+}
+
+// promptCacheKey names the stable prefix of a request, its model, system message and tools, so
+// that requests sharing it are routed to the same prompt cache
+func promptCacheKey(chat *chatRequest) string {
+	hash := sha256.New()
+	hash.Write([]byte(chat.Model))
+	hash.Write([]byte{0})
+	if len(chat.Messages) > 0 && chat.Messages[0].Role == "system" && chat.Messages[0].Content != nil {
+		hash.Write([]byte(*chat.Messages[0].Content))
+	}
+	hash.Write([]byte{0})
+	tools, _ := json.Marshal(chat.Tools)
+	hash.Write(tools)
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// buildMessages converts domain messages to chat messages. Tool results become "tool"
+// messages placed right after the assistant message that requested them. With echoReasoning,
+// the reasoning behind the tool calls of the current turn is sent back, as thinking models such
+// as deepseek-reasoner and kimi-k2-thinking require; reasoning of earlier turns is dropped
+func buildMessages(request *services.ClaudeRequest, echoReasoning bool) []chatMessage {
+	messages := make([]chatMessage, 0, len(request.Messages)+1)
+
+	if !request.SystemPrompt.IsEmpty() {
+		messages = append(messages, textMessage("system", request.SystemPrompt.String()))
+	}
+
+	turnStart := currentTurn(request.Messages)
+	for i, msg := range request.Messages {
+		if msg.Role == vo.RoleAssistant {
+			messages = append(messages, assistantMessage(msg.Content, echoReasoning && i > turnStart))
+			continue
+		}
+
+		var texts []string
+		var parts []contentPart
+		for _, block := range msg.Content {
+			switch block.Type {
+			case vo.ContentTypeText:
+				texts = append(texts, block.Text)
+				parts = append(parts, contentPart{Type: "text", Text: block.Text})
+
+			case vo.ContentTypeImage, vo.ContentTypeDocument:
+				if part, ok := mediaPart(block); ok {
+					parts = append(parts, part)
+				}
+
+			case vo.ContentTypeToolResult:
+				content := block.Content
+				if block.IsError {
+					content = "Error: " + content
+				}
+				tool := textMessage("tool", content)
+				tool.ToolCallID = block.ToolUseID
+				messages = append(messages, tool)
+			}
+		}
+		switch {
+		case len(parts) > len(texts):
+			messages = append(messages, chatMessage{Role: msg.Role.String(), Parts: parts})
+		case len(texts) > 0:
+			messages = append(messages, textMessage(msg.Role.String(), strings.Join(texts, "\n\n")))
+		}
+	}
+
+	return messages
+}
+
+// mediaPart converts an image to an image_url part and a PDF document to a file part
+func mediaPart(block entities.ContentBlock) (contentPart, bool) {
+	source := block.Source
+	if source == nil {
+		return contentPart{}, false
+	}
+	url := source.URL
+	if source.Type != "url" {
+		url = "data:" + source.MediaType + ";base64," + source.Data
+	}
+
+	if block.Type == vo.ContentTypeImage {
+		return contentPart{Type: "image_url", ImageURL: &imageURL{URL: url}}, true
+	}
+	if source.Type == "url" {
+		// Files can only be sent inline
+		return contentPart{}, false
+	}
+	return contentPart{Type: "file", File: &filePart{Filename: block.Name, FileData: url}}, true
+}
+
+// currentTurn returns the index of the last user message with text, which starts the current turn
+func currentTurn(messages []services.ClaudeMessage) int {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != vo.RoleUser {
+			continue
+		}
+		for _, block := range messages[i].Content {
+			if block.Type == vo.ContentTypeText {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// assistantMessage converts assistant text and tool_use blocks, and with withReasoning the
+// thinking behind tool calls
+func assistantMessage(blocks []entities.ContentBlock, withReasoning bool) chatMessage {
+	msg := chatMessage{Role: "assistant"}
+
+	var text strings.Builder
+	for _, block := range blocks {
+		switch block.Type {
+		case vo.ContentTypeText:
+			text.WriteString(block.Text)
+
+		case vo.ContentTypeToolUse:
+			input := block.Input
+			if input == nil {
+				input = map[string]interface{}{}
+			}
+			arguments, _ := json.Marshal(input)
+			msg.ToolCalls = append(msg.ToolCalls, toolCall{
+				ID:       block.ID,
+				Type:     "function",
+				Function: functionCall{Name: block.Name, Arguments: string(arguments)},
+			})
+		}
+	}
+
+	if withReasoning && len(msg.ToolCalls) > 0 {
+		msg.ReasoningContent = entities.ReasoningText(blocks)
+	}
+
+	// Content may only be null when the message carries tool calls
+	if text.Len() > 0 || len(msg.ToolCalls) == 0 {
+		content := text.String()
+		msg.Content = &content
+	}
+	return msg
+}
+
+func textMessage(role, text string) chatMessage {
+	return chatMessage{Role: role, Content: &text}
+}
+
+// convertResponse converts a chat completion to a domain response
+func (c *Client) convertResponse(completion *chatResponse) *services.ClaudeResponse {
+	choice := completion.Choices[0]
+	content := make([]entities.ContentBlock, 0, 2+len(choice.Message.ToolCalls))
+
+	if reasoning := choice.Message.reasoning(); reasoning != "" {
+		content = append(content, entities.ContentBlock{
+			Type:     vo.ContentTypeThinking,
+			Thinking: reasoning,
+		})
+	}
+	if choice.Message.Content != nil && *choice.Message.Content != "" {
+		content = append(content, entities.ContentBlock{
+			Type: vo.ContentTypeText,
+			Text: *choice.Message.Content,
+		})
+	}
+	for _, call := range choice.Message.ToolCalls {
+		content = append(content, entities.ContentBlock{
+			Type:  vo.ContentTypeToolUse,
+			ID:    call.ID,
+			Name:  call.Function.Name,
+			Input: c.parseArguments(call.Function.Name, call.Function.Arguments),
+		})
+	}
+
+	response := &services.ClaudeResponse{
+		ID:         completion.ID,
+		Type:       "message",
+		Role:       vo.RoleAssistant,
+		Content:    content,
+		Model:      completion.Model,
+		StopReason: stopReason(choice.FinishReason),
+		Usage:      convertUsage(completion.Usage),
+	}
+	return response
+}
+
+// convertUsage reports cached prompt tokens apart from the other input tokens
+func convertUsage(usage *chatUsage) *services.ClaudeUsage {
+	if usage == nil {
+		return &services.ClaudeUsage{}
+	}
+	cached := usage.PromptCacheHitTokens
+	if usage.PromptTokensDetails != nil && usage.PromptTokensDetails.CachedTokens > cached {
+		cached = usage.PromptTokensDetails.CachedTokens
+	}
+	cached = min(cached, usage.PromptTokens)
+	converted := &services.ClaudeUsage{
+		InputTokens:          usage.PromptTokens - cached,
+		OutputTokens:         usage.CompletionTokens,
+		CacheReadInputTokens: cached,
+	}
+	if usage.CompletionTokensDetails != nil {
+		converted.ReasoningTokens = usage.CompletionTokensDetails.ReasoningTokens
+	}
+	return converted
+}
+
+// parseArguments decodes tool call arguments, which the API returns as a JSON string
+func (c *Client) parseArguments(tool, arguments string) map[string]interface{} {
+	input := make(map[string]interface{})
+	if strings.TrimSpace(arguments) == "" {
+		return input
+	}
+	if err := json.Unmarshal([]byte(arguments), &input); err != nil {
+		c.logger.Warn().Err(err).Str("tool", tool).Msg("Tool call arguments are not a JSON object")
+	}
+	return input
+}
+
+// stopReason maps a chat completions finish reason to the Anthropic stop reason
+func stopReason(finishReason string) string {
+	switch finishReason {
+	case "stop", "":
+		return "end_turn"
+	case "length":
+		return "max_tokens"
+	case "tool_calls", "function_call":
+		return "tool_use"
+	case "content_filter":
+		return "refusal"
+	default:
+		return finishReason
+	}
+}
+
+// streamConverter turns chat completion chunks into Anthropic-style stream events
+type streamConverter struct {
+	model         vo.Model
+	started       bool
+	nextIndex     int
+	thinkingIndex int         // block index of the open thinking block, -1 when none
+	textIndex     int         // block index of the open text block, -1 when none
+	toolIndexes   map[int]int // chunk tool call index to block index
+	openBlocks    []int       // started blocks, in order
+	finishReason  string
+	usage         *chatUsage
+}
+
+func newStreamConverter(model vo.Model) *streamConverter {
+	return &streamConverter{model: model, thinkingIndex: -1, textIndex: -1, toolIndexes: make(map[int]int)}
+}
+
+// run reads server-sent events from body until [DONE], the end of the body or a send failure
+func (s *streamConverter) run(body io.Reader, send func(*services.ClaudeStreamEvent) bool) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+		if data == "" {
+			continue
+		}
+
+		var chunk chatChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("%w: decoding stream chunk: %v", ErrAPIError, err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("%w: %s", ErrAPIError, chunk.Error.Message)
+		}
+		for _, event := range s.convert(&chunk) {
+			if !send(event) {
+				return context.Canceled
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: reading stream: %v", ErrAPIError, err)
+	}
+
+	for _, event := range s.finish() {
+		if !send(event) {
+			return context.Canceled
+		}
+	}
+	return nil
+}
+
+// convert returns the events for one chunk
+func (s *streamConverter) convert(chunk *chatChunk) []*services.ClaudeStreamEvent {
+	var events []*services.ClaudeStreamEvent
+
+	if !s.started {
+		s.started = true
+		model := chunk.Model
+		if model == "" {
+			model = s.model.String()
+		}
+		events = append(events, &services.ClaudeStreamEvent{
+			Type:    "message_start",
+			Message: &services.ClaudeResponse{ID: chunk.ID, Type: "message", Model: model, Role: vo.RoleAssistant},
+		})
+	}
+	if chunk.Usage != nil {
+		s.usage = chunk.Usage
+	}
+
+	for _, choice := range chunk.Choices {
+		if reasoning := choice.Delta.reasoning(); reasoning != "" {
+			if s.thinkingIndex < 0 {
+				s.thinkingIndex = s.startBlock()
+				events = append(events, &services.ClaudeStreamEvent{
+					Type:         "content_block_start",
+					Index:        s.thinkingIndex,
+					ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeThinking},
+				})
+			}
+			events = append(events, &services.ClaudeStreamEvent{
+				Type:  "content_block_delta",
+				Index: s.thinkingIndex,
+				Delta: &services.ClaudeDelta{Type: "thinking_delta", Thinking: reasoning},
+			})
+		}
+
+		if choice.Delta.Content != "" {
+			if s.textIndex < 0 {
+				s.textIndex = s.startBlock()
+				events = append(events, &services.ClaudeStreamEvent{
+					Type:         "content_block_start",
+					Index:        s.textIndex,
+					ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeText},
+				})
+			}
+			events = append(events, &services.ClaudeStreamEvent{
+				Type:  "content_block_delta",
+				Index: s.textIndex,
+				Delta: &services.ClaudeDelta{Type: "text_delta", Text: choice.Delta.Content},
+			})
+		}
+
+		for i, call := range choice.Delta.ToolCalls {
+			callIndex := i
+			if call.Index != nil {
+				callIndex = *call.Index
+			}
+			index, ok := s.toolIndexes[callIndex]
+			if !ok {
+				index = s.startBlock()
+				s.toolIndexes[callIndex] = index
+				events = append(events, &services.ClaudeStreamEvent{
+					Type:         "content_block_start",
+					Index:        index,
+					ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeToolUse, ID: call.ID, Name: call.Function.Name},
+				})
+			}
+			if call.Function.Arguments != "" {
+				events = append(events, &services.ClaudeStreamEvent{
+					Type:  "content_block_delta",
+					Index: index,
+					Delta: &services.ClaudeDelta{Type: "input_json_delta", PartialJSON: call.Function.Arguments},
+				})
+			}
+		}
+
+		if choice.FinishReason != nil && *choice.FinishReason != "" {
+			s.finishReason = *choice.FinishReason
+		}
+	}
+
+	return events
+}
+
+// finish closes the open blocks and ends the message
+func (s *streamConverter) finish() []*services.ClaudeStreamEvent {
+	events := make([]*services.ClaudeStreamEvent, 0, len(s.openBlocks)+2)
+	for _, index := range s.openBlocks {
+		events = append(events, &services.ClaudeStreamEvent{Type: "content_block_stop", Index: index})
+	}
+
+	return append(events,
+		&services.ClaudeStreamEvent{
+			Type:  "message_delta",
+			Delta: &services.ClaudeDelta{StopReason: stopReason(s.finishReason)},
+			Usage: convertUsage(s.usage),
+		},
+		&services.ClaudeStreamEvent{Type: "message_stop"},
+	)
+}
+
+func (s *streamConverter) startBlock() int {
+	index := s.nextIndex
+	s.nextIndex++
+	s.openBlocks = append(s.openBlocks, index)
+	return index
+}
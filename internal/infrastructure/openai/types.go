@@ -0,0 +1,220 @@
+// Package openai provides an LLM backend for OpenAI-compatible chat completions APIs.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openai
+
+import (
+	"encoding/json"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+)
+
+// chatRequest is the body of POST /chat/completions
+type chatRequest struct {
+	Model               string         `json:"model"`
+	Messages            []chatMessage  `json:"messages"`
+	MaxTokens           int            `json:"max_tokens,omitempty"`
+	MaxCompletionTokens int            `json:"max_completion_tokens,omitempty"`
+	Temperature         *float64       `json:"temperature,omitempty"`
+	TopP                *float64       `json:"top_p,omitempty"`
+	Stop                []string       `json:"stop,omitempty"`
+	Tools               []chatTool     `json:"tools,omitempty"`
+	Stream              bool           `json:"stream,omitempty"`
+	StreamOptions       *streamOptions `json:"stream_options,omitempty"`
+	PromptCacheKey      string         `json:"prompt_cache_key,omitempty"`
+
+	// Reasoning, in the dialect of the provider
+	ReasoningEffort string          `json:"reasoning_effort,omitempty"` // OpenAI, xAI
+	EnableThinking  *bool           `json:"enable_thinking,omitempty"`  // Qwen
+	ThinkingBudget  int             `json:"thinking_budget,omitempty"`  // Qwen
+	Thinking        *thinkingOption `json:"thinking,omitempty"`         // DeepSeek, Kimi, GLM, MiMo
+}
+
+// thinkingOption switches the thinking mode of hybrid reasoning models
+type thinkingOption struct {
+	Type string `json:"type"` // "enabled" or "disabled"
+}
+
+// streamOptions asks for a final usage chunk when streaming
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// chatMessage is one message of the conversation
+type chatMessage struct {
+	Role       string     `json:"role"`
+	Content    *string    `json:"content"`
+	ToolCalls  []toolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+
+	// ReasoningContent is the reasoning of thinking models; some local servers name it reasoning
+	ReasoningContent string `json:"reasoning_content,omitempty"`
+	Reasoning        string `json:"reasoning,omitempty"`
+
+	// Parts replace Content when the message carries images or files
+	Parts []contentPart `json:"-"`
+}
+
+// MarshalJSON sends Parts as the content of messages that carry images or files
+func (m chatMessage) MarshalJSON() ([]byte, error) {
+	type message chatMessage
+	if len(m.Parts) == 0 {
+		return json.Marshal(message(m))
+	}
+	return json.Marshal(struct {
+		message
+		Content []contentPart `json:"content"`
+	}{message(m), m.Parts})
+}
+
+// contentPart is a part of a user message with images or files
+type contentPart struct {
+	Type     string    `json:"type"` // "text", "image_url" or "file"
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+	File     *filePart `json:"file,omitempty"`
+}
+
+// imageURL is an image given by URL or as a data URL
+type imageURL struct {
+	URL string `json:"url"`
+}
+
+// filePart is a file, such as a PDF, given as a data URL
+type filePart struct {
+	Filename string `json:"filename,omitempty"`
+	FileData string `json:"file_data"`
+}
+
+// reasoning returns the reasoning of the message under either name
+func (m *chatMessage) reasoning() string {
+	if m.ReasoningContent != "" {
+		return m.ReasoningContent
+	}
+	return m.Reasoning
+}
+
+// chatTool declares a function the model may call
+type chatTool struct {
+	Type     string       `json:"type"`
+	Function chatFunction `json:"function"`
+}
+
+// chatFunction describes a callable function
+type chatFunction struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description,omitempty"`
+	Parameters  *entities.JSONSchema `json:"parameters"`
+}
+
+// toolCall is a function call made by the model; Index is only set in stream chunks
+type toolCall struct {
+	Index    *int         `json:"index,omitempty"`
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function functionCall `json:"function"`
+}
+
+// functionCall names the called function and its JSON-encoded arguments
+type functionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
+}
+
+// chatResponse is a non-streaming chat completion
+type chatResponse struct {
+	ID      string       `json:"id"`
+	Model   string       `json:"model"`
+	Choices []chatChoice `json:"choices"`
+	Usage   *chatUsage   `json:"usage"`
+}
+
+// chatChoice is one completion choice
+type chatChoice struct {
+	Index        int         `json:"index"`
+	Message      chatMessage `json:"message"`
+	FinishReason string      `json:"finish_reason"`
+}
+
+// chatUsage reports token usage; prompt tokens include those served from the prompt cache
+type chatUsage struct {
+	PromptTokens        int                 `json:"prompt_tokens"`
+	CompletionTokens    int                 `json:"completion_tokens"`
+	PromptTokensDetails *promptTokenDetails `json:"prompt_tokens_details,omitempty"`
+
+	CompletionTokensDetails *completionTokenDetails `json:"completion_tokens_details,omitempty"`
+
+	// PromptCacheHitTokens is DeepSeek's count of cached prompt tokens
+	PromptCacheHitTokens int `json:"prompt_cache_hit_tokens,omitempty"`
+}
+
+// promptTokenDetails breaks down prompt tokens
+type promptTokenDetails struct {
+	CachedTokens int `json:"cached_tokens"`
+}
+
+// completionTokenDetails breaks down completion tokens
+type completionTokenDetails struct {
+	ReasoningTokens int `json:"reasoning_tokens"`
+}
+
+// chatChunk is one server-sent event of a streaming completion
+type chatChunk struct {
+	ID      string        `json:"id"`
+	Model   string        `json:"model"`
+	Choices []chunkChoice `json:"choices"`
+	Usage   *chatUsage    `json:"usage"`
+	Error   *chunkError   `json:"error"`
+}
+
+// chunkChoice carries the delta of one choice
+type chunkChoice struct {
+	Index        int        `json:"index"`
+	Delta        chunkDelta `json:"delta"`
+	FinishReason *string    `json:"finish_reason"`
+}
+
+// chunkDelta is the incremental content of a choice
+type chunkDelta struct {
+	Role      string     `json:"role,omitempty"`
+	Content   string     `json:"content,omitempty"`
+	ToolCalls []toolCall `json:"tool_calls,omitempty"`
+
+	ReasoningContent string `json:"reasoning_content,omitempty"`
+	Reasoning        string `json:"reasoning,omitempty"`
+}
+
+// reasoning returns the reasoning delta under either name
+func (d *chunkDelta) reasoning() string {
+	if d.ReasoningContent != "" {
+		return d.ReasoningContent
+	}
+	return d.Reasoning
+}
+
+// chunkError is an error reported inside the stream
+type chunkError struct {
+	Message string `json:"message"`
+}
+
+// modelList is the models endpoint result
+type modelList struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
@@ -21,7 +21,10 @@ package persistence
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
 )
 
 // AnalyticsRepository handles analytics queries on ClickHouse
@@ -36,13 +39,16 @@ func NewAnalyticsRepository(ch *ClickHouse) *AnalyticsRepository {
 
 // TokenUsageStats represents token usage statistics
 type TokenUsageStats struct {
-	Model         string
-	InputTokens   uint64
-	OutputTokens  uint64
-	TotalTokens   uint64
-	RequestCount  uint64
-	AvgInputSize  float64
-	AvgOutputSize float64
+	Model            string
+	InputTokens      uint64
+	OutputTokens     uint64
+	CacheReadTokens  uint64
+	CacheWriteTokens uint64
+	TotalTokens      uint64
+	RequestCount     uint64
+	AvgInputSize     float64
+	AvgOutputSize    float64
+	CostUSD          float64
 }
 
 // ToolUsageStats represents tool usage statistics
@@ -80,10 +86,13 @@ func (r *AnalyticsRepository) GetTokenUsageByModel(ctx context.Context, since, u
 			model,
 			sum(input_tokens) as input_tokens,
 			sum(output_tokens) as output_tokens,
+			sum(cache_read_tokens) as cache_read_tokens,
+			sum(cache_write_tokens) as cache_write_tokens,
 			sum(total_tokens) as total_tokens,
 			count() as request_count,
 			avg(input_tokens) as avg_input_size,
-			avg(output_tokens) as avg_output_size
+			avg(output_tokens) as avg_output_size,
+			sum(cost_usd) as cost_usd
 		FROM api_request_analytics
 		WHERE timestamp >= ? AND timestamp <= ?
 		GROUP BY model
@@ -103,10 +112,13 @@ func (r *AnalyticsRepository) GetTokenUsageByModel(ctx context.Context, since, u
 			&s.Model,
 			&s.InputTokens,
 			&s.OutputTokens,
+			&s.CacheReadTokens,
+			&s.CacheWriteTokens,
 			&s.TotalTokens,
 			&s.RequestCount,
 			&s.AvgInputSize,
 			&s.AvgOutputSize,
+			&s.CostUSD,
 		); err != nil {
 			return nil, err
 		}
@@ -368,6 +380,7 @@ func (r *AnalyticsRepository) GetTopTools(ctx context.Context, since, until time
 type DashboardSummary struct {
 	TotalRequests     uint64
 	TotalTokens       uint64
+	TotalCostUSD      float64
 	TotalToolCalls    uint64
 	TotalSessions     uint64
 	AvgLatencyMs      float64
@@ -382,6 +395,7 @@ func (r *AnalyticsRepository) GetDashboardSummary(ctx context.Context, since, un
 		SELECT
 			count() as total_requests,
 			sum(total_tokens) as total_tokens,
+			sum(cost_usd) as total_cost_usd,
 			avg(duration_ms) as avg_latency_ms,
 			countIf(is_error = 1) * 100.0 / count() as error_rate
 		FROM api_request_analytics
@@ -398,6 +412,7 @@ func (r *AnalyticsRepository) GetDashboardSummary(ctx context.Context, since, un
 		if err := rows.Scan(
 			&summary.TotalRequests,
 			&summary.TotalTokens,
+			&summary.TotalCostUSD,
 			&summary.AvgLatencyMs,
 			&summary.ErrorRate,
 		); err != nil {
@@ -455,3 +470,102 @@ func (r *AnalyticsRepository) GetDashboardSummary(ctx context.Context, since, un
 
 	return &summary, nil
 }
+
+// llmUsageGroupColumns maps LLM usage groups to api_request_analytics expressions
+var llmUsageGroupColumns = map[repositories.LLMUsageGroup]string{
+	"":                                  "''",
+	repositories.LLMUsageByModel:        "model",
+	repositories.LLMUsageByProvider:     "provider",
+	repositories.LLMUsageBySession:      "toString(session_id)",
+	repositories.LLMUsageByConversation: "toString(conversation_id)",
+	repositories.LLMUsageByOrganization: "organization_id",
+	repositories.LLMUsageByDay:          "toString(toDate(timestamp))",
+}
+
+// GetLLMUsage returns token usage and cost of the LLM calls matching the filter, most expensive group first
+func (r *AnalyticsRepository) GetLLMUsage(ctx context.Context, filter repositories.LLMUsageFilter) ([]*repositories.LLMUsageSummary, error) {
+	group, ok := llmUsageGroupColumns[filter.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown LLM usage group %q", filter.GroupBy)
+	}
+
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, arg interface{}) {
+		conditions = append(conditions, condition)
+		args = append(args, arg)
+	}
+	if !filter.Since.IsZero() {
+		where("timestamp >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		where("timestamp < ?", filter.Until)
+	}
+	if filter.SessionID != "" {
+		where("toString(session_id) = ?", filter.SessionID)
+	}
+	if filter.ConversationID != "" {
+		where("toString(conversation_id) = ?", filter.ConversationID)
+	}
+	if filter.OrganizationID != "" {
+		where("organization_id = ?", filter.OrganizationID)
+	}
+	if filter.Model != "" {
+		where("model = ?", filter.Model.String())
+	}
+	if filter.Provider != "" {
+		where("provider = ?", string(filter.Provider))
+	}
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			%s as grp,
+			count() as requests,
+			countIf(is_error = 1) as errors,
+			sum(input_tokens) as input_tokens,
+			sum(output_tokens) as output_tokens,
+			sum(cache_read_tokens) as cache_read_tokens,
+			sum(cache_write_tokens) as cache_write_tokens,
+			sum(reasoning_tokens) as reasoning_tokens,
+			sum(cost_usd) as cost_usd,
+			countIf(pricing_version = '') as unpriced,
+			avg(duration_ms) as avg_duration_ms
+		FROM api_request_analytics
+		%s
+		GROUP BY grp
+		ORDER BY cost_usd DESC, grp
+	`, group, whereClause)
+
+	rows, err := r.ch.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query LLM usage: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var summaries []*repositories.LLMUsageSummary
+	for rows.Next() {
+		var (
+			s                                    repositories.LLMUsageSummary
+			requests, failed, unpriced           uint64
+			input, output, cacheRead, cacheWrite uint64
+			reasoning                            uint64
+			avgDurationMs                        float64
+		)
+		if err := rows.Scan(&s.Group, &requests, &failed, &input, &output, &cacheRead, &cacheWrite, &reasoning,
+			&s.CostUSD, &unpriced, &avgDurationMs); err != nil {
+			return nil, err
+		}
+		s.Requests, s.Errors, s.UnpricedRequests = int64(requests), int64(failed), int64(unpriced)
+		s.InputTokens, s.OutputTokens = int64(input), int64(output)
+		s.CacheReadTokens, s.CacheWriteTokens = int64(cacheRead), int64(cacheWrite)
+		s.ReasoningTokens = int64(reasoning)
+		s.AvgLatency = time.Duration(avgDurationMs * float64(time.Millisecond))
+		summaries = append(summaries, &s)
+	}
+
+	return summaries, rows.Err()
+}
@@ -21,6 +21,7 @@ package persistence
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
@@ -145,13 +146,19 @@ func (c *ClickHouse) CreateTables(ctx context.Context) error {
 			tool_name LowCardinality(String),
 			duration_ms UInt64,
 			is_error UInt8,
+			error_message String DEFAULT '',
 			input_size UInt32,
-			output_size UInt32
+			output_size UInt32,
+			metadata String DEFAULT '{}'
 		) ENGINE = MergeTree()
 		PARTITION BY toYYYYMM(timestamp)
 		ORDER BY (timestamp, tool_name, session_id)
 		TTL timestamp + INTERVAL 90 DAY`,
 
+		// Columns added for the tool call audit trail; keep the order of the migration schema
+		`ALTER TABLE tool_call_analytics ADD COLUMN IF NOT EXISTS error_message String DEFAULT '' AFTER is_error`,
+		`ALTER TABLE tool_call_analytics ADD COLUMN IF NOT EXISTS metadata String DEFAULT '{}' AFTER output_size`,
+
 		// API request analytics table
 		`CREATE TABLE IF NOT EXISTS api_request_analytics (
 			timestamp DateTime64(3) CODEC(Delta, ZSTD(1)),
@@ -169,6 +176,22 @@ func (c *ClickHouse) CreateTables(ctx context.Context) error {
 		ORDER BY (timestamp, model, session_id)
 		TTL timestamp + INTERVAL 90 DAY`,
 
+		// Columns added for LLM usage and cost accounting; inserts name their columns, so order does not matter
+		`ALTER TABLE api_request_analytics
+			ADD COLUMN IF NOT EXISTS request_id UUID,
+			ADD COLUMN IF NOT EXISTS organization_id String DEFAULT '',
+			ADD COLUMN IF NOT EXISTS provider LowCardinality(String) DEFAULT '',
+			ADD COLUMN IF NOT EXISTS requested_model LowCardinality(String) DEFAULT '',
+			ADD COLUMN IF NOT EXISTS cache_read_tokens UInt32 DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS cache_write_tokens UInt32 DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS status LowCardinality(String) DEFAULT '',
+			ADD COLUMN IF NOT EXISTS error_message String DEFAULT '',
+			ADD COLUMN IF NOT EXISTS is_streaming UInt8 DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS stop_reason LowCardinality(String) DEFAULT '',
+			ADD COLUMN IF NOT EXISTS cost_usd Float64 DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS pricing_version LowCardinality(String) DEFAULT '',
+			ADD COLUMN IF NOT EXISTS reasoning_tokens UInt32 DEFAULT 0`,
+
 		// Session analytics table
 		`CREATE TABLE IF NOT EXISTS session_analytics (
 			timestamp DateTime64(3) CODEC(Delta, ZSTD(1)),
@@ -228,22 +251,71 @@ type ToolCallEvent struct {
 	ToolName       string
 	DurationMs     uint64
 	IsError        bool
+	ErrorMessage   string
 	InputSize      uint32
 	OutputSize     uint32
+	Metadata       string // JSON object; empty is stored as {}
+}
+
+// metadata returns the metadata column value
+func (e *ToolCallEvent) metadata() string {
+	if e.Metadata == "" {
+		return "{}"
+	}
+	return e.Metadata
 }
 
 // APIRequestEvent represents an API request analytics event
 type APIRequestEvent struct {
-	Timestamp      time.Time
-	SessionID      string
-	ConversationID string
-	Model          string
-	InputTokens    uint32
-	OutputTokens   uint32
-	TotalTokens    uint32
-	DurationMs     uint64
-	StatusCode     uint16
-	IsError        bool
+	Timestamp        time.Time
+	RequestID        string
+	SessionID        string
+	ConversationID   string
+	OrganizationID   string
+	Provider         string
+	RequestedModel   string
+	Model            string
+	InputTokens      uint32
+	OutputTokens     uint32
+	CacheReadTokens  uint32
+	CacheWriteTokens uint32
+	ReasoningTokens  uint32
+	TotalTokens      uint32
+	DurationMs       uint64
+	StatusCode       uint16
+	IsError          bool
+	Status           string
+	ErrorMessage     string
+	IsStreaming      bool
+	StopReason       string
+	CostUSD          float64
+	PricingVersion   string
+}
+
+// apiRequestColumns are the api_request_analytics columns written, in the order of values()
+const apiRequestColumns = `timestamp, request_id, session_id, conversation_id, organization_id, provider, requested_model, model,
+	input_tokens, output_tokens, cache_read_tokens, cache_write_tokens, total_tokens, duration_ms, status_code, is_error,
+	status, error_message, is_streaming, stop_reason, cost_usd, pricing_version, reasoning_tokens`
+
+// values returns the column values of the event
+func (e *APIRequestEvent) values() []interface{} {
+	return []interface{}{
+		e.Timestamp, e.RequestID, e.SessionID, e.ConversationID, e.OrganizationID, e.Provider, e.RequestedModel, e.Model,
+		e.InputTokens, e.OutputTokens, e.CacheReadTokens, e.CacheWriteTokens, e.TotalTokens, e.DurationMs, e.StatusCode, boolToUInt8(e.IsError),
+		e.Status, e.ErrorMessage, boolToUInt8(e.IsStreaming), e.StopReason, e.CostUSD, e.PricingVersion, e.ReasoningTokens,
+	}
+}
+
+// batchColumns names the columns of tables whose batches do not fill every column in table order
+var batchColumns = map[string]string{
+	"api_request_analytics": apiRequestColumns,
+}
+
+func boolToUInt8(b bool) uint8 {
+	if b {
+		return 1
+	}
+	return 0
 }
 
 // SessionEvent represents a session analytics event
@@ -268,41 +340,26 @@ func (c *ClickHouse) InsertToolCallEvent(ctx context.Context, event *ToolCallEve
 
 	return c.conn.Exec(ctx, `
 		INSERT INTO tool_call_analytics
-		(timestamp, session_id, conversation_id, tool_name, duration_ms, is_error, input_size, output_size)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		(timestamp, session_id, conversation_id, tool_name, duration_ms, is_error, error_message, input_size, output_size, metadata)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		event.Timestamp,
 		event.SessionID,
 		event.ConversationID,
 		event.ToolName,
 		event.DurationMs,
 		isError,
+		event.ErrorMessage,
 		event.InputSize,
 		event.OutputSize,
+		event.metadata(),
 	)
 }
 
 // InsertAPIRequestEvent inserts an API request analytics event
 func (c *ClickHouse) InsertAPIRequestEvent(ctx context.Context, event *APIRequestEvent) error {
-	isError := uint8(0)
-	if event.IsError {
-		isError = 1
-	}
-
-	return c.conn.Exec(ctx, `
-		INSERT INTO api_request_analytics
-		(timestamp, session_id, conversation_id, model, input_tokens, output_tokens, total_tokens, duration_ms, status_code, is_error)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		event.Timestamp,
-		event.SessionID,
-		event.ConversationID,
-		event.Model,
-		event.InputTokens,
-		event.OutputTokens,
-		event.TotalTokens,
-		event.DurationMs,
-		event.StatusCode,
-		isError,
-	)
+	values := event.values()
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+	return c.conn.Exec(ctx, fmt.Sprintf("INSERT INTO api_request_analytics (%s) VALUES (%s)", apiRequestColumns, placeholders), values...)
 }
 
 // InsertSessionEvent inserts a session analytics event
@@ -356,7 +413,11 @@ func (b *BatchInsert) Flush(ctx context.Context) error {
 		return nil
 	}
 
-	batch, err := b.ch.conn.PrepareBatch(ctx, fmt.Sprintf("INSERT INTO %s", b.tableName))
+	query := fmt.Sprintf("INSERT INTO %s", b.tableName)
+	if columns, ok := batchColumns[b.tableName]; ok {
+		query += fmt.Sprintf(" (%s)", columns)
+	}
+	batch, err := b.ch.conn.PrepareBatch(ctx, query)
 	if err != nil {
 		return fmt.Errorf("failed to prepare batch: %w", err)
 	}
@@ -375,28 +436,15 @@ func (b *BatchInsert) Flush(ctx context.Context) error {
 				e.ToolName,
 				e.DurationMs,
 				isError,
+				e.ErrorMessage,
 				e.InputSize,
 				e.OutputSize,
+				e.metadata(),
 			); err != nil {
 				return err
 			}
 		case *APIRequestEvent:
-			isError := uint8(0)
-			if e.IsError {
-				isError = 1
-			}
-			if err := batch.Append(
-				e.Timestamp,
-				e.SessionID,
-				e.ConversationID,
-				e.Model,
-				e.InputTokens,
-				e.OutputTokens,
-				e.TotalTokens,
-				e.DurationMs,
-				e.StatusCode,
-				isError,
-			); err != nil {
+			if err := batch.Append(e.values()...); err != nil {
 				return err
 			}
 		}
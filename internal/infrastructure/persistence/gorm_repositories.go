@@ -22,9 +22,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
@@ -124,14 +126,46 @@ func NewGormConversationRepository(db *gorm.DB) *GormConversationRepository {
 	return &GormConversationRepository{db: db}
 }
 
+// Save stores the conversation and adds its new messages; stored messages are never rewritten
 func (r *GormConversationRepository) Save(ctx context.Context, conv *aggregates.Conversation) error {
 	model := conversationToModel(conv)
-	return r.db.WithContext(ctx).Save(model).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(model).Error; err != nil {
+			return err
+		}
+
+		// Compaction rewrites and removes stored messages
+		ids := make([]string, len(model.Messages))
+		for i, message := range model.Messages {
+			ids[i] = message.ID
+		}
+		stale := tx.Where("conversation_id = ?", model.ID)
+		if len(ids) > 0 {
+			stale = stale.Where("id NOT IN ?", ids)
+		}
+		if err := stale.Delete(&MessageModel{}).Error; err != nil {
+			return err
+		}
+		if len(model.Messages) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"content"}),
+		}).Create(&model.Messages).Error
+	})
+}
+
+// withMessages loads conversations with their messages in order
+func (r *GormConversationRepository) withMessages(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Preload("Messages", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at")
+	})
 }
 
 func (r *GormConversationRepository) FindByID(ctx context.Context, id vo.ConversationID) (*aggregates.Conversation, error) {
 	var model ConversationModel
-	if err := r.db.WithContext(ctx).Preload("Messages").Where("id = ?", id.String()).First(&model).Error; err != nil {
+	if err := r.withMessages(ctx).Where("id = ?", id.String()).First(&model).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
@@ -142,7 +176,7 @@ func (r *GormConversationRepository) FindByID(ctx context.Context, id vo.Convers
 
 func (r *GormConversationRepository) FindBySessionID(ctx context.Context, sessionID vo.SessionID) ([]*aggregates.Conversation, error) {
 	var models []ConversationModel
-	if err := r.db.WithContext(ctx).Where("session_id = ?", sessionID.String()).Find(&models).Error; err != nil {
+	if err := r.withMessages(ctx).Where("session_id = ?", sessionID.String()).Find(&models).Error; err != nil {
 		return nil, err
 	}
 	conversations := make([]*aggregates.Conversation, 0, len(models))
@@ -158,7 +192,7 @@ func (r *GormConversationRepository) FindBySessionID(ctx context.Context, sessio
 
 func (r *GormConversationRepository) FindActive(ctx context.Context) ([]*aggregates.Conversation, error) {
 	var models []ConversationModel
-	if err := r.db.WithContext(ctx).Where("status = ?", "active").Find(&models).Error; err != nil {
+	if err := r.withMessages(ctx).Where("status = ?", "active").Find(&models).Error; err != nil {
 		return nil, err
 	}
 	conversations := make([]*aggregates.Conversation, 0, len(models))
@@ -362,14 +396,34 @@ func modelToSession(m *SessionModel) (*aggregates.Session, error) {
 }
 
 func conversationToModel(c *aggregates.Conversation) *ConversationModel {
+	settings := c.Settings()
 	m := &ConversationModel{
-		ID:        c.ID().String(),
-		SessionID: c.SessionID().String(),
-		Model:     string(c.Model()),
-		Status:    string(c.Status()),
-		CreatedAt: c.CreatedAt(),
-		UpdatedAt: c.UpdatedAt(),
-		ClosedAt:  c.ClosedAt(),
+		ID:           c.ID().String(),
+		SessionID:    c.SessionID().String(),
+		Model:        string(c.Model()),
+		SystemPrompt: settings.SystemPrompt.String(),
+		Status:       string(c.Status()),
+		MaxTokens:    settings.MaxTokens,
+		Temperature:  settings.Temperature,
+		TopP:         settings.TopP,
+		TopK:         settings.TopK,
+		CreatedAt:    c.CreatedAt(),
+		UpdatedAt:    c.UpdatedAt(),
+		ClosedAt:     c.ClosedAt(),
+	}
+	if len(settings.StopSequences) > 0 {
+		m.StopSequences = JSONB{"sequences": settings.StopSequences}
+	}
+	if settings.Reasoning.IsEnabled() {
+		b, _ := json.Marshal(settings.Reasoning)
+		_ = json.Unmarshal(b, &m.Reasoning)
+	}
+	if len(settings.Metadata) > 0 {
+		b, _ := json.Marshal(settings.Metadata)
+		_ = json.Unmarshal(b, &m.Metadata)
+	}
+	for _, msg := range c.Messages() {
+		m.Messages = append(m.Messages, messageToModel(m.ID, msg))
 	}
 	return m
 }
@@ -383,11 +437,77 @@ func modelToConversation(m *ConversationModel) (*aggregates.Conversation, error)
 	if err != nil {
 		return nil, fmt.Errorf("invalid session ID %q: %w", m.SessionID, err)
 	}
+	systemPrompt, err := vo.NewSystemPrompt(m.SystemPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid system prompt of conversation %q: %w", m.ID, err)
+	}
+
+	settings := aggregates.ConversationSettings{
+		SystemPrompt: systemPrompt,
+		MaxTokens:    m.MaxTokens,
+		Temperature:  m.Temperature,
+		TopP:         m.TopP,
+		TopK:         m.TopK,
+		Metadata:     m.Metadata,
+	}
+	if len(m.Reasoning) > 0 {
+		b, _ := json.Marshal(m.Reasoning)
+		var reasoning vo.Reasoning
+		if err := json.Unmarshal(b, &reasoning); err == nil && reasoning.IsEnabled() {
+			settings.Reasoning = &reasoning
+		}
+	}
+	if sequences, ok := m.StopSequences["sequences"].([]interface{}); ok {
+		for _, sequence := range sequences {
+			if s, ok := sequence.(string); ok {
+				settings.StopSequences = append(settings.StopSequences, s)
+			}
+		}
+	}
+
+	messages := make([]*entities.Message, 0, len(m.Messages))
+	for i := range m.Messages {
+		msg, err := modelToMessage(&m.Messages[i])
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	slices.SortStableFunc(messages, func(a, b *entities.Message) int {
+		return a.CreatedAt().Compare(b.CreatedAt())
+	})
 
 	c := aggregates.RestoreConversation(cid, sid, m.Model, m.Status, m.CreatedAt, m.UpdatedAt, m.ClosedAt)
+	c.RestoreState(settings, messages)
 	return c, nil
 }
 
+func messageToModel(conversationID string, msg *entities.Message) MessageModel {
+	var blocks []interface{}
+	b, _ := json.Marshal(msg.Content())
+	_ = json.Unmarshal(b, &blocks)
+	return MessageModel{
+		ID:             msg.ID().String(),
+		ConversationID: conversationID,
+		Role:           msg.Role().String(),
+		Content:        JSONB{"blocks": blocks},
+		CreatedAt:      msg.CreatedAt(),
+	}
+}
+
+func modelToMessage(m *MessageModel) (*entities.Message, error) {
+	id, err := vo.NewMessageID(m.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message ID %q: %w", m.ID, err)
+	}
+	var content []entities.ContentBlock
+	b, _ := json.Marshal(m.Content["blocks"])
+	if err := json.Unmarshal(b, &content); err != nil {
+		return nil, fmt.Errorf("invalid content of message %q: %w", m.ID, err)
+	}
+	return entities.RestoreMessage(id, vo.Role(m.Role), content, m.CreatedAt), nil
+}
+
 func toolToModel(t *entities.Tool) *ToolModel {
 	m := &ToolModel{
 		Name:        t.Name().String(),
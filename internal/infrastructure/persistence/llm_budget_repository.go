@@ -0,0 +1,193 @@
+// Package persistence provides LLM budget repository implementations
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+)
+
+// GormLLMBudgetRepository implements ILLMBudgetRepository on the llm_budgets table
+type GormLLMBudgetRepository struct {
+	db *gorm.DB
+}
+
+// NewGormLLMBudgetRepository creates a new GORM LLM budget repository
+func NewGormLLMBudgetRepository(db *gorm.DB) *GormLLMBudgetRepository {
+	return &GormLLMBudgetRepository{db: db}
+}
+
+// Save creates or replaces the budget of an organization for its period; a replaced budget keeps its ID
+func (r *GormLLMBudgetRepository) Save(ctx context.Context, budget *entities.LLMBudget) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "organization_id"}, {Name: "period"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"token_limit", "cost_limit_usd", "warn_at", "hard_limit", "webhook_url", "notify_emails", "enabled", "updated_at",
+		}),
+	}).Create(llmBudgetToModel(budget)).Error
+}
+
+// FindByOrganization retrieves the budgets of an organization
+func (r *GormLLMBudgetRepository) FindByOrganization(ctx context.Context, organizationID string) ([]*entities.LLMBudget, error) {
+	return r.find(r.db.WithContext(ctx).Where("organization_id = ?", organizationID))
+}
+
+// List retrieves all budgets ordered by organization and period
+func (r *GormLLMBudgetRepository) List(ctx context.Context) ([]*entities.LLMBudget, error) {
+	return r.find(r.db.WithContext(ctx))
+}
+
+// Delete removes a budget
+func (r *GormLLMBudgetRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&LLMBudgetModel{}, "id = ?", id).Error
+}
+
+func (r *GormLLMBudgetRepository) find(query *gorm.DB) ([]*entities.LLMBudget, error) {
+	var models []LLMBudgetModel
+	if err := query.Order("organization_id, period").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	budgets := make([]*entities.LLMBudget, 0, len(models))
+	for i := range models {
+		budgets = append(budgets, modelToLLMBudget(&models[i]))
+	}
+	return budgets, nil
+}
+
+var _ repositories.ILLMBudgetRepository = (*GormLLMBudgetRepository)(nil)
+
+func llmBudgetToModel(b *entities.LLMBudget) *LLMBudgetModel {
+	emails := make(JSONBArray, 0, len(b.NotifyEmails))
+	for _, email := range b.NotifyEmails {
+		emails = append(emails, email)
+	}
+	return &LLMBudgetModel{
+		ID:             b.ID,
+		OrganizationID: b.OrganizationID,
+		Period:         string(b.Period),
+		TokenLimit:     b.TokenLimit,
+		CostLimitUSD:   b.CostLimitUSD,
+		WarnAt:         b.WarnAt,
+		HardLimit:      b.HardLimit,
+		WebhookURL:     b.WebhookURL,
+		NotifyEmails:   emails,
+		Enabled:        b.Enabled,
+		CreatedAt:      b.CreatedAt,
+		UpdatedAt:      b.UpdatedAt,
+	}
+}
+
+func modelToLLMBudget(m *LLMBudgetModel) *entities.LLMBudget {
+	b := &entities.LLMBudget{
+		ID:             m.ID,
+		OrganizationID: m.OrganizationID,
+		Period:         entities.BudgetPeriod(m.Period),
+		TokenLimit:     m.TokenLimit,
+		CostLimitUSD:   m.CostLimitUSD,
+		WarnAt:         m.WarnAt,
+		HardLimit:      m.HardLimit,
+		WebhookURL:     m.WebhookURL,
+		Enabled:        m.Enabled,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+	for _, email := range m.NotifyEmails {
+		if s, ok := email.(string); ok {
+			b.NotifyEmails = append(b.NotifyEmails, s)
+		}
+	}
+	return b
+}
+
+// InMemoryLLMBudgetRepository implements ILLMBudgetRepository for deployments without PostgreSQL
+type InMemoryLLMBudgetRepository struct {
+	mu      sync.RWMutex
+	budgets map[string]*entities.LLMBudget // keyed by organization and period
+}
+
+// NewInMemoryLLMBudgetRepository creates a new in-memory LLM budget repository
+func NewInMemoryLLMBudgetRepository() *InMemoryLLMBudgetRepository {
+	return &InMemoryLLMBudgetRepository{budgets: make(map[string]*entities.LLMBudget)}
+}
+
+// Save creates or replaces the budget of an organization for its period; a replaced budget keeps its ID
+func (r *InMemoryLLMBudgetRepository) Save(ctx context.Context, budget *entities.LLMBudget) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *budget
+	stored.NotifyEmails = append([]string(nil), budget.NotifyEmails...)
+	key := budget.OrganizationID + "/" + string(budget.Period)
+	if existing, ok := r.budgets[key]; ok {
+		stored.ID = existing.ID
+		stored.CreatedAt = existing.CreatedAt
+	}
+	r.budgets[key] = &stored
+	return nil
+}
+
+// FindByOrganization retrieves the budgets of an organization
+func (r *InMemoryLLMBudgetRepository) FindByOrganization(ctx context.Context, organizationID string) ([]*entities.LLMBudget, error) {
+	return r.find(func(b *entities.LLMBudget) bool { return b.OrganizationID == organizationID }), nil
+}
+
+// List retrieves all budgets ordered by organization and period
+func (r *InMemoryLLMBudgetRepository) List(ctx context.Context) ([]*entities.LLMBudget, error) {
+	return r.find(func(*entities.LLMBudget) bool { return true }), nil
+}
+
+// Delete removes a budget
+func (r *InMemoryLLMBudgetRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, b := range r.budgets {
+		if b.ID == id {
+			delete(r.budgets, key)
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryLLMBudgetRepository) find(match func(*entities.LLMBudget) bool) []*entities.LLMBudget {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	budgets := make([]*entities.LLMBudget, 0)
+	for _, b := range r.budgets {
+		if match(b) {
+			copied := *b
+			copied.NotifyEmails = append([]string(nil), b.NotifyEmails...)
+			budgets = append(budgets, &copied)
+		}
+	}
+	sort.Slice(budgets, func(i, j int) bool {
+		if budgets[i].OrganizationID != budgets[j].OrganizationID {
+			return budgets[i].OrganizationID < budgets[j].OrganizationID
+		}
+		return budgets[i].Period < budgets[j].Period
+	})
+	return budgets
+}
+
+var _ repositories.ILLMBudgetRepository = (*InMemoryLLMBudgetRepository)(nil)
@@ -0,0 +1,221 @@
+// Package persistence provides LLM usage repository implementations
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+)
+
+// nilUUID fills UUID columns in ClickHouse when a value is missing
+const nilUUID = "00000000-0000-0000-0000-000000000000"
+
+// ClickHouseLLMUsageRepository implements ILLMUsageRepository on api_request_analytics.
+// Records are written in batches: when the batch is full and on Flush
+type ClickHouseLLMUsageRepository struct {
+	analytics *AnalyticsRepository
+
+	mu    sync.Mutex
+	batch *BatchInsert
+}
+
+// NewClickHouseLLMUsageRepository creates a repository that inserts batchSize records at a time
+func NewClickHouseLLMUsageRepository(ch *ClickHouse, batchSize int) *ClickHouseLLMUsageRepository {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	return &ClickHouseLLMUsageRepository{
+		analytics: NewAnalyticsRepository(ch),
+		batch:     ch.NewBatchInsert("api_request_analytics", batchSize),
+	}
+}
+
+// Save queues a usage record, sending the batch once it is full
+func (r *ClickHouseLLMUsageRepository) Save(ctx context.Context, usage *entities.LLMUsage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.batch.Add(NewAPIRequestEvent(usage))
+}
+
+// Flush sends the queued records
+func (r *ClickHouseLLMUsageRepository) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.batch.Flush(ctx)
+}
+
+// Summarize aggregates the records matching the filter; queued records are not counted until flushed
+func (r *ClickHouseLLMUsageRepository) Summarize(ctx context.Context, filter repositories.LLMUsageFilter) ([]*repositories.LLMUsageSummary, error) {
+	return r.analytics.GetLLMUsage(ctx, filter)
+}
+
+var _ repositories.ILLMUsageRepository = (*ClickHouseLLMUsageRepository)(nil)
+
+// NewAPIRequestEvent converts a usage record to a ClickHouse API request analytics event
+func NewAPIRequestEvent(usage *entities.LLMUsage) *APIRequestEvent {
+	return &APIRequestEvent{
+		Timestamp:        usage.CalledAt,
+		RequestID:        uuidOrNil(usage.ID),
+		SessionID:        uuidOrNil(usage.SessionID),
+		ConversationID:   uuidOrNil(usage.ConversationID),
+		OrganizationID:   usage.OrganizationID,
+		Provider:         string(usage.Provider),
+		RequestedModel:   usage.RequestedModel.String(),
+		Model:            usage.Model.String(),
+		InputTokens:      uint32(usage.InputTokens),
+		OutputTokens:     uint32(usage.OutputTokens),
+		CacheReadTokens:  uint32(usage.CacheReadTokens),
+		CacheWriteTokens: uint32(usage.CacheWriteTokens),
+		ReasoningTokens:  uint32(usage.ReasoningTokens),
+		TotalTokens:      uint32(usage.TotalTokens()),
+		DurationMs:       uint64(usage.Latency.Milliseconds()),
+		StatusCode:       uint16(usage.StatusCode),
+		IsError:          usage.IsError(),
+		Status:           string(usage.Status),
+		ErrorMessage:     usage.ErrorMessage,
+		IsStreaming:      usage.Streaming,
+		StopReason:       usage.StopReason,
+		CostUSD:          usage.CostUSD,
+		PricingVersion:   usage.PricingVersion,
+	}
+}
+
+// uuidOrNil returns id when it is a UUID, which ClickHouse UUID columns require, and the nil UUID otherwise
+func uuidOrNil(id string) string {
+	if _, err := uuid.Parse(id); err != nil {
+		return nilUUID
+	}
+	return id
+}
+
+// InMemoryLLMUsageRepository implements ILLMUsageRepository keeping the most recent records
+type InMemoryLLMUsageRepository struct {
+	mu         sync.RWMutex
+	records    []*entities.LLMUsage // oldest first
+	maxRecords int
+}
+
+// NewInMemoryLLMUsageRepository creates a repository that keeps at most maxRecords records
+func NewInMemoryLLMUsageRepository(maxRecords int) *InMemoryLLMUsageRepository {
+	if maxRecords < 1 {
+		maxRecords = 1
+	}
+	return &InMemoryLLMUsageRepository{maxRecords: maxRecords}
+}
+
+// Save stores a usage record, dropping the oldest when full
+func (r *InMemoryLLMUsageRepository) Save(ctx context.Context, usage *entities.LLMUsage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, usage)
+	if over := len(r.records) - r.maxRecords; over > 0 {
+		r.records = append([]*entities.LLMUsage(nil), r.records[over:]...)
+	}
+	return nil
+}
+
+// Summarize aggregates the records matching the filter, most expensive group first
+func (r *InMemoryLLMUsageRepository) Summarize(ctx context.Context, filter repositories.LLMUsageFilter) ([]*repositories.LLMUsageSummary, error) {
+	key, err := llmUsageGroupKey(filter.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	groups := make(map[string]*repositories.LLMUsageSummary)
+	latency := make(map[string]time.Duration)
+	for _, u := range r.records {
+		if filter.SessionID != "" && u.SessionID != filter.SessionID ||
+			filter.ConversationID != "" && u.ConversationID != filter.ConversationID ||
+			filter.OrganizationID != "" && u.OrganizationID != filter.OrganizationID ||
+			filter.Model != "" && u.Model != filter.Model ||
+			filter.Provider != "" && u.Provider != filter.Provider ||
+			!filter.Since.IsZero() && u.CalledAt.Before(filter.Since) ||
+			!filter.Until.IsZero() && !u.CalledAt.Before(filter.Until) {
+			continue
+		}
+
+		group := key(u)
+		s, ok := groups[group]
+		if !ok {
+			s = &repositories.LLMUsageSummary{Group: group}
+			groups[group] = s
+		}
+		s.Requests++
+		if u.IsError() {
+			s.Errors++
+		}
+		if u.PricingVersion == "" {
+			s.UnpricedRequests++
+		}
+		s.InputTokens += int64(u.InputTokens)
+		s.OutputTokens += int64(u.OutputTokens)
+		s.CacheReadTokens += int64(u.CacheReadTokens)
+		s.CacheWriteTokens += int64(u.CacheWriteTokens)
+		s.ReasoningTokens += int64(u.ReasoningTokens)
+		s.CostUSD += u.CostUSD
+		latency[group] += u.Latency
+	}
+
+	summaries := make([]*repositories.LLMUsageSummary, 0, len(groups))
+	for group, s := range groups {
+		s.AvgLatency = latency[group] / time.Duration(s.Requests)
+		summaries = append(summaries, s)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].CostUSD != summaries[j].CostUSD {
+			return summaries[i].CostUSD > summaries[j].CostUSD
+		}
+		return summaries[i].Group < summaries[j].Group
+	})
+	return summaries, nil
+}
+
+// llmUsageGroupKey returns the function giving the group of a record
+func llmUsageGroupKey(group repositories.LLMUsageGroup) (func(*entities.LLMUsage) string, error) {
+	switch group {
+	case "":
+		return func(*entities.LLMUsage) string { return "" }, nil
+	case repositories.LLMUsageByModel:
+		return func(u *entities.LLMUsage) string { return u.Model.String() }, nil
+	case repositories.LLMUsageByProvider:
+		return func(u *entities.LLMUsage) string { return string(u.Provider) }, nil
+	case repositories.LLMUsageBySession:
+		return func(u *entities.LLMUsage) string { return u.SessionID }, nil
+	case repositories.LLMUsageByConversation:
+		return func(u *entities.LLMUsage) string { return u.ConversationID }, nil
+	case repositories.LLMUsageByOrganization:
+		return func(u *entities.LLMUsage) string { return u.OrganizationID }, nil
+	case repositories.LLMUsageByDay:
+		return func(u *entities.LLMUsage) string { return u.CalledAt.UTC().Format(time.DateOnly) }, nil
+	}
+	return nil, fmt.Errorf("unknown LLM usage group %q", group)
+}
+
+var _ repositories.ILLMUsageRepository = (*InMemoryLLMUsageRepository)(nil)
@@ -62,6 +62,7 @@ type ConversationModel struct {
 	TopP          float64        `gorm:"not null;default:1.0"`
 	TopK          int            `gorm:"default:0"`
 	StopSequences JSONB          `gorm:"type:jsonb"`
+	Reasoning     JSONB          `gorm:"type:jsonb"`
 	Metadata      JSONB          `gorm:"type:jsonb"`
 	CreatedAt     time.Time      `gorm:"not null;index"`
 	UpdatedAt     time.Time      `gorm:"not null"`
@@ -214,6 +215,48 @@ func (AuditLogModel) TableName() string {
 	return "audit_logs"
 }
 
+// ToolExecutionModel represents an audited tool call in the database
+type ToolExecutionModel struct {
+	ID            string    `gorm:"type:uuid;primaryKey"`
+	SessionID     *string   `gorm:"type:uuid;index"`
+	ClientName    string    `gorm:"type:varchar(255)"`
+	ClientVersion string    `gorm:"type:varchar(50)"`
+	APIKeyID      string    `gorm:"type:varchar(255);index"`
+	ToolName      string    `gorm:"type:varchar(255);not null;index"`
+	Input         JSONB     `gorm:"type:jsonb;not null"`
+	Status        string    `gorm:"type:varchar(20);not null;index"`
+	IsError       bool      `gorm:"not null;default:false;index"`
+	ErrorMessage  string    `gorm:"type:text"`
+	DurationMs    int64     `gorm:"not null"`
+	ExecutedAt    time.Time `gorm:"not null;index"`
+}
+
+// TableName returns the table name for ToolExecutionModel
+func (ToolExecutionModel) TableName() string {
+	return "tool_executions"
+}
+
+// LLMBudgetModel represents an organization's LLM budget in the database
+type LLMBudgetModel struct {
+	ID             string     `gorm:"type:uuid;primaryKey"`
+	OrganizationID string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_llm_budgets_org_period"`
+	Period         string     `gorm:"type:varchar(20);not null;uniqueIndex:idx_llm_budgets_org_period"`
+	TokenLimit     int64      `gorm:"not null;default:0"`
+	CostLimitUSD   float64    `gorm:"column:cost_limit_usd;type:numeric(14,4);not null;default:0"`
+	WarnAt         float64    `gorm:"not null;default:0"`
+	HardLimit      bool       `gorm:"not null"`
+	WebhookURL     string     `gorm:"type:text"`
+	NotifyEmails   JSONBArray `gorm:"type:jsonb;not null;default:'[]'"`
+	Enabled        bool       `gorm:"not null;index"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for LLMBudgetModel
+func (LLMBudgetModel) TableName() string {
+	return "llm_budgets"
+}
+
 // JSONB is a custom type for PostgreSQL JSONB columns
 type JSONB map[string]interface{}
 
@@ -298,5 +341,7 @@ func AllModels() []interface{} {
 		&ToolCallModel{},
 		&APIRequestModel{},
 		&AuditLogModel{},
+		&ToolExecutionModel{},
+		&LLMBudgetModel{},
 	}
 }
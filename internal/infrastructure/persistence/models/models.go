@@ -177,6 +177,7 @@ type Conversation struct {
 	TopP          float64     `gorm:"type:decimal(3,2);not null;default:1.0" json:"topP"`
 	TopK          int         `gorm:"not null;default:0" json:"topK"`
 	StopSequences StringArray `gorm:"type:jsonb;not null;default:'[]'" json:"stopSequences"`
+	Reasoning     JSONB       `gorm:"type:jsonb" json:"reasoning,omitempty"`
 	Metadata      JSONB       `gorm:"type:jsonb;not null;default:'{}'" json:"metadata"`
 	CreatedAt     time.Time   `gorm:"autoCreateTime" json:"createdAt"`
 	UpdatedAt     time.Time   `gorm:"autoUpdateTime" json:"updatedAt"`
@@ -360,9 +361,13 @@ type ToolExecution struct {
 	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
 	SessionID      *uuid.UUID `gorm:"type:uuid;index" json:"sessionId,omitempty"`
 	ConversationID *uuid.UUID `gorm:"type:uuid" json:"conversationId,omitempty"`
+	ClientName     string     `gorm:"type:varchar(255)" json:"clientName,omitempty"`
+	ClientVersion  string     `gorm:"type:varchar(50)" json:"clientVersion,omitempty"`
+	APIKeyID       string     `gorm:"type:varchar(255);index" json:"apiKeyId,omitempty"`
 	ToolName       string     `gorm:"type:varchar(255);not null;index" json:"toolName"`
 	Input          JSONB      `gorm:"type:jsonb;not null;default:'{}'" json:"input"`
 	Output         JSONB      `gorm:"type:jsonb" json:"output,omitempty"`
+	Status         string     `gorm:"type:varchar(20);not null;default:'success';index" json:"status"`
 	IsError        bool       `gorm:"not null;default:false;index" json:"isError"`
 	ErrorMessage   string     `gorm:"type:text" json:"errorMessage,omitempty"`
 	DurationMs     int        `gorm:"" json:"durationMs,omitempty"`
@@ -382,6 +387,39 @@ func (te *ToolExecution) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// ============================================================================
+// LLMBudget Model
+// ============================================================================
+
+// LLMBudget represents an organization's LLM token and cost budget in the database
+type LLMBudget struct {
+	ID             uuid.UUID   `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID string      `gorm:"type:varchar(255);not null;uniqueIndex:idx_llm_budgets_org_period" json:"organizationId"`
+	Period         string      `gorm:"type:varchar(20);not null;uniqueIndex:idx_llm_budgets_org_period" json:"period"`
+	TokenLimit     int64       `gorm:"not null;default:0" json:"tokenLimit"`
+	CostLimitUSD   float64     `gorm:"column:cost_limit_usd;type:numeric(14,4);not null;default:0" json:"costLimitUsd"`
+	WarnAt         float64     `gorm:"not null;default:0" json:"warnAt"`
+	HardLimit      bool        `gorm:"not null" json:"hardLimit"`
+	WebhookURL     string      `gorm:"type:text" json:"webhookUrl,omitempty"`
+	NotifyEmails   StringArray `gorm:"type:jsonb;not null;default:'[]'" json:"notifyEmails"`
+	Enabled        bool        `gorm:"not null;index" json:"enabled"`
+	CreatedAt      time.Time   `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt      time.Time   `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// TableName returns the table name for LLMBudget
+func (LLMBudget) TableName() string {
+	return "llm_budgets"
+}
+
+// BeforeCreate generates a UUID if not set
+func (b *LLMBudget) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
+
 // ============================================================================
 // APIKey Model
 // ============================================================================
@@ -445,6 +483,7 @@ func AllModels() []interface{} {
 		&Prompt{},
 		&ResourceSubscription{},
 		&ToolExecution{},
+		&LLMBudget{},
 		&APIKey{},
 		&SchemaMigration{},
 	}
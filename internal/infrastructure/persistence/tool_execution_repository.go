@@ -0,0 +1,214 @@
+// Package persistence provides functionality for the TelemetryFlow GO MCP Server.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+)
+
+// defaultToolExecutionLimit caps searches that set no limit
+const defaultToolExecutionLimit = 100
+
+// GormToolExecutionRepository implements IToolExecutionRepository on the tool_executions table
+type GormToolExecutionRepository struct {
+	db *gorm.DB
+}
+
+// NewGormToolExecutionRepository creates a new GORM tool execution repository
+func NewGormToolExecutionRepository(db *gorm.DB) *GormToolExecutionRepository {
+	return &GormToolExecutionRepository{db: db}
+}
+
+// Save stores a tool execution record
+func (r *GormToolExecutionRepository) Save(ctx context.Context, execution *entities.ToolExecution) error {
+	return r.db.WithContext(ctx).Create(toolExecutionToModel(execution)).Error
+}
+
+// Search retrieves records matching the filter, newest first
+func (r *GormToolExecutionRepository) Search(ctx context.Context, filter repositories.ToolExecutionFilter) ([]*entities.ToolExecution, error) {
+	query := r.db.WithContext(ctx).Model(&ToolExecutionModel{})
+	if filter.SessionID != "" {
+		query = query.Where("session_id = ?", filter.SessionID)
+	}
+	if filter.ToolName != "" {
+		query = query.Where("tool_name = ?", filter.ToolName)
+	}
+	if filter.APIKeyID != "" {
+		query = query.Where("api_key_id = ?", filter.APIKeyID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", string(filter.Status))
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("executed_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("executed_at < ?", filter.Until)
+	}
+
+	var models []ToolExecutionModel
+	if err := query.Order("executed_at DESC").Limit(toolExecutionLimit(filter)).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	executions := make([]*entities.ToolExecution, 0, len(models))
+	for i := range models {
+		executions = append(executions, modelToToolExecution(&models[i]))
+	}
+	return executions, nil
+}
+
+// DeleteBefore removes records executed before the given time
+func (r *GormToolExecutionRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("executed_at < ?", before).Delete(&ToolExecutionModel{})
+	return result.RowsAffected, result.Error
+}
+
+var _ repositories.IToolExecutionRepository = (*GormToolExecutionRepository)(nil)
+
+func toolExecutionToModel(e *entities.ToolExecution) *ToolExecutionModel {
+	m := &ToolExecutionModel{
+		ID:            e.ID,
+		ClientName:    e.ClientName,
+		ClientVersion: e.ClientVersion,
+		APIKeyID:      e.APIKeyID,
+		ToolName:      e.ToolName,
+		Input:         JSONB(e.Arguments),
+		Status:        string(e.Status),
+		IsError:       e.IsError(),
+		ErrorMessage:  e.ErrorMessage,
+		DurationMs:    e.Duration.Milliseconds(),
+		ExecutedAt:    e.ExecutedAt,
+	}
+	if m.Input == nil {
+		m.Input = JSONB{}
+	}
+	if e.SessionID != "" {
+		sessionID := e.SessionID
+		m.SessionID = &sessionID
+	}
+	return m
+}
+
+func modelToToolExecution(m *ToolExecutionModel) *entities.ToolExecution {
+	e := &entities.ToolExecution{
+		ID:            m.ID,
+		ClientName:    m.ClientName,
+		ClientVersion: m.ClientVersion,
+		APIKeyID:      m.APIKeyID,
+		ToolName:      m.ToolName,
+		Arguments:     map[string]interface{}(m.Input),
+		Status:        entities.ToolExecutionStatus(m.Status),
+		ErrorMessage:  m.ErrorMessage,
+		Duration:      time.Duration(m.DurationMs) * time.Millisecond,
+		ExecutedAt:    m.ExecutedAt,
+	}
+	if m.SessionID != nil {
+		e.SessionID = *m.SessionID
+	}
+	return e
+}
+
+// toolExecutionLimit returns the filter limit or the default
+func toolExecutionLimit(filter repositories.ToolExecutionFilter) int {
+	if filter.Limit > 0 {
+		return filter.Limit
+	}
+	return defaultToolExecutionLimit
+}
+
+// InMemoryToolExecutionRepository implements IToolExecutionRepository keeping the most recent records
+type InMemoryToolExecutionRepository struct {
+	mu         sync.RWMutex
+	executions []*entities.ToolExecution // oldest first
+	maxRecords int
+}
+
+// NewInMemoryToolExecutionRepository creates a repository that keeps at most maxRecords records
+func NewInMemoryToolExecutionRepository(maxRecords int) *InMemoryToolExecutionRepository {
+	if maxRecords < 1 {
+		maxRecords = 1
+	}
+	return &InMemoryToolExecutionRepository{maxRecords: maxRecords}
+}
+
+// Save stores a tool execution record, dropping the oldest when full
+func (r *InMemoryToolExecutionRepository) Save(ctx context.Context, execution *entities.ToolExecution) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.executions = append(r.executions, execution)
+	if over := len(r.executions) - r.maxRecords; over > 0 {
+		r.executions = append([]*entities.ToolExecution(nil), r.executions[over:]...)
+	}
+	return nil
+}
+
+// Search retrieves records matching the filter, newest first
+func (r *InMemoryToolExecutionRepository) Search(ctx context.Context, filter repositories.ToolExecutionFilter) ([]*entities.ToolExecution, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	limit := toolExecutionLimit(filter)
+	matches := make([]*entities.ToolExecution, 0)
+	for _, e := range r.executions {
+		if filter.SessionID != "" && e.SessionID != filter.SessionID ||
+			filter.ToolName != "" && e.ToolName != filter.ToolName ||
+			filter.APIKeyID != "" && e.APIKeyID != filter.APIKeyID ||
+			filter.Status != "" && e.Status != filter.Status ||
+			!filter.Since.IsZero() && e.ExecutedAt.Before(filter.Since) ||
+			!filter.Until.IsZero() && !e.ExecutedAt.Before(filter.Until) {
+			continue
+		}
+		matches = append(matches, e)
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].ExecutedAt.After(matches[j].ExecutedAt)
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// DeleteBefore removes records executed before the given time
+func (r *InMemoryToolExecutionRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.executions[:0]
+	for _, e := range r.executions {
+		if !e.ExecutedAt.Before(before) {
+			kept = append(kept, e)
+		}
+	}
+	removed := int64(len(r.executions) - len(kept))
+	for i := len(kept); i < len(r.executions); i++ {
+		r.executions[i] = nil
+	}
+	r.executions = kept
+	return removed, nil
+}
+
+var _ repositories.IToolExecutionRepository = (*InMemoryToolExecutionRepository)(nil)
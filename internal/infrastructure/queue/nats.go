@@ -23,6 +23,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -219,14 +220,14 @@ func (q *NATSQueue) Initialize(ctx context.Context) error {
 		nats.ReconnectWait(q.config.ReconnectWait),
 		nats.DisconnectErrHandler(func(nc *nats.Conn, err error) {
 			if err != nil {
-				fmt.Printf("NATS disconnected: %v\n", err)
+				fmt.Fprintf(os.Stderr, "NATS disconnected: %v\n", err)
 			}
 		}),
 		nats.ReconnectHandler(func(nc *nats.Conn) {
-			fmt.Printf("NATS reconnected to %s\n", nc.ConnectedUrl())
+			fmt.Fprintf(os.Stderr, "NATS reconnected to %s\n", nc.ConnectedUrl())
 		}),
 		nats.ClosedHandler(func(nc *nats.Conn) {
-			fmt.Println("NATS connection closed")
+			fmt.Fprintln(os.Stderr, "NATS connection closed")
 		}),
 	}
 
@@ -376,7 +377,7 @@ func (q *NATSQueue) StartConsumer(ctx context.Context, streamName, consumerName,
 func (q *NATSQueue) consumeMessages(ctx context.Context, consumer jetstream.Consumer) {
 	iter, err := consumer.Messages()
 	if err != nil {
-		fmt.Printf("Failed to get message iterator: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Failed to get message iterator: %v\n", err)
 		return
 	}
 	defer iter.Stop()
@@ -391,7 +392,7 @@ func (q *NATSQueue) consumeMessages(ctx context.Context, consumer jetstream.Cons
 				if errors.Is(err, context.Canceled) {
 					return
 				}
-				fmt.Printf("Error getting message: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Error getting message: %v\n", err)
 				continue
 			}
 
@@ -404,7 +405,7 @@ func (q *NATSQueue) consumeMessages(ctx context.Context, consumer jetstream.Cons
 func (q *NATSQueue) processMessage(ctx context.Context, msg jetstream.Msg) {
 	var task Task
 	if err := json.Unmarshal(msg.Data(), &task); err != nil {
-		fmt.Printf("Failed to unmarshal task: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Failed to unmarshal task: %v\n", err)
 		_ = msg.Term() // Terminal failure, don't retry
 		return
 	}
@@ -414,7 +415,7 @@ func (q *NATSQueue) processMessage(ctx context.Context, msg jetstream.Msg) {
 	q.mu.RUnlock()
 
 	if !ok {
-		fmt.Printf("No handler for task type: %s\n", task.Type)
+		fmt.Fprintf(os.Stderr, "No handler for task type: %s\n", task.Type)
 		_ = msg.Term()
 		return
 	}
@@ -434,16 +435,16 @@ func (q *NATSQueue) processMessage(ctx context.Context, msg jetstream.Msg) {
 	if err != nil {
 		metadata, _ := msg.Metadata()
 		if metadata != nil && metadata.NumDelivered >= uint64(q.config.MaxDeliver) { //nolint:gosec // MaxDeliver is always positive
-			fmt.Printf("Task %s failed after max retries: %v\n", task.ID, err)
+			fmt.Fprintf(os.Stderr, "Task %s failed after max retries: %v\n", task.ID, err)
 			_ = msg.Term()
 		} else {
-			fmt.Printf("Task %s failed, will retry: %v\n", task.ID, err)
+			fmt.Fprintf(os.Stderr, "Task %s failed, will retry: %v\n", task.ID, err)
 			_ = msg.Nak()
 		}
 		return
 	}
 
-	fmt.Printf("Task %s completed in %v\n", task.ID, duration)
+	fmt.Fprintf(os.Stderr, "Task %s completed in %v\n", task.ID, duration)
 	_ = msg.Ack()
 }
 
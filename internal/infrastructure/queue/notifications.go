@@ -0,0 +1,193 @@
+// Package queue delivers webhook and email notification tasks.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notification errors
+var (
+	ErrWebhookFailed    = errors.New("webhook delivery failed")
+	ErrInvalidRecipient = errors.New("invalid email recipient")
+)
+
+// webhookRetryDelay is the delay before the first webhook retry; it doubles with each retry
+var webhookRetryDelay = time.Second
+
+// SMTPConfig configures the server that email notifications are sent through
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // empty sends without authentication
+	Password string
+	From     string
+}
+
+// RegisterNotificationHandlers registers the webhook delivery handler and, when smtp is set, the
+// email notification handler
+func RegisterNotificationHandlers(q TaskQueue, client *http.Client, smtpConfig *SMTPConfig) {
+	q.RegisterHandler(TaskTypeWebhookDelivery, NewWebhookDeliveryHandler(client))
+	if smtpConfig != nil {
+		q.RegisterHandler(TaskTypeEmailNotification, NewEmailNotificationHandler(*smtpConfig))
+	}
+}
+
+// NewWebhookDeliveryHandler returns a handler that sends the payload body as JSON, retrying failed
+// deliveries up to the payload's max_retries with exponential backoff
+func NewWebhookDeliveryHandler(client *http.Client) TaskHandler {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return func(ctx context.Context, task *Task) error {
+		var payload WebhookDeliveryPayload
+		if err := decodePayload(task, &payload); err != nil {
+			return err
+		}
+		if payload.URL == "" {
+			return ErrInvalidTask
+		}
+		body, err := json.Marshal(payload.Body)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrSerializeFailed, err)
+		}
+
+		delay := webhookRetryDelay
+		for {
+			err = deliverWebhook(ctx, client, &payload, body)
+			if err == nil || payload.RetryCount >= payload.MaxRetries {
+				return err
+			}
+			payload.RetryCount++
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%w: %v", err, ctx.Err())
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+	}
+}
+
+// deliverWebhook makes a single delivery attempt
+func deliverWebhook(ctx context.Context, client *http.Client, payload *WebhookDeliveryPayload, body []byte) error {
+	method := payload.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, payload.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range payload.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrWebhookFailed, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: %s returned %s", ErrWebhookFailed, payload.URL, resp.Status)
+	}
+	return nil
+}
+
+// NewEmailNotificationHandler returns a handler that sends the payload through an SMTP server
+func NewEmailNotificationHandler(cfg SMTPConfig) TaskHandler {
+	return func(ctx context.Context, task *Task) error {
+		var payload EmailNotificationPayload
+		if err := decodePayload(task, &payload); err != nil {
+			return err
+		}
+		message, recipients, err := buildEmail(cfg.From, &payload)
+		if err != nil {
+			return err
+		}
+
+		addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+		var auth smtp.Auth
+		if cfg.Username != "" {
+			auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+		}
+		return smtp.SendMail(addr, auth, cfg.From, recipients, message)
+	}
+}
+
+// buildEmail renders the message and returns it with the envelope recipients. Attachments are not supported
+func buildEmail(from string, payload *EmailNotificationPayload) ([]byte, []string, error) {
+	var recipients []string
+	for _, list := range [][]string{payload.To, payload.CC, payload.BCC} {
+		for _, address := range list {
+			parsed, err := mail.ParseAddress(address)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: %s", ErrInvalidRecipient, address)
+			}
+			recipients = append(recipients, parsed.Address)
+		}
+	}
+	if len(payload.To) == 0 {
+		return nil, nil, fmt.Errorf("%w: no recipients", ErrInvalidRecipient)
+	}
+
+	contentType := "text/plain"
+	if payload.HTML {
+		contentType = "text/html"
+	}
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(payload.To, ", "))
+	if len(payload.CC) > 0 {
+		fmt.Fprintf(&message, "Cc: %s\r\n", strings.Join(payload.CC, ", "))
+	}
+	// Header values must not break out of their line
+	fmt.Fprintf(&message, "Subject: %s\r\n", strings.NewReplacer("\r", " ", "\n", " ").Replace(payload.Subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: %s; charset=UTF-8\r\n\r\n", contentType)
+	message.WriteString(strings.ReplaceAll(payload.Body, "\n", "\r\n"))
+	return message.Bytes(), recipients, nil
+}
+
+// decodePayload decodes the task payload into dest
+func decodePayload(task *Task, dest interface{}) error {
+	data, err := json.Marshal(task.Payload)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDeserializeFailed, err)
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("%w: %v", ErrDeserializeFailed, err)
+	}
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"time"
 )
 
@@ -64,6 +65,8 @@ const (
 	EventTypePromptGenerated     = "prompt.generated"
 	EventTypeAPIRequestCompleted = "api.request.completed"
 	EventTypeAPIRequestFailed    = "api.request.failed"
+	EventTypeBudgetWarning       = "budget.warning"
+	EventTypeBudgetExceeded      = "budget.exceeded"
 )
 
 // Telemetry types
@@ -290,7 +293,7 @@ func RegisterDefaultHandlers(q *NATSQueue) {
 			return err
 		}
 		// TODO: Implement Claude API call
-		fmt.Printf("Processing Claude request for session %s\n", payload.SessionID)
+		fmt.Fprintf(os.Stderr, "Processing Claude request for session %s\n", payload.SessionID)
 		return nil
 	})
 
@@ -302,7 +305,7 @@ func RegisterDefaultHandlers(q *NATSQueue) {
 			return err
 		}
 		// TODO: Implement tool execution
-		fmt.Printf("Executing tool %s for session %s\n", payload.ToolName, payload.SessionID)
+		fmt.Fprintf(os.Stderr, "Executing tool %s for session %s\n", payload.ToolName, payload.SessionID)
 		return nil
 	})
 
@@ -314,7 +317,7 @@ func RegisterDefaultHandlers(q *NATSQueue) {
 			return err
 		}
 		// TODO: Implement telemetry export
-		fmt.Printf("Exporting telemetry for service %s to %s\n", payload.ServiceName, payload.Destination)
+		fmt.Fprintf(os.Stderr, "Exporting telemetry for service %s to %s\n", payload.ServiceName, payload.Destination)
 		return nil
 	})
 
@@ -326,7 +329,7 @@ func RegisterDefaultHandlers(q *NATSQueue) {
 			return err
 		}
 		// TODO: Implement session cleanup
-		fmt.Printf("Cleaning up session %s\n", payload.SessionID)
+		fmt.Fprintf(os.Stderr, "Cleaning up session %s\n", payload.SessionID)
 		return nil
 	})
 
@@ -338,7 +341,7 @@ func RegisterDefaultHandlers(q *NATSQueue) {
 			return err
 		}
 		// TODO: Implement cache invalidation
-		fmt.Printf("Invalidating cache pattern: %s\n", payload.Pattern)
+		fmt.Fprintf(os.Stderr, "Invalidating cache pattern: %s\n", payload.Pattern)
 		return nil
 	})
 
@@ -350,7 +353,7 @@ func RegisterDefaultHandlers(q *NATSQueue) {
 			return err
 		}
 		// TODO: Implement webhook delivery
-		fmt.Printf("Delivering webhook to %s\n", payload.URL)
+		fmt.Fprintf(os.Stderr, "Delivering webhook to %s\n", payload.URL)
 		return nil
 	})
 }
@@ -0,0 +1,120 @@
+// Package queue provides predefined task types for the MCP server.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ToolTaskRunner executes a background tool task by ID.
+type ToolTaskRunner func(ctx context.Context, taskID string) error
+
+// ToolTaskDispatcher queues background tool calls. Tasks always execute on the
+// local worker pool, since their state lives in this process; when NATS is
+// enabled they pass through an instance-specific JetStream subject first so
+// bursts are buffered durably instead of being rejected when the pool is full.
+type ToolTaskDispatcher struct {
+	pool    *WorkerPool
+	queue   TaskQueue
+	subject string
+}
+
+// NewToolTaskDispatcher creates a dispatcher that runs tasks on the pool.
+func NewToolTaskDispatcher(pool *WorkerPool) *ToolTaskDispatcher {
+	return &ToolTaskDispatcher{
+		pool:    pool,
+		queue:   pool,
+		subject: fmt.Sprintf("%s.%s", SubjectTaskPrefix, TaskTypeToolExecution),
+	}
+}
+
+// Handle registers the runner that executes dispatched tasks.
+func (d *ToolTaskDispatcher) Handle(run ToolTaskRunner) {
+	d.pool.RegisterHandler(TaskTypeToolExecution, func(ctx context.Context, task *Task) error {
+		payload, err := decodeToolExecutionPayload(task)
+		if err != nil {
+			return err
+		}
+		return run(ctx, payload.CallbackID)
+	})
+}
+
+// UseNATS routes tasks through a NATS queue consumed only by this instance.
+// A stable instance ID lets a restarted server drain, and discard, its stale messages.
+func (d *ToolTaskDispatcher) UseNATS(ctx context.Context, q *NATSQueue, instanceID string) error {
+	instanceID = subjectToken(instanceID)
+	subject := fmt.Sprintf("%s.%s.%s", SubjectTaskPrefix, TaskTypeToolExecution, instanceID)
+
+	// Hand tasks to the pool, holding the message until there is capacity
+	q.RegisterHandler(TaskTypeToolExecution, func(ctx context.Context, task *Task) error {
+		_, err := d.pool.PublishWait(ctx, task)
+		return err
+	})
+
+	if err := q.StartConsumer(ctx, StreamTasks, "tfo-mcp-tools-"+instanceID, subject); err != nil {
+		return fmt.Errorf("failed to start tool task consumer: %w", err)
+	}
+
+	d.queue = q
+	d.subject = subject
+	return nil
+}
+
+// Dispatch queues a tool task for execution.
+func (d *ToolTaskDispatcher) Dispatch(ctx context.Context, taskID string) error {
+	// Execution limits are enforced by the task runner, not the queue
+	task := NewTaskBuilder(TaskTypeToolExecution).
+		WithPayload(ToolExecutionPayload{CallbackID: taskID}).
+		WithSubject(d.subject).
+		WithMaxRetry(0).
+		WithTimeout(0).
+		Build()
+
+	_, err := d.queue.Publish(ctx, task)
+	return err
+}
+
+// subjectToken makes a value safe to use as a single NATS subject token.
+func subjectToken(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == '*' || r == '>' || r <= ' ' {
+			return '-'
+		}
+		return r
+	}, value)
+}
+
+// decodeToolExecutionPayload extracts a tool execution payload from a task.
+func decodeToolExecutionPayload(task *Task) (*ToolExecutionPayload, error) {
+	data, err := json.Marshal(task.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDeserializeFailed, err)
+	}
+	var payload ToolExecutionPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDeserializeFailed, err)
+	}
+	if payload.CallbackID == "" {
+		return nil, ErrInvalidTask
+	}
+	return &payload, nil
+}
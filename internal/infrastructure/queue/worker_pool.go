@@ -0,0 +1,196 @@
+// Package queue provides predefined task types for the MCP server.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Worker pool errors
+var (
+	ErrQueueFull   = errors.New("queue is full")
+	ErrQueueClosed = errors.New("queue is closed")
+)
+
+// TaskQueue is the publishing side shared by the in-process worker pool and NATSQueue.
+type TaskQueue interface {
+	RegisterHandler(taskType string, handler TaskHandler)
+	Publish(ctx context.Context, task *Task) (string, error)
+}
+
+// WorkerPool runs tasks on a fixed number of in-process goroutines.
+type WorkerPool struct {
+	workers   int
+	tasks     chan *Task
+	handlers  map[string]TaskHandler
+	mu        sync.RWMutex
+	wg        sync.WaitGroup
+	closed    bool
+	closing   chan struct{}
+	closeOnce sync.Once
+	cancel    context.CancelFunc
+}
+
+// NewWorkerPool creates a worker pool with the given concurrency and queue capacity.
+func NewWorkerPool(workers, queueSize int) *WorkerPool {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	return &WorkerPool{
+		workers:  workers,
+		tasks:    make(chan *Task, queueSize),
+		handlers: make(map[string]TaskHandler),
+		closing:  make(chan struct{}),
+	}
+}
+
+// Start launches the workers. They stop when ctx is cancelled or Close is called.
+func (p *WorkerPool) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	p.mu.Lock()
+	p.cancel = cancel
+	p.mu.Unlock()
+
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.work(ctx)
+	}
+}
+
+// work processes tasks until the context is done or the queue is closed.
+func (p *WorkerPool) work(ctx context.Context) {
+	defer p.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case task, ok := <-p.tasks:
+			// select picks randomly, so recheck cancellation before running
+			if !ok || ctx.Err() != nil {
+				return
+			}
+			p.process(ctx, task)
+		}
+	}
+}
+
+// process runs a single task with its handler and timeout.
+func (p *WorkerPool) process(ctx context.Context, task *Task) {
+	p.mu.RLock()
+	handler, ok := p.handlers[task.Type]
+	p.mu.RUnlock()
+	if !ok {
+		fmt.Fprintf(os.Stderr, "No handler for task type: %s\n", task.Type)
+		return
+	}
+
+	execCtx := ctx
+	if task.Timeout > 0 {
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(ctx, task.Timeout)
+		defer cancel()
+	}
+
+	if err := handler(execCtx, task); err != nil {
+		fmt.Fprintf(os.Stderr, "Task %s failed: %v\n", task.ID, err)
+	}
+}
+
+// RegisterHandler registers a task handler for a specific task type.
+func (p *WorkerPool) RegisterHandler(taskType string, handler TaskHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers[taskType] = handler
+}
+
+// Publish enqueues a task without blocking, returning ErrQueueFull when at capacity.
+func (p *WorkerPool) Publish(ctx context.Context, task *Task) (string, error) {
+	return p.enqueue(ctx, task, false)
+}
+
+// PublishWait enqueues a task, waiting for capacity until ctx is done.
+func (p *WorkerPool) PublishWait(ctx context.Context, task *Task) (string, error) {
+	return p.enqueue(ctx, task, true)
+}
+
+// enqueue adds a task to the queue, optionally waiting for capacity.
+func (p *WorkerPool) enqueue(ctx context.Context, task *Task, wait bool) (string, error) {
+	if task == nil || task.Type == "" {
+		return "", ErrInvalidTask
+	}
+	if task.ID == "" {
+		task.ID = generateTaskID()
+	}
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = time.Now()
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return "", ErrQueueClosed
+	}
+
+	if !wait {
+		select {
+		case p.tasks <- task:
+			return task.ID, nil
+		default:
+			return "", ErrQueueFull
+		}
+	}
+
+	select {
+	case p.tasks <- task:
+		return task.ID, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-p.closing:
+		return "", ErrQueueClosed
+	}
+}
+
+// Close stops accepting tasks, cancels running handlers and waits for workers to exit.
+func (p *WorkerPool) Close() error {
+	// Release publishers blocked in PublishWait before taking the write lock
+	p.closeOnce.Do(func() { close(p.closing) })
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.tasks)
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+	return nil
+}
@@ -0,0 +1,500 @@
+// Package upstream provides an MCP client for proxying upstream MCP servers
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/pkg/mcp"
+)
+
+// Client errors
+var (
+	ErrClientClosed       = errors.New("upstream connection closed")
+	ErrInvalidResponse    = errors.New("invalid upstream response")
+	ErrUnsupportedFeature = errors.New("upstream server does not support this feature")
+)
+
+// cancelNotifyTimeout bounds how long a notifications/cancelled send may take
+const cancelNotifyTimeout = 5 * time.Second
+
+// transport carries JSON-RPC messages to an upstream server.
+// Incoming messages are delivered to the receive callback passed at construction.
+type transport interface {
+	send(ctx context.Context, data []byte) error
+	close() error
+}
+
+// message is a JSON-RPC 2.0 message in either direction
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *mcp.Error      `json:"error,omitempty"`
+}
+
+// ServerInfo describes the upstream server returned by initialize
+type ServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ServerCapabilities lists the upstream features the proxy uses
+type ServerCapabilities struct {
+	Tools     json.RawMessage `json:"tools,omitempty"`
+	Resources json.RawMessage `json:"resources,omitempty"`
+	Prompts   json.RawMessage `json:"prompts,omitempty"`
+}
+
+// Tool is a tool definition returned by tools/list
+type Tool struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description,omitempty"`
+	InputSchema *entities.JSONSchema `json:"inputSchema,omitempty"`
+}
+
+// Resource is a resource definition returned by resources/list
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// Prompt is a prompt definition returned by prompts/list
+type Prompt struct {
+	Name        string                     `json:"name"`
+	Description string                     `json:"description,omitempty"`
+	Arguments   []*entities.PromptArgument `json:"arguments,omitempty"`
+}
+
+// Client is an MCP client connected to a single upstream server
+type Client struct {
+	name      string
+	transport transport
+	logger    zerolog.Logger
+
+	mu       sync.Mutex
+	nextID   int64
+	pending  map[string]chan *message
+	progress map[string]entities.ProgressReporter
+	closed   bool
+	closeErr error
+
+	serverInfo   ServerInfo
+	capabilities ServerCapabilities
+}
+
+// newClient creates a client; the transport is attached by the caller before use
+func newClient(name string, logger zerolog.Logger) *Client {
+	return &Client{
+		name:     name,
+		logger:   logger.With().Str("upstream", name).Logger(),
+		pending:  make(map[string]chan *message),
+		progress: make(map[string]entities.ProgressReporter),
+	}
+}
+
+// Name returns the upstream name used as the namespace prefix
+func (c *Client) Name() string {
+	return c.name
+}
+
+// ServerInfo returns the upstream server info from initialize
+func (c *Client) ServerInfo() ServerInfo {
+	return c.serverInfo
+}
+
+// Capabilities returns the upstream server capabilities from initialize
+func (c *Client) Capabilities() ServerCapabilities {
+	return c.capabilities
+}
+
+// Initialize performs the MCP handshake
+func (c *Client) Initialize(ctx context.Context, clientName, clientVersion string) error {
+	params := map[string]interface{}{
+		"protocolVersion": vo.CurrentMCPProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]interface{}{"name": clientName, "version": clientVersion},
+	}
+
+	var result struct {
+		ServerInfo   ServerInfo         `json:"serverInfo"`
+		Capabilities ServerCapabilities `json:"capabilities"`
+	}
+	if err := c.call(ctx, vo.MethodInitialize, params, &result); err != nil {
+		return err
+	}
+	c.serverInfo = result.ServerInfo
+	c.capabilities = result.Capabilities
+
+	return c.notify(ctx, vo.MethodInitialized, nil)
+}
+
+// ListTools returns all upstream tools, following pagination
+func (c *Client) ListTools(ctx context.Context) ([]*Tool, error) {
+	if c.capabilities.Tools == nil {
+		return nil, nil
+	}
+	var tools []*Tool
+	err := c.paginate(ctx, vo.MethodToolsList, func(raw json.RawMessage) error {
+		var page struct {
+			Tools []*Tool `json:"tools"`
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		tools = append(tools, page.Tools...)
+		return nil
+	})
+	return tools, err
+}
+
+// CallTool invokes an upstream tool, forwarding progress to the reporter in ctx.
+// Cancelling ctx sends notifications/cancelled upstream.
+func (c *Client) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (*entities.ToolResult, error) {
+	params := map[string]interface{}{
+		"name":      name,
+		"arguments": arguments,
+	}
+
+	if reporter, ok := entities.ProgressReporterFromContext(ctx); ok {
+		token := c.registerProgress(reporter)
+		defer c.unregisterProgress(token)
+		params["_meta"] = map[string]interface{}{"progressToken": token}
+	}
+
+	var result entities.ToolResult
+	if err := c.call(ctx, vo.MethodToolsCall, params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListResources returns all upstream resources, following pagination
+func (c *Client) ListResources(ctx context.Context) ([]*Resource, error) {
+	if c.capabilities.Resources == nil {
+		return nil, nil
+	}
+	var resources []*Resource
+	err := c.paginate(ctx, vo.MethodResourcesList, func(raw json.RawMessage) error {
+		var page struct {
+			Resources []*Resource `json:"resources"`
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		resources = append(resources, page.Resources...)
+		return nil
+	})
+	return resources, err
+}
+
+// ReadResource reads an upstream resource
+func (c *Client) ReadResource(ctx context.Context, uri string) ([]*entities.ResourceContent, error) {
+	if c.capabilities.Resources == nil {
+		return nil, ErrUnsupportedFeature
+	}
+	var result struct {
+		Contents []*entities.ResourceContent `json:"contents"`
+	}
+	if err := c.call(ctx, vo.MethodResourcesRead, map[string]interface{}{"uri": uri}, &result); err != nil {
+		return nil, err
+	}
+	return result.Contents, nil
+}
+
+// ListPrompts returns all upstream prompts, following pagination
+func (c *Client) ListPrompts(ctx context.Context) ([]*Prompt, error) {
+	if c.capabilities.Prompts == nil {
+		return nil, nil
+	}
+	var prompts []*Prompt
+	err := c.paginate(ctx, vo.MethodPromptsList, func(raw json.RawMessage) error {
+		var page struct {
+			Prompts []*Prompt `json:"prompts"`
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		prompts = append(prompts, page.Prompts...)
+		return nil
+	})
+	return prompts, err
+}
+
+// GetPrompt renders an upstream prompt
+func (c *Client) GetPrompt(ctx context.Context, name string, arguments map[string]string) (*entities.PromptMessages, error) {
+	if c.capabilities.Prompts == nil {
+		return nil, ErrUnsupportedFeature
+	}
+	params := map[string]interface{}{"name": name}
+	if len(arguments) > 0 {
+		params["arguments"] = arguments
+	}
+	var result entities.PromptMessages
+	if err := c.call(ctx, vo.MethodPromptsGet, params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Close closes the connection and fails pending calls
+func (c *Client) Close() error {
+	c.shutdown(ErrClientClosed)
+	if c.transport == nil {
+		return nil
+	}
+	return c.transport.close()
+}
+
+// paginate calls a list method until the upstream stops returning a cursor
+func (c *Client) paginate(ctx context.Context, method vo.MCPMethod, page func(json.RawMessage) error) error {
+	cursor := ""
+	for {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+
+		var raw json.RawMessage
+		if err := c.call(ctx, method, params, &raw); err != nil {
+			return err
+		}
+		if err := page(raw); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+		}
+
+		var next struct {
+			NextCursor string `json:"nextCursor"`
+		}
+		_ = json.Unmarshal(raw, &next)
+		if next.NextCursor == "" || next.NextCursor == cursor {
+			return nil
+		}
+		cursor = next.NextCursor
+	}
+}
+
+// call sends a request and decodes its result into out
+func (c *Client) call(ctx context.Context, method vo.MCPMethod, params interface{}, out interface{}) error {
+	c.mu.Lock()
+	if c.closed {
+		err := c.closeErr
+		c.mu.Unlock()
+		return err
+	}
+	c.nextID++
+	id := strconv.FormatInt(c.nextID, 10)
+	reply := make(chan *message, 1)
+	c.pending[id] = reply
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	data, err := encode(json.RawMessage(id), method, params)
+	if err != nil {
+		return err
+	}
+	if err := c.transport.send(ctx, data); err != nil {
+		if ctx.Err() != nil {
+			c.cancelUpstream(ctx, id)
+			return ctx.Err()
+		}
+		return err
+	}
+
+	select {
+	case msg, ok := <-reply:
+		if !ok {
+			c.mu.Lock()
+			err := c.closeErr
+			c.mu.Unlock()
+			return err
+		}
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if out == nil {
+			return nil
+		}
+		if err := json.Unmarshal(msg.Result, out); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+		}
+		return nil
+	case <-ctx.Done():
+		c.cancelUpstream(ctx, id)
+		return ctx.Err()
+	}
+}
+
+// cancelUpstream tells the upstream server to abandon a request
+func (c *Client) cancelUpstream(ctx context.Context, id string) {
+	reason := "request cancelled"
+	if cause := context.Cause(ctx); cause != nil {
+		reason = cause.Error()
+	}
+
+	notifyCtx, cancel := context.WithTimeout(context.Background(), cancelNotifyTimeout)
+	defer cancel()
+	params := map[string]interface{}{"requestId": json.RawMessage(id), "reason": reason}
+	if err := c.notify(notifyCtx, vo.MethodNotificationsCancelled, params); err != nil {
+		c.logger.Debug().Err(err).Str("request_id", id).Msg("Failed to forward cancellation")
+	}
+}
+
+// notify sends a notification
+func (c *Client) notify(ctx context.Context, method vo.MCPMethod, params interface{}) error {
+	data, err := encode(nil, method, params)
+	if err != nil {
+		return err
+	}
+	return c.transport.send(ctx, data)
+}
+
+// encode builds a JSON-RPC request, or a notification when id is nil
+func encode(id json.RawMessage, method vo.MCPMethod, params interface{}) ([]byte, error) {
+	msg := message{JSONRPC: "2.0", ID: id, Method: method.String()}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal params: %w", err)
+		}
+		msg.Params = raw
+	}
+	return json.Marshal(msg)
+}
+
+// receive routes a message from the upstream server
+func (c *Client) receive(data []byte) {
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		c.logger.Debug().Err(err).Msg("Ignoring malformed upstream message")
+		return
+	}
+
+	switch {
+	case msg.Method == "" && msg.ID != nil:
+		c.mu.Lock()
+		if reply, ok := c.pending[string(msg.ID)]; ok {
+			delete(c.pending, string(msg.ID))
+			reply <- &msg
+		}
+		c.mu.Unlock()
+	case msg.Method != "" && msg.ID != nil:
+		// Answer asynchronously so a blocked write cannot stall the read loop
+		go c.answer(&msg)
+	case vo.MCPMethod(msg.Method) == vo.MethodNotificationsProgress:
+		c.forwardProgress(msg.Params)
+	default:
+		c.logger.Debug().Str("method", msg.Method).Msg("Upstream notification")
+	}
+}
+
+// answer responds to requests from the upstream server; the proxy offers no client features beyond ping
+func (c *Client) answer(req *message) {
+	reply := message{JSONRPC: "2.0", ID: req.ID}
+	if vo.MCPMethod(req.Method) == vo.MethodPing {
+		reply.Result = json.RawMessage(`{}`)
+	} else {
+		reply.Error = mcp.NewMethodNotFoundError(req.Method)
+	}
+
+	data, err := json.Marshal(reply)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cancelNotifyTimeout)
+	defer cancel()
+	if err := c.transport.send(ctx, data); err != nil {
+		c.logger.Debug().Err(err).Str("method", req.Method).Msg("Failed to answer upstream request")
+	}
+}
+
+// registerProgress allocates a progress token routed to reporter
+func (c *Client) registerProgress(reporter entities.ProgressReporter) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextID++
+	token := fmt.Sprintf("%s-%d", c.name, c.nextID)
+	c.progress[token] = reporter
+	return token
+}
+
+// unregisterProgress drops a progress token
+func (c *Client) unregisterProgress(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.progress, token)
+}
+
+// forwardProgress passes an upstream progress notification to the waiting caller
+func (c *Client) forwardProgress(raw json.RawMessage) {
+	var p struct {
+		ProgressToken interface{} `json:"progressToken"`
+		Progress      float64     `json:"progress"`
+		Total         float64     `json:"total"`
+		Message       string      `json:"message"`
+	}
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return
+	}
+	token, ok := p.ProgressToken.(string)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	reporter, ok := c.progress[token]
+	c.mu.Unlock()
+	if ok {
+		reporter(entities.ToolProgress{Progress: p.Progress, Total: p.Total, Message: p.Message})
+	}
+}
+
+// shutdown marks the client closed and fails pending calls with err
+func (c *Client) shutdown(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.closeErr = err
+	for id, reply := range c.pending {
+		close(reply)
+		delete(c.pending, id)
+	}
+}
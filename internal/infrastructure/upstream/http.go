@@ -0,0 +1,241 @@
+// Package upstream provides an MCP client for proxying upstream MCP servers
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upstream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// sessionHeader carries the upstream session ID in the streamable HTTP transport
+const sessionHeader = "Mcp-Session-Id"
+
+// maxErrorBodySize caps how much of an error response is included in errors
+const maxErrorBodySize = 512
+
+// HTTPOptions configures a streamable HTTP upstream
+type HTTPOptions struct {
+	URL string
+	// Headers are sent with every request; values are expanded against the proxy environment
+	Headers map[string]string
+	// Client overrides the HTTP client (defaults to http.DefaultClient)
+	Client *http.Client
+}
+
+// httpTransport implements the MCP streamable HTTP transport
+type httpTransport struct {
+	url     string
+	headers http.Header
+	client  *http.Client
+	receive func([]byte)
+	logger  zerolog.Logger
+
+	mu        sync.Mutex
+	sessionID string
+}
+
+// ConnectHTTP creates a client for an upstream MCP server reachable over streamable HTTP
+func ConnectHTTP(name string, opts HTTPOptions, logger zerolog.Logger) (*Client, error) {
+	if opts.URL == "" {
+		return nil, fmt.Errorf("upstream %s: url is required", name)
+	}
+
+	client := newClient(name, logger)
+
+	headers := make(http.Header)
+	for key, value := range opts.Headers {
+		headers.Set(key, os.ExpandEnv(value))
+	}
+	httpClient := opts.Client
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	client.transport = &httpTransport{
+		url:     opts.URL,
+		headers: headers,
+		client:  httpClient,
+		receive: client.receive,
+		logger:  client.logger,
+	}
+	return client, nil
+}
+
+// send POSTs a message and delivers every message in the reply, which may be JSON or an SSE stream
+func (t *httpTransport) send(ctx context.Context, data []byte) error {
+	var outgoing message
+	_ = json.Unmarshal(data, &outgoing)
+	isRequest := outgoing.Method != "" && outgoing.ID != nil
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	t.prepare(req)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if id := resp.Header.Get(sessionHeader); id != "" {
+		t.mu.Lock()
+		t.sessionID = id
+		t.mu.Unlock()
+	}
+
+	if resp.StatusCode == http.StatusAccepted || resp.StatusCode == http.StatusNoContent {
+		if isRequest {
+			return fmt.Errorf("%w: no response to %s", ErrInvalidResponse, outgoing.Method)
+		}
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return fmt.Errorf("upstream returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	answered := false
+	deliver := func(raw []byte) {
+		if isRequest && !answered {
+			var incoming message
+			if json.Unmarshal(raw, &incoming) == nil && incoming.Method == "" && bytes.Equal(incoming.ID, outgoing.ID) {
+				answered = true
+			}
+		}
+		t.receive(raw)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "text/event-stream" {
+		err = readEventStream(resp.Body, deliver)
+	} else {
+		err = readJSONBody(resp.Body, deliver)
+	}
+	if err != nil {
+		return err
+	}
+	if isRequest && !answered {
+		return fmt.Errorf("%w: no response to %s", ErrInvalidResponse, outgoing.Method)
+	}
+	return nil
+}
+
+// close ends the upstream session
+func (t *httpTransport) close() error {
+	t.mu.Lock()
+	sessionID := t.sessionID
+	t.mu.Unlock()
+	if sessionID == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.url, nil)
+	if err != nil {
+		return err
+	}
+	t.prepare(req)
+	resp, err := t.client.Do(req)
+	if err != nil {
+		t.logger.Debug().Err(err).Msg("Failed to end upstream session")
+		return nil
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// prepare adds the configured and session headers
+func (t *httpTransport) prepare(req *http.Request) {
+	for key, values := range t.headers {
+		req.Header[key] = values
+	}
+	t.mu.Lock()
+	if t.sessionID != "" {
+		req.Header.Set(sessionHeader, t.sessionID)
+	}
+	t.mu.Unlock()
+}
+
+// readJSONBody delivers a single message or a batch
+func readJSONBody(body io.Reader, deliver func([]byte)) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil
+	}
+	if data[0] != '[' {
+		deliver(data)
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	for _, raw := range batch {
+		deliver(raw)
+	}
+	return nil
+}
+
+// readEventStream delivers the data of each server-sent event until the stream ends
+func readEventStream(body io.Reader, deliver func([]byte)) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxStdioMessageSize)
+
+	var data []string
+	flush := func() {
+		if len(data) > 0 {
+			deliver([]byte(strings.Join(data, "\n")))
+			data = data[:0]
+		}
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	flush()
+	return scanner.Err()
+}
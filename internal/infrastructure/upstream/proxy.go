@@ -0,0 +1,314 @@
+// Package upstream provides an MCP client for proxying upstream MCP servers
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// Separator joins the upstream name and the upstream tool or prompt name
+const Separator = "__"
+
+// DefaultTimeout applies to upstream calls when no timeout is configured
+const DefaultTimeout = 60 * time.Second
+
+// Proxy errors
+var (
+	ErrDuplicateUpstream  = errors.New("upstream already connected")
+	ErrUnknownTransport   = errors.New("upstream transport must be 'stdio' or 'http'")
+	ErrEmptyResourceReply = errors.New("upstream returned no resource contents")
+)
+
+// Options describes an upstream server to connect
+type Options struct {
+	// Name is the namespace prefix for re-exported tools and prompts
+	Name      string
+	Transport string // "stdio" or "http"
+	Stdio     StdioOptions
+	HTTP      HTTPOptions
+	// Timeout bounds tool calls, resource reads and prompt renders
+	Timeout time.Duration
+}
+
+// Proxy re-exports the tools, resources and prompts of upstream MCP servers
+type Proxy struct {
+	clientName    string
+	clientVersion string
+	logger        zerolog.Logger
+
+	mu        sync.Mutex
+	clients   map[string]*Client
+	tools     []*entities.Tool
+	resources []*entities.Resource
+	prompts   []*entities.Prompt
+	uris      map[string]string
+}
+
+// NewProxy creates a proxy that identifies itself upstream with the given client info
+func NewProxy(clientName, clientVersion string, logger zerolog.Logger) *Proxy {
+	return &Proxy{
+		clientName:    clientName,
+		clientVersion: clientVersion,
+		logger:        logger.With().Str("component", "upstream-proxy").Logger(),
+		clients:       make(map[string]*Client),
+		uris:          make(map[string]string),
+	}
+}
+
+// Connect starts an upstream, performs the handshake and collects its tools, resources and prompts
+func (p *Proxy) Connect(ctx context.Context, opts Options) error {
+	p.mu.Lock()
+	_, exists := p.clients[opts.Name]
+	p.mu.Unlock()
+	if exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateUpstream, opts.Name)
+	}
+
+	var client *Client
+	var err error
+	switch opts.Transport {
+	case "stdio":
+		client, err = ConnectStdio(opts.Name, opts.Stdio, p.logger)
+	case "http":
+		client, err = ConnectHTTP(opts.Name, opts.HTTP, p.logger)
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnknownTransport, opts.Transport)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := p.Add(ctx, client, opts.Timeout); err != nil {
+		_ = client.Close()
+		return err
+	}
+	return nil
+}
+
+// Add initializes a connected client and re-exports its features
+func (p *Proxy) Add(ctx context.Context, client *Client, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	if err := client.Initialize(ctx, p.clientName, p.clientVersion); err != nil {
+		return fmt.Errorf("upstream %s: initialize failed: %w", client.Name(), err)
+	}
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		return fmt.Errorf("upstream %s: tools/list failed: %w", client.Name(), err)
+	}
+	resources, err := client.ListResources(ctx)
+	if err != nil {
+		return fmt.Errorf("upstream %s: resources/list failed: %w", client.Name(), err)
+	}
+	prompts, err := client.ListPrompts(ctx)
+	if err != nil {
+		return fmt.Errorf("upstream %s: prompts/list failed: %w", client.Name(), err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.clients[client.Name()]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateUpstream, client.Name())
+	}
+	p.clients[client.Name()] = client
+
+	for _, tool := range tools {
+		if entity, err := p.proxyTool(client, tool, timeout); err != nil {
+			p.logger.Warn().Err(err).Str("upstream", client.Name()).Str("tool", tool.Name).Msg("Skipping upstream tool")
+		} else {
+			p.tools = append(p.tools, entity)
+		}
+	}
+	for _, resource := range resources {
+		if owner, taken := p.uris[resource.URI]; taken {
+			p.logger.Warn().Str("upstream", client.Name()).Str("uri", resource.URI).Str("owner", owner).Msg("Skipping duplicate upstream resource")
+			continue
+		}
+		if entity, err := proxyResource(client, resource, timeout); err != nil {
+			p.logger.Warn().Err(err).Str("upstream", client.Name()).Str("uri", resource.URI).Msg("Skipping upstream resource")
+		} else {
+			p.uris[resource.URI] = client.Name()
+			p.resources = append(p.resources, entity)
+		}
+	}
+	for _, prompt := range prompts {
+		if entity, err := proxyPrompt(client, prompt, timeout); err != nil {
+			p.logger.Warn().Err(err).Str("upstream", client.Name()).Str("prompt", prompt.Name).Msg("Skipping upstream prompt")
+		} else {
+			p.prompts = append(p.prompts, entity)
+		}
+	}
+
+	p.logger.Info().
+		Str("upstream", client.Name()).
+		Str("server", client.ServerInfo().Name).
+		Int("tools", len(tools)).
+		Int("resources", len(resources)).
+		Int("prompts", len(prompts)).
+		Msg("Upstream MCP server connected")
+	return nil
+}
+
+// Tools returns the re-exported upstream tools
+func (p *Proxy) Tools() []*entities.Tool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*entities.Tool(nil), p.tools...)
+}
+
+// Resources returns the re-exported upstream resources
+func (p *Proxy) Resources() []*entities.Resource {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*entities.Resource(nil), p.resources...)
+}
+
+// Prompts returns the re-exported upstream prompts
+func (p *Proxy) Prompts() []*entities.Prompt {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*entities.Prompt(nil), p.prompts...)
+}
+
+// Close disconnects every upstream
+func (p *Proxy) Close() error {
+	p.mu.Lock()
+	clients := p.clients
+	p.clients = make(map[string]*Client)
+	p.mu.Unlock()
+
+	var errs []error
+	for _, client := range clients {
+		if err := client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// proxyTool builds a namespaced tool that forwards calls upstream
+func (p *Proxy) proxyTool(client *Client, tool *Tool, timeout time.Duration) (*entities.Tool, error) {
+	name, err := vo.NewToolName(client.Name() + Separator + tool.Name)
+	if err != nil {
+		return nil, err
+	}
+	description := tool.Description
+	if len(description) > vo.MaxToolDescriptionLength {
+		description = description[:vo.MaxToolDescriptionLength]
+	}
+	desc, err := vo.NewToolDescription(description)
+	if err != nil {
+		return nil, err
+	}
+	schema := tool.InputSchema
+	if schema == nil {
+		schema = &entities.JSONSchema{Type: "object"}
+	}
+
+	entity, err := entities.NewTool(name, desc, schema)
+	if err != nil {
+		return nil, err
+	}
+	entity.SetCategory("upstream")
+	entity.SetTags([]string{"upstream", client.Name()})
+	entity.SetTimeout(timeout)
+	entity.SetMetadata("upstream", client.Name())
+	entity.SetMetadata("upstream_tool", tool.Name)
+
+	upstreamName := tool.Name
+	entity.SetContextHandler(func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		if input == nil {
+			input = map[string]interface{}{}
+		}
+		return client.CallTool(ctx, upstreamName, input)
+	})
+	return entity, nil
+}
+
+// proxyResource builds a resource that reads through to the upstream
+func proxyResource(client *Client, resource *Resource, timeout time.Duration) (*entities.Resource, error) {
+	uri, err := vo.NewResourceURI(resource.URI)
+	if err != nil {
+		return nil, err
+	}
+	entity, err := entities.NewResource(uri, client.Name()+Separator+resource.Name)
+	if err != nil {
+		return nil, err
+	}
+	entity.SetDescription(resource.Description)
+	if resource.MimeType != "" {
+		if mimeType, err := vo.NewMimeType(resource.MimeType); err == nil {
+			entity.SetMimeType(mimeType)
+		}
+	}
+	entity.SetMetadata("upstream", client.Name())
+
+	entity.SetReader(func(uri string) (*entities.ResourceContent, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		contents, err := client.ReadResource(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		if len(contents) == 0 {
+			return nil, ErrEmptyResourceReply
+		}
+		return contents[0], nil
+	})
+	return entity, nil
+}
+
+// proxyPrompt builds a namespaced prompt rendered by the upstream
+func proxyPrompt(client *Client, prompt *Prompt, timeout time.Duration) (*entities.Prompt, error) {
+	name, err := vo.NewToolName(client.Name() + Separator + prompt.Name)
+	if err != nil {
+		return nil, err
+	}
+	entity, err := entities.NewPrompt(name, prompt.Description)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range prompt.Arguments {
+		if arg != nil {
+			entity.AddArgument(arg)
+		}
+	}
+	entity.SetMetadata("upstream", client.Name())
+
+	upstreamName := prompt.Name
+	entity.SetGenerator(func(args map[string]string) (*entities.PromptMessages, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		return client.GetPrompt(ctx, upstreamName, args)
+	})
+	return entity, nil
+}
@@ -0,0 +1,214 @@
+// Package upstream provides an MCP client for proxying upstream MCP servers
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upstream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// stdioStopTimeout is how long a subprocess gets to exit after stdin closes
+const stdioStopTimeout = 3 * time.Second
+
+// maxStdioMessageSize caps a single newline-delimited message from the subprocess
+const maxStdioMessageSize = 10 * 1024 * 1024
+
+// defaultEnvPassthrough is inherited by subprocesses when no passthrough list is configured
+var defaultEnvPassthrough = []string{"PATH", "HOME", "USER", "LOGNAME", "TMPDIR", "TZ", "LANG", "LC_*"}
+
+// StdioOptions configures a subprocess upstream
+type StdioOptions struct {
+	Command string
+	Args    []string
+	// Env holds NAME=value entries; values are expanded against the proxy environment
+	Env []string
+	// EnvPassthrough lists variable names (glob patterns) inherited from the proxy
+	EnvPassthrough []string
+	WorkingDir     string
+}
+
+// stdioTransport exchanges newline-delimited JSON-RPC with a subprocess
+type stdioTransport struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	logger zerolog.Logger
+
+	writeMu sync.Mutex
+	exited  chan struct{}
+	once    sync.Once
+}
+
+// ConnectStdio starts an upstream MCP server subprocess
+func ConnectStdio(name string, opts StdioOptions, logger zerolog.Logger) (*Client, error) {
+	if opts.Command == "" {
+		return nil, fmt.Errorf("upstream %s: command is required", name)
+	}
+
+	client := newClient(name, logger)
+
+	cmd := exec.Command(opts.Command, opts.Args...)
+	cmd.Dir = opts.WorkingDir
+	cmd.Env = subprocessEnv(opts.EnvPassthrough, opts.Env)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stderr = &stderrLogger{logger: client.logger}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("upstream %s: failed to start %s: %w", name, opts.Command, err)
+	}
+
+	t := &stdioTransport{
+		cmd:    cmd,
+		stdin:  stdin,
+		logger: client.logger,
+		exited: make(chan struct{}),
+	}
+	client.transport = t
+
+	go func() {
+		t.readLoop(stdout, client.receive)
+		err := cmd.Wait()
+		close(t.exited)
+		if err != nil {
+			client.logger.Warn().Err(err).Msg("Upstream process exited")
+		}
+		client.shutdown(fmt.Errorf("%w: process exited", ErrClientClosed))
+	}()
+
+	return client, nil
+}
+
+// send writes one message line to the subprocess
+func (t *stdioTransport) send(ctx context.Context, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	select {
+	case <-t.exited:
+		return fmt.Errorf("%w: process exited", ErrClientClosed)
+	default:
+	}
+	if _, err := fmt.Fprintf(t.stdin, "%s\n", data); err != nil {
+		return fmt.Errorf("%w: %v", ErrClientClosed, err)
+	}
+	return nil
+}
+
+// close closes stdin and kills the subprocess if it does not exit in time
+func (t *stdioTransport) close() error {
+	t.once.Do(func() {
+		t.writeMu.Lock()
+		_ = t.stdin.Close()
+		t.writeMu.Unlock()
+
+		select {
+		case <-t.exited:
+		case <-time.After(stdioStopTimeout):
+			_ = t.cmd.Process.Kill()
+			<-t.exited
+		}
+	})
+	return nil
+}
+
+// readLoop delivers each stdout line to receive until the stream ends
+func (t *stdioTransport) readLoop(stdout io.Reader, receive func([]byte)) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxStdioMessageSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		receive(append([]byte(nil), line...))
+	}
+	if err := scanner.Err(); err != nil {
+		t.logger.Warn().Err(err).Msg("Upstream stdout read failed")
+	}
+	// Drain so the process is not blocked writing after a read error
+	_, _ = io.Copy(io.Discard, stdout)
+}
+
+// stderrLogger forwards subprocess stderr to the log, one entry per line
+type stderrLogger struct {
+	logger  zerolog.Logger
+	partial []byte
+}
+
+// Write implements io.Writer
+func (w *stderrLogger) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		if line := strings.TrimRight(string(w.partial[:i]), "\r"); line != "" {
+			w.logger.Debug().Str("stderr", line).Msg("Upstream output")
+		}
+		w.partial = w.partial[i+1:]
+	}
+	// Keep a runaway line from growing without bound
+	if len(w.partial) > maxStdioMessageSize {
+		w.partial = w.partial[:0]
+	}
+	return len(p), nil
+}
+
+// subprocessEnv builds the subprocess environment from the passthrough list and explicit entries
+func subprocessEnv(passthrough, entries []string) []string {
+	if len(passthrough) == 0 {
+		passthrough = defaultEnvPassthrough
+	}
+
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		for _, pattern := range passthrough {
+			if ok, _ := filepath.Match(pattern, name); ok {
+				env = append(env, kv)
+				break
+			}
+		}
+	}
+	for _, kv := range entries {
+		env = append(env, os.ExpandEnv(kv))
+	}
+	return env
+}
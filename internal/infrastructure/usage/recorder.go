@@ -0,0 +1,187 @@
+// Package usage meters LLM calls: token usage, latency and cost.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+const (
+	// writeTimeout bounds a single repository write or flush
+	writeTimeout = 10 * time.Second
+
+	// maxErrorLength caps the error message stored with a failed call
+	maxErrorLength = 1024
+)
+
+// flusher is implemented by repositories that write in batches
+type flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// Options configures a Recorder
+type Options struct {
+	BufferSize     int           // records waiting to be written before new ones are dropped
+	FlushInterval  time.Duration // how often batched records are sent
+	OrganizationID string        // organization of calls made outside an organization's session
+}
+
+// Recorder prices LLM calls and writes their usage records to a repository. Records are written by
+// a background goroutine so LLM calls never wait on the database
+type Recorder struct {
+	repo   repositories.ILLMUsageRepository
+	prices vo.PriceTable
+	opts   Options
+	logger zerolog.Logger
+
+	mu      sync.RWMutex
+	closed  bool
+	records chan *entities.LLMUsage
+	done    chan struct{}
+	dropped atomic.Int64
+}
+
+// NewRecorder creates a recorder that prices calls with prices
+func NewRecorder(repo repositories.ILLMUsageRepository, prices vo.PriceTable, opts Options, logger zerolog.Logger) *Recorder {
+	if opts.BufferSize < 1 {
+		opts.BufferSize = 1
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	return &Recorder{
+		repo:    repo,
+		prices:  prices,
+		opts:    opts,
+		logger:  logger,
+		records: make(chan *entities.LLMUsage, opts.BufferSize),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start runs the writer until Close is called
+func (r *Recorder) Start() {
+	go r.run()
+}
+
+// RecordUsage prices a call and queues it for writing; it never blocks, dropping the record when the buffer is full
+func (r *Recorder) RecordUsage(_ context.Context, usage *entities.LLMUsage) {
+	r.Price(usage)
+	if usage.OrganizationID == "" {
+		usage.OrganizationID = r.opts.OrganizationID
+	}
+	if len(usage.ErrorMessage) > maxErrorLength {
+		usage.ErrorMessage = usage.ErrorMessage[:maxErrorLength]
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+
+	select {
+	case r.records <- usage:
+	default:
+		if r.dropped.Add(1) == 1 {
+			r.logger.Warn().Msg("LLM usage buffer full, dropping usage records")
+		}
+	}
+}
+
+// Price sets the cost of a call and the price table version it was computed with.
+// Calls to models without a price cost nothing and carry no version
+func (r *Recorder) Price(usage *entities.LLMUsage) {
+	price, ok := r.prices.Price(usage.Model)
+	if !ok {
+		usage.CostUSD, usage.PricingVersion = 0, ""
+		return
+	}
+	usage.CostUSD = price.Cost(usage.InputTokens, usage.OutputTokens, usage.CacheReadTokens, usage.CacheWriteTokens)
+	usage.PricingVersion = r.prices.Version
+}
+
+// Dropped returns how many records were dropped because the buffer was full
+func (r *Recorder) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+// Close writes the queued records, flushes batches and stops the writer
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	close(r.records)
+	r.mu.Unlock()
+
+	<-r.done
+	return nil
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+
+	flush := time.NewTicker(r.opts.FlushInterval)
+	defer flush.Stop()
+
+	for {
+		select {
+		case usage, ok := <-r.records:
+			if !ok {
+				r.flush()
+				return
+			}
+			r.write(usage)
+		case <-flush.C:
+			r.flush()
+		}
+	}
+}
+
+func (r *Recorder) write(usage *entities.LLMUsage) {
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	if err := r.repo.Save(ctx, usage); err != nil {
+		r.logger.Warn().Err(err).Str("model", usage.Model.String()).Msg("Failed to save LLM usage record")
+	}
+}
+
+func (r *Recorder) flush() {
+	batched, ok := r.repo.(flusher)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	if err := batched.Flush(ctx); err != nil {
+		r.logger.Warn().Err(err).Msg("Failed to send LLM usage batch")
+	}
+}
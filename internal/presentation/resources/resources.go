@@ -29,6 +29,9 @@ import (
 	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
 )
 
+// DefaultMaxFileSize is the default maximum number of bytes read from a single file
+const DefaultMaxFileSize int64 = 10 * 1024 * 1024 // 10MB
+
 // ResourceHandler handles MCP resource operations
 type ResourceHandler struct {
 	allowedPaths []string
@@ -43,6 +46,11 @@ func NewResourceHandler(allowedPaths []string, maxFileSize int64) *ResourceHandl
 	}
 }
 
+// MaxFileSize returns the maximum number of bytes read from a single file
+func (h *ResourceHandler) MaxFileSize() int64 {
+	return h.maxFileSize
+}
+
 // ResourceContent represents the content of a resource
 type ResourceContent struct {
 	URI      string
@@ -92,7 +100,7 @@ func (h *ResourceHandler) ReadResource(ctx context.Context, uri string) (*Resour
 	}
 
 	// Determine MIME type
-	mimeType := h.detectMimeType(path)
+	mimeType := h.DetectMimeType(path)
 
 	return &ResourceContent{
 		URI:      uri,
@@ -118,7 +126,7 @@ func (h *ResourceHandler) ListResources(ctx context.Context) ([]ResourceInfo, er
 				URI:         "file://" + path,
 				Name:        info.Name(),
 				Description: fmt.Sprintf("File: %s", path),
-				MimeType:    h.detectMimeType(path),
+				MimeType:    h.DetectMimeType(path),
 			})
 			return nil
 		})
@@ -138,27 +146,41 @@ type ResourceInfo struct {
 	MimeType    string `json:"mimeType,omitempty"`
 }
 
-// isPathAllowed checks if a path is within allowed directories
+// isPathAllowed checks if a path is within allowed directories, once symlinks are resolved
 func (h *ResourceHandler) isPathAllowed(path string) bool {
-	absPath, err := filepath.Abs(path)
+	resolved, err := resolvePath(path)
 	if err != nil {
 		return false
 	}
 
 	for _, allowed := range h.allowedPaths {
-		allowedAbs, err := filepath.Abs(allowed)
+		root, err := resolvePath(allowed)
 		if err != nil {
 			continue
 		}
-		if strings.HasPrefix(absPath, allowedAbs) {
+		rel, err := filepath.Rel(root, resolved)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
 			return true
 		}
 	}
 	return false
 }
 
-// detectMimeType detects the MIME type based on file extension
-func (h *ResourceHandler) detectMimeType(path string) string {
+// resolvePath returns the absolute path with its symlinks resolved; a path that does not exist is
+// only made absolute
+func resolvePath(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		return resolved, nil
+	}
+	return absPath, nil
+}
+
+// DetectMimeType detects the MIME type based on file extension
+func (h *ResourceHandler) DetectMimeType(path string) string {
 	ext := strings.ToLower(filepath.Ext(path))
 
 	mimeTypes := map[string]string{
@@ -27,6 +27,7 @@ import (
 	"io"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog"
 
@@ -34,6 +35,7 @@ import (
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/queries"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
 	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
 )
@@ -43,6 +45,7 @@ var (
 	ErrServerClosed     = errors.New("server closed")
 	ErrInvalidTransport = errors.New("invalid transport")
 	ErrSessionRequired  = errors.New("session required")
+	ErrRequestCancelled = errors.New("request cancelled by client")
 )
 
 // Server represents the MCP server
@@ -54,6 +57,7 @@ type Server struct {
 	sessionHandler      *handlers.SessionHandler
 	toolHandler         *handlers.ToolHandler
 	conversationHandler *handlers.ConversationHandler
+	taskHandler         *handlers.TaskHandler
 
 	// State
 	mu             sync.RWMutex
@@ -61,9 +65,22 @@ type Server struct {
 	running        bool
 	done           chan struct{}
 
+	// Resources and prompts registered in every new session
+	resources []*entities.Resource
+	prompts   []*entities.Prompt
+
+	// LLM providers reported in initialize
+	llmProviders []string
+
+	// In-flight requests, keyed by JSON-RPC ID, for notifications/cancelled
+	inFlightMu sync.Mutex
+	inFlight   map[string]context.CancelCauseFunc
+	wg         sync.WaitGroup
+
 	// I/O
-	reader io.Reader
-	writer io.Writer
+	reader  io.Reader
+	writer  io.Writer
+	writeMu sync.Mutex
 }
 
 // NewServer creates a new MCP server
@@ -81,11 +98,38 @@ func NewServer(
 		toolHandler:         toolHandler,
 		conversationHandler: conversationHandler,
 		done:                make(chan struct{}),
+		inFlight:            make(map[string]context.CancelCauseFunc),
 		reader:              os.Stdin,
 		writer:              os.Stdout,
 	}
 }
 
+// SetTaskHandler enables background task support for tools/call
+func (s *Server) SetTaskHandler(taskHandler *handlers.TaskHandler) {
+	s.taskHandler = taskHandler
+}
+
+// SetLLMProviders reports the active LLM providers in the initialize capabilities
+func (s *Server) SetLLMProviders(providers []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.llmProviders = providers
+}
+
+// RegisterResource exposes a resource in every session initialized afterwards
+func (s *Server) RegisterResource(resource *entities.Resource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resources = append(s.resources, resource)
+}
+
+// RegisterPrompt exposes a prompt in every session initialized afterwards
+func (s *Server) RegisterPrompt(prompt *entities.Prompt) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prompts = append(s.prompts, prompt)
+}
+
 // SetIO sets custom I/O for the server (useful for testing)
 func (s *Server) SetIO(reader io.Reader, writer io.Writer) {
 	s.reader = reader
@@ -131,6 +175,9 @@ func (s *Server) runStdio(ctx context.Context) error {
 	scanner := bufio.NewScanner(s.reader)
 	scanner.Buffer(make([]byte, 1024*1024), 10*1024*1024) // 10MB max message size
 
+	// Let in-flight tool calls finish writing before returning
+	defer s.wg.Wait()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -153,21 +200,85 @@ func (s *Server) runStdio(ctx context.Context) error {
 
 			s.logger.Debug().Str("request", line).Msg("Received request")
 
-			response, err := s.handleRequest(ctx, []byte(line))
-			if err != nil {
-				s.logger.Error().Err(err).Msg("Error handling request")
-				response = s.createErrorResponse(nil, vo.ErrorCodeInternalError, err.Error())
+			// Long-running requests run concurrently so cancellations can be read while they execute
+			data := []byte(line)
+			if runsConcurrently(data) {
+				s.wg.Add(1)
+				go func() {
+					defer s.wg.Done()
+					s.processMessage(ctx, data)
+				}()
+				continue
 			}
+			s.processMessage(ctx, data)
+		}
+	}
+}
 
-			if response != nil {
-				if err := s.sendResponse(response); err != nil {
-					s.logger.Error().Err(err).Msg("Error sending response")
-				}
-			}
+// processMessage handles a single message and writes the response, if any
+func (s *Server) processMessage(ctx context.Context, data []byte) {
+	response, err := s.handleRequest(ctx, data)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Error handling request")
+		response = s.createErrorResponse(nil, vo.ErrorCodeInternalError, err.Error())
+	}
+
+	if response != nil {
+		if err := s.sendResponse(response); err != nil {
+			s.logger.Error().Err(err).Msg("Error sending response")
 		}
 	}
 }
 
+// runsConcurrently reports whether a raw message is a tools/call or blocking tasks/result request
+func runsConcurrently(data []byte) bool {
+	var peek struct {
+		Method string `json:"method"`
+	}
+	if json.Unmarshal(data, &peek) != nil {
+		return false
+	}
+	method := vo.MCPMethod(peek.Method)
+	return method == vo.MethodToolsCall || method == vo.MethodTasksResult
+}
+
+// requestKey returns the in-flight map key for a JSON-RPC request ID
+func requestKey(id interface{}) string {
+	return fmt.Sprintf("%T:%v", id, id)
+}
+
+// trackRequest registers a cancellable context for a request until release is called
+func (s *Server) trackRequest(ctx context.Context, id interface{}) (context.Context, func()) {
+	reqCtx, cancel := context.WithCancelCause(ctx)
+	if id == nil {
+		return reqCtx, func() { cancel(nil) }
+	}
+
+	key := requestKey(id)
+	s.inFlightMu.Lock()
+	s.inFlight[key] = cancel
+	s.inFlightMu.Unlock()
+
+	return reqCtx, func() {
+		s.inFlightMu.Lock()
+		delete(s.inFlight, key)
+		s.inFlightMu.Unlock()
+		cancel(nil)
+	}
+}
+
+// cancelRequest cancels an in-flight request, reporting whether it was found
+func (s *Server) cancelRequest(id interface{}) bool {
+	s.inFlightMu.Lock()
+	cancel, ok := s.inFlight[requestKey(id)]
+	s.inFlightMu.Unlock()
+
+	if ok {
+		cancel(ErrRequestCancelled)
+	}
+	return ok
+}
+
 // JSONRPCRequest represents a JSON-RPC 2.0 request
 type JSONRPCRequest struct {
 	JSONRPC string          `json:"jsonrpc"`
@@ -217,7 +328,17 @@ func (s *Server) handleRequest(ctx context.Context, data []byte) (*JSONRPCRespon
 	}
 
 	// Handle regular methods
-	result, err := s.dispatchMethod(ctx, method, req.Params)
+	reqCtx, release := s.trackRequest(ctx, req.ID)
+	defer release()
+
+	result, err := s.dispatchMethod(reqCtx, method, req.Params)
+
+	// Cancelled requests get no response
+	if errors.Is(context.Cause(reqCtx), ErrRequestCancelled) {
+		s.logger.Debug().Interface("id", req.ID).Msg("Dropping response for cancelled request")
+		return nil, nil
+	}
+
 	if err != nil {
 		if mcpErr, ok := err.(*MCPError); ok {
 			return s.createErrorResponse(req.ID, mcpErr.Code, mcpErr.Message), nil
@@ -265,6 +386,14 @@ func (s *Server) dispatchMethod(ctx context.Context, method vo.MCPMethod, params
 		return s.handleLoggingSetLevel(ctx, params)
 	case vo.MethodCompletionComplete:
 		return s.handleCompletionComplete(ctx, params)
+	case vo.MethodTasksGet:
+		return s.handleTasksGet(ctx, params)
+	case vo.MethodTasksResult:
+		return s.handleTasksResult(ctx, params)
+	case vo.MethodTasksList:
+		return s.handleTasksList(ctx, params)
+	case vo.MethodTasksCancel:
+		return s.handleTasksCancel(ctx, params)
 	default:
 		return nil, &MCPError{Code: vo.ErrorCodeMethodNotFound, Message: "Method not found"}
 	}
@@ -276,17 +405,47 @@ func (s *Server) handleNotification(ctx context.Context, method vo.MCPMethod, pa
 	case vo.MethodInitialized:
 		s.logger.Info().Msg("Client initialized")
 	case vo.MethodNotificationsCancelled:
-		s.logger.Debug().Msg("Request cancelled")
+		var p CancelledParams
+		if err := json.Unmarshal(params, &p); err != nil || p.RequestID == nil {
+			s.logger.Debug().Msg("Invalid cancellation notification")
+			return
+		}
+		found := s.cancelRequest(p.RequestID)
+		s.logger.Debug().
+			Interface("id", p.RequestID).
+			Str("reason", p.Reason).
+			Bool("in_flight", found).
+			Msg("Request cancelled")
 	default:
 		s.logger.Debug().Str("method", method.String()).Msg("Unknown notification")
 	}
 }
 
+// CancelledParams represents notifications/cancelled parameters
+type CancelledParams struct {
+	RequestID interface{} `json:"requestId"`
+	Reason    string      `json:"reason,omitempty"`
+}
+
+// RequestMeta represents the _meta field of a request
+type RequestMeta struct {
+	ProgressToken interface{} `json:"progressToken,omitempty"`
+	// BypassCache skips the tool result cache for this call
+	BypassCache bool `json:"bypassCache,omitempty"`
+}
+
 // InitializeParams represents initialize request parameters
 type InitializeParams struct {
 	ProtocolVersion string                 `json:"protocolVersion"`
 	Capabilities    map[string]interface{} `json:"capabilities"`
 	ClientInfo      ClientInfo             `json:"clientInfo"`
+	Meta            *InitializeMeta        `json:"_meta,omitempty"`
+}
+
+// InitializeMeta represents the _meta field of an initialize request
+type InitializeMeta struct {
+	// APIKey is checked against security.allowed_api_keys
+	APIKey string `json:"apiKey,omitempty"`
 }
 
 // ClientInfo represents client information
@@ -308,13 +467,33 @@ func (s *Server) handleInitialize(ctx context.Context, params json.RawMessage) (
 		ProtocolVersion: p.ProtocolVersion,
 		Capabilities:    p.Capabilities,
 	}
+	if p.Meta != nil {
+		cmd.APIKey = p.Meta.APIKey
+	}
 
 	session, err := s.sessionHandler.HandleInitializeSession(ctx, cmd)
+	if errors.Is(err, handlers.ErrInvalidAPIKey) {
+		s.logger.Warn().Str("client", p.ClientInfo.Name).Msg("Session refused: invalid API key")
+		return nil, &MCPError{Code: vo.ErrorCodeUnauthorized, Message: "Invalid API key"}
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	if s.taskHandler != nil {
+		session.EnableTasks()
+	}
+
 	s.mu.Lock()
+	for _, resource := range s.resources {
+		session.RegisterResource(resource)
+	}
+	for _, prompt := range s.prompts {
+		session.RegisterPrompt(prompt)
+	}
+	if len(s.llmProviders) > 0 {
+		session.SetExperimental("llm", map[string]interface{}{"providers": s.llmProviders})
+	}
 	s.currentSession = session
 	s.mu.Unlock()
 
@@ -358,6 +537,13 @@ func (s *Server) handleToolsList(ctx context.Context, params json.RawMessage) (i
 type ToolCallParams struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments"`
+	Task      *TaskParams            `json:"task,omitempty"`
+	Meta      *RequestMeta           `json:"_meta,omitempty"`
+}
+
+// TaskParams requests task-augmented execution
+type TaskParams struct {
+	TTL int64 `json:"ttl,omitempty"` // Milliseconds
 }
 
 // handleToolsCall handles tools/call request
@@ -375,11 +561,29 @@ func (s *Server) handleToolsCall(ctx context.Context, params json.RawMessage) (i
 		return nil, &MCPError{Code: vo.ErrorCodeInternalError, Message: "Session not initialized"}
 	}
 
+	// Task-augmented calls return a handle immediately; without task support they run inline
+	if p.Task != nil && s.taskHandler != nil {
+		return s.submitToolTask(ctx, session.ID(), &p)
+	}
+
 	cmd := &commands.ExecuteToolCommand{
 		SessionID: session.ID(),
 		Name:      p.Name,
 		Arguments: p.Arguments,
 	}
+	if p.Meta != nil {
+		cmd.BypassCache = p.Meta.BypassCache
+	}
+
+	// Forward tool progress when the client asked for it
+	if p.Meta != nil && p.Meta.ProgressToken != nil {
+		token := p.Meta.ProgressToken
+		ctx = entities.WithProgressReporter(ctx, func(update entities.ToolProgress) {
+			if err := s.sendProgress(token, update); err != nil {
+				s.logger.Debug().Err(err).Msg("Failed to send progress notification")
+			}
+		})
+	}
 
 	result, err := s.toolHandler.HandleExecuteTool(ctx, cmd)
 	if err != nil {
@@ -543,6 +747,145 @@ func (s *Server) handleCompletionComplete(ctx context.Context, params json.RawMe
 	}, nil
 }
 
+// submitToolTask starts a tools/call as a background task
+func (s *Server) submitToolTask(ctx context.Context, sessionID vo.SessionID, p *ToolCallParams) (interface{}, error) {
+	task, err := s.taskHandler.HandleSubmitToolTask(ctx, &commands.SubmitToolTaskCommand{
+		SessionID: sessionID,
+		Name:      p.Name,
+		Arguments: p.Arguments,
+		TTL:       time.Duration(p.Task.TTL) * time.Millisecond,
+	})
+	if err != nil {
+		return nil, taskError(err)
+	}
+
+	return map[string]interface{}{"task": task.ToMCPTask()}, nil
+}
+
+// TaskIDParams represents tasks/get, tasks/result and tasks/cancel parameters
+type TaskIDParams struct {
+	TaskID string `json:"taskId"`
+}
+
+// TaskListParams represents tasks/list parameters
+type TaskListParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// relatedTaskMetaKey links a task result to its task
+const relatedTaskMetaKey = "io.modelcontextprotocol/related-task"
+
+// taskRequest parses task parameters and resolves the session
+func (s *Server) taskRequest(params json.RawMessage, target interface{}) (vo.SessionID, error) {
+	if s.taskHandler == nil {
+		return vo.SessionID{}, &MCPError{Code: vo.ErrorCodeMethodNotFound, Message: "Method not found"}
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, target); err != nil {
+			return vo.SessionID{}, &MCPError{Code: vo.ErrorCodeInvalidParams, Message: "Invalid params"}
+		}
+	}
+
+	s.mu.RLock()
+	session := s.currentSession
+	s.mu.RUnlock()
+
+	if session == nil {
+		return vo.SessionID{}, &MCPError{Code: vo.ErrorCodeInternalError, Message: "Session not initialized"}
+	}
+	return session.ID(), nil
+}
+
+// taskError maps task handler errors to MCP errors
+func taskError(err error) error {
+	switch {
+	case errors.Is(err, handlers.ErrTaskNotFound), errors.Is(err, handlers.ErrTaskAlreadyFinished):
+		return &MCPError{Code: vo.ErrorCodeInvalidParams, Message: err.Error()}
+	case errors.Is(err, handlers.ErrToolNotFound):
+		return &MCPError{Code: vo.ErrorCodeToolNotFound, Message: err.Error()}
+	}
+	return err
+}
+
+// handleTasksGet handles tasks/get request
+func (s *Server) handleTasksGet(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p TaskIDParams
+	sessionID, err := s.taskRequest(params, &p)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := s.taskHandler.HandleGetTask(ctx, &queries.GetTaskQuery{SessionID: sessionID, TaskID: p.TaskID})
+	if err != nil {
+		return nil, taskError(err)
+	}
+	return task.ToMCPTask(), nil
+}
+
+// handleTasksResult handles tasks/result request, blocking until the task finishes
+func (s *Server) handleTasksResult(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p TaskIDParams
+	sessionID, err := s.taskRequest(params, &p)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := s.taskHandler.HandleGetTaskResult(ctx, &queries.GetTaskResultQuery{
+		SessionID: sessionID,
+		TaskID:    p.TaskID,
+		Wait:      -1,
+	})
+	if err != nil {
+		return nil, taskError(err)
+	}
+
+	if task.Status() == vo.TaskStatusCancelled {
+		return nil, &MCPError{Code: vo.ErrorCodeCancelled, Message: "Task was cancelled"}
+	}
+	if task.Result() == nil {
+		return nil, &MCPError{Code: vo.ErrorCodeToolExecutionError, Message: task.StatusMessage()}
+	}
+
+	// Copy the result so the shared task result is not mutated
+	result := *task.Result()
+	result.Meta = make(map[string]interface{}, len(task.Result().Meta)+1)
+	for key, value := range task.Result().Meta {
+		result.Meta[key] = value
+	}
+	result.Meta[relatedTaskMetaKey] = map[string]interface{}{"taskId": task.ID()}
+	return &result, nil
+}
+
+// handleTasksList handles tasks/list request
+func (s *Server) handleTasksList(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p TaskListParams
+	sessionID, err := s.taskRequest(params, &p)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.taskHandler.HandleListTasks(ctx, &queries.ListTasksQuery{SessionID: sessionID, Cursor: p.Cursor})
+	if err != nil {
+		return nil, err
+	}
+	return result.ToMCPTaskList(), nil
+}
+
+// handleTasksCancel handles tasks/cancel request
+func (s *Server) handleTasksCancel(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p TaskIDParams
+	sessionID, err := s.taskRequest(params, &p)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := s.taskHandler.HandleCancelTask(ctx, &commands.CancelTaskCommand{SessionID: sessionID, TaskID: p.TaskID})
+	if err != nil {
+		return nil, taskError(err)
+	}
+	return task.ToMCPTask(), nil
+}
+
 // createErrorResponse creates an error response
 func (s *Server) createErrorResponse(id interface{}, code vo.MCPErrorCode, message string) *JSONRPCResponse {
 	return &JSONRPCResponse{
@@ -564,10 +907,27 @@ func (s *Server) sendResponse(response *JSONRPCResponse) error {
 
 	s.logger.Debug().Str("response", string(data)).Msg("Sending response")
 
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
 	_, err = fmt.Fprintf(s.writer, "%s\n", data)
 	return err
 }
 
+// sendProgress sends a notifications/progress message for a progress token
+func (s *Server) sendProgress(token interface{}, update entities.ToolProgress) error {
+	params := map[string]interface{}{
+		"progressToken": token,
+		"progress":      update.Progress,
+	}
+	if update.Total > 0 {
+		params["total"] = update.Total
+	}
+	if update.Message != "" {
+		params["message"] = update.Message
+	}
+	return s.SendNotification(vo.MethodNotificationsProgress, params)
+}
+
 // SendNotification sends a notification to the client
 func (s *Server) SendNotification(method vo.MCPMethod, params interface{}) error {
 	notification := map[string]interface{}{
@@ -583,10 +943,20 @@ func (s *Server) SendNotification(method vo.MCPMethod, params interface{}) error
 		return err
 	}
 
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
 	_, err = fmt.Fprintf(s.writer, "%s\n", data)
 	return err
 }
 
+// NotifyToolsChanged sends notifications/tools/list_changed when a client session is open
+func (s *Server) NotifyToolsChanged() error {
+	if s.Session() == nil {
+		return nil
+	}
+	return s.SendNotification(vo.MethodNotificationsToolsListChanged, nil)
+}
+
 // Session returns the current session
 func (s *Server) Session() *aggregates.Session {
 	s.mu.RLock()
@@ -0,0 +1,191 @@
+// Package tools contains built-in MCP tools for TelemetryFlow
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	appsvc "github.com/telemetryflow/telemetryflow-go-mcp/internal/application/services"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// defaultAgentTimeout applies when SetAgent is given no timeout
+const defaultAgentTimeout = 5 * time.Minute
+
+// agentInstructions tell the model to gather data with its tools rather than expect it in the prompt
+const agentInstructions = "No context section is attached to this conversation. Gather the live data you need with the available tools, " +
+	"starting with collect_telemetry_context, and call several tools at once when they are independent. " +
+	"Answer once you have enough data, citing the numbers the tools returned."
+
+// SetAgent registers analyze_telemetry, which answers a question by letting model call the
+// agent's allowed tools
+func (r *ToolRegistry) SetAgent(agent *appsvc.AgentService, model vo.Model, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultAgentTimeout
+	}
+	r.agent = agent
+	r.agentModel = model
+	r.agentTimeout = timeout
+	r.registerAnalyzeTelemetry()
+}
+
+func (r *ToolRegistry) registerAnalyzeTelemetry() {
+	name, _ := vo.NewToolName("analyze_telemetry")
+	desc, _ := vo.NewToolDescription("Answer a question about your systems by letting the LLM investigate with the server's own tools, such as collect_telemetry_context, over several steps. Returns the answer and the tool calls it made")
+
+	contextTypes := make([]interface{}, 0, len(vo.AllContextTypes()))
+	for _, ct := range vo.AllContextTypes() {
+		contextTypes = append(contextTypes, ct.String())
+	}
+
+	schema := &entities.JSONSchema{
+		Type: "object",
+		Properties: map[string]*entities.JSONSchema{
+			"question": {
+				Type:        "string",
+				Description: "The question to investigate, e.g. why checkout latency rose in the last hour",
+			},
+			"context_type": {
+				Type:        "string",
+				Description: "Analyst persona for the system prompt (default: dashboard)",
+				Enum:        contextTypes,
+			},
+			"model": {
+				Type:        "string",
+				Description: fmt.Sprintf("The LLM model to use (default: %s); it must support tool calling", r.agentModel),
+			},
+			"instructions": {
+				Type:        "string",
+				Description: "Optional additional instructions for the analysis",
+			},
+			"tools": {
+				Type:        "array",
+				Description: "Narrow the tools the model may call; only tools the server allows are used",
+				Items:       &entities.JSONSchema{Type: "string"},
+			},
+			"max_iterations": {
+				Type:        "integer",
+				Description: fmt.Sprintf("Maximum LLM calls (default and maximum: %d)", r.agent.Options().MaxIterations),
+			},
+			"include_reasoning": includeReasoningProperty(),
+		},
+		Required: []string{"question"},
+	}
+	maps.Copy(schema.Properties, reasoningProperties())
+
+	tool, _ := entities.NewTool(name, desc, schema)
+	tool.SetCategory("ai")
+	tool.SetTags([]string{"telemetry", "agent", "ai"})
+	tool.SetContextHandler(r.handleAnalyzeTelemetry)
+	tool.SetTimeout(r.agentTimeout)
+
+	r.tools["analyze_telemetry"] = tool
+}
+
+func (r *ToolRegistry) handleAnalyzeTelemetry(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+	sessionID, ok := handlers.SessionIDFromContext(ctx)
+	if !ok {
+		return entities.NewErrorToolResult(ErrNoSessionContext), nil
+	}
+
+	question, _ := input["question"].(string)
+	if question == "" {
+		return entities.NewErrorToolResult(fmt.Errorf("question is required")), nil
+	}
+
+	contextType := vo.ContextDashboard
+	if ct, ok := input["context_type"].(string); ok && ct != "" {
+		contextType = vo.ContextType(ct)
+		if !contextType.IsValid() {
+			return entities.NewErrorToolResult(fmt.Errorf("invalid context_type: %s", ct)), nil
+		}
+	}
+
+	model := r.agentModel
+	if m, ok := input["model"].(string); ok && m != "" {
+		model = vo.Model(m)
+	}
+
+	instructions := agentInstructions
+	if extra, ok := input["instructions"].(string); ok && extra != "" {
+		instructions += "\n" + extra
+	}
+	systemPrompt, err := vo.NewSystemPrompt(r.promptBuilder.BuildSystemPrompt(contextType, instructions))
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+
+	var options appsvc.AgentOptions
+	if n, ok := input["max_iterations"].(float64); ok && n > 0 {
+		options.MaxIterations = int(n)
+	}
+	if names, ok := input["tools"].([]interface{}); ok {
+		for _, name := range names {
+			if s, ok := name.(string); ok && s != "" {
+				options.AllowedTools = append(options.AllowedTools, s)
+			}
+		}
+	}
+
+	reasoning, err := reasoningInput(input)
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+
+	// The conversation lives for this call only
+	conversation := aggregates.NewConversation(sessionID, model)
+	if err := conversation.SetSystemPrompt(systemPrompt); err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+	conversation.SetReasoning(reasoning)
+
+	ctx, cancel := context.WithTimeout(ctx, r.agentTimeout)
+	defer cancel()
+
+	run, err := r.agent.Run(ctx, conversation, question, options)
+	if run == nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+
+	var result *entities.ToolResult
+	if err != nil {
+		text := fmt.Sprintf("Analysis stopped: %v", err)
+		if partial := run.Text(); partial != "" {
+			text += "\n\n" + partial
+		}
+		result = entities.NewTextToolResult(text)
+		result.IsError = true
+	} else {
+		result = entities.NewTextToolResult(run.Text())
+	}
+
+	if run.Response != nil && run.Response.ServedBy != "" {
+		result.SetMeta("model", run.Response.ServedBy.String())
+	}
+	result.SetMeta("agent", run)
+	setReasoningMeta(result, input, strings.Join(run.Reasoning, "\n\n"))
+	return result, nil
+}
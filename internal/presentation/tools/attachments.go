@@ -0,0 +1,199 @@
+// Package tools contains built-in MCP tools for TelemetryFlow
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/resources"
+)
+
+// ErrInvalidAttachment is returned for attachments that cannot be read
+var ErrInvalidAttachment = errors.New("invalid attachment")
+
+// attachmentsProperty is the schema of the attachments input
+func attachmentsProperty() *entities.JSONSchema {
+	return &entities.JSONSchema{
+		Type:        "array",
+		Description: "Images (PNG, JPEG, GIF, WebP, up to 5 MB) and PDF documents (up to 32 MB) for the model to read, such as dashboard screenshots or exported reports. Each gives exactly one of data, path or uri. Models without vision or PDF support refuse them",
+		Items: &entities.JSONSchema{
+			Type: "object",
+			Properties: map[string]*entities.JSONSchema{
+				"data": {
+					Type:        "string",
+					Description: "Base64 content; requires media_type",
+				},
+				"media_type": {
+					Type:        "string",
+					Description: "MIME type of data: image/png, image/jpeg, image/gif, image/webp or application/pdf",
+				},
+				"path": {
+					Type:        "string",
+					Description: "Path of a file within the server's allowed paths (mcp.allowed_paths)",
+				},
+				"uri": {
+					Type:        "string",
+					Description: "URI of an MCP resource, such as file:///reports/latency.pdf",
+				},
+				"name": {
+					Type:        "string",
+					Description: "File name of a document (default: the file name of path or uri)",
+				},
+			},
+		},
+	}
+}
+
+// RegisterResource lets attachments refer to resource by its URI
+func (r *ToolRegistry) RegisterResource(resource *entities.Resource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.resources == nil {
+		r.resources = make(map[string]*entities.Resource)
+	}
+	r.resources[resource.URI().String()] = resource
+}
+
+// attachmentsInput reads the attachments input as image and document blocks
+func (r *ToolRegistry) attachmentsInput(ctx context.Context, input map[string]interface{}) ([]entities.ContentBlock, error) {
+	items, _ := input["attachments"].([]interface{})
+	blocks := make([]entities.ContentBlock, 0, len(items))
+	for i, item := range items {
+		attachment, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: attachments[%d] is not an object", ErrInvalidAttachment, i)
+		}
+		block, err := r.attachment(ctx, attachment)
+		if err != nil {
+			return nil, fmt.Errorf("attachments[%d]: %w", i, err)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// attachment reads one attachment from its data, path or uri
+func (r *ToolRegistry) attachment(ctx context.Context, attachment map[string]interface{}) (entities.ContentBlock, error) {
+	data, _ := attachment["data"].(string)
+	path, _ := attachment["path"].(string)
+	uri, _ := attachment["uri"].(string)
+	mediaType, _ := attachment["media_type"].(string)
+	name, _ := attachment["name"].(string)
+
+	given := 0
+	for _, source := range []string{data, path, uri} {
+		if source != "" {
+			given++
+		}
+	}
+	if given != 1 {
+		return entities.ContentBlock{}, fmt.Errorf("%w: give exactly one of data, path or uri", ErrInvalidAttachment)
+	}
+
+	var content []byte
+	switch {
+	case data != "":
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return entities.ContentBlock{}, fmt.Errorf("%w: data is not base64: %v", ErrInvalidAttachment, err)
+		}
+		content = decoded
+	case path != "":
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return entities.ContentBlock{}, err
+		}
+		uri = "file://" + absPath
+		fallthrough
+	default:
+		var err error
+		if content, mediaType, err = r.readResource(ctx, uri, mediaType); err != nil {
+			return entities.ContentBlock{}, err
+		}
+		if name == "" {
+			name = filepath.Base(strings.TrimPrefix(uri, "file://"))
+		}
+	}
+
+	return entities.NewMediaBlock(detectMediaType(mediaType, content), content, name)
+}
+
+// readResource reads a file:// URI within the allowed paths, or a registered resource, returning
+// its content and media type; declared takes precedence over the resource's media type
+func (r *ToolRegistry) readResource(ctx context.Context, uri, declared string) ([]byte, string, error) {
+	if strings.HasPrefix(uri, "file://") {
+		content, err := r.resourceHandler.ReadResource(ctx, uri)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", uri, err)
+		}
+		if declared == "" {
+			declared = content.MimeType
+		}
+		return []byte(content.Text), declared, nil
+	}
+
+	r.mu.RLock()
+	resource, ok := r.resources[uri]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %s", resources.ErrResourceNotFound, uri)
+	}
+	content, err := resource.Read()
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", uri, err)
+	}
+	if declared == "" {
+		declared = content.MimeType
+	}
+	if content.Blob == "" {
+		return []byte(content.Text), declared, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(content.Blob)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s blob is not base64: %v", ErrInvalidAttachment, uri, err)
+	}
+	return data, declared, nil
+}
+
+// detectMediaType returns the declared media type when images or documents may have it, or else
+// the type sniffed from the content
+func detectMediaType(declared string, content []byte) string {
+	if parsed, _, err := mime.ParseMediaType(declared); err == nil {
+		if _, err := vo.MediaContentType(parsed); err == nil {
+			return parsed
+		}
+	}
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(content))
+	if _, err := vo.MediaContentType(sniffed); err == nil {
+		return sniffed
+	}
+	if declared != "" {
+		return declared
+	}
+	return sniffed
+}
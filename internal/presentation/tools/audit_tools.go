@@ -0,0 +1,166 @@
+// Package tools contains built-in MCP tools for TelemetryFlow
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/queries"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// defaultAuditSearchLimit applies when search_audit_trail sets no limit
+const defaultAuditSearchLimit = 50
+
+// SetAuditHandler registers the audit trail search tool backed by handler
+func (r *ToolRegistry) SetAuditHandler(handler *handlers.AuditHandler) {
+	r.auditHandler = handler
+	r.registerSearchAuditTrail()
+}
+
+func (r *ToolRegistry) registerSearchAuditTrail() {
+	name, _ := vo.NewToolName("search_audit_trail")
+	desc, _ := vo.NewToolDescription("Search the audit trail of tool calls by session, tool, API key, status and time range. Returns the newest calls first")
+
+	schema := &entities.JSONSchema{
+		Type: "object",
+		Properties: map[string]*entities.JSONSchema{
+			"session_id": {
+				Type:        "string",
+				Description: "Only calls from this session",
+			},
+			"tool": {
+				Type:        "string",
+				Description: "Only calls to this tool",
+			},
+			"api_key_id": {
+				Type:        "string",
+				Description: "Only calls made with this API key ID",
+			},
+			"status": {
+				Type:        "string",
+				Description: "Only calls with this outcome",
+				Enum: []interface{}{
+					string(entities.ToolExecutionSuccess), string(entities.ToolExecutionError),
+					string(entities.ToolExecutionTimeout), string(entities.ToolExecutionCancelled),
+					string(entities.ToolExecutionRejected),
+				},
+			},
+			"since": {
+				Type:        "string",
+				Description: "Start of the time range, as RFC 3339 or a duration ago such as 24h",
+			},
+			"until": {
+				Type:        "string",
+				Description: "End of the time range, as RFC 3339 or a duration ago (default: now)",
+			},
+			"limit": {
+				Type:        "integer",
+				Description: fmt.Sprintf("Maximum number of calls to return (default: %d, max: %d)", defaultAuditSearchLimit, handlers.MaxAuditSearchLimit),
+			},
+		},
+	}
+
+	tool, _ := entities.NewTool(name, desc, schema)
+	tool.SetCategory("audit")
+	tool.SetTags([]string{"audit", "security", "tools"})
+	tool.SetContextHandler(r.handleSearchAuditTrail)
+
+	r.tools["search_audit_trail"] = tool
+}
+
+func (r *ToolRegistry) handleSearchAuditTrail(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+	query := &queries.SearchToolExecutionsQuery{Limit: defaultAuditSearchLimit}
+	query.SessionID, _ = input["session_id"].(string)
+	query.ToolName, _ = input["tool"].(string)
+	query.APIKeyID, _ = input["api_key_id"].(string)
+	query.Status, _ = input["status"].(string)
+	if limit, ok := input["limit"].(float64); ok && limit > 0 {
+		query.Limit = int(limit)
+	}
+
+	now := time.Now()
+	var err error
+	if query.Since, err = parseAuditTime(input["since"], now); err != nil {
+		return entities.NewErrorToolResult(fmt.Errorf("since: %w", err)), nil
+	}
+	if query.Until, err = parseAuditTime(input["until"], now); err != nil {
+		return entities.NewErrorToolResult(fmt.Errorf("until: %w", err)), nil
+	}
+
+	executions, err := r.auditHandler.HandleSearchToolExecutions(ctx, query)
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+
+	records := make([]map[string]interface{}, 0, len(executions))
+	for _, e := range executions {
+		record := map[string]interface{}{
+			"id":         e.ID,
+			"sessionId":  e.SessionID,
+			"tool":       e.ToolName,
+			"arguments":  e.Arguments,
+			"status":     e.Status,
+			"durationMs": e.Duration.Milliseconds(),
+			"executedAt": e.ExecutedAt.UTC().Format(time.RFC3339Nano),
+		}
+		if e.ClientName != "" {
+			record["client"] = map[string]string{"name": e.ClientName, "version": e.ClientVersion}
+		}
+		if e.APIKeyID != "" {
+			record["apiKeyId"] = e.APIKeyID
+		}
+		if e.ErrorMessage != "" {
+			record["error"] = e.ErrorMessage
+		}
+		records = append(records, record)
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"count":      len(records),
+		"executions": records,
+	}, "", "  ")
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+	return entities.NewTextToolResult(string(data)), nil
+}
+
+// parseAuditTime accepts an RFC 3339 timestamp or a duration before now; absent values give the zero time
+func parseAuditTime(value interface{}, now time.Time) (time.Time, error) {
+	s, _ := value.(string)
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 time nor a positive duration", s)
+	}
+	return now.Add(-d), nil
+}
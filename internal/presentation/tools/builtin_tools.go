@@ -22,32 +22,59 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
 	appsvc "github.com/telemetryflow/telemetryflow-go-mcp/internal/application/services"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
 	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/resources"
 )
 
+// localModelDiscoveryTimeout bounds one local model discovery round
+const localModelDiscoveryTimeout = 10 * time.Second
+
 // ToolRegistry manages built-in tools
 type ToolRegistry struct {
 	claudeService    services.IClaudeService
 	contextCollector *appsvc.ContextCollector
 	promptBuilder    *appsvc.PromptBuilder
-	tools            map[string]*entities.Tool
+	resourceHandler  *resources.ResourceHandler
+	commandPolicy    config.CommandPolicyConfig
+	taskHandler      *handlers.TaskHandler
+	auditHandler     *handlers.AuditHandler
+	usageHandler     *handlers.UsageHandler
+	budgetHandler    *handlers.BudgetHandler
+	modelAliases     []vo.Model
+	localModels      []vo.Model
+	agent            *appsvc.AgentService
+	agentModel       vo.Model
+	agentTimeout     time.Duration
+
+	conversationHandler *handlers.ConversationHandler
+
+	mu        sync.RWMutex
+	tools     map[string]*entities.Tool
+	resources map[string]*entities.Resource // registered resources that attachments may refer to
 }
 
 // NewToolRegistry creates a new tool registry
 func NewToolRegistry(claudeService services.IClaudeService) *ToolRegistry {
 	registry := &ToolRegistry{
-		claudeService: claudeService,
-		promptBuilder: appsvc.NewPromptBuilder(),
-		tools:         make(map[string]*entities.Tool),
+		claudeService:   claudeService,
+		promptBuilder:   appsvc.NewPromptBuilder(),
+		resourceHandler: resources.NewResourceHandler(nil, resources.DefaultMaxFileSize),
+		commandPolicy:   config.DefaultCommandPolicy(),
+		tools:           make(map[string]*entities.Tool),
 	}
 
 	// Register built-in tools
@@ -62,6 +89,8 @@ func NewToolRegistryWithCollector(claudeService services.IClaudeService, collect
 		claudeService:    claudeService,
 		contextCollector: collector,
 		promptBuilder:    appsvc.NewPromptBuilder(),
+		resourceHandler:  resources.NewResourceHandler(nil, resources.DefaultMaxFileSize),
+		commandPolicy:    config.DefaultCommandPolicy(),
 		tools:            make(map[string]*entities.Tool),
 	}
 
@@ -70,8 +99,23 @@ func NewToolRegistryWithCollector(claudeService services.IClaudeService, collect
 	return registry
 }
 
+// SetResourceHandler sets the resource handler used for file size limits and MIME detection
+func (r *ToolRegistry) SetResourceHandler(handler *resources.ResourceHandler) {
+	r.resourceHandler = handler
+}
+
+// SetCommandPolicy sets the policy enforced by execute_command
+func (r *ToolRegistry) SetCommandPolicy(policy config.CommandPolicyConfig) {
+	r.commandPolicy = policy
+	if tool, ok := r.tools["execute_command"]; ok {
+		tool.SetTimeout(maxCommandTimeout(policy) + commandWaitDelay)
+	}
+}
+
 // GetTools returns all registered tools
 func (r *ToolRegistry) GetTools() []*entities.Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	tools := make([]*entities.Tool, 0, len(r.tools))
 	for _, tool := range r.tools {
 		tools = append(tools, tool)
@@ -81,6 +125,8 @@ func (r *ToolRegistry) GetTools() []*entities.Tool {
 
 // GetTool returns a tool by name
 func (r *ToolRegistry) GetTool(name string) (*entities.Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	tool, ok := r.tools[name]
 	return tool, ok
 }
@@ -93,6 +139,7 @@ func (r *ToolRegistry) registerBuiltinTools() {
 	// File tools
 	r.registerReadFile()
 	r.registerWriteFile()
+	r.registerEditFile()
 	r.registerListDirectory()
 
 	// Shell tool
@@ -111,12 +158,100 @@ func (r *ToolRegistry) registerBuiltinTools() {
 	r.registerCollectTelemetryContext()
 	r.registerListContextTypes()
 	r.registerBuildSystemPrompt()
+	r.registerInvestigateTelemetry()
+}
+
+// conversationModels are the catalog models offered by claude_conversation
+var conversationModels = []string{
+	"claude-opus-4-7", "claude-opus-4-7-fast", "claude-opus-4-6", "claude-opus-4-6-fast",
+	"claude-sonnet-4-6", "claude-opus-4-5", "claude-sonnet-4-5-20250929",
+	"claude-haiku-4-5", "claude-haiku-4-5-20251001", "claude-sonnet-4-20250514",
+	"claude-mythos-preview",
+	"gemini-3.5-flash", "gemini-2.5-pro", "gemini-2.5-flash",
+	"gpt-5.5-pro", "gpt-5.5", "gpt-5.4-pro", "gpt-5.4", "o3",
+	"deepseek-v4-pro", "deepseek-v4-flash", "deepseek-chat", "deepseek-reasoner",
+	"qwen3.6-max-preview", "qwen3.6-plus", "qwen3.6-flash",
+	"mistral-medium-3-5", "mistral-small-2603", "mistral-large-2512",
+	"grok-4.3", "grok-4.20-multi-agent", "grok-4.20-0309-reasoning",
+	"kimi-k2.6", "kimi-k2.5", "kimi-k2-thinking",
+	"glm-5.1", "glm-5-turbo", "glm-4.7-flash",
+	"mimo-v2.5-pro", "mimo-v2.5", "mimo-v2-pro",
+}
+
+// conversationModelEnum returns the model aliases, the catalog models and the discovered local models
+func (r *ToolRegistry) conversationModelEnum() []interface{} {
+	enum := make([]interface{}, 0, len(r.modelAliases)+len(conversationModels)+len(r.localModels))
+	for _, alias := range r.modelAliases {
+		enum = append(enum, alias.String())
+	}
+	for _, model := range conversationModels {
+		enum = append(enum, model)
+	}
+	for _, model := range r.localModels {
+		enum = append(enum, model.String())
+	}
+	return enum
+}
+
+// SetModelAliases offers the configured model aliases, each served by a fallback chain, in the
+// claude_conversation model enum
+func (r *ToolRegistry) SetModelAliases(aliases []vo.Model) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.modelAliases = slices.Clone(aliases)
+	r.registerClaudeConversation()
+}
+
+// SetLocalModels offers the discovered local models in the claude_conversation model enum.
+// It returns the rebuilt tool to re-register, or false when the models are unchanged
+func (r *ToolRegistry) SetLocalModels(models []vo.Model) (*entities.Tool, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if slices.Equal(r.localModels, models) {
+		return nil, false
+	}
+	r.localModels = slices.Clone(models)
+	r.registerClaudeConversation()
+	return r.tools["claude_conversation"], true
+}
+
+// LocalModelLister lists the models installed on the local LLM providers
+type LocalModelLister interface {
+	LocalModels(ctx context.Context) ([]vo.Model, error)
+}
+
+// DiscoverLocalModels lists the local models and offers them in claude_conversation. It returns the
+// rebuilt tool when the models changed; on error the enum is kept
+func (r *ToolRegistry) DiscoverLocalModels(ctx context.Context, lister LocalModelLister) (*entities.Tool, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, localModelDiscoveryTimeout)
+	defer cancel()
+
+	models, err := lister.LocalModels(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	tool, changed := r.SetLocalModels(models)
+	return tool, changed, nil
+}
+
+// RefreshLocalModels re-discovers the local models and re-registers claude_conversation in repo
+// when they changed. It reports whether the tool list changed
+func (r *ToolRegistry) RefreshLocalModels(ctx context.Context, lister LocalModelLister, repo repositories.IToolRepository) (bool, error) {
+	tool, changed, err := r.DiscoverLocalModels(ctx, lister)
+	if err != nil || !changed {
+		return false, err
+	}
+	if err := repo.Register(ctx, tool); err != nil {
+		return false, fmt.Errorf("re-registering claude_conversation: %w", err)
+	}
+	return true, nil
 }
 
 // registerClaudeConversation registers the Claude conversation tool
 func (r *ToolRegistry) registerClaudeConversation() {
 	name, _ := vo.NewToolName("claude_conversation")
-	desc, _ := vo.NewToolDescription("Send a message to Claude and receive a response. Use this for AI-powered assistance, code generation, analysis, and general conversation.")
+	desc, _ := vo.NewToolDescription("Send a message to Claude and receive a response. Use this for AI-powered assistance, code generation, analysis, and general conversation. The response text streams as progress notifications when the call carries a progress token.")
 
 	schema := &entities.JSONSchema{
 		Type: "object",
@@ -129,44 +264,37 @@ func (r *ToolRegistry) registerClaudeConversation() {
 				Type:        "string",
 				Description: "Optional system prompt to set context",
 			},
+			"context": {
+				Type:        "string",
+				Description: "Optional reference material, such as collected telemetry context, sent before the message and cached across calls that share it",
+			},
 			"model": {
 				Type:        "string",
-				Description: "The LLM model to use (default: claude-opus-4-7). Supported: Anthropic Claude, Google Gemini, OpenAI GPT/o, DeepSeek, Qwen, Mistral, Grok, Kimi, Zhipu GLM, Xiaomi MiMo",
-				Enum: []interface{}{
-					"claude-opus-4-7", "claude-opus-4-7-fast", "claude-opus-4-6", "claude-opus-4-6-fast",
-					"claude-sonnet-4-6", "claude-opus-4-5", "claude-sonnet-4-5-20250929",
-					"claude-haiku-4-5", "claude-haiku-4-5-20251001", "claude-sonnet-4-20250514",
-					"claude-mythos-preview",
-					"gemini-3.5-flash", "gemini-2.5-pro", "gemini-2.5-flash",
-					"gpt-5.5-pro", "gpt-5.5", "gpt-5.4-pro", "gpt-5.4", "o3",
-					"deepseek-v4-pro", "deepseek-v4-flash", "deepseek-chat", "deepseek-reasoner",
-					"qwen3.6-max-preview", "qwen3.6-plus", "qwen3.6-flash",
-					"mistral-medium-3-5", "mistral-small-2603", "mistral-large-2512",
-					"grok-4.3", "grok-4.20-multi-agent", "grok-4.20-0309-reasoning",
-					"kimi-k2.6", "kimi-k2.5", "kimi-k2-thinking",
-					"glm-5.1", "glm-5-turbo", "glm-4.7-flash",
-					"mimo-v2.5-pro", "mimo-v2.5", "mimo-v2-pro",
-				},
+				Description: "The LLM model to use (default: claude-opus-4-7). Supported: Anthropic Claude, Google Gemini, OpenAI GPT/o, DeepSeek, Qwen, Mistral, Grok, Kimi, Zhipu GLM, Xiaomi MiMo, and installed local models (ollama/<name>, local/<name>), and configured aliases that fall back across models",
+				Enum:        r.conversationModelEnum(),
 			},
 			"max_tokens": {
 				Type:        "integer",
 				Description: "Maximum tokens in the response (default: 4096)",
 			},
+			"attachments":       attachmentsProperty(),
+			"include_reasoning": includeReasoningProperty(),
 		},
 		Required: []string{"message"},
 	}
+	maps.Copy(schema.Properties, reasoningProperties())
 
 	tool, _ := entities.NewTool(name, desc, schema)
 	tool.SetCategory("ai")
 	tool.SetTags([]string{"claude", "conversation", "ai"})
-	tool.SetHandler(r.handleClaudeConversation)
+	tool.SetContextHandler(r.handleClaudeConversation)
 	tool.SetTimeout(120 * time.Second)
 
 	r.tools["claude_conversation"] = tool
 }
 
 // handleClaudeConversation handles Claude conversation requests
-func (r *ToolRegistry) handleClaudeConversation(input map[string]interface{}) (*entities.ToolResult, error) {
+func (r *ToolRegistry) handleClaudeConversation(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
 	message, ok := input["message"].(string)
 	if !ok || message == "" {
 		return entities.NewErrorToolResult(fmt.Errorf("message is required")), nil
@@ -188,44 +316,50 @@ func (r *ToolRegistry) handleClaudeConversation(input map[string]interface{}) (*
 		systemPrompt, _ = vo.NewSystemPrompt(sp)
 	}
 
+	reasoning, err := reasoningInput(input)
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+	attachments, err := r.attachmentsInput(ctx, input)
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+
+	// Context comes first, so that calls sharing it share a cached prefix
+	var content []entities.ContentBlock
+	if contextText, ok := input["context"].(string); ok && strings.TrimSpace(contextText) != "" {
+		content = append(content, entities.ContentBlock{Type: vo.ContentTypeText, Text: contextText, CacheBreakpoint: true})
+	}
+	content = append(content, attachments...)
+	content = append(content, entities.ContentBlock{Type: vo.ContentTypeText, Text: message})
+
 	request := &services.ClaudeRequest{
 		Model:        model,
 		SystemPrompt: systemPrompt,
-		Messages: []services.ClaudeMessage{
-			{
-				Role: vo.RoleUser,
-				Content: []entities.ContentBlock{
-					{Type: vo.ContentTypeText, Text: message},
-				},
-			},
-		},
-		MaxTokens: maxTokens,
+		Messages:     []services.ClaudeMessage{{Role: vo.RoleUser, Content: content}},
+		MaxTokens:    maxTokens,
+		Reasoning:    reasoning,
 	}
 
-	// Call Claude API
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	// Call Claude API; cancelling the call aborts the stream
+	ctx, cancel := context.WithTimeout(ctx, 120*time.Second)
 	defer cancel()
 
-	response, err := r.claudeService.CreateMessage(ctx, request)
+	response, err := streamMessage(ctx, r.claudeService, request)
 	if err != nil {
 		return entities.NewErrorToolResult(err), nil
 	}
 
-	// Extract text content
-	var text string
-	for _, block := range response.Content {
-		if block.Type == vo.ContentTypeText {
-			text += block.Text
-		}
-	}
-
-	return entities.NewTextToolResult(text), nil
+	result := entities.NewTextToolResult(responseText(response))
+	setResponseMeta(result, response)
+	setReasoningMeta(result, input, entities.ReasoningText(response.Content))
+	return result, nil
 }
 
 // registerReadFile registers the read file tool
 func (r *ToolRegistry) registerReadFile() {
 	name, _ := vo.NewToolName("read_file")
-	desc, _ := vo.NewToolDescription("Read the contents of a file at the specified path")
+	desc, _ := vo.NewToolDescription("Read the contents of a file at the specified path. Supports line or byte ranges, charset decoding, and returns binary files as base64 content.")
 
 	schema := &entities.JSONSchema{
 		Type: "object",
@@ -236,7 +370,27 @@ func (r *ToolRegistry) registerReadFile() {
 			},
 			"encoding": {
 				Type:        "string",
-				Description: "The encoding to use (default: utf-8)",
+				Description: "The encoding to use (default: utf-8). Use base64 to force binary output",
+				Enum: []interface{}{
+					"utf-8", "latin1", "iso-8859-1", "windows-1252",
+					"utf-16", "utf-16le", "utf-16be", "base64",
+				},
+			},
+			"offset": {
+				Type:        "integer",
+				Description: "Line number to start reading from (1-based, default: 1)",
+			},
+			"limit": {
+				Type:        "integer",
+				Description: "Maximum number of lines to read (default: all)",
+			},
+			"byte_offset": {
+				Type:        "integer",
+				Description: "Byte position to start reading from. Takes precedence over line ranges",
+			},
+			"byte_length": {
+				Type:        "integer",
+				Description: "Maximum number of bytes to read from byte_offset",
 			},
 		},
 		Required: []string{"path"},
@@ -245,12 +399,12 @@ func (r *ToolRegistry) registerReadFile() {
 	tool, _ := entities.NewTool(name, desc, schema)
 	tool.SetCategory("file")
 	tool.SetTags([]string{"file", "read"})
-	tool.SetHandler(handleReadFile)
+	tool.SetHandler(r.handleReadFile)
 
 	r.tools["read_file"] = tool
 }
 
-func handleReadFile(input map[string]interface{}) (*entities.ToolResult, error) {
+func (r *ToolRegistry) handleReadFile(input map[string]interface{}) (*entities.ToolResult, error) {
 	path, ok := input["path"].(string)
 	if !ok || path == "" {
 		return entities.NewErrorToolResult(fmt.Errorf("path is required")), nil
@@ -262,12 +416,12 @@ func handleReadFile(input map[string]interface{}) (*entities.ToolResult, error)
 		return entities.NewErrorToolResult(err), nil
 	}
 
-	content, err := os.ReadFile(absPath) //nolint:gosec // G304: path is sanitized via filepath.Abs
+	opts, err := parseReadFileOptions(input)
 	if err != nil {
 		return entities.NewErrorToolResult(err), nil
 	}
 
-	return entities.NewTextToolResult(string(content)), nil
+	return readFile(absPath, opts, r.resourceHandler), nil
 }
 
 // registerWriteFile registers the write file tool
@@ -326,13 +480,100 @@ func handleWriteFile(input map[string]interface{}) (*entities.ToolResult, error)
 		}
 	}
 
-	if err := os.WriteFile(absPath, []byte(content), 0600); err != nil {
+	// Write through symlinks so the link itself is not replaced by the rename
+	target := absPath
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		target = resolved
+	}
+
+	if err := atomicWriteFile(target, []byte(content), 0600); err != nil {
 		return entities.NewErrorToolResult(err), nil
 	}
 
 	return entities.NewTextToolResult(fmt.Sprintf("Successfully wrote %d bytes to %s", len(content), absPath)), nil
 }
 
+// registerEditFile registers the edit file tool
+func (r *ToolRegistry) registerEditFile() {
+	name, _ := vo.NewToolName("edit_file")
+	desc, _ := vo.NewToolDescription("Edit a file in place using exact search/replace blocks or a unified diff. Writes are atomic and preserve file permissions; dry_run returns the resulting diff without writing.")
+
+	schema := &entities.JSONSchema{
+		Type: "object",
+		Properties: map[string]*entities.JSONSchema{
+			"path": {
+				Type:        "string",
+				Description: "The path to the file to edit",
+			},
+			"edits": {
+				Type:        "array",
+				Description: "Search/replace blocks applied in order. Each old_text must match exactly once unless replace_all is set",
+				Items: &entities.JSONSchema{
+					Type: "object",
+					Properties: map[string]*entities.JSONSchema{
+						"old_text": {
+							Type:        "string",
+							Description: "The exact text to replace",
+						},
+						"new_text": {
+							Type:        "string",
+							Description: "The replacement text",
+						},
+						"replace_all": {
+							Type:        "boolean",
+							Description: "Replace every occurrence instead of requiring a unique match",
+						},
+					},
+					Required: []string{"old_text", "new_text"},
+				},
+			},
+			"patch": {
+				Type:        "string",
+				Description: "A unified diff to apply to the file. Mutually exclusive with edits",
+			},
+			"fuzz": {
+				Type:        "integer",
+				Description: "Maximum context lines a patch hunk may ignore when it does not match exactly (default: 2)",
+			},
+			"dry_run": {
+				Type:        "boolean",
+				Description: "Return the resulting diff without modifying the file",
+			},
+			"backup": {
+				Type:        "boolean",
+				Description: "Keep a copy of the original file with a .bak suffix",
+			},
+		},
+		Required: []string{"path"},
+	}
+
+	tool, _ := entities.NewTool(name, desc, schema)
+	tool.SetCategory("file")
+	tool.SetTags([]string{"file", "edit", "patch"})
+	tool.SetHandler(handleEditFile)
+
+	r.tools["edit_file"] = tool
+}
+
+func handleEditFile(input map[string]interface{}) (*entities.ToolResult, error) {
+	path, ok := input["path"].(string)
+	if !ok || path == "" {
+		return entities.NewErrorToolResult(fmt.Errorf("path is required")), nil
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+
+	opts, err := parseEditFileOptions(input)
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+
+	return editFile(absPath, opts), nil
+}
+
 // registerListDirectory registers the list directory tool
 func (r *ToolRegistry) registerListDirectory() {
 	name, _ := vo.NewToolName("list_directory")
@@ -392,7 +633,7 @@ func handleListDirectory(input map[string]interface{}) (*entities.ToolResult, er
 // registerExecuteCommand registers the execute command tool
 func (r *ToolRegistry) registerExecuteCommand() {
 	name, _ := vo.NewToolName("execute_command")
-	desc, _ := vo.NewToolDescription("Execute a shell command and return the output")
+	desc, _ := vo.NewToolDescription("Execute a shell command subject to the server's command policy and return the exit code, stdout and stderr. Output is streamed as progress notifications when the call includes a progress token")
 
 	schema := &entities.JSONSchema{
 		Type: "object",
@@ -403,11 +644,11 @@ func (r *ToolRegistry) registerExecuteCommand() {
 			},
 			"working_dir": {
 				Type:        "string",
-				Description: "The working directory for the command",
+				Description: "The working directory for the command, within the allowed directories when the policy sets them",
 			},
 			"timeout": {
 				Type:        "integer",
-				Description: "Timeout in seconds (default: 30)",
+				Description: "Timeout in seconds (default: 30, capped by the command policy)",
 			},
 		},
 		Required: []string{"command"},
@@ -416,41 +657,38 @@ func (r *ToolRegistry) registerExecuteCommand() {
 	tool, _ := entities.NewTool(name, desc, schema)
 	tool.SetCategory("system")
 	tool.SetTags([]string{"command", "shell", "execute"})
-	tool.SetHandler(handleExecuteCommand)
-	tool.SetTimeout(60 * time.Second)
+	tool.SetContextHandler(r.handleExecuteCommand)
+	tool.SetTimeout(maxCommandTimeout(r.commandPolicy) + commandWaitDelay)
 
 	r.tools["execute_command"] = tool
 }
 
-func handleExecuteCommand(input map[string]interface{}) (*entities.ToolResult, error) {
+func (r *ToolRegistry) handleExecuteCommand(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
 	command, ok := input["command"].(string)
 	if !ok || command == "" {
 		return entities.NewErrorToolResult(fmt.Errorf("command is required")), nil
 	}
 
-	timeout := 30
-	if t, ok := input["timeout"].(float64); ok {
-		timeout = int(t)
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
-	defer cancel()
+	policy := r.commandPolicy
 
-	cmd := exec.CommandContext(ctx, "sh", "-c", command) //nolint:gosec // G204: command execution is intentional for shell tool
+	argv, err := buildCommandArgv(command, policy)
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
 
-	if workingDir, ok := input["working_dir"].(string); ok && workingDir != "" {
-		cmd.Dir = workingDir
+	workingDir, _ := input["working_dir"].(string)
+	dir, err := resolveWorkingDir(workingDir, policy.AllowedDirs)
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
 	}
 
-	output, err := cmd.CombinedOutput()
+	timeout := commandTimeout(input, policy)
+	result, err := runCommand(ctx, argv, dir, timeout, policy)
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return entities.NewErrorToolResult(fmt.Errorf("command timed out after %d seconds", timeout)), nil
-		}
-		return entities.NewTextToolResult(fmt.Sprintf("Command failed: %s\nOutput: %s", err.Error(), string(output))), nil
+		return entities.NewErrorToolResult(err), nil
 	}
 
-	return entities.NewTextToolResult(string(output)), nil
+	return result.toToolResult(timeout), nil
 }
 
 // registerSearchFiles registers the search files tool
@@ -830,3 +1068,76 @@ func (r *ToolRegistry) handleBuildSystemPrompt(input map[string]interface{}) (*e
 
 	return entities.NewTextToolResult(prompt), nil
 }
+
+// registerInvestigateTelemetry registers a pipeline that collects telemetry context and asks Claude about it
+func (r *ToolRegistry) registerInvestigateTelemetry() {
+	var contextTypes []interface{}
+	for _, ct := range vo.AllContextTypes() {
+		contextTypes = append(contextTypes, string(ct))
+	}
+
+	tool, err := NewPipelineTool(config.PipelineConfig{
+		Name:        "investigate_telemetry",
+		Description: "Collect live telemetry context, build the matching system prompt and ask Claude a question about it in a single call",
+		Category:    "telemetry",
+		Tags:        []string{"telemetry", "ai", "telemetryflow"},
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"organization_id": map[string]interface{}{"type": "string", "description": "The organization ID to collect context for"},
+				"context_type":    map[string]interface{}{"type": "string", "description": "The type of telemetry context to collect", "enum": contextTypes},
+				"question":        map[string]interface{}{"type": "string", "description": "The question to ask about the collected telemetry"},
+				"instructions":    map[string]interface{}{"type": "string", "description": "Optional additional instructions for the system prompt"},
+			},
+			"required": []interface{}{"organization_id", "context_type", "question"},
+		},
+		Steps: []config.PipelineStepConfig{
+			{
+				ID:   "collect",
+				Tool: "collect_telemetry_context",
+				Arguments: map[string]interface{}{
+					"organization_id": "{{.input.organization_id}}",
+					"context_type":    "{{.input.context_type}}",
+				},
+			},
+			{
+				ID:   "prompt",
+				Tool: "build_system_prompt",
+				Arguments: map[string]interface{}{
+					"context_type":  "{{.input.context_type}}",
+					"custom_prompt": "{{.input.instructions}}",
+				},
+			},
+			{
+				ID:        "ask",
+				Tool:      "claude_conversation",
+				DependsOn: []string{"collect", "prompt"},
+				Arguments: map[string]interface{}{
+					"context":       "{{.steps.collect.json.context_prompt}}",
+					"message":       "{{.input.question}}",
+					"system_prompt": "{{.steps.prompt.text}}",
+				},
+			},
+		},
+		Timeout: 3 * time.Minute,
+	}, r.lookupTool)
+	if err != nil {
+		return
+	}
+
+	r.tools["investigate_telemetry"] = tool
+}
+
+// lookupTool resolves pipeline steps against the registry's own tools
+func (r *ToolRegistry) lookupTool(ctx context.Context, name string) (*entities.Tool, error) {
+	r.mu.RLock()
+	tool, ok := r.tools[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("tool %s not found", name)
+	}
+	if !tool.IsEnabled() {
+		return nil, fmt.Errorf("tool %s is disabled", name)
+	}
+	return tool, nil
+}
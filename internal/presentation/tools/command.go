@@ -0,0 +1,517 @@
+// Package tools contains built-in MCP tools for TelemetryFlow
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+)
+
+// Command policy errors
+var (
+	ErrCommandDenied          = errors.New("command denied by policy")
+	ErrCommandSubstitution    = errors.New("command substitution is not allowed by the command policy")
+	ErrShellOperatorsDisabled = errors.New("shell operators require security.command.use_shell")
+	ErrUnterminatedQuote      = errors.New("unterminated quote in command")
+	ErrWorkingDirDenied       = errors.New("working directory is outside the allowed directories")
+	ErrChangeDirDenied        = errors.New("changing directory is not allowed while security.command.allowed_dirs is set")
+)
+
+// commandWaitDelay bounds how long output pipes are drained after the process is killed
+const commandWaitDelay = 2 * time.Second
+
+// expansionChars make the shell compute a command name that cannot be checked before it runs
+const expansionChars = "$`*?[{~"
+
+// commandRunners run the command given in their arguments, so they would carry any command
+// past the patterns. They are refused whenever allow or deny patterns are set.
+var commandRunners = map[string]bool{
+	// Shell builtins and keywords
+	"eval": true, "exec": true, "command": true, "builtin": true, "source": true, ".": true,
+	"trap": true, "function": true, "coproc": true, "time": true,
+	// Wrappers
+	"env": true, "xargs": true, "nohup": true, "nice": true, "ionice": true, "timeout": true,
+	"sudo": true, "doas": true, "su": true, "runuser": true, "setsid": true, "stdbuf": true,
+	"chroot": true, "taskset": true, "chrt": true, "flock": true, "watch": true, "strace": true,
+	"ltrace": true, "busybox": true, "parallel": true, "script": true, "unshare": true,
+	"nsenter": true, "systemd-run": true,
+	// Shells
+	"sh": true, "bash": true, "dash": true, "zsh": true, "ksh": true, "mksh": true, "ash": true,
+	"yash": true, "rbash": true, "csh": true, "tcsh": true, "fish": true, "pwsh": true,
+}
+
+// commandRunnerFlags are options that make a command run another command given in its
+// arguments. They are refused whenever allow or deny patterns are set.
+var commandRunnerFlags = map[string][]string{
+	"find": {"-exec", "-execdir", "-ok", "-okdir"},
+}
+
+// commandPrefixKeywords are reserved words followed by a command in the same segment
+var commandPrefixKeywords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "do": true, "while": true, "until": true,
+	"!": true, "{": true,
+}
+
+// changeDirCommands change the working directory of the shell
+var changeDirCommands = map[string]bool{"cd": true, "pushd": true, "popd": true}
+
+// commandlessKeywords start or end a compound command and name no command themselves
+var commandlessKeywords = map[string]bool{
+	"fi": true, "done": true, "esac": true, "}": true, "for": true, "select": true, "case": true,
+}
+
+// commandResult holds the outcome of a command execution
+type commandResult struct {
+	exitCode  int
+	stdout    *cappedBuffer
+	stderr    *cappedBuffer
+	timedOut  bool
+	cancelled bool
+	duration  time.Duration
+}
+
+// cappedBuffer keeps at most limit bytes while counting everything written
+type cappedBuffer struct {
+	buf   bytes.Buffer
+	limit int64
+	total int64
+}
+
+// Write implements io.Writer, silently discarding bytes past the limit
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.total += int64(len(p))
+	if b.limit <= 0 {
+		return b.buf.Write(p)
+	}
+	if room := b.limit - int64(b.buf.Len()); room > 0 {
+		if int64(len(p)) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// Truncated reports whether output was discarded
+func (b *cappedBuffer) Truncated() bool {
+	return b.total > int64(b.buf.Len())
+}
+
+// String returns the retained output
+func (b *cappedBuffer) String() string {
+	return b.buf.String()
+}
+
+// commandTimeout resolves the requested timeout against the policy
+func commandTimeout(input map[string]interface{}, policy config.CommandPolicyConfig) time.Duration {
+	timeout := policy.DefaultTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	if t, ok := input["timeout"].(float64); ok && t > 0 {
+		timeout = time.Duration(t * float64(time.Second))
+	}
+	if maxTimeout := maxCommandTimeout(policy); timeout > maxTimeout {
+		timeout = maxTimeout
+	}
+	return timeout
+}
+
+// maxCommandTimeout returns the policy's upper bound for command timeouts, or the default
+// one when the policy sets none
+func maxCommandTimeout(policy config.CommandPolicyConfig) time.Duration {
+	if policy.MaxTimeout > 0 {
+		return policy.MaxTimeout
+	}
+	return config.DefaultCommandPolicy().MaxTimeout
+}
+
+// buildCommandArgv checks the command against the policy and returns the argv to execute
+func buildCommandArgv(command string, policy config.CommandPolicyConfig) ([]string, error) {
+	segments, substitution, err := parseShellCommand(command)
+	if err != nil {
+		return nil, err
+	}
+
+	restricted := len(policy.AllowedCommands) > 0 || len(policy.DeniedCommands) > 0
+	if substitution && restricted {
+		return nil, ErrCommandSubstitution
+	}
+
+	for _, segment := range segments {
+		name := commandName(segment)
+		if name != "" && len(policy.AllowedDirs) > 0 && changeDirCommands[name] {
+			return nil, fmt.Errorf("%w: %s", ErrChangeDirDenied, name)
+		}
+		if name == "" || !restricted {
+			continue
+		}
+		if err := checkCommandName(name, policy); err != nil {
+			return nil, err
+		}
+		if err := checkCommandFlags(name, segment); err != nil {
+			return nil, err
+		}
+	}
+
+	if policy.UseShell {
+		return []string{"sh", "-c", command}, nil
+	}
+
+	if len(segments) != 1 || substitution {
+		return nil, ErrShellOperatorsDisabled
+	}
+	argv := segments[0]
+	for len(argv) > 0 && isEnvAssignment(argv[0]) {
+		argv = argv[1:]
+	}
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("command is required")
+	}
+	return argv, nil
+}
+
+// checkCommandName applies the deny and allow patterns to argv[0], refusing names the
+// shell would expand and commands that run other commands
+func checkCommandName(name string, policy config.CommandPolicyConfig) error {
+	if strings.ContainsAny(name, expansionChars) {
+		return fmt.Errorf("%w: %s is expanded by the shell", ErrCommandDenied, name)
+	}
+	if commandRunners[filepath.Base(name)] {
+		return fmt.Errorf("%w: %s runs other commands", ErrCommandDenied, name)
+	}
+	if matchesAnyPattern(policy.DeniedCommands, name, filepath.Base(name)) {
+		return fmt.Errorf("%w: %s", ErrCommandDenied, name)
+	}
+	if len(policy.AllowedCommands) > 0 && !matchesAnyPattern(policy.AllowedCommands, name, filepath.Base(name)) {
+		return fmt.Errorf("%w: %s is not in allowed_commands", ErrCommandDenied, name)
+	}
+	return nil
+}
+
+// checkCommandFlags refuses the options that turn name into a runner of other commands
+func checkCommandFlags(name string, words []string) error {
+	flags := commandRunnerFlags[filepath.Base(name)]
+	for _, word := range words {
+		if slices.Contains(flags, word) {
+			return fmt.Errorf("%w: %s %s runs other commands", ErrCommandDenied, name, word)
+		}
+	}
+	return nil
+}
+
+// matchesAnyPattern reports whether any candidate matches any glob pattern
+func matchesAnyPattern(patterns []string, candidates ...string) bool {
+	for _, pattern := range patterns {
+		for _, candidate := range candidates {
+			if ok, _ := filepath.Match(pattern, candidate); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// commandName returns argv[0] of a simple command, skipping leading variable assignments,
+// redirections and the reserved words that precede a command
+func commandName(words []string) string {
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		switch {
+		case isEnvAssignment(word), commandPrefixKeywords[word]:
+		case isRedirection(word):
+			// A bare operator such as "2>" takes the next word as its target
+			if strings.TrimLeft(word, "0123456789<>&|") == "" {
+				i++
+			}
+		case commandlessKeywords[word]:
+			return ""
+		default:
+			return word
+		}
+	}
+	return ""
+}
+
+// isRedirection reports whether word starts with a redirection operator such as >, 2>> or &>
+func isRedirection(word string) bool {
+	word = strings.TrimLeft(word, "0123456789")
+	return strings.HasPrefix(word, "<") || strings.HasPrefix(word, ">") || strings.HasPrefix(word, "&>")
+}
+
+// isEnvAssignment reports whether word is a NAME=value prefix assignment
+func isEnvAssignment(word string) bool {
+	idx := strings.IndexByte(word, '=')
+	if idx <= 0 {
+		return false
+	}
+	for i, c := range word[:idx] {
+		if c != '_' && (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseShellCommand splits a command line into simple commands at shell control
+// operators, honoring quotes and escapes. It also reports command substitution.
+// Backslash-newline is a line continuation outside single quotes and is dropped, as the
+// shell does, so r\<newline>m is checked as rm.
+func parseShellCommand(command string) ([][]string, bool, error) {
+	var segments [][]string
+	var words []string
+	var word strings.Builder
+	inWord := false
+	substitution := false
+
+	flushWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	flushSegment := func() {
+		flushWord()
+		if len(words) > 0 {
+			segments = append(segments, words)
+			words = nil
+		}
+	}
+
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case c == '\\' && i+1 < len(command) && command[i+1] == '\n':
+			i++
+		case c == '\\' && i+1 < len(command):
+			i++
+			word.WriteByte(command[i])
+			inWord = true
+		case c == '\'':
+			end := strings.IndexByte(command[i+1:], '\'')
+			if end < 0 {
+				return nil, false, ErrUnterminatedQuote
+			}
+			word.WriteString(command[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			j := i + 1
+			for ; j < len(command) && command[j] != '"'; j++ {
+				switch {
+				case command[j] == '\\' && j+1 < len(command) && command[j+1] == '\n':
+					j++
+					continue
+				case command[j] == '\\' && j+1 < len(command):
+					j++
+				case command[j] == '`' || (command[j] == '$' && j+1 < len(command) && command[j+1] == '('):
+					substitution = true
+				}
+				word.WriteByte(command[j])
+			}
+			if j >= len(command) {
+				return nil, false, ErrUnterminatedQuote
+			}
+			i = j
+			inWord = true
+		case c == '`':
+			substitution = true
+			word.WriteByte(c)
+			inWord = true
+		case c == '$' && i+1 < len(command) && command[i+1] == '(':
+			substitution = true
+			word.WriteByte(c)
+			inWord = true
+		case (c == '<' || c == '>') && i+1 < len(command) && command[i+1] == '(':
+			substitution = true
+			word.WriteByte(c)
+			inWord = true
+		case c == '&' && (i+1 < len(command) && command[i+1] == '>' ||
+			inWord && (command[i-1] == '>' || command[i-1] == '<')):
+			// Part of a redirection such as &>, >& or <&
+			word.WriteByte(c)
+			inWord = true
+		case c == '|' || c == '&' || c == ';' || c == '\n' || c == '(' || c == ')':
+			flushSegment()
+		case c == ' ' || c == '\t' || c == '\r':
+			flushWord()
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	flushSegment()
+
+	return segments, substitution, nil
+}
+
+// resolveWorkingDir resolves dir and checks it against the allowed roots. This restricts
+// where commands start, not which paths they may name
+func resolveWorkingDir(dir string, allowedDirs []string) (string, error) {
+	if dir == "" {
+		if len(allowedDirs) == 0 {
+			return "", nil
+		}
+		dir = allowedDirs[0]
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(absDir)
+	if err != nil {
+		return "", err
+	}
+	if len(allowedDirs) == 0 {
+		return resolved, nil
+	}
+
+	for _, root := range allowedDirs {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		resolvedRoot, err := filepath.EvalSymlinks(absRoot)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(resolvedRoot, resolved)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrWorkingDirDenied, dir)
+}
+
+// filterEnvironment returns the entries of environ whose names match the allowlist
+func filterEnvironment(environ, allowlist []string) []string {
+	env := make([]string, 0, len(allowlist))
+	for _, entry := range environ {
+		name, _, _ := strings.Cut(entry, "=")
+		if matchesAnyPattern(allowlist, name) {
+			env = append(env, entry)
+		}
+	}
+	return env
+}
+
+// runCommand executes argv under the policy, killing the whole process group on
+// timeout or cancellation. Output is streamed as progress when the caller asked for it.
+func runCommand(ctx context.Context, argv []string, dir string, timeout time.Duration, policy config.CommandPolicyConfig) (*commandResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...) //nolint:gosec // G204: command execution is intentional for shell tool
+	cmd.Dir = dir
+	cmd.Env = filterEnvironment(os.Environ(), policy.EnvAllowlist)
+	cmd.WaitDelay = commandWaitDelay
+	configureProcessGroup(cmd)
+
+	result := &commandResult{
+		stdout: &cappedBuffer{limit: policy.MaxOutputBytes},
+		stderr: &cappedBuffer{limit: policy.MaxOutputBytes},
+	}
+	cmd.Stdout = result.stdout
+	cmd.Stderr = result.stderr
+
+	if _, ok := entities.ProgressReporterFromContext(ctx); ok {
+		streamer := newOutputStreamer(ctx, policy.ProgressInterval)
+		defer streamer.Close()
+		cmd.Stdout = io.MultiWriter(result.stdout, streamer.Writer("stdout"))
+		cmd.Stderr = io.MultiWriter(result.stderr, streamer.Writer("stderr"))
+	}
+
+	start := time.Now()
+	err := cmd.Run()
+	result.duration = time.Since(start)
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.timedOut = true
+		result.exitCode = -1
+	case errors.Is(ctx.Err(), context.Canceled):
+		result.cancelled = true
+		result.exitCode = -1
+	case errors.As(err, &exitErr):
+		result.exitCode = exitErr.ExitCode()
+	case err != nil && !errors.Is(err, exec.ErrWaitDelay):
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// toToolResult renders the command result with separate stdout and stderr sections
+func (c *commandResult) toToolResult(timeout time.Duration) *entities.ToolResult {
+	var sb strings.Builder
+	switch {
+	case c.timedOut:
+		fmt.Fprintf(&sb, "Command timed out after %s\n", timeout)
+	case c.cancelled:
+		sb.WriteString("Command cancelled\n")
+	default:
+		fmt.Fprintf(&sb, "Exit code: %d\n", c.exitCode)
+	}
+	writeStream(&sb, "STDOUT", c.stdout)
+	writeStream(&sb, "STDERR", c.stderr)
+
+	result := entities.NewTextToolResult(strings.TrimRight(sb.String(), "\n"))
+	result.IsError = c.timedOut || c.cancelled
+	result.SetMeta("exit_code", c.exitCode)
+	result.SetMeta("stdout", c.stdout.String())
+	result.SetMeta("stderr", c.stderr.String())
+	result.SetMeta("stdout_bytes", c.stdout.total)
+	result.SetMeta("stderr_bytes", c.stderr.total)
+	result.SetMeta("stdout_truncated", c.stdout.Truncated())
+	result.SetMeta("stderr_truncated", c.stderr.Truncated())
+	result.SetMeta("timed_out", c.timedOut)
+	result.SetMeta("cancelled", c.cancelled)
+	result.SetMeta("duration_ms", c.duration.Milliseconds())
+	return result
+}
+
+// writeStream appends a labelled output section, noting truncation
+func writeStream(sb *strings.Builder, label string, stream *cappedBuffer) {
+	if stream.total == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "\n%s:\n%s", label, stream.String())
+	if !strings.HasSuffix(stream.String(), "\n") {
+		sb.WriteByte('\n')
+	}
+	if stream.Truncated() {
+		fmt.Fprintf(sb, "[%s truncated: showing %d of %d bytes]\n", strings.ToLower(label), stream.buf.Len(), stream.total)
+	}
+}
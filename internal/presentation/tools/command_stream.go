@@ -0,0 +1,181 @@
+// Package tools contains built-in MCP tools for TelemetryFlow
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+)
+
+// maxStreamLineBytes splits overly long lines, such as progress bars, into chunks
+const maxStreamLineBytes = 4096
+
+// maxProgressMessageBytes bounds the output carried by a single progress notification
+const maxProgressMessageBytes = 64 * 1024
+
+// outputStreamer forwards complete output lines as throttled progress notifications
+type outputStreamer struct {
+	ctx context.Context
+
+	mu           sync.Mutex
+	partial      map[string][]byte
+	pending      []string
+	pendingBytes int
+	skipped      int
+	lines        int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newOutputStreamer starts a streamer that flushes buffered lines at most once per interval
+func newOutputStreamer(ctx context.Context, interval time.Duration) *outputStreamer {
+	if interval <= 0 {
+		interval = 250 * time.Millisecond
+	}
+	s := &outputStreamer{
+		ctx:     ctx,
+		partial: make(map[string][]byte),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.flush()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+
+	return s
+}
+
+// Writer returns an io.Writer feeding the named stream
+func (s *outputStreamer) Writer(stream string) io.Writer {
+	return &streamWriter{streamer: s, stream: stream}
+}
+
+// Close flushes any partial lines and stops the flush loop
+func (s *outputStreamer) Close() {
+	close(s.stop)
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stream := range []string{"stdout", "stderr"} {
+		if rest := s.partial[stream]; len(rest) > 0 {
+			s.addLine(stream, rest)
+		}
+	}
+	s.partial = nil
+	s.flushLocked()
+}
+
+// write splits incoming bytes into lines, keeping the trailing partial line
+func (s *outputStreamer) write(stream string, p []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.partial == nil {
+		return
+	}
+
+	buf := append(s.partial[stream], p...)
+	for {
+		idx := bytes.IndexByte(buf, '\n')
+		if idx < 0 {
+			if len(buf) < maxStreamLineBytes {
+				break
+			}
+			idx = maxStreamLineBytes
+			s.addLine(stream, buf[:idx])
+			buf = buf[idx:]
+			continue
+		}
+		s.addLine(stream, buf[:idx])
+		buf = buf[idx+1:]
+	}
+	s.partial[stream] = append([]byte(nil), buf...)
+}
+
+// addLine queues a line, counting it as skipped once the message budget is spent
+func (s *outputStreamer) addLine(stream string, line []byte) {
+	text := strings.TrimSuffix(string(line), "\r")
+	if stream == "stderr" {
+		text = "[stderr] " + text
+	}
+	if s.pendingBytes+len(text)+1 > maxProgressMessageBytes {
+		s.skipped++
+		return
+	}
+	s.pending = append(s.pending, text)
+	s.pendingBytes += len(text) + 1
+}
+
+// flush sends buffered lines as a progress notification
+func (s *outputStreamer) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flushLocked()
+}
+
+func (s *outputStreamer) flushLocked() {
+	if len(s.pending) == 0 && s.skipped == 0 {
+		return
+	}
+
+	message := strings.Join(s.pending, "\n")
+	if s.skipped > 0 {
+		message += fmt.Sprintf("\n[%d lines not streamed; see final result]", s.skipped)
+	}
+	s.lines += len(s.pending) + s.skipped
+	s.pending = nil
+	s.pendingBytes = 0
+	s.skipped = 0
+
+	entities.ReportProgress(s.ctx, entities.ToolProgress{
+		Progress: float64(s.lines),
+		Message:  message,
+	})
+}
+
+// streamWriter adapts one output stream of a command to the streamer
+type streamWriter struct {
+	streamer *outputStreamer
+	stream   string
+}
+
+// Write implements io.Writer
+func (w *streamWriter) Write(p []byte) (int, error) {
+	w.streamer.write(w.stream, p)
+	return len(p), nil
+}
@@ -0,0 +1,144 @@
+// Package tools provides built-in MCP tools
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+)
+
+// Config tool errors
+var (
+	ErrToolNameTaken = errors.New("tool name is already used by another tool")
+)
+
+// BuildConfigTools compiles the HTTP tools and pipelines declared in cfg.
+// Definitions that fail to compile are skipped and reported in the returned error.
+func BuildConfigTools(cfg *config.Config, repo repositories.IToolRepository, client *http.Client) ([]*entities.Tool, error) {
+	var errs []error
+	tools := make([]*entities.Tool, 0, len(cfg.HTTPTools)+len(cfg.Pipelines))
+
+	for _, def := range cfg.HTTPTools {
+		tool, err := NewHTTPTool(def, client)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		tools = append(tools, tool)
+	}
+
+	lookup := RepositoryLookup(repo)
+	for _, def := range cfg.Pipelines {
+		tool, err := NewPipelineTool(def, lookup)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		tools = append(tools, tool)
+	}
+
+	return tools, errors.Join(errs...)
+}
+
+// ConfigToolSet registers tools declared in the config file and keeps them in sync across reloads
+type ConfigToolSet struct {
+	mu    sync.Mutex
+	names map[string]bool
+}
+
+// NewConfigToolSet creates an empty config tool set
+func NewConfigToolSet() *ConfigToolSet {
+	return &ConfigToolSet{names: make(map[string]bool)}
+}
+
+// Names returns the currently registered config tool names
+func (s *ConfigToolSet) Names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.names))
+	for name := range s.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Sync registers tools in repo, replacing tools from the previous sync and removing those no longer present.
+// Names used by tools outside the set are skipped and reported in the returned error.
+func (s *ConfigToolSet) Sync(ctx context.Context, repo repositories.IToolRepository, tools []*entities.Tool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	next := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		name := tool.Name().String()
+		if !s.names[name] {
+			exists, err := repo.Exists(ctx, tool.Name())
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if exists {
+				errs = append(errs, fmt.Errorf("tool %s: %w", name, ErrToolNameTaken))
+				continue
+			}
+		}
+
+		if err := repo.Register(ctx, tool); err != nil {
+			errs = append(errs, fmt.Errorf("tool %s: %w", name, err))
+			continue
+		}
+		next[name] = true
+	}
+
+	for name := range s.names {
+		if next[name] {
+			continue
+		}
+		toolName, err := vo.NewToolName(name)
+		if err == nil {
+			err = repo.Unregister(ctx, toolName)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tool %s: %w", name, err))
+			// Keep tracking it so the next sync retries the removal
+			next[name] = true
+		}
+	}
+
+	s.names = next
+	return errors.Join(errs...)
+}
+
+// Load builds the HTTP tools and pipelines declared in cfg and syncs them into repo.
+// Tools that fail to build or register are skipped and reported in the returned error
+func (s *ConfigToolSet) Load(ctx context.Context, cfg *config.Config, repo repositories.IToolRepository) error {
+	tools, buildErr := BuildConfigTools(cfg, repo, nil)
+	return errors.Join(buildErr, s.Sync(ctx, repo, tools))
+}
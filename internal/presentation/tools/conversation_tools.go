@@ -0,0 +1,480 @@
+// Package tools contains built-in MCP tools for TelemetryFlow
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/commands"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/dto"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/queries"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// conversationMessageTimeout bounds send_conversation_message when tools are not run
+const conversationMessageTimeout = 120 * time.Second
+
+// SetConversationHandler registers the tools that hold multi-turn conversations backed by handler.
+// Call it after SetAgent so that send_conversation_message can run tools
+func (r *ToolRegistry) SetConversationHandler(handler *handlers.ConversationHandler) {
+	r.conversationHandler = handler
+	r.registerStartConversation()
+	r.registerSendConversationMessage()
+	r.registerListConversations()
+	r.registerGetConversation()
+	r.registerCloseConversation()
+}
+
+func (r *ToolRegistry) registerStartConversation() {
+	name, _ := vo.NewToolName("start_conversation")
+	desc, _ := vo.NewToolDescription("Start a multi-turn conversation and return its conversation_id. Follow up with send_conversation_message; the conversation keeps its history until close_conversation")
+
+	contextTypes := make([]interface{}, 0, len(vo.AllContextTypes()))
+	for _, ct := range vo.AllContextTypes() {
+		contextTypes = append(contextTypes, ct.String())
+	}
+
+	schema := &entities.JSONSchema{
+		Type: "object",
+		Properties: map[string]*entities.JSONSchema{
+			"model": {
+				Type:        "string",
+				Description: fmt.Sprintf("The LLM model or alias to use (default: %s)", vo.DefaultModel),
+			},
+			"context_type": {
+				Type:        "string",
+				Description: "Analyst persona; builds the system prompt with system_prompt as additional instructions",
+				Enum:        contextTypes,
+			},
+			"system_prompt": {
+				Type:        "string",
+				Description: "System prompt, or additional instructions when context_type is set",
+			},
+			"max_tokens": {
+				Type:        "integer",
+				Description: "Maximum tokens of each response (default: 4096)",
+			},
+			"temperature": {
+				Type:        "number",
+				Description: "Sampling temperature between 0 and 2 (default: 1)",
+			},
+		},
+	}
+	maps.Copy(schema.Properties, reasoningProperties())
+
+	tool, _ := entities.NewTool(name, desc, schema)
+	tool.SetCategory("ai")
+	tool.SetTags([]string{"conversation", "ai", "start"})
+	tool.SetContextHandler(r.handleStartConversation)
+
+	r.tools["start_conversation"] = tool
+}
+
+func (r *ToolRegistry) handleStartConversation(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+	sessionID, ok := handlers.SessionIDFromContext(ctx)
+	if !ok {
+		return entities.NewErrorToolResult(ErrNoSessionContext), nil
+	}
+
+	cmd := &commands.CreateConversationCommand{
+		SessionID:   sessionID,
+		Model:       vo.DefaultModel,
+		Temperature: -1,
+	}
+	if m, ok := input["model"].(string); ok && m != "" {
+		cmd.Model = vo.Model(m)
+		if !cmd.Model.IsValid() && !slices.Contains(r.modelAliases, cmd.Model) {
+			return entities.NewErrorToolResult(fmt.Errorf("%w: %s", vo.ErrInvalidModel, m)), nil
+		}
+	}
+	if mt, ok := input["max_tokens"].(float64); ok && mt > 0 {
+		cmd.MaxTokens = int(mt)
+	}
+	if t, ok := input["temperature"].(float64); ok {
+		cmd.Temperature = t
+	}
+	reasoning, err := reasoningInput(input)
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+	cmd.Reasoning = reasoning
+
+	cmd.SystemPrompt, _ = input["system_prompt"].(string)
+	if ct, ok := input["context_type"].(string); ok && ct != "" {
+		cmd.ContextType = vo.ContextType(ct)
+		if !cmd.ContextType.IsValid() {
+			return entities.NewErrorToolResult(fmt.Errorf("invalid context_type: %s", ct)), nil
+		}
+		cmd.SystemPrompt = r.promptBuilder.BuildSystemPrompt(cmd.ContextType, cmd.SystemPrompt)
+	}
+
+	conversation, err := r.conversationHandler.HandleCreateConversation(ctx, cmd)
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+	return conversationResult(conversation, nil), nil
+}
+
+func (r *ToolRegistry) registerSendConversationMessage() {
+	name, _ := vo.NewToolName("send_conversation_message")
+	desc, _ := vo.NewToolDescription("Send a message in a conversation started with start_conversation and return the reply. The model sees the whole conversation history, and the reply streams as progress notifications when the call carries a progress token")
+
+	properties := map[string]*entities.JSONSchema{
+		"conversation_id": {
+			Type:        "string",
+			Description: "The conversation ID returned by start_conversation",
+		},
+		"message": {
+			Type:        "string",
+			Description: "The message to send",
+		},
+		"attachments":       attachmentsProperty(),
+		"include_reasoning": includeReasoningProperty(),
+	}
+	timeout := conversationMessageTimeout
+	if r.agent != nil {
+		properties["run_tools"] = &entities.JSONSchema{
+			Type:        "boolean",
+			Description: "Let the model call the server's tools, such as collect_telemetry_context, before it replies (default: false)",
+		}
+		properties["tools"] = &entities.JSONSchema{
+			Type:        "array",
+			Description: "Narrow the tools the model may call when run_tools is set",
+			Items:       &entities.JSONSchema{Type: "string"},
+		}
+		timeout = max(timeout, r.agentTimeout)
+	}
+
+	schema := &entities.JSONSchema{
+		Type:       "object",
+		Properties: properties,
+		Required:   []string{"conversation_id", "message"},
+	}
+
+	tool, _ := entities.NewTool(name, desc, schema)
+	tool.SetCategory("ai")
+	tool.SetTags([]string{"conversation", "ai", "message"})
+	tool.SetContextHandler(r.handleSendConversationMessage)
+	tool.SetTimeout(timeout)
+
+	r.tools["send_conversation_message"] = tool
+}
+
+func (r *ToolRegistry) handleSendConversationMessage(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+	conversation, errResult := r.sessionConversation(ctx, input)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	message, _ := input["message"].(string)
+	if message == "" {
+		return entities.NewErrorToolResult(fmt.Errorf("message is required")), nil
+	}
+
+	attachments, err := r.attachmentsInput(ctx, input)
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+
+	cmd := &commands.SendMessageCommand{ConversationID: conversation.ID(), Content: message, Attachments: attachments}
+	cmd.RunTools, _ = input["run_tools"].(bool)
+	if names, ok := input["tools"].([]interface{}); ok {
+		for _, name := range names {
+			if s, ok := name.(string); ok && s != "" {
+				cmd.AllowedTools = append(cmd.AllowedTools, s)
+			}
+		}
+	}
+
+	// Plain replies stream to clients that asked for progress; tool runs report only their result
+	if streamer := newTextStreamer(ctx); streamer != nil && !cmd.RunTools {
+		defer streamer.Close()
+		cmd.Stream = true
+		cmd.OnText = streamer.Handler()
+	}
+
+	sent, err := r.conversationHandler.HandleSendMessage(ctx, cmd)
+	if sent == nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+
+	text := responseText(sent.Response)
+
+	var result *entities.ToolResult
+	if err != nil {
+		// The agent stopped at a limit; the conversation keeps the run so far
+		stopped := fmt.Sprintf("Stopped: %v", err)
+		if text != "" {
+			stopped += "\n\n" + text
+		}
+		result = entities.NewTextToolResult(stopped)
+		result.IsError = true
+	} else {
+		result = entities.NewTextToolResult(text)
+	}
+
+	result.SetMeta("conversation_id", conversation.ID().String())
+	result.SetMeta("message_count", conversation.MessageCount())
+	if sent.Agent != nil {
+		// The run's total usage is in the agent result
+		if sent.Response != nil && sent.Response.ServedBy != "" {
+			result.SetMeta("model", sent.Response.ServedBy.String())
+		}
+		result.SetMeta("agent", sent.Agent)
+		setReasoningMeta(result, input, strings.Join(sent.Agent.Reasoning, "\n\n"))
+	} else {
+		setResponseMeta(result, sent.Response)
+		if sent.Response != nil {
+			setReasoningMeta(result, input, entities.ReasoningText(sent.Response.Content))
+		}
+	}
+	return result, nil
+}
+
+func (r *ToolRegistry) registerListConversations() {
+	name, _ := vo.NewToolName("list_conversations")
+	desc, _ := vo.NewToolDescription("List the conversations of this session, most recently updated first")
+
+	schema := &entities.JSONSchema{
+		Type: "object",
+		Properties: map[string]*entities.JSONSchema{
+			"active_only": {
+				Type:        "boolean",
+				Description: "Only list conversations that are not closed (default: false)",
+			},
+			"limit": {
+				Type:        "integer",
+				Description: "Maximum conversations to return (default: all)",
+			},
+		},
+	}
+
+	tool, _ := entities.NewTool(name, desc, schema)
+	tool.SetCategory("ai")
+	tool.SetTags([]string{"conversation", "list"})
+	tool.SetContextHandler(r.handleListConversations)
+
+	r.tools["list_conversations"] = tool
+}
+
+func (r *ToolRegistry) handleListConversations(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+	sessionID, ok := handlers.SessionIDFromContext(ctx)
+	if !ok {
+		return entities.NewErrorToolResult(ErrNoSessionContext), nil
+	}
+
+	query := &queries.ListConversationsQuery{SessionID: sessionID}
+	query.ActiveOnly, _ = input["active_only"].(bool)
+	if limit, ok := input["limit"].(float64); ok && limit > 0 {
+		query.Limit = int(limit)
+	}
+
+	list, err := r.conversationHandler.HandleListConversations(ctx, query)
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+
+	conversations := make([]dto.ConversationDTO, 0, len(list.Conversations))
+	for _, conversation := range list.Conversations {
+		conversations = append(conversations, *conversationDTO(conversation, nil))
+	}
+	data, _ := json.MarshalIndent(map[string]interface{}{"conversations": conversations}, "", "  ")
+	return entities.NewTextToolResult(string(data)), nil
+}
+
+func (r *ToolRegistry) registerGetConversation() {
+	name, _ := vo.NewToolName("get_conversation")
+	desc, _ := vo.NewToolDescription("Get a conversation of this session with its messages")
+
+	schema := conversationIDSchema(map[string]*entities.JSONSchema{
+		"offset": {
+			Type:        "integer",
+			Description: "Number of messages to skip (default: 0)",
+		},
+		"limit": {
+			Type:        "integer",
+			Description: "Maximum messages to return (default: all)",
+		},
+	})
+
+	tool, _ := entities.NewTool(name, desc, schema)
+	tool.SetCategory("ai")
+	tool.SetTags([]string{"conversation", "read"})
+	tool.SetContextHandler(r.handleGetConversation)
+
+	r.tools["get_conversation"] = tool
+}
+
+func (r *ToolRegistry) handleGetConversation(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+	conversation, errResult := r.sessionConversation(ctx, input)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	query := &queries.GetConversationMessagesQuery{ConversationID: conversation.ID()}
+	if offset, ok := input["offset"].(float64); ok && offset > 0 {
+		query.Offset = int(offset)
+	}
+	if limit, ok := input["limit"].(float64); ok && limit > 0 {
+		query.Limit = int(limit)
+	}
+
+	messages, err := r.conversationHandler.HandleGetConversationMessages(ctx, query)
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+	if messages == nil {
+		messages = []*entities.Message{}
+	}
+	return conversationResult(conversation, messages), nil
+}
+
+func (r *ToolRegistry) registerCloseConversation() {
+	name, _ := vo.NewToolName("close_conversation")
+	desc, _ := vo.NewToolDescription("Close a conversation. Its history can still be read, but no more messages can be sent")
+
+	tool, _ := entities.NewTool(name, desc, conversationIDSchema(nil))
+	tool.SetCategory("ai")
+	tool.SetTags([]string{"conversation", "close"})
+	tool.SetContextHandler(r.handleCloseConversation)
+
+	r.tools["close_conversation"] = tool
+}
+
+func (r *ToolRegistry) handleCloseConversation(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+	conversation, errResult := r.sessionConversation(ctx, input)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	err := r.conversationHandler.HandleCloseConversation(ctx, &commands.CloseConversationCommand{ConversationID: conversation.ID()})
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+	return conversationResult(conversation, nil), nil
+}
+
+// conversationIDSchema builds an input schema with a required conversation_id and optional extras
+func conversationIDSchema(extra map[string]*entities.JSONSchema) *entities.JSONSchema {
+	properties := map[string]*entities.JSONSchema{
+		"conversation_id": {
+			Type:        "string",
+			Description: "The conversation ID returned by start_conversation",
+		},
+	}
+	for key, schema := range extra {
+		properties[key] = schema
+	}
+	return &entities.JSONSchema{
+		Type:       "object",
+		Properties: properties,
+		Required:   []string{"conversation_id"},
+	}
+}
+
+// sessionConversation loads the conversation named in input; conversations of other sessions are
+// reported as not found
+func (r *ToolRegistry) sessionConversation(ctx context.Context, input map[string]interface{}) (*aggregates.Conversation, *entities.ToolResult) {
+	sessionID, ok := handlers.SessionIDFromContext(ctx)
+	if !ok {
+		return nil, entities.NewErrorToolResult(ErrNoSessionContext)
+	}
+	value, _ := input["conversation_id"].(string)
+	if value == "" {
+		return nil, entities.NewErrorToolResult(fmt.Errorf("conversation_id is required"))
+	}
+	conversationID, err := vo.NewConversationID(value)
+	if err != nil {
+		return nil, entities.NewErrorToolResult(err)
+	}
+
+	conversation, err := r.conversationHandler.HandleGetConversation(ctx, &queries.GetConversationQuery{ConversationID: conversationID})
+	if err != nil {
+		return nil, entities.NewErrorToolResult(err)
+	}
+	if !conversation.SessionID().Equals(sessionID) {
+		return nil, entities.NewErrorToolResult(handlers.ErrConversationNotFound)
+	}
+	return conversation, nil
+}
+
+// conversationResult renders a conversation, with messages when given, as a JSON result
+func conversationResult(conversation *aggregates.Conversation, messages []*entities.Message) *entities.ToolResult {
+	data, _ := json.MarshalIndent(conversationDTO(conversation, messages), "", "  ")
+	result := entities.NewTextToolResult(string(data))
+	result.SetMeta("conversation_id", conversation.ID().String())
+	return result
+}
+
+// conversationDTO converts a conversation and the given messages
+func conversationDTO(conversation *aggregates.Conversation, messages []*entities.Message) *dto.ConversationDTO {
+	d := &dto.ConversationDTO{
+		ID:           conversation.ID().String(),
+		SessionID:    conversation.SessionID().String(),
+		Model:        conversation.Model().String(),
+		SystemPrompt: conversation.SystemPrompt().String(),
+		Reasoning:    conversation.Reasoning(),
+		MessageCount: conversation.MessageCount(),
+		IsActive:     conversation.IsActive(),
+		CreatedAt:    conversation.CreatedAt(),
+		UpdatedAt:    conversation.UpdatedAt(),
+	}
+	if ct, ok := conversation.GetMetadata("context_type"); ok {
+		d.ContextType, _ = ct.(string)
+	}
+	for _, msg := range messages {
+		blocks := make([]dto.ContentBlockDTO, 0, len(msg.Content()))
+		for _, block := range msg.Content() {
+			// Reasoning stays in the history for the model, not for the client
+			if block.Type.IsReasoning() {
+				continue
+			}
+			blockDTO := dto.ContentBlockDTO{
+				Type:      block.Type.String(),
+				Text:      block.Text,
+				ID:        block.ID,
+				Name:      block.Name,
+				Input:     block.Input,
+				ToolUseID: block.ToolUseID,
+				Content:   block.Content,
+				IsError:   block.IsError,
+			}
+			if block.Source != nil {
+				blockDTO.MediaType = block.Source.MediaType
+			}
+			blocks = append(blocks, blockDTO)
+		}
+		d.Messages = append(d.Messages, dto.MessageDTO{
+			ID:        msg.ID().String(),
+			Role:      msg.Role().String(),
+			Content:   blocks,
+			CreatedAt: msg.CreatedAt(),
+		})
+	}
+	return d
+}
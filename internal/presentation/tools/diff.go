@@ -0,0 +1,389 @@
+// Package tools contains built-in MCP tools for TelemetryFlow
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Patch errors
+var (
+	ErrInvalidPatch     = errors.New("invalid patch")
+	ErrHunkDoesNotApply = errors.New("hunk does not apply")
+)
+
+// diffContextLines is the number of context lines emitted around changes
+const diffContextLines = 3
+
+// maxDiffCells bounds the LCS table size before falling back to a whole-block diff
+const maxDiffCells = 4_000_000
+
+// diffOp is a single line operation: ' ' keep, '-' delete, '+' insert
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// splitLines splits text into lines, each keeping its trailing newline
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// lineDiff computes a line-level edit script from a to b
+func lineDiff(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, lcsDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// lcsDiff diffs two line slices via a longest-common-subsequence table
+func lcsDiff(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	if n*m > maxDiffCells {
+		ops := make([]diffOp, 0, n+m)
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	}
+
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else if table[i+1][j] >= table[i][j+1] {
+				table[i][j] = table[i+1][j]
+			} else {
+				table[i][j] = table[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// unifiedDiff renders the difference between two texts as a unified diff
+func unifiedDiff(path, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	ops := lineDiff(splitLines(oldText), splitLines(newText))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", path, path)
+
+	// Walk the script collecting hunks of changes padded with context
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		start := i - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*diffContextLines {
+				end += diffContextLines
+				if end > run {
+					end = run
+				}
+				break
+			}
+			end = run
+		}
+
+		hunkOldStart, hunkNewStart := oldLine-(i-start), newLine-(i-start)
+		oldCount, newCount := 0, 0
+		var body strings.Builder
+		for _, op := range ops[start:end] {
+			switch op.kind {
+			case ' ':
+				oldCount++
+				newCount++
+			case '-':
+				oldCount++
+			case '+':
+				newCount++
+			}
+			body.WriteByte(op.kind)
+			body.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				body.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(hunkOldStart, oldCount), hunkRange(hunkNewStart, newCount))
+		sb.WriteString(body.String())
+
+		for _, op := range ops[i:end] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		i = end
+	}
+
+	return sb.String()
+}
+
+// hunkRange formats a hunk header range, using the empty-range convention for zero counts
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// patchHunk is a parsed unified-diff hunk
+type patchHunk struct {
+	oldStart int
+	lines    []diffOp
+}
+
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// parseUnifiedDiff parses the hunks of a single-file unified diff
+func parseUnifiedDiff(patch string) ([]patchHunk, error) {
+	var hunks []patchHunk
+	var current *patchHunk
+
+	for _, raw := range strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n") {
+		if m := hunkHeaderPattern.FindStringSubmatch(raw); m != nil {
+			start, _ := strconv.Atoi(m[1])
+			hunks = append(hunks, patchHunk{oldStart: start})
+			current = &hunks[len(hunks)-1]
+			continue
+		}
+		if current == nil {
+			// Skip headers such as "diff --git", "---" and "+++"
+			continue
+		}
+		if strings.HasPrefix(raw, `\`) {
+			if n := len(current.lines); n > 0 {
+				current.lines[n-1].line = strings.TrimSuffix(current.lines[n-1].line, "\n")
+			}
+			continue
+		}
+		if raw == "" {
+			current.lines = append(current.lines, diffOp{' ', "\n"})
+			continue
+		}
+		switch raw[0] {
+		case ' ', '-', '+':
+			current.lines = append(current.lines, diffOp{raw[0], raw[1:] + "\n"})
+		default:
+			return nil, fmt.Errorf("%w: unexpected line %q", ErrInvalidPatch, raw)
+		}
+	}
+
+	if len(hunks) == 0 {
+		return nil, fmt.Errorf("%w: no hunks found", ErrInvalidPatch)
+	}
+
+	// A trailing blank line produced by splitting is not hunk content
+	last := &hunks[len(hunks)-1]
+	if n := len(last.lines); n > 0 && last.lines[n-1] == (diffOp{' ', "\n"}) && strings.HasSuffix(patch, "\n") {
+		last.lines = last.lines[:n-1]
+	}
+
+	return hunks, nil
+}
+
+// applyUnifiedDiff applies a unified diff to text. Each hunk is located near its
+// stated position; with fuzz > 0 up to that many outer context lines may be ignored.
+func applyUnifiedDiff(text, patch string, fuzz int) (string, error) {
+	hunks, err := parseUnifiedDiff(patch)
+	if err != nil {
+		return "", err
+	}
+
+	lines := splitLines(text)
+	offset := 0
+	searchFrom := 0
+
+	for idx, hunk := range hunks {
+		applied := false
+		for f := 0; f <= fuzz && !applied; f++ {
+			oldLines, newLines, trimmedHead, ok := fuzzHunk(hunk.lines, f)
+			if !ok {
+				continue
+			}
+			expected := hunk.oldStart - 1 + offset + trimmedHead
+			if len(oldLines) == 0 && hunk.oldStart == 0 {
+				expected = 0
+			}
+			pos := findHunk(lines, oldLines, expected, searchFrom)
+			if pos < 0 {
+				continue
+			}
+
+			replaced := make([]string, 0, len(lines)-len(oldLines)+len(newLines))
+			replaced = append(replaced, lines[:pos]...)
+			replaced = append(replaced, newLines...)
+			replaced = append(replaced, lines[pos+len(oldLines):]...)
+			lines = replaced
+
+			offset += len(newLines) - len(oldLines) + (pos - expected)
+			searchFrom = pos + len(newLines)
+			applied = true
+		}
+		if !applied {
+			return "", fmt.Errorf("%w: hunk #%d at line %d", ErrHunkDoesNotApply, idx+1, hunk.oldStart)
+		}
+	}
+
+	return strings.Join(lines, ""), nil
+}
+
+// fuzzHunk returns the old/new line sets of a hunk with up to fuzz context lines
+// dropped from each end, plus the number of lines dropped from the head
+func fuzzHunk(ops []diffOp, fuzz int) ([]string, []string, int, bool) {
+	head := 0
+	for head < fuzz && head < len(ops) && ops[head].kind == ' ' {
+		head++
+	}
+	tail := 0
+	for tail < fuzz && tail < len(ops)-head && ops[len(ops)-1-tail].kind == ' ' {
+		tail++
+	}
+	if fuzz > 0 && head+tail == 0 {
+		return nil, nil, 0, false
+	}
+
+	var oldLines, newLines []string
+	for _, op := range ops[head : len(ops)-tail] {
+		if op.kind != '+' {
+			oldLines = append(oldLines, op.line)
+		}
+		if op.kind != '-' {
+			newLines = append(newLines, op.line)
+		}
+	}
+	return oldLines, newLines, head, true
+}
+
+// findHunk returns the match position of needle in lines closest to expected, or -1
+func findHunk(lines, needle []string, expected, from int) int {
+	if expected < from {
+		expected = from
+	}
+	if expected > len(lines) {
+		expected = len(lines)
+	}
+	for delta := 0; ; delta++ {
+		before, after := expected-delta, expected+delta
+		if before < from && after > len(lines)-len(needle) {
+			return -1
+		}
+		if after <= len(lines)-len(needle) && linesMatch(lines[after:], needle) {
+			return after
+		}
+		if delta > 0 && before >= from && before <= len(lines)-len(needle) && linesMatch(lines[before:], needle) {
+			return before
+		}
+	}
+}
+
+// linesMatch reports whether lines starts with needle, ignoring a missing final newline
+func linesMatch(lines, needle []string) bool {
+	if len(needle) > len(lines) {
+		return false
+	}
+	for i, want := range needle {
+		if strings.TrimSuffix(lines[i], "\n") != strings.TrimSuffix(want, "\n") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,219 @@
+// Package tools contains built-in MCP tools for TelemetryFlow
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+)
+
+// Edit file errors
+var (
+	ErrNoEdits            = errors.New("either edits or patch is required")
+	ErrEditsAndPatch      = errors.New("edits and patch are mutually exclusive")
+	ErrEditNotFound       = errors.New("old_text not found")
+	ErrEditNotUnique      = errors.New("old_text is not unique")
+	ErrEditEmptySearch    = errors.New("old_text must not be empty")
+	ErrEditInvalidRequest = errors.New("invalid edit")
+)
+
+// defaultPatchFuzz is the number of context lines a hunk may ignore when applying
+const defaultPatchFuzz = 2
+
+// backupSuffix is appended to the file name when a backup is requested
+const backupSuffix = ".bak"
+
+// searchReplaceEdit is a single exact search/replace block
+type searchReplaceEdit struct {
+	oldText    string
+	newText    string
+	replaceAll bool
+}
+
+// editFileOptions holds the parsed edit_file arguments
+type editFileOptions struct {
+	edits  []searchReplaceEdit
+	patch  string
+	fuzz   int
+	dryRun bool
+	backup bool
+}
+
+// parseEditFileOptions validates and extracts the edit_file arguments
+func parseEditFileOptions(input map[string]interface{}) (*editFileOptions, error) {
+	opts := &editFileOptions{fuzz: defaultPatchFuzz}
+
+	if patch, ok := input["patch"].(string); ok {
+		opts.patch = patch
+	}
+	if raw, ok := input["edits"].([]interface{}); ok {
+		for i, item := range raw {
+			block, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%w: edit #%d must be an object", ErrEditInvalidRequest, i+1)
+			}
+			oldText, _ := block["old_text"].(string)
+			newText, ok := block["new_text"].(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: edit #%d is missing new_text", ErrEditInvalidRequest, i+1)
+			}
+			if oldText == "" {
+				return nil, fmt.Errorf("%w: edit #%d", ErrEditEmptySearch, i+1)
+			}
+			replaceAll, _ := block["replace_all"].(bool)
+			opts.edits = append(opts.edits, searchReplaceEdit{oldText: oldText, newText: newText, replaceAll: replaceAll})
+		}
+	}
+
+	switch {
+	case opts.patch == "" && len(opts.edits) == 0:
+		return nil, ErrNoEdits
+	case opts.patch != "" && len(opts.edits) > 0:
+		return nil, ErrEditsAndPatch
+	}
+
+	if fuzz, ok := input["fuzz"].(float64); ok {
+		if fuzz < 0 {
+			return nil, fmt.Errorf("%w: fuzz must not be negative", ErrEditInvalidRequest)
+		}
+		opts.fuzz = int(fuzz)
+	}
+	if dryRun, ok := input["dry_run"].(bool); ok {
+		opts.dryRun = dryRun
+	}
+	if backup, ok := input["backup"].(bool); ok {
+		opts.backup = backup
+	}
+
+	return opts, nil
+}
+
+// applySearchReplace applies edits in order, requiring each match to be unique
+// unless replace_all is set
+func applySearchReplace(content string, edits []searchReplaceEdit) (string, error) {
+	for i, edit := range edits {
+		count := strings.Count(content, edit.oldText)
+		switch {
+		case count == 0:
+			return "", fmt.Errorf("%w: edit #%d", ErrEditNotFound, i+1)
+		case count > 1 && !edit.replaceAll:
+			return "", fmt.Errorf("%w: edit #%d matches %d times; add surrounding context or set replace_all", ErrEditNotUnique, i+1, count)
+		}
+		if edit.replaceAll {
+			content = strings.ReplaceAll(content, edit.oldText, edit.newText)
+		} else {
+			content = strings.Replace(content, edit.oldText, edit.newText, 1)
+		}
+	}
+	return content, nil
+}
+
+// editFile applies the requested edits to absPath, writing atomically unless dry-run is set
+func editFile(absPath string, opts *editFileOptions) *entities.ToolResult {
+	target, err := filepath.EvalSymlinks(absPath)
+	if err != nil {
+		return entities.NewErrorToolResult(err)
+	}
+
+	original, err := os.ReadFile(target)
+	if err != nil {
+		return entities.NewErrorToolResult(err)
+	}
+
+	var updated string
+	if opts.patch != "" {
+		updated, err = applyUnifiedDiff(string(original), opts.patch, opts.fuzz)
+	} else {
+		updated, err = applySearchReplace(string(original), opts.edits)
+	}
+	if err != nil {
+		return entities.NewErrorToolResult(err)
+	}
+
+	diff := unifiedDiff(filepath.Base(absPath), string(original), updated)
+	changed := diff != ""
+
+	var summary string
+	switch {
+	case !changed:
+		summary = fmt.Sprintf("No changes to %s", absPath)
+	case opts.dryRun:
+		summary = fmt.Sprintf("Dry run: %s would change as follows\n\n%s", absPath, diff)
+	default:
+		if opts.backup {
+			if err := atomicWriteFile(target+backupSuffix, original, 0600); err != nil {
+				return entities.NewErrorToolResult(fmt.Errorf("failed to write backup: %w", err))
+			}
+		}
+		if err := atomicWriteFile(target, []byte(updated), 0600); err != nil {
+			return entities.NewErrorToolResult(err)
+		}
+		summary = fmt.Sprintf("Successfully edited %s\n\n%s", absPath, diff)
+	}
+
+	result := entities.NewTextToolResult(summary)
+	result.SetMeta("changed", changed)
+	result.SetMeta("dry_run", opts.dryRun)
+	if changed && opts.backup && !opts.dryRun {
+		result.SetMeta("backup_path", target+backupSuffix)
+	}
+	return result
+}
+
+// atomicWriteFile writes data to a temp file in the target directory and renames it
+// into place. An existing file keeps its permission bits; otherwise defaultMode is used.
+func atomicWriteFile(path string, data []byte, defaultMode os.FileMode) (err error) {
+	mode := defaultMode
+	if info, statErr := os.Stat(path); statErr == nil {
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", path)
+		}
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(mode); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
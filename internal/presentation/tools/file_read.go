@@ -0,0 +1,384 @@
+// Package tools contains built-in MCP tools for TelemetryFlow
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/resources"
+)
+
+// Read file errors
+var (
+	ErrUnsupportedEncoding = errors.New("unsupported encoding")
+	ErrInvalidReadRange    = errors.New("invalid read range")
+)
+
+// encodingBase64 forces binary output regardless of the detected content type
+const encodingBase64 = "base64"
+
+// sniffLength is the number of leading bytes inspected for binary detection
+const sniffLength = 512
+
+// readFileOptions holds the parsed read_file arguments
+type readFileOptions struct {
+	encoding   string
+	lineOffset int
+	lineLimit  int
+	byteOffset int64
+	byteLength int64
+	byteRange  bool
+}
+
+// parseReadFileOptions parses and validates the read_file range and encoding arguments
+func parseReadFileOptions(input map[string]interface{}) (*readFileOptions, error) {
+	opts := &readFileOptions{encoding: "utf-8", lineOffset: 1}
+
+	if enc, ok := input["encoding"].(string); ok && enc != "" {
+		opts.encoding = strings.ToLower(enc)
+	}
+	if opts.encoding != encodingBase64 {
+		if _, err := textEncoding(opts.encoding); err != nil {
+			return nil, err
+		}
+	}
+
+	if v, ok := input["offset"].(float64); ok {
+		if v < 1 {
+			return nil, fmt.Errorf("%w: offset must be >= 1", ErrInvalidReadRange)
+		}
+		opts.lineOffset = int(v)
+	}
+	if v, ok := input["limit"].(float64); ok {
+		if v < 0 {
+			return nil, fmt.Errorf("%w: limit must be >= 0", ErrInvalidReadRange)
+		}
+		opts.lineLimit = int(v)
+	}
+	if v, ok := input["byte_offset"].(float64); ok {
+		if v < 0 {
+			return nil, fmt.Errorf("%w: byte_offset must be >= 0", ErrInvalidReadRange)
+		}
+		opts.byteOffset = int64(v)
+		opts.byteRange = true
+	}
+	if v, ok := input["byte_length"].(float64); ok {
+		if v < 0 {
+			return nil, fmt.Errorf("%w: byte_length must be >= 0", ErrInvalidReadRange)
+		}
+		opts.byteLength = int64(v)
+		opts.byteRange = true
+	}
+
+	return opts, nil
+}
+
+// textEncoding returns the decoder for a named charset (nil for UTF-8)
+func textEncoding(name string) (encoding.Encoding, error) {
+	switch name {
+	case "utf-8", "utf8":
+		return nil, nil
+	case "latin1", "latin-1", "iso-8859-1":
+		return charmap.ISO8859_1, nil
+	case "windows-1252", "cp1252":
+		return charmap.Windows1252, nil
+	case "utf-16":
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), nil
+	case "utf-16le":
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), nil
+	case "utf-16be":
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, name)
+}
+
+// readFile reads a window of a file without loading more than the handler's max file size
+func readFile(path string, opts *readFileOptions, handler *resources.ResourceHandler) *entities.ToolResult {
+	file, err := os.Open(path) //nolint:gosec // G304: path is sanitized via filepath.Abs
+	if err != nil {
+		return entities.NewErrorToolResult(err)
+	}
+	defer func() { _ = file.Close() }()
+
+	info, err := file.Stat()
+	if err != nil {
+		return entities.NewErrorToolResult(err)
+	}
+	if info.IsDir() {
+		return entities.NewErrorToolResult(fmt.Errorf("%s is a directory", path))
+	}
+
+	maxSize := handler.MaxFileSize()
+	if maxSize <= 0 {
+		maxSize = resources.DefaultMaxFileSize
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := file.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return entities.NewErrorToolResult(err)
+	}
+	head = head[:n]
+
+	mimeType, binary := detectContentType(handler.DetectMimeType(path), head, opts.encoding)
+	if binary {
+		return readBinaryWindow(file, path, info.Size(), mimeType, opts, maxSize)
+	}
+	return readTextWindow(file, info.Size(), mimeType, opts, maxSize)
+}
+
+// detectContentType combines extension-based MIME detection with content sniffing
+func detectContentType(mimeType string, head []byte, enc string) (string, bool) {
+	if enc == encodingBase64 {
+		return mimeType, true
+	}
+
+	mt, _ := vo.NewMimeType(mimeType)
+	if !mt.IsText() {
+		return mimeType, true
+	}
+
+	// UTF-16 text legitimately contains NUL bytes
+	if strings.HasPrefix(enc, "utf-16") {
+		return mimeType, false
+	}
+
+	if bytes.IndexByte(head, 0) >= 0 {
+		sniffed := http.DetectContentType(head)
+		if strings.HasPrefix(sniffed, "text/") {
+			sniffed = "application/octet-stream"
+		}
+		return sniffed, true
+	}
+
+	return mimeType, false
+}
+
+// readBinaryWindow returns a byte range of a binary file as base64 image or blob content
+func readBinaryWindow(file *os.File, path string, size int64, mimeType string, opts *readFileOptions, maxSize int64) *entities.ToolResult {
+	if opts.byteOffset > size {
+		return entities.NewErrorToolResult(fmt.Errorf("%w: byte_offset %d beyond end of file (%d bytes)", ErrInvalidReadRange, opts.byteOffset, size))
+	}
+
+	want := size - opts.byteOffset
+	if opts.byteLength > 0 && opts.byteLength < want {
+		want = opts.byteLength
+	}
+	length := want
+	if length > maxSize {
+		length = maxSize
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(io.NewSectionReader(file, opts.byteOffset, length), data); err != nil {
+		return entities.NewErrorToolResult(err)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var result *entities.ToolResult
+	if strings.HasPrefix(mimeType, "image/") {
+		result = entities.NewImageToolResult(encoded, mimeType)
+	} else {
+		result = entities.NewBlobToolResult("file://"+path, encoded, mimeType)
+	}
+
+	truncated := length < want
+	result.SetMeta("mime_type", mimeType)
+	result.SetMeta("encoding", encodingBase64)
+	result.SetMeta("total_size", size)
+	result.SetMeta("byte_offset", opts.byteOffset)
+	result.SetMeta("bytes_read", length)
+	result.SetMeta("truncated", truncated)
+	if truncated {
+		result.Content = append(result.Content, truncationNotice(length, size, maxSize))
+	}
+
+	return result
+}
+
+// readTextWindow returns a decoded byte or line range of a text file
+func readTextWindow(file *os.File, size int64, mimeType string, opts *readFileOptions, maxSize int64) *entities.ToolResult {
+	enc, err := textEncoding(opts.encoding)
+	if err != nil {
+		return entities.NewErrorToolResult(err)
+	}
+
+	var result *entities.ToolResult
+	if opts.byteRange {
+		result, err = readTextBytes(file, size, enc, opts, maxSize)
+	} else {
+		result, err = readTextLines(file, enc, opts, maxSize)
+	}
+	if err != nil {
+		return entities.NewErrorToolResult(err)
+	}
+
+	result.SetMeta("mime_type", mimeType)
+	result.SetMeta("encoding", opts.encoding)
+	result.SetMeta("total_size", size)
+	if truncated, _ := result.Meta["truncated"].(bool); truncated {
+		read, _ := result.Meta["bytes_read"].(int64)
+		result.Content = append(result.Content, truncationNotice(read, size, maxSize))
+	}
+
+	return result
+}
+
+// readTextBytes decodes a byte range of a text file
+func readTextBytes(file *os.File, size int64, enc encoding.Encoding, opts *readFileOptions, maxSize int64) (*entities.ToolResult, error) {
+	if opts.byteOffset > size {
+		return nil, fmt.Errorf("%w: byte_offset %d beyond end of file (%d bytes)", ErrInvalidReadRange, opts.byteOffset, size)
+	}
+
+	want := size - opts.byteOffset
+	if opts.byteLength > 0 && opts.byteLength < want {
+		want = opts.byteLength
+	}
+	length := want
+	if length > maxSize {
+		length = maxSize
+	}
+
+	var reader io.Reader = io.NewSectionReader(file, opts.byteOffset, length)
+	if enc != nil {
+		reader = enc.NewDecoder().Reader(reader)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	result := entities.NewTextToolResult(string(data))
+	result.SetMeta("byte_offset", opts.byteOffset)
+	result.SetMeta("bytes_read", length)
+	result.SetMeta("truncated", length < want)
+	return result, nil
+}
+
+// readTextLines decodes a line range of a text file, streaming past skipped lines
+func readTextLines(file *os.File, enc encoding.Encoding, opts *readFileOptions, maxSize int64) (*entities.ToolResult, error) {
+	var reader io.Reader = file
+	if enc != nil {
+		reader = enc.NewDecoder().Reader(reader)
+	}
+	br := bufio.NewReader(reader)
+
+	// Skip lines before the requested offset without buffering them
+	for line := 1; line < opts.lineOffset; line++ {
+		if _, _, err := readLine(br, 0); err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("%w: offset %d beyond end of file (%d lines)", ErrInvalidReadRange, opts.lineOffset, line-1)
+			}
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	linesRead := 0
+	truncated := false
+	for opts.lineLimit == 0 || linesRead < opts.lineLimit {
+		remaining := maxSize - int64(out.Len())
+		if remaining <= 0 {
+			_, err := br.Peek(1)
+			truncated = err == nil
+			break
+		}
+		data, complete, err := readLine(br, remaining)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		out.Write(data)
+		linesRead++
+		if !complete {
+			truncated = true
+			break
+		}
+	}
+
+	// Lines left over after the limit are reported as more content, not truncation
+	hasMore := false
+	if !truncated {
+		if _, err := br.Peek(1); err == nil {
+			hasMore = true
+		}
+	}
+
+	result := entities.NewTextToolResult(out.String())
+	result.SetMeta("start_line", opts.lineOffset)
+	result.SetMeta("lines_read", linesRead)
+	result.SetMeta("bytes_read", int64(out.Len()))
+	result.SetMeta("truncated", truncated)
+	if hasMore {
+		result.SetMeta("next_offset", opts.lineOffset+linesRead)
+	}
+	return result, nil
+}
+
+// readLine reads one line including its newline, keeping at most limit bytes.
+// A limit of 0 discards the line. It reports whether the whole line fit and
+// returns io.EOF only when no bytes were left to read.
+func readLine(br *bufio.Reader, limit int64) ([]byte, bool, error) {
+	var line []byte
+	seen := false
+	for {
+		fragment, err := br.ReadSlice('\n')
+		seen = seen || len(fragment) > 0
+		if limit > 0 {
+			room := limit - int64(len(line))
+			if int64(len(fragment)) > room {
+				line = append(line, fragment[:room]...)
+				return line, false, nil
+			}
+			line = append(line, fragment...)
+		}
+		switch {
+		case err == bufio.ErrBufferFull:
+			continue
+		case err == io.EOF && seen:
+			return line, true, nil
+		default:
+			return line, true, err
+		}
+	}
+}
+
+// truncationNotice builds the text block appended to results cut by the max-size guard
+func truncationNotice(read, total, maxSize int64) entities.ToolResultContent {
+	return entities.ToolResultContent{
+		Type: "text",
+		Text: fmt.Sprintf("[truncated: returned %d of %d bytes; max read size is %d bytes — use offset/limit or byte_offset/byte_length to read more]", read, total, maxSize),
+	}
+}
@@ -0,0 +1,331 @@
+// Package tools provides built-in MCP tools
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+)
+
+const (
+	// defaultHTTPToolTimeout applies when a definition sets no timeout
+	defaultHTTPToolTimeout = 30 * time.Second
+
+	// maxHTTPToolResponse caps how much of a response body is read
+	maxHTTPToolResponse = 1024 * 1024
+)
+
+// toolTemplateFuncs are available in HTTP tool and pipeline templates in addition to the text/template builtins
+var toolTemplateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// httpTool is a compiled HTTP tool definition
+type httpTool struct {
+	def    config.HTTPToolConfig
+	method string
+	url    *template.Template
+	query  map[string]*template.Template
+	body   *template.Template
+	path   *jsonPath
+	schema *entities.JSONSchema
+	client *http.Client
+}
+
+// NewHTTPTool compiles a declarative HTTP tool definition into a tool
+func NewHTTPTool(def config.HTTPToolConfig, client *http.Client) (*entities.Tool, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	name, err := vo.NewToolName(def.Name)
+	if err != nil {
+		return nil, fmt.Errorf("http tool %q: %w", def.Name, err)
+	}
+	desc, err := vo.NewToolDescription(def.Description)
+	if err != nil {
+		return nil, fmt.Errorf("http tool %s: %w", def.Name, err)
+	}
+
+	t := &httpTool{
+		def:    def,
+		method: strings.ToUpper(def.Method),
+		query:  make(map[string]*template.Template, len(def.Query)),
+		client: client,
+	}
+	if t.method == "" {
+		t.method = http.MethodGet
+	}
+
+	if t.schema, err = decodeSchema(def.InputSchema); err != nil {
+		return nil, fmt.Errorf("http tool %s: invalid input_schema: %w", def.Name, err)
+	}
+	if t.url, err = parseToolTemplate("url", def.URL); err != nil {
+		return nil, fmt.Errorf("http tool %s: %w", def.Name, err)
+	}
+	for key, value := range def.Query {
+		if t.query[key], err = parseToolTemplate("query."+key, value); err != nil {
+			return nil, fmt.Errorf("http tool %s: %w", def.Name, err)
+		}
+	}
+	if def.Body != "" {
+		if t.body, err = parseToolTemplate("body", def.Body); err != nil {
+			return nil, fmt.Errorf("http tool %s: %w", def.Name, err)
+		}
+	}
+	if def.ResponsePath != "" {
+		if t.path, err = compileJSONPath(def.ResponsePath); err != nil {
+			return nil, fmt.Errorf("http tool %s: %w", def.Name, err)
+		}
+	}
+
+	tool, err := entities.NewTool(name, desc, t.schema)
+	if err != nil {
+		return nil, err
+	}
+	category := def.Category
+	if category == "" {
+		category = "http"
+	}
+	tool.SetCategory(category)
+	tool.SetTags(append([]string{"http"}, def.Tags...))
+	timeout := def.Timeout
+	if timeout <= 0 {
+		timeout = defaultHTTPToolTimeout
+	}
+	tool.SetTimeout(timeout)
+	tool.SetMetadata("http_method", t.method)
+	tool.SetContextHandler(t.execute)
+	return tool, nil
+}
+
+// parseToolTemplate parses a template that fails on references to unknown fields
+func parseToolTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(toolTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// decodeSchema converts a YAML input schema to a JSON schema
+func decodeSchema(raw map[string]interface{}) (*entities.JSONSchema, error) {
+	schema := &entities.JSONSchema{Type: "object"}
+	if len(raw) == 0 {
+		return schema, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// execute renders the request, calls the endpoint and extracts the result
+func (t *httpTool) execute(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+	data := t.templateData(input)
+
+	urlData, err := escapeURLData(data)
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+	target, err := renderTemplate(t.url, urlData)
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+	reqURL, err := url.Parse(target)
+	if err != nil || (reqURL.Scheme != "http" && reqURL.Scheme != "https") {
+		return entities.NewErrorToolResult(fmt.Errorf("invalid request url %q", target)), nil
+	}
+
+	if len(t.query) > 0 {
+		values := reqURL.Query()
+		keys := make([]string, 0, len(t.query))
+		for key := range t.query {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, err := renderTemplate(t.query[key], data)
+			if err != nil {
+				return entities.NewErrorToolResult(err), nil
+			}
+			if value != "" {
+				values.Set(key, value)
+			}
+		}
+		reqURL.RawQuery = values.Encode()
+	}
+
+	var body io.Reader
+	if t.body != nil {
+		rendered, err := renderTemplate(t.body, data)
+		if err != nil {
+			return entities.NewErrorToolResult(err), nil
+		}
+		body = strings.NewReader(rendered)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, t.method, reqURL.String(), body)
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+	for key, value := range t.def.Headers {
+		req.Header.Set(key, os.ExpandEnv(value))
+	}
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPToolResponse+1))
+	if err != nil {
+		return nil, err
+	}
+	truncated := len(raw) > maxHTTPToolResponse
+	if truncated {
+		raw = raw[:maxHTTPToolResponse]
+	}
+
+	result := t.buildResult(resp.StatusCode, raw, truncated)
+	result.SetMeta("status_code", resp.StatusCode)
+	if truncated {
+		result.SetMeta("truncated", true)
+	}
+	return result, nil
+}
+
+// buildResult turns a response into a tool result, applying the response path
+func (t *httpTool) buildResult(status int, raw []byte, truncated bool) *entities.ToolResult {
+	if status >= http.StatusBadRequest {
+		result := entities.NewTextToolResult(fmt.Sprintf("HTTP %d %s\n%s", status, http.StatusText(status), raw))
+		result.IsError = true
+		return result
+	}
+	if t.path == nil {
+		return entities.NewTextToolResult(string(raw))
+	}
+	if truncated {
+		return entities.NewErrorToolResult(fmt.Errorf("response exceeds %d bytes; cannot apply response_path", maxHTTPToolResponse))
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return entities.NewErrorToolResult(fmt.Errorf("response is not JSON: %w", err))
+	}
+
+	matches := t.path.eval(doc)
+	if len(matches) == 0 {
+		return entities.NewErrorToolResult(fmt.Errorf("response_path %s matched nothing", t.def.ResponsePath))
+	}
+	var value interface{} = matches
+	if t.path.definite {
+		value = matches[0]
+	}
+	if text, ok := value.(string); ok {
+		return entities.NewTextToolResult(text)
+	}
+	formatted, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return entities.NewErrorToolResult(err)
+	}
+	return entities.NewTextToolResult(string(formatted))
+}
+
+// templateData returns the arguments with absent schema properties rendered as empty strings
+func (t *httpTool) templateData(input map[string]interface{}) map[string]interface{} {
+	return withSchemaDefaults(t.schema, input)
+}
+
+// withSchemaDefaults copies input, adding absent schema properties as empty strings
+func withSchemaDefaults(schema *entities.JSONSchema, input map[string]interface{}) map[string]interface{} {
+	data := make(map[string]interface{}, len(input)+len(schema.Properties))
+	for key := range schema.Properties {
+		data[key] = ""
+	}
+	for key, value := range input {
+		data[key] = value
+	}
+	return data
+}
+
+// escapeURLData escapes every value so it is safe in any part of a URL. Arrays and
+// objects are rendered as escaped JSON so their contents cannot add path segments,
+// a query or a fragment
+func escapeURLData(data map[string]interface{}) (map[string]interface{}, error) {
+	escaped := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		switch v := value.(type) {
+		case nil:
+			escaped[key] = ""
+		case string:
+			escaped[key] = escapeURLValue(v)
+		case float64, int, int64, bool, json.Number:
+			escaped[key] = escapeURLValue(fmt.Sprint(v))
+		default:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("argument %s cannot be used in the url: %w", key, err)
+			}
+			escaped[key] = escapeURLValue(string(encoded))
+		}
+	}
+	return escaped, nil
+}
+
+// escapeURLValue percent-encodes everything outside the unreserved set
+func escapeURLValue(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// renderTemplate executes a template to a string
+func renderTemplate(tmpl *template.Template, data map[string]interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", tmpl.Name(), err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,216 @@
+// Package tools provides built-in MCP tools
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidJSONPath is returned for unsupported or malformed JSONPath expressions
+var ErrInvalidJSONPath = errors.New("invalid JSONPath")
+
+// jsonPathStep is one segment of a compiled JSONPath expression
+type jsonPathStep struct {
+	name      string
+	index     int
+	isIndex   bool
+	wildcard  bool
+	recursive bool
+}
+
+// jsonPath is a compiled JSONPath expression.
+// Supported: $, .name, ['name'], [n] (negative counts from the end), [*], .* and ..name
+type jsonPath struct {
+	steps    []jsonPathStep
+	definite bool
+}
+
+// compileJSONPath parses a JSONPath expression
+func compileJSONPath(expr string) (*jsonPath, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("%w: %q must start with '$'", ErrInvalidJSONPath, expr)
+	}
+
+	path := &jsonPath{definite: true}
+	rest := expr[1:]
+	for rest != "" {
+		var step jsonPathStep
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			step.recursive = true
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				var err error
+				if step, rest, err = parseBracket(rest, expr); err != nil {
+					return nil, err
+				}
+				step.recursive = true
+				break
+			}
+			step.name, rest = parseDotName(rest)
+		case strings.HasPrefix(rest, "."):
+			step.name, rest = parseDotName(rest[1:])
+		case strings.HasPrefix(rest, "["):
+			var err error
+			if step, rest, err = parseBracket(rest, expr); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q in %q", ErrInvalidJSONPath, rest, expr)
+		}
+
+		if step.name == "*" && !step.isIndex {
+			step.name = ""
+			step.wildcard = true
+		}
+		if step.name == "" && !step.isIndex && !step.wildcard {
+			return nil, fmt.Errorf("%w: empty segment in %q", ErrInvalidJSONPath, expr)
+		}
+		if step.wildcard || step.recursive {
+			path.definite = false
+		}
+		path.steps = append(path.steps, step)
+	}
+	return path, nil
+}
+
+// parseDotName reads a member name up to the next '.' or '['
+func parseDotName(s string) (string, string) {
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+// parseBracket reads a ['name'], ["name"], [n] or [*] segment
+func parseBracket(s, expr string) (jsonPathStep, string, error) {
+	var step jsonPathStep
+	s = s[1:]
+
+	if s != "" && (s[0] == '\'' || s[0] == '"') {
+		quote := s[0]
+		end := strings.IndexByte(s[1:], quote)
+		if end < 0 || !strings.HasPrefix(s[end+2:], "]") {
+			return step, "", fmt.Errorf("%w: unterminated name in %q", ErrInvalidJSONPath, expr)
+		}
+		step.name = s[1 : end+1]
+		if step.name == "" {
+			return step, "", fmt.Errorf("%w: empty name in %q", ErrInvalidJSONPath, expr)
+		}
+		return step, s[end+3:], nil
+	}
+
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return step, "", fmt.Errorf("%w: missing ']' in %q", ErrInvalidJSONPath, expr)
+	}
+	token := strings.TrimSpace(s[:end])
+	if token == "*" {
+		step.wildcard = true
+		return step, s[end+1:], nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil {
+		return step, "", fmt.Errorf("%w: bad index %q in %q", ErrInvalidJSONPath, token, expr)
+	}
+	step.index = index
+	step.isIndex = true
+	return step, s[end+1:], nil
+}
+
+// eval returns every value matched by the path, in document order
+func (p *jsonPath) eval(doc interface{}) []interface{} {
+	nodes := []interface{}{doc}
+	for _, step := range p.steps {
+		var next []interface{}
+		for _, node := range nodes {
+			if step.recursive {
+				for _, candidate := range descendants(node) {
+					next = append(next, step.apply(candidate)...)
+				}
+				continue
+			}
+			next = append(next, step.apply(node)...)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// apply selects the children of node matched by the step
+func (s jsonPathStep) apply(node interface{}) []interface{} {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		if s.wildcard {
+			keys := make([]string, 0, len(value))
+			for key := range value {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			out := make([]interface{}, len(keys))
+			for i, key := range keys {
+				out[i] = value[key]
+			}
+			return out
+		}
+		if child, ok := value[s.name]; ok && !s.isIndex {
+			return []interface{}{child}
+		}
+	case []interface{}:
+		if s.wildcard {
+			return value
+		}
+		if s.isIndex {
+			index := s.index
+			if index < 0 {
+				index += len(value)
+			}
+			if index >= 0 && index < len(value) {
+				return []interface{}{value[index]}
+			}
+		}
+	}
+	return nil
+}
+
+// descendants returns node and everything below it, depth first
+func descendants(node interface{}) []interface{} {
+	out := []interface{}{node}
+	switch value := node.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			out = append(out, descendants(value[key])...)
+		}
+	case []interface{}:
+		for _, child := range value {
+			out = append(out, descendants(child)...)
+		}
+	}
+	return out
+}
@@ -0,0 +1,194 @@
+// Package tools contains built-in MCP tools for TelemetryFlow
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// streamProgressInterval is the minimum time between two streamed text notifications
+const streamProgressInterval = 100 * time.Millisecond
+
+// textStreamer forwards streamed LLM text as progress notifications, coalescing the deltas that
+// arrive within one interval. Progress counts the characters streamed so far
+type textStreamer struct {
+	ctx      context.Context
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending strings.Builder
+	sent    int
+	last    time.Time
+}
+
+// newTextStreamer returns a streamer reporting to the progress reporter in ctx, or nil when the
+// client did not ask for progress
+func newTextStreamer(ctx context.Context) *textStreamer {
+	if _, ok := entities.ProgressReporterFromContext(ctx); !ok {
+		return nil
+	}
+	return &textStreamer{ctx: ctx, interval: streamProgressInterval}
+}
+
+// Handler returns the delta handler to pass to the stream, or nil for a nil streamer
+func (s *textStreamer) Handler() services.StreamTextHandler {
+	if s == nil {
+		return nil
+	}
+	return s.write
+}
+
+// Close sends any text still buffered
+func (s *textStreamer) Close() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flushLocked()
+}
+
+func (s *textStreamer) write(text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending.WriteString(text)
+	if time.Since(s.last) >= s.interval {
+		s.flushLocked()
+	}
+}
+
+func (s *textStreamer) flushLocked() {
+	if s.pending.Len() == 0 {
+		return
+	}
+	message := s.pending.String()
+	s.pending.Reset()
+	s.sent += utf8.RuneCountInString(message)
+	s.last = time.Now()
+
+	entities.ReportProgress(s.ctx, entities.ToolProgress{
+		Progress: float64(s.sent),
+		Message:  message,
+	})
+}
+
+// streamMessage sends a request, streaming its text to the client when it asked for progress.
+// Returning early cancels the stream, which aborts the upstream request
+func streamMessage(ctx context.Context, llm services.IClaudeService, request *services.ClaudeRequest) (*services.ClaudeResponse, error) {
+	streamer := newTextStreamer(ctx)
+	if streamer == nil {
+		return llm.CreateMessage(ctx, request)
+	}
+	defer streamer.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	request.Stream = true
+	events, err := llm.CreateMessageStream(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	response, err := services.CollectStream(events, streamer.Handler())
+	if err == nil {
+		// A cancelled stream may simply end, leaving a truncated response
+		err = ctx.Err()
+	}
+	return response, err
+}
+
+// responseText joins the text blocks of a response
+func responseText(response *services.ClaudeResponse) string {
+	if response == nil {
+		return ""
+	}
+	var text string
+	for _, block := range response.Content {
+		if block.Type == vo.ContentTypeText {
+			text += block.Text
+		}
+	}
+	return text
+}
+
+// setResponseMeta records the serving model, stop reason and token usage of a response
+func setResponseMeta(result *entities.ToolResult, response *services.ClaudeResponse) {
+	if response == nil {
+		return
+	}
+	if response.ServedBy != "" {
+		result.SetMeta("model", response.ServedBy.String())
+	}
+	if response.StopReason != "" {
+		result.SetMeta("stop_reason", response.StopReason)
+	}
+	if response.Usage != nil {
+		result.SetMeta("usage", response.Usage)
+	}
+}
+
+// reasoningProperties returns the schema of the inputs that ask the model to reason
+func reasoningProperties() map[string]*entities.JSONSchema {
+	efforts := make([]interface{}, 0, len(vo.AllReasoningEfforts()))
+	for _, effort := range vo.AllReasoningEfforts() {
+		efforts = append(efforts, effort.String())
+	}
+	return map[string]*entities.JSONSchema{
+		"reasoning_effort": {
+			Type:        "string",
+			Description: "Let the model reason before it answers, with a thinking budget of 2048, 8192 or 24576 tokens (default: no reasoning). Models that cannot reason ignore it",
+			Enum:        efforts,
+		},
+		"reasoning_budget_tokens": {
+			Type:        "integer",
+			Description: fmt.Sprintf("Thinking budget in tokens, at least %d; takes precedence over reasoning_effort", vo.MinReasoningBudgetTokens),
+		},
+	}
+}
+
+// includeReasoningProperty is the schema of the include_reasoning input
+func includeReasoningProperty() *entities.JSONSchema {
+	return &entities.JSONSchema{
+		Type:        "boolean",
+		Description: "Return the model's reasoning in the reasoning field of the result metadata (default: false, reasoning is stripped)",
+	}
+}
+
+// reasoningInput reads the reasoning inputs; it returns nil when neither is set
+func reasoningInput(input map[string]interface{}) (*vo.Reasoning, error) {
+	effort, _ := input["reasoning_effort"].(string)
+	budget, _ := input["reasoning_budget_tokens"].(float64)
+	return vo.NewReasoning(effort, int(budget))
+}
+
+// setReasoningMeta returns the reasoning to the client when the call asked for it
+func setReasoningMeta(result *entities.ToolResult, input map[string]interface{}, reasoning string) {
+	if include, _ := input["include_reasoning"].(bool); include && reasoning != "" {
+		result.SetMeta("reasoning", reasoning)
+	}
+}
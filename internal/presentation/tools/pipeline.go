@@ -0,0 +1,576 @@
+// Package tools provides built-in MCP tools
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+)
+
+// Pipeline errors
+var (
+	ErrPipelineStepFailed = errors.New("pipeline step failed")
+	ErrPipelineTooDeep    = errors.New("pipelines are nested too deeply")
+	ErrPipelineNoOutput   = errors.New("no pipeline step succeeded")
+)
+
+// Pipeline step statuses
+const (
+	StepStatusOK        = "ok"
+	StepStatusFailed    = "failed"
+	StepStatusSkipped   = "skipped"
+	StepStatusCancelled = "cancelled"
+)
+
+const (
+	// defaultPipelineTimeout applies when a pipeline sets no timeout
+	defaultPipelineTimeout = 5 * time.Minute
+
+	// maxPipelineDepth limits pipelines calling pipelines
+	maxPipelineDepth = 4
+
+	// pipelineTracerName identifies pipeline spans
+	pipelineTracerName = "github.com/telemetryflow/telemetryflow-go-mcp/pipeline"
+)
+
+// ToolLookup resolves a tool by name when a pipeline step runs
+type ToolLookup func(ctx context.Context, name string) (*entities.Tool, error)
+
+// RepositoryLookup resolves pipeline step tools from a tool repository
+func RepositoryLookup(repo repositories.IToolRepository) ToolLookup {
+	return func(ctx context.Context, name string) (*entities.Tool, error) {
+		toolName, err := vo.NewToolName(name)
+		if err != nil {
+			return nil, err
+		}
+		tool, err := repo.FindByName(ctx, toolName)
+		if err != nil {
+			return nil, err
+		}
+		if tool == nil {
+			return nil, fmt.Errorf("tool %s not found", name)
+		}
+		if !tool.IsEnabled() {
+			return nil, fmt.Errorf("tool %s is disabled", name)
+		}
+		return tool, nil
+	}
+}
+
+// pipelineStep is a compiled pipeline step
+type pipelineStep struct {
+	config.PipelineStepConfig
+	args map[string]interface{} // string templates are replaced by *template.Template
+	when *template.Template
+	// visible lists the steps whose results the step's templates may reference
+	visible []string
+}
+
+// pipelineTool is a compiled pipeline definition
+type pipelineTool struct {
+	def    config.PipelineConfig
+	schema *entities.JSONSchema
+	steps  []*pipelineStep
+	output *template.Template
+	lookup ToolLookup
+}
+
+// StepTrace records how one step of a pipeline run went
+type StepTrace struct {
+	ID         string `json:"id"`
+	Tool       string `json:"tool"`
+	Status     string `json:"status"`
+	Attempts   int    `json:"attempts,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// pipelineDepthKey is the context key for the current pipeline nesting depth
+type pipelineDepthKey struct{}
+
+// NewPipelineTool compiles a pipeline definition into a tool that runs its steps as a DAG
+func NewPipelineTool(def config.PipelineConfig, lookup ToolLookup) (*entities.Tool, error) {
+	if err := config.ValidatePipelines([]config.PipelineConfig{def}); err != nil {
+		return nil, err
+	}
+
+	name, err := vo.NewToolName(def.Name)
+	if err != nil {
+		return nil, fmt.Errorf("pipeline %q: %w", def.Name, err)
+	}
+	desc, err := vo.NewToolDescription(def.Description)
+	if err != nil {
+		return nil, fmt.Errorf("pipeline %s: %w", def.Name, err)
+	}
+
+	p := &pipelineTool{def: def, lookup: lookup}
+	if p.schema, err = decodeSchema(def.InputSchema); err != nil {
+		return nil, fmt.Errorf("pipeline %s: invalid input_schema: %w", def.Name, err)
+	}
+
+	deps := make(map[string][]string, len(def.Steps))
+	for _, step := range def.Steps {
+		deps[step.ID] = step.DependsOn
+	}
+	for _, stepDef := range def.Steps {
+		step := &pipelineStep{PipelineStepConfig: stepDef, visible: ancestors(stepDef.ID, deps)}
+		args, err := compileArguments("steps."+stepDef.ID, stepDef.Arguments)
+		if err != nil {
+			return nil, fmt.Errorf("pipeline %s: %w", def.Name, err)
+		}
+		step.args = args.(map[string]interface{})
+		if stepDef.When != "" {
+			if step.when, err = parseToolTemplate("steps."+stepDef.ID+".when", stepDef.When); err != nil {
+				return nil, fmt.Errorf("pipeline %s: %w", def.Name, err)
+			}
+		}
+		p.steps = append(p.steps, step)
+	}
+	if def.Output != "" {
+		if p.output, err = parseToolTemplate("output", def.Output); err != nil {
+			return nil, fmt.Errorf("pipeline %s: %w", def.Name, err)
+		}
+	}
+
+	tool, err := entities.NewTool(name, desc, p.schema)
+	if err != nil {
+		return nil, err
+	}
+	category := def.Category
+	if category == "" {
+		category = "pipeline"
+	}
+	tool.SetCategory(category)
+	tool.SetTags(append([]string{"pipeline"}, def.Tags...))
+	timeout := def.Timeout
+	if timeout <= 0 {
+		timeout = defaultPipelineTimeout
+	}
+	tool.SetTimeout(timeout)
+	tool.SetMetadata("pipeline_steps", len(def.Steps))
+	tool.SetContextHandler(p.execute)
+	return tool, nil
+}
+
+// ancestors returns every step that id depends on, directly or transitively
+func ancestors(id string, deps map[string][]string) []string {
+	seen := make(map[string]bool)
+	out := []string{}
+	var walk func(string)
+	walk = func(current string) {
+		for _, dep := range deps[current] {
+			if !seen[dep] {
+				seen[dep] = true
+				out = append(out, dep)
+				walk(dep)
+			}
+		}
+	}
+	walk(id)
+	return out
+}
+
+// compileArguments replaces template strings in an argument tree with parsed templates
+func compileArguments(path string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if !strings.Contains(v, "{{") {
+			return v, nil
+		}
+		return parseToolTemplate(path, v)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			compiled, err := compileArguments(path+"."+key, child)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = compiled
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, child := range v {
+			compiled, err := compileArguments(fmt.Sprintf("%s[%d]", path, i), child)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = compiled
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// renderArguments renders the templates in a compiled argument tree
+func renderArguments(value interface{}, data map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case *template.Template:
+		return renderTemplate(v, data)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			rendered, err := renderArguments(child, data)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = rendered
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, child := range v {
+			rendered, err := renderArguments(child, data)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = rendered
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// coerceArgument converts a rendered template to the JSON type the target tool expects
+func coerceArgument(schema *entities.JSONSchema, key string, value interface{}) interface{} {
+	text, ok := value.(string)
+	if !ok || schema == nil || schema.Properties[key] == nil {
+		return value
+	}
+	switch schema.Properties[key].Type {
+	case "number", "integer", "boolean", "object", "array":
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(text), &decoded); err == nil {
+			return decoded
+		}
+	}
+	return value
+}
+
+// pipelineRun holds the state of one pipeline execution
+type pipelineRun struct {
+	p        *pipelineTool
+	input    map[string]interface{}
+	cancel   context.CancelFunc
+	reporter entities.ProgressReporter
+
+	mu        sync.Mutex
+	results   map[string]map[string]interface{}
+	traces    map[string]*StepTrace
+	failure   error
+	completed int
+	progress  float64
+}
+
+// execute runs the pipeline steps, each as soon as its dependencies have finished
+func (p *pipelineTool) execute(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+	depth, _ := ctx.Value(pipelineDepthKey{}).(int)
+	if depth >= maxPipelineDepth {
+		return entities.NewErrorToolResult(fmt.Errorf("%w: %s", ErrPipelineTooDeep, p.def.Name)), nil
+	}
+	ctx = context.WithValue(ctx, pipelineDepthKey{}, depth+1)
+
+	ctx, span := otel.Tracer(pipelineTracerName).Start(ctx, "pipeline "+p.def.Name,
+		trace.WithAttributes(
+			attribute.String("mcp.pipeline.name", p.def.Name),
+			attribute.Int("mcp.pipeline.steps", len(p.steps)),
+		))
+	defer span.End()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	run := &pipelineRun{
+		p:       p,
+		input:   withSchemaDefaults(p.schema, input),
+		cancel:  cancel,
+		results: make(map[string]map[string]interface{}, len(p.steps)),
+		traces:  make(map[string]*StepTrace, len(p.steps)),
+	}
+	run.reporter, _ = entities.ProgressReporterFromContext(ctx)
+
+	done := make(map[string]chan struct{}, len(p.steps))
+	for _, step := range p.steps {
+		done[step.ID] = make(chan struct{})
+	}
+	var wg sync.WaitGroup
+	for _, step := range p.steps {
+		wg.Add(1)
+		go func(step *pipelineStep) {
+			defer wg.Done()
+			defer close(done[step.ID])
+			for _, dep := range step.DependsOn {
+				select {
+				case <-done[dep]:
+				case <-ctx.Done():
+				}
+			}
+			run.runStep(ctx, step)
+		}(step)
+	}
+	wg.Wait()
+
+	traces := make([]StepTrace, 0, len(p.steps))
+	for _, step := range p.steps {
+		traces = append(traces, *run.traces[step.ID])
+	}
+
+	result := run.finish()
+	result.SetMeta("steps", traces)
+	if result.IsError {
+		span.SetStatus(codes.Error, "pipeline failed")
+	}
+	return result, nil
+}
+
+// finish builds the tool result once every step has ended
+func (r *pipelineRun) finish() *entities.ToolResult {
+	if r.failure != nil {
+		return entities.NewErrorToolResult(fmt.Errorf("pipeline %s: %w", r.p.def.Name, r.failure))
+	}
+
+	if r.p.output != nil {
+		text, err := renderTemplate(r.p.output, r.templateData(nil))
+		if err != nil {
+			return entities.NewErrorToolResult(err)
+		}
+		return entities.NewTextToolResult(text)
+	}
+
+	for i := len(r.p.steps) - 1; i >= 0; i-- {
+		result := r.results[r.p.steps[i].ID]
+		if result["status"] == StepStatusOK {
+			return entities.NewTextToolResult(result["text"].(string))
+		}
+	}
+	return entities.NewErrorToolResult(fmt.Errorf("pipeline %s: %w", r.p.def.Name, ErrPipelineNoOutput))
+}
+
+// runStep evaluates the step condition, calls the tool with retries and records the outcome
+func (r *pipelineRun) runStep(ctx context.Context, step *pipelineStep) {
+	start := time.Now()
+	stepTrace := &StepTrace{ID: step.ID, Tool: step.Tool}
+	output := map[string]interface{}{"status": "", "text": "", "json": nil, "error": ""}
+
+	record := func(status string, err error) {
+		stepTrace.Status = status
+		stepTrace.DurationMs = time.Since(start).Milliseconds()
+		output["status"] = status
+		if err != nil {
+			stepTrace.Error = err.Error()
+			output["error"] = err.Error()
+		}
+
+		r.mu.Lock()
+		r.results[step.ID] = output
+		r.traces[step.ID] = stepTrace
+		r.completed++
+		completed := r.completed
+		if status == StepStatusFailed && step.OnError != "continue" && r.failure == nil {
+			r.failure = fmt.Errorf("%w: %s: %v", ErrPipelineStepFailed, step.ID, err)
+			r.cancel()
+		}
+		r.mu.Unlock()
+
+		if status != StepStatusCancelled {
+			r.report(float64(completed), fmt.Sprintf("step %s (%s) %s", step.ID, step.Tool, status))
+		}
+	}
+
+	if ctx.Err() != nil {
+		record(StepStatusCancelled, nil)
+		return
+	}
+
+	ctx, span := otel.Tracer(pipelineTracerName).Start(ctx, "pipeline.step "+step.ID,
+		trace.WithAttributes(
+			attribute.String("mcp.pipeline.step", step.ID),
+			attribute.String("mcp.tool.name", step.Tool),
+		))
+	defer span.End()
+	fail := func(err error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		record(StepStatusFailed, err)
+	}
+
+	data := r.templateData(step.visible)
+	if step.when != nil {
+		cond, err := renderTemplate(step.when, data)
+		if err != nil {
+			fail(err)
+			return
+		}
+		if cond = strings.TrimSpace(cond); cond == "" || cond == "false" || cond == "0" {
+			span.SetAttributes(attribute.Bool("mcp.pipeline.skipped", true))
+			record(StepStatusSkipped, nil)
+			return
+		}
+	}
+
+	tool, err := r.p.lookup(ctx, step.Tool)
+	if err != nil {
+		fail(err)
+		return
+	}
+	rendered, err := renderArguments(step.args, data)
+	if err != nil {
+		fail(err)
+		return
+	}
+	args := rendered.(map[string]interface{})
+	for key, value := range args {
+		if _, templated := step.args[key].(*template.Template); templated {
+			args[key] = coerceArgument(tool.InputSchema(), key, value)
+		}
+	}
+
+	timeout := step.Timeout
+	if timeout <= 0 {
+		timeout = tool.Timeout()
+	}
+	stepCtx := entities.WithProgressReporter(ctx, r.forward(step.ID))
+
+	for attempt := 1; attempt <= step.Retries+1; attempt++ {
+		stepTrace.Attempts = attempt
+		callCtx, cancel := context.WithTimeout(stepCtx, timeout)
+		var result *entities.ToolResult
+		if execute, ok := handlers.NestedToolExecutorFromContext(ctx); ok {
+			result, err = execute(callCtx, step.Tool, args, timeout)
+		} else {
+			result, err = tool.ExecuteContext(callCtx, args)
+		}
+		cancel()
+
+		if err == nil && result != nil && !result.IsError {
+			span.SetAttributes(attribute.Int("mcp.pipeline.attempts", attempt))
+			text := resultText(result)
+			output["text"] = text
+			var decoded interface{}
+			if json.Unmarshal([]byte(text), &decoded) == nil {
+				output["json"] = decoded
+			}
+			record(StepStatusOK, nil)
+			return
+		}
+		if err == nil {
+			err = errors.New(resultText(result))
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	span.SetAttributes(attribute.Int("mcp.pipeline.attempts", stepTrace.Attempts))
+	if ctx.Err() != nil && !errors.Is(err, context.DeadlineExceeded) {
+		record(StepStatusCancelled, err)
+		return
+	}
+	fail(err)
+}
+
+// templateData returns the input and the results of the given steps for template rendering; nil means all steps
+func (r *pipelineRun) templateData(steps []string) map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := make(map[string]interface{}, len(r.results))
+	if steps == nil {
+		for id, result := range r.results {
+			results[id] = result
+		}
+	}
+	for _, id := range steps {
+		if result, ok := r.results[id]; ok {
+			results[id] = result
+		}
+	}
+	return map[string]interface{}{"input": r.input, "steps": results}
+}
+
+// report sends pipeline progress, keeping the reported value strictly increasing
+func (r *pipelineRun) report(progress float64, message string) {
+	if r.reporter == nil {
+		return
+	}
+	r.mu.Lock()
+	if progress <= r.progress {
+		r.mu.Unlock()
+		return
+	}
+	r.progress = progress
+	r.mu.Unlock()
+
+	r.reporter(entities.ToolProgress{Progress: progress, Total: float64(len(r.p.steps)), Message: message})
+}
+
+// forward relays progress from a step's tool as a fraction of the current step
+func (r *pipelineRun) forward(stepID string) entities.ProgressReporter {
+	return func(update entities.ToolProgress) {
+		if update.Total <= 0 {
+			return
+		}
+		fraction := update.Progress / update.Total
+		if fraction > 0.99 {
+			fraction = 0.99
+		}
+		r.mu.Lock()
+		completed := r.completed
+		r.mu.Unlock()
+
+		message := stepID
+		if update.Message != "" {
+			message += ": " + update.Message
+		}
+		r.report(float64(completed)+fraction, message)
+	}
+}
+
+// resultText joins the text content of a tool result
+func resultText(result *entities.ToolResult) string {
+	if result == nil {
+		return ""
+	}
+	var parts []string
+	for _, content := range result.Content {
+		if content.Text != "" {
+			parts = append(parts, content.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
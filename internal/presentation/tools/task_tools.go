@@ -0,0 +1,272 @@
+// Package tools contains built-in MCP tools for TelemetryFlow
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/commands"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/queries"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// Task tool errors
+var (
+	ErrNoSessionContext  = errors.New("tool call has no session context")
+	ErrTaskToolRecursion = errors.New("task tools cannot run in the background")
+)
+
+// maxTaskResultWait caps how long get_task_result blocks within one tool call
+const maxTaskResultWait = 60 * time.Second
+
+// taskToolNames lists the companion tools registered by SetTaskHandler
+var taskToolNames = map[string]bool{
+	"start_background_task": true,
+	"get_task_status":       true,
+	"get_task_result":       true,
+	"cancel_task":           true,
+}
+
+// SetTaskHandler registers the background task tools backed by handler
+func (r *ToolRegistry) SetTaskHandler(handler *handlers.TaskHandler) {
+	r.taskHandler = handler
+	r.registerStartBackgroundTask()
+	r.registerGetTaskStatus()
+	r.registerGetTaskResult()
+	r.registerCancelTask()
+}
+
+func (r *ToolRegistry) registerStartBackgroundTask() {
+	name, _ := vo.NewToolName("start_background_task")
+	desc, _ := vo.NewToolDescription("Start another tool in the background and return a task ID immediately. Use get_task_status, get_task_result and cancel_task to follow up")
+
+	schema := &entities.JSONSchema{
+		Type: "object",
+		Properties: map[string]*entities.JSONSchema{
+			"tool": {
+				Type:        "string",
+				Description: "Name of the tool to run",
+			},
+			"arguments": {
+				Type:        "object",
+				Description: "Arguments for the tool",
+			},
+			"ttl_seconds": {
+				Type:        "integer",
+				Description: "How long to keep the task and its result (default and maximum set by the server)",
+			},
+		},
+		Required: []string{"tool"},
+	}
+
+	tool, _ := entities.NewTool(name, desc, schema)
+	tool.SetCategory("tasks")
+	tool.SetTags([]string{"task", "background", "async"})
+	tool.SetContextHandler(r.handleStartBackgroundTask)
+
+	r.tools["start_background_task"] = tool
+}
+
+func (r *ToolRegistry) handleStartBackgroundTask(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+	sessionID, ok := handlers.SessionIDFromContext(ctx)
+	if !ok {
+		return entities.NewErrorToolResult(ErrNoSessionContext), nil
+	}
+
+	toolName, ok := input["tool"].(string)
+	if !ok || toolName == "" {
+		return entities.NewErrorToolResult(fmt.Errorf("tool is required")), nil
+	}
+	if taskToolNames[toolName] {
+		return entities.NewErrorToolResult(ErrTaskToolRecursion), nil
+	}
+
+	arguments, _ := input["arguments"].(map[string]interface{})
+	var ttl time.Duration
+	if seconds, ok := input["ttl_seconds"].(float64); ok && seconds > 0 {
+		ttl = time.Duration(seconds) * time.Second
+	}
+
+	task, err := r.taskHandler.HandleSubmitToolTask(ctx, &commands.SubmitToolTaskCommand{
+		SessionID: sessionID,
+		Name:      toolName,
+		Arguments: arguments,
+		TTL:       ttl,
+	})
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+
+	return taskStatusResult(task), nil
+}
+
+func (r *ToolRegistry) registerGetTaskStatus() {
+	name, _ := vo.NewToolName("get_task_status")
+	desc, _ := vo.NewToolDescription("Get the status of a background task")
+
+	tool, _ := entities.NewTool(name, desc, taskIDSchema(nil))
+	tool.SetCategory("tasks")
+	tool.SetTags([]string{"task", "background", "status"})
+	tool.SetContextHandler(r.handleGetTaskStatus)
+
+	r.tools["get_task_status"] = tool
+}
+
+func (r *ToolRegistry) handleGetTaskStatus(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+	sessionID, taskID, errResult := taskToolInput(ctx, input)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	task, err := r.taskHandler.HandleGetTask(ctx, &queries.GetTaskQuery{SessionID: sessionID, TaskID: taskID})
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+
+	return taskStatusResult(task), nil
+}
+
+func (r *ToolRegistry) registerGetTaskResult() {
+	name, _ := vo.NewToolName("get_task_result")
+	desc, _ := vo.NewToolDescription("Get the result of a background task, optionally waiting for it to finish. Returns the current status if the task is still running")
+
+	tool, _ := entities.NewTool(name, desc, taskIDSchema(map[string]*entities.JSONSchema{
+		"wait_seconds": {
+			Type:        "integer",
+			Description: fmt.Sprintf("Seconds to wait for the task to finish (default: 0, max: %d)", int(maxTaskResultWait.Seconds())),
+		},
+	}))
+	tool.SetCategory("tasks")
+	tool.SetTags([]string{"task", "background", "result"})
+	tool.SetContextHandler(r.handleGetTaskResult)
+	tool.SetTimeout(maxTaskResultWait + 5*time.Second)
+
+	r.tools["get_task_result"] = tool
+}
+
+func (r *ToolRegistry) handleGetTaskResult(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+	sessionID, taskID, errResult := taskToolInput(ctx, input)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	var wait time.Duration
+	if seconds, ok := input["wait_seconds"].(float64); ok && seconds > 0 {
+		wait = min(time.Duration(seconds)*time.Second, maxTaskResultWait)
+	}
+
+	task, err := r.taskHandler.HandleGetTaskResult(ctx, &queries.GetTaskResultQuery{
+		SessionID: sessionID,
+		TaskID:    taskID,
+		Wait:      wait,
+	})
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+
+	if task.Result() == nil {
+		return taskStatusResult(task), nil
+	}
+
+	// Return the tool's own content with the task attached
+	result := &entities.ToolResult{
+		Content: task.Result().Content,
+		IsError: task.Result().IsError,
+		Meta:    make(map[string]interface{}, len(task.Result().Meta)+1),
+	}
+	for key, value := range task.Result().Meta {
+		result.Meta[key] = value
+	}
+	result.SetMeta("task", task.ToMCPTask())
+	return result, nil
+}
+
+func (r *ToolRegistry) registerCancelTask() {
+	name, _ := vo.NewToolName("cancel_task")
+	desc, _ := vo.NewToolDescription("Cancel a running background task")
+
+	tool, _ := entities.NewTool(name, desc, taskIDSchema(nil))
+	tool.SetCategory("tasks")
+	tool.SetTags([]string{"task", "background", "cancel"})
+	tool.SetContextHandler(r.handleCancelTask)
+
+	r.tools["cancel_task"] = tool
+}
+
+func (r *ToolRegistry) handleCancelTask(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+	sessionID, taskID, errResult := taskToolInput(ctx, input)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	task, err := r.taskHandler.HandleCancelTask(ctx, &commands.CancelTaskCommand{SessionID: sessionID, TaskID: taskID})
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+
+	return taskStatusResult(task), nil
+}
+
+// taskIDSchema builds an input schema with a required task_id and optional extras
+func taskIDSchema(extra map[string]*entities.JSONSchema) *entities.JSONSchema {
+	properties := map[string]*entities.JSONSchema{
+		"task_id": {
+			Type:        "string",
+			Description: "The task ID returned by start_background_task or a task-augmented tools/call",
+		},
+	}
+	for key, schema := range extra {
+		properties[key] = schema
+	}
+	return &entities.JSONSchema{
+		Type:       "object",
+		Properties: properties,
+		Required:   []string{"task_id"},
+	}
+}
+
+// taskToolInput extracts the calling session and task ID
+func taskToolInput(ctx context.Context, input map[string]interface{}) (vo.SessionID, string, *entities.ToolResult) {
+	sessionID, ok := handlers.SessionIDFromContext(ctx)
+	if !ok {
+		return vo.SessionID{}, "", entities.NewErrorToolResult(ErrNoSessionContext)
+	}
+	taskID, ok := input["task_id"].(string)
+	if !ok || taskID == "" {
+		return vo.SessionID{}, "", entities.NewErrorToolResult(fmt.Errorf("task_id is required"))
+	}
+	return sessionID, taskID, nil
+}
+
+// taskStatusResult renders a task as a JSON status result
+func taskStatusResult(task *entities.Task) *entities.ToolResult {
+	info := task.ToMCPTask()
+	info["tool"] = task.ToolName()
+	data, _ := json.MarshalIndent(info, "", "  ")
+
+	result := entities.NewTextToolResult(string(data))
+	result.SetMeta("task", task.ToMCPTask())
+	return result
+}
@@ -0,0 +1,241 @@
+// Package tools provides the LLM usage tool.
+//
+// TelemetryFlow GO MCP Server - Community Enterprise Observability Platform
+// Copyright (c) 2024-2026 Telemetri Data Indonesia. All rights reserved.
+// Open Source Software built by Telemetri Data Indonesia.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/queries"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// SetUsageHandler registers the LLM usage tool backed by handler
+func (r *ToolRegistry) SetUsageHandler(handler *handlers.UsageHandler) {
+	r.usageHandler = handler
+	r.registerGetLLMUsage()
+}
+
+func (r *ToolRegistry) registerGetLLMUsage() {
+	name, _ := vo.NewToolName("get_llm_usage")
+	desc, _ := vo.NewToolDescription("Report the token usage and USD cost of LLM calls, filtered by session, conversation, organization, model, provider and time range, and optionally grouped by one of them")
+
+	groups := make([]interface{}, 0, len(handlers.LLMUsageGroups))
+	for _, group := range handlers.LLMUsageGroups {
+		groups = append(groups, string(group))
+	}
+
+	schema := &entities.JSONSchema{
+		Type: "object",
+		Properties: map[string]*entities.JSONSchema{
+			"session_id": {
+				Type:        "string",
+				Description: "Only calls made for this session",
+			},
+			"conversation_id": {
+				Type:        "string",
+				Description: "Only calls made for this conversation",
+			},
+			"organization_id": {
+				Type:        "string",
+				Description: "Only calls made for this organization",
+			},
+			"model": {
+				Type:        "string",
+				Description: "Only calls served by this model",
+			},
+			"provider": {
+				Type:        "string",
+				Description: "Only calls served by this provider",
+			},
+			"since": {
+				Type:        "string",
+				Description: "Start of the time range, as RFC 3339 or a duration ago such as 24h",
+			},
+			"until": {
+				Type:        "string",
+				Description: "End of the time range, as RFC 3339 or a duration ago (default: now)",
+			},
+			"group_by": {
+				Type:        "string",
+				Description: "Split the usage by this dimension, most expensive first; omitted gives the total only",
+				Enum:        groups,
+			},
+		},
+	}
+
+	tool, _ := entities.NewTool(name, desc, schema)
+	tool.SetCategory("analytics")
+	tool.SetTags([]string{"llm", "usage", "cost", "analytics"})
+	tool.SetContextHandler(r.handleGetLLMUsage)
+
+	r.tools["get_llm_usage"] = tool
+}
+
+func (r *ToolRegistry) handleGetLLMUsage(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+	query := &queries.GetLLMUsageQuery{}
+	query.SessionID, _ = input["session_id"].(string)
+	query.ConversationID, _ = input["conversation_id"].(string)
+	query.OrganizationID, _ = input["organization_id"].(string)
+	query.Model, _ = input["model"].(string)
+	query.Provider, _ = input["provider"].(string)
+	query.GroupBy, _ = input["group_by"].(string)
+
+	now := time.Now()
+	var err error
+	if query.Since, err = parseAuditTime(input["since"], now); err != nil {
+		return entities.NewErrorToolResult(fmt.Errorf("since: %w", err)), nil
+	}
+	if query.Until, err = parseAuditTime(input["until"], now); err != nil {
+		return entities.NewErrorToolResult(fmt.Errorf("until: %w", err)), nil
+	}
+
+	report, err := r.usageHandler.HandleGetLLMUsage(ctx, query)
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+
+	output := map[string]interface{}{
+		"total": usageSummaryRecord(&report.Total, "", ""),
+	}
+	if query.GroupBy != "" {
+		records := make([]map[string]interface{}, 0, len(report.Groups))
+		for _, s := range report.Groups {
+			records = append(records, usageSummaryRecord(s, query.GroupBy, s.Group))
+		}
+		output["groupBy"] = query.GroupBy
+		output["groups"] = records
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+	return entities.NewTextToolResult(string(data)), nil
+}
+
+// usageSummaryRecord renders a usage summary, naming its group under key
+func usageSummaryRecord(s *repositories.LLMUsageSummary, key, group string) map[string]interface{} {
+	record := map[string]interface{}{
+		"requests":         s.Requests,
+		"errors":           s.Errors,
+		"inputTokens":      s.InputTokens,
+		"outputTokens":     s.OutputTokens,
+		"cacheReadTokens":  s.CacheReadTokens,
+		"cacheWriteTokens": s.CacheWriteTokens,
+		"costUsd":          s.CostUSD,
+		"avgLatencyMs":     s.AvgLatency.Milliseconds(),
+	}
+	if key != "" {
+		record[key] = group
+	}
+	if s.UnpricedRequests > 0 {
+		record["unpricedRequests"] = s.UnpricedRequests
+	}
+	if s.ReasoningTokens > 0 {
+		record["reasoningTokens"] = s.ReasoningTokens
+	}
+	// Share of input tokens read from the prompt cache
+	if input := s.InputTokens + s.CacheReadTokens + s.CacheWriteTokens; input > 0 {
+		record["cacheHitRate"] = float64(s.CacheReadTokens) / float64(input)
+	}
+	return record
+}
+
+// SetBudgetHandler registers the LLM budget tool backed by handler
+func (r *ToolRegistry) SetBudgetHandler(handler *handlers.BudgetHandler) {
+	r.budgetHandler = handler
+	r.registerGetLLMBudget()
+}
+
+func (r *ToolRegistry) registerGetLLMBudget() {
+	name, _ := vo.NewToolName("get_llm_budget")
+	desc, _ := vo.NewToolDescription("Report the daily and monthly LLM token and USD budgets of an organization, what it has spent of them in the current period and when they reset")
+
+	schema := &entities.JSONSchema{
+		Type: "object",
+		Properties: map[string]*entities.JSONSchema{
+			"organization_id": {
+				Type:        "string",
+				Description: "Organization to report (default: the organization of the session, or all organizations)",
+			},
+		},
+	}
+
+	tool, _ := entities.NewTool(name, desc, schema)
+	tool.SetCategory("analytics")
+	tool.SetTags([]string{"llm", "budget", "cost", "analytics"})
+	tool.SetContextHandler(r.handleGetLLMBudget)
+
+	r.tools["get_llm_budget"] = tool
+}
+
+func (r *ToolRegistry) handleGetLLMBudget(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+	query := &queries.GetLLMBudgetsQuery{}
+	query.OrganizationID, _ = input["organization_id"].(string)
+	if query.OrganizationID == "" {
+		query.OrganizationID = entities.UsageScopeFromContext(ctx).OrganizationID
+	}
+
+	statuses, err := r.budgetHandler.HandleGetLLMBudgets(ctx, query)
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+
+	records := make([]map[string]interface{}, 0, len(statuses))
+	for _, status := range statuses {
+		records = append(records, budgetStatusRecord(status))
+	}
+	data, err := json.MarshalIndent(map[string]interface{}{"budgets": records}, "", "  ")
+	if err != nil {
+		return entities.NewErrorToolResult(err), nil
+	}
+	return entities.NewTextToolResult(string(data)), nil
+}
+
+// budgetStatusRecord renders a budget and its usage; limits a budget does not set are omitted
+func budgetStatusRecord(status *handlers.LLMBudgetStatus) map[string]interface{} {
+	budget := status.Budget
+	record := map[string]interface{}{
+		"organizationId": budget.OrganizationID,
+		"period":         string(budget.Period),
+		"enabled":        budget.Enabled,
+		"hardLimit":      budget.HardLimit,
+		"tokens":         status.Usage.Tokens,
+		"costUsd":        status.Usage.CostUSD,
+		"used":           status.Used,
+		"windowStart":    status.WindowStart.Format(time.RFC3339),
+		"resetsAt":       status.ResetsAt.Format(time.RFC3339),
+	}
+	if budget.TokenLimit > 0 {
+		record["tokenLimit"] = budget.TokenLimit
+	}
+	if budget.CostLimitUSD > 0 {
+		record["costLimitUsd"] = budget.CostLimitUSD
+	}
+	if budget.WarnAt > 0 {
+		record["warnAt"] = budget.WarnAt
+	}
+	return record
+}
@@ -0,0 +1,17 @@
+-- ============================================================================
+-- TelemetryFlow GO MCP - ClickHouse LLM Usage Migration (Rollback)
+-- Version: 000002
+-- Description: Drops the LLM usage and cost accounting columns
+-- ============================================================================
+
+ALTER TABLE telemetryflow_mcp.api_request_analytics DROP COLUMN IF EXISTS pricing_version;
+ALTER TABLE telemetryflow_mcp.api_request_analytics DROP COLUMN IF EXISTS cost_usd;
+ALTER TABLE telemetryflow_mcp.api_request_analytics DROP COLUMN IF EXISTS error_message;
+ALTER TABLE telemetryflow_mcp.api_request_analytics DROP COLUMN IF EXISTS status;
+ALTER TABLE telemetryflow_mcp.api_request_analytics DROP COLUMN IF EXISTS cache_write_tokens;
+ALTER TABLE telemetryflow_mcp.api_request_analytics DROP COLUMN IF EXISTS cache_read_tokens;
+ALTER TABLE telemetryflow_mcp.api_request_analytics DROP COLUMN IF EXISTS requested_model;
+ALTER TABLE telemetryflow_mcp.api_request_analytics DROP COLUMN IF EXISTS provider;
+ALTER TABLE telemetryflow_mcp.api_request_analytics DROP COLUMN IF EXISTS organization_id;
+
+DELETE FROM telemetryflow_mcp.schema_migrations WHERE version = '000002_llm_usage_cost';
@@ -0,0 +1,18 @@
+-- ============================================================================
+-- TelemetryFlow GO MCP - ClickHouse LLM Usage Migration
+-- Version: 000002
+-- Description: Adds organization, provider, cache token, status and cost columns
+--              for LLM usage and cost accounting
+-- ============================================================================
+
+ALTER TABLE telemetryflow_mcp.api_request_analytics ADD COLUMN IF NOT EXISTS organization_id String DEFAULT '' AFTER conversation_id;
+ALTER TABLE telemetryflow_mcp.api_request_analytics ADD COLUMN IF NOT EXISTS provider LowCardinality(String) DEFAULT '' AFTER organization_id;
+ALTER TABLE telemetryflow_mcp.api_request_analytics ADD COLUMN IF NOT EXISTS requested_model LowCardinality(String) DEFAULT '' AFTER provider;
+ALTER TABLE telemetryflow_mcp.api_request_analytics ADD COLUMN IF NOT EXISTS cache_read_tokens UInt32 DEFAULT 0 AFTER output_tokens;
+ALTER TABLE telemetryflow_mcp.api_request_analytics ADD COLUMN IF NOT EXISTS cache_write_tokens UInt32 DEFAULT 0 AFTER cache_read_tokens;
+ALTER TABLE telemetryflow_mcp.api_request_analytics ADD COLUMN IF NOT EXISTS status LowCardinality(String) DEFAULT '' AFTER is_error;
+ALTER TABLE telemetryflow_mcp.api_request_analytics ADD COLUMN IF NOT EXISTS error_message String DEFAULT '' AFTER status;
+ALTER TABLE telemetryflow_mcp.api_request_analytics ADD COLUMN IF NOT EXISTS cost_usd Float64 DEFAULT 0 AFTER stop_reason;
+ALTER TABLE telemetryflow_mcp.api_request_analytics ADD COLUMN IF NOT EXISTS pricing_version LowCardinality(String) DEFAULT '' AFTER cost_usd;
+
+INSERT INTO telemetryflow_mcp.schema_migrations (version) VALUES ('000002_llm_usage_cost');
@@ -0,0 +1,9 @@
+-- ============================================================================
+-- TelemetryFlow GO MCP - ClickHouse LLM Reasoning Tokens Migration (Rollback)
+-- Version: 000003
+-- Description: Drops the reasoning tokens column
+-- ============================================================================
+
+ALTER TABLE telemetryflow_mcp.api_request_analytics DROP COLUMN IF EXISTS reasoning_tokens;
+
+DELETE FROM telemetryflow_mcp.schema_migrations WHERE version = '000003_llm_reasoning_tokens';
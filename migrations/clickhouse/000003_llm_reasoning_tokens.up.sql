@@ -0,0 +1,9 @@
+-- ============================================================================
+-- TelemetryFlow GO MCP - ClickHouse LLM Reasoning Tokens Migration
+-- Version: 000003
+-- Description: Adds the reasoning tokens, a part of the output tokens, of LLM calls
+-- ============================================================================
+
+ALTER TABLE telemetryflow_mcp.api_request_analytics ADD COLUMN IF NOT EXISTS reasoning_tokens UInt32 DEFAULT 0 AFTER output_tokens;
+
+INSERT INTO telemetryflow_mcp.schema_migrations (version) VALUES ('000003_llm_reasoning_tokens');
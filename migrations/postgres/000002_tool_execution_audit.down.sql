@@ -0,0 +1,13 @@
+-- ============================================================================
+-- TelemetryFlow GO MCP - PostgreSQL Tool Execution Audit Migration (Rollback)
+-- Version: 000002
+-- Description: Removes the tool call audit trail columns
+-- ============================================================================
+
+DROP INDEX IF EXISTS idx_tool_executions_status;
+DROP INDEX IF EXISTS idx_tool_executions_api_key_id;
+
+ALTER TABLE tool_executions DROP COLUMN IF EXISTS status;
+ALTER TABLE tool_executions DROP COLUMN IF EXISTS api_key_id;
+ALTER TABLE tool_executions DROP COLUMN IF EXISTS client_version;
+ALTER TABLE tool_executions DROP COLUMN IF EXISTS client_name;
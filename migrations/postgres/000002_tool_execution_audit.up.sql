@@ -0,0 +1,13 @@
+-- ============================================================================
+-- TelemetryFlow GO MCP - PostgreSQL Tool Execution Audit Migration
+-- Version: 000002
+-- Description: Adds caller and status columns for the tool call audit trail
+-- ============================================================================
+
+ALTER TABLE tool_executions ADD COLUMN IF NOT EXISTS client_name VARCHAR(255);
+ALTER TABLE tool_executions ADD COLUMN IF NOT EXISTS client_version VARCHAR(50);
+ALTER TABLE tool_executions ADD COLUMN IF NOT EXISTS api_key_id VARCHAR(255);
+ALTER TABLE tool_executions ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'success';
+
+CREATE INDEX IF NOT EXISTS idx_tool_executions_api_key_id ON tool_executions(api_key_id);
+CREATE INDEX IF NOT EXISTS idx_tool_executions_status ON tool_executions(status);
@@ -0,0 +1,8 @@
+-- ============================================================================
+-- TelemetryFlow GO MCP - PostgreSQL LLM Budgets Migration (Rollback)
+-- Version: 000003
+-- Description: Removes the per-organization LLM budgets
+-- ============================================================================
+
+DROP TRIGGER IF EXISTS update_llm_budgets_updated_at ON llm_budgets;
+DROP TABLE IF EXISTS llm_budgets;
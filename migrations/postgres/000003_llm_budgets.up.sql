@@ -0,0 +1,28 @@
+-- ============================================================================
+-- TelemetryFlow GO MCP - PostgreSQL LLM Budgets Migration
+-- Version: 000003
+-- Description: Adds per-organization LLM token and cost budgets
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS llm_budgets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id VARCHAR(255) NOT NULL,
+    period VARCHAR(20) NOT NULL,
+    token_limit BIGINT NOT NULL DEFAULT 0,
+    cost_limit_usd NUMERIC(14,4) NOT NULL DEFAULT 0,
+    warn_at DOUBLE PRECISION NOT NULL DEFAULT 0,
+    hard_limit BOOLEAN NOT NULL DEFAULT true,
+    webhook_url TEXT,
+    notify_emails JSONB NOT NULL DEFAULT '[]',
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_llm_budgets_period CHECK (period IN ('daily', 'monthly'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_llm_budgets_org_period ON llm_budgets(organization_id, period);
+CREATE INDEX IF NOT EXISTS idx_llm_budgets_enabled ON llm_budgets(enabled);
+
+CREATE TRIGGER update_llm_budgets_updated_at
+    BEFORE UPDATE ON llm_budgets
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
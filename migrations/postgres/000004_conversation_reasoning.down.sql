@@ -0,0 +1,7 @@
+-- ============================================================================
+-- TelemetryFlow GO MCP - PostgreSQL Conversation Reasoning Migration (Rollback)
+-- Version: 000004
+-- Description: Removes the reasoning setting of conversations
+-- ============================================================================
+
+ALTER TABLE conversations DROP COLUMN IF EXISTS reasoning;
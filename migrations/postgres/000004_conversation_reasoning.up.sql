@@ -0,0 +1,7 @@
+-- ============================================================================
+-- TelemetryFlow GO MCP - PostgreSQL Conversation Reasoning Migration
+-- Version: 000004
+-- Description: Adds the reasoning setting of conversations
+-- ============================================================================
+
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS reasoning JSONB;
@@ -22,6 +22,8 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
 )
 
 // Model constants
@@ -43,8 +45,9 @@ const (
 
 // ContentType constants
 const (
-	ContentTypeText  = "text"
-	ContentTypeImage = "image"
+	ContentTypeText     = "text"
+	ContentTypeImage    = "image"
+	ContentTypeDocument = "document"
 )
 
 // StopReason constants
@@ -107,6 +110,7 @@ type ContentBlock struct {
 	Type      string       `json:"type"`
 	Text      string       `json:"text,omitempty"`
 	Source    *ImageSource `json:"source,omitempty"`
+	Title     string       `json:"title,omitempty"`
 	ID        string       `json:"id,omitempty"`
 	Name      string       `json:"name,omitempty"`
 	Input     interface{}  `json:"input,omitempty"`
@@ -114,7 +118,7 @@ type ContentBlock struct {
 	Content   string       `json:"content,omitempty"`
 }
 
-// ImageSource represents an image source
+// ImageSource represents the base64 source of an image or PDF document
 type ImageSource struct {
 	Type      string `json:"type"`
 	MediaType string `json:"media_type"`
@@ -214,6 +218,23 @@ func NewAssistantMessage(text string) Message {
 	return NewTextMessage(RoleAssistant, text)
 }
 
+// NewImageBlock creates an image block from base64 data
+func NewImageBlock(mediaType, data string) ContentBlock {
+	return ContentBlock{
+		Type:   ContentTypeImage,
+		Source: &ImageSource{Type: "base64", MediaType: mediaType, Data: data},
+	}
+}
+
+// NewDocumentBlock creates a PDF document block from base64 data
+func NewDocumentBlock(data, title string) ContentBlock {
+	return ContentBlock{
+		Type:   ContentTypeDocument,
+		Source: &ImageSource{Type: "base64", MediaType: "application/pdf", Data: data},
+		Title:  title,
+	}
+}
+
 // NewToolResultMessage creates a tool result message
 func NewToolResultMessage(toolUseID, content string) Message {
 	return Message{
@@ -270,7 +291,8 @@ func ValidateModel(model string) error {
 	}
 }
 
-// GetModelInfo returns information about a model
+// GetModelInfo returns the output and context limits of a model, from the model catalog for
+// models other than the constants above
 func GetModelInfo(model string) (maxTokens int, contextWindow int) {
 	switch model {
 	case ModelOpus4:
@@ -281,7 +303,9 @@ func GetModelInfo(model string) (maxTokens int, contextWindow int) {
 		return 8192, 200000
 	case ModelHaiku35:
 		return 8192, 200000
-	default:
-		return DefaultMaxTokens, 100000
 	}
+	if capabilities, ok := vo.Model(model).CatalogCapabilities(); ok {
+		return capabilities.MaxOutputTokens, capabilities.ContextWindow
+	}
+	return DefaultMaxTokens, 100000
 }
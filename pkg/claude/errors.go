@@ -21,6 +21,7 @@ package claude
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Error types
@@ -42,6 +43,9 @@ type APIError struct {
 	Type       string `json:"type"`
 	Message    string `json:"message"`
 	StatusCode int    `json:"-"`
+
+	// RetryAfter is the delay the API asked for in its retry-after headers
+	RetryAfter time.Duration `json:"-"`
 }
 
 // Error implements the error interface
@@ -71,8 +75,12 @@ func (e *APIError) Is(target error) bool {
 	}
 }
 
-// Retryable returns whether the error is retryable
+// Retryable returns whether the error is retryable: 408, 429, 5xx and 529 responses are, other 4xx
+// responses are not. Without a status code the error type decides
 func (e *APIError) Retryable() bool {
+	if e.StatusCode > 0 {
+		return e.StatusCode == 408 || e.StatusCode == 429 || e.StatusCode >= 500
+	}
 	switch e.Type {
 	case "rate_limit_error", "overloaded_error", "api_error":
 		return true
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 )
 
@@ -46,6 +47,7 @@ type Metrics struct {
 	ClaudeTokensOutput  metric.Int64Counter
 	ClaudeLatency       metric.Float64Histogram
 	ClaudeErrors        metric.Int64Counter
+	ClaudeFallbacks     metric.Int64Counter
 
 	// Session metrics
 	ActiveSessions  metric.Int64UpDownCounter
@@ -55,6 +57,10 @@ type Metrics struct {
 	ResourceReadsTotal  metric.Int64Counter
 	ResourceCacheHits   metric.Int64Counter
 	ResourceCacheMisses metric.Int64Counter
+
+	// Tool result cache metrics
+	ToolCacheHits   metric.Int64Counter
+	ToolCacheMisses metric.Int64Counter
 }
 
 // NewMetrics creates a new Metrics instance
@@ -166,6 +172,15 @@ func NewMetrics(serviceName string) (*Metrics, error) {
 		return nil, err
 	}
 
+	m.ClaudeFallbacks, err = meter.Int64Counter(
+		"claude.fallbacks.total",
+		metric.WithDescription("Total number of calls passed to the next model of a fallback chain"),
+		metric.WithUnit("{fallbacks}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	// Session metrics
 	m.ActiveSessions, err = meter.Int64UpDownCounter(
 		"mcp.sessions.active",
@@ -213,6 +228,25 @@ func NewMetrics(serviceName string) (*Metrics, error) {
 		return nil, err
 	}
 
+	// Tool result cache metrics
+	m.ToolCacheHits, err = meter.Int64Counter(
+		"mcp.tool.cache.hits",
+		metric.WithDescription("Number of tool result cache hits"),
+		metric.WithUnit("{hits}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.ToolCacheMisses, err = meter.Int64Counter(
+		"mcp.tool.cache.misses",
+		metric.WithDescription("Number of tool result cache misses"),
+		metric.WithUnit("{misses}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	return m, nil
 }
 
@@ -235,7 +269,7 @@ func (m *Metrics) RecordToolCall(ctx context.Context, toolName string, duration
 
 // RecordClaudeRequest records a Claude API request metric
 func (m *Metrics) RecordClaudeRequest(ctx context.Context, model string, inputTokens, outputTokens int, duration time.Duration, err error) {
-	attrs := metric.WithAttributes()
+	attrs := metric.WithAttributes(attribute.String("model", model))
 	m.ClaudeRequestsTotal.Add(ctx, 1, attrs)
 	m.ClaudeTokensInput.Add(ctx, int64(inputTokens), attrs)
 	m.ClaudeTokensOutput.Add(ctx, int64(outputTokens), attrs)
@@ -245,6 +279,15 @@ func (m *Metrics) RecordClaudeRequest(ctx context.Context, model string, inputTo
 	}
 }
 
+// RecordClaudeFallback records a call that moved on from a model of a fallback chain
+func (m *Metrics) RecordClaudeFallback(ctx context.Context, alias, fromModel, reason string) {
+	m.ClaudeFallbacks.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("alias", alias),
+		attribute.String("model", fromModel),
+		attribute.String("reason", reason),
+	))
+}
+
 // IncrementActiveSessions increments active sessions counter
 func (m *Metrics) IncrementActiveSessions(ctx context.Context) {
 	m.ActiveSessions.Add(ctx, 1)
@@ -271,6 +314,19 @@ func (m *Metrics) RecordResourceRead(ctx context.Context, uri string, cacheHit b
 	}
 }
 
+// RecordToolCacheLookup records a tool result cache lookup
+func (m *Metrics) RecordToolCacheLookup(ctx context.Context, toolName string, cacheHit bool) {
+	attrs := metric.WithAttributes(
+		attribute.String("tool", toolName),
+		attribute.Bool("hit", cacheHit),
+	)
+	if cacheHit {
+		m.ToolCacheHits.Add(ctx, 1, attrs)
+	} else {
+		m.ToolCacheMisses.Add(ctx, 1, attrs)
+	}
+}
+
 // IncrementRequestsInFlight increments in-flight requests
 func (m *Metrics) IncrementRequestsInFlight(ctx context.Context) {
 	m.RequestsInFlight.Add(ctx, 1)
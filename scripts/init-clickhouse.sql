@@ -34,15 +34,24 @@ CREATE TABLE IF NOT EXISTS telemetryflow_analytics.api_request_analytics (
     request_id UUID,
     session_id UUID,
     conversation_id UUID,
+    organization_id String DEFAULT '',
+    provider LowCardinality(String) DEFAULT '',
+    requested_model LowCardinality(String) DEFAULT '',
     model LowCardinality(String),
     input_tokens UInt32,
     output_tokens UInt32,
+    cache_read_tokens UInt32 DEFAULT 0,
+    cache_write_tokens UInt32 DEFAULT 0,
     total_tokens UInt32,
     duration_ms UInt64,
     status_code UInt16,
     is_error UInt8,
+    status LowCardinality(String) DEFAULT '',
+    error_message String DEFAULT '',
     is_streaming UInt8 DEFAULT 0,
     stop_reason LowCardinality(String) DEFAULT '',
+    cost_usd Float64 DEFAULT 0,
+    pricing_version LowCardinality(String) DEFAULT '',
     metadata String DEFAULT '{}'
 ) ENGINE = MergeTree()
 PARTITION BY toYYYYMM(timestamp)
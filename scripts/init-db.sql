@@ -122,9 +122,13 @@ CREATE TABLE IF NOT EXISTS tool_executions (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     session_id UUID REFERENCES sessions(id) ON DELETE SET NULL,
     conversation_id UUID REFERENCES conversations(id) ON DELETE SET NULL,
+    client_name VARCHAR(255),
+    client_version VARCHAR(50),
+    api_key_id VARCHAR(255),
     tool_name VARCHAR(255) NOT NULL,
     input JSONB NOT NULL DEFAULT '{}',
     output JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'success',
     is_error BOOLEAN NOT NULL DEFAULT false,
     error_message TEXT,
     duration_ms INTEGER,
@@ -188,6 +192,8 @@ CREATE INDEX IF NOT EXISTS idx_resource_subscriptions_deleted_at ON resource_sub
 CREATE INDEX IF NOT EXISTS idx_tool_executions_session_id ON tool_executions(session_id);
 CREATE INDEX IF NOT EXISTS idx_tool_executions_tool_name ON tool_executions(tool_name);
 CREATE INDEX IF NOT EXISTS idx_tool_executions_executed_at ON tool_executions(executed_at);
+CREATE INDEX IF NOT EXISTS idx_tool_executions_api_key_id ON tool_executions(api_key_id);
+CREATE INDEX IF NOT EXISTS idx_tool_executions_status ON tool_executions(status);
 CREATE INDEX IF NOT EXISTS idx_tool_executions_deleted_at ON tool_executions(deleted_at);
 
 CREATE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys(key_hash);
@@ -0,0 +1,204 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/commands"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/queries"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+)
+
+// recordingAuditor keeps the records it receives
+type recordingAuditor struct {
+	mu      sync.Mutex
+	records []*entities.ToolExecution
+}
+
+func (a *recordingAuditor) Record(_ context.Context, execution *entities.ToolExecution) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.records = append(a.records, execution)
+}
+
+func (a *recordingAuditor) last(t *testing.T) *entities.ToolExecution {
+	t.Helper()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	require.NotEmpty(t, a.records)
+	return a.records[len(a.records)-1]
+}
+
+func TestHandleExecuteTool_Audit(t *testing.T) {
+	session := createInitializedSession()
+	session.SetMetadata(handlers.SessionMetadataAPIKeyID, "key-1")
+
+	ok := createTestTool(t, "ok_tool")
+	ok.SetHandler(func(input map[string]interface{}) (*entities.ToolResult, error) {
+		return entities.NewTextToolResult("done"), nil
+	})
+	failing := createTestTool(t, "failing_tool")
+	failing.SetHandler(func(input map[string]interface{}) (*entities.ToolResult, error) {
+		return entities.NewErrorToolResult(errors.New("backend unavailable")), nil
+	})
+	disabled := createTestTool(t, "disabled_tool")
+	disabled.Disable()
+
+	sr := new(mockSessionRepo)
+	tr := new(mockToolRepo)
+	pub := new(mockEventPublisher)
+	sr.On("FindByID", mock.Anything, session.ID()).Return(session, nil)
+	for _, tool := range []*entities.Tool{ok, failing, disabled} {
+		tr.On("FindByName", mock.Anything, tool.Name()).Return(tool, nil)
+	}
+	missing, _ := vo.NewToolName("missing_tool")
+	tr.On("FindByName", mock.Anything, missing).Return(nil, nil)
+	pub.On("Publish", mock.Anything, mock.Anything).Return(nil)
+
+	auditor := &recordingAuditor{}
+	h := handlers.NewToolHandler(sr, tr, pub)
+	h.SetAuditor(auditor, handlers.AuditOptions{MaxValueLength: 8})
+
+	execute := func(name string, args map[string]interface{}) {
+		_, _ = h.HandleExecuteTool(context.Background(), &commands.ExecuteToolCommand{
+			SessionID: session.ID(), Name: name, Arguments: args,
+		})
+	}
+
+	execute("ok_tool", map[string]interface{}{"query": "SELECT * FROM logs", "api_key": "sk-live-123"})
+	record := auditor.last(t)
+	assert.Equal(t, session.ID().String(), record.SessionID)
+	assert.Equal(t, "test", record.ClientName)
+	assert.Equal(t, "1.0", record.ClientVersion)
+	assert.Equal(t, "key-1", record.APIKeyID)
+	assert.Equal(t, "ok_tool", record.ToolName)
+	assert.Equal(t, entities.ToolExecutionSuccess, record.Status)
+	assert.Equal(t, "[REDACTED]", record.Arguments["api_key"])
+	assert.Equal(t, "SELECT *...(truncated)", record.Arguments["query"])
+
+	execute("failing_tool", nil)
+	assert.Equal(t, entities.ToolExecutionError, auditor.last(t).Status)
+	assert.Equal(t, "backend unavailable", auditor.last(t).ErrorMessage)
+
+	execute("disabled_tool", nil)
+	assert.Equal(t, entities.ToolExecutionRejected, auditor.last(t).Status)
+
+	execute("missing_tool", nil)
+	assert.Equal(t, entities.ToolExecutionRejected, auditor.last(t).Status)
+	assert.Len(t, auditor.records, 4)
+}
+
+func TestHandleExecuteTool_AuditAPIKey(t *testing.T) {
+	ctx := context.Background()
+	sessionRepo := persistence.NewInMemorySessionRepository()
+	toolRepo := persistence.NewInMemoryToolRepository()
+	tool := createTestTool(t, "ok_tool")
+	tool.SetHandler(func(input map[string]interface{}) (*entities.ToolResult, error) {
+		return entities.NewTextToolResult("done"), nil
+	})
+	require.NoError(t, toolRepo.Register(ctx, tool))
+	pub := new(mockEventPublisher)
+	pub.On("Publish", mock.Anything, mock.Anything).Return(nil)
+
+	sessions := handlers.NewSessionHandler(sessionRepo, pub)
+	sessions.SetAPIKeys([]string{"tfk-analyst", "tfk-oncall"}, true)
+	auditor := &recordingAuditor{}
+	tools := handlers.NewToolHandler(sessionRepo, toolRepo, pub)
+	tools.SetAuditor(auditor, handlers.AuditOptions{})
+
+	session, err := sessions.HandleInitializeSession(ctx, &commands.InitializeSessionCommand{
+		ClientName: "test", ProtocolVersion: "2024-11-05", APIKey: "tfk-oncall",
+	})
+	require.NoError(t, err)
+	_, err = tools.HandleExecuteTool(ctx, &commands.ExecuteToolCommand{SessionID: session.ID(), Name: "ok_tool"})
+	require.NoError(t, err)
+
+	// The record names the key by its hash, never by the key itself
+	record := auditor.last(t)
+	assert.Equal(t, handlers.APIKeyID("tfk-oncall"), record.APIKeyID)
+	assert.Len(t, record.APIKeyID, 64)
+	assert.NotContains(t, record.APIKeyID, "tfk-oncall")
+
+	for _, key := range []string{"", "tfk-unknown"} {
+		_, err = sessions.HandleInitializeSession(ctx, &commands.InitializeSessionCommand{ClientName: "test", APIKey: key})
+		assert.ErrorIs(t, err, handlers.ErrInvalidAPIKey)
+	}
+
+	// Without require_api_key, sessions without a known key start and record no key ID
+	sessions.SetAPIKeys([]string{"tfk-analyst"}, false)
+	session, err = sessions.HandleInitializeSession(ctx, &commands.InitializeSessionCommand{
+		ClientName: "test", ProtocolVersion: "2024-11-05", APIKey: "tfk-unknown",
+	})
+	require.NoError(t, err)
+	_, err = tools.HandleExecuteTool(ctx, &commands.ExecuteToolCommand{SessionID: session.ID(), Name: "ok_tool"})
+	require.NoError(t, err)
+	assert.Empty(t, auditor.last(t).APIKeyID)
+}
+
+func TestRedactArguments(t *testing.T) {
+	args := map[string]interface{}{
+		"db_password": "hunter2",
+		"X-Api-Key":   "abc",
+		"accessToken": "t",
+		"max_tokens":  float64(1024),
+		"headers": map[string]interface{}{
+			"Authorization": "Bearer x",
+			"Accept":        "application/json",
+		},
+		"items": []interface{}{map[string]interface{}{"client_secret": "s", "name": "n"}},
+	}
+
+	redacted := handlers.RedactArguments(args, handlers.DefaultRedactedArguments, 0)
+	assert.Equal(t, "[REDACTED]", redacted["db_password"])
+	assert.Equal(t, "[REDACTED]", redacted["X-Api-Key"])
+	assert.Equal(t, "[REDACTED]", redacted["accessToken"])
+	assert.Equal(t, float64(1024), redacted["max_tokens"], "whole-word matching leaves max_tokens alone")
+	headers := redacted["headers"].(map[string]interface{})
+	assert.Equal(t, "[REDACTED]", headers["Authorization"])
+	assert.Equal(t, "application/json", headers["Accept"])
+	item := redacted["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "[REDACTED]", item["client_secret"])
+	assert.Equal(t, "n", item["name"])
+
+	assert.Equal(t, "hunter2", args["db_password"], "input is not modified")
+	assert.Nil(t, handlers.RedactArguments(nil, handlers.DefaultRedactedArguments, 0))
+}
+
+func TestHandleSearchToolExecutions(t *testing.T) {
+	ctx := context.Background()
+	repo := persistence.NewInMemoryToolExecutionRepository(100)
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, status := range []entities.ToolExecutionStatus{
+		entities.ToolExecutionSuccess, entities.ToolExecutionError, entities.ToolExecutionSuccess,
+	} {
+		e := entities.NewToolExecution("s1", "query", nil)
+		e.Status = status
+		e.ExecutedAt = base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, repo.Save(ctx, e))
+	}
+	h := handlers.NewAuditHandler(repo)
+
+	results, err := h.HandleSearchToolExecutions(ctx, &queries.SearchToolExecutionsQuery{Status: "success"})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].ExecutedAt.After(results[1].ExecutedAt), "newest first")
+
+	results, err = h.HandleSearchToolExecutions(ctx, &queries.SearchToolExecutionsQuery{Since: base.Add(30 * time.Second), Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, base.Add(2*time.Minute), results[0].ExecutedAt)
+
+	_, err = h.HandleSearchToolExecutions(ctx, &queries.SearchToolExecutionsQuery{Status: "exploded"})
+	assert.ErrorIs(t, err, handlers.ErrInvalidAuditStatus)
+	_, err = h.HandleSearchToolExecutions(ctx, &queries.SearchToolExecutionsQuery{Since: base, Until: base})
+	assert.ErrorIs(t, err, handlers.ErrInvalidTimeRange)
+}
@@ -3,7 +3,9 @@ package handlers_test
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -11,10 +13,12 @@ import (
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/commands"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/queries"
+	appsvc "github.com/telemetryflow/telemetryflow-go-mcp/internal/application/services"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
 	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
 )
 
 type mockConversationRepo struct {
@@ -176,7 +180,11 @@ func TestHandleSendMessage(t *testing.T) {
 		pub := new(mockEventPublisher)
 		cs := new(mockClaudeSvc)
 		cr.On("FindByID", ctx, conv.ID()).Return(conv, nil)
-		cs.On("CreateMessage", ctx, mock.AnythingOfType("*services.ClaudeRequest")).Return(&services.ClaudeResponse{
+		billed := mock.MatchedBy(func(c context.Context) bool {
+			scope := entities.UsageScopeFromContext(c)
+			return scope.ConversationID == conv.ID().String() && scope.SessionID == session.ID().String()
+		})
+		cs.On("CreateMessage", billed, mock.AnythingOfType("*services.ClaudeRequest")).Return(&services.ClaudeResponse{
 			Content: []entities.ContentBlock{
 				{Type: vo.ContentTypeText, Text: "response"},
 			},
@@ -364,19 +372,25 @@ func TestHandleSendMessage_Streaming(t *testing.T) {
 		cr.On("Save", ctx, mock.AnythingOfType("*aggregates.Conversation")).Return(nil)
 		pub.On("Publish", ctx, mock.Anything).Return(nil)
 
-		eventCh := make(chan *services.ClaudeStreamEvent, 3)
-		eventCh <- &services.ClaudeStreamEvent{Message: &services.ClaudeResponse{ID: "resp1"}}
-		eventCh <- &services.ClaudeStreamEvent{ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeText, Text: "hello"}}
+		eventCh := make(chan *services.ClaudeStreamEvent, 5)
+		eventCh <- &services.ClaudeStreamEvent{Type: "message_start", Message: &services.ClaudeResponse{ID: "resp1"}}
+		eventCh <- &services.ClaudeStreamEvent{Type: "content_block_start", ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeText}}
+		eventCh <- &services.ClaudeStreamEvent{Type: "content_block_delta", Delta: &services.ClaudeDelta{Type: "text_delta", Text: "hel"}}
+		eventCh <- &services.ClaudeStreamEvent{Type: "content_block_delta", Delta: &services.ClaudeDelta{Type: "text_delta", Text: "lo"}}
 		close(eventCh)
-		cs.On("CreateMessageStream", ctx, mock.AnythingOfType("*services.ClaudeRequest")).Return((<-chan *services.ClaudeStreamEvent)(eventCh), nil)
+		cs.On("CreateMessageStream", mock.Anything, mock.AnythingOfType("*services.ClaudeRequest")).Return((<-chan *services.ClaudeStreamEvent)(eventCh), nil)
 
+		var deltas []string
 		h := handlers.NewConversationHandler(new(mockSessionRepo), cr, cs, pub)
 		result, err := h.HandleSendMessage(ctx, &commands.SendMessageCommand{
 			ConversationID: conv.ID(), Content: "hi", Stream: true,
+			OnText: func(text string) { deltas = append(deltas, text) },
 		})
 		require.NoError(t, err)
 		assert.NotNil(t, result)
 		assert.Equal(t, "resp1", result.Response.ID)
+		assert.Equal(t, "hello", result.Response.Content[0].Text)
+		assert.Equal(t, []string{"hel", "lo"}, deltas)
 	})
 
 	t.Run("streaming error", func(t *testing.T) {
@@ -388,7 +402,7 @@ func TestHandleSendMessage_Streaming(t *testing.T) {
 		eventCh := make(chan *services.ClaudeStreamEvent, 1)
 		eventCh <- &services.ClaudeStreamEvent{Error: errors.New("stream error")}
 		close(eventCh)
-		cs.On("CreateMessageStream", ctx, mock.AnythingOfType("*services.ClaudeRequest")).Return((<-chan *services.ClaudeStreamEvent)(eventCh), nil)
+		cs.On("CreateMessageStream", mock.Anything, mock.AnythingOfType("*services.ClaudeRequest")).Return((<-chan *services.ClaudeStreamEvent)(eventCh), nil)
 
 		h := handlers.NewConversationHandler(new(mockSessionRepo), cr, cs, new(mockEventPublisher))
 		_, err := h.HandleSendMessage(ctx, &commands.SendMessageCommand{
@@ -402,7 +416,7 @@ func TestHandleSendMessage_Streaming(t *testing.T) {
 		cr := new(mockConversationRepo)
 		cs := new(mockClaudeSvc)
 		cr.On("FindByID", ctx, conv3.ID()).Return(conv3, nil)
-		cs.On("CreateMessageStream", ctx, mock.AnythingOfType("*services.ClaudeRequest")).Return((<-chan *services.ClaudeStreamEvent)(nil), errors.New("conn refused"))
+		cs.On("CreateMessageStream", mock.Anything, mock.AnythingOfType("*services.ClaudeRequest")).Return((<-chan *services.ClaudeStreamEvent)(nil), errors.New("conn refused"))
 
 		h := handlers.NewConversationHandler(new(mockSessionRepo), cr, cs, new(mockEventPublisher))
 		_, err := h.HandleSendMessage(ctx, &commands.SendMessageCommand{
@@ -532,7 +546,7 @@ func TestHandleSendMessage_AdditionalPaths(t *testing.T) {
 		cr := new(mockConversationRepo)
 		cs := new(mockClaudeSvc)
 		cr.On("FindByID", ctx, conv.ID()).Return(conv, nil)
-		cs.On("CreateMessage", ctx, mock.AnythingOfType("*services.ClaudeRequest")).Return(nil, errors.New("api"))
+		cs.On("CreateMessage", mock.Anything, mock.AnythingOfType("*services.ClaudeRequest")).Return(nil, errors.New("api"))
 		h := handlers.NewConversationHandler(new(mockSessionRepo), cr, cs, new(mockEventPublisher))
 		_, err := h.HandleSendMessage(ctx, &commands.SendMessageCommand{
 			ConversationID: conv.ID(), Content: "hi",
@@ -557,7 +571,7 @@ func TestHandleSendMessage_AdditionalPaths(t *testing.T) {
 		cr := new(mockConversationRepo)
 		cs := new(mockClaudeSvc)
 		cr.On("FindByID", ctx, conv2.ID()).Return(conv2, nil)
-		cs.On("CreateMessage", ctx, mock.AnythingOfType("*services.ClaudeRequest")).Return(&services.ClaudeResponse{
+		cs.On("CreateMessage", mock.Anything, mock.AnythingOfType("*services.ClaudeRequest")).Return(&services.ClaudeResponse{
 			Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: "ok"}},
 		}, nil)
 		cr.On("Save", ctx, mock.AnythingOfType("*aggregates.Conversation")).Return(errors.New("db"))
@@ -574,7 +588,7 @@ func TestHandleSendMessage_AdditionalPaths(t *testing.T) {
 		cs := new(mockClaudeSvc)
 		pub := new(mockEventPublisher)
 		cr.On("FindByID", ctx, conv3.ID()).Return(conv3, nil)
-		cs.On("CreateMessage", ctx, mock.AnythingOfType("*services.ClaudeRequest")).Return(&services.ClaudeResponse{
+		cs.On("CreateMessage", mock.Anything, mock.AnythingOfType("*services.ClaudeRequest")).Return(&services.ClaudeResponse{
 			Content: []entities.ContentBlock{
 				{Type: vo.ContentTypeText, Text: "result"},
 				{Type: vo.ContentTypeToolUse, Text: "tool call"},
@@ -592,6 +606,227 @@ func TestHandleSendMessage_AdditionalPaths(t *testing.T) {
 	})
 }
 
+// overlapRecorder answers after a pause, recording the history it was sent and how many
+// calls overlapped
+type overlapRecorder struct {
+	mockClaudeSvc
+	mu         sync.Mutex
+	running    int
+	maxRunning int
+	histories  []int
+}
+
+func (s *overlapRecorder) CreateMessage(ctx context.Context, req *services.ClaudeRequest) (*services.ClaudeResponse, error) {
+	s.mu.Lock()
+	s.running++
+	s.maxRunning = max(s.maxRunning, s.running)
+	s.histories = append(s.histories, len(req.Messages))
+	s.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	s.mu.Lock()
+	s.running--
+	s.mu.Unlock()
+	return &services.ClaudeResponse{Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: "ok"}}}, nil
+}
+
+func TestHandleSendMessage_ConcurrentTurns(t *testing.T) {
+	ctx := context.Background()
+	repo := persistence.NewInMemoryConversationRepository()
+	conv := aggregates.NewConversation(vo.GenerateSessionID(), vo.ModelClaudeOpus47)
+	require.NoError(t, repo.Save(ctx, conv))
+	pub := new(mockEventPublisher)
+	pub.On("Publish", mock.Anything, mock.Anything).Return(nil)
+	llm := &overlapRecorder{}
+	h := handlers.NewConversationHandler(new(mockSessionRepo), repo, llm, pub)
+
+	var wg sync.WaitGroup
+	for _, text := range []string{"first", "second", "third"} {
+		wg.Go(func() {
+			_, err := h.HandleSendMessage(ctx, &commands.SendMessageCommand{ConversationID: conv.ID(), Content: text})
+			assert.NoError(t, err)
+		})
+	}
+	wg.Wait()
+
+	// Turns ran one at a time, each seeing the whole history before it
+	assert.Equal(t, 1, llm.maxRunning)
+	assert.Equal(t, []int{1, 3, 5}, llm.histories)
+	messages := conv.Messages()
+	require.Len(t, messages, 6)
+	for i, message := range messages {
+		assert.Equal(t, i%2 == 1, message.IsAssistantMessage(), "message %d", i)
+	}
+
+	// A caller waiting for its turn gives up when cancelled
+	started, release, done := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	blocking := new(mockClaudeSvc)
+	blocking.On("CreateMessage", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		close(started)
+		<-release
+	}).Return(&services.ClaudeResponse{
+		Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: "ok"}},
+	}, nil)
+	h = handlers.NewConversationHandler(new(mockSessionRepo), repo, blocking, pub)
+	go func() {
+		defer close(done)
+		_, _ = h.HandleSendMessage(ctx, &commands.SendMessageCommand{ConversationID: conv.ID(), Content: "slow"})
+	}()
+	<-started
+	cancelled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err := h.HandleSendMessage(cancelled, &commands.SendMessageCommand{ConversationID: conv.ID(), Content: "impatient"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	close(release)
+	<-done
+	assert.Equal(t, 8, conv.MessageCount())
+}
+
+func TestHandleSendMessage_FailedTurnRollsBack(t *testing.T) {
+	ctx := context.Background()
+	repo := persistence.NewInMemoryConversationRepository()
+	conv := aggregates.NewConversation(vo.GenerateSessionID(), vo.ModelClaudeOpus47)
+	require.NoError(t, repo.Save(ctx, conv))
+	conv.ClearEvents()
+	pub := new(mockEventPublisher)
+	pub.On("Publish", mock.Anything, mock.Anything).Return(nil)
+	cs := new(mockClaudeSvc)
+	cs.On("CreateMessage", mock.Anything, mock.Anything).Return(nil, errors.New("rate limited")).Once()
+	cs.On("CreateMessage", mock.Anything, mock.Anything).Return(&services.ClaudeResponse{
+		Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: "ok"}},
+	}, nil)
+	h := handlers.NewConversationHandler(new(mockSessionRepo), repo, cs, pub)
+
+	_, err := h.HandleSendMessage(ctx, &commands.SendMessageCommand{ConversationID: conv.ID(), Content: "lost"})
+	require.Error(t, err)
+	assert.Equal(t, 0, conv.MessageCount(), "the failed turn leaves no user message behind")
+	assert.Empty(t, conv.Events())
+
+	_, err = h.HandleSendMessage(ctx, &commands.SendMessageCommand{ConversationID: conv.ID(), Content: "retry"})
+	require.NoError(t, err)
+	require.Equal(t, 2, conv.MessageCount())
+	assert.Equal(t, "retry", conv.Messages()[0].Content()[0].Text)
+}
+
+// echoAgentTools offers a single echo tool to an agent
+type echoAgentTools struct{ tool *entities.Tool }
+
+func (e *echoAgentTools) AgentTools(ctx context.Context) ([]*entities.Tool, error) {
+	return []*entities.Tool{e.tool}, nil
+}
+
+func (e *echoAgentTools) ExecuteAgentTool(ctx context.Context, name string, input map[string]interface{}) (*entities.ToolResult, error) {
+	return entities.NewTextToolResult("echoed"), nil
+}
+
+func TestHandleSendMessage_RunTools(t *testing.T) {
+	ctx := context.Background()
+	session := createInitializedSession()
+	name, _ := vo.NewToolName("echo")
+	desc, _ := vo.NewToolDescription("Echo the input")
+	tool, _ := entities.NewTool(name, desc, &entities.JSONSchema{Type: "object"})
+
+	t.Run("runs tools until the model ends its turn", func(t *testing.T) {
+		conv, _ := session.CreateConversation(vo.ModelClaudeOpus47)
+		cr := new(mockConversationRepo)
+		cs := new(mockClaudeSvc)
+		pub := new(mockEventPublisher)
+		cr.On("FindByID", ctx, conv.ID()).Return(conv, nil)
+		cs.On("CreateMessage", mock.Anything, mock.AnythingOfType("*services.ClaudeRequest")).Return(&services.ClaudeResponse{
+			Content: []entities.ContentBlock{{Type: vo.ContentTypeToolUse, ID: "t1", Name: "echo"}},
+		}, nil).Once()
+		cs.On("CreateMessage", mock.Anything, mock.AnythingOfType("*services.ClaudeRequest")).Return(&services.ClaudeResponse{
+			Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: "done"}},
+		}, nil).Once()
+		cr.On("Save", ctx, conv).Return(nil)
+		pub.On("Publish", ctx, mock.Anything).Return(nil)
+
+		h := handlers.NewConversationHandler(new(mockSessionRepo), cr, cs, pub)
+		h.SetAgent(appsvc.NewAgentService(cs, &echoAgentTools{tool: tool}, appsvc.AgentOptions{
+			MaxIterations: 3, MaxParallelTools: 1, AllowedTools: []string{appsvc.AllowAllTools},
+		}))
+
+		resp, err := h.HandleSendMessage(ctx, &commands.SendMessageCommand{
+			ConversationID: conv.ID(), Content: "echo something", RunTools: true,
+		})
+		require.NoError(t, err)
+		assert.False(t, resp.HasToolUse)
+		require.NotNil(t, resp.Agent)
+		assert.Equal(t, 2, resp.Agent.Iterations)
+		assert.Equal(t, "done", resp.Agent.Text())
+		assert.Equal(t, 4, conv.MessageCount())
+		cr.AssertCalled(t, "Save", ctx, conv)
+	})
+
+	t.Run("agent disabled", func(t *testing.T) {
+		conv, _ := session.CreateConversation(vo.ModelClaudeOpus47)
+		cr := new(mockConversationRepo)
+		cr.On("FindByID", ctx, conv.ID()).Return(conv, nil)
+		h := handlers.NewConversationHandler(new(mockSessionRepo), cr, new(mockClaudeSvc), new(mockEventPublisher))
+
+		_, err := h.HandleSendMessage(ctx, &commands.SendMessageCommand{
+			ConversationID: conv.ID(), Content: "hi", RunTools: true,
+		})
+		assert.ErrorIs(t, err, handlers.ErrAgentDisabled)
+	})
+}
+
+func TestConversationHandler_Limits(t *testing.T) {
+	ctx := context.Background()
+	sessionRepo := persistence.NewInMemorySessionRepository()
+	conversationRepo := persistence.NewInMemoryConversationRepository()
+	session := createInitializedSession()
+	require.NoError(t, sessionRepo.Save(ctx, session))
+
+	pub := new(mockEventPublisher)
+	pub.On("Publish", ctx, mock.Anything).Return(nil)
+	cs := new(mockClaudeSvc)
+	cs.On("CreateMessage", mock.Anything, mock.AnythingOfType("*services.ClaudeRequest")).Return(&services.ClaudeResponse{
+		Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: "ok"}},
+	}, nil)
+
+	h := handlers.NewConversationHandler(sessionRepo, conversationRepo, cs, pub)
+	h.SetLimits(handlers.ConversationLimits{MaxConversations: 2, MaxMessages: 4})
+	h.SetModelAliases([]vo.Model{"analyst-default"})
+
+	create := func() (*aggregates.Conversation, error) {
+		return h.HandleCreateConversation(ctx, &commands.CreateConversationCommand{
+			SessionID: session.ID(), Model: "analyst-default", ContextType: vo.ContextMetrics, Temperature: -1,
+		})
+	}
+
+	first, err := create()
+	require.NoError(t, err)
+	assert.Equal(t, vo.Model("analyst-default"), first.Model(), "aliases are kept")
+	contextType, _ := first.GetMetadata("context_type")
+	assert.Equal(t, "metrics", contextType)
+
+	second, err := create()
+	require.NoError(t, err)
+	_, err = create()
+	assert.ErrorIs(t, err, handlers.ErrMaxConversations)
+
+	// Closing a conversation frees its slot
+	require.NoError(t, h.HandleCloseConversation(ctx, &commands.CloseConversationCommand{ConversationID: second.ID()}))
+	_, err = create()
+	require.NoError(t, err)
+
+	send := func() error {
+		_, err := h.HandleSendMessage(ctx, &commands.SendMessageCommand{ConversationID: first.ID(), Content: "hi"})
+		return err
+	}
+	require.NoError(t, send())
+	require.NoError(t, send())
+	assert.ErrorIs(t, send(), aggregates.ErrMaxMessagesExceeded)
+	assert.Equal(t, 4, first.MessageCount())
+
+	list, err := h.HandleListConversations(ctx, &queries.ListConversationsQuery{SessionID: session.ID(), ActiveOnly: true})
+	require.NoError(t, err)
+	require.Len(t, list.Conversations, 2)
+	assert.Equal(t, first.ID(), list.Conversations[0].ID(), "most recently updated first")
+}
+
 func TestHandleCloseConversation_AdditionalPaths(t *testing.T) {
 	ctx := context.Background()
 
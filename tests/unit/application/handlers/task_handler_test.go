@@ -0,0 +1,312 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/commands"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/queries"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+)
+
+type discardPublisher struct{}
+
+func (discardPublisher) Publish(ctx context.Context, event interface{}) error { return nil }
+
+// goDispatcher runs each task on its own goroutine
+type goDispatcher struct {
+	run func(ctx context.Context, taskID string) error
+	err error
+}
+
+func (d *goDispatcher) Dispatch(ctx context.Context, taskID string) error {
+	if d.err != nil {
+		return d.err
+	}
+	go func() { _ = d.run(context.Background(), taskID) }()
+	return nil
+}
+
+type taskFixture struct {
+	handler    *handlers.TaskHandler
+	dispatcher *goDispatcher
+	sessionID  vo.SessionID
+}
+
+func newTaskFixture(t *testing.T, options handlers.TaskOptions, tools ...*entities.Tool) *taskFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	sessionRepo := persistence.NewInMemorySessionRepository()
+	toolRepo := persistence.NewInMemoryToolRepository()
+	for _, tool := range tools {
+		require.NoError(t, toolRepo.Register(ctx, tool))
+	}
+	session := aggregates.NewSession()
+	require.NoError(t, sessionRepo.Save(ctx, session))
+
+	dispatcher := &goDispatcher{}
+	handler := handlers.NewTaskHandler(handlers.NewToolHandler(sessionRepo, toolRepo, discardPublisher{}), dispatcher, options)
+	dispatcher.run = handler.RunTask
+
+	return &taskFixture{handler: handler, dispatcher: dispatcher, sessionID: session.ID()}
+}
+
+func (f *taskFixture) submit(t *testing.T, name string) *entities.Task {
+	t.Helper()
+	task, err := f.handler.HandleSubmitToolTask(context.Background(), &commands.SubmitToolTaskCommand{
+		SessionID: f.sessionID,
+		Name:      name,
+		Arguments: map[string]interface{}{},
+	})
+	require.NoError(t, err)
+	return task
+}
+
+func (f *taskFixture) wait(t *testing.T, taskID string) *entities.Task {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	task, err := f.handler.HandleGetTaskResult(ctx, &queries.GetTaskResultQuery{SessionID: f.sessionID, TaskID: taskID, Wait: -1})
+	require.NoError(t, err)
+	return task
+}
+
+func newContextTool(t *testing.T, name string, handler entities.ContextToolHandler) *entities.Tool {
+	t.Helper()
+	tool := createTestTool(t, name)
+	tool.SetContextHandler(handler)
+	return tool
+}
+
+func TestTaskHandler_CompletesInBackground(t *testing.T) {
+	release := make(chan struct{})
+	tool := newContextTool(t, "slow", func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		entities.ReportProgress(ctx, entities.ToolProgress{Progress: 1, Message: "step one\nstep two\n"})
+		<-release
+		return entities.NewTextToolResult("done"), nil
+	})
+	f := newTaskFixture(t, handlers.TaskOptions{DefaultTTL: time.Hour, PollInterval: time.Second}, tool)
+
+	task := f.submit(t, "slow")
+	assert.Equal(t, vo.TaskStatusWorking, task.Status())
+	assert.Equal(t, time.Hour, task.TTL())
+
+	// Not finished yet: a zero wait returns the current status
+	require.Eventually(t, func() bool {
+		current, err := f.handler.HandleGetTask(context.Background(), &queries.GetTaskQuery{SessionID: f.sessionID, TaskID: task.ID()})
+		return err == nil && current.StatusMessage() == "step two"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	pending, err := f.handler.HandleGetTaskResult(context.Background(), &queries.GetTaskResultQuery{SessionID: f.sessionID, TaskID: task.ID()})
+	require.NoError(t, err)
+	assert.Equal(t, vo.TaskStatusWorking, pending.Status())
+	assert.Nil(t, pending.Result())
+
+	close(release)
+	finished := f.wait(t, task.ID())
+	assert.Equal(t, vo.TaskStatusCompleted, finished.Status())
+	require.NotNil(t, finished.Result())
+	assert.Equal(t, "done", finished.Result().Content[0].Text)
+}
+
+func TestTaskHandler_ErrorResultFailsTask(t *testing.T) {
+	tool := newContextTool(t, "broken", func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		return entities.NewErrorToolResult(errors.New("boom")), nil
+	})
+	f := newTaskFixture(t, handlers.TaskOptions{}, tool)
+
+	finished := f.wait(t, f.submit(t, "broken").ID())
+	assert.Equal(t, vo.TaskStatusFailed, finished.Status())
+	require.NotNil(t, finished.Result())
+	assert.True(t, finished.Result().IsError)
+}
+
+func TestTaskHandler_TimeoutOverridesToolTimeout(t *testing.T) {
+	tool := newContextTool(t, "sleepy", func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+			return entities.NewTextToolResult("woke"), nil
+		}
+	})
+	tool.SetTimeout(50 * time.Millisecond)
+	f := newTaskFixture(t, handlers.TaskOptions{Timeout: time.Minute}, tool)
+
+	finished := f.wait(t, f.submit(t, "sleepy").ID())
+	assert.Equal(t, vo.TaskStatusCompleted, finished.Status())
+}
+
+func TestTaskHandler_Cancel(t *testing.T) {
+	stopped := make(chan struct{})
+	tool := newContextTool(t, "blocking", func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		<-ctx.Done()
+		close(stopped)
+		return entities.NewTextToolResult("late"), nil
+	})
+	f := newTaskFixture(t, handlers.TaskOptions{}, tool)
+	task := f.submit(t, "blocking")
+
+	// Let the worker pick up the task before cancelling
+	time.Sleep(50 * time.Millisecond)
+
+	cancelled, err := f.handler.HandleCancelTask(context.Background(), &commands.CancelTaskCommand{SessionID: f.sessionID, TaskID: task.ID()})
+	require.NoError(t, err)
+	assert.Equal(t, vo.TaskStatusCancelled, cancelled.Status())
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tool was not cancelled")
+	}
+
+	// The late result does not overwrite the cancellation
+	finished := f.wait(t, task.ID())
+	assert.Equal(t, vo.TaskStatusCancelled, finished.Status())
+	assert.Nil(t, finished.Result())
+
+	_, err = f.handler.HandleCancelTask(context.Background(), &commands.CancelTaskCommand{SessionID: f.sessionID, TaskID: task.ID()})
+	assert.ErrorIs(t, err, handlers.ErrTaskAlreadyFinished)
+}
+
+func TestTaskHandler_SessionIsolation(t *testing.T) {
+	tool := newContextTool(t, "quick", func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		return entities.NewTextToolResult("ok"), nil
+	})
+	f := newTaskFixture(t, handlers.TaskOptions{}, tool)
+	task := f.submit(t, "quick")
+
+	other := vo.GenerateSessionID()
+	_, err := f.handler.HandleGetTask(context.Background(), &queries.GetTaskQuery{SessionID: other, TaskID: task.ID()})
+	assert.ErrorIs(t, err, handlers.ErrTaskNotFound)
+
+	_, err = f.handler.HandleCancelTask(context.Background(), &commands.CancelTaskCommand{SessionID: other, TaskID: task.ID()})
+	assert.ErrorIs(t, err, handlers.ErrTaskNotFound)
+
+	list, err := f.handler.HandleListTasks(context.Background(), &queries.ListTasksQuery{SessionID: other})
+	require.NoError(t, err)
+	assert.Empty(t, list.Tasks)
+}
+
+func TestTaskHandler_SubmitErrors(t *testing.T) {
+	tool := newContextTool(t, "quick", func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		return entities.NewTextToolResult("ok"), nil
+	})
+
+	t.Run("unknown tool", func(t *testing.T) {
+		f := newTaskFixture(t, handlers.TaskOptions{})
+		_, err := f.handler.HandleSubmitToolTask(context.Background(), &commands.SubmitToolTaskCommand{SessionID: f.sessionID, Name: "missing"})
+		assert.ErrorIs(t, err, handlers.ErrToolNotFound)
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		f := newTaskFixture(t, handlers.TaskOptions{}, tool)
+		_, err := f.handler.HandleSubmitToolTask(context.Background(), &commands.SubmitToolTaskCommand{SessionID: vo.GenerateSessionID(), Name: "quick"})
+		assert.ErrorIs(t, err, handlers.ErrSessionNotFound)
+	})
+
+	t.Run("dispatch failure", func(t *testing.T) {
+		f := newTaskFixture(t, handlers.TaskOptions{}, tool)
+		f.dispatcher.err = errors.New("queue is full")
+		_, err := f.handler.HandleSubmitToolTask(context.Background(), &commands.SubmitToolTaskCommand{SessionID: f.sessionID, Name: "quick"})
+		assert.ErrorContains(t, err, "queue is full")
+
+		list, err := f.handler.HandleListTasks(context.Background(), &queries.ListTasksQuery{SessionID: f.sessionID})
+		require.NoError(t, err)
+		assert.Empty(t, list.Tasks)
+	})
+
+	t.Run("too many tasks", func(t *testing.T) {
+		f := newTaskFixture(t, handlers.TaskOptions{MaxTasks: 1}, tool)
+		f.submit(t, "quick")
+		_, err := f.handler.HandleSubmitToolTask(context.Background(), &commands.SubmitToolTaskCommand{SessionID: f.sessionID, Name: "quick"})
+		assert.ErrorIs(t, err, handlers.ErrTooManyTasks)
+	})
+}
+
+func TestTaskHandler_TTL(t *testing.T) {
+	tool := newContextTool(t, "quick", func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		return entities.NewTextToolResult("ok"), nil
+	})
+	f := newTaskFixture(t, handlers.TaskOptions{DefaultTTL: time.Minute, MaxTTL: time.Hour}, tool)
+
+	task, err := f.handler.HandleSubmitToolTask(context.Background(), &commands.SubmitToolTaskCommand{
+		SessionID: f.sessionID,
+		Name:      "quick",
+		TTL:       48 * time.Hour,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, task.TTL())
+
+	// Finished tasks are dropped once their TTL elapses
+	short := newTaskFixture(t, handlers.TaskOptions{DefaultTTL: 20 * time.Millisecond}, tool)
+	expiring := short.submit(t, "quick")
+	short.wait(t, expiring.ID())
+	time.Sleep(40 * time.Millisecond)
+	_, err = short.handler.HandleGetTask(context.Background(), &queries.GetTaskQuery{SessionID: short.sessionID, TaskID: expiring.ID()})
+	assert.ErrorIs(t, err, handlers.ErrTaskNotFound)
+}
+
+func TestTaskHandler_ListPagination(t *testing.T) {
+	tool := newContextTool(t, "quick", func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		return entities.NewTextToolResult("ok"), nil
+	})
+	f := newTaskFixture(t, handlers.TaskOptions{}, tool)
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		ids = append(ids, f.submit(t, "quick").ID())
+		time.Sleep(time.Millisecond)
+	}
+
+	page, err := f.handler.HandleListTasks(context.Background(), &queries.ListTasksQuery{SessionID: f.sessionID, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Tasks, 2)
+	assert.Equal(t, ids[0], page.Tasks[0].ID())
+	assert.Equal(t, ids[1], page.NextCursor)
+
+	rest, err := f.handler.HandleListTasks(context.Background(), &queries.ListTasksQuery{SessionID: f.sessionID, Cursor: page.NextCursor, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, rest.Tasks, 1)
+	assert.Equal(t, ids[2], rest.Tasks[0].ID())
+	assert.Empty(t, rest.NextCursor)
+
+	mcp := page.ToMCPTaskList()
+	assert.Len(t, mcp["tasks"], 2)
+	assert.Equal(t, ids[1], mcp["nextCursor"])
+}
+
+func TestTaskHandler_RunTaskIgnoresDuplicates(t *testing.T) {
+	calls := make(chan struct{}, 2)
+	tool := newContextTool(t, "counted", func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		calls <- struct{}{}
+		return entities.NewTextToolResult("ok"), nil
+	})
+	f := newTaskFixture(t, handlers.TaskOptions{}, tool)
+	task := f.submit(t, "counted")
+	f.wait(t, task.ID())
+
+	require.NoError(t, f.handler.RunTask(context.Background(), task.ID()))
+	require.NoError(t, f.handler.RunTask(context.Background(), "unknown"))
+	assert.Len(t, calls, 1)
+}
+
+func TestSessionIDFromContext(t *testing.T) {
+	_, ok := handlers.SessionIDFromContext(context.Background())
+	assert.False(t, ok)
+
+	id := vo.GenerateSessionID()
+	got, ok := handlers.SessionIDFromContext(handlers.WithSessionID(context.Background(), id))
+	assert.True(t, ok)
+	assert.Equal(t, id, got)
+}
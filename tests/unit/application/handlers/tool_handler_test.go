@@ -14,6 +14,7 @@ import (
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/queries"
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
 	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/cache"
 )
 
 type mockToolRepo struct {
@@ -652,3 +653,38 @@ func TestExecuteToolWithContext_Timeout(t *testing.T) {
 	_ = result
 	_ = err
 }
+
+func TestHandleExecuteTool_ResultCache(t *testing.T) {
+	session := createInitializedSession()
+	calls := 0
+	tool := createTestTool(t, "cached_tool")
+	tool.SetHandler(func(input map[string]interface{}) (*entities.ToolResult, error) {
+		calls++
+		return entities.NewTextToolResult("result"), nil
+	})
+	sr := new(mockSessionRepo)
+	tr := new(mockToolRepo)
+	pub := new(mockEventPublisher)
+	sr.On("FindByID", mock.Anything, session.ID()).Return(session, nil)
+	tn, _ := vo.NewToolName("cached_tool")
+	tr.On("FindByName", mock.Anything, tn).Return(tool, nil)
+	pub.On("Publish", mock.Anything, mock.Anything).Return(nil)
+	h := handlers.NewToolHandler(sr, tr, pub)
+	h.SetResultCache(cache.NewToolResultCache(cache.NewMemoryCache(10, 0), map[string]cache.ToolCachePolicy{
+		"cached_tool": {TTL: time.Minute},
+	}, nil))
+
+	execute := func(bypass bool) *entities.ToolResult {
+		result, err := h.HandleExecuteTool(context.Background(), &commands.ExecuteToolCommand{
+			SessionID: session.ID(), Name: "cached_tool", Arguments: map[string]interface{}{"id": "a"}, BypassCache: bypass,
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	assert.Equal(t, cache.ToolCacheMiss, execute(false).Meta[cache.ToolCacheMetaKey])
+	assert.Equal(t, cache.ToolCacheHit, execute(false).Meta[cache.ToolCacheMetaKey])
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, cache.ToolCacheBypass, execute(true).Meta[cache.ToolCacheMetaKey])
+	assert.Equal(t, 2, calls)
+}
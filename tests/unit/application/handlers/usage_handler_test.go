@@ -0,0 +1,85 @@
+package handlers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/commands"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/queries"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+)
+
+func TestHandleGetLLMUsage(t *testing.T) {
+	ctx := context.Background()
+	repo := persistence.NewInMemoryLLMUsageRepository(100)
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	for i, model := range []vo.Model{vo.ModelClaudeOpus47, vo.ModelClaudeOpus47, vo.ModelGPT54} {
+		u := entities.NewLLMUsage(model, model)
+		u.OrganizationID = "acme"
+		u.InputTokens, u.OutputTokens = 1000, 100
+		u.CostUSD = 0.01 * float64(i+1)
+		u.Latency = time.Duration(i+1) * 100 * time.Millisecond
+		u.CalledAt = base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, repo.Save(ctx, u))
+	}
+	h := handlers.NewUsageHandler(repo)
+
+	report, err := h.HandleGetLLMUsage(ctx, &queries.GetLLMUsageQuery{GroupBy: "model"})
+	require.NoError(t, err)
+	require.Len(t, report.Groups, 2)
+	assert.Equal(t, vo.ModelClaudeOpus47.String(), report.Groups[0].Group)
+	assert.Equal(t, int64(3), report.Total.Requests)
+	assert.Equal(t, int64(3000), report.Total.InputTokens)
+	assert.InDelta(t, 0.06, report.Total.CostUSD, 1e-9)
+	assert.Equal(t, 200*time.Millisecond, report.Total.AvgLatency, "the total latency is weighted by requests")
+
+	report, err = h.HandleGetLLMUsage(ctx, &queries.GetLLMUsageQuery{Model: vo.ModelGPT54.String()})
+	require.NoError(t, err)
+	assert.Empty(t, report.Groups, "no groups without group_by")
+	assert.Equal(t, int64(1), report.Total.Requests)
+
+	report, err = h.HandleGetLLMUsage(ctx, &queries.GetLLMUsageQuery{OrganizationID: "globex"})
+	require.NoError(t, err)
+	assert.Zero(t, report.Total.Requests)
+	assert.Zero(t, report.Total.AvgLatency)
+
+	_, err = h.HandleGetLLMUsage(ctx, &queries.GetLLMUsageQuery{GroupBy: "tenant"})
+	assert.ErrorIs(t, err, handlers.ErrInvalidUsageGroup)
+
+	_, err = h.HandleGetLLMUsage(ctx, &queries.GetLLMUsageQuery{Since: base, Until: base})
+	assert.ErrorIs(t, err, handlers.ErrInvalidTimeRange)
+}
+
+func TestHandleExecuteTool_UsageScope(t *testing.T) {
+	session := createInitializedSession()
+	session.SetMetadata(handlers.SessionMetadataOrganizationID, "acme")
+
+	var scope entities.UsageScope
+	tool := createTestTool(t, "llm_tool")
+	tool.SetContextHandler(func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		scope = entities.UsageScopeFromContext(ctx)
+		return entities.NewTextToolResult("done"), nil
+	})
+
+	sr := new(mockSessionRepo)
+	tr := new(mockToolRepo)
+	pub := new(mockEventPublisher)
+	sr.On("FindByID", mock.Anything, session.ID()).Return(session, nil)
+	tr.On("FindByName", mock.Anything, tool.Name()).Return(tool, nil)
+	pub.On("Publish", mock.Anything, mock.Anything).Return(nil)
+
+	h := handlers.NewToolHandler(sr, tr, pub)
+	_, err := h.HandleExecuteTool(context.Background(), &commands.ExecuteToolCommand{SessionID: session.ID(), Name: "llm_tool"})
+	require.NoError(t, err)
+
+	// LLM calls made by the tool are billed to the session and its organization
+	assert.Equal(t, session.ID().String(), scope.SessionID)
+	assert.Equal(t, "acme", scope.OrganizationID)
+}
@@ -0,0 +1,294 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appsvc "github.com/telemetryflow/telemetryflow-go-mcp/internal/application/services"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// scriptedLLM answers each CreateMessage with the next scripted response
+type scriptedLLM struct {
+	mu        sync.Mutex
+	responses []*services.ClaudeResponse
+	requests  []*services.ClaudeRequest
+}
+
+func (s *scriptedLLM) CreateMessage(ctx context.Context, req *services.ClaudeRequest) (*services.ClaudeResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+	if len(s.responses) == 0 {
+		return nil, errors.New("no scripted response")
+	}
+	response := s.responses[0]
+	s.responses = s.responses[1:]
+	return response, nil
+}
+func (s *scriptedLLM) CreateMessageStream(ctx context.Context, req *services.ClaudeRequest) (<-chan *services.ClaudeStreamEvent, error) {
+	return nil, errors.New("not supported")
+}
+func (s *scriptedLLM) CountTokens(ctx context.Context, req *services.ClaudeRequest) (int, error) {
+	return 0, nil
+}
+func (s *scriptedLLM) ValidateRequest(req *services.ClaudeRequest) error { return nil }
+
+func toolUse(id, name string) entities.ContentBlock {
+	return entities.ContentBlock{Type: vo.ContentTypeToolUse, ID: id, Name: name, Input: map[string]interface{}{"id": id}}
+}
+
+func agentResponse(tokens int, content ...entities.ContentBlock) *services.ClaudeResponse {
+	stop := "end_turn"
+	for _, block := range content {
+		if block.Type == vo.ContentTypeToolUse {
+			stop = "tool_use"
+		}
+	}
+	return &services.ClaudeResponse{
+		Role:       vo.RoleAssistant,
+		Content:    content,
+		StopReason: stop,
+		Usage:      &services.ClaudeUsage{InputTokens: tokens, OutputTokens: tokens},
+	}
+}
+
+func textBlock(text string) entities.ContentBlock {
+	return entities.ContentBlock{Type: vo.ContentTypeText, Text: text}
+}
+
+// fakeAgentTools runs every tool by echoing its name, after delay
+type fakeAgentTools struct {
+	tools   []*entities.Tool
+	delay   time.Duration
+	calls   atomic.Int32
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func newFakeAgentTools(names ...string) *fakeAgentTools {
+	f := &fakeAgentTools{}
+	for _, n := range names {
+		name, _ := vo.NewToolName(n)
+		desc, _ := vo.NewToolDescription("test tool " + n)
+		tool, _ := entities.NewTool(name, desc, &entities.JSONSchema{Type: "object"})
+		f.tools = append(f.tools, tool)
+	}
+	return f
+}
+
+func (f *fakeAgentTools) AgentTools(ctx context.Context) ([]*entities.Tool, error) {
+	return f.tools, nil
+}
+
+func (f *fakeAgentTools) ExecuteAgentTool(ctx context.Context, name string, input map[string]interface{}) (*entities.ToolResult, error) {
+	f.calls.Add(1)
+	running := f.running.Add(1)
+	defer f.running.Add(-1)
+	for {
+		peak := f.peak.Load()
+		if running <= peak || f.peak.CompareAndSwap(peak, running) {
+			break
+		}
+	}
+	time.Sleep(f.delay)
+	if name == "broken" {
+		return nil, errors.New("tool exploded")
+	}
+	return entities.NewTextToolResult(fmt.Sprintf("%s(%v)", name, input["id"])), nil
+}
+
+func newAgentConversation() *aggregates.Conversation {
+	return aggregates.NewConversation(vo.GenerateSessionID(), vo.DefaultModel)
+}
+
+func agentOptions() appsvc.AgentOptions {
+	return appsvc.AgentOptions{MaxIterations: 5, TokenBudget: 10000, MaxParallelTools: 4, AllowedTools: []string{appsvc.AllowAllTools}}
+}
+
+// toolResults returns the tool_result blocks of message
+func toolResults(message *entities.Message) []entities.ContentBlock {
+	var results []entities.ContentBlock
+	for _, block := range message.Content() {
+		if block.Type == vo.ContentTypeToolResult {
+			results = append(results, block)
+		}
+	}
+	return results
+}
+
+func TestAgentService_Run(t *testing.T) {
+	llm := &scriptedLLM{responses: []*services.ClaudeResponse{
+		agentResponse(100, textBlock("Looking"), toolUse("t1", "metrics"), toolUse("t2", "logs")),
+		agentResponse(100, textBlock("Latency is fine")),
+	}}
+	tools := newFakeAgentTools("metrics", "logs")
+	agent := appsvc.NewAgentService(llm, tools, agentOptions())
+	conversation := newAgentConversation()
+
+	result, err := agent.Run(context.Background(), conversation, "How is latency?", appsvc.AgentOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Latency is fine", result.Text())
+	assert.Equal(t, 2, result.Iterations)
+	assert.Equal(t, 400, result.Usage.InputTokens+result.Usage.OutputTokens)
+	require.Len(t, result.ToolCalls, 2)
+	assert.Equal(t, "metrics", result.ToolCalls[0].Tool)
+	assert.Equal(t, "logs", result.ToolCalls[1].Tool)
+
+	// user, assistant(tool_use), user(tool_result), assistant
+	messages := conversation.Messages()
+	require.Len(t, messages, 4)
+	results := toolResults(messages[2])
+	require.Len(t, results, 2)
+	assert.Equal(t, "t1", results[0].ToolUseID)
+	assert.Equal(t, "metrics(t1)", results[0].Content)
+	assert.Equal(t, "logs(t2)", results[1].Content)
+
+	// The tools are offered on every request
+	require.Len(t, llm.requests, 2)
+	assert.Len(t, llm.requests[0].Tools, 2)
+	assert.Len(t, llm.requests[1].Messages, 3)
+}
+
+func TestAgentService_RunsToolsInParallel(t *testing.T) {
+	llm := &scriptedLLM{responses: []*services.ClaudeResponse{
+		agentResponse(10, toolUse("a", "x"), toolUse("b", "x"), toolUse("c", "x"), toolUse("d", "x"), toolUse("e", "x")),
+		agentResponse(10, textBlock("done")),
+	}}
+	tools := newFakeAgentTools("x")
+	tools.delay = 20 * time.Millisecond
+	options := agentOptions()
+	options.MaxParallelTools = 2
+	agent := appsvc.NewAgentService(llm, tools, options)
+
+	_, err := agent.Run(context.Background(), newAgentConversation(), "go", appsvc.AgentOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), tools.calls.Load())
+	assert.Equal(t, int32(2), tools.peak.Load())
+}
+
+func TestAgentService_ToolErrorsAreFedBack(t *testing.T) {
+	llm := &scriptedLLM{responses: []*services.ClaudeResponse{
+		agentResponse(10, toolUse("a", "broken"), toolUse("b", "execute_command")),
+		agentResponse(10, textBlock("Could not check")),
+	}}
+	tools := newFakeAgentTools("broken", "execute_command")
+	options := agentOptions()
+	options.AllowedTools = []string{"broken"}
+	agent := appsvc.NewAgentService(llm, tools, options)
+	conversation := newAgentConversation()
+
+	result, err := agent.Run(context.Background(), conversation, "go", appsvc.AgentOptions{})
+	require.NoError(t, err)
+	assert.Len(t, llm.requests[0].Tools, 1, "only allowed tools are offered")
+
+	results := toolResults(conversation.Messages()[2])
+	require.Len(t, results, 2)
+	assert.True(t, results[0].IsError)
+	assert.Contains(t, results[0].Content, "tool exploded")
+	assert.True(t, results[1].IsError)
+	assert.Contains(t, results[1].Content, appsvc.ErrAgentToolNotFound.Error())
+	assert.Equal(t, int32(1), tools.calls.Load(), "a tool that is not allowed never runs")
+	assert.True(t, result.ToolCalls[1].IsError)
+}
+
+func TestAgentService_Limits(t *testing.T) {
+	loopForever := func(n int) []*services.ClaudeResponse {
+		responses := make([]*services.ClaudeResponse, n)
+		for i := range responses {
+			responses[i] = agentResponse(1000, toolUse(fmt.Sprintf("t%d", i), "x"))
+		}
+		return responses
+	}
+
+	t.Run("max iterations", func(t *testing.T) {
+		llm := &scriptedLLM{responses: loopForever(10)}
+		tools := newFakeAgentTools("x")
+		agent := appsvc.NewAgentService(llm, tools, agentOptions())
+
+		result, err := agent.Run(context.Background(), newAgentConversation(), "go", appsvc.AgentOptions{MaxIterations: 3})
+		require.ErrorIs(t, err, appsvc.ErrAgentMaxIterations)
+		require.NotNil(t, result)
+		assert.Equal(t, 3, result.Iterations)
+		assert.Equal(t, int32(2), tools.calls.Load(), "tool calls of the last iteration are not run")
+	})
+
+	t.Run("options cannot raise the limits", func(t *testing.T) {
+		llm := &scriptedLLM{responses: loopForever(10)}
+		agent := appsvc.NewAgentService(llm, newFakeAgentTools("x"), agentOptions())
+
+		result, err := agent.Run(context.Background(), newAgentConversation(), "go", appsvc.AgentOptions{MaxIterations: 100, TokenBudget: 1 << 30})
+		require.Error(t, err)
+		assert.LessOrEqual(t, result.Iterations, 5)
+	})
+
+	t.Run("token budget", func(t *testing.T) {
+		llm := &scriptedLLM{responses: loopForever(10)}
+		agent := appsvc.NewAgentService(llm, newFakeAgentTools("x"), agentOptions())
+
+		result, err := agent.Run(context.Background(), newAgentConversation(), "go", appsvc.AgentOptions{TokenBudget: 5000})
+		require.ErrorIs(t, err, appsvc.ErrAgentTokenBudget)
+		assert.Equal(t, 3, result.Iterations)
+		assert.LessOrEqual(t, llm.requests[2].MaxTokens, 1000, "max_tokens is capped at the remaining budget")
+	})
+
+	t.Run("conversation continues after a limit", func(t *testing.T) {
+		llm := &scriptedLLM{responses: append(loopForever(1), agentResponse(10, textBlock("ok")))}
+		agent := appsvc.NewAgentService(llm, newFakeAgentTools("x"), agentOptions())
+		conversation := newAgentConversation()
+
+		_, err := agent.Run(context.Background(), conversation, "go", appsvc.AgentOptions{MaxIterations: 1})
+		require.ErrorIs(t, err, appsvc.ErrAgentMaxIterations)
+
+		result, err := agent.Run(context.Background(), conversation, "summarize", appsvc.AgentOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "ok", result.Text())
+
+		// The unanswered tool call is answered in the next user turn
+		turn := conversation.Messages()[2]
+		assert.Equal(t, vo.RoleUser, turn.Role())
+		results := toolResults(turn)
+		require.Len(t, results, 1)
+		assert.True(t, results[0].IsError)
+	})
+}
+
+func TestAgentService_RejectsNestedRuns(t *testing.T) {
+	var agent *appsvc.AgentService
+	var nestedErr error
+	tools := newFakeAgentTools("x")
+	nested := &nestingTools{fakeAgentTools: tools, run: func(ctx context.Context) {
+		_, nestedErr = agent.Run(ctx, newAgentConversation(), "again", appsvc.AgentOptions{})
+	}}
+	llm := &scriptedLLM{responses: []*services.ClaudeResponse{
+		agentResponse(10, toolUse("a", "x")),
+		agentResponse(10, textBlock("done")),
+	}}
+	agent = appsvc.NewAgentService(llm, nested, agentOptions())
+
+	_, err := agent.Run(context.Background(), newAgentConversation(), "go", appsvc.AgentOptions{})
+	require.NoError(t, err)
+	assert.ErrorIs(t, nestedErr, appsvc.ErrAgentNested)
+}
+
+// nestingTools calls run from inside a tool
+type nestingTools struct {
+	*fakeAgentTools
+	run func(ctx context.Context)
+}
+
+func (n *nestingTools) ExecuteAgentTool(ctx context.Context, name string, input map[string]interface{}) (*entities.ToolResult, error) {
+	n.run(ctx)
+	return n.fakeAgentTools.ExecuteAgentTool(ctx, name, input)
+}
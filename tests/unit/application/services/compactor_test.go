@@ -0,0 +1,145 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appsvc "github.com/telemetryflow/telemetryflow-go-mcp/internal/application/services"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/events"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+// smallModel has an 8K context window, so a few long messages exceed its budget
+const smallModel = vo.ModelMoonshotV18K
+
+// newLongConversation builds alternating user and assistant turns of size characters each
+func newLongConversation(t *testing.T, model vo.Model, turns, size int) *aggregates.Conversation {
+	t.Helper()
+	conversation := aggregates.NewConversation(vo.GenerateSessionID(), model)
+	conversation.SetMaxTokens(1024)
+	for i := range turns {
+		_, err := conversation.AddUserMessage(strings.Repeat("q", size))
+		require.NoError(t, err)
+		if i < turns-1 {
+			_, err = conversation.AddAssistantMessage([]entities.ContentBlock{{Type: vo.ContentTypeText, Text: strings.Repeat("a", size)}})
+			require.NoError(t, err)
+		}
+	}
+	return conversation
+}
+
+func compactionRequest(t *testing.T, conversation *aggregates.Conversation) *services.ClaudeRequest {
+	t.Helper()
+	request, err := appsvc.NewAgentService(nil, nil, appsvc.AgentOptions{}).BuildRequest(conversation)
+	require.NoError(t, err)
+	return request
+}
+
+func TestCompactor_UnderBudget(t *testing.T) {
+	llm := &scriptedLLM{}
+	conversation := newLongConversation(t, vo.ModelClaudeSonnet46, 3, 6000)
+	request := compactionRequest(t, conversation)
+
+	record, err := appsvc.NewCompactor(llm, appsvc.CompactionOptions{}).Fit(context.Background(), conversation, request)
+	require.NoError(t, err)
+	assert.Nil(t, record)
+	assert.Equal(t, 5, conversation.MessageCount())
+	assert.Empty(t, llm.requests)
+}
+
+func TestCompactor_DropsOldToolResults(t *testing.T) {
+	llm := &scriptedLLM{}
+	conversation := aggregates.NewConversation(vo.GenerateSessionID(), smallModel)
+	conversation.SetMaxTokens(1024)
+	_, _ = conversation.AddUserMessage("Which services are erroring?")
+	_, _ = conversation.AddAssistantMessage([]entities.ContentBlock{toolUse("t1", "query")})
+	result, _ := entities.NewMessage(vo.RoleUser, []entities.ContentBlock{
+		{Type: vo.ContentTypeToolResult, ToolUseID: "t1", Content: strings.Repeat("row\n", 10000)},
+	})
+	require.NoError(t, conversation.AddMessage(result))
+	for _, text := range []string{"checkout and cart", "Why checkout?", "A bad deploy", "Roll back?"} {
+		if conversation.LastMessage().Role() == vo.RoleUser {
+			_, _ = conversation.AddAssistantMessage([]entities.ContentBlock{{Type: vo.ContentTypeText, Text: text}})
+		} else {
+			_, _ = conversation.AddUserMessage(text)
+		}
+	}
+	request := compactionRequest(t, conversation)
+
+	record, err := appsvc.NewCompactor(llm, appsvc.CompactionOptions{KeepMessages: 4}).Fit(context.Background(), conversation, request)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, 1, record.ToolResults)
+	assert.Zero(t, record.SummarizedMessages)
+	assert.Greater(t, record.TokensBefore, record.TokensAfter)
+	assert.Empty(t, llm.requests, "no summary is needed")
+
+	// The tool result keeps its pairing but loses its content, in the conversation and the request
+	assert.Equal(t, 7, conversation.MessageCount())
+	dropped := conversation.Messages()[2].Content()[0]
+	assert.Equal(t, "t1", dropped.ToolUseID)
+	assert.NotContains(t, dropped.Content, "row")
+	assert.Equal(t, dropped.Content, request.Messages[2].Content[0].Content)
+
+	records, ok := conversation.GetMetadata(appsvc.CompactionMetadataKey)
+	require.True(t, ok)
+	assert.Equal(t, []interface{}{*record}, records)
+}
+
+func TestCompactor_SummarizesOlderTurns(t *testing.T) {
+	llm := &scriptedLLM{responses: []*services.ClaudeResponse{
+		{Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: "The user asked about checkout latency twice."}}},
+	}}
+	conversation := newLongConversation(t, "small-alias", 4, 6000)
+	conversation.ClearEvents()
+	request := compactionRequest(t, conversation)
+
+	compactor := appsvc.NewCompactor(llm, appsvc.CompactionOptions{
+		KeepMessages: 3,
+		SummaryModel: vo.ModelClaudeHaiku45,
+		ModelChain: func(model vo.Model) []vo.Model {
+			return []vo.Model{vo.ModelClaudeSonnet46, smallModel}
+		},
+	})
+	record, err := compactor.Fit(context.Background(), conversation, request)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, 4, record.SummarizedMessages)
+
+	// The summary model received the older turns
+	require.Len(t, llm.requests, 1)
+	assert.Equal(t, vo.ModelClaudeHaiku45, llm.requests[0].Model)
+	assert.Contains(t, llm.requests[0].Messages[0].Content[0].Text, "User: qqq")
+	assert.Contains(t, llm.requests[0].Messages[0].Content[0].Text, "Assistant: aaa")
+
+	// The summary is folded into the first kept user turn
+	messages := conversation.Messages()
+	require.Len(t, messages, 3)
+	assert.Equal(t, vo.RoleUser, messages[0].Role())
+	assert.Contains(t, messages[0].Content()[0].Text, "The user asked about checkout latency twice.")
+	assert.Equal(t, strings.Repeat("q", 6000), messages[0].Content()[1].Text)
+	require.Len(t, request.Messages, 3)
+	assert.Equal(t, messages[0].Content(), request.Messages[0].Content)
+
+	var compacted bool
+	for _, event := range conversation.Events() {
+		_, ok := event.(*events.ConversationCompactedEvent)
+		compacted = compacted || ok
+	}
+	assert.True(t, compacted)
+}
+
+func TestCompactor_SummaryFailure(t *testing.T) {
+	conversation := newLongConversation(t, smallModel, 4, 6000)
+	_, err := appsvc.NewCompactor(&scriptedLLM{}, appsvc.CompactionOptions{KeepMessages: 3}).
+		Fit(context.Background(), conversation, compactionRequest(t, conversation))
+	assert.ErrorIs(t, err, appsvc.ErrCompactionSummary)
+	assert.Equal(t, 7, conversation.MessageCount())
+}
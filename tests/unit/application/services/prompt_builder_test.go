@@ -58,6 +58,18 @@ func TestPromptBuilder_BuildContextPrompt(t *testing.T) {
 	assert.Contains(t, prompt, "```json")
 }
 
+func TestPromptBuilder_BuildContextBlock(t *testing.T) {
+	pb := appsvc.NewPromptBuilder()
+
+	ctx := &vo.TelemetryContext{Type: vo.ContextLogs, Summary: "3 errors.", Data: map[string]interface{}{"errors": 3}}
+	ctx.TimeRange = vo.DefaultTimeRange()
+
+	block := pb.BuildContextBlock(ctx)
+	assert.Equal(t, vo.ContentTypeText, block.Type)
+	assert.Equal(t, pb.BuildContextPrompt(ctx), block.Text)
+	assert.True(t, block.CacheBreakpoint)
+}
+
 func TestPromptBuilder_BuildContextPrompt_Truncation(t *testing.T) {
 	pb := appsvc.NewPromptBuilder()
 
@@ -364,6 +364,20 @@ func TestConversationMessageContent(t *testing.T) {
 		require.Len(t, content, 1)
 		assert.Equal(t, "Hello, Claude!", content[0].Text)
 	})
+
+	t.Run("should place attachments before the text", func(t *testing.T) {
+		conv := createTestConversation(t)
+		image, err := entities.NewMediaBlock(vo.MimeTypePNG, []byte("\x89PNG"), "")
+		require.NoError(t, err)
+
+		msg, err := conv.AddUserMessage("What does this dashboard show?", image)
+		require.NoError(t, err)
+
+		content := msg.Content()
+		require.Len(t, content, 2)
+		assert.Equal(t, vo.ContentTypeImage, content[0].Type)
+		assert.Equal(t, "What does this dashboard show?", msg.GetTextContent())
+	})
 }
 
 // Helper functions
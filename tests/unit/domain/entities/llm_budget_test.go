@@ -0,0 +1,93 @@
+package entities_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+func TestBudgetPeriod_Window(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	at := time.Date(2026, 12, 31, 23, 30, 0, 0, time.UTC)
+
+	start, end := entities.BudgetDaily.Window(at)
+	assert.Equal(t, time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), end)
+
+	start, end = entities.BudgetMonthly.Window(at.In(jakarta))
+	assert.Equal(t, time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), start, "windows are in UTC")
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), end)
+
+	assert.False(t, entities.BudgetPeriod("weekly").IsValid())
+}
+
+func TestLLMBudget_Validate(t *testing.T) {
+	valid := entities.NewLLMBudget("acme", entities.BudgetMonthly)
+	valid.TokenLimit = 1000
+	require.NoError(t, valid.Validate())
+
+	for _, tc := range []struct {
+		edit func(b *entities.LLMBudget)
+		err  error
+	}{
+		{func(b *entities.LLMBudget) { b.OrganizationID = "" }, entities.ErrBudgetWithoutOrganization},
+		{func(b *entities.LLMBudget) { b.Period = "weekly" }, entities.ErrInvalidBudgetPeriod},
+		{func(b *entities.LLMBudget) { b.TokenLimit = 0 }, entities.ErrBudgetWithoutLimit},
+		{func(b *entities.LLMBudget) { b.CostLimitUSD = -1 }, entities.ErrInvalidBudgetLimit},
+		{func(b *entities.LLMBudget) { b.WarnAt = 1 }, entities.ErrInvalidBudgetWarnAt},
+	} {
+		b := *valid
+		tc.edit(&b)
+		assert.ErrorIs(t, b.Validate(), tc.err)
+	}
+}
+
+func TestLLMBudget_ExceededAndUsed(t *testing.T) {
+	b := entities.NewLLMBudget("acme", entities.BudgetDaily)
+	b.TokenLimit = 1000
+	b.CostLimitUSD = 2
+
+	assert.Empty(t, b.Exceeded(entities.BudgetUsage{Tokens: 1000, CostUSD: 2}), "reaching a limit is within it")
+	assert.Equal(t, entities.BudgetLimitTokens, b.Exceeded(entities.BudgetUsage{Tokens: 1001}))
+	assert.Equal(t, entities.BudgetLimitCost, b.Exceeded(entities.BudgetUsage{CostUSD: 2.01}))
+	assert.InDelta(t, 0.75, b.Used(entities.BudgetUsage{Tokens: 500, CostUSD: 1.5}), 1e-9, "the largest share")
+
+	tokensOnly := entities.NewLLMBudget("acme", entities.BudgetDaily)
+	tokensOnly.TokenLimit = 100
+	assert.Empty(t, tokensOnly.Exceeded(entities.BudgetUsage{CostUSD: 1e6}), "a zero limit is unlimited")
+}
+
+func TestBudgetExceededError(t *testing.T) {
+	b := entities.NewLLMBudget("acme", entities.BudgetMonthly)
+	b.CostLimitUSD = 500
+	resets := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	err := entities.NewBudgetExceededError(b, entities.BudgetLimitCost,
+		entities.BudgetUsage{Tokens: 10, CostUSD: 499.5}, entities.BudgetUsage{CostUSD: 0.75}, resets)
+	assert.True(t, errors.Is(err, entities.ErrBudgetExceeded))
+	assert.Equal(t, "LLM budget exceeded: organization acme has used $499.50 of its monthly $500.00 budget "+
+		"and this call needs up to $0.75; the budget resets at 2026-11-01T00:00:00Z", err.Error())
+
+	details := err.ErrorDetails()
+	assert.Equal(t, "cost", details["limit"])
+	assert.Equal(t, 499.5, details["used"])
+	assert.Equal(t, "2026-11-01T00:00:00Z", details["resetsAt"])
+
+	// Tool results carry the MCP error code and details of the wrapped error
+	result := entities.NewErrorToolResult(fmt.Errorf("failed to send message: %w", err))
+	require.True(t, result.IsError)
+	meta, ok := result.Meta["error"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, int(vo.ErrorCodeBudgetExceeded), meta["code"])
+	assert.Equal(t, "Budget exceeded", meta["message"])
+	assert.Equal(t, details, meta["data"])
+
+	assert.Nil(t, entities.NewErrorToolResult(errors.New("plain")).Meta)
+}
@@ -2,6 +2,7 @@
 package entities_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
@@ -279,3 +280,28 @@ func TestMessage_MetadataGetter(t *testing.T) {
 		t.Errorf("Metadata() = %v, want val", md["key"])
 	}
 }
+
+func TestNewMediaBlock(t *testing.T) {
+	block, err := entities.NewMediaBlock(vo.MimeTypePNG, []byte("\x89PNG"), "chart.png")
+	if err != nil {
+		t.Fatalf("NewMediaBlock() error = %v", err)
+	}
+	if block.Type != vo.ContentTypeImage || block.Source.Type != "base64" || block.Source.Data != "iVBORw==" {
+		t.Errorf("NewMediaBlock() = %+v, want a base64 image", block)
+	}
+	if block.Name != "" {
+		t.Errorf("images carry no name, got %q", block.Name)
+	}
+
+	block, err = entities.NewMediaBlock(vo.MimeTypePDF, []byte("%PDF-1.7"), "report.pdf")
+	if err != nil {
+		t.Fatalf("NewMediaBlock() error = %v", err)
+	}
+	if block.Type != vo.ContentTypeDocument || block.Name != "report.pdf" || block.Source.MediaType != vo.MimeTypePDF {
+		t.Errorf("NewMediaBlock() = %+v, want a named PDF document", block)
+	}
+
+	if _, err := entities.NewMediaBlock("image/svg+xml", []byte("<svg/>"), ""); !errors.Is(err, vo.ErrUnsupportedMediaType) {
+		t.Errorf("NewMediaBlock(svg) error = %v, want ErrUnsupportedMediaType", err)
+	}
+}
@@ -0,0 +1,69 @@
+package entities_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+func TestTask_Lifecycle(t *testing.T) {
+	task := entities.NewTask(vo.GenerateSessionID(), "echo", map[string]interface{}{"message": "hi"}, time.Hour, 2*time.Second)
+	assert.NotEmpty(t, task.ID())
+	assert.Equal(t, vo.TaskStatusWorking, task.Status())
+	assert.False(t, task.IsTerminal())
+
+	task.SetStatusMessage("halfway")
+	assert.Equal(t, "halfway", task.StatusMessage())
+
+	assert.True(t, task.Complete(entities.NewTextToolResult("done")))
+	assert.Equal(t, vo.TaskStatusCompleted, task.Status())
+	assert.Empty(t, task.StatusMessage())
+
+	// Terminal tasks do not change again
+	assert.False(t, task.Cancel("too late"))
+	task.SetStatusMessage("ignored")
+	assert.Equal(t, vo.TaskStatusCompleted, task.Status())
+	assert.Empty(t, task.StatusMessage())
+}
+
+func TestTask_TerminalStatuses(t *testing.T) {
+	session := vo.GenerateSessionID()
+
+	failed := entities.NewTask(session, "echo", nil, 0, 0)
+	failed.Complete(entities.NewErrorToolResult(errors.New("boom")))
+	assert.Equal(t, vo.TaskStatusFailed, failed.Status())
+	assert.NotNil(t, failed.Result())
+
+	crashed := entities.NewTask(session, "echo", nil, 0, 0)
+	crashed.Fail("tool not found")
+	assert.Equal(t, vo.TaskStatusFailed, crashed.Status())
+	assert.Nil(t, crashed.Result())
+	assert.Equal(t, "tool not found", crashed.StatusMessage())
+
+	cancelled := entities.NewTask(session, "echo", nil, 0, 0)
+	cancelled.Cancel("user abort")
+	assert.Equal(t, vo.TaskStatusCancelled, cancelled.Status())
+}
+
+func TestTask_ExpiryAndMCPFormat(t *testing.T) {
+	task := entities.NewTask(vo.GenerateSessionID(), "echo", nil, time.Minute, 500*time.Millisecond)
+	assert.False(t, task.IsExpired(time.Now()))
+	assert.True(t, task.IsExpired(time.Now().Add(2*time.Minute)))
+
+	noTTL := entities.NewTask(vo.GenerateSessionID(), "echo", nil, 0, 0)
+	assert.False(t, noTTL.IsExpired(time.Now().Add(24*time.Hour)))
+
+	mcp := task.ToMCPTask()
+	assert.Equal(t, task.ID(), mcp["taskId"])
+	assert.Equal(t, "working", mcp["status"])
+	assert.Equal(t, int64(60000), mcp["ttl"])
+	assert.Equal(t, int64(500), mcp["pollInterval"])
+	assert.NotContains(t, mcp, "statusMessage")
+	_, err := time.Parse(time.RFC3339Nano, mcp["createdAt"].(string))
+	assert.NoError(t, err)
+}
@@ -1,6 +1,7 @@
 package entities_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -440,3 +441,57 @@ func BenchmarkTool_ToMCPTool(b *testing.B) {
 		_ = tool.ToMCPTool()
 	}
 }
+
+func TestTool_SetContextHandler(t *testing.T) {
+	name, _ := vo.NewToolName("ctx_tool")
+	desc, _ := vo.NewToolDescription("Context-aware tool")
+	tool, _ := entities.NewTool(name, desc, nil)
+
+	type ctxKey struct{}
+	tool.SetContextHandler(func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		value, _ := ctx.Value(ctxKey{}).(string)
+		return entities.NewTextToolResult("value=" + value), nil
+	})
+
+	if tool.Handler() == nil {
+		t.Fatal("Handler should fall back to the context handler")
+	}
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "set")
+	result, err := tool.ExecuteContext(ctx, nil)
+	if err != nil {
+		t.Fatalf("ExecuteContext() failed: %v", err)
+	}
+	if result.Content[0].Text != "value=set" {
+		t.Errorf("Expected context value to reach handler, got '%s'", result.Content[0].Text)
+	}
+
+	result, _ = tool.Execute(nil)
+	if result.Content[0].Text != "value=" {
+		t.Errorf("Expected background context from Execute, got '%s'", result.Content[0].Text)
+	}
+
+	// A plain handler replaces the context handler
+	tool.SetHandler(func(input map[string]interface{}) (*entities.ToolResult, error) {
+		return entities.NewTextToolResult("plain"), nil
+	})
+	result, _ = tool.ExecuteContext(ctx, nil)
+	if result.Content[0].Text != "plain" {
+		t.Errorf("Expected plain handler, got '%s'", result.Content[0].Text)
+	}
+}
+
+func TestReportProgress(t *testing.T) {
+	// Without a reporter this is a no-op
+	entities.ReportProgress(context.Background(), entities.ToolProgress{Progress: 1})
+
+	var got []entities.ToolProgress
+	ctx := entities.WithProgressReporter(context.Background(), func(update entities.ToolProgress) {
+		got = append(got, update)
+	})
+	entities.ReportProgress(ctx, entities.ToolProgress{Progress: 1, Total: 2, Message: "half"})
+
+	if len(got) != 1 || got[0].Message != "half" || got[0].Total != 2 {
+		t.Errorf("Expected one progress update, got %+v", got)
+	}
+}
@@ -0,0 +1,78 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+func TestClaudeRequest_OutputTokens(t *testing.T) {
+	tests := []struct {
+		name       string
+		model      vo.Model
+		maxTokens  int
+		reasoning  *vo.Reasoning
+		wantTokens int
+		wantBudget int
+	}{
+		{"no reasoning", vo.ModelClaudeSonnet46, 4096, nil, 4096, 0},
+		{"budget added to the answer", vo.ModelClaudeSonnet46, 4096, &vo.Reasoning{Effort: vo.ReasoningEffortMedium}, 4096 + 8192, 8192},
+		{"model that cannot reason", vo.ModelDeepSeekChat, 4096, &vo.Reasoning{Effort: vo.ReasoningEffortHigh}, 4096, 0},
+		{"local model may reason", vo.LocalModel(vo.ProviderOllama, "qwen3:8b"), 1024, &vo.Reasoning{BudgetTokens: 2000}, 3024, 2000},
+		{"capped at the output limit", vo.ModelKimiK2Thinking, 4096, &vo.Reasoning{BudgetTokens: 32000}, 32768, 28672},
+		{"budget keeps half the limit", vo.ModelKimiK2Thinking, 20000, &vo.Reasoning{Effort: vo.ReasoningEffortHigh}, 32768, 16384},
+		{"small budget is not raised", vo.ModelKimiK2Thinking, 32000, &vo.Reasoning{BudgetTokens: 1024}, 32768, 1024},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &services.ClaudeRequest{Model: tt.model, MaxTokens: tt.maxTokens, Reasoning: tt.reasoning}
+			tokens, budget := request.OutputTokens()
+			assert.Equal(t, tt.wantTokens, tokens)
+			assert.Equal(t, tt.wantBudget, budget)
+		})
+	}
+}
+
+func TestClaudeRequest_CheckInputs(t *testing.T) {
+	image, err := entities.NewMediaBlock(vo.MimeTypePNG, []byte("\x89PNG"), "")
+	require.NoError(t, err)
+	document, err := entities.NewMediaBlock(vo.MimeTypePDF, []byte("%PDF-1.7"), "report.pdf")
+	require.NoError(t, err)
+	messages := func(blocks ...entities.ContentBlock) []services.ClaudeMessage {
+		return []services.ClaudeMessage{{Role: vo.RoleUser, Content: append(blocks, entities.ContentBlock{Type: vo.ContentTypeText, Text: "Explain"})}}
+	}
+
+	request := &services.ClaudeRequest{Model: vo.ModelClaudeSonnet46, Messages: messages(image, document)}
+	assert.NoError(t, request.CheckInputs())
+
+	request = &services.ClaudeRequest{Model: vo.ModelMistralLarge3, Messages: messages(image)}
+	assert.NoError(t, request.CheckInputs())
+	request.Messages = messages(document)
+	assert.ErrorIs(t, request.CheckInputs(), vo.ErrUnsupportedInput)
+
+	request = &services.ClaudeRequest{Model: vo.ModelDeepSeekChat, Messages: messages(image)}
+	assert.ErrorIs(t, request.CheckInputs(), vo.ErrUnsupportedInput)
+}
+
+func TestClaudeRequest_EstimateMediaTokens(t *testing.T) {
+	image, err := entities.NewMediaBlock(vo.MimeTypePNG, []byte("\x89PNG"), "")
+	require.NoError(t, err)
+	document, err := entities.NewMediaBlock(vo.MimeTypePDF, make([]byte, 200*1024), "report.pdf")
+	require.NoError(t, err)
+	request := &services.ClaudeRequest{
+		Model: vo.ModelClaudeSonnet46,
+		Messages: []services.ClaudeMessage{{Role: vo.RoleUser, Content: []entities.ContentBlock{
+			image, document, {Type: vo.ContentTypeText, Text: "Compare them"},
+		}}},
+	}
+
+	chars, tokens := request.EstimateMediaTokens()
+	assert.Equal(t, len(image.Source.Data)+len(document.Source.Data), chars)
+	assert.Equal(t, 1600+4*3000, tokens, "an image, and a document of four 64 KB pages")
+	assert.Equal(t, tokens+len("Compare them")/4, request.EstimateInputTokens(), "base64 data is not counted as text")
+}
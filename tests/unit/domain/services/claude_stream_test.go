@@ -0,0 +1,94 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+func streamOf(events ...*services.ClaudeStreamEvent) <-chan *services.ClaudeStreamEvent {
+	ch := make(chan *services.ClaudeStreamEvent, len(events))
+	for _, event := range events {
+		ch <- event
+	}
+	close(ch)
+	return ch
+}
+
+func TestCollectStream(t *testing.T) {
+	events := streamOf(
+		&services.ClaudeStreamEvent{Type: "message_start", Message: &services.ClaudeResponse{ID: "msg_1", Model: "claude-sonnet-4-6", ServedBy: vo.ModelClaudeSonnet46},
+			Usage: &services.ClaudeUsage{InputTokens: 120}},
+		&services.ClaudeStreamEvent{Type: "content_block_start", Index: 0, ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeText}},
+		&services.ClaudeStreamEvent{Type: "content_block_delta", Index: 0, Delta: &services.ClaudeDelta{Type: "text_delta", Text: "Checking "}},
+		&services.ClaudeStreamEvent{Type: "content_block_delta", Index: 0, Delta: &services.ClaudeDelta{Type: "text_delta", Text: "latency."}},
+		&services.ClaudeStreamEvent{Type: "content_block_stop", Index: 0},
+		&services.ClaudeStreamEvent{Type: "content_block_start", Index: 1, ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeToolUse, ID: "t1", Name: "query"}},
+		&services.ClaudeStreamEvent{Type: "content_block_delta", Index: 1, Delta: &services.ClaudeDelta{Type: "input_json_delta", PartialJSON: `{"metric":`}},
+		&services.ClaudeStreamEvent{Type: "content_block_delta", Index: 1, Delta: &services.ClaudeDelta{Type: "input_json_delta", PartialJSON: `"p99"}`}},
+		&services.ClaudeStreamEvent{Type: "content_block_stop", Index: 1},
+		&services.ClaudeStreamEvent{Type: "message_delta", Delta: &services.ClaudeDelta{StopReason: "tool_use"}, Usage: &services.ClaudeUsage{OutputTokens: 30}},
+		&services.ClaudeStreamEvent{Type: "message_stop"},
+	)
+
+	var deltas []string
+	response, err := services.CollectStream(events, func(text string) { deltas = append(deltas, text) })
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Checking ", "latency."}, deltas)
+	assert.Equal(t, "msg_1", response.ID)
+	assert.Equal(t, vo.ModelClaudeSonnet46, response.ServedBy)
+	assert.Equal(t, "tool_use", response.StopReason)
+	assert.Equal(t, &services.ClaudeUsage{InputTokens: 120, OutputTokens: 30}, response.Usage)
+
+	require.Len(t, response.Content, 2)
+	assert.Equal(t, "Checking latency.", response.Content[0].Text)
+	assert.Equal(t, "t1", response.Content[1].ID)
+	assert.Equal(t, map[string]interface{}{"metric": "p99"}, response.Content[1].Input)
+}
+
+func TestCollectStream_Thinking(t *testing.T) {
+	events := streamOf(
+		&services.ClaudeStreamEvent{Type: "content_block_start", Index: 0, ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeThinking}},
+		&services.ClaudeStreamEvent{Type: "content_block_delta", Index: 0, Delta: &services.ClaudeDelta{Type: "thinking_delta", Thinking: "p99 rose "}},
+		&services.ClaudeStreamEvent{Type: "content_block_delta", Index: 0, Delta: &services.ClaudeDelta{Type: "thinking_delta", Thinking: "at 10:00."}},
+		&services.ClaudeStreamEvent{Type: "content_block_delta", Index: 0, Delta: &services.ClaudeDelta{Type: "signature_delta", Signature: "sig"}},
+		&services.ClaudeStreamEvent{Type: "content_block_stop", Index: 0},
+		&services.ClaudeStreamEvent{Type: "content_block_start", Index: 1, ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeRedactedThinking, Data: "opaque"}},
+		&services.ClaudeStreamEvent{Type: "content_block_stop", Index: 1},
+		&services.ClaudeStreamEvent{Type: "content_block_start", Index: 2, ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeText}},
+		&services.ClaudeStreamEvent{Type: "content_block_delta", Index: 2, Delta: &services.ClaudeDelta{Type: "text_delta", Text: "A deploy."}},
+		&services.ClaudeStreamEvent{Type: "content_block_stop", Index: 2},
+		&services.ClaudeStreamEvent{Type: "message_delta", Delta: &services.ClaudeDelta{StopReason: "end_turn"}, Usage: &services.ClaudeUsage{OutputTokens: 40, ReasoningTokens: 25}},
+	)
+
+	var deltas []string
+	response, err := services.CollectStream(events, func(text string) { deltas = append(deltas, text) })
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"A deploy."}, deltas, "reasoning is not passed on as text")
+	require.Len(t, response.Content, 3)
+	assert.Equal(t, entities.ContentBlock{Type: vo.ContentTypeThinking, Thinking: "p99 rose at 10:00.", Signature: "sig"}, response.Content[0])
+	assert.Equal(t, "opaque", response.Content[1].Data)
+	assert.Equal(t, "p99 rose at 10:00.", entities.ReasoningText(response.Content))
+	assert.Equal(t, 25, response.Usage.ReasoningTokens)
+}
+
+func TestCollectStream_Error(t *testing.T) {
+	streamErr := errors.New("connection reset")
+	response, err := services.CollectStream(streamOf(
+		&services.ClaudeStreamEvent{Type: "content_block_start", Index: 0, ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeText}},
+		&services.ClaudeStreamEvent{Type: "content_block_delta", Index: 0, Delta: &services.ClaudeDelta{Type: "text_delta", Text: "Partial"}},
+		&services.ClaudeStreamEvent{Error: streamErr},
+	), nil)
+
+	assert.ErrorIs(t, err, streamErr)
+	require.Len(t, response.Content, 1)
+	assert.Equal(t, "Partial", response.Content[0].Text)
+}
@@ -18,6 +18,8 @@ func TestContentType_IsValid(t *testing.T) {
 		{"image is valid", vo.ContentTypeImage, true},
 		{"tool_use is valid", vo.ContentTypeToolUse, true},
 		{"tool_result is valid", vo.ContentTypeToolResult, true},
+		{"thinking is valid", vo.ContentTypeThinking, true},
+		{"redacted_thinking is valid", vo.ContentTypeRedactedThinking, true},
 		{"invalid type", vo.ContentType("invalid"), false},
 		{"empty type", vo.ContentType(""), false},
 	}
@@ -127,6 +129,99 @@ func TestModel_String(t *testing.T) {
 	}
 }
 
+func TestModel_Provider(t *testing.T) {
+	tests := []struct {
+		model vo.Model
+		want  vo.Provider
+	}{
+		{vo.ModelClaudeOpus47, vo.ProviderAnthropic},
+		{vo.ModelGemini25Pro, vo.ProviderGoogle},
+		{vo.ModelGPT54, vo.ProviderOpenAI},
+		{vo.ModelO3, vo.ProviderOpenAI},
+		{vo.ModelDeepSeekReasoner, vo.ProviderDeepSeek},
+		{vo.ModelQwen36Plus, vo.ProviderQwen},
+		{vo.ModelMistralCodestral, vo.ProviderMistral},
+		{vo.ModelMistralMinistral38B, vo.ProviderMistral},
+		{vo.ModelGrok43, vo.ProviderXAI},
+		{vo.ModelKimiK26, vo.ProviderMoonshot},
+		{vo.ModelMoonshotV18K, vo.ProviderMoonshot},
+		{vo.ModelGLM51, vo.ProviderZhipu},
+		{vo.ModelMiMoV25Pro, vo.ProviderMiMo},
+		{vo.Model("llama3"), ""},
+		{vo.Model("ollama/qwen3:8b"), vo.ProviderOllama},
+		{vo.Model("local/mistral-7b-instruct"), vo.ProviderLocal},
+		{vo.Model("ollama/"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model.String(), func(t *testing.T) {
+			if got := tt.model.Provider(); got != tt.want {
+				t.Errorf("Model.Provider() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModel_Local(t *testing.T) {
+	tests := []struct {
+		model    vo.Model
+		local    bool
+		apiModel string
+	}{
+		{vo.LocalModel(vo.ProviderOllama, "llama3.2:latest"), true, "llama3.2:latest"},
+		{vo.Model("local/meta-llama/Llama-3.1-8B-Instruct"), true, "meta-llama/Llama-3.1-8B-Instruct"},
+		{vo.Model("ollama/"), false, "ollama/"},
+		{vo.Model("acme/model"), false, "acme/model"},
+		{vo.ModelClaudeSonnet46, false, "claude-sonnet-4-6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model.String(), func(t *testing.T) {
+			if got := tt.model.IsLocal(); got != tt.local {
+				t.Errorf("Model.IsLocal() = %v, want %v", got, tt.local)
+			}
+			if got := tt.model.ProviderModelName(); got != tt.apiModel {
+				t.Errorf("Model.ProviderModelName() = %v, want %v", got, tt.apiModel)
+			}
+			if tt.local && !tt.model.IsValid() {
+				t.Errorf("local model %s should be valid", tt.model)
+			}
+		})
+	}
+}
+
+func TestModel_Capabilities(t *testing.T) {
+	tests := []struct {
+		model   vo.Model
+		want    vo.ModelCapabilities
+		catalog bool
+	}{
+		{vo.ModelClaudeHaiku45, vo.ModelCapabilities{ContextWindow: 200000, MaxOutputTokens: 64000, Vision: true, Documents: true, Tools: true, Reasoning: true}, true},
+		{vo.ModelMoonshotV18K, vo.ModelCapabilities{ContextWindow: 8192, MaxOutputTokens: 4096, Tools: true}, true},
+		{vo.ModelMiMoV2TTS, vo.ModelCapabilities{ContextWindow: 8192, MaxOutputTokens: 8192}, true},
+		{vo.LocalModel(vo.ProviderOllama, "llama3.2"), vo.DefaultModelCapabilities, false},
+		{vo.Model("analyst-default"), vo.DefaultModelCapabilities, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model.String(), func(t *testing.T) {
+			if got := tt.model.Capabilities(); got != tt.want {
+				t.Errorf("Model.Capabilities() = %+v, want %+v", got, tt.want)
+			}
+			if _, ok := tt.model.CatalogCapabilities(); ok != tt.catalog {
+				t.Errorf("Model.CatalogCapabilities() ok = %v, want %v", ok, tt.catalog)
+			}
+		})
+	}
+
+	if got := vo.ModelMoonshotV18K.Capabilities().InputBudget(1024); got != 7168 {
+		t.Errorf("InputBudget(1024) = %d, want 7168", got)
+	}
+	if got := vo.ModelMoonshotV18K.Capabilities().InputBudget(100000); got != 4096 {
+		t.Errorf("InputBudget caps the output reserve at MaxOutputTokens, got %d", got)
+	}
+}
+
 func TestNewTextContent(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -20,6 +20,7 @@ func TestMCPMethod_IsValid(t *testing.T) {
 		vo.MethodResourcesList, vo.MethodResourcesRead, vo.MethodResourcesSubscribe, vo.MethodResourcesUnsubscribe,
 		vo.MethodPromptsList, vo.MethodPromptsGet,
 		vo.MethodCompletionComplete, vo.MethodLoggingSetLevel,
+		vo.MethodTasksGet, vo.MethodTasksResult, vo.MethodTasksList, vo.MethodTasksCancel,
 		vo.MethodNotificationsCancelled, vo.MethodNotificationsProgress, vo.MethodNotificationsMessage,
 		vo.MethodNotificationsResourcesUpdated, vo.MethodNotificationsResourcesListChanged,
 		vo.MethodNotificationsToolsListChanged, vo.MethodNotificationsPromptsListChanged,
@@ -45,7 +46,7 @@ func TestMCPMethod_IsNotification(t *testing.T) {
 func TestMCPCapability_IsValid(t *testing.T) {
 	caps := []vo.MCPCapability{
 		vo.CapabilityTools, vo.CapabilityResources, vo.CapabilityPrompts,
-		vo.CapabilityLogging, vo.CapabilitySampling, vo.CapabilityRoots, vo.CapabilityExperimental,
+		vo.CapabilityLogging, vo.CapabilitySampling, vo.CapabilityRoots, vo.CapabilityTasks, vo.CapabilityExperimental,
 	}
 	for _, c := range caps {
 		if !c.IsValid() {
@@ -59,6 +60,23 @@ func TestMCPCapability_String(t *testing.T) {
 	assert.Equal(t, "tools", vo.CapabilityTools.String())
 }
 
+func TestMCPTaskStatus(t *testing.T) {
+	statuses := []vo.MCPTaskStatus{
+		vo.TaskStatusWorking, vo.TaskStatusInputRequired,
+		vo.TaskStatusCompleted, vo.TaskStatusFailed, vo.TaskStatusCancelled,
+	}
+	for _, s := range statuses {
+		assert.True(t, s.IsValid(), s.String())
+	}
+	assert.False(t, vo.MCPTaskStatus("done").IsValid())
+
+	assert.False(t, vo.TaskStatusWorking.IsTerminal())
+	assert.False(t, vo.TaskStatusInputRequired.IsTerminal())
+	assert.True(t, vo.TaskStatusCompleted.IsTerminal())
+	assert.True(t, vo.TaskStatusFailed.IsTerminal())
+	assert.True(t, vo.TaskStatusCancelled.IsTerminal())
+}
+
 func TestMCPLogLevel_IsValid(t *testing.T) {
 	levels := []vo.MCPLogLevel{
 		vo.LogLevelDebug, vo.LogLevelInfo, vo.LogLevelNotice, vo.LogLevelWarning,
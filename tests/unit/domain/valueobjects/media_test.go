@@ -0,0 +1,53 @@
+package valueobjects_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+func TestCheckMedia(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		size      int
+		want      vo.ContentType
+		wantErr   error
+	}{
+		{vo.MimeTypePNG, 1024, vo.ContentTypeImage, nil},
+		{vo.MimeTypeWebP, vo.MaxImageBytes, vo.ContentTypeImage, nil},
+		{vo.MimeTypePDF, 10 * 1024 * 1024, vo.ContentTypeDocument, nil},
+		{vo.MimeTypeJPEG, vo.MaxImageBytes + 1, "", vo.ErrMediaTooLarge},
+		{vo.MimeTypePDF, vo.MaxDocumentBytes + 1, "", vo.ErrMediaTooLarge},
+		{vo.MimeTypeGIF, 0, "", vo.ErrEmptyContent},
+		{"image/tiff", 1024, "", vo.ErrUnsupportedMediaType},
+		{vo.MimeTypePlainText, 1024, "", vo.ErrUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mediaType, func(t *testing.T) {
+			got, err := vo.CheckMedia(tt.mediaType, tt.size)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.True(t, got.IsMedia())
+			assert.True(t, got.IsValid())
+		})
+	}
+}
+
+func TestModel_Accepts(t *testing.T) {
+	assert.True(t, vo.ModelClaudeSonnet46.Accepts(vo.ContentTypeImage))
+	assert.True(t, vo.ModelClaudeSonnet46.Accepts(vo.ContentTypeDocument))
+	assert.True(t, vo.ModelGemini25Flash.Accepts(vo.ContentTypeDocument))
+	assert.True(t, vo.ModelMistralLarge3.Accepts(vo.ContentTypeImage))
+	assert.False(t, vo.ModelMistralLarge3.Accepts(vo.ContentTypeDocument), "Mistral models take images, not PDFs")
+	assert.False(t, vo.ModelDeepSeekChat.Accepts(vo.ContentTypeImage))
+	assert.True(t, vo.ModelDeepSeekChat.Accepts(vo.ContentTypeText))
+	assert.True(t, vo.LocalModel(vo.ProviderOllama, "llava").Accepts(vo.ContentTypeImage), "models outside the catalog are left to their provider")
+}
@@ -0,0 +1,75 @@
+package valueobjects_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+func TestModelPrice_Cost(t *testing.T) {
+	price := vo.ModelPrice{Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75}
+
+	assert.InDelta(t, 0.0, price.Cost(0, 0, 0, 0), 1e-12)
+	assert.InDelta(t, 3.0, price.Cost(1_000_000, 0, 0, 0), 1e-12)
+	assert.InDelta(t, 0.003+0.0075+0.0003+0.00375, price.Cost(1000, 500, 1000, 1000), 1e-12)
+}
+
+func TestDefaultPriceTable(t *testing.T) {
+	table := vo.DefaultPriceTable()
+	assert.Equal(t, vo.PricingVersion, table.Version)
+
+	for _, model := range []vo.Model{
+		vo.ModelClaudeOpus47, vo.ModelGemini25Pro, vo.ModelGPT54, vo.ModelDeepSeekChat,
+		vo.ModelQwen36Plus, vo.ModelMistralLarge3, vo.ModelGrok43, vo.ModelKimiK26,
+		vo.ModelGLM51, vo.ModelMiMoV25Pro,
+	} {
+		price, ok := table.Price(model)
+		require.True(t, ok, model)
+		assert.Positive(t, price.Input, model)
+		assert.Positive(t, price.Output, model)
+	}
+
+	// Anthropic bills cache reads at a tenth of the input price and writes at a quarter more
+	opus, _ := table.Price(vo.ModelClaudeOpus47)
+	assert.Equal(t, vo.ModelPrice{Input: 5, Output: 25, CacheRead: 0.5, CacheWrite: 6.25}, opus)
+
+	// Free tier models are priced at zero rather than unknown
+	flash, ok := table.Price(vo.ModelGLM4Flash)
+	require.True(t, ok)
+	assert.Zero(t, flash)
+}
+
+func TestPriceTable_Price(t *testing.T) {
+	table := vo.DefaultPriceTable()
+
+	local, ok := table.Price("ollama/llama3.2")
+	require.True(t, ok, "local models are free")
+	assert.Zero(t, local)
+
+	_, ok = table.Price("unknown-model")
+	assert.False(t, ok)
+}
+
+func TestPriceTable_Override(t *testing.T) {
+	table := vo.DefaultPriceTable()
+	contract := vo.ModelPrice{Input: 4, Output: 20, CacheRead: 0.4, CacheWrite: 5}
+
+	overridden := table.Override("acme-2026", map[vo.Model]vo.ModelPrice{
+		vo.ModelClaudeOpus47: contract,
+		"ollama/llama3.2":    {Input: 0.01, Output: 0.01},
+	})
+	assert.Equal(t, "acme-2026", overridden.Version)
+
+	price, _ := overridden.Price(vo.ModelClaudeOpus47)
+	assert.Equal(t, contract, price)
+	price, _ = overridden.Price("ollama/llama3.2")
+	assert.Equal(t, 0.01, price.Input, "a table may price local models")
+	price, _ = overridden.Price(vo.ModelGPT54)
+	assert.Equal(t, 2.5, price.Input, "other models keep their default price")
+
+	original, _ := table.Price(vo.ModelClaudeOpus47)
+	assert.Equal(t, 5.0, original.Input, "the original table is left untouched")
+}
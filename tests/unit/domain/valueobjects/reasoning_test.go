@@ -0,0 +1,53 @@
+package valueobjects_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+)
+
+func TestNewReasoning(t *testing.T) {
+	reasoning, err := vo.NewReasoning("", 0)
+	require.NoError(t, err)
+	assert.Nil(t, reasoning, "nothing asked for is no reasoning")
+	assert.False(t, reasoning.IsEnabled())
+	assert.Zero(t, reasoning.Budget())
+
+	reasoning, err = vo.NewReasoning("medium", 0)
+	require.NoError(t, err)
+	assert.True(t, reasoning.IsEnabled())
+	assert.Equal(t, 8192, reasoning.Budget())
+	assert.Equal(t, vo.ReasoningEffortMedium, reasoning.Level())
+
+	reasoning, err = vo.NewReasoning("low", 16000)
+	require.NoError(t, err)
+	assert.Equal(t, 16000, reasoning.Budget(), "an explicit budget takes precedence")
+	assert.Equal(t, vo.ReasoningEffortLow, reasoning.Level())
+
+	_, err = vo.NewReasoning("extreme", 0)
+	assert.ErrorIs(t, err, vo.ErrInvalidReasoningEffort)
+	_, err = vo.NewReasoning("", 512)
+	assert.ErrorIs(t, err, vo.ErrInvalidReasoningBudget)
+	_, err = vo.NewReasoning("", -1)
+	assert.ErrorIs(t, err, vo.ErrInvalidReasoningBudget)
+}
+
+func TestReasoning_Level(t *testing.T) {
+	tests := []struct {
+		budget int
+		want   vo.ReasoningEffort
+	}{
+		{1024, vo.ReasoningEffortLow},
+		{2048, vo.ReasoningEffortLow},
+		{4096, vo.ReasoningEffortMedium},
+		{8192, vo.ReasoningEffortMedium},
+		{32000, vo.ReasoningEffortHigh},
+	}
+	for _, tt := range tests {
+		reasoning := &vo.Reasoning{BudgetTokens: tt.budget}
+		assert.Equal(t, tt.want, reasoning.Level(), "budget %d", tt.budget)
+	}
+}
@@ -0,0 +1,144 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/audit"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+)
+
+// fakeBatch records analytics events and flushes
+type fakeBatch struct {
+	mu      sync.Mutex
+	pending []interface{}
+	sent    []interface{}
+	flushes int
+}
+
+func (b *fakeBatch) Add(event interface{}) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = append(b.pending, event)
+	return nil
+}
+
+func (b *fakeBatch) Flush(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.flushes++
+	b.sent = append(b.sent, b.pending...)
+	b.pending = nil
+	return nil
+}
+
+func (b *fakeBatch) sentCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.sent)
+}
+
+func TestTrail_WritesRecordsAndAnalytics(t *testing.T) {
+	repo := persistence.NewInMemoryToolExecutionRepository(100)
+	batch := &fakeBatch{}
+	trail := audit.NewTrail(repo, batch, audit.Options{BufferSize: 10, FlushInterval: time.Hour}, zerolog.Nop())
+	trail.Start()
+
+	for i := 0; i < 3; i++ {
+		trail.Record(context.Background(), entities.NewToolExecution("", "query_metrics", nil))
+	}
+	require.NoError(t, trail.Close())
+
+	saved, err := repo.Search(context.Background(), repositories.ToolExecutionFilter{})
+	require.NoError(t, err)
+	assert.Len(t, saved, 3)
+	assert.Equal(t, 3, batch.sentCount(), "close flushes pending analytics events")
+
+	// Records after close are ignored
+	trail.Record(context.Background(), entities.NewToolExecution("", "query_metrics", nil))
+	require.NoError(t, trail.Close())
+	saved, err = repo.Search(context.Background(), repositories.ToolExecutionFilter{})
+	require.NoError(t, err)
+	assert.Len(t, saved, 3)
+}
+
+func TestTrail_FlushesOnInterval(t *testing.T) {
+	repo := persistence.NewInMemoryToolExecutionRepository(100)
+	batch := &fakeBatch{}
+	trail := audit.NewTrail(repo, batch, audit.Options{BufferSize: 10, FlushInterval: 10 * time.Millisecond}, zerolog.Nop())
+	trail.Start()
+	defer trail.Close()
+
+	trail.Record(context.Background(), entities.NewToolExecution("", "query_logs", nil))
+	assert.Eventually(t, func() bool { return batch.sentCount() == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestTrail_DropsWhenBufferFull(t *testing.T) {
+	repo := persistence.NewInMemoryToolExecutionRepository(100)
+	// Not started, so nothing drains the buffer
+	trail := audit.NewTrail(repo, nil, audit.Options{BufferSize: 2}, zerolog.Nop())
+
+	for i := 0; i < 5; i++ {
+		trail.Record(context.Background(), entities.NewToolExecution("", "query_logs", nil))
+	}
+	assert.Equal(t, int64(3), trail.Dropped())
+
+	trail.Start()
+	require.NoError(t, trail.Close())
+	saved, err := repo.Search(context.Background(), repositories.ToolExecutionFilter{})
+	require.NoError(t, err)
+	assert.Len(t, saved, 2)
+}
+
+func TestTrail_AppliesRetentionOnStart(t *testing.T) {
+	ctx := context.Background()
+	repo := persistence.NewInMemoryToolExecutionRepository(100)
+	old := entities.NewToolExecution("", "query_logs", nil)
+	old.ExecutedAt = time.Now().Add(-48 * time.Hour)
+	recent := entities.NewToolExecution("", "query_logs", nil)
+	require.NoError(t, repo.Save(ctx, old))
+	require.NoError(t, repo.Save(ctx, recent))
+
+	trail := audit.NewTrail(repo, nil, audit.Options{BufferSize: 1, Retention: 24 * time.Hour}, zerolog.Nop())
+	trail.Start()
+	require.NoError(t, trail.Close())
+
+	saved, err := repo.Search(ctx, repositories.ToolExecutionFilter{})
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	assert.Equal(t, recent.ID, saved[0].ID)
+}
+
+func TestToolCallEvent(t *testing.T) {
+	execution := entities.NewToolExecution("", "query_logs", map[string]interface{}{"query": "error"})
+	execution.Status = entities.ToolExecutionTimeout
+	execution.ErrorMessage = "context deadline exceeded"
+	execution.Duration = 1500 * time.Millisecond
+	execution.ClientName = "claude-desktop"
+	execution.APIKeyID = "key-1"
+
+	event := audit.ToolCallEvent(execution)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000000", event.SessionID)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000000", event.ConversationID)
+	assert.Equal(t, "query_logs", event.ToolName)
+	assert.Equal(t, uint64(1500), event.DurationMs)
+	assert.True(t, event.IsError)
+	assert.Equal(t, "context deadline exceeded", event.ErrorMessage)
+	assert.Equal(t, uint32(len(`{"query":"error"}`)), event.InputSize)
+
+	var metadata map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(event.Metadata), &metadata))
+	assert.Equal(t, "timeout", metadata["status"])
+	assert.Equal(t, "claude-desktop", metadata["client_name"])
+	assert.Equal(t, "key-1", metadata["api_key_id"])
+	assert.Equal(t, execution.ID, metadata["execution_id"])
+}
@@ -0,0 +1,109 @@
+package bootstrap_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/bootstrap"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/llm"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+)
+
+func TestNewLLMRegistry(t *testing.T) {
+	disabled := false
+	cfg := config.DefaultConfig()
+	cfg.Claude.APIKey = "test-key"
+	cfg.Providers = config.ProvidersConfig{
+		"deepseek": {APIKey: "ds-key", DefaultModel: "deepseek-chat"},
+		"ollama":   {BaseURL: "http://127.0.0.1:11434"},
+		"mistral":  {APIKey: "m-key", Enabled: &disabled},
+		"acme":     {APIKey: "acme-key"},
+	}
+
+	registry, err := bootstrap.NewLLMRegistry(cfg, zerolog.Nop())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"anthropic", "deepseek", "ollama"}, bootstrap.ProviderNames(registry))
+
+	model, ok := registry.DefaultModel(vo.ProviderDeepSeek)
+	assert.True(t, ok)
+	assert.Equal(t, vo.Model("deepseek-chat"), model)
+
+	cfg.Claude.APIKey = ""
+	cfg.Providers = nil
+	registry, err = bootstrap.NewLLMRegistry(cfg, zerolog.Nop())
+	require.NoError(t, err)
+	assert.Empty(t, bootstrap.ProviderNames(registry))
+}
+
+func TestPriceTable(t *testing.T) {
+	assert.Equal(t, vo.DefaultPriceTable(), bootstrap.PriceTable(config.PricingConfig{}))
+
+	table := bootstrap.PriceTable(config.PricingConfig{
+		Version: "2026-10",
+		Models:  []config.ModelPriceConfig{{Model: "local/llama-3.1-8b", Input: 0.1, Output: 0.2}},
+	})
+	assert.Equal(t, "2026-10", table.Version)
+	price, ok := table.Price("local/llama-3.1-8b")
+	require.True(t, ok)
+	assert.Equal(t, vo.ModelPrice{Input: 0.1, Output: 0.2}, price)
+	_, ok = table.Price("claude-sonnet-4-20250514")
+	assert.True(t, ok, "built-in prices are kept")
+}
+
+func TestNewUsageRecorder(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Usage.ClickHouse = true // ignored while clickhouse.enabled is off
+
+	recorder, repo, cleanup := bootstrap.NewUsageRecorder(cfg, zerolog.Nop())
+	require.IsType(t, &persistence.InMemoryLLMUsageRepository{}, repo)
+
+	usage := entities.NewLLMUsage("claude-sonnet-4-20250514", "claude-sonnet-4-20250514")
+	usage.InputTokens = 100
+	recorder.RecordUsage(context.Background(), usage)
+	cleanup()
+
+	summaries, err := repo.Summarize(context.Background(), repositories.LLMUsageFilter{})
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, int64(1), summaries[0].Requests)
+	assert.Equal(t, int64(100), summaries[0].InputTokens)
+}
+
+func TestNewBudgetGuard(t *testing.T) {
+	hardLimit := false
+	cfg := config.DefaultConfig()
+	cfg.Budgets.Enabled = true
+	cfg.Budgets.Backend = "redis"
+	cfg.Budgets.RedisURL = "redis://127.0.0.1:1/0" // unreachable, so counters stay in memory
+	cfg.Budgets.Limits = []config.BudgetLimitConfig{
+		{OrganizationID: "org-1", Period: "daily", Tokens: 1000, HardLimit: &hardLimit},
+		{OrganizationID: "org-2", Period: "monthly", CostUSD: 50},
+	}
+
+	repo := persistence.NewInMemoryLLMBudgetRepository()
+	router := llm.NewRouter(llm.NewRegistry(), cfg.Routing, nil, zerolog.Nop())
+	guard, cleanup := bootstrap.NewBudgetGuard(cfg, router, repo, zerolog.Nop())
+	defer cleanup()
+	require.NotNil(t, guard)
+
+	budgets, err := repo.FindByOrganization(context.Background(), "org-1")
+	require.NoError(t, err)
+	require.Len(t, budgets, 1)
+	assert.Equal(t, entities.BudgetPeriod("daily"), budgets[0].Period)
+	assert.Equal(t, int64(1000), budgets[0].TokenLimit)
+	assert.False(t, budgets[0].HardLimit)
+
+	budgets, err = repo.FindByOrganization(context.Background(), "org-2")
+	require.NoError(t, err)
+	require.Len(t, budgets, 1)
+	assert.Equal(t, 50.0, budgets[0].CostLimitUSD)
+	assert.True(t, budgets[0].HardLimit, "hard limits are the default")
+}
@@ -0,0 +1,46 @@
+package bootstrap_test
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/bootstrap"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+)
+
+func TestNewRepositories_InMemory(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Database.Enabled = false
+
+	repos, err := bootstrap.NewRepositories(cfg, zerolog.Nop())
+	require.NoError(t, err)
+	defer repos.Close()
+
+	assert.IsType(t, &persistence.InMemorySessionRepository{}, repos.Sessions)
+	assert.IsType(t, &persistence.InMemoryConversationRepository{}, repos.Conversations)
+	assert.IsType(t, &persistence.InMemoryToolRepository{}, repos.Tools)
+	assert.IsType(t, &persistence.InMemoryToolExecutionRepository{}, repos.Executions)
+	assert.IsType(t, &persistence.InMemoryLLMBudgetRepository{}, repos.Budgets)
+}
+
+func TestNewRepositories_DatabaseUnreachable(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Database.Enabled = true
+	cfg.Database.Host = "127.0.0.1"
+	cfg.Database.Port = 1
+
+	_, err := bootstrap.NewRepositories(cfg, zerolog.Nop())
+	assert.Error(t, err)
+}
+
+func TestConnectTelemetryStores_Disabled(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Database.Enabled = false
+	cfg.Clickhouse.Enabled = false
+
+	assert.Equal(t, bootstrap.TelemetryStores{}, bootstrap.ConnectTelemetryStores(cfg, zerolog.Nop()))
+}
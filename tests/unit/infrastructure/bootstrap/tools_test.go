@@ -0,0 +1,106 @@
+package bootstrap_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/bootstrap"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/cache"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+)
+
+func TestNewToolResultCache(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ToolCache.Backend = "redis"
+	cfg.ToolCache.RedisURL = "redis://127.0.0.1:1/0" // unreachable, so entries stay in memory
+	cfg.ToolCache.MaxEntrySize = 64
+	cfg.ToolCache.Tools = []config.ToolCachePolicyConfig{
+		{Tool: "get_incident", TTL: time.Minute},
+		{Tool: "get_report", TTL: time.Minute, MaxEntrySize: 1024},
+	}
+
+	resultCache, cleanup := bootstrap.NewToolResultCache(cfg, zerolog.Nop())
+	defer cleanup()
+
+	execute := func(tool, text string) string {
+		result, err := resultCache.Execute(context.Background(), "session-1", tool, map[string]interface{}{"size": len(text)}, false,
+			func(context.Context) (*entities.ToolResult, error) {
+				return entities.NewTextToolResult(text), nil
+			})
+		require.NoError(t, err)
+		return result.Meta[cache.ToolCacheMetaKey].(string)
+	}
+
+	assert.Equal(t, cache.ToolCacheMiss, execute("get_incident", "ok"))
+	assert.Equal(t, cache.ToolCacheHit, execute("get_incident", "ok"))
+
+	// A policy without max_entry_size uses the global limit; its own limit overrides it
+	large := strings.Repeat("x", 100)
+	assert.Equal(t, cache.ToolCacheMiss, execute("get_incident", large))
+	assert.Equal(t, cache.ToolCacheMiss, execute("get_incident", large))
+	assert.Equal(t, cache.ToolCacheMiss, execute("get_report", large))
+	assert.Equal(t, cache.ToolCacheHit, execute("get_report", large))
+}
+
+func TestNewAuditTrail(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Audit.ClickHouse = true // ignored while clickhouse.enabled is off
+
+	repo := persistence.NewInMemoryToolExecutionRepository(10)
+	trail, cleanup := bootstrap.NewAuditTrail(cfg, repo, zerolog.Nop())
+	trail.Record(context.Background(), entities.NewToolExecution("session-1", "echo", nil))
+	cleanup()
+
+	records, err := repo.Search(context.Background(), repositories.ToolExecutionFilter{})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "echo", records[0].ToolName)
+}
+
+func TestNewUpstreamProxy(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Upstreams.ConnectTimeout = 2 * time.Second
+	cfg.Upstreams.Servers = []config.UpstreamServerConfig{
+		{Name: "off", Transport: "stdio", Command: "true", Disabled: true},
+		{Name: "broken", Transport: "stdio", Command: "/nonexistent/mcp-server"},
+	}
+
+	proxy := bootstrap.NewUpstreamProxy(cfg, zerolog.Nop())
+	defer func() { _ = proxy.Close() }()
+	assert.Empty(t, proxy.Tools(), "servers that are disabled or fail to connect are skipped")
+}
+
+func TestTaskQueue(t *testing.T) {
+	for name, tasks := range map[string]config.TasksConfig{
+		"in-process": {Workers: 2, QueueSize: 10},
+		// An unreachable NATS server leaves tasks on the in-process pool
+		"nats fallback": {Workers: 2, QueueSize: 10, Backend: "nats", NATSURL: "nats://127.0.0.1:1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			queue := bootstrap.NewTaskQueue(tasks)
+			ran := make(chan string, 1)
+			queue.Start(func(ctx context.Context, taskID string) error {
+				ran <- taskID
+				return nil
+			}, zerolog.Nop())
+			defer func() { _ = queue.Close() }()
+
+			require.NoError(t, queue.Dispatcher().Dispatch(context.Background(), "task-1"))
+			select {
+			case id := <-ran:
+				assert.Equal(t, "task-1", id)
+			case <-time.After(5 * time.Second):
+				t.Fatal("task did not run")
+			}
+		})
+	}
+}
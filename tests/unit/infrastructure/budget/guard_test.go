@@ -0,0 +1,227 @@
+package budget_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/budget"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/queue"
+)
+
+const testModel = vo.Model("test-model")
+
+// fakeLLM counts 100 input tokens per request and reports 100 input and 50 output tokens per call
+type fakeLLM struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (f *fakeLLM) CreateMessage(ctx context.Context, request *services.ClaudeRequest) (*services.ClaudeResponse, error) {
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+	return &services.ClaudeResponse{ServedBy: testModel, Usage: &services.ClaudeUsage{InputTokens: 100, OutputTokens: 50}}, nil
+}
+
+func (f *fakeLLM) CreateMessageStream(ctx context.Context, request *services.ClaudeRequest) (<-chan *services.ClaudeStreamEvent, error) {
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+	events := make(chan *services.ClaudeStreamEvent, 3)
+	events <- &services.ClaudeStreamEvent{Type: "message_start", Message: &services.ClaudeResponse{ServedBy: testModel},
+		Usage: &services.ClaudeUsage{InputTokens: 100}}
+	events <- &services.ClaudeStreamEvent{Type: "message_delta", Usage: &services.ClaudeUsage{OutputTokens: 50}}
+	events <- &services.ClaudeStreamEvent{Type: "message_stop"}
+	close(events)
+	return events, nil
+}
+
+func (f *fakeLLM) CountTokens(ctx context.Context, request *services.ClaudeRequest) (int, error) {
+	return 100, nil
+}
+
+func (f *fakeLLM) ValidateRequest(request *services.ClaudeRequest) error { return nil }
+
+func (f *fakeLLM) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+// recordingNotifier records the types of the events it is sent
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, b *entities.LLMBudget, event *budget.Event) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.events = append(n.events, event.Type)
+}
+
+func (n *recordingNotifier) types() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.events...)
+}
+
+// failingCounter is a counter whose backend is down
+type failingCounter struct{}
+
+func (failingCounter) Add(ctx context.Context, key string, delta entities.BudgetUsage, expiresAt time.Time) (entities.BudgetUsage, error) {
+	return entities.BudgetUsage{}, errors.New("connection refused")
+}
+
+func (failingCounter) Get(ctx context.Context, key string) (entities.BudgetUsage, error) {
+	return entities.BudgetUsage{}, errors.New("connection refused")
+}
+
+func (failingCounter) MarkOnce(ctx context.Context, key string, expiresAt time.Time) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+// request asks for at most 200 output tokens, so each call reserves 300 tokens
+func request() *services.ClaudeRequest {
+	return &services.ClaudeRequest{Model: testModel, MaxTokens: 200}
+}
+
+func acme() context.Context {
+	return entities.WithUsageScope(context.Background(), entities.UsageScope{OrganizationID: "acme"})
+}
+
+func newGuard(t *testing.T, counter budget.Counter, budgets ...*entities.LLMBudget) (*budget.Guard, *fakeLLM, *recordingNotifier) {
+	t.Helper()
+	repo := persistence.NewInMemoryLLMBudgetRepository()
+	for _, b := range budgets {
+		require.NoError(t, repo.Save(context.Background(), b))
+	}
+	prices := vo.PriceTable{Prices: map[vo.Model]vo.ModelPrice{testModel: {Input: 1000, Output: 2000}}}
+	llm := &fakeLLM{}
+	notifier := &recordingNotifier{}
+	return budget.NewGuard(llm, repo, counter, prices, notifier, budget.Options{}, zerolog.Nop()), llm, notifier
+}
+
+func tokenBudget(limit int64, hard bool) *entities.LLMBudget {
+	b := entities.NewLLMBudget("acme", entities.BudgetDaily)
+	b.TokenLimit = limit
+	b.WarnAt = 0.5
+	b.HardLimit = hard
+	return b
+}
+
+func TestGuard_RejectsCallsOverHardBudget(t *testing.T) {
+	b := tokenBudget(500, true)
+	guard, llm, notifier := newGuard(t, budget.NewMemoryCounter(), b)
+
+	for i := 0; i < 2; i++ {
+		_, err := guard.CreateMessage(acme(), request())
+		require.NoError(t, err)
+	}
+	used, err := guard.Usage(context.Background(), b)
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), used.Tokens, "reservations are reconciled with the reported usage")
+	assert.Equal(t, []string{queue.EventTypeBudgetWarning}, notifier.types())
+
+	// 300 used and 300 reserved exceeds 500
+	for i := 0; i < 2; i++ {
+		_, err = guard.CreateMessage(acme(), request())
+		var exceeded *entities.BudgetExceededError
+		require.ErrorAs(t, err, &exceeded)
+		assert.ErrorIs(t, err, entities.ErrBudgetExceeded)
+		assert.Equal(t, entities.BudgetLimitTokens, exceeded.Limit)
+		assert.Equal(t, float64(300), exceeded.Used)
+		assert.Equal(t, float64(300), exceeded.Requested)
+		assert.Equal(t, float64(500), exceeded.Max)
+	}
+	assert.Equal(t, 2, llm.callCount(), "rejected calls do not reach the provider")
+	assert.Equal(t, []string{queue.EventTypeBudgetWarning, queue.EventTypeBudgetExceeded}, notifier.types(),
+		"each event is sent once per period")
+
+	used, err = guard.Usage(context.Background(), b)
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), used.Tokens, "rejected reservations are released")
+}
+
+func TestGuard_CostBudget(t *testing.T) {
+	b := entities.NewLLMBudget("acme", entities.BudgetMonthly)
+	b.CostLimitUSD = 0.5
+	guard, _, _ := newGuard(t, budget.NewMemoryCounter(), b)
+
+	// Each call costs 100*1000/1M + 50*2000/1M = $0.20, and reserves up to $0.50
+	_, err := guard.CreateMessage(acme(), request())
+	require.NoError(t, err)
+	used, err := guard.Usage(context.Background(), b)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.2, used.CostUSD, 1e-9)
+
+	_, err = guard.CreateMessage(acme(), request())
+	var exceeded *entities.BudgetExceededError
+	require.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, entities.BudgetLimitCost, exceeded.Limit)
+}
+
+func TestGuard_SoftBudgetOnlyNotifies(t *testing.T) {
+	guard, llm, notifier := newGuard(t, budget.NewMemoryCounter(), tokenBudget(200, false))
+
+	for i := 0; i < 3; i++ {
+		_, err := guard.CreateMessage(acme(), request())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, llm.callCount())
+	assert.Equal(t, []string{queue.EventTypeBudgetWarning, queue.EventTypeBudgetExceeded}, notifier.types())
+}
+
+func TestGuard_SettlesStreams(t *testing.T) {
+	b := tokenBudget(10000, true)
+	guard, _, _ := newGuard(t, budget.NewMemoryCounter(), b)
+
+	events, err := guard.CreateMessageStream(acme(), request())
+	require.NoError(t, err)
+	count := 0
+	for range events {
+		count++
+	}
+	assert.Equal(t, 3, count)
+
+	assert.Eventually(t, func() bool {
+		used, err := guard.Usage(context.Background(), b)
+		return err == nil && used.Tokens == 150
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestGuard_PassesThroughWithoutBudget(t *testing.T) {
+	guard, llm, _ := newGuard(t, budget.NewMemoryCounter(), tokenBudget(1, true))
+
+	_, err := guard.CreateMessage(context.Background(), request())
+	require.NoError(t, err, "calls outside an organization are not budgeted")
+	other := entities.WithUsageScope(context.Background(), entities.UsageScope{OrganizationID: "globex"})
+	_, err = guard.CreateMessage(other, request())
+	require.NoError(t, err, "organizations without budgets are not limited")
+
+	disabled := tokenBudget(1, true)
+	disabled.Enabled = false
+	guard, _, _ = newGuard(t, budget.NewMemoryCounter(), disabled)
+	_, err = guard.CreateMessage(acme(), request())
+	require.NoError(t, err)
+	assert.Equal(t, 2, llm.callCount())
+}
+
+func TestGuard_FailsOpenWhenCounterIsDown(t *testing.T) {
+	guard, llm, _ := newGuard(t, failingCounter{}, tokenBudget(1, true))
+
+	_, err := guard.CreateMessage(acme(), request())
+	require.NoError(t, err)
+	assert.Equal(t, 1, llm.callCount())
+}
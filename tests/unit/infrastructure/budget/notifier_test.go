@@ -0,0 +1,85 @@
+package budget_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/budget"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/queue"
+)
+
+// recordingQueue records published tasks
+type recordingQueue struct {
+	mu    sync.Mutex
+	tasks []*queue.Task
+}
+
+func (q *recordingQueue) RegisterHandler(taskType string, handler queue.TaskHandler) {}
+
+func (q *recordingQueue) Publish(ctx context.Context, task *queue.Task) (string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.tasks = append(q.tasks, task)
+	return task.ID, nil
+}
+
+func decode(t *testing.T, task *queue.Task, dest interface{}) {
+	t.Helper()
+	data, err := json.Marshal(task.Payload)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, dest))
+}
+
+func TestTaskNotifier_PublishesDeliveries(t *testing.T) {
+	q := &recordingQueue{}
+	notifier := budget.NewTaskNotifier(q, budget.NotifierOptions{
+		WebhookURL: "https://hooks.example.com/default",
+		Emails:     []string{"ops@example.com"},
+		SendEmail:  true,
+		MaxRetries: 2,
+	}, zerolog.Nop())
+
+	b := tokenBudget(1000, true)
+	b.WebhookURL = "https://hooks.example.com/acme"
+	notifier.Notify(context.Background(), b, &budget.Event{
+		Type:           queue.EventTypeBudgetExceeded,
+		OrganizationID: "acme",
+		Period:         "daily",
+		Tokens:         1200,
+		TokenLimit:     1000,
+		Used:           1.2,
+		HardLimit:      true,
+	})
+
+	require.Len(t, q.tasks, 2)
+	var webhook queue.WebhookDeliveryPayload
+	decode(t, q.tasks[0], &webhook)
+	assert.Equal(t, queue.TaskTypeWebhookDelivery, q.tasks[0].Type)
+	assert.Equal(t, "https://hooks.example.com/acme", webhook.URL, "the budget's webhook wins")
+	assert.Equal(t, queue.EventTypeBudgetExceeded, webhook.Headers["X-TelemetryFlow-Event"])
+	assert.Equal(t, "acme", webhook.Body["organizationId"])
+	assert.Equal(t, 2, webhook.MaxRetries)
+
+	var email queue.EmailNotificationPayload
+	decode(t, q.tasks[1], &email)
+	assert.Equal(t, []string{"ops@example.com"}, email.To)
+	assert.Contains(t, email.Subject, "acme")
+	assert.Contains(t, email.Body, "Tokens: 1200 of 1000")
+	assert.Contains(t, email.Body, "rejected until the budget resets")
+}
+
+func TestTaskNotifier_WithoutDestinations(t *testing.T) {
+	q := &recordingQueue{}
+	notifier := budget.NewTaskNotifier(q, budget.NotifierOptions{Emails: []string{"ops@example.com"}}, zerolog.Nop())
+
+	notifier.Notify(context.Background(), entities.NewLLMBudget("acme", entities.BudgetDaily),
+		&budget.Event{Type: queue.EventTypeBudgetWarning, OrganizationID: "acme"})
+	assert.Empty(t, q.tasks, "no webhook is configured and email is not enabled")
+}
@@ -0,0 +1,66 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/cache"
+)
+
+func TestMemoryCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemoryCache(2, 0)
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1")))
+	require.NoError(t, c.Set(ctx, "b", []byte("2")))
+	_, err := c.Get(ctx, "a") // a becomes most recently used
+	require.NoError(t, err)
+	require.NoError(t, c.Set(ctx, "c", []byte("3")))
+
+	assert.Equal(t, 2, c.Len())
+	_, err = c.Get(ctx, "b")
+	assert.ErrorIs(t, err, cache.ErrCacheMiss)
+	value, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+}
+
+func TestMemoryCache_TTL(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemoryCache(10, 0)
+
+	require.NoError(t, c.SetWithTTL(ctx, "short", []byte("x"), 20*time.Millisecond))
+	require.NoError(t, c.Set(ctx, "forever", []byte("y")))
+	time.Sleep(40 * time.Millisecond)
+
+	_, err := c.Get(ctx, "short")
+	assert.ErrorIs(t, err, cache.ErrCacheMiss)
+	_, err = c.Get(ctx, "forever")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestMemoryCache_GetOrSetJSON(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemoryCache(10, time.Minute)
+	calls := 0
+	fn := func() (interface{}, error) {
+		calls++
+		return map[string]int{"n": 1}, nil
+	}
+
+	var first, second map[string]int
+	require.NoError(t, c.GetOrSetJSON(ctx, "k", time.Minute, &first, fn))
+	require.NoError(t, c.GetOrSetJSON(ctx, "k", time.Minute, &second, fn))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, map[string]int{"n": 1}, second)
+
+	require.NoError(t, c.Delete(ctx, "k"))
+	assert.Equal(t, 0, c.Len())
+	_, err := c.Get(ctx, "")
+	assert.ErrorIs(t, err, cache.ErrInvalidKey)
+}
@@ -0,0 +1,186 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/cache"
+)
+
+// countingRun returns a tool run func that reports how often it was called
+func countingRun(result func() *entities.ToolResult) (func(context.Context) (*entities.ToolResult, error), *int) {
+	calls := 0
+	return func(context.Context) (*entities.ToolResult, error) {
+		calls++
+		return result(), nil
+	}, &calls
+}
+
+// failingStore is a store whose backend is unreachable
+type failingStore struct{}
+
+func (failingStore) GetOrSetJSON(context.Context, string, time.Duration, interface{}, func() (interface{}, error)) error {
+	return errors.New("connection refused")
+}
+
+func (failingStore) SetJSONWithTTL(context.Context, string, interface{}, time.Duration) error {
+	return errors.New("connection refused")
+}
+
+func TestToolResultCache_HitAndMiss(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewToolResultCache(cache.NewMemoryCache(10, 0), map[string]cache.ToolCachePolicy{
+		"query": {TTL: time.Minute},
+	}, nil)
+	run, calls := countingRun(func() *entities.ToolResult { return entities.NewTextToolResult("rows") })
+	args := map[string]interface{}{"sql": "SELECT 1", "limit": float64(10)}
+
+	first, err := c.Execute(ctx, "session-1", "query", args, false, run)
+	require.NoError(t, err)
+	assert.Equal(t, cache.ToolCacheMiss, first.Meta[cache.ToolCacheMetaKey])
+
+	second, err := c.Execute(ctx, "session-1", "query", map[string]interface{}{"limit": float64(10), "sql": "SELECT 1"}, false, run)
+	require.NoError(t, err)
+	assert.Equal(t, cache.ToolCacheHit, second.Meta[cache.ToolCacheMetaKey])
+	assert.Equal(t, "rows", second.Content[0].Text)
+	assert.Equal(t, 1, *calls)
+
+	_, err = c.Execute(ctx, "session-1", "query", map[string]interface{}{"sql": "SELECT 2"}, false, run)
+	require.NoError(t, err)
+	assert.Equal(t, 2, *calls, "different arguments miss")
+}
+
+func TestToolResultCache_SessionScope(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewToolResultCache(cache.NewMemoryCache(10, 0), map[string]cache.ToolCachePolicy{
+		"query":  {TTL: time.Minute},
+		"shared": {TTL: time.Minute, Shared: true},
+	}, nil)
+	run, calls := countingRun(func() *entities.ToolResult { return entities.NewTextToolResult("rows") })
+	args := map[string]interface{}{"sql": "SELECT 1"}
+
+	_, err := c.Execute(ctx, "session-1", "query", args, false, run)
+	require.NoError(t, err)
+	other, err := c.Execute(ctx, "session-2", "query", args, false, run)
+	require.NoError(t, err)
+	assert.Equal(t, cache.ToolCacheMiss, other.Meta[cache.ToolCacheMetaKey], "sessions do not see each other's entries")
+	assert.Equal(t, 2, *calls)
+
+	_, err = c.Execute(ctx, "session-1", "shared", args, false, run)
+	require.NoError(t, err)
+	shared, err := c.Execute(ctx, "session-2", "shared", args, false, run)
+	require.NoError(t, err)
+	assert.Equal(t, cache.ToolCacheHit, shared.Meta[cache.ToolCacheMetaKey])
+	assert.Equal(t, 3, *calls)
+}
+
+func TestToolResultCache_Bypass(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewToolResultCache(cache.NewMemoryCache(10, 0), map[string]cache.ToolCachePolicy{
+		"query": {TTL: time.Minute},
+	}, nil)
+	version := 0
+	run, calls := countingRun(func() *entities.ToolResult {
+		version++
+		return entities.NewTextToolResult(strings.Repeat("v", version))
+	})
+
+	_, err := c.Execute(ctx, "session-1", "query", nil, false, run)
+	require.NoError(t, err)
+	refreshed, err := c.Execute(ctx, "session-1", "query", nil, true, run)
+	require.NoError(t, err)
+	assert.Equal(t, cache.ToolCacheBypass, refreshed.Meta[cache.ToolCacheMetaKey])
+
+	cached, err := c.Execute(ctx, "session-1", "query", nil, false, run)
+	require.NoError(t, err)
+	assert.Equal(t, "vv", cached.Content[0].Text, "bypass refreshes the entry")
+	assert.Equal(t, 2, *calls)
+}
+
+func TestToolResultCache_NotCached(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewToolResultCache(cache.NewMemoryCache(10, 0), map[string]cache.ToolCachePolicy{
+		"query": {TTL: time.Minute, MaxEntrySize: 64},
+	}, nil)
+
+	tests := []struct {
+		name   string
+		tool   string
+		result func() *entities.ToolResult
+	}{
+		{"no policy", "other", func() *entities.ToolResult { return entities.NewTextToolResult("ok") }},
+		{"error result", "query", func() *entities.ToolResult { return entities.NewErrorToolResult(errors.New("boom")) }},
+		{"oversized result", "query", func() *entities.ToolResult { return entities.NewTextToolResult(strings.Repeat("x", 100)) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run, calls := countingRun(tt.result)
+			_, err := c.Execute(ctx, "session-1", tt.tool, nil, false, run)
+			require.NoError(t, err)
+			_, err = c.Execute(ctx, "session-1", tt.tool, nil, false, run)
+			require.NoError(t, err)
+			assert.Equal(t, 2, *calls)
+		})
+	}
+
+	t.Run("run error", func(t *testing.T) {
+		_, err := c.Execute(ctx, "session-1", "query", nil, false, func(context.Context) (*entities.ToolResult, error) {
+			return nil, context.DeadlineExceeded
+		})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestToolResultCache_StoreUnavailable(t *testing.T) {
+	c := cache.NewToolResultCache(failingStore{}, map[string]cache.ToolCachePolicy{
+		"query": {TTL: time.Minute},
+	}, nil)
+	run, calls := countingRun(func() *entities.ToolResult { return entities.NewTextToolResult("ok") })
+
+	result, err := c.Execute(context.Background(), "session-1", "query", nil, false, run)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result.Content[0].Text)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestToolResultKey(t *testing.T) {
+	policy := cache.ToolCachePolicy{
+		KeyArgs:    []string{"organization_id", "time_range_from"},
+		TimeBucket: 5 * time.Minute,
+	}
+
+	key := func(args map[string]interface{}) string {
+		k, err := cache.ToolResultKey("", "collect_telemetry_context", args, policy)
+		require.NoError(t, err)
+		return k
+	}
+
+	base := key(map[string]interface{}{"organization_id": "org-1", "time_range_from": "2026-01-01T10:01:00Z"})
+	assert.True(t, strings.HasPrefix(base, "tool-result:collect_telemetry_context:"))
+	assert.Equal(t, base, key(map[string]interface{}{
+		"organization_id": "org-1",
+		"time_range_from": "2026-01-01T12:03:59+02:00",
+		"max_items":       float64(5),
+	}), "same bucket, other zone and ignored arguments share a key")
+	assert.NotEqual(t, base, key(map[string]interface{}{"organization_id": "org-1", "time_range_from": "2026-01-01T10:06:00Z"}))
+	assert.NotEqual(t, base, key(map[string]interface{}{"organization_id": "org-2", "time_range_from": "2026-01-01T10:01:00Z"}))
+
+	scoped, err := cache.ToolResultKey("session-1", "collect_telemetry_context", map[string]interface{}{"organization_id": "org-1", "time_range_from": "2026-01-01T10:01:00Z"}, policy)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(scoped, "tool-result:session-1:collect_telemetry_context:"))
+	assert.NotEqual(t, base, scoped)
+
+	all := cache.ToolCachePolicy{}
+	withNull, err := cache.ToolResultKey("", "t", map[string]interface{}{"a": "x", "b": nil}, all)
+	require.NoError(t, err)
+	without, err := cache.ToolResultKey("", "t", map[string]interface{}{"a": "x"}, all)
+	require.NoError(t, err)
+	assert.Equal(t, without, withNull, "null arguments are ignored")
+}
@@ -0,0 +1,156 @@
+package claude_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/claude"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+)
+
+// capturedRequest is the part of a Messages API request that carries cache breakpoints
+type capturedRequest struct {
+	System   []map[string]interface{} `json:"system"`
+	Tools    []map[string]interface{} `json:"tools"`
+	Messages []struct {
+		Content []map[string]interface{} `json:"content"`
+	} `json:"messages"`
+	raw string
+}
+
+func (r *capturedRequest) breakpoints() int {
+	return strings.Count(r.raw, `"cache_control"`)
+}
+
+func cached(part map[string]interface{}) bool {
+	_, ok := part["cache_control"]
+	return ok
+}
+
+// sendCaching sends request through a client with the given prompt caching settings and returns
+// the request the API received
+func sendCaching(t *testing.T, caching config.PromptCachingConfig, request *services.ClaudeRequest) *capturedRequest {
+	t.Helper()
+	captured := &capturedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		captured.raw = string(body)
+		assert.NoError(t, json.Unmarshal(body, captured))
+		writeMessageResponse(w, "msg_cache", "end_turn", []map[string]interface{}{{"type": "text", "text": "ok"}}, 10, 5)
+	}))
+	defer server.Close()
+
+	client, err := claude.NewClient(&config.ClaudeConfig{
+		APIKey:        "test-api-key",
+		BaseURL:       server.URL,
+		MaxTokens:     4096,
+		PromptCaching: caching,
+	}, zerolog.Nop())
+	require.NoError(t, err)
+	_, err = client.CreateMessage(context.Background(), request)
+	require.NoError(t, err)
+	return captured
+}
+
+func text(s string) entities.ContentBlock {
+	return entities.ContentBlock{Type: vo.ContentTypeText, Text: s}
+}
+
+// cachingRequest is a conversation with a long system prompt, tools, a telemetry context block
+// and a follow-up question
+func cachingRequest() *services.ClaudeRequest {
+	systemPrompt, _ := vo.NewSystemPrompt(strings.Repeat("You are a site reliability analyst. ", 200))
+	contextBlock := text(strings.Repeat(`{"service":"checkout","p99_ms":840}`, 200))
+	contextBlock.CacheBreakpoint = true
+	return &services.ClaudeRequest{
+		Model:        vo.ModelClaudeSonnet46,
+		MaxTokens:    1024,
+		SystemPrompt: systemPrompt,
+		Tools: []services.ClaudeTool{
+			{Name: "query_metrics", Description: strings.Repeat("Query metrics by name and labels. ", 150)},
+			{Name: "query_logs", Description: "Search logs"},
+		},
+		Messages: []services.ClaudeMessage{
+			{Role: vo.RoleUser, Content: []entities.ContentBlock{contextBlock, text("Why is checkout slow?")}},
+			{Role: vo.RoleAssistant, Content: []entities.ContentBlock{text("Its database calls are slow.")}},
+			{Role: vo.RoleUser, Content: []entities.ContentBlock{text("Which queries?")}},
+		},
+	}
+}
+
+func TestPromptCaching_PlacesBreakpoints(t *testing.T) {
+	got := sendCaching(t, config.PromptCachingConfig{Enabled: true}, cachingRequest())
+
+	require.Len(t, got.System, 1)
+	assert.True(t, cached(got.System[0]), "system prompt")
+	require.Len(t, got.Tools, 2)
+	assert.False(t, cached(got.Tools[0]))
+	assert.True(t, cached(got.Tools[1]), "last tool definition")
+	require.Len(t, got.Messages, 3)
+	assert.True(t, cached(got.Messages[0].Content[0]), "marked context block")
+	assert.False(t, cached(got.Messages[0].Content[1]))
+	assert.True(t, cached(got.Messages[2].Content[0]), "end of the conversation")
+	assert.Equal(t, 4, got.breakpoints())
+}
+
+func TestPromptCaching_AtMostFourBreakpoints(t *testing.T) {
+	request := cachingRequest()
+	var blocks []entities.ContentBlock
+	for i := 0; i < 5; i++ {
+		block := text(strings.Repeat("context ", 1000))
+		block.CacheBreakpoint = true
+		blocks = append(blocks, block)
+	}
+	request.Messages[0].Content = append(blocks, request.Messages[0].Content...)
+
+	got := sendCaching(t, config.PromptCachingConfig{Enabled: true}, request)
+	assert.Equal(t, 4, got.breakpoints())
+	assert.True(t, cached(got.System[0]))
+	assert.True(t, cached(got.Messages[2].Content[0]))
+	assert.True(t, cached(got.Messages[0].Content[5]), "the newest marked blocks win")
+	assert.True(t, cached(got.Messages[0].Content[4]))
+	assert.False(t, cached(got.Tools[1]), "tools come last")
+}
+
+func TestPromptCaching_SkipsShortPrefixes(t *testing.T) {
+	systemPrompt, _ := vo.NewSystemPrompt("You are terse.")
+	request := makeBasicRequest()
+	request.SystemPrompt = systemPrompt
+	request.Messages = append(request.Messages,
+		services.ClaudeMessage{Role: vo.RoleAssistant, Content: []entities.ContentBlock{text("Hi")}},
+		services.ClaudeMessage{Role: vo.RoleUser, Content: []entities.ContentBlock{text("Bye")}})
+
+	got := sendCaching(t, config.PromptCachingConfig{Enabled: true}, request)
+	assert.Zero(t, got.breakpoints())
+
+	// A lower minimum caches short prefixes too
+	got = sendCaching(t, config.PromptCachingConfig{Enabled: true, MinTokens: 1}, request)
+	assert.True(t, cached(got.System[0]))
+	assert.True(t, cached(got.Messages[2].Content[0]))
+}
+
+func TestPromptCaching_Disabled(t *testing.T) {
+	got := sendCaching(t, config.PromptCachingConfig{}, cachingRequest())
+	assert.Zero(t, got.breakpoints())
+}
+
+func TestPromptCaching_SingleMessageCachesOnlyPrompt(t *testing.T) {
+	request := cachingRequest()
+	request.Messages = request.Messages[2:]
+
+	got := sendCaching(t, config.PromptCachingConfig{Enabled: true}, request)
+	assert.True(t, cached(got.System[0]))
+	assert.False(t, cached(got.Messages[0].Content[0]), "a one-off question is not cached")
+}
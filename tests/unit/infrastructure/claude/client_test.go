@@ -555,6 +555,53 @@ func TestCreateMessage_APIError(t *testing.T) {
 	assert.ErrorIs(t, err, claude.ErrAPIError)
 }
 
+func TestCreateMessage_RetriesOverloaded(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		switch calls {
+		case 1:
+			w.WriteHeader(529)
+			_, _ = w.Write([]byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`))
+		case 2:
+			w.Header().Set("Retry-After-Ms", "20")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`))
+		default:
+			writeMessageResponse(w, "msg_retry", "end_turn", []map[string]interface{}{{"type": "text", "text": "ok"}}, 5, 1)
+		}
+	}))
+	defer server.Close()
+
+	client, err := claude.NewClient(&config.ClaudeConfig{
+		APIKey:     "test-api-key",
+		BaseURL:    server.URL,
+		MaxRetries: 2,
+		RetryDelay: time.Millisecond,
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	start := time.Now()
+	resp, err := client.CreateMessage(context.Background(), makeBasicRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "msg_retry", resp.ID)
+	assert.Equal(t, 3, calls)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond, "retry-after is honored")
+
+	// Validation errors are never retried
+	calls = 0
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"invalid_request_error","message":"bad request"}}`))
+	})
+	_, err = client.CreateMessage(context.Background(), makeBasicRequest())
+	require.ErrorIs(t, err, claude.ErrAPIError)
+	assert.Equal(t, 1, calls)
+}
+
 func TestCreateMessage_WithAllOptions(t *testing.T) {
 	client, server := newTestClientWithServer(func(w http.ResponseWriter, r *http.Request) {
 		body, _ := io.ReadAll(r.Body)
@@ -618,6 +665,93 @@ func TestCreateMessage_WithAllOptions(t *testing.T) {
 	assert.Equal(t, "msg_opts", resp.ID)
 }
 
+func TestCreateMessage_Thinking(t *testing.T) {
+	client, server := newTestClientWithServer(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var parsed map[string]interface{}
+		_ = json.Unmarshal(body, &parsed)
+
+		assert.Equal(t, map[string]interface{}{"type": "enabled", "budget_tokens": float64(2048)}, parsed["thinking"])
+		assert.Equal(t, float64(1024+2048), parsed["max_tokens"])
+		assert.NotContains(t, parsed, "temperature", "thinking rejects sampling settings")
+
+		// Signed thinking and redacted thinking are sent back ahead of the tool call
+		messages := parsed["messages"].([]interface{})
+		content := messages[1].(map[string]interface{})["content"].([]interface{})
+		require.Len(t, content, 3)
+		assert.Equal(t, map[string]interface{}{"type": "thinking", "thinking": "Check metrics.", "signature": "sig"}, content[0])
+		assert.Equal(t, map[string]interface{}{"type": "redacted_thinking", "data": "opaque"}, content[1])
+		assert.Equal(t, "tool_use", content[2].(map[string]interface{})["type"])
+
+		writeMessageResponse(w, "msg_think", "end_turn", []map[string]interface{}{
+			{"type": "thinking", "thinking": "p99 is high.", "signature": "sig2"},
+			{"type": "redacted_thinking", "data": "opaque2"},
+			{"type": "text", "text": "Scale out."},
+		}, 20, 40)
+	})
+	defer server.Close()
+
+	req := makeBasicRequest()
+	req.Temperature = 0.5
+	req.Reasoning = &vo.Reasoning{Effort: vo.ReasoningEffortLow}
+	req.Messages = append(req.Messages,
+		services.ClaudeMessage{Role: vo.RoleAssistant, Content: []entities.ContentBlock{
+			{Type: vo.ContentTypeThinking, Thinking: "Check metrics.", Signature: "sig"},
+			{Type: vo.ContentTypeThinking, Thinking: "Unsigned thoughts from another provider."},
+			{Type: vo.ContentTypeRedactedThinking, Data: "opaque"},
+			{Type: vo.ContentTypeToolUse, ID: "toolu_1", Name: "query_metrics", Input: map[string]interface{}{}},
+		}},
+		services.ClaudeMessage{Role: vo.RoleUser, Content: []entities.ContentBlock{
+			{Type: vo.ContentTypeToolResult, ToolUseID: "toolu_1", Content: "p99 2.3s"},
+		}},
+	)
+
+	resp, err := client.CreateMessage(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, resp.Content, 3)
+	assert.Equal(t, entities.ContentBlock{Type: vo.ContentTypeThinking, Thinking: "p99 is high.", Signature: "sig2"}, resp.Content[0])
+	assert.Equal(t, entities.ContentBlock{Type: vo.ContentTypeRedactedThinking, Data: "opaque2"}, resp.Content[1])
+	assert.Equal(t, "Scale out.", resp.Content[2].Text)
+}
+
+func TestCreateMessage_Media(t *testing.T) {
+	client, server := newTestClientWithServer(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var parsed map[string]interface{}
+		_ = json.Unmarshal(body, &parsed)
+
+		messages := parsed["messages"].([]interface{})
+		content := messages[0].(map[string]interface{})["content"].([]interface{})
+		require.Len(t, content, 3)
+		assert.Equal(t, map[string]interface{}{
+			"type":   "image",
+			"source": map[string]interface{}{"type": "base64", "media_type": "image/png", "data": "iVBORw=="},
+		}, content[0])
+		assert.Equal(t, map[string]interface{}{
+			"type":   "document",
+			"title":  "report.pdf",
+			"source": map[string]interface{}{"type": "base64", "media_type": "application/pdf", "data": "JVBERi0xLjc="},
+		}, content[1])
+		assert.Equal(t, "text", content[2].(map[string]interface{})["type"])
+
+		writeMessageResponse(w, "msg_media", "end_turn", []map[string]interface{}{
+			{"type": "text", "text": "Latency peaks at 14:00."},
+		}, 3000, 10)
+	})
+	defer server.Close()
+
+	image, err := entities.NewMediaBlock(vo.MimeTypePNG, []byte("\x89PNG"), "")
+	require.NoError(t, err)
+	document, err := entities.NewMediaBlock(vo.MimeTypePDF, []byte("%PDF-1.7"), "report.pdf")
+	require.NoError(t, err)
+	req := makeBasicRequest()
+	req.Messages[0].Content = append([]entities.ContentBlock{image, document}, req.Messages[0].Content...)
+
+	resp, err := client.CreateMessage(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "Latency peaks at 14:00.", resp.Content[0].Text)
+}
+
 func TestCreateMessage_MultipleContentTypes(t *testing.T) {
 	client, server := newTestClientWithServer(func(w http.ResponseWriter, r *http.Request) {
 		writeMessageResponse(w, "msg_multi", "tool_use", []map[string]interface{}{
@@ -917,6 +1051,50 @@ drain:
 	}
 }
 
+func TestCreateMessageStream_StreamErrorAfterCallerLeft(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	client, server := newTestClientWithServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+
+		// message_start and 99 deltas fill the event buffer before the connection drops
+		writeSSE(w, "message_start", `{"type":"message_start","message":{"id":"msg_abort","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-20250514","stop_reason":"","stop_sequence":"","usage":{"input_tokens":10,"output_tokens":0,"cache_creation_input_tokens":0,"cache_read_input_tokens":0}}}`)
+		for i := 0; i < 99; i++ {
+			writeSSE(w, "content_block_delta", fmt.Sprintf(`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"word%d "}}`, i))
+		}
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		panic(http.ErrAbortHandler)
+	})
+	defer server.Close()
+
+	eventChan, err := client.CreateMessageStream(ctx, makeBasicRequest())
+	require.NoError(t, err)
+
+	// The caller stops reading; the stream error must not block the goroutine once ctx is done
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+	time.Sleep(100 * time.Millisecond)
+
+	var count int
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-eventChan:
+			if !ok {
+				assert.Equal(t, 100, count, "no error is queued after the caller left")
+				return
+			}
+			assert.Nil(t, event.Error)
+			count++
+		case <-timeout:
+			t.Fatal("stream goroutine did not exit")
+		}
+	}
+}
+
 func TestCreateMessageStream_ServerError(t *testing.T) {
 	client, server := newTestClientWithServer(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/event-stream")
@@ -1097,6 +1275,7 @@ func TestCreateMessage_WithRetriesConfigured(t *testing.T) {
 	require.Error(t, err)
 	assert.Nil(t, resp)
 	assert.ErrorIs(t, err, claude.ErrAPIError)
+	assert.ErrorIs(t, err, claude.ErrMaxRetriesExceeded)
 }
 
 func TestCreateMessage_EmptyToolResultContent(t *testing.T) {
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -39,6 +40,18 @@ func TestConfig_Validate_MissingAPIKey(t *testing.T) {
 	assert.Contains(t, err.Error(), "claude.api_key")
 }
 
+func TestConfig_Validate_LocalProviderOnly(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Claude.APIKey = ""
+	cfg.Providers = config.ProvidersConfig{"local": {}}
+	require.Error(t, cfg.Validate(), "local needs a base URL to be enabled")
+
+	cfg.Providers = config.ProvidersConfig{"ollama": {}}
+	assert.NoError(t, cfg.Validate())
+	assert.True(t, cfg.Providers.IsEnabled("ollama"))
+	assert.False(t, cfg.Providers.IsEnabled("openai"))
+}
+
 func TestConfig_Validate_InvalidPort(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfg.Claude.APIKey = "test-key"
@@ -57,6 +70,18 @@ func TestConfig_Validate_InvalidTransport(t *testing.T) {
 	assert.Contains(t, err.Error(), "server.transport")
 }
 
+func TestConfig_Validate_RequireAPIKeyWithoutKeys(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Claude.APIKey = "test-key"
+	cfg.Security.RequireAPIKey = true
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "security.allowed_api_keys")
+
+	cfg.Security.AllowedAPIKeys = []string{"tfk-analyst"}
+	assert.NoError(t, cfg.Validate())
+}
+
 func TestConfig_Validate_InvalidMaxTokens(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfg.Claude.APIKey = "test-key"
@@ -142,6 +167,146 @@ func TestConfig_Load(t *testing.T) {
 	})
 }
 
+func TestConfig_Load_Providers(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-test")
+	t.Setenv("OPENAI_API_KEY", "sk-openai-env")
+	t.Setenv("TELEMETRYFLOW_MCP_DEEPSEEK_BASE_URL", "https://deepseek.internal/v1")
+	t.Setenv("GOOGLE_API_KEY", "google-env")
+	t.Setenv("TELEMETRYFLOW_MCP_OLLAMA_BASE_URL", "http://gpu-box:11434")
+
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	content := []byte("providers:\n  mistral:\n    api_key: mistral-from-file\n  deepseek:\n    api_key: ds-from-file\n")
+	require.NoError(t, os.WriteFile(cfgPath, content, 0644))
+
+	cfg, err := config.Load(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, "sk-openai-env", cfg.Providers["openai"].APIKey)
+	assert.Equal(t, "google-env", cfg.Providers["google"].APIKey)
+	assert.Equal(t, "http://gpu-box:11434", cfg.Providers["ollama"].BaseURL)
+	assert.NotContains(t, cfg.Providers, "local")
+	assert.Equal(t, "mistral-from-file", cfg.Providers["mistral"].APIKey)
+	assert.Equal(t, "ds-from-file", cfg.Providers["deepseek"].APIKey)
+	assert.Equal(t, "https://deepseek.internal/v1", cfg.Providers["deepseek"].BaseURL)
+
+	cfg.Providers["xai"] = config.ProviderConfig{APIKey: "k", BaseURL: "not a url"}
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "providers.xai.base_url")
+
+	cfg.Providers["xai"] = config.ProviderConfig{APIKey: "k"}
+	cfg.Providers["local"] = config.ProviderConfig{BaseURL: "http://localhost:8000/v1", ToolCalling: "sometimes"}
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "providers.local.tool_calling")
+}
+
+func TestConfig_Load_ProviderSettings(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "openai.key")
+	require.NoError(t, os.WriteFile(keyPath, []byte("sk-openai-from-secret\n"), 0600))
+	claudeKeyPath := filepath.Join(dir, "anthropic.key")
+	require.NoError(t, os.WriteFile(claudeKeyPath, []byte("sk-ant-from-secret"), 0600))
+
+	t.Setenv("TELEMETRYFLOW_MCP_CLAUDE_API_KEY_FILE", claudeKeyPath)
+	t.Setenv("TELEMETRYFLOW_MCP_OPENAI_API_KEY_FILE", keyPath)
+	t.Setenv("TELEMETRYFLOW_MCP_OPENAI_TIMEOUT", "45s")
+	t.Setenv("TELEMETRYFLOW_MCP_OPENAI_PROJECT", "proj_sre")
+	t.Setenv("TELEMETRYFLOW_MCP_MISTRAL_ENABLED", "false")
+
+	cfgPath := filepath.Join(dir, "config.yaml")
+	content := []byte(`providers:
+  openai:
+    organization: org-telemetryflow
+    default_model: gpt-5.4
+    max_retries: 2
+    retry_delay: 500ms
+  mistral:
+    api_key: mistral-key
+`)
+	require.NoError(t, os.WriteFile(cfgPath, content, 0644))
+
+	cfg, err := config.Load(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, "sk-ant-from-secret", cfg.Claude.APIKey)
+
+	openai := cfg.Providers["openai"]
+	assert.Equal(t, "sk-openai-from-secret", openai.APIKey)
+	assert.Equal(t, "org-telemetryflow", openai.Organization)
+	assert.Equal(t, "proj_sre", openai.Project)
+	assert.Equal(t, "gpt-5.4", openai.DefaultModel)
+	assert.Equal(t, 45*time.Second, openai.Timeout)
+	assert.Equal(t, 2, openai.MaxRetries)
+	assert.Equal(t, 500*time.Millisecond, openai.RetryDelay)
+
+	// A provider with a key can still be switched off
+	assert.False(t, cfg.Providers.IsEnabled("mistral"))
+	assert.Equal(t, []string{"anthropic", "openai"}, cfg.ActiveProviders())
+
+	t.Setenv("TELEMETRYFLOW_MCP_OPENAI_API_KEY_FILE", filepath.Join(dir, "missing.key"))
+	_, err = config.Load(cfgPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "providers.openai.api_key_file")
+}
+
+func TestConfig_Validate_EnabledProviders(t *testing.T) {
+	enabled, disabled := true, false
+
+	cfg := config.DefaultConfig()
+	cfg.Claude.APIKey = "sk-ant"
+	cfg.Providers = config.ProvidersConfig{"openai": {Enabled: &enabled}}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "providers.openai.api_key is required")
+
+	cfg.Providers = config.ProvidersConfig{"openai": {APIKey: "sk-openai", Timeout: -time.Second}}
+	require.Error(t, cfg.Validate())
+
+	// Disabling the only provider leaves nothing to serve requests
+	cfg.Providers = nil
+	cfg.Claude.Enabled = &disabled
+	assert.Empty(t, cfg.ActiveProviders())
+	require.Error(t, cfg.Validate())
+
+	cfg.Claude.Enabled = &enabled
+	cfg.Claude.APIKey = ""
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "claude.enabled")
+}
+
+func TestConfig_Load_Routing(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-test")
+
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	content := []byte(`routing:
+  aliases:
+    analyst-default: [claude-opus-4-7, gemini-2.5-pro, gpt-5.4]
+  attempt_timeout: 20s
+  circuit_breaker:
+    min_requests: 10
+`)
+	require.NoError(t, os.WriteFile(cfgPath, content, 0644))
+
+	cfg, err := config.Load(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"claude-opus-4-7", "gemini-2.5-pro", "gpt-5.4"}, cfg.Routing.Aliases["analyst-default"])
+	assert.Equal(t, 20*time.Second, cfg.Routing.AttemptTimeout)
+	assert.True(t, cfg.Routing.CircuitBreaker.Enabled)
+	assert.Equal(t, 10, cfg.Routing.CircuitBreaker.MinRequests)
+	assert.Equal(t, 0.5, cfg.Routing.CircuitBreaker.ErrorRate)
+
+	cfg.Routing.Aliases["fast"] = []string{"analyst-default"}
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "routing.aliases.fast")
+
+	delete(cfg.Routing.Aliases, "fast")
+	cfg.Routing.CircuitBreaker.ErrorRate = 1.5
+	require.Error(t, cfg.Validate())
+}
+
 func TestConfig_Load_EnvOverrides(t *testing.T) {
 	t.Run("ANTHROPIC_API_KEY sets claude api key", func(t *testing.T) {
 		t.Setenv("ANTHROPIC_API_KEY", "sk-ant-from-env")
@@ -295,3 +460,570 @@ func TestConfig_Validate_InvalidTraceSampleRateLow(t *testing.T) {
 		t.Error("expected error for negative trace sample rate")
 	}
 }
+
+func TestDefaultCommandPolicy(t *testing.T) {
+	policy := config.DefaultConfig().Security.Command
+	assert.True(t, policy.UseShell)
+	assert.Contains(t, policy.EnvAllowlist, "PATH")
+	assert.NotContains(t, policy.EnvAllowlist, "ANTHROPIC_API_KEY")
+	assert.Equal(t, int64(1024*1024), policy.MaxOutputBytes)
+	assert.Equal(t, 30*time.Second, policy.DefaultTimeout)
+	assert.Equal(t, 5*time.Minute, policy.MaxTimeout)
+}
+
+func TestConfig_Validate_CommandPolicy(t *testing.T) {
+	t.Run("invalid pattern", func(t *testing.T) {
+		cfg := config.DefaultConfig()
+		cfg.Claude.APIKey = "test-key"
+		cfg.Security.Command.AllowedCommands = []string{"[git"}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "security.command")
+	})
+
+	t.Run("default exceeds max timeout", func(t *testing.T) {
+		cfg := config.DefaultConfig()
+		cfg.Claude.APIKey = "test-key"
+		cfg.Security.Command.DefaultTimeout = time.Hour
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "default_timeout")
+	})
+
+	t.Run("negative output cap", func(t *testing.T) {
+		cfg := config.DefaultConfig()
+		cfg.Claude.APIKey = "test-key"
+		cfg.Security.Command.MaxOutputBytes = -1
+		require.Error(t, cfg.Validate())
+	})
+}
+
+func TestConfig_Validate_Tasks(t *testing.T) {
+	cfg := config.DefaultConfig()
+	assert.True(t, cfg.Tasks.Enabled)
+	assert.Equal(t, "memory", cfg.Tasks.Backend)
+	assert.NoError(t, cfg.Tasks.Validate())
+
+	tests := []struct {
+		name   string
+		mutate func(*config.TasksConfig)
+		errMsg string
+	}{
+		{"unknown backend", func(c *config.TasksConfig) { c.Backend = "redis" }, "tasks.backend"},
+		{"nats without url", func(c *config.TasksConfig) { c.Backend = "nats"; c.NATSURL = "" }, "tasks.nats_url"},
+		{"no workers", func(c *config.TasksConfig) { c.Workers = 0 }, "tasks.workers"},
+		{"negative timeout", func(c *config.TasksConfig) { c.Timeout = -time.Second }, "durations"},
+		{"default ttl above max", func(c *config.TasksConfig) { c.DefaultTTL = 48 * time.Hour }, "tasks.default_ttl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Claude.APIKey = "test-key"
+			tt.mutate(&cfg.Tasks)
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+
+	t.Run("disabled skips validation", func(t *testing.T) {
+		tasks := config.DefaultConfig().Tasks
+		tasks.Enabled = false
+		tasks.Backend = "redis"
+		assert.NoError(t, tasks.Validate())
+	})
+}
+
+func TestConfig_Validate_Upstreams(t *testing.T) {
+	valid := []config.UpstreamServerConfig{
+		{Name: "github", Transport: "stdio", Command: "github-mcp", Env: []string{"GITHUB_TOKEN=${GITHUB_TOKEN}"}},
+		{Name: "metrics-api", Transport: "http", URL: "https://mcp.internal/metrics"},
+	}
+	assert.NoError(t, config.UpstreamsConfig{Servers: valid}.Validate())
+
+	tests := []struct {
+		name   string
+		server config.UpstreamServerConfig
+		errMsg string
+	}{
+		{"missing name", config.UpstreamServerConfig{Transport: "stdio", Command: "x"}, "invalid name"},
+		{"separator in name", config.UpstreamServerConfig{Name: "git__hub", Transport: "stdio", Command: "x"}, "invalid name"},
+		{"duplicate name", config.UpstreamServerConfig{Name: "github", Transport: "stdio", Command: "x"}, "duplicate"},
+		{"unknown transport", config.UpstreamServerConfig{Name: "x", Transport: "ws"}, "transport"},
+		{"stdio without command", config.UpstreamServerConfig{Name: "x", Transport: "stdio"}, "command is required"},
+		{"http without url", config.UpstreamServerConfig{Name: "x", Transport: "http"}, "url is required"},
+		{"env without value", config.UpstreamServerConfig{Name: "x", Transport: "stdio", Command: "x", Env: []string{"TOKEN"}}, "NAME=value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Claude.APIKey = "test-key"
+			cfg.Upstreams.Servers = append([]config.UpstreamServerConfig{valid[0]}, tt.server)
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestConfig_Validate_ToolCache(t *testing.T) {
+	cfg := config.DefaultConfig()
+	assert.Equal(t, "memory", cfg.ToolCache.Backend)
+	assert.Empty(t, cfg.ToolCache.Tools)
+	assert.NoError(t, cfg.ToolCache.Validate())
+
+	policy := config.ToolCachePolicyConfig{Tool: "collect_telemetry_context", TTL: time.Minute}
+	tests := []struct {
+		name   string
+		mutate func(*config.ToolCacheConfig)
+		errMsg string
+	}{
+		{"unknown backend", func(c *config.ToolCacheConfig) { c.Backend = "memcached" }, "tool_cache.backend"},
+		{"redis without url", func(c *config.ToolCacheConfig) { c.Backend = "redis" }, "tool_cache.redis_url"},
+		{"no entries", func(c *config.ToolCacheConfig) { c.MaxEntries = 0 }, "tool_cache.max_entries"},
+		{"missing tool", func(c *config.ToolCacheConfig) {
+			c.Tools = append(c.Tools, config.ToolCachePolicyConfig{TTL: time.Minute})
+		}, "tool is required"},
+		{"duplicate tool", func(c *config.ToolCacheConfig) { c.Tools = append(c.Tools, policy) }, "duplicate"},
+		{"no ttl", func(c *config.ToolCacheConfig) { c.Tools[0].TTL = 0 }, "ttl must be positive"},
+		{"negative bucket", func(c *config.ToolCacheConfig) { c.Tools[0].TimeBucket = -time.Second }, "time_bucket"},
+		{"filesystem tool", func(c *config.ToolCacheConfig) { c.Tools[0].Tool = "read_file" }, "cannot be cached"},
+		{"command tool", func(c *config.ToolCacheConfig) { c.Tools[0].Tool = "execute_command" }, "cannot be cached"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Claude.APIKey = "test-key"
+			cfg.ToolCache.Tools = []config.ToolCachePolicyConfig{policy}
+			require.NoError(t, cfg.Validate())
+			tt.mutate(&cfg.ToolCache)
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+
+	t.Run("built-in tools", func(t *testing.T) {
+		cacheable := []string{
+			"echo", "system_info", "collect_telemetry_context", "build_system_prompt", "list_context_types",
+		}
+		stateful := []string{
+			"read_file", "write_file", "edit_file", "list_directory", "search_files", "execute_command",
+			"claude_conversation", "analyze_telemetry", "investigate_telemetry",
+			"start_conversation", "send_conversation_message", "close_conversation", "get_conversation", "list_conversations",
+			"start_background_task", "get_task_status", "get_task_result", "cancel_task",
+			"get_llm_usage", "get_llm_budget", "search_audit_trail",
+		}
+		for _, tool := range cacheable {
+			c := config.DefaultConfig().ToolCache
+			c.Tools = []config.ToolCachePolicyConfig{{Tool: tool, TTL: time.Minute}}
+			assert.NoError(t, c.Validate(), tool)
+		}
+		for _, tool := range stateful {
+			c := config.DefaultConfig().ToolCache
+			c.Tools = []config.ToolCachePolicyConfig{{Tool: tool, TTL: time.Minute}}
+			err := c.Validate()
+			if assert.Error(t, err, tool) {
+				assert.Contains(t, err.Error(), "cannot be cached")
+			}
+		}
+	})
+
+	t.Run("http tool", func(t *testing.T) {
+		cfg := config.DefaultConfig()
+		cfg.Claude.APIKey = "test-key"
+		cfg.HTTPTools = []config.HTTPToolConfig{{Name: "get_deploys", URL: "https://deploys.internal/api"}}
+		cfg.ToolCache.Tools = []config.ToolCachePolicyConfig{{Tool: "get_deploys", TTL: time.Minute}}
+		require.NoError(t, cfg.Validate())
+
+		cfg.HTTPTools[0].Method = "post"
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "uses POST and cannot be cached")
+	})
+}
+
+func TestConfig_Validate_Audit(t *testing.T) {
+	cfg := config.DefaultConfig()
+	assert.True(t, cfg.Audit.Enabled)
+	assert.Equal(t, 720*time.Hour, cfg.Audit.Retention)
+	assert.NoError(t, cfg.Audit.Validate())
+
+	tests := []struct {
+		name   string
+		mutate func(*config.AuditConfig)
+		errMsg string
+	}{
+		{"no buffer", func(c *config.AuditConfig) { c.BufferSize = 0 }, "audit.buffer_size"},
+		{"no records", func(c *config.AuditConfig) { c.MaxRecords = 0 }, "audit.max_records"},
+		{"no batch", func(c *config.AuditConfig) { c.BatchSize = 0 }, "audit.batch_size"},
+		{"negative retention", func(c *config.AuditConfig) { c.Retention = -time.Hour }, "must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Claude.APIKey = "test-key"
+			tt.mutate(&cfg.Audit)
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+
+	t.Run("disabled skips checks", func(t *testing.T) {
+		cfg := config.DefaultConfig()
+		cfg.Audit.Enabled = false
+		cfg.Audit.BufferSize = 0
+		assert.NoError(t, cfg.Audit.Validate())
+	})
+}
+
+func TestLoadHTTPTools(t *testing.T) {
+	content := []byte(`
+http_tools:
+  - name: get_incident
+    description: Fetch an incident
+    input_schema:
+      type: object
+      properties:
+        incidentId:
+          type: string
+      required: [incidentId]
+    method: GET
+    url: https://status.example.com/incidents/{{.incidentId}}
+    headers:
+      X-Api-Key: ${STATUS_API_KEY}
+    response_path: $.incident.summary
+    timeout: 10s
+`)
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, content, 0644))
+
+	tools, err := config.LoadHTTPTools(cfgPath)
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+
+	tool := tools[0]
+	assert.Equal(t, "get_incident", tool.Name)
+	assert.Equal(t, "GET", tool.Method)
+	assert.Equal(t, "${STATUS_API_KEY}", tool.Headers["X-Api-Key"], "header names and secret references are kept verbatim")
+	assert.Contains(t, tool.InputSchema["properties"], "incidentId", "property names keep their case")
+	assert.Equal(t, 10*time.Second, tool.Timeout)
+	assert.NoError(t, config.ValidateHTTPTools(tools))
+}
+
+func TestValidateHTTPTools(t *testing.T) {
+	valid := config.HTTPToolConfig{Name: "get_incident", URL: "https://status.example.com/incidents"}
+	assert.NoError(t, config.ValidateHTTPTools([]config.HTTPToolConfig{valid}))
+
+	tests := []struct {
+		name   string
+		tool   config.HTTPToolConfig
+		errMsg string
+	}{
+		{"invalid name", config.HTTPToolConfig{Name: "get incident", URL: "https://x"}, "invalid name"},
+		{"duplicate name", config.HTTPToolConfig{Name: "get_incident", URL: "https://x"}, "duplicate"},
+		{"unsupported method", config.HTTPToolConfig{Name: "t", Method: "TRACE", URL: "https://x"}, "unsupported method"},
+		{"relative url", config.HTTPToolConfig{Name: "t", URL: "/incidents"}, "url must start"},
+		{"bad response path", config.HTTPToolConfig{Name: "t", URL: "https://x", ResponsePath: "data.id"}, "response_path"},
+		{"negative timeout", config.HTTPToolConfig{Name: "t", URL: "https://x", Timeout: -time.Second}, "timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := config.ValidateHTTPTools([]config.HTTPToolConfig{valid, tt.tool})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestLoadPipelines(t *testing.T) {
+	content := []byte(`
+pipelines:
+  - name: triage_service
+    description: Collect context and summarize
+    steps:
+      - id: collect
+        tool: collect_telemetry_context
+        arguments:
+          organization_id: "{{.input.orgId}}"
+          context_type: metrics
+      - id: ask
+        tool: claude_conversation
+        depends_on: [collect]
+        when: '{{ne .steps.collect.status "failed"}}'
+        on_error: continue
+        retries: 2
+        arguments:
+          message: "{{.steps.collect.text}}"
+`)
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, content, 0644))
+
+	pipelines, err := config.LoadPipelines(cfgPath)
+	require.NoError(t, err)
+	require.Len(t, pipelines, 1)
+	require.Len(t, pipelines[0].Steps, 2)
+
+	ask := pipelines[0].Steps[1]
+	assert.Equal(t, []string{"collect"}, ask.DependsOn)
+	assert.Equal(t, "continue", ask.OnError)
+	assert.Equal(t, 2, ask.Retries)
+	assert.Equal(t, "{{.input.orgId}}", pipelines[0].Steps[0].Arguments["organization_id"], "templates keep their case")
+	assert.NoError(t, config.ValidatePipelines(pipelines))
+}
+
+func TestValidatePipelines(t *testing.T) {
+	step := func(id string, deps ...string) config.PipelineStepConfig {
+		return config.PipelineStepConfig{ID: id, Tool: "echo", DependsOn: deps}
+	}
+	pipeline := func(steps ...config.PipelineStepConfig) config.PipelineConfig {
+		return config.PipelineConfig{Name: "p", Steps: steps}
+	}
+
+	assert.NoError(t, config.ValidatePipelines([]config.PipelineConfig{pipeline(step("a"), step("b", "a"), step("c", "a", "b"))}))
+
+	tests := []struct {
+		name     string
+		pipeline config.PipelineConfig
+		errMsg   string
+	}{
+		{"invalid name", config.PipelineConfig{Name: "bad name", Steps: []config.PipelineStepConfig{step("a")}}, "invalid name"},
+		{"no steps", pipeline(), "at least one step"},
+		{"invalid step id", pipeline(step("my-step")), "invalid step id"},
+		{"duplicate step id", pipeline(step("a"), step("a")), "duplicate step id"},
+		{"missing tool", pipeline(config.PipelineStepConfig{ID: "a"}), "tool is required"},
+		{"calls itself", pipeline(config.PipelineStepConfig{ID: "a", Tool: "p"}), "cannot call itself"},
+		{"unknown dependency", pipeline(step("a", "zzz")), "unknown dependency"},
+		{"cycle", pipeline(step("a", "c"), step("b", "a"), step("c", "b")), "cycle"},
+		{"bad on_error", pipeline(config.PipelineStepConfig{ID: "a", Tool: "echo", OnError: "ignore"}), "on_error"},
+		{"too many retries", pipeline(config.PipelineStepConfig{ID: "a", Tool: "echo", Retries: 10}), "retries"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := config.ValidatePipelines([]config.PipelineConfig{tt.pipeline})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+
+	t.Run("name shared with http tool", func(t *testing.T) {
+		cfg := config.DefaultConfig()
+		cfg.Claude.APIKey = "test-key"
+		cfg.HTTPTools = []config.HTTPToolConfig{{Name: "p", URL: "https://example.com"}}
+		cfg.Pipelines = []config.PipelineConfig{pipeline(step("a"))}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already used")
+	})
+}
+
+func TestConfig_Agent(t *testing.T) {
+	cfg := config.DefaultConfig()
+	assert.True(t, cfg.Agent.Enabled)
+	assert.Equal(t, config.DefaultAgentTools, cfg.Agent.AllowedTools)
+	for _, tool := range []string{"execute_command", "write_file", "read_file", "list_directory", "search_files"} {
+		assert.NotContains(t, cfg.Agent.AllowedTools, tool)
+	}
+
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	content := []byte("claude:\n  api_key: sk-from-file\nagent:\n  max_iterations: 4\n  allowed_tools: [collect_telemetry_context]\n")
+	require.NoError(t, os.WriteFile(cfgPath, content, 0644))
+
+	cfg, err := config.Load(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, 4, cfg.Agent.MaxIterations)
+	assert.Equal(t, []string{"collect_telemetry_context"}, cfg.Agent.AllowedTools)
+	assert.Equal(t, config.DefaultConfig().Agent.TokenBudget, cfg.Agent.TokenBudget)
+
+	cfg.Agent.MaxParallelTools = 0
+	assert.ErrorContains(t, cfg.Validate(), "agent.max_parallel_tools")
+
+	cfg.Agent.Enabled = false
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestConfig_Compaction(t *testing.T) {
+	cfg := config.DefaultConfig()
+	assert.True(t, cfg.Compaction.Enabled)
+	assert.Equal(t, 0.8, cfg.Compaction.Threshold)
+
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	content := []byte("claude:\n  api_key: sk-from-file\ncompaction:\n  threshold: 0.6\n  summary_model: claude-haiku-4-5\n")
+	require.NoError(t, os.WriteFile(cfgPath, content, 0644))
+
+	cfg, err := config.Load(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, 0.6, cfg.Compaction.Threshold)
+	assert.Equal(t, "claude-haiku-4-5", cfg.Compaction.SummaryModel)
+	assert.Equal(t, 6, cfg.Compaction.KeepMessages)
+
+	cfg.Compaction.Threshold = 1.5
+	assert.ErrorContains(t, cfg.Validate(), "compaction.threshold")
+
+	cfg.Compaction.Enabled = false
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestConfig_PromptCaching(t *testing.T) {
+	cfg := config.DefaultConfig()
+	assert.True(t, cfg.Claude.PromptCaching.Enabled)
+
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	content := []byte(`claude:
+  api_key: sk-from-file
+  prompt_caching:
+    min_tokens: 2048
+providers:
+  openai:
+    api_key: sk-openai
+    prompt_caching:
+      enabled: true
+`)
+	require.NoError(t, os.WriteFile(cfgPath, content, 0644))
+
+	cfg, err := config.Load(cfgPath)
+	require.NoError(t, err)
+	assert.True(t, cfg.Claude.PromptCaching.Enabled, "enabled unless turned off")
+	assert.Equal(t, 2048, cfg.Claude.PromptCaching.MinTokens)
+	assert.True(t, cfg.Providers["openai"].PromptCaching.Enabled)
+
+	t.Setenv("TELEMETRYFLOW_MCP_CLAUDE_PROMPT_CACHING_ENABLED", "false")
+	cfg, err = config.Load(cfgPath)
+	require.NoError(t, err)
+	assert.False(t, cfg.Claude.PromptCaching.Enabled)
+
+	cfg.Claude.PromptCaching.MinTokens = -1
+	assert.ErrorContains(t, cfg.Validate(), "claude.prompt_caching.min_tokens")
+}
+
+func TestConfig_Usage(t *testing.T) {
+	cfg := config.DefaultConfig()
+	assert.True(t, cfg.Usage.Enabled)
+	assert.True(t, cfg.Usage.ClickHouse)
+	assert.Contains(t, cfg.Agent.AllowedTools, "get_llm_usage")
+
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	content := []byte(`claude:
+  api_key: sk-from-file
+usage:
+  organization_id: acme
+  pricing:
+    version: acme-2026
+    models:
+      - model: claude-opus-4-7
+        input: 4
+        output: 20
+        cache_read: 0.4
+        cache_write: 5
+`)
+	require.NoError(t, os.WriteFile(cfgPath, content, 0644))
+
+	cfg, err := config.Load(cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", cfg.Usage.OrganizationID)
+	assert.Equal(t, "acme-2026", cfg.Usage.Pricing.Version)
+	require.Len(t, cfg.Usage.Pricing.Models, 1)
+	assert.Equal(t, config.ModelPriceConfig{Model: "claude-opus-4-7", Input: 4, Output: 20, CacheRead: 0.4, CacheWrite: 5}, cfg.Usage.Pricing.Models[0])
+	assert.Equal(t, config.DefaultConfig().Usage.BatchSize, cfg.Usage.BatchSize)
+
+	tests := []struct {
+		name   string
+		mutate func(*config.UsageConfig)
+		errMsg string
+	}{
+		{"no buffer", func(c *config.UsageConfig) { c.BufferSize = 0 }, "usage.buffer_size"},
+		{"no version", func(c *config.UsageConfig) { c.Pricing.Version = "" }, "usage.pricing.version"},
+		{"no model", func(c *config.UsageConfig) { c.Pricing.Models[0].Model = "" }, "model is required"},
+		{"negative price", func(c *config.UsageConfig) { c.Pricing.Models[0].Output = -1 }, "must not be negative"},
+		{"duplicate", func(c *config.UsageConfig) {
+			c.Pricing.Models = append(c.Pricing.Models, c.Pricing.Models[0])
+		}, "duplicate price"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.Load(cfgPath)
+			require.NoError(t, err)
+			tt.mutate(&cfg.Usage)
+			assert.ErrorContains(t, cfg.Validate(), tt.errMsg)
+		})
+	}
+
+	cfg.Usage.Enabled = false
+	cfg.Usage.BufferSize = 0
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestConfig_Budgets(t *testing.T) {
+	cfg := config.DefaultConfig()
+	assert.False(t, cfg.Budgets.Enabled)
+	assert.Equal(t, "memory", cfg.Budgets.Backend)
+
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	content := []byte(`claude:
+  api_key: sk-from-file
+budgets:
+  enabled: true
+  limits:
+    - organization_id: acme
+      period: monthly
+      cost_usd: 500
+      warn_at: 0.9
+    - organization_id: acme
+      period: daily
+      tokens: 2000000
+      hard_limit: false
+  notifications:
+    webhook_url: https://hooks.example.com/budgets
+    smtp:
+      host: smtp.example.com
+      from: mcp@example.com
+`)
+	require.NoError(t, os.WriteFile(cfgPath, content, 0644))
+
+	cfg, err := config.Load(cfgPath)
+	require.NoError(t, err)
+	require.Len(t, cfg.Budgets.Limits, 2)
+	monthly, daily := cfg.Budgets.Limits[0], cfg.Budgets.Limits[1]
+	assert.Equal(t, 500.0, monthly.CostUSD)
+	require.NotNil(t, monthly.WarnAt)
+	assert.Equal(t, 0.9, *monthly.WarnAt)
+	assert.Nil(t, monthly.HardLimit)
+	assert.Equal(t, int64(2000000), daily.Tokens)
+	require.NotNil(t, daily.HardLimit)
+	assert.False(t, *daily.HardLimit)
+	assert.Equal(t, 587, cfg.Budgets.Notifications.SMTP.Port)
+
+	tests := []struct {
+		name   string
+		mutate func(*config.BudgetsConfig)
+		errMsg string
+	}{
+		{"unknown backend", func(c *config.BudgetsConfig) { c.Backend = "etcd" }, "budgets.backend"},
+		{"redis without url", func(c *config.BudgetsConfig) { c.Backend = "redis" }, "budgets.redis_url"},
+		{"no organization", func(c *config.BudgetsConfig) { c.Limits[0].OrganizationID = "" }, "organization_id is required"},
+		{"bad period", func(c *config.BudgetsConfig) { c.Limits[0].Period = "weekly" }, "period must be"},
+		{"duplicate", func(c *config.BudgetsConfig) { c.Limits[1].Period = "monthly" }, "duplicate monthly budget"},
+		{"no limit", func(c *config.BudgetsConfig) { c.Limits[0].CostUSD = 0 }, "tokens or cost_usd is required"},
+		{"negative", func(c *config.BudgetsConfig) { c.Limits[1].Tokens = -1 }, "must not be negative"},
+		{"warn_at", func(c *config.BudgetsConfig) { warnAt := 1.0; c.Limits[0].WarnAt = &warnAt }, "warn_at"},
+		{"smtp from", func(c *config.BudgetsConfig) { c.Notifications.SMTP.From = "" }, "smtp.from"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.Load(cfgPath)
+			require.NoError(t, err)
+			tt.mutate(&cfg.Budgets)
+			assert.ErrorContains(t, cfg.Validate(), tt.errMsg)
+		})
+	}
+
+	cfg.Budgets.Enabled = false
+	cfg.Budgets.Backend = "etcd"
+	assert.NoError(t, cfg.Validate())
+}
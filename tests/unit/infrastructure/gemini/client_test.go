@@ -0,0 +1,397 @@
+package gemini_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/gemini"
+)
+
+// geminiServer is a stand-in Gemini endpoint that records the last request
+type geminiServer struct {
+	*httptest.Server
+	path    string
+	query   string
+	body    map[string]interface{}
+	headers http.Header
+}
+
+func newGeminiServer(t *testing.T, status int, contentType, response string) *geminiServer {
+	t.Helper()
+	s := &geminiServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.path = r.URL.Path
+		s.query = r.URL.RawQuery
+		data, _ := io.ReadAll(r.Body)
+		s.body = nil
+		_ = json.Unmarshal(data, &s.body)
+		s.headers = r.Header.Clone()
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, response)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestClient(t *testing.T, baseURL string) *gemini.Client {
+	t.Helper()
+	client, err := gemini.NewClient(config.ProviderConfig{APIKey: "test-key", BaseURL: baseURL + "/v1beta/"}, zerolog.Nop())
+	require.NoError(t, err)
+	return client
+}
+
+// toolTurnRequest is a conversation where the model called a tool and got its result
+func toolTurnRequest(model vo.Model) *services.ClaudeRequest {
+	systemPrompt, _ := vo.NewSystemPrompt("You are an SRE assistant")
+	closed := false
+	return &services.ClaudeRequest{
+		Model:        model,
+		SystemPrompt: systemPrompt,
+		MaxTokens:    512,
+		Temperature:  0.2,
+		Messages: []services.ClaudeMessage{
+			{Role: vo.RoleUser, Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: "Why is checkout slow?"}}},
+			{Role: vo.RoleAssistant, Content: []entities.ContentBlock{
+				{Type: vo.ContentTypeText, Text: "Let me check the metrics."},
+				{Type: vo.ContentTypeToolUse, ID: "call_1", Name: "query_metrics", Input: map[string]interface{}{"service": "checkout"}},
+			}},
+			{Role: vo.RoleUser, Content: []entities.ContentBlock{
+				{Type: vo.ContentTypeToolResult, ToolUseID: "call_1", Content: "p99 latency 2.3s"},
+				{Type: vo.ContentTypeText, Text: "What next?"},
+			}},
+		},
+		Tools: []services.ClaudeTool{{
+			Name:        "query_metrics",
+			Description: "Query service metrics",
+			InputSchema: &entities.JSONSchema{
+				Type: "object",
+				Properties: map[string]*entities.JSONSchema{
+					"service": {Type: "string", Description: "Service name"},
+					"window":  {Type: "string", Enum: []interface{}{"1h", "24h"}},
+				},
+				Required:             []string{"service"},
+				AdditionalProperties: &closed,
+			},
+		}},
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := gemini.NewClient(config.ProviderConfig{}, zerolog.Nop())
+	assert.ErrorIs(t, err, gemini.ErrAPIKeyRequired)
+
+	_, err = gemini.NewClient(config.ProviderConfig{APIKey: "k"}, zerolog.Nop())
+	assert.NoError(t, err)
+}
+
+func TestClient_CreateMessage_MapsRequest(t *testing.T) {
+	srv := newGeminiServer(t, http.StatusOK, "application/json", `{
+		"candidates": [{"content": {"role": "model", "parts": [{"text": "Checkout latency comes from the payment service."}]}, "finishReason": "STOP"}],
+		"usageMetadata": {"promptTokenCount": 120, "cachedContentTokenCount": 100, "candidatesTokenCount": 30, "thoughtsTokenCount": 12},
+		"modelVersion": "gemini-2.5-flash",
+		"responseId": "resp-1"
+	}`)
+	client := newTestClient(t, srv.URL)
+
+	resp, err := client.CreateMessage(context.Background(), toolTurnRequest(vo.ModelGemini25Flash))
+	require.NoError(t, err)
+
+	assert.Equal(t, "/v1beta/models/gemini-2.5-flash:generateContent", srv.path)
+	assert.Equal(t, "test-key", srv.headers.Get("x-goog-api-key"))
+
+	assert.Equal(t, map[string]interface{}{"parts": []interface{}{map[string]interface{}{"text": "You are an SRE assistant"}}}, srv.body["systemInstruction"])
+	assert.Equal(t, map[string]interface{}{"maxOutputTokens": float64(512), "temperature": 0.2}, srv.body["generationConfig"])
+
+	contents := srv.body["contents"].([]interface{})
+	require.Len(t, contents, 3)
+	model := contents[1].(map[string]interface{})
+	assert.Equal(t, "model", model["role"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"text": "Let me check the metrics."},
+		map[string]interface{}{"functionCall": map[string]interface{}{"name": "query_metrics", "args": map[string]interface{}{"service": "checkout"}}},
+	}, model["parts"])
+	user := contents[2].(map[string]interface{})
+	assert.Equal(t, "user", user["role"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"functionResponse": map[string]interface{}{"name": "query_metrics", "response": map[string]interface{}{"content": "p99 latency 2.3s"}}},
+		map[string]interface{}{"text": "What next?"},
+	}, user["parts"])
+
+	declarations := srv.body["tools"].([]interface{})[0].(map[string]interface{})["functionDeclarations"].([]interface{})
+	declaration := declarations[0].(map[string]interface{})
+	assert.Equal(t, "query_metrics", declaration["name"])
+	parameters := declaration["parameters"].(map[string]interface{})
+	assert.NotContains(t, parameters, "additionalProperties")
+	assert.Equal(t, []interface{}{"service"}, parameters["required"])
+	window := parameters["properties"].(map[string]interface{})["window"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"1h", "24h"}, window["enum"])
+	assert.Equal(t, "enum", window["format"])
+
+	assert.Equal(t, "resp-1", resp.ID)
+	assert.Equal(t, "gemini-2.5-flash", resp.Model)
+	assert.Equal(t, vo.RoleAssistant, resp.Role)
+	assert.Equal(t, "end_turn", resp.StopReason)
+	require.Len(t, resp.Content, 1)
+	assert.Equal(t, "Checkout latency comes from the payment service.", resp.Content[0].Text)
+	assert.Equal(t, 20, resp.Usage.InputTokens, "cached prompt tokens are reported apart")
+	assert.Equal(t, 100, resp.Usage.CacheReadInputTokens)
+	assert.Equal(t, 42, resp.Usage.OutputTokens)
+	assert.Equal(t, 12, resp.Usage.ReasoningTokens, "thoughts are part of the output tokens")
+}
+
+func TestClient_CreateMessage_Reasoning(t *testing.T) {
+	srv := newGeminiServer(t, http.StatusOK, "application/json",
+		`{"candidates": [{"content": {"parts": [{"text": "ok"}]}, "finishReason": "STOP"}]}`)
+	client := newTestClient(t, srv.URL)
+
+	request := toolTurnRequest(vo.ModelGemini25Flash)
+	request.Reasoning = &vo.Reasoning{Effort: vo.ReasoningEffortMedium}
+	request.Messages[1].Content = []entities.ContentBlock{
+		{Type: vo.ContentTypeThinking, Thinking: "The checkout metrics will tell."},
+		{Type: vo.ContentTypeToolUse, ID: "call_1", Name: "query_metrics", Input: map[string]interface{}{"service": "checkout"}, Signature: "c2ln"},
+	}
+	_, err := client.CreateMessage(context.Background(), request)
+	require.NoError(t, err)
+
+	config := srv.body["generationConfig"].(map[string]interface{})
+	assert.Equal(t, float64(512+8192), config["maxOutputTokens"], "the thinking budget is added to the output tokens")
+	assert.Equal(t, map[string]interface{}{"thinkingBudget": float64(8192), "includeThoughts": true}, config["thinkingConfig"])
+
+	// Thought summaries are not replayed, but the signature of the call is
+	parts := srv.body["contents"].([]interface{})[1].(map[string]interface{})["parts"].([]interface{})
+	require.Len(t, parts, 1)
+	assert.Equal(t, "c2ln", parts[0].(map[string]interface{})["thoughtSignature"])
+}
+
+func TestClient_CreateMessage_ThoughtSignature(t *testing.T) {
+	srv := newGeminiServer(t, http.StatusOK, "application/json",
+		`{"candidates": [{"content": {"parts": [{"text": "ok"}]}, "finishReason": "STOP"}]}`)
+	client := newTestClient(t, srv.URL)
+
+	request := toolTurnRequest(vo.ModelGemini3FlashPreview)
+	request.Messages[1].Content = append(request.Messages[1].Content,
+		entities.ContentBlock{Type: vo.ContentTypeToolUse, ID: "call_2", Name: "query_logs", Input: map[string]interface{}{}})
+	_, err := client.CreateMessage(context.Background(), request)
+	require.NoError(t, err)
+
+	parts := srv.body["contents"].([]interface{})[1].(map[string]interface{})["parts"].([]interface{})
+	require.Len(t, parts, 3)
+	assert.Equal(t, "skip_thought_signature_validator", parts[1].(map[string]interface{})["thoughtSignature"])
+	assert.NotContains(t, parts[2], "thoughtSignature")
+}
+
+func TestClient_CreateMessage_Media(t *testing.T) {
+	srv := newGeminiServer(t, http.StatusOK, "application/json",
+		`{"candidates": [{"content": {"parts": [{"text": "ok"}]}, "finishReason": "STOP"}]}`)
+	client := newTestClient(t, srv.URL)
+
+	image, err := entities.NewMediaBlock(vo.MimeTypePNG, []byte("\x89PNG"), "")
+	require.NoError(t, err)
+	document, err := entities.NewMediaBlock(vo.MimeTypePDF, []byte("%PDF-1.7"), "report.pdf")
+	require.NoError(t, err)
+	_, err = client.CreateMessage(context.Background(), &services.ClaudeRequest{
+		Model:     vo.ModelGemini25Flash,
+		MaxTokens: 512,
+		Messages: []services.ClaudeMessage{{Role: vo.RoleUser, Content: []entities.ContentBlock{
+			image, document, {Type: vo.ContentTypeText, Text: "Summarize"},
+		}}},
+	})
+	require.NoError(t, err)
+
+	parts := srv.body["contents"].([]interface{})[0].(map[string]interface{})["parts"].([]interface{})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"inlineData": map[string]interface{}{"mimeType": "image/png", "data": "iVBORw=="}},
+		map[string]interface{}{"inlineData": map[string]interface{}{"mimeType": "application/pdf", "data": "JVBERi0xLjc="}},
+		map[string]interface{}{"text": "Summarize"},
+	}, parts)
+}
+
+func TestClient_CreateMessage_FunctionCalls(t *testing.T) {
+	srv := newGeminiServer(t, http.StatusOK, "application/json", `{
+		"candidates": [{"content": {"role": "model", "parts": [
+			{"text": "thinking...", "thought": true},
+			{"text": "Querying logs."},
+			{"functionCall": {"id": "fc-1", "name": "query_logs", "args": {"level": "error"}}, "thoughtSignature": "c2ln"},
+			{"functionCall": {"name": "query_traces"}}
+		]}, "finishReason": "STOP"}]
+	}`)
+	client := newTestClient(t, srv.URL)
+
+	resp, err := client.CreateMessage(context.Background(), toolTurnRequest(vo.ModelGemini25Pro))
+	require.NoError(t, err)
+
+	assert.Equal(t, "tool_use", resp.StopReason)
+	require.Len(t, resp.Content, 4)
+	assert.Equal(t, vo.ContentTypeThinking, resp.Content[0].Type)
+	assert.Equal(t, "thinking...", resp.Content[0].Thinking)
+	assert.Equal(t, "Querying logs.", resp.Content[1].Text)
+	assert.Equal(t, vo.ContentTypeToolUse, resp.Content[2].Type)
+	assert.Equal(t, "fc-1", resp.Content[2].ID)
+	assert.Equal(t, "c2ln", resp.Content[2].Signature)
+	assert.Equal(t, map[string]interface{}{"level": "error"}, resp.Content[2].Input)
+	assert.Equal(t, "query_traces", resp.Content[3].Name)
+	assert.NotEmpty(t, resp.Content[3].ID)
+	assert.Empty(t, resp.Content[3].Signature)
+	assert.Equal(t, map[string]interface{}{}, resp.Content[3].Input)
+}
+
+func TestClient_CreateMessage_Safety(t *testing.T) {
+	srv := newGeminiServer(t, http.StatusOK, "application/json",
+		`{"promptFeedback": {"blockReason": "SAFETY"}, "usageMetadata": {"promptTokenCount": 8}}`)
+	client := newTestClient(t, srv.URL)
+
+	_, err := client.CreateMessage(context.Background(), toolTurnRequest(vo.ModelGemini25Flash))
+	require.ErrorIs(t, err, gemini.ErrPromptBlocked)
+	assert.Contains(t, err.Error(), "SAFETY")
+
+	srv = newGeminiServer(t, http.StatusOK, "application/json",
+		`{"candidates": [{"finishReason": "SAFETY"}]}`)
+	client = newTestClient(t, srv.URL)
+
+	resp, err := client.CreateMessage(context.Background(), toolTurnRequest(vo.ModelGemini25Flash))
+	require.NoError(t, err)
+	assert.Equal(t, "refusal", resp.StopReason)
+	assert.Empty(t, resp.Content)
+}
+
+func TestClient_CreateMessage_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		sentinel error
+		message  string
+	}{
+		{"rate limited", http.StatusTooManyRequests, `{"error": {"code": 429, "message": "Quota exceeded", "status": "RESOURCE_EXHAUSTED"}}`, gemini.ErrRateLimited, "RESOURCE_EXHAUSTED: Quota exceeded"},
+		{"bad request", http.StatusBadRequest, `{"error": {"code": 400, "message": "Invalid schema", "status": "INVALID_ARGUMENT"}}`, gemini.ErrAPIError, "HTTP 400"},
+		{"plain body", http.StatusBadGateway, `upstream down`, gemini.ErrAPIError, "upstream down"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newGeminiServer(t, tt.status, "application/json", tt.body)
+			client := newTestClient(t, srv.URL)
+
+			_, err := client.CreateMessage(context.Background(), toolTurnRequest(vo.ModelGemini25Flash))
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tt.sentinel)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestClient_ValidateRequest(t *testing.T) {
+	client := newTestClient(t, "http://localhost")
+
+	assert.ErrorIs(t, client.ValidateRequest(nil), gemini.ErrInvalidRequest)
+
+	err := client.ValidateRequest(toolTurnRequest(vo.ModelGPT54))
+	require.ErrorIs(t, err, gemini.ErrInvalidRequest)
+	assert.Contains(t, err.Error(), "not served by google")
+
+	request := toolTurnRequest(vo.ModelGemini25Flash)
+	request.MaxTokens = 0
+	require.NoError(t, client.ValidateRequest(request))
+	assert.Equal(t, gemini.DefaultMaxTokens, request.MaxTokens)
+
+	orphan := toolTurnRequest(vo.ModelGemini25Flash)
+	orphan.Messages = orphan.Messages[2:]
+	_, err = client.CreateMessage(context.Background(), orphan)
+	assert.ErrorIs(t, err, gemini.ErrInvalidRequest)
+}
+
+func TestClient_CountTokens(t *testing.T) {
+	srv := newGeminiServer(t, http.StatusOK, "application/json", `{"totalTokens": 231}`)
+	client := newTestClient(t, srv.URL)
+
+	count, err := client.CountTokens(context.Background(), toolTurnRequest(vo.ModelGemini25Flash))
+	require.NoError(t, err)
+	assert.Equal(t, 231, count)
+
+	assert.Equal(t, "/v1beta/models/gemini-2.5-flash:countTokens", srv.path)
+	generate := srv.body["generateContentRequest"].(map[string]interface{})
+	assert.Equal(t, "models/gemini-2.5-flash", generate["model"])
+	assert.Len(t, generate["contents"], 3)
+	assert.NotContains(t, generate, "generationConfig")
+}
+
+func TestClient_CreateMessageStream(t *testing.T) {
+	stream := strings.Join([]string{
+		`data: {"candidates": [{"content": {"role": "model", "parts": [{"text": "Check"}]}}], "modelVersion": "gemini-2.5-flash", "responseId": "resp-2"}`,
+		``,
+		`data: {"candidates": [{"content": {"role": "model", "parts": [{"text": "ing."}]}}]}`,
+		``,
+		`data: {"candidates": [{"content": {"role": "model", "parts": [{"functionCall": {"name": "query_logs", "args": {"level": "error"}}}]}, "finishReason": "STOP"}], "usageMetadata": {"promptTokenCount": 42, "candidatesTokenCount": 17}}`,
+		``,
+	}, "\n")
+	srv := newGeminiServer(t, http.StatusOK, "text/event-stream", stream)
+	client := newTestClient(t, srv.URL)
+
+	events, err := client.CreateMessageStream(context.Background(), toolTurnRequest(vo.ModelGemini25Flash))
+	require.NoError(t, err)
+
+	var collected []*services.ClaudeStreamEvent
+	for event := range events {
+		require.NoError(t, event.Error)
+		collected = append(collected, event)
+	}
+
+	assert.Equal(t, "/v1beta/models/gemini-2.5-flash:streamGenerateContent", srv.path)
+	assert.Equal(t, "alt=sse", srv.query)
+
+	types := make([]string, 0, len(collected))
+	for _, e := range collected {
+		types = append(types, e.Type)
+	}
+	assert.Equal(t, []string{
+		"message_start",
+		"content_block_start", "content_block_delta", "content_block_delta",
+		"content_block_start", "content_block_delta",
+		"content_block_stop", "content_block_stop",
+		"message_delta", "message_stop",
+	}, types)
+
+	assert.Equal(t, "resp-2", collected[0].Message.ID)
+	assert.Equal(t, "Check", collected[2].Delta.Text)
+	assert.Equal(t, "ing.", collected[3].Delta.Text)
+
+	toolStart := collected[4]
+	assert.Equal(t, 1, toolStart.Index)
+	assert.Equal(t, vo.ContentTypeToolUse, toolStart.ContentBlock.Type)
+	assert.Equal(t, "query_logs", toolStart.ContentBlock.Name)
+	assert.Equal(t, `{"level":"error"}`, collected[5].Delta.PartialJSON)
+
+	messageDelta := collected[8]
+	assert.Equal(t, "tool_use", messageDelta.Delta.StopReason)
+	assert.Equal(t, 42, messageDelta.Usage.InputTokens)
+	assert.Equal(t, 17, messageDelta.Usage.OutputTokens)
+}
+
+func TestClient_CreateMessageStream_Blocked(t *testing.T) {
+	srv := newGeminiServer(t, http.StatusOK, "text/event-stream",
+		"data: {\"promptFeedback\": {\"blockReason\": \"PROHIBITED_CONTENT\"}}\n\n")
+	client := newTestClient(t, srv.URL)
+
+	events, err := client.CreateMessageStream(context.Background(), toolTurnRequest(vo.ModelGemini25Flash))
+	require.NoError(t, err)
+
+	var last *services.ClaudeStreamEvent
+	for event := range events {
+		last = event
+	}
+	require.NotNil(t, last)
+	assert.ErrorIs(t, last.Error, gemini.ErrPromptBlocked)
+}
@@ -0,0 +1,128 @@
+package llm_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/llm"
+)
+
+// namedBackend answers every request with its own name
+type namedBackend struct {
+	name  string
+	calls int
+}
+
+func (b *namedBackend) CreateMessage(ctx context.Context, request *services.ClaudeRequest) (*services.ClaudeResponse, error) {
+	b.calls++
+	return &services.ClaudeResponse{Model: request.Model.String(), Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: b.name}}}, nil
+}
+
+func (b *namedBackend) CreateMessageStream(ctx context.Context, request *services.ClaudeRequest) (<-chan *services.ClaudeStreamEvent, error) {
+	b.calls++
+	events := make(chan *services.ClaudeStreamEvent, 1)
+	events <- &services.ClaudeStreamEvent{Type: "message_stop"}
+	close(events)
+	return events, nil
+}
+
+func (b *namedBackend) CountTokens(ctx context.Context, request *services.ClaudeRequest) (int, error) {
+	b.calls++
+	return len(b.name), nil
+}
+
+func (b *namedBackend) ValidateRequest(request *services.ClaudeRequest) error {
+	return nil
+}
+
+func request(model vo.Model) *services.ClaudeRequest {
+	return &services.ClaudeRequest{
+		Model:    model,
+		Messages: []services.ClaudeMessage{{Role: vo.RoleUser, Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: "hi"}}}},
+	}
+}
+
+func TestRegistry_RoutesByProvider(t *testing.T) {
+	anthropic := &namedBackend{name: "anthropic"}
+	openai := &namedBackend{name: "openai"}
+	registry := llm.NewRegistry()
+	registry.Register(vo.ProviderOpenAI, openai)
+	registry.Register(vo.ProviderAnthropic, anthropic)
+
+	assert.Equal(t, []vo.Provider{vo.ProviderAnthropic, vo.ProviderOpenAI}, registry.Providers())
+
+	response, err := registry.CreateMessage(context.Background(), request(vo.ModelGPT55))
+	require.NoError(t, err)
+	assert.Equal(t, "openai", response.Content[0].Text)
+
+	response, err = registry.CreateMessage(context.Background(), request(vo.ModelClaudeSonnet46))
+	require.NoError(t, err)
+	assert.Equal(t, "anthropic", response.Content[0].Text)
+
+	count, err := registry.CountTokens(context.Background(), request(vo.ModelO3))
+	require.NoError(t, err)
+	assert.Equal(t, len("openai"), count)
+
+	events, err := registry.CreateMessageStream(context.Background(), request(vo.ModelClaudeOpus47))
+	require.NoError(t, err)
+	for range events {
+	}
+
+	assert.Equal(t, 2, openai.calls)
+	assert.Equal(t, 2, anthropic.calls)
+	assert.NoError(t, registry.ValidateRequest(request(vo.ModelGPT54)))
+}
+
+func TestRegistry_Errors(t *testing.T) {
+	registry := llm.NewRegistry()
+	registry.Register(vo.ProviderAnthropic, &namedBackend{name: "anthropic"})
+
+	_, err := registry.CreateMessage(context.Background(), request(vo.ModelGemini25Pro))
+	require.ErrorIs(t, err, llm.ErrProviderNotConfigured)
+	assert.Contains(t, err.Error(), "google")
+
+	_, err = registry.CreateMessageStream(context.Background(), request("llama3"))
+	assert.ErrorIs(t, err, llm.ErrUnknownModelProvider)
+
+	assert.ErrorIs(t, registry.ValidateRequest(nil), llm.ErrInvalidRequest)
+
+	// Models that cannot read an attachment are refused before the provider is called
+	registry.Register(vo.ProviderDeepSeek, &namedBackend{name: "deepseek"})
+	document, err := entities.NewMediaBlock(vo.MimeTypePDF, []byte("%PDF-1.7"), "report.pdf")
+	require.NoError(t, err)
+	req := request(vo.ModelDeepSeekChat)
+	req.Messages[0].Content = append([]entities.ContentBlock{document}, req.Messages[0].Content...)
+	_, err = registry.CreateMessage(context.Background(), req)
+	require.ErrorIs(t, err, vo.ErrUnsupportedInput)
+	assert.Contains(t, err.Error(), "deepseek-chat does not accept document input")
+	assert.ErrorIs(t, registry.ValidateRequest(req), vo.ErrUnsupportedInput)
+}
+
+func TestRegistry_DefaultModel(t *testing.T) {
+	openai := &namedBackend{name: "openai"}
+	registry := llm.NewRegistry()
+	registry.Register(vo.ProviderOpenAI, openai)
+	registry.SetDefaultModel(vo.ProviderOpenAI, vo.ModelGPT54)
+
+	model, ok := registry.DefaultModel(vo.ProviderOpenAI)
+	require.True(t, ok)
+	assert.Equal(t, vo.ModelGPT54, model)
+	assert.Equal(t, vo.ModelGPT54, registry.ResolveModel("openai"))
+	assert.Equal(t, vo.ModelO3, registry.ResolveModel(vo.ModelO3))
+
+	// A bare provider name is served by its default model, leaving the caller's request untouched
+	req := request("openai")
+	response, err := registry.CreateMessage(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, vo.ModelGPT54.String(), response.Model)
+	assert.Equal(t, vo.Model("openai"), req.Model)
+
+	_, err = registry.CreateMessage(context.Background(), request("anthropic"))
+	assert.ErrorIs(t, err, llm.ErrUnknownModelProvider)
+}
@@ -0,0 +1,182 @@
+package llm_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/llm"
+	"github.com/telemetryflow/telemetryflow-go-mcp/pkg/claude"
+)
+
+func statusError(code int) error {
+	return &llm.StatusError{StatusCode: code, Err: fmt.Errorf("HTTP %d", code)}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"rate limited", statusError(http.StatusTooManyRequests), true},
+		{"server error", statusError(http.StatusInternalServerError), true},
+		{"bad gateway", statusError(http.StatusBadGateway), true},
+		{"overloaded", statusError(llm.StatusCodeOverloaded), true},
+		{"request timeout", statusError(http.StatusRequestTimeout), true},
+		{"bad request", statusError(http.StatusBadRequest), false},
+		{"unauthorized", statusError(http.StatusUnauthorized), false},
+		{"not found", statusError(http.StatusNotFound), false},
+		{"unprocessable", statusError(http.StatusUnprocessableEntity), false},
+		{"wrapped status", fmt.Errorf("call: %w", statusError(http.StatusServiceUnavailable)), true},
+		{"claude overloaded", claude.NewAPIError("overloaded_error", "Overloaded", 529), true},
+		{"claude invalid request", claude.NewAPIError("invalid_request_error", "bad", 400), false},
+		{"claude type only", claude.NewAPIError("rate_limit_error", "slow down", 0), true},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"unexpected EOF", fmt.Errorf("reading: %w", io.ErrUnexpectedEOF), true},
+		{"deadline", context.DeadlineExceeded, true},
+		{"cancelled", context.Canceled, false},
+		{"validation", errors.New("invalid request: model is required"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, llm.IsRetryable(tt.err))
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	header := func(kv ...string) http.Header {
+		h := http.Header{}
+		for i := 0; i < len(kv); i += 2 {
+			h.Set(kv[i], kv[i+1])
+		}
+		return h
+	}
+
+	assert.Equal(t, time.Duration(0), llm.ParseRetryAfter(header(), now))
+	assert.Equal(t, 3*time.Second, llm.ParseRetryAfter(header("Retry-After", "3"), now))
+	assert.Equal(t, 1500*time.Millisecond, llm.ParseRetryAfter(header("Retry-After", "1.5"), now))
+	assert.Equal(t, 250*time.Millisecond, llm.ParseRetryAfter(header("Retry-After-Ms", "250", "Retry-After", "1"), now))
+	assert.Equal(t, 10*time.Second, llm.ParseRetryAfter(header("Retry-After", now.Add(10*time.Second).Format(http.TimeFormat)), now))
+	assert.Equal(t, time.Duration(0), llm.ParseRetryAfter(header("Retry-After", now.Add(-time.Minute).Format(http.TimeFormat)), now))
+	assert.Equal(t, time.Duration(0), llm.ParseRetryAfter(header("Retry-After", "soon"), now))
+
+	delay, ok := llm.RetryAfter(fmt.Errorf("call: %w", &llm.StatusError{StatusCode: 429, RetryAfter: time.Second, Err: errors.New("rate limited")}))
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, delay)
+	_, ok = llm.RetryAfter(statusError(http.StatusServiceUnavailable))
+	assert.False(t, ok)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := llm.RetryPolicy{InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 2}
+
+	for attempt, ceiling := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		5: time.Second,
+		9: time.Second,
+	} {
+		for i := 0; i < 20; i++ {
+			delay := policy.Backoff(attempt)
+			assert.GreaterOrEqual(t, delay, ceiling/2, "attempt %d", attempt)
+			assert.LessOrEqual(t, delay, ceiling, "attempt %d", attempt)
+		}
+	}
+
+	defaults := llm.NewRetryPolicy(3, 0)
+	assert.Equal(t, llm.DefaultRetryDelay, defaults.InitialDelay)
+	assert.Equal(t, llm.DefaultMaxRetryDelay, defaults.MaxDelay)
+	assert.Equal(t, llm.DefaultRetryMultiplier, defaults.Multiplier)
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	policy := llm.NewRetryPolicy(3, time.Millisecond)
+
+	t.Run("retries until success", func(t *testing.T) {
+		calls := 0
+		err := policy.Do(context.Background(), zerolog.Nop(), func() error {
+			calls++
+			if calls < 3 {
+				return statusError(llm.StatusCodeOverloaded)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		calls := 0
+		err := policy.Do(context.Background(), zerolog.Nop(), func() error {
+			calls++
+			return statusError(http.StatusServiceUnavailable)
+		})
+		require.Error(t, err)
+		assert.Equal(t, 4, calls)
+	})
+
+	t.Run("never retries validation errors", func(t *testing.T) {
+		calls := 0
+		err := policy.Do(context.Background(), zerolog.Nop(), func() error {
+			calls++
+			return statusError(http.StatusBadRequest)
+		})
+		require.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("waits for retry-after", func(t *testing.T) {
+		calls := 0
+		start := time.Now()
+		err := policy.Do(context.Background(), zerolog.Nop(), func() error {
+			calls++
+			if calls == 1 {
+				return &llm.StatusError{StatusCode: 429, RetryAfter: 50 * time.Millisecond, Err: errors.New("rate limited")}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+
+	t.Run("retry-after beyond the max delay ends retries", func(t *testing.T) {
+		calls := 0
+		short := llm.RetryPolicy{MaxRetries: 3, InitialDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond, Multiplier: 2}
+		err := short.Do(context.Background(), zerolog.Nop(), func() error {
+			calls++
+			return &llm.StatusError{StatusCode: 429, RetryAfter: time.Hour, Err: errors.New("rate limited")}
+		})
+		require.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("stops waiting when the context is cancelled", func(t *testing.T) {
+		slow := llm.NewRetryPolicy(3, time.Hour)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		calls := 0
+		start := time.Now()
+		err := slow.Do(ctx, zerolog.Nop(), func() error {
+			calls++
+			return statusError(http.StatusServiceUnavailable)
+		})
+		require.Error(t, err)
+		assert.Equal(t, 1, calls)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
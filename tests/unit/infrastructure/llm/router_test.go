@@ -0,0 +1,336 @@
+package llm_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/llm"
+)
+
+var errOverloaded = errors.New("API error: 529 overloaded")
+
+// flakyBackend fails, hangs or answers per model and records the models it was called with
+type flakyBackend struct {
+	mu     sync.Mutex
+	fail   map[vo.Model]error
+	hang   map[vo.Model]bool
+	called []vo.Model
+}
+
+func (b *flakyBackend) calls() []vo.Model {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]vo.Model(nil), b.called...)
+}
+
+func (b *flakyBackend) outcome(ctx context.Context, model vo.Model) error {
+	b.mu.Lock()
+	b.called = append(b.called, model)
+	err, hang := b.fail[model], b.hang[model]
+	b.mu.Unlock()
+	if hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return err
+}
+
+func (b *flakyBackend) CreateMessage(ctx context.Context, request *services.ClaudeRequest) (*services.ClaudeResponse, error) {
+	if err := b.outcome(ctx, request.Model); err != nil {
+		return nil, err
+	}
+	return &services.ClaudeResponse{
+		Model:   request.Model.String(),
+		Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: "from " + request.Model.String()}},
+		Usage:   &services.ClaudeUsage{InputTokens: 3, OutputTokens: 2},
+	}, nil
+}
+
+func (b *flakyBackend) CreateMessageStream(ctx context.Context, request *services.ClaudeRequest) (<-chan *services.ClaudeStreamEvent, error) {
+	err := b.outcome(ctx, request.Model)
+	events := make(chan *services.ClaudeStreamEvent, 3)
+	if err != nil {
+		events <- &services.ClaudeStreamEvent{Type: "error", Error: err}
+	} else {
+		events <- &services.ClaudeStreamEvent{
+			Type:    "message_start",
+			Message: &services.ClaudeResponse{Model: request.Model.String()},
+			Usage:   &services.ClaudeUsage{InputTokens: 3, CacheReadInputTokens: 40},
+		}
+		events <- &services.ClaudeStreamEvent{Type: "message_stop", Usage: &services.ClaudeUsage{OutputTokens: 2}}
+	}
+	close(events)
+	return events, nil
+}
+
+func (b *flakyBackend) CountTokens(ctx context.Context, request *services.ClaudeRequest) (int, error) {
+	return len(request.Model), nil
+}
+
+func (b *flakyBackend) ValidateRequest(request *services.ClaudeRequest) error {
+	return nil
+}
+
+func routingConfig(aliases map[string][]string) config.RoutingConfig {
+	cfg := config.DefaultConfig().Routing
+	cfg.Aliases = aliases
+	return cfg
+}
+
+var analystChain = map[string][]string{"analyst-default": {"claude-opus-4-7", "gemini-2.5-pro", "gpt-5.4"}}
+
+func TestRouter_FallbackChain(t *testing.T) {
+	backend := &flakyBackend{fail: map[vo.Model]error{vo.ModelClaudeOpus47: errOverloaded}}
+	router := llm.NewRouter(backend, routingConfig(analystChain), nil, zerolog.Nop())
+
+	assert.Equal(t, []vo.Model{"analyst-default"}, router.Aliases())
+	assert.Equal(t, []vo.Model{vo.ModelClaudeOpus47, vo.ModelGemini25Pro, vo.ModelGPT54}, router.Chain("analyst-default"))
+	assert.Equal(t, []vo.Model{vo.ModelO3}, router.Chain(vo.ModelO3))
+
+	req := request("analyst-default")
+	response, err := router.CreateMessage(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, vo.ModelGemini25Pro, response.ServedBy)
+	assert.Equal(t, "from gemini-2.5-pro", response.Content[0].Text)
+	assert.Equal(t, []vo.Model{vo.ModelClaudeOpus47, vo.ModelGemini25Pro}, backend.calls())
+	assert.Equal(t, vo.Model("analyst-default"), req.Model, "the caller's request is left untouched")
+
+	count, err := router.CountTokens(context.Background(), request("analyst-default"))
+	require.NoError(t, err)
+	assert.Equal(t, len(vo.ModelClaudeOpus47), count)
+}
+
+func TestRouter_AllModelsFail(t *testing.T) {
+	backend := &flakyBackend{fail: map[vo.Model]error{
+		vo.ModelClaudeOpus47: errOverloaded,
+		vo.ModelGemini25Pro:  errOverloaded,
+		vo.ModelGPT54:        errors.New("API error: 500"),
+	}}
+	router := llm.NewRouter(backend, routingConfig(analystChain), nil, zerolog.Nop())
+
+	_, err := router.CreateMessage(context.Background(), request("analyst-default"))
+	require.ErrorIs(t, err, llm.ErrAllModelsFailed)
+	assert.ErrorIs(t, err, errOverloaded)
+	assert.Contains(t, err.Error(), "gpt-5.4: API error: 500")
+
+	// A single model keeps its own error
+	_, err = router.CreateMessage(context.Background(), request(vo.ModelClaudeOpus47))
+	require.ErrorIs(t, err, errOverloaded)
+	assert.NotErrorIs(t, err, llm.ErrAllModelsFailed)
+}
+
+func TestRouter_AttemptTimeout(t *testing.T) {
+	backend := &flakyBackend{hang: map[vo.Model]bool{vo.ModelClaudeOpus47: true}}
+	cfg := routingConfig(analystChain)
+	cfg.AttemptTimeout = 20 * time.Millisecond
+	router := llm.NewRouter(backend, cfg, nil, zerolog.Nop())
+
+	response, err := router.CreateMessage(context.Background(), request("analyst-default"))
+	require.NoError(t, err)
+	assert.Equal(t, vo.ModelGemini25Pro, response.ServedBy)
+
+	// A caller that gives up is not failed over
+	backend = &flakyBackend{hang: map[vo.Model]bool{vo.ModelClaudeOpus47: true}}
+	router = llm.NewRouter(backend, routingConfig(analystChain), nil, zerolog.Nop())
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = router.CreateMessage(ctx, request("analyst-default"))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, []vo.Model{vo.ModelClaudeOpus47}, backend.calls())
+	assert.Equal(t, 0, router.Health()[vo.ModelClaudeOpus47].Failures)
+}
+
+func TestRouter_CircuitBreaker(t *testing.T) {
+	backend := &flakyBackend{fail: map[vo.Model]error{vo.ModelClaudeOpus47: errOverloaded}}
+	cfg := routingConfig(analystChain)
+	cfg.CircuitBreaker.MinRequests = 2
+	cfg.CircuitBreaker.Cooldown = 50 * time.Millisecond
+	router := llm.NewRouter(backend, cfg, nil, zerolog.Nop())
+
+	for i := 0; i < 2; i++ {
+		_, err := router.CreateMessage(context.Background(), request(vo.ModelClaudeOpus47))
+		require.ErrorIs(t, err, errOverloaded)
+	}
+	assert.Equal(t, llm.BreakerOpen, router.Health()[vo.ModelClaudeOpus47].State)
+
+	// An open breaker fails fast for the model and is skipped in chains
+	_, err := router.CreateMessage(context.Background(), request(vo.ModelClaudeOpus47))
+	require.ErrorIs(t, err, llm.ErrCircuitOpen)
+	response, err := router.CreateMessage(context.Background(), request("analyst-default"))
+	require.NoError(t, err)
+	assert.Equal(t, vo.ModelGemini25Pro, response.ServedBy)
+	assert.Len(t, backend.calls(), 3)
+
+	// After the cooldown one trial call is let through, and its success closes the breaker
+	time.Sleep(60 * time.Millisecond)
+	backend.mu.Lock()
+	backend.fail = nil
+	backend.mu.Unlock()
+	response, err = router.CreateMessage(context.Background(), request("analyst-default"))
+	require.NoError(t, err)
+	assert.Equal(t, vo.ModelClaudeOpus47, response.ServedBy)
+	assert.Equal(t, llm.BreakerClosed, router.Health()[vo.ModelClaudeOpus47].State)
+}
+
+func TestRouter_LatencyThreshold(t *testing.T) {
+	backend := &flakyBackend{}
+	cfg := routingConfig(nil)
+	cfg.CircuitBreaker.MinRequests = 1
+	cfg.CircuitBreaker.LatencyThreshold = time.Nanosecond
+	router := llm.NewRouter(backend, cfg, nil, zerolog.Nop())
+
+	// Answers slower than the threshold count against the model even when they succeed
+	_, err := router.CreateMessage(context.Background(), request(vo.ModelO3))
+	require.NoError(t, err)
+	assert.Equal(t, llm.BreakerOpen, router.Health()[vo.ModelO3].State)
+}
+
+func TestRouter_SkipsUnconfiguredProviders(t *testing.T) {
+	registry := llm.NewRegistry()
+	registry.Register(vo.ProviderOpenAI, &namedBackend{name: "openai"})
+	router := llm.NewRouter(registry, routingConfig(analystChain), nil, zerolog.Nop())
+
+	response, err := router.CreateMessage(context.Background(), request("analyst-default"))
+	require.NoError(t, err)
+	assert.Equal(t, vo.ModelGPT54, response.ServedBy)
+
+	// Missing providers are a configuration problem, not a health signal
+	assert.Equal(t, 0, router.Health()[vo.ModelClaudeOpus47].Failures)
+	assert.Equal(t, llm.BreakerClosed, router.Health()[vo.ModelGemini25Pro].State)
+}
+
+func TestRouter_SkipsModelsWithoutInput(t *testing.T) {
+	registry := llm.NewRegistry()
+	registry.Register(vo.ProviderDeepSeek, &namedBackend{name: "deepseek"})
+	registry.Register(vo.ProviderOpenAI, &namedBackend{name: "openai"})
+	router := llm.NewRouter(registry, routingConfig(map[string][]string{"vision": {"deepseek-chat", "gpt-5.4"}}), nil, zerolog.Nop())
+
+	image, err := entities.NewMediaBlock(vo.MimeTypePNG, []byte("\x89PNG"), "")
+	require.NoError(t, err)
+	req := request("vision")
+	req.Messages[0].Content = append([]entities.ContentBlock{image}, req.Messages[0].Content...)
+	response, err := router.CreateMessage(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, vo.ModelGPT54, response.ServedBy)
+
+	// A model that cannot read the input is not a health signal either
+	assert.Equal(t, 0, router.Health()[vo.ModelDeepSeekChat].Failures)
+}
+
+func TestRouter_Stream(t *testing.T) {
+	backend := &flakyBackend{fail: map[vo.Model]error{vo.ModelClaudeOpus47: errOverloaded}}
+	router := llm.NewRouter(backend, routingConfig(analystChain), nil, zerolog.Nop())
+
+	events, err := router.CreateMessageStream(context.Background(), request("analyst-default"))
+	require.NoError(t, err)
+
+	var types []string
+	var served vo.Model
+	for event := range events {
+		require.NoError(t, event.Error)
+		types = append(types, event.Type)
+		if event.Type == "message_start" {
+			served = event.Message.ServedBy
+		}
+	}
+	assert.Equal(t, []string{"message_start", "message_stop"}, types)
+	assert.Equal(t, vo.ModelGemini25Pro, served)
+
+	backend.fail[vo.ModelGemini25Pro] = errOverloaded
+	backend.fail[vo.ModelGPT54] = errOverloaded
+	_, err = router.CreateMessageStream(context.Background(), request("analyst-default"))
+	assert.ErrorIs(t, err, llm.ErrAllModelsFailed)
+}
+
+// usageLog keeps the usage records passed to it
+type usageLog struct {
+	mu      sync.Mutex
+	records []*entities.LLMUsage
+}
+
+func (l *usageLog) RecordUsage(ctx context.Context, usage *entities.LLMUsage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, usage)
+}
+
+func (l *usageLog) all() []*entities.LLMUsage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]*entities.LLMUsage(nil), l.records...)
+}
+
+func TestRouter_RecordsUsage(t *testing.T) {
+	backend := &flakyBackend{fail: map[vo.Model]error{vo.ModelClaudeOpus47: &llm.StatusError{StatusCode: 529, Err: errOverloaded}}}
+	router := llm.NewRouter(backend, routingConfig(analystChain), nil, zerolog.Nop())
+	log := &usageLog{}
+	router.SetUsageRecorder(log)
+
+	ctx := entities.WithUsageScope(context.Background(), entities.UsageScope{SessionID: "s1", ConversationID: "c1", OrganizationID: "acme"})
+	_, err := router.CreateMessage(ctx, request("analyst-default"))
+	require.NoError(t, err)
+
+	// Every attempt is metered, including the one that failed over
+	records := log.all()
+	require.Len(t, records, 2)
+	failed, served := records[0], records[1]
+	assert.Equal(t, vo.ModelClaudeOpus47, failed.Model)
+	assert.Equal(t, entities.LLMCallError, failed.Status)
+	assert.Equal(t, 529, failed.StatusCode)
+	assert.Contains(t, failed.ErrorMessage, "overloaded")
+
+	assert.Equal(t, vo.Model("analyst-default"), served.RequestedModel)
+	assert.Equal(t, vo.ModelGemini25Pro, served.Model)
+	assert.Equal(t, vo.ProviderGoogle, served.Provider)
+	assert.Equal(t, entities.LLMCallSuccess, served.Status)
+	assert.Equal(t, 200, served.StatusCode)
+	assert.Equal(t, 3, served.InputTokens)
+	assert.Equal(t, 2, served.OutputTokens)
+	assert.False(t, served.Streaming)
+	assert.Equal(t, "s1", served.SessionID)
+	assert.Equal(t, "c1", served.ConversationID)
+	assert.Equal(t, "acme", served.OrganizationID)
+}
+
+func TestRouter_RecordsStreamUsage(t *testing.T) {
+	backend := &flakyBackend{}
+	router := llm.NewRouter(backend, routingConfig(nil), nil, zerolog.Nop())
+	log := &usageLog{}
+	router.SetUsageRecorder(log)
+
+	events, err := router.CreateMessageStream(context.Background(), request(vo.ModelClaudeSonnet46))
+	require.NoError(t, err)
+	for range events {
+	}
+
+	// The usage reported across the stream's events is merged into one record
+	records := log.all()
+	require.Len(t, records, 1)
+	assert.True(t, records[0].Streaming)
+	assert.Equal(t, vo.ModelClaudeSonnet46, records[0].Model)
+	assert.Equal(t, 3, records[0].InputTokens)
+	assert.Equal(t, 40, records[0].CacheReadTokens)
+	assert.Equal(t, 2, records[0].OutputTokens)
+	assert.Equal(t, entities.LLMCallSuccess, records[0].Status)
+
+	// A caller that gives up is recorded as cancelled
+	backend.hang = map[vo.Model]bool{vo.ModelO3: true}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = router.CreateMessage(ctx, request(vo.ModelO3))
+	require.ErrorIs(t, err, context.Canceled)
+	records = log.all()
+	require.Len(t, records, 2)
+	assert.Equal(t, entities.LLMCallCancelled, records[1].Status)
+}
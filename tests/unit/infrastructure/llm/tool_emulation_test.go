@@ -0,0 +1,367 @@
+package llm_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/llm"
+)
+
+// scriptedBackend answers with fixed text and records the last request it received. Streams
+// send the answer in chunk-sized deltas, after a thinking block when thinking is set, and
+// wait for gate, if set, after the first delta
+type scriptedBackend struct {
+	answer   string
+	thinking string
+	chunk    int
+	gate     chan struct{}
+	last     *services.ClaudeRequest
+	tools    map[vo.Model]bool
+	toolsErr error
+	models   []vo.Model
+}
+
+func (b *scriptedBackend) CreateMessage(ctx context.Context, request *services.ClaudeRequest) (*services.ClaudeResponse, error) {
+	b.last = request
+	return &services.ClaudeResponse{
+		ID:         "msg_1",
+		Model:      request.Model.String(),
+		Content:    []entities.ContentBlock{{Type: vo.ContentTypeText, Text: b.answer}},
+		StopReason: "end_turn",
+		Usage:      &services.ClaudeUsage{InputTokens: 10, OutputTokens: 5},
+	}, nil
+}
+
+func (b *scriptedBackend) CreateMessageStream(ctx context.Context, request *services.ClaudeRequest) (<-chan *services.ClaudeStreamEvent, error) {
+	b.last = request
+	chunk := b.chunk
+	if chunk == 0 {
+		chunk = 5
+	}
+	events := make(chan *services.ClaudeStreamEvent)
+	go func() {
+		defer close(events)
+		events <- &services.ClaudeStreamEvent{Type: "message_start", Message: &services.ClaudeResponse{ID: "msg_1", Type: "message", Role: vo.RoleAssistant}}
+		index := 0
+		if b.thinking != "" {
+			events <- &services.ClaudeStreamEvent{Type: "content_block_start", Index: 0, ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeThinking}}
+			events <- &services.ClaudeStreamEvent{Type: "content_block_delta", Index: 0, Delta: &services.ClaudeDelta{Type: "thinking_delta", Thinking: b.thinking}}
+			events <- &services.ClaudeStreamEvent{Type: "content_block_stop", Index: 0}
+			index = 1
+		}
+		events <- &services.ClaudeStreamEvent{Type: "content_block_start", Index: index, ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeText}}
+		for i := 0; i < len(b.answer); i += chunk {
+			events <- &services.ClaudeStreamEvent{Type: "content_block_delta", Index: index, Delta: &services.ClaudeDelta{Type: "text_delta", Text: b.answer[i:min(i+chunk, len(b.answer))]}}
+			if i == 0 && b.gate != nil {
+				<-b.gate
+			}
+		}
+		events <- &services.ClaudeStreamEvent{Type: "content_block_stop", Index: index}
+		events <- &services.ClaudeStreamEvent{Type: "message_delta", Delta: &services.ClaudeDelta{StopReason: "end_turn"}, Usage: &services.ClaudeUsage{InputTokens: 10, OutputTokens: 5}}
+		events <- &services.ClaudeStreamEvent{Type: "message_stop"}
+	}()
+	return events, nil
+}
+
+func (b *scriptedBackend) CountTokens(ctx context.Context, request *services.ClaudeRequest) (int, error) {
+	b.last = request
+	return len(request.SystemPrompt.String()), nil
+}
+
+func (b *scriptedBackend) ValidateRequest(request *services.ClaudeRequest) error {
+	return nil
+}
+
+func (b *scriptedBackend) SupportsTools(ctx context.Context, model vo.Model) (bool, error) {
+	return b.tools[model], b.toolsErr
+}
+
+func (b *scriptedBackend) ListModels(ctx context.Context) ([]vo.Model, error) {
+	return b.models, nil
+}
+
+func toolRequest(model vo.Model) *services.ClaudeRequest {
+	systemPrompt, _ := vo.NewSystemPrompt("You are an SRE assistant")
+	return &services.ClaudeRequest{
+		Model:        model,
+		SystemPrompt: systemPrompt,
+		Messages: []services.ClaudeMessage{
+			{Role: vo.RoleUser, Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: "Why is checkout slow?"}}},
+			{Role: vo.RoleAssistant, Content: []entities.ContentBlock{
+				{Type: vo.ContentTypeToolUse, ID: "call_1", Name: "query_metrics", Input: map[string]interface{}{"service": "checkout"}},
+			}},
+			{Role: vo.RoleUser, Content: []entities.ContentBlock{
+				{Type: vo.ContentTypeToolResult, ToolUseID: "call_1", Content: "p99 latency 2.3s"},
+			}},
+		},
+		Tools: []services.ClaudeTool{
+			{Name: "query_metrics", Description: "Query service metrics", InputSchema: &entities.JSONSchema{Type: "object"}},
+			{Name: "query_logs", Description: "Search logs"},
+		},
+	}
+}
+
+func TestToolEmulator_RewritesRequest(t *testing.T) {
+	backend := &scriptedBackend{answer: "The payment service is slow."}
+	emulator := llm.WithToolCalling(backend, llm.ToolCallingEmulated)
+
+	resp, err := emulator.CreateMessage(context.Background(), toolRequest("ollama/gemma2:2b"))
+	require.NoError(t, err)
+	assert.Equal(t, "end_turn", resp.StopReason)
+	assert.Equal(t, "The payment service is slow.", resp.Content[0].Text)
+
+	sent := backend.last
+	assert.Empty(t, sent.Tools)
+	assert.Contains(t, sent.SystemPrompt.String(), "You are an SRE assistant")
+	assert.Contains(t, sent.SystemPrompt.String(), `"tool_calls"`)
+	assert.Contains(t, sent.SystemPrompt.String(), "- query_logs: Search logs")
+	require.Len(t, sent.Messages, 3)
+	assert.Equal(t, `{"tool_calls":[{"name":"query_metrics","arguments":{"service":"checkout"}}]}`, sent.Messages[1].Content[0].Text)
+	assert.Equal(t, "Result of tool query_metrics:\np99 latency 2.3s", sent.Messages[2].Content[0].Text)
+	for _, msg := range sent.Messages {
+		require.Len(t, msg.Content, 1)
+		assert.Equal(t, vo.ContentTypeText, msg.Content[0].Type)
+	}
+}
+
+func TestToolEmulator_ParsesToolCalls(t *testing.T) {
+	tests := []struct {
+		name   string
+		answer string
+		calls  []string
+		prefix string
+	}{
+		{"bare object", `{"tool_calls": [{"name": "query_logs", "arguments": {"level": "error"}}]}`, []string{"query_logs"}, ""},
+		{"fenced block", "I will look at the logs.\n```json\n{\"tool_calls\": [{\"name\": \"query_logs\"}, {\"name\": \"query_metrics\", \"arguments\": {}}]}\n```", []string{"query_logs", "query_metrics"}, "I will look at the logs."},
+		{"unknown tool", `{"tool_calls": [{"name": "rm_rf"}]}`, nil, ""},
+		{"plain answer", "Nothing to call.", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emulator := llm.WithToolCalling(&scriptedBackend{answer: tt.answer}, llm.ToolCallingEmulated)
+
+			resp, err := emulator.CreateMessage(context.Background(), toolRequest("local/llama-3.1-8b"))
+			require.NoError(t, err)
+
+			if tt.calls == nil {
+				assert.Equal(t, "end_turn", resp.StopReason)
+				assert.Equal(t, tt.answer, resp.Content[0].Text)
+				return
+			}
+			assert.Equal(t, "tool_use", resp.StopReason)
+			var names []string
+			for _, block := range resp.Content {
+				switch block.Type {
+				case vo.ContentTypeToolUse:
+					names = append(names, block.Name)
+					assert.NotEmpty(t, block.ID)
+					assert.NotNil(t, block.Input)
+				case vo.ContentTypeText:
+					assert.Equal(t, tt.prefix, block.Text)
+				}
+			}
+			assert.Equal(t, tt.calls, names)
+		})
+	}
+}
+
+func TestToolEmulator_Stream(t *testing.T) {
+	backend := &scriptedBackend{answer: `{"tool_calls": [{"name": "query_logs", "arguments": {"level": "error"}}]}`}
+	emulator := llm.WithToolCalling(backend, llm.ToolCallingEmulated)
+
+	events, err := emulator.CreateMessageStream(context.Background(), toolRequest("ollama/gemma2:2b"))
+	require.NoError(t, err)
+
+	var types []string
+	var stopReason, arguments string
+	for event := range events {
+		types = append(types, event.Type)
+		if event.Type == "content_block_delta" {
+			arguments += event.Delta.PartialJSON
+		}
+		if event.Type == "message_delta" {
+			stopReason = event.Delta.StopReason
+		}
+	}
+	assert.Equal(t, []string{"message_start", "content_block_start", "content_block_delta", "content_block_stop", "message_delta", "message_stop"}, types)
+	assert.Equal(t, `{"level":"error"}`, arguments)
+	assert.Equal(t, "tool_use", stopReason)
+}
+
+// streamedAnswer collects an emulated stream
+type streamedAnswer struct {
+	texts      []string
+	blocks     []vo.ContentType
+	calls      []string
+	stopReason string
+}
+
+func collectStream(t *testing.T, events <-chan *services.ClaudeStreamEvent) streamedAnswer {
+	t.Helper()
+	var answer streamedAnswer
+	for event := range events {
+		require.NoError(t, event.Error)
+		switch event.Type {
+		case "content_block_start":
+			require.Equal(t, len(answer.blocks), event.Index)
+			answer.blocks = append(answer.blocks, event.ContentBlock.Type)
+			if event.ContentBlock.Type == vo.ContentTypeToolUse {
+				answer.calls = append(answer.calls, event.ContentBlock.Name)
+			}
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" {
+				answer.texts = append(answer.texts, event.Delta.Text)
+			}
+		case "message_delta":
+			answer.stopReason = event.Delta.StopReason
+		}
+	}
+	return answer
+}
+
+func TestToolEmulator_StreamsText(t *testing.T) {
+	fenced := "I will look at the logs.\n```json\n{\"tool_calls\": [{\"name\": \"query_logs\"}]}\n```"
+	tests := []struct {
+		name     string
+		backend  *scriptedBackend
+		text     string
+		calls    []string
+		blocks   []vo.ContentType
+		streamed bool
+	}{
+		{
+			name:     "plain answer",
+			backend:  &scriptedBackend{answer: "The payment service is slow since the 14:00 deploy."},
+			text:     "The payment service is slow since the 14:00 deploy.",
+			blocks:   []vo.ContentType{vo.ContentTypeText},
+			streamed: true,
+		},
+		{
+			name:     "fenced call after text",
+			backend:  &scriptedBackend{answer: fenced, thinking: "Logs first."},
+			text:     "I will look at the logs.\n",
+			calls:    []string{"query_logs"},
+			blocks:   []vo.ContentType{vo.ContentTypeThinking, vo.ContentTypeText, vo.ContentTypeToolUse},
+			streamed: true,
+		},
+		{
+			name:     "code block followed by text",
+			backend:  &scriptedBackend{answer: "Run:\n```sh\nkubectl get pods\n```\nThen check the restarts."},
+			text:     "Run:\n```sh\nkubectl get pods\n```\nThen check the restarts.",
+			blocks:   []vo.ContentType{vo.ContentTypeText},
+			streamed: true,
+		},
+		{
+			name:    "unknown tool",
+			backend: &scriptedBackend{answer: `{"tool_calls": [{"name": "rm_rf"}]}`},
+			text:    `{"tool_calls": [{"name": "rm_rf"}]}`,
+			blocks:  []vo.ContentType{vo.ContentTypeText},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emulator := llm.WithToolCalling(tt.backend, llm.ToolCallingEmulated)
+			events, err := emulator.CreateMessageStream(context.Background(), toolRequest("ollama/gemma2:2b"))
+			require.NoError(t, err)
+
+			answer := collectStream(t, events)
+			assert.Equal(t, tt.text, strings.Join(answer.texts, ""))
+			assert.Equal(t, tt.blocks, answer.blocks)
+			assert.Equal(t, tt.calls, answer.calls)
+			assert.Equal(t, tt.streamed, len(answer.texts) > 1, "text deltas: %q", answer.texts)
+			if tt.calls != nil {
+				assert.Equal(t, "tool_use", answer.stopReason)
+			} else {
+				assert.Equal(t, "end_turn", answer.stopReason)
+			}
+		})
+	}
+}
+
+func TestToolEmulator_StreamDeliversTextBeforeAnswerEnds(t *testing.T) {
+	gate := make(chan struct{})
+	backend := &scriptedBackend{answer: "Checkout latency doubled.", gate: gate}
+	emulator := llm.WithToolCalling(backend, llm.ToolCallingEmulated)
+
+	events, err := emulator.CreateMessageStream(context.Background(), toolRequest("ollama/gemma2:2b"))
+	require.NoError(t, err)
+
+	// The backend waits after its first delta, so the text must arrive before the answer ends
+	timeout := time.After(5 * time.Second)
+	for first := true; first; {
+		select {
+		case event := <-events:
+			first = event.Type != "content_block_delta"
+			if !first {
+				assert.Equal(t, "Check", event.Delta.Text)
+			}
+		case <-timeout:
+			t.Fatal("no text before the answer ended")
+		}
+	}
+	close(gate)
+	assert.Equal(t, "out latency doubled.", strings.Join(collectStream(t, events).texts, ""))
+}
+
+func TestToolEmulator_StreamHeldTextLimit(t *testing.T) {
+	answer := `{"report": "` + strings.Repeat("x", 3<<20) + `"}`
+	backend := &scriptedBackend{answer: answer, chunk: 64 << 10}
+	emulator := llm.WithToolCalling(backend, llm.ToolCallingEmulated)
+
+	events, err := emulator.CreateMessageStream(context.Background(), toolRequest("ollama/gemma2:2b"))
+	require.NoError(t, err)
+
+	result := collectStream(t, events)
+	assert.Equal(t, answer, strings.Join(result.texts, ""))
+	assert.Greater(t, len(result.texts), 1, "text past the limit streams instead of being held")
+	assert.Equal(t, "end_turn", result.stopReason)
+}
+
+func TestWithToolCalling_Modes(t *testing.T) {
+	model := vo.Model("ollama/qwen3:8b")
+	backend := &scriptedBackend{answer: "ok", tools: map[vo.Model]bool{model: true}}
+
+	// Auto uses native tool calling for models that support it
+	_, err := llm.WithToolCalling(backend, "").CreateMessage(context.Background(), toolRequest(model))
+	require.NoError(t, err)
+	assert.Len(t, backend.last.Tools, 2)
+
+	// ... and emulates for the others
+	_, err = llm.WithToolCalling(backend, llm.ToolCallingAuto).CreateMessage(context.Background(), toolRequest("ollama/gemma2:2b"))
+	require.NoError(t, err)
+	assert.Empty(t, backend.last.Tools)
+
+	// Unknown support is left to the backend
+	backend.toolsErr = errors.New("unreachable")
+	_, err = llm.WithToolCalling(backend, llm.ToolCallingAuto).CreateMessage(context.Background(), toolRequest("ollama/gemma2:2b"))
+	require.NoError(t, err)
+	assert.Len(t, backend.last.Tools, 2)
+
+	assert.Same(t, backend, llm.WithToolCalling(backend, llm.ToolCallingNative))
+
+	// Requests without tools pass through unchanged
+	plain := request("ollama/gemma2:2b")
+	_, err = llm.WithToolCalling(backend, llm.ToolCallingEmulated).CreateMessage(context.Background(), plain)
+	require.NoError(t, err)
+	assert.Same(t, plain, backend.last)
+}
+
+func TestRegistry_LocalModels(t *testing.T) {
+	registry := llm.NewRegistry()
+	registry.Register(vo.ProviderOllama, llm.WithToolCalling(&scriptedBackend{models: []vo.Model{"ollama/qwen3:8b"}}, llm.ToolCallingEmulated))
+	registry.Register(vo.ProviderLocal, &scriptedBackend{models: []vo.Model{"local/llama-3.1-8b"}})
+	registry.Register(vo.ProviderOpenAI, &scriptedBackend{models: []vo.Model{"gpt-4o"}})
+
+	models, err := registry.LocalModels(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []vo.Model{"local/llama-3.1-8b", "ollama/qwen3:8b"}, models)
+}
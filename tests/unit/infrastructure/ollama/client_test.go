@@ -0,0 +1,285 @@
+package ollama_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/ollama"
+)
+
+// ollamaServer is a stand-in Ollama server answering each path with a fixed response
+type ollamaServer struct {
+	*httptest.Server
+	bodies    map[string]map[string]interface{}
+	requests  map[string]int
+	responses map[string]string
+}
+
+func newOllamaServer(t *testing.T, responses map[string]string) *ollamaServer {
+	t.Helper()
+	s := &ollamaServer{bodies: map[string]map[string]interface{}{}, requests: map[string]int{}, responses: responses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]interface{}
+		_ = json.Unmarshal(data, &body)
+		s.bodies[r.URL.Path] = body
+		s.requests[r.URL.Path]++
+
+		response, ok := s.responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"error":"model 'missing' not found"}`)
+			return
+		}
+		_, _ = io.WriteString(w, response)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestClient(srv *ollamaServer) *ollama.Client {
+	return ollama.NewClient(config.ProviderConfig{BaseURL: srv.URL + "/"}, zerolog.Nop())
+}
+
+// toolTurnRequest is a conversation where the model called a tool and got its result
+func toolTurnRequest() *services.ClaudeRequest {
+	systemPrompt, _ := vo.NewSystemPrompt("You are an SRE assistant")
+	return &services.ClaudeRequest{
+		Model:        vo.LocalModel(vo.ProviderOllama, "qwen3:8b"),
+		SystemPrompt: systemPrompt,
+		MaxTokens:    512,
+		Temperature:  0.2,
+		Messages: []services.ClaudeMessage{
+			{Role: vo.RoleUser, Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: "Why is checkout slow?"}}},
+			{Role: vo.RoleAssistant, Content: []entities.ContentBlock{
+				{Type: vo.ContentTypeToolUse, ID: "call_1", Name: "query_metrics", Input: map[string]interface{}{"service": "checkout"}},
+			}},
+			{Role: vo.RoleUser, Content: []entities.ContentBlock{
+				{Type: vo.ContentTypeToolResult, ToolUseID: "call_1", Content: "p99 latency 2.3s"},
+			}},
+		},
+		Tools: []services.ClaudeTool{{
+			Name:        "query_metrics",
+			Description: "Query service metrics",
+			InputSchema: &entities.JSONSchema{Type: "object", Properties: map[string]*entities.JSONSchema{"service": {Type: "string"}}},
+		}},
+	}
+}
+
+func TestClient_CreateMessage(t *testing.T) {
+	srv := newOllamaServer(t, map[string]string{"/api/chat": `{
+		"model": "qwen3:8b",
+		"message": {"role": "assistant", "content": "Checking logs.", "tool_calls": [{"function": {"name": "query_logs", "arguments": {"level": "error"}}}]},
+		"done": true, "done_reason": "stop", "prompt_eval_count": 80, "eval_count": 21
+	}`})
+	client := newTestClient(srv)
+
+	resp, err := client.CreateMessage(context.Background(), toolTurnRequest())
+	require.NoError(t, err)
+
+	body := srv.bodies["/api/chat"]
+	assert.Equal(t, "qwen3:8b", body["model"])
+	assert.Equal(t, false, body["stream"])
+	assert.NotContains(t, body, "think")
+	assert.Equal(t, map[string]interface{}{"num_predict": float64(512), "temperature": 0.2}, body["options"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"role": "system", "content": "You are an SRE assistant"},
+		map[string]interface{}{"role": "user", "content": "Why is checkout slow?"},
+		map[string]interface{}{"role": "assistant", "content": "", "tool_calls": []interface{}{
+			map[string]interface{}{"function": map[string]interface{}{"name": "query_metrics", "arguments": map[string]interface{}{"service": "checkout"}}},
+		}},
+		map[string]interface{}{"role": "tool", "content": "p99 latency 2.3s", "tool_name": "query_metrics"},
+	}, body["messages"])
+	require.Len(t, body["tools"], 1)
+
+	assert.Equal(t, "ollama/qwen3:8b", resp.Model)
+	assert.Equal(t, "tool_use", resp.StopReason)
+	require.Len(t, resp.Content, 2)
+	assert.Equal(t, "Checking logs.", resp.Content[0].Text)
+	assert.Equal(t, "query_logs", resp.Content[1].Name)
+	assert.NotEmpty(t, resp.Content[1].ID)
+	assert.Equal(t, map[string]interface{}{"level": "error"}, resp.Content[1].Input)
+	assert.Equal(t, 80, resp.Usage.InputTokens)
+	assert.Equal(t, 21, resp.Usage.OutputTokens)
+}
+
+func TestClient_CreateMessage_Thinking(t *testing.T) {
+	srv := newOllamaServer(t, map[string]string{"/api/chat": `{
+		"model": "qwen3:8b",
+		"message": {"role": "assistant", "thinking": "Latency rose after the deploy.", "content": "Roll back the deploy."},
+		"done": true, "done_reason": "stop", "prompt_eval_count": 80, "eval_count": 60
+	}`})
+	client := newTestClient(srv)
+
+	request := toolTurnRequest()
+	request.Reasoning = &vo.Reasoning{Effort: vo.ReasoningEffortLow}
+	request.Messages[1].Content = append([]entities.ContentBlock{{Type: vo.ContentTypeThinking, Thinking: "Metrics first."}}, request.Messages[1].Content...)
+	resp, err := client.CreateMessage(context.Background(), request)
+	require.NoError(t, err)
+
+	body := srv.bodies["/api/chat"]
+	assert.Equal(t, true, body["think"])
+	assert.Equal(t, float64(512+2048), body["options"].(map[string]interface{})["num_predict"])
+	assistant := body["messages"].([]interface{})[2].(map[string]interface{})
+	assert.Equal(t, "Metrics first.", assistant["thinking"], "thinking behind tool calls is sent back")
+
+	require.Len(t, resp.Content, 2)
+	assert.Equal(t, vo.ContentTypeThinking, resp.Content[0].Type)
+	assert.Equal(t, "Latency rose after the deploy.", resp.Content[0].Thinking)
+	assert.Equal(t, "Roll back the deploy.", resp.Content[1].Text)
+}
+
+func TestClient_CreateMessage_Images(t *testing.T) {
+	srv := newOllamaServer(t, map[string]string{
+		"/api/show": `{"capabilities": ["completion", "vision"]}`,
+		"/api/chat": `{"model": "llava", "message": {"role": "assistant", "content": "A latency chart."}, "done": true, "done_reason": "stop"}`,
+	})
+	client := newTestClient(srv)
+
+	image, err := entities.NewMediaBlock(vo.MimeTypePNG, []byte("\x89PNG"), "")
+	require.NoError(t, err)
+	request := &services.ClaudeRequest{
+		Model:     vo.LocalModel(vo.ProviderOllama, "llava"),
+		MaxTokens: 512,
+		Messages: []services.ClaudeMessage{{Role: vo.RoleUser, Content: []entities.ContentBlock{
+			image, {Type: vo.ContentTypeText, Text: "What is this?"},
+		}}},
+	}
+	_, err = client.CreateMessage(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"role": "user", "content": "What is this?", "images": []interface{}{"iVBORw=="}},
+	}, srv.bodies["/api/chat"]["messages"])
+
+	// Models without vision and documents are refused before the chat call
+	srv.responses["/api/show"] = `{"capabilities": ["completion"]}`
+	request.Model = vo.LocalModel(vo.ProviderOllama, "qwen3:8b")
+	_, err = client.CreateMessage(context.Background(), request)
+	assert.ErrorIs(t, err, vo.ErrUnsupportedInput)
+
+	document, err := entities.NewMediaBlock(vo.MimeTypePDF, []byte("%PDF-1.7"), "report.pdf")
+	require.NoError(t, err)
+	request.Model = vo.LocalModel(vo.ProviderOllama, "llava")
+	request.Messages[0].Content[0] = document
+	_, err = client.CreateMessage(context.Background(), request)
+	assert.ErrorIs(t, err, vo.ErrUnsupportedInput)
+	assert.Equal(t, 1, srv.requests["/api/chat"])
+}
+
+func TestClient_CreateMessage_Errors(t *testing.T) {
+	srv := newOllamaServer(t, map[string]string{})
+	client := newTestClient(srv)
+
+	_, err := client.CreateMessage(context.Background(), toolTurnRequest())
+	require.ErrorIs(t, err, ollama.ErrModelNotFound)
+	assert.Contains(t, err.Error(), "model 'missing' not found")
+
+	assert.ErrorIs(t, client.ValidateRequest(nil), ollama.ErrInvalidRequest)
+	request := toolTurnRequest()
+	request.Model = vo.ModelGPT54
+	assert.ErrorIs(t, client.ValidateRequest(request), ollama.ErrInvalidRequest)
+
+	request = toolTurnRequest()
+	request.MaxTokens = 0
+	require.NoError(t, client.ValidateRequest(request))
+	assert.Equal(t, ollama.DefaultMaxTokens, request.MaxTokens)
+}
+
+func TestClient_CreateMessageStream(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"model":"qwen3:8b","message":{"role":"assistant","content":"Check"},"done":false}`,
+		`{"model":"qwen3:8b","message":{"role":"assistant","content":"ing."},"done":false}`,
+		`{"model":"qwen3:8b","message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"query_logs","arguments":{"level":"error"}}}]},"done":false}`,
+		`{"model":"qwen3:8b","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":42,"eval_count":17}`,
+	}, "\n")
+	srv := newOllamaServer(t, map[string]string{"/api/chat": stream})
+	client := newTestClient(srv)
+
+	events, err := client.CreateMessageStream(context.Background(), toolTurnRequest())
+	require.NoError(t, err)
+
+	var collected []*services.ClaudeStreamEvent
+	for event := range events {
+		require.NoError(t, event.Error)
+		collected = append(collected, event)
+	}
+	assert.Equal(t, true, srv.bodies["/api/chat"]["stream"])
+
+	types := make([]string, 0, len(collected))
+	for _, e := range collected {
+		types = append(types, e.Type)
+	}
+	assert.Equal(t, []string{
+		"message_start",
+		"content_block_start", "content_block_delta", "content_block_delta",
+		"content_block_start", "content_block_delta",
+		"content_block_stop", "content_block_stop",
+		"message_delta", "message_stop",
+	}, types)
+	assert.Equal(t, "ing.", collected[3].Delta.Text)
+	assert.Equal(t, "query_logs", collected[4].ContentBlock.Name)
+	assert.Equal(t, `{"level":"error"}`, collected[5].Delta.PartialJSON)
+	assert.Equal(t, "tool_use", collected[8].Delta.StopReason)
+	assert.Equal(t, 42, collected[8].Usage.InputTokens)
+	assert.Equal(t, 17, collected[8].Usage.OutputTokens)
+}
+
+func TestClient_CreateMessageStream_Error(t *testing.T) {
+	srv := newOllamaServer(t, map[string]string{"/api/chat": "{\"message\":{\"content\":\"Hi\"}}\n{\"error\":\"out of memory\"}\n"})
+	client := newTestClient(srv)
+
+	events, err := client.CreateMessageStream(context.Background(), toolTurnRequest())
+	require.NoError(t, err)
+
+	var last *services.ClaudeStreamEvent
+	for event := range events {
+		last = event
+	}
+	require.NotNil(t, last)
+	require.ErrorIs(t, last.Error, ollama.ErrAPIError)
+	assert.Contains(t, last.Error.Error(), "out of memory")
+}
+
+func TestClient_ListModels(t *testing.T) {
+	srv := newOllamaServer(t, map[string]string{
+		"/api/tags": `{"models": [{"name": "qwen3:8b", "model": "qwen3:8b"}, {"name": "llama3.2:latest", "model": "llama3.2:latest"}]}`,
+	})
+	client := newTestClient(srv)
+
+	models, err := client.ListModels(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []vo.Model{"ollama/llama3.2:latest", "ollama/qwen3:8b"}, models)
+}
+
+func TestClient_SupportsTools(t *testing.T) {
+	srv := newOllamaServer(t, map[string]string{"/api/show": `{"capabilities": ["completion", "tools"]}`})
+	client := newTestClient(srv)
+
+	supported, err := client.SupportsTools(context.Background(), "ollama/qwen3:8b")
+	require.NoError(t, err)
+	assert.True(t, supported)
+	assert.Equal(t, "qwen3:8b", srv.bodies["/api/show"]["model"])
+
+	// Capabilities are cached per model
+	_, err = client.SupportsTools(context.Background(), "ollama/qwen3:8b")
+	require.NoError(t, err)
+	assert.Equal(t, 1, srv.requests["/api/show"])
+
+	srv.responses["/api/show"] = `{"capabilities": ["completion"]}`
+	supported, err = client.SupportsTools(context.Background(), "ollama/gemma2:2b")
+	require.NoError(t, err)
+	assert.False(t, supported)
+}
@@ -0,0 +1,609 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/llm"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/openai"
+)
+
+// chatServer is a stand-in chat completions endpoint that records the last request body
+type chatServer struct {
+	*httptest.Server
+	body    map[string]interface{}
+	headers http.Header
+}
+
+func newChatServer(t *testing.T, status int, contentType, response string) *chatServer {
+	t.Helper()
+	s := &chatServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		data, _ := io.ReadAll(r.Body)
+		s.body = nil
+		_ = json.Unmarshal(data, &s.body)
+		s.headers = r.Header.Clone()
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, response)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestClient(t *testing.T, provider vo.Provider, baseURL string) *openai.Client {
+	t.Helper()
+	client, err := openai.NewClient(provider, config.ProviderConfig{APIKey: "test-key", BaseURL: baseURL + "/v1/"}, zerolog.Nop())
+	require.NoError(t, err)
+	return client
+}
+
+// toolTurnRequest is a conversation where the assistant called a tool and got its result
+func toolTurnRequest(model vo.Model) *services.ClaudeRequest {
+	systemPrompt, _ := vo.NewSystemPrompt("You are an SRE assistant")
+	return &services.ClaudeRequest{
+		Model:        model,
+		SystemPrompt: systemPrompt,
+		MaxTokens:    512,
+		Temperature:  0.2,
+		Messages: []services.ClaudeMessage{
+			{Role: vo.RoleUser, Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: "Why is checkout slow?"}}},
+			{Role: vo.RoleAssistant, Content: []entities.ContentBlock{
+				{Type: vo.ContentTypeText, Text: "Let me check the metrics."},
+				{Type: vo.ContentTypeToolUse, ID: "call_1", Name: "query_metrics", Input: map[string]interface{}{"service": "checkout"}},
+			}},
+			{Role: vo.RoleUser, Content: []entities.ContentBlock{
+				{Type: vo.ContentTypeToolResult, ToolUseID: "call_1", Content: "p99 latency 2.3s"},
+				{Type: vo.ContentTypeText, Text: "What next?"},
+			}},
+		},
+		Tools: []services.ClaudeTool{{
+			Name:        "query_metrics",
+			Description: "Query service metrics",
+			InputSchema: &entities.JSONSchema{
+				Type:       "object",
+				Properties: map[string]*entities.JSONSchema{"service": {Type: "string", Description: "Service name"}},
+				Required:   []string{"service"},
+			},
+		}},
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := openai.NewClient(vo.ProviderOpenAI, config.ProviderConfig{}, zerolog.Nop())
+	assert.ErrorIs(t, err, openai.ErrAPIKeyRequired)
+
+	_, err = openai.NewClient("acme", config.ProviderConfig{APIKey: "k"}, zerolog.Nop())
+	assert.ErrorIs(t, err, openai.ErrUnknownProvider)
+
+	client, err := openai.NewClient(vo.ProviderDeepSeek, config.ProviderConfig{APIKey: "k"}, zerolog.Nop())
+	require.NoError(t, err)
+	assert.Equal(t, vo.ProviderDeepSeek, client.Provider())
+
+	for _, provider := range []vo.Provider{
+		vo.ProviderOpenAI, vo.ProviderDeepSeek, vo.ProviderQwen, vo.ProviderMistral,
+		vo.ProviderXAI, vo.ProviderMoonshot, vo.ProviderZhipu,
+	} {
+		_, ok := openai.DefaultBaseURL(provider)
+		assert.True(t, ok, "%s has a default endpoint", provider)
+	}
+}
+
+func TestClient_CreateMessage_MapsRequest(t *testing.T) {
+	srv := newChatServer(t, http.StatusOK, "application/json", `{
+		"id": "chatcmpl-1",
+		"model": "deepseek-chat",
+		"choices": [{"index": 0, "message": {"role": "assistant", "content": "Checkout is waiting on the database."}, "finish_reason": "stop"}],
+		"usage": {"prompt_tokens": 120, "completion_tokens": 9, "prompt_tokens_details": {"cached_tokens": 100}}
+	}`)
+	client := newTestClient(t, vo.ProviderDeepSeek, srv.URL)
+
+	response, err := client.CreateMessage(context.Background(), toolTurnRequest(vo.ModelDeepSeekChat))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Bearer test-key", srv.headers.Get("Authorization"))
+	assert.Equal(t, "deepseek-chat", srv.body["model"])
+	assert.EqualValues(t, 512, srv.body["max_tokens"])
+	assert.NotContains(t, srv.body, "max_completion_tokens")
+	assert.EqualValues(t, 0.2, srv.body["temperature"])
+
+	messages := srv.body["messages"].([]interface{})
+	require.Len(t, messages, 5)
+	assert.Equal(t, map[string]interface{}{"role": "system", "content": "You are an SRE assistant"}, messages[0])
+	assert.Equal(t, map[string]interface{}{"role": "user", "content": "Why is checkout slow?"}, messages[1])
+
+	assistant := messages[2].(map[string]interface{})
+	assert.Equal(t, "Let me check the metrics.", assistant["content"])
+	calls := assistant["tool_calls"].([]interface{})
+	require.Len(t, calls, 1)
+	call := calls[0].(map[string]interface{})
+	assert.Equal(t, "call_1", call["id"])
+	assert.Equal(t, "function", call["type"])
+	function := call["function"].(map[string]interface{})
+	assert.Equal(t, "query_metrics", function["name"])
+	assert.JSONEq(t, `{"service": "checkout"}`, function["arguments"].(string))
+
+	assert.Equal(t, map[string]interface{}{"role": "tool", "tool_call_id": "call_1", "content": "p99 latency 2.3s"}, messages[3])
+	assert.Equal(t, map[string]interface{}{"role": "user", "content": "What next?"}, messages[4])
+
+	tools := srv.body["tools"].([]interface{})
+	require.Len(t, tools, 1)
+	toolJSON, _ := json.Marshal(tools[0])
+	assert.JSONEq(t, `{
+		"type": "function",
+		"function": {
+			"name": "query_metrics",
+			"description": "Query service metrics",
+			"parameters": {"type": "object", "properties": {"service": {"type": "string", "description": "Service name"}}, "required": ["service"]}
+		}
+	}`, string(toolJSON))
+
+	assert.Equal(t, "chatcmpl-1", response.ID)
+	assert.Equal(t, vo.RoleAssistant, response.Role)
+	assert.Equal(t, "end_turn", response.StopReason)
+	require.Len(t, response.Content, 1)
+	assert.Equal(t, "Checkout is waiting on the database.", response.Content[0].Text)
+	assert.Equal(t, 20, response.Usage.InputTokens, "cached prompt tokens are reported apart")
+	assert.Equal(t, 100, response.Usage.CacheReadInputTokens)
+	assert.Equal(t, 120, response.Usage.TotalInputTokens())
+	assert.Equal(t, 9, response.Usage.OutputTokens)
+}
+
+func TestClient_AccountHeaders(t *testing.T) {
+	srv := newChatServer(t, http.StatusOK, "application/json", `{"choices": [{"message": {"content": "ok"}, "finish_reason": "stop"}]}`)
+	client, err := openai.NewClient(vo.ProviderOpenAI, config.ProviderConfig{
+		APIKey:       "test-key",
+		BaseURL:      srv.URL + "/v1",
+		Organization: "org-telemetryflow",
+		Project:      "proj_sre",
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	_, err = client.CreateMessage(context.Background(), toolTurnRequest(vo.ModelGPT54))
+	require.NoError(t, err)
+	assert.Equal(t, "org-telemetryflow", srv.headers.Get("OpenAI-Organization"))
+	assert.Equal(t, "proj_sre", srv.headers.Get("OpenAI-Project"))
+
+	_, err = newTestClient(t, vo.ProviderOpenAI, srv.URL).CreateMessage(context.Background(), toolTurnRequest(vo.ModelGPT54))
+	require.NoError(t, err)
+	assert.Empty(t, srv.headers.Get("OpenAI-Organization"))
+}
+
+func TestClient_PromptCacheKey(t *testing.T) {
+	srv := newChatServer(t, http.StatusOK, "application/json", `{
+		"id": "chatcmpl-1",
+		"choices": [{"index": 0, "message": {"role": "assistant", "content": "ok"}, "finish_reason": "stop"}],
+		"usage": {"prompt_tokens": 10, "completion_tokens": 1}
+	}`)
+	client, err := openai.NewClient(vo.ProviderOpenAI, config.ProviderConfig{
+		APIKey:        "test-key",
+		BaseURL:       srv.URL + "/v1",
+		PromptCaching: config.PromptCachingConfig{Enabled: true},
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	send := func(request *services.ClaudeRequest) string {
+		_, err := client.CreateMessage(context.Background(), request)
+		require.NoError(t, err)
+		key, _ := srv.body["prompt_cache_key"].(string)
+		return key
+	}
+
+	first := send(toolTurnRequest(vo.ModelGPT54))
+	assert.Len(t, first, 32)
+	followUp := toolTurnRequest(vo.ModelGPT54)
+	followUp.Messages = followUp.Messages[:1]
+	assert.Equal(t, first, send(followUp), "the key depends on the stable prefix only")
+	other := toolTurnRequest(vo.ModelGPT54)
+	other.SystemPrompt, _ = vo.NewSystemPrompt("You are a DBA")
+	assert.NotEqual(t, first, send(other))
+
+	// Disabled by default
+	_, err = newTestClient(t, vo.ProviderOpenAI, srv.URL).CreateMessage(context.Background(), toolTurnRequest(vo.ModelGPT54))
+	require.NoError(t, err)
+	assert.NotContains(t, srv.body, "prompt_cache_key")
+}
+
+func TestClient_CreateMessage_ToolCalls(t *testing.T) {
+	srv := newChatServer(t, http.StatusOK, "application/json", `{
+		"id": "chatcmpl-2",
+		"model": "gpt-5.4",
+		"choices": [{"index": 0, "message": {"role": "assistant", "content": null, "tool_calls": [
+			{"id": "call_a", "type": "function", "function": {"name": "query_metrics", "arguments": "{\"service\":\"checkout\"}"}},
+			{"id": "call_b", "type": "function", "function": {"name": "query_logs", "arguments": ""}}
+		]}, "finish_reason": "tool_calls"}]
+	}`)
+	client := newTestClient(t, vo.ProviderOpenAI, srv.URL)
+
+	request := toolTurnRequest(vo.ModelGPT54)
+	request.Messages = request.Messages[:1]
+	response, err := client.CreateMessage(context.Background(), request)
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 512, srv.body["max_completion_tokens"], "OpenAI takes max_completion_tokens")
+	assert.NotContains(t, srv.body, "max_tokens")
+
+	assert.Equal(t, "tool_use", response.StopReason)
+	require.Len(t, response.Content, 2)
+	assert.Equal(t, vo.ContentTypeToolUse, response.Content[0].Type)
+	assert.Equal(t, "call_a", response.Content[0].ID)
+	assert.Equal(t, "query_metrics", response.Content[0].Name)
+	assert.Equal(t, map[string]interface{}{"service": "checkout"}, response.Content[0].Input)
+	assert.Equal(t, map[string]interface{}{}, response.Content[1].Input)
+}
+
+func TestClient_CreateMessage_Reasoning(t *testing.T) {
+	tests := []struct {
+		name      string
+		provider  vo.Provider
+		model     vo.Model
+		reasoning vo.Reasoning
+		want      map[string]interface{} // nil values must be absent
+	}{
+		{"openai effort", vo.ProviderOpenAI, vo.ModelGPT54, vo.Reasoning{Effort: vo.ReasoningEffortMedium}, map[string]interface{}{
+			"reasoning_effort": "medium", "max_completion_tokens": float64(512 + 8192), "temperature": nil,
+		}},
+		{"grok-3-mini takes low or high", vo.ProviderXAI, vo.ModelGrok3Mini, vo.Reasoning{BudgetTokens: 4096}, map[string]interface{}{
+			"reasoning_effort": "high", "max_tokens": float64(512 + 4096), "temperature": 0.2,
+		}},
+		{"grok 4 always reasons", vo.ProviderXAI, vo.ModelGrok43, vo.Reasoning{Effort: vo.ReasoningEffortLow}, map[string]interface{}{
+			"reasoning_effort": nil,
+		}},
+		{"qwen budget", vo.ProviderQwen, vo.ModelQwen36Plus, vo.Reasoning{Effort: vo.ReasoningEffortLow}, map[string]interface{}{
+			"enable_thinking": true, "thinking_budget": float64(2048), "max_tokens": float64(512 + 2048),
+		}},
+		{"deepseek thinking mode", vo.ProviderDeepSeek, vo.ModelDeepSeekReasoner, vo.Reasoning{Effort: vo.ReasoningEffortHigh}, map[string]interface{}{
+			"thinking": map[string]interface{}{"type": "enabled"}, "reasoning_effort": nil,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newChatServer(t, http.StatusOK, "application/json",
+				`{"id": "chatcmpl-r", "choices": [{"index": 0, "message": {"role": "assistant", "content": "ok"}, "finish_reason": "stop"}]}`)
+			request := toolTurnRequest(tt.model)
+			request.Reasoning = &tt.reasoning
+			_, err := newTestClient(t, tt.provider, srv.URL).CreateMessage(context.Background(), request)
+			require.NoError(t, err)
+
+			for field, want := range tt.want {
+				if want == nil {
+					assert.NotContains(t, srv.body, field)
+				} else {
+					assert.Equal(t, want, srv.body[field], field)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_CreateMessage_ReasoningContent(t *testing.T) {
+	srv := newChatServer(t, http.StatusOK, "application/json", `{
+		"id": "chatcmpl-4",
+		"model": "deepseek-reasoner",
+		"choices": [{"index": 0, "message": {"role": "assistant", "reasoning_content": "Errors spiked first.", "content": "Check the logs."}, "finish_reason": "stop"}],
+		"usage": {"prompt_tokens": 50, "completion_tokens": 40, "completion_tokens_details": {"reasoning_tokens": 25}}
+	}`)
+	client := newTestClient(t, vo.ProviderDeepSeek, srv.URL)
+
+	// Mid-turn: the assistant reasoned before its tool call and the tool has answered
+	request := toolTurnRequest(vo.ModelDeepSeekReasoner)
+	request.Messages[1].Content = append([]entities.ContentBlock{{Type: vo.ContentTypeThinking, Thinking: "Metrics first."}}, request.Messages[1].Content...)
+	request.Messages[2].Content = request.Messages[2].Content[:1]
+	response, err := client.CreateMessage(context.Background(), request)
+	require.NoError(t, err)
+
+	assistant := srv.body["messages"].([]interface{})[2].(map[string]interface{})
+	assert.Equal(t, "assistant", assistant["role"])
+	assert.Equal(t, "Metrics first.", assistant["reasoning_content"], "reasoning behind the tool calls of the current turn is sent back")
+
+	require.Len(t, response.Content, 2)
+	assert.Equal(t, vo.ContentTypeThinking, response.Content[0].Type)
+	assert.Equal(t, "Errors spiked first.", response.Content[0].Thinking)
+	assert.Equal(t, "Check the logs.", response.Content[1].Text)
+	assert.Equal(t, 40, response.Usage.OutputTokens)
+	assert.Equal(t, 25, response.Usage.ReasoningTokens)
+
+	// Once the user asks something new, earlier reasoning is dropped
+	request.Messages = append(request.Messages, services.ClaudeMessage{
+		Role: vo.RoleUser, Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: "And now?"}},
+	})
+	_, err = client.CreateMessage(context.Background(), request)
+	require.NoError(t, err)
+	assistant = srv.body["messages"].([]interface{})[2].(map[string]interface{})
+	assert.NotContains(t, assistant, "reasoning_content")
+}
+
+func TestClient_CreateMessage_Media(t *testing.T) {
+	srv := newChatServer(t, http.StatusOK, "application/json", `{
+		"id": "chatcmpl-5",
+		"model": "gpt-4.1",
+		"choices": [{"index": 0, "message": {"role": "assistant", "content": "Latency peaks at 14:00."}, "finish_reason": "stop"}],
+		"usage": {"prompt_tokens": 900, "completion_tokens": 8}
+	}`)
+	client := newTestClient(t, vo.ProviderOpenAI, srv.URL)
+
+	image, err := entities.NewMediaBlock(vo.MimeTypePNG, []byte("\x89PNG"), "")
+	require.NoError(t, err)
+	document, err := entities.NewMediaBlock(vo.MimeTypePDF, []byte("%PDF-1.7"), "report.pdf")
+	require.NoError(t, err)
+	request := &services.ClaudeRequest{
+		Model: vo.ModelGPT41,
+		Messages: []services.ClaudeMessage{{Role: vo.RoleUser, Content: []entities.ContentBlock{
+			image, document, {Type: vo.ContentTypeText, Text: "Summarize"},
+		}}},
+		MaxTokens: 256,
+	}
+	response, err := client.CreateMessage(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, "Latency peaks at 14:00.", response.Content[0].Text)
+
+	user := srv.body["messages"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"type": "image_url", "image_url": map[string]interface{}{"url": "data:image/png;base64,iVBORw=="}},
+		map[string]interface{}{"type": "file", "file": map[string]interface{}{"filename": "report.pdf", "file_data": "data:application/pdf;base64,JVBERi0xLjc="}},
+		map[string]interface{}{"type": "text", "text": "Summarize"},
+	}, user["content"], "messages with media send their content as parts")
+
+	// Media is estimated apart from its base64 data
+	tokens, err := client.CountTokens(context.Background(), request)
+	require.NoError(t, err)
+	assert.Less(t, tokens, 1600+3000+100)
+	assert.Greater(t, tokens, 1600+3000)
+}
+
+func TestClient_CreateMessage_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		sentinel error
+		message  string
+	}{
+		{"rate limited", http.StatusTooManyRequests, `{"error": {"message": "Rate limit reached", "type": "requests"}}`, openai.ErrRateLimited, "Rate limit reached"},
+		{"bad request", http.StatusBadRequest, `{"error": {"message": "Invalid tool schema"}}`, openai.ErrAPIError, "HTTP 400: Invalid tool schema"},
+		{"string error", http.StatusUnauthorized, `{"error": "invalid api key"}`, openai.ErrAPIError, "invalid api key"},
+		{"plain body", http.StatusBadGateway, `upstream down`, openai.ErrAPIError, "upstream down"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newChatServer(t, tt.status, "application/json", tt.body)
+			client := newTestClient(t, vo.ProviderMistral, srv.URL)
+
+			_, err := client.CreateMessage(context.Background(), toolTurnRequest(vo.ModelMistralLarge3))
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tt.sentinel)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestClient_Retries(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, `{"error": {"message": "try again"}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"id": "chatcmpl-1", "model": "gpt-5.4", "choices": [{"message": {"role": "assistant", "content": "ok"}, "finish_reason": "stop"}]}`)
+	}))
+	defer srv.Close()
+
+	client, err := openai.NewClient(vo.ProviderOpenAI, config.ProviderConfig{
+		APIKey:     "test-key",
+		BaseURL:    srv.URL,
+		MaxRetries: 1,
+		RetryDelay: time.Millisecond,
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	response, err := client.CreateMessage(context.Background(), toolTurnRequest(vo.ModelGPT54))
+	require.NoError(t, err)
+	assert.Equal(t, "ok", response.Content[0].Text)
+	assert.Equal(t, 2, calls)
+
+	// Retries are spent, and the last status error is returned
+	calls = 0
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	_, err = client.CreateMessage(context.Background(), toolTurnRequest(vo.ModelGPT54))
+	require.ErrorIs(t, err, openai.ErrAPIError)
+	var statusErr *llm.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusBadGateway, statusErr.StatusCode)
+	assert.Equal(t, 2, calls)
+}
+
+func TestClient_ValidateRequest(t *testing.T) {
+	client := newTestClient(t, vo.ProviderOpenAI, "http://localhost")
+
+	assert.ErrorIs(t, client.ValidateRequest(nil), openai.ErrInvalidRequest)
+	assert.ErrorIs(t, client.ValidateRequest(toolTurnRequest("gpt-unknown")), openai.ErrInvalidRequest)
+
+	err := client.ValidateRequest(toolTurnRequest(vo.ModelDeepSeekChat))
+	require.ErrorIs(t, err, openai.ErrInvalidRequest)
+	assert.Contains(t, err.Error(), "not served by openai")
+
+	request := toolTurnRequest(vo.ModelGPT54)
+	request.MaxTokens = 0
+	require.NoError(t, client.ValidateRequest(request))
+	assert.Equal(t, openai.DefaultMaxTokens, request.MaxTokens)
+}
+
+func TestClient_CountTokens(t *testing.T) {
+	client := newTestClient(t, vo.ProviderOpenAI, "http://localhost")
+
+	short := &services.ClaudeRequest{
+		Model:    vo.ModelGPT54,
+		Messages: []services.ClaudeMessage{{Role: vo.RoleUser, Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: "hi"}}}},
+	}
+	shortCount, err := client.CountTokens(context.Background(), short)
+	require.NoError(t, err)
+	longCount, err := client.CountTokens(context.Background(), toolTurnRequest(vo.ModelGPT54))
+	require.NoError(t, err)
+
+	assert.Positive(t, shortCount)
+	assert.Greater(t, longCount, shortCount)
+}
+
+func TestClient_CreateMessageStream(t *testing.T) {
+	stream := strings.Join([]string{
+		`data: {"id":"chatcmpl-3","model":"grok-4.3","choices":[{"index":0,"delta":{"role":"assistant","content":"Check"}}]}`,
+		``,
+		`data: {"id":"chatcmpl-3","choices":[{"index":0,"delta":{"content":"ing."}}]}`,
+		``,
+		`: keep-alive`,
+		`data: {"id":"chatcmpl-3","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_x","type":"function","function":{"name":"query_logs","arguments":""}}]}}]}`,
+		`data: {"id":"chatcmpl-3","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"level\":"}}]}}]}`,
+		`data: {"id":"chatcmpl-3","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"error\"}"}}]},"finish_reason":"tool_calls"}]}`,
+		`data: {"id":"chatcmpl-3","choices":[],"usage":{"prompt_tokens":42,"completion_tokens":17}}`,
+		`data: [DONE]`,
+		``,
+	}, "\n")
+	srv := newChatServer(t, http.StatusOK, "text/event-stream", stream)
+	client := newTestClient(t, vo.ProviderXAI, srv.URL)
+
+	events, err := client.CreateMessageStream(context.Background(), toolTurnRequest(vo.ModelGrok43))
+	require.NoError(t, err)
+
+	var collected []*services.ClaudeStreamEvent
+	for event := range events {
+		require.NoError(t, event.Error)
+		collected = append(collected, event)
+	}
+
+	assert.Equal(t, true, srv.body["stream"])
+	assert.Equal(t, map[string]interface{}{"include_usage": true}, srv.body["stream_options"])
+
+	types := make([]string, 0, len(collected))
+	for _, e := range collected {
+		types = append(types, e.Type)
+	}
+	assert.Equal(t, []string{
+		"message_start",
+		"content_block_start", "content_block_delta", "content_block_delta",
+		"content_block_start", "content_block_delta", "content_block_delta",
+		"content_block_stop", "content_block_stop",
+		"message_delta", "message_stop",
+	}, types)
+
+	assert.Equal(t, "chatcmpl-3", collected[0].Message.ID)
+	assert.Equal(t, "grok-4.3", collected[0].Message.Model)
+	assert.Equal(t, 0, collected[1].Index)
+	assert.Equal(t, "Check", collected[2].Delta.Text)
+	assert.Equal(t, "text_delta", collected[2].Delta.Type)
+
+	toolStart := collected[4]
+	assert.Equal(t, 1, toolStart.Index)
+	assert.Equal(t, vo.ContentTypeToolUse, toolStart.ContentBlock.Type)
+	assert.Equal(t, "call_x", toolStart.ContentBlock.ID)
+	assert.Equal(t, "query_logs", toolStart.ContentBlock.Name)
+	assert.Equal(t, "input_json_delta", collected[5].Delta.Type)
+	assert.Equal(t, `{"level":"error"}`, collected[5].Delta.PartialJSON+collected[6].Delta.PartialJSON)
+
+	messageDelta := collected[9]
+	assert.Equal(t, "tool_use", messageDelta.Delta.StopReason)
+	assert.Equal(t, 42, messageDelta.Usage.InputTokens)
+	assert.Equal(t, 17, messageDelta.Usage.OutputTokens)
+}
+
+func TestClient_CreateMessageStream_Reasoning(t *testing.T) {
+	stream := strings.Join([]string{
+		`data: {"id":"chatcmpl-5","model":"kimi-k2-thinking","choices":[{"index":0,"delta":{"role":"assistant","reasoning_content":"Latency "}}]}`,
+		`data: {"id":"chatcmpl-5","choices":[{"index":0,"delta":{"reasoning_content":"rose at 10:00."}}]}`,
+		`data: {"id":"chatcmpl-5","choices":[{"index":0,"delta":{"content":"A deploy at 10:00."}}]}`,
+		`data: {"id":"chatcmpl-5","choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":42,"completion_tokens":30,"completion_tokens_details":{"reasoning_tokens":12}}}`,
+		`data: [DONE]`,
+		``,
+	}, "\n")
+	srv := newChatServer(t, http.StatusOK, "text/event-stream", stream)
+	client := newTestClient(t, vo.ProviderMoonshot, srv.URL)
+
+	events, err := client.CreateMessageStream(context.Background(), toolTurnRequest(vo.ModelKimiK2Thinking))
+	require.NoError(t, err)
+
+	var texts []string
+	response, err := services.CollectStream(events, func(text string) { texts = append(texts, text) })
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"A deploy at 10:00."}, texts, "reasoning is not streamed as text")
+	require.Len(t, response.Content, 2)
+	assert.Equal(t, vo.ContentTypeThinking, response.Content[0].Type)
+	assert.Equal(t, "Latency rose at 10:00.", response.Content[0].Thinking)
+	assert.Equal(t, "A deploy at 10:00.", response.Content[1].Text)
+	assert.Equal(t, 12, response.Usage.ReasoningTokens)
+}
+
+func TestClient_CreateMessageStream_ErrorChunk(t *testing.T) {
+	srv := newChatServer(t, http.StatusOK, "text/event-stream",
+		"data: {\"id\":\"c\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hi\"}}]}\n\ndata: {\"error\":{\"message\":\"overloaded\"}}\n\n")
+	client := newTestClient(t, vo.ProviderQwen, srv.URL)
+
+	events, err := client.CreateMessageStream(context.Background(), toolTurnRequest(vo.ModelQwen36Plus))
+	require.NoError(t, err)
+
+	var last *services.ClaudeStreamEvent
+	for event := range events {
+		last = event
+	}
+	require.NotNil(t, last)
+	require.Error(t, last.Error)
+	assert.True(t, errors.Is(last.Error, openai.ErrAPIError))
+	assert.Contains(t, last.Error.Error(), "overloaded")
+}
+
+func TestClient_Local(t *testing.T) {
+	_, err := openai.NewClient(vo.ProviderLocal, config.ProviderConfig{}, zerolog.Nop())
+	assert.ErrorIs(t, err, openai.ErrUnknownProvider, "local needs a base URL")
+
+	var authorization, model string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/v1/models":
+			_, _ = io.WriteString(w, `{"object": "list", "data": [{"id": "meta-llama/Llama-3.1-8B-Instruct"}, {"id": "qwen2.5-coder"}]}`)
+		case "/v1/chat/completions":
+			var body map[string]interface{}
+			data, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(data, &body)
+			model, _ = body["model"].(string)
+			_, _ = io.WriteString(w, `{"id": "c1", "model": "qwen2.5-coder", "choices": [{"index": 0, "message": {"role": "assistant", "content": "hi"}, "finish_reason": "stop"}]}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := openai.NewClient(vo.ProviderLocal, config.ProviderConfig{BaseURL: srv.URL + "/v1"}, zerolog.Nop())
+	require.NoError(t, err)
+
+	models, err := client.ListModels(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []vo.Model{"local/meta-llama/Llama-3.1-8B-Instruct", "local/qwen2.5-coder"}, models)
+	assert.Empty(t, authorization)
+
+	_, err = client.CreateMessage(context.Background(), &services.ClaudeRequest{
+		Model:    "local/qwen2.5-coder",
+		Messages: []services.ClaudeMessage{{Role: vo.RoleUser, Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: "hi"}}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "qwen2.5-coder", model)
+}
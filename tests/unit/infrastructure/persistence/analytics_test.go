@@ -25,7 +25,7 @@ func TestAnalyticsRepository_GetTokenUsageByModel(t *testing.T) {
 				return &mockClickHouseRows{
 					maxRows: 1,
 					scanValues: [][]interface{}{
-						{"model-a", uint64(100), uint64(50), uint64(150), uint64(10), 10.0, 5.0},
+						{"model-a", uint64(100), uint64(50), uint64(20), uint64(0), uint64(170), uint64(10), 10.0, 5.0, 0.75},
 					},
 				}, nil
 			},
@@ -41,8 +41,14 @@ func TestAnalyticsRepository_GetTokenUsageByModel(t *testing.T) {
 		if stats[0].Model != "model-a" {
 			t.Errorf("expected model-a, got %s", stats[0].Model)
 		}
-		if stats[0].TotalTokens != 150 {
-			t.Errorf("expected 150 tokens, got %d", stats[0].TotalTokens)
+		if stats[0].TotalTokens != 170 {
+			t.Errorf("expected 170 tokens, got %d", stats[0].TotalTokens)
+		}
+		if stats[0].CacheReadTokens != 20 {
+			t.Errorf("expected 20 cache read tokens, got %d", stats[0].CacheReadTokens)
+		}
+		if stats[0].CostUSD != 0.75 {
+			t.Errorf("expected 0.75 USD, got %f", stats[0].CostUSD)
 		}
 	})
 
@@ -287,7 +293,7 @@ func TestAnalyticsRepository_GetDashboardSummary(t *testing.T) {
 					return &mockClickHouseRows{
 						maxRows: 1,
 						scanValues: [][]interface{}{
-							{uint64(1000), uint64(500000), 250.0, 2.5},
+							{uint64(1000), uint64(500000), 12.5, 250.0, 2.5},
 						},
 					}, nil
 				case 2:
@@ -317,6 +323,9 @@ func TestAnalyticsRepository_GetDashboardSummary(t *testing.T) {
 		if summary.TotalRequests != 1000 {
 			t.Errorf("expected 1000, got %d", summary.TotalRequests)
 		}
+		if summary.TotalCostUSD != 12.5 {
+			t.Errorf("expected 12.5 USD, got %f", summary.TotalCostUSD)
+		}
 		if summary.TotalToolCalls != 500 {
 			t.Errorf("expected 500, got %d", summary.TotalToolCalls)
 		}
@@ -350,7 +359,7 @@ func TestAnalyticsRepository_GetDashboardSummary(t *testing.T) {
 					return &mockClickHouseRows{
 						maxRows: 1,
 						scanValues: [][]interface{}{
-							{uint64(1000), uint64(500000), 250.0, 2.5},
+							{uint64(1000), uint64(500000), 12.5, 250.0, 2.5},
 						},
 					}, nil
 				}
@@ -374,7 +383,7 @@ func TestAnalyticsRepository_GetDashboardSummary(t *testing.T) {
 					return &mockClickHouseRows{
 						maxRows: 1,
 						scanValues: [][]interface{}{
-							{uint64(1000), uint64(500000), 250.0, 2.5},
+							{uint64(1000), uint64(500000), 12.5, 250.0, 2.5},
 						},
 					}, nil
 				case 2:
@@ -112,8 +112,8 @@ func TestClickHouse_CreateTables(t *testing.T) {
 		if err := ch.CreateTables(context.Background()); err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
-		if execCount != 5 {
-			t.Errorf("expected 5 exec calls, got %d", execCount)
+		if execCount != 8 {
+			t.Errorf("expected 8 exec calls, got %d", execCount)
 		}
 	})
 
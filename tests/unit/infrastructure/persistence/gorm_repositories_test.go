@@ -255,6 +255,116 @@ func TestGormConversationRepository(t *testing.T) {
 		}
 	})
 
+	t.Run("messages and settings round trip", func(t *testing.T) {
+		conv := newTestConversation(t, session.ID())
+		prompt, _ := vo.NewSystemPrompt("You are an SRE")
+		if err := conv.SetSystemPrompt(prompt); err != nil {
+			t.Fatalf("SetSystemPrompt: %v", err)
+		}
+		conv.SetMaxTokens(2048)
+		conv.SetTemperature(0.3)
+		conv.SetStopSequences([]string{"END"})
+		conv.SetReasoning(&vo.Reasoning{Effort: vo.ReasoningEffortLow})
+		conv.SetMetadata("context_type", "metrics")
+		if _, err := conv.AddUserMessage("How is latency?"); err != nil {
+			t.Fatalf("AddUserMessage: %v", err)
+		}
+		if err := repo.Save(ctx, conv); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		// A second turn adds messages to the stored ones
+		if _, err := conv.AddAssistantMessage([]entities.ContentBlock{
+			{Type: vo.ContentTypeToolUse, ID: "t1", Name: "echo", Input: map[string]interface{}{"message": "x"}},
+		}); err != nil {
+			t.Fatalf("AddAssistantMessage: %v", err)
+		}
+		if _, err := conv.AddAssistantMessage([]entities.ContentBlock{{Type: vo.ContentTypeText, Text: "p99 is 412ms"}}); err != nil {
+			t.Fatalf("AddAssistantMessage: %v", err)
+		}
+		if err := repo.Save(ctx, conv); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		found, err := repo.FindByID(ctx, conv.ID())
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		if found.SystemPrompt().String() != "You are an SRE" || found.MaxTokens() != 2048 || found.Temperature() != 0.3 {
+			t.Errorf("settings not restored: %q %d %v", found.SystemPrompt(), found.MaxTokens(), found.Temperature())
+		}
+		if got := found.StopSequences(); len(got) != 1 || got[0] != "END" {
+			t.Errorf("stop sequences not restored: %v", got)
+		}
+		if got := found.Reasoning(); got == nil || got.Effort != vo.ReasoningEffortLow {
+			t.Errorf("reasoning not restored: %+v", got)
+		}
+		if ct, _ := found.GetMetadata("context_type"); ct != "metrics" {
+			t.Errorf("metadata not restored: %v", ct)
+		}
+
+		messages := found.Messages()
+		if len(messages) != 3 {
+			t.Fatalf("expected 3 messages, got %d", len(messages))
+		}
+		if messages[0].GetTextContent() != "How is latency?" || messages[0].ID() != conv.Messages()[0].ID() {
+			t.Errorf("first message not restored: %+v", messages[0].Content())
+		}
+		if use := messages[1].Content()[0]; use.Type != vo.ContentTypeToolUse || use.Name != "echo" || use.Input["message"] != "x" {
+			t.Errorf("tool use not restored: %+v", use)
+		}
+		if messages[2].GetTextContent() != "p99 is 412ms" {
+			t.Errorf("last message not restored: %+v", messages[2].Content())
+		}
+
+		// The restored conversation takes the next turn
+		if _, err := found.AddUserMessage("And errors?"); err != nil {
+			t.Errorf("AddUserMessage on restored conversation: %v", err)
+		}
+	})
+
+	t.Run("compacted history replaces stored messages", func(t *testing.T) {
+		conv := newTestConversation(t, session.ID())
+		for _, text := range []string{"How is latency?", "p99 is 412ms", "And errors?"} {
+			var err error
+			if conv.MessageCount()%2 == 0 {
+				_, err = conv.AddUserMessage(text)
+			} else {
+				_, err = conv.AddAssistantMessage([]entities.ContentBlock{{Type: vo.ContentTypeText, Text: text}})
+			}
+			if err != nil {
+				t.Fatalf("add message: %v", err)
+			}
+		}
+		if err := repo.Save(ctx, conv); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		last := conv.Messages()[2]
+		folded := entities.RestoreMessage(last.ID(), last.Role(), []entities.ContentBlock{
+			{Type: vo.ContentTypeText, Text: "Summary: p99 was 412ms"},
+			{Type: vo.ContentTypeText, Text: "And errors?"},
+		}, last.CreatedAt())
+		if err := conv.CompactHistory([]*entities.Message{folded}); err != nil {
+			t.Fatalf("CompactHistory: %v", err)
+		}
+		if err := repo.Save(ctx, conv); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		found, err := repo.FindByID(ctx, conv.ID())
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		messages := found.Messages()
+		if len(messages) != 1 {
+			t.Fatalf("expected 1 message, got %d", len(messages))
+		}
+		if content := messages[0].Content(); len(content) != 2 || content[0].Text != "Summary: p99 was 412ms" {
+			t.Errorf("compacted message not stored: %+v", content)
+		}
+	})
+
 	t.Run("find non-existent returns nil", func(t *testing.T) {
 		found, err := repo.FindByID(ctx, vo.GenerateConversationID())
 		if err != nil {
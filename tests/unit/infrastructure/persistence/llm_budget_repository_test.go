@@ -0,0 +1,71 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+)
+
+func testLLMBudgetRepository(t *testing.T, repo repositories.ILLMBudgetRepository) {
+	ctx := context.Background()
+
+	monthly := entities.NewLLMBudget("acme", entities.BudgetMonthly)
+	monthly.CostLimitUSD = 500
+	monthly.NotifyEmails = []string{"finops@acme.example"}
+	daily := entities.NewLLMBudget("acme", entities.BudgetDaily)
+	daily.TokenLimit = 1_000_000
+	daily.HardLimit = false
+	other := entities.NewLLMBudget("globex", entities.BudgetMonthly)
+	other.TokenLimit = 10
+	for _, b := range []*entities.LLMBudget{monthly, daily, other} {
+		require.NoError(t, repo.Save(ctx, b))
+	}
+
+	acme, err := repo.FindByOrganization(ctx, "acme")
+	require.NoError(t, err)
+	require.Len(t, acme, 2)
+	assert.Equal(t, entities.BudgetDaily, acme[0].Period, "ordered by period")
+	assert.Equal(t, int64(1_000_000), acme[0].TokenLimit)
+	assert.False(t, acme[0].HardLimit)
+	assert.Equal(t, 500.0, acme[1].CostLimitUSD)
+	assert.Equal(t, []string{"finops@acme.example"}, acme[1].NotifyEmails)
+	assert.Equal(t, 0.8, acme[1].WarnAt)
+
+	// Saving another monthly budget for acme replaces the first one
+	replacement := entities.NewLLMBudget("acme", entities.BudgetMonthly)
+	replacement.CostLimitUSD = 750
+	replacement.Enabled = false
+	require.NoError(t, repo.Save(ctx, replacement))
+
+	all, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, "acme", all[0].OrganizationID)
+	assert.Equal(t, "globex", all[2].OrganizationID)
+	assert.Equal(t, monthly.ID, all[1].ID, "a replaced budget keeps its ID")
+	assert.Equal(t, 750.0, all[1].CostLimitUSD)
+	assert.False(t, all[1].Enabled)
+	assert.Empty(t, all[1].NotifyEmails)
+
+	require.NoError(t, repo.Delete(ctx, other.ID))
+	none, err := repo.FindByOrganization(ctx, "globex")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestGormLLMBudgetRepository(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&persistence.LLMBudgetModel{}))
+
+	testLLMBudgetRepository(t, persistence.NewGormLLMBudgetRepository(db))
+}
+
+func TestInMemoryLLMBudgetRepository(t *testing.T) {
+	testLLMBudgetRepository(t, persistence.NewInMemoryLLMBudgetRepository())
+}
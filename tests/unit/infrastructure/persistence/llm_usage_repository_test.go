@@ -0,0 +1,127 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+)
+
+func newLLMUsage(sessionID, org string, model vo.Model, cost float64, calledAt time.Time) *entities.LLMUsage {
+	u := entities.NewLLMUsage(model, model)
+	u.SessionID = sessionID
+	u.OrganizationID = org
+	u.InputTokens, u.OutputTokens = 100, 10
+	u.Latency = 200 * time.Millisecond
+	u.CostUSD = cost
+	u.PricingVersion = vo.PricingVersion
+	u.CalledAt = calledAt
+	return u
+}
+
+func TestInMemoryLLMUsageRepository_Summarize(t *testing.T) {
+	ctx := context.Background()
+	repo := persistence.NewInMemoryLLMUsageRepository(100)
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	first := newLLMUsage("s1", "acme", vo.ModelClaudeOpus47, 0.5, base)
+	second := newLLMUsage("s1", "acme", vo.ModelGPT54, 0.1, base.Add(time.Hour))
+	second.Status = entities.LLMCallError
+	second.Latency = 400 * time.Millisecond
+	third := newLLMUsage("s2", "globex", vo.ModelClaudeOpus47, 0.25, base.Add(24*time.Hour))
+	third.CacheReadTokens = 1000
+	unpriced := newLLMUsage("s2", "globex", "unknown-model", 0, base.Add(25*time.Hour))
+	unpriced.PricingVersion = ""
+	for _, u := range []*entities.LLMUsage{first, second, third, unpriced} {
+		require.NoError(t, repo.Save(ctx, u))
+	}
+
+	totals, err := repo.Summarize(ctx, repositories.LLMUsageFilter{})
+	require.NoError(t, err)
+	require.Len(t, totals, 1)
+	assert.Equal(t, int64(4), totals[0].Requests)
+	assert.Equal(t, int64(1), totals[0].Errors)
+	assert.Equal(t, int64(1), totals[0].UnpricedRequests)
+	assert.Equal(t, int64(400), totals[0].InputTokens)
+	assert.Equal(t, int64(1000), totals[0].CacheReadTokens)
+	assert.InDelta(t, 0.85, totals[0].CostUSD, 1e-9)
+	assert.Equal(t, 250*time.Millisecond, totals[0].AvgLatency)
+
+	byModel, err := repo.Summarize(ctx, repositories.LLMUsageFilter{GroupBy: repositories.LLMUsageByModel})
+	require.NoError(t, err)
+	require.Len(t, byModel, 3)
+	assert.Equal(t, vo.ModelClaudeOpus47.String(), byModel[0].Group, "most expensive group first")
+	assert.Equal(t, int64(2), byModel[0].Requests)
+	assert.InDelta(t, 0.75, byModel[0].CostUSD, 1e-9)
+
+	byDay, err := repo.Summarize(ctx, repositories.LLMUsageFilter{OrganizationID: "acme", GroupBy: repositories.LLMUsageByDay})
+	require.NoError(t, err)
+	require.Len(t, byDay, 1)
+	assert.Equal(t, "2026-10-01", byDay[0].Group)
+	assert.Equal(t, int64(2), byDay[0].Requests)
+
+	ranged, err := repo.Summarize(ctx, repositories.LLMUsageFilter{
+		Since:   base.Add(time.Hour),
+		Until:   base.Add(25 * time.Hour),
+		GroupBy: repositories.LLMUsageBySession,
+	})
+	require.NoError(t, err)
+	require.Len(t, ranged, 2)
+	assert.Equal(t, "s2", ranged[0].Group)
+	assert.Equal(t, int64(1), ranged[0].Requests, "until is exclusive")
+	assert.Equal(t, "s1", ranged[1].Group)
+
+	none, err := repo.Summarize(ctx, repositories.LLMUsageFilter{Provider: vo.ProviderGoogle})
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	_, err = repo.Summarize(ctx, repositories.LLMUsageFilter{GroupBy: "tenant"})
+	assert.Error(t, err)
+}
+
+func TestInMemoryLLMUsageRepository_MaxRecords(t *testing.T) {
+	ctx := context.Background()
+	repo := persistence.NewInMemoryLLMUsageRepository(2)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, repo.Save(ctx, newLLMUsage("s1", "", vo.ModelGPT54, float64(i), time.Now())))
+	}
+
+	totals, err := repo.Summarize(ctx, repositories.LLMUsageFilter{})
+	require.NoError(t, err)
+	require.Len(t, totals, 1)
+	assert.Equal(t, int64(2), totals[0].Requests)
+	assert.InDelta(t, 3.0, totals[0].CostUSD, 1e-9, "the oldest record is dropped")
+}
+
+func TestNewAPIRequestEvent(t *testing.T) {
+	u := newLLMUsage("not-a-uuid", "acme", vo.ModelClaudeOpus47, 0.02, time.Now())
+	u.RequestedModel = "analyst-default"
+	u.ConversationID = "123e4567-e89b-12d3-a456-426614174000"
+	u.CacheWriteTokens = 50
+	u.Streaming = true
+	u.StopReason = "end_turn"
+	u.StatusCode = 200
+
+	event := persistence.NewAPIRequestEvent(u)
+	assert.Equal(t, u.ID, event.RequestID)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000000", event.SessionID)
+	assert.Equal(t, u.ConversationID, event.ConversationID)
+	assert.Equal(t, "acme", event.OrganizationID)
+	assert.Equal(t, "anthropic", event.Provider)
+	assert.Equal(t, "analyst-default", event.RequestedModel)
+	assert.Equal(t, vo.ModelClaudeOpus47.String(), event.Model)
+	assert.Equal(t, uint32(160), event.TotalTokens)
+	assert.Equal(t, uint64(200), event.DurationMs)
+	assert.Equal(t, "success", event.Status)
+	assert.False(t, event.IsError)
+	assert.True(t, event.IsStreaming)
+	assert.Equal(t, 0.02, event.CostUSD)
+	assert.Equal(t, vo.PricingVersion, event.PricingVersion)
+}
@@ -304,7 +304,7 @@ func TestModels_StringArray_ScanValue(t *testing.T) {
 
 func TestModels_AllModels(t *testing.T) {
 	all := models.AllModels()
-	if len(all) != 10 {
-		t.Errorf("expected 10 models, got %d", len(all))
+	if len(all) != 11 {
+		t.Errorf("expected 11 models, got %d", len(all))
 	}
 }
@@ -93,7 +93,7 @@ func TestJSONBArray_Scan_InvalidType(t *testing.T) {
 
 func TestAllModels(t *testing.T) {
 	models := persistence.AllModels()
-	assert.Len(t, models, 9)
+	assert.Len(t, models, 11)
 	for i, m := range models {
 		assert.NotNil(t, m, "model %d is nil", i)
 	}
@@ -349,6 +349,7 @@ func setupDemoTablesDB(t *testing.T) *gorm.DB {
 		top_k INTEGER DEFAULT 0,
 		stop_sequences BLOB,
 		metadata BLOB,
+		reasoning BLOB,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL,
 		closed_at DATETIME,
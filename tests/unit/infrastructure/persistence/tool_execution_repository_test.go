@@ -0,0 +1,99 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+)
+
+func newExecution(sessionID, toolName string, status entities.ToolExecutionStatus, executedAt time.Time) *entities.ToolExecution {
+	e := entities.NewToolExecution(sessionID, toolName, map[string]interface{}{"query": "up"})
+	e.Status = status
+	e.ExecutedAt = executedAt
+	e.Duration = 25 * time.Millisecond
+	return e
+}
+
+func testToolExecutionRepository(t *testing.T, repo repositories.IToolExecutionRepository) {
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	sessionID := "11111111-1111-1111-1111-111111111111"
+
+	first := newExecution(sessionID, "query_metrics", entities.ToolExecutionSuccess, base)
+	second := newExecution(sessionID, "query_logs", entities.ToolExecutionError, base.Add(time.Minute))
+	second.ErrorMessage = "backend unavailable"
+	second.APIKeyID = "key-1"
+	third := newExecution("", "query_metrics", entities.ToolExecutionRejected, base.Add(2*time.Minute))
+	for _, e := range []*entities.ToolExecution{first, second, third} {
+		require.NoError(t, repo.Save(ctx, e))
+	}
+
+	all, err := repo.Search(ctx, repositories.ToolExecutionFilter{})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, third.ID, all[0].ID, "newest first")
+	assert.Equal(t, first.ID, all[2].ID)
+
+	bySession, err := repo.Search(ctx, repositories.ToolExecutionFilter{SessionID: sessionID})
+	require.NoError(t, err)
+	assert.Len(t, bySession, 2)
+
+	byTool, err := repo.Search(ctx, repositories.ToolExecutionFilter{ToolName: "query_metrics", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, byTool, 1)
+	assert.Equal(t, third.ID, byTool[0].ID)
+
+	failed, err := repo.Search(ctx, repositories.ToolExecutionFilter{Status: entities.ToolExecutionError, APIKeyID: "key-1"})
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, "backend unavailable", failed[0].ErrorMessage)
+	assert.Equal(t, 25*time.Millisecond, failed[0].Duration)
+	assert.Equal(t, "up", failed[0].Arguments["query"])
+
+	window, err := repo.Search(ctx, repositories.ToolExecutionFilter{Since: base.Add(30 * time.Second), Until: base.Add(2 * time.Minute)})
+	require.NoError(t, err)
+	require.Len(t, window, 1)
+	assert.Equal(t, second.ID, window[0].ID)
+
+	removed, err := repo.DeleteBefore(ctx, base.Add(90*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), removed)
+
+	remaining, err := repo.Search(ctx, repositories.ToolExecutionFilter{})
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, third.ID, remaining[0].ID)
+	assert.Empty(t, remaining[0].SessionID)
+}
+
+func TestGormToolExecutionRepository(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&persistence.ToolExecutionModel{}))
+
+	testToolExecutionRepository(t, persistence.NewGormToolExecutionRepository(db))
+}
+
+func TestInMemoryToolExecutionRepository(t *testing.T) {
+	testToolExecutionRepository(t, persistence.NewInMemoryToolExecutionRepository(10))
+
+	t.Run("keeps newest records", func(t *testing.T) {
+		ctx := context.Background()
+		repo := persistence.NewInMemoryToolExecutionRepository(2)
+		base := time.Now().UTC()
+		for i := 0; i < 3; i++ {
+			require.NoError(t, repo.Save(ctx, newExecution("", "tool", entities.ToolExecutionSuccess, base.Add(time.Duration(i)*time.Second))))
+		}
+		all, err := repo.Search(ctx, repositories.ToolExecutionFilter{})
+		require.NoError(t, err)
+		require.Len(t, all, 2)
+		assert.Equal(t, base.Add(2*time.Second), all[0].ExecutedAt)
+		assert.Equal(t, base.Add(time.Second), all[1].ExecutedAt)
+	})
+}
@@ -0,0 +1,68 @@
+package queue_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/queue"
+)
+
+func webhookTask(url string, maxRetries int) *queue.Task {
+	return queue.NewTaskBuilder(queue.TaskTypeWebhookDelivery).
+		WithPayload(queue.WebhookDeliveryPayload{
+			URL:        url,
+			Headers:    map[string]string{"X-TelemetryFlow-Event": "budget.warning"},
+			Body:       map[string]interface{}{"organizationId": "acme"},
+			MaxRetries: maxRetries,
+		}).
+		Build()
+}
+
+func TestWebhookDeliveryHandler_RetriesUntilDelivered(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "budget.warning", r.Header.Get("X-TelemetryFlow-Event"))
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "acme", body["organizationId"])
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	handler := queue.NewWebhookDeliveryHandler(server.Client())
+	require.NoError(t, handler(context.Background(), webhookTask(server.URL, 1)))
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+func TestWebhookDeliveryHandler_Fails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	handler := queue.NewWebhookDeliveryHandler(server.Client())
+	assert.ErrorIs(t, handler(context.Background(), webhookTask(server.URL, 0)), queue.ErrWebhookFailed)
+	assert.ErrorIs(t, handler(context.Background(), webhookTask("", 0)), queue.ErrInvalidTask)
+}
+
+func TestEmailNotificationHandler_RejectsInvalidRecipients(t *testing.T) {
+	handler := queue.NewEmailNotificationHandler(queue.SMTPConfig{Host: "127.0.0.1", Port: 1, From: "mcp@example.com"})
+	task := queue.NewTaskBuilder(queue.TaskTypeEmailNotification).
+		WithPayload(queue.EmailNotificationPayload{To: []string{"not an address"}, Subject: "budget"}).
+		Build()
+
+	assert.ErrorIs(t, handler(context.Background(), task), queue.ErrInvalidRecipient)
+}
@@ -0,0 +1,123 @@
+package queue_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/queue"
+)
+
+func TestWorkerPool_RunsTasks(t *testing.T) {
+	pool := queue.NewWorkerPool(2, 10)
+	var count atomic.Int32
+	done := make(chan struct{}, 5)
+	pool.RegisterHandler("test", func(ctx context.Context, task *queue.Task) error {
+		count.Add(1)
+		done <- struct{}{}
+		return nil
+	})
+	pool.Start(context.Background())
+	defer func() { _ = pool.Close() }()
+
+	for i := 0; i < 5; i++ {
+		id, err := pool.Publish(context.Background(), &queue.Task{Type: "test"})
+		require.NoError(t, err)
+		assert.NotEmpty(t, id)
+	}
+	for i := 0; i < 5; i++ {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("task did not run")
+		}
+	}
+	assert.Equal(t, int32(5), count.Load())
+}
+
+func TestWorkerPool_Backpressure(t *testing.T) {
+	pool := queue.NewWorkerPool(1, 1)
+	defer func() { _ = pool.Close() }()
+
+	_, err := pool.Publish(context.Background(), &queue.Task{Type: "test"})
+	require.NoError(t, err)
+
+	// Not started, so the single slot stays occupied
+	_, err = pool.Publish(context.Background(), &queue.Task{Type: "test"})
+	assert.ErrorIs(t, err, queue.ErrQueueFull)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = pool.PublishWait(ctx, &queue.Task{Type: "test"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = pool.Publish(context.Background(), &queue.Task{})
+	assert.ErrorIs(t, err, queue.ErrInvalidTask)
+}
+
+func TestWorkerPool_CloseCancelsHandlers(t *testing.T) {
+	pool := queue.NewWorkerPool(1, 2)
+	started := make(chan struct{})
+	var once sync.Once
+	pool.RegisterHandler("block", func(ctx context.Context, task *queue.Task) error {
+		once.Do(func() { close(started) })
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	pool.Start(context.Background())
+
+	_, err := pool.Publish(context.Background(), &queue.Task{Type: "block"})
+	require.NoError(t, err)
+	<-started
+
+	// A waiting publisher is released by Close
+	_, err = pool.Publish(context.Background(), &queue.Task{Type: "block"})
+	require.NoError(t, err)
+	_, err = pool.Publish(context.Background(), &queue.Task{Type: "block"})
+	require.NoError(t, err)
+	waitErr := make(chan error, 1)
+	go func() {
+		_, err := pool.PublishWait(context.Background(), &queue.Task{Type: "block"})
+		waitErr <- err
+	}()
+
+	closed := make(chan struct{})
+	go func() {
+		_ = pool.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return")
+	}
+	assert.ErrorIs(t, <-waitErr, queue.ErrQueueClosed)
+
+	_, err = pool.Publish(context.Background(), &queue.Task{Type: "block"})
+	assert.ErrorIs(t, err, queue.ErrQueueClosed)
+}
+
+func TestToolTaskDispatcher_DispatchesToRunner(t *testing.T) {
+	pool := queue.NewWorkerPool(1, 4)
+	dispatcher := queue.NewToolTaskDispatcher(pool)
+	ran := make(chan string, 1)
+	dispatcher.Handle(func(ctx context.Context, taskID string) error {
+		ran <- taskID
+		return nil
+	})
+	pool.Start(context.Background())
+	defer func() { _ = pool.Close() }()
+
+	require.NoError(t, dispatcher.Dispatch(context.Background(), "task-123"))
+	select {
+	case id := <-ran:
+		assert.Equal(t, "task-123", id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("task was not dispatched")
+	}
+}
@@ -0,0 +1,343 @@
+package upstream_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/upstream"
+)
+
+// fakeUpstreamEnv makes the test binary act as a stdio upstream server
+const fakeUpstreamEnv = "TFO_FAKE_UPSTREAM"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeUpstreamEnv) == "1" {
+		runFakeStdioUpstream()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeUpstream implements a minimal MCP server shared by the stdio and HTTP fakes
+type fakeUpstream struct {
+	mu        sync.Mutex
+	cancelled []string
+}
+
+type fakeRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// handle answers one message, calling write for each outgoing message
+func (f *fakeUpstream) handle(req *fakeRequest, write func(interface{})) {
+	reply := func(result interface{}) {
+		write(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}
+
+	var params struct {
+		Cursor    string                 `json:"cursor"`
+		Name      string                 `json:"name"`
+		URI       string                 `json:"uri"`
+		RequestID json.RawMessage        `json:"requestId"`
+		Arguments map[string]interface{} `json:"arguments"`
+		Meta      struct {
+			ProgressToken interface{} `json:"progressToken"`
+		} `json:"_meta"`
+	}
+	_ = json.Unmarshal(req.Params, &params)
+
+	switch req.Method {
+	case "initialize":
+		reply(map[string]interface{}{
+			"protocolVersion": "2024-11-05",
+			"serverInfo":      map[string]interface{}{"name": "fake", "version": "1.0"},
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}, "resources": map[string]interface{}{}, "prompts": map[string]interface{}{}},
+		})
+	case "tools/list":
+		// Two pages exercise cursor handling
+		if params.Cursor == "" {
+			reply(map[string]interface{}{
+				"tools":      []interface{}{map[string]interface{}{"name": "echo", "description": "Echo text", "inputSchema": map[string]interface{}{"type": "object"}}},
+				"nextCursor": "page-2",
+			})
+			return
+		}
+		reply(map[string]interface{}{"tools": []interface{}{
+			map[string]interface{}{"name": "slow", "inputSchema": map[string]interface{}{"type": "object"}},
+			map[string]interface{}{"name": "stats", "inputSchema": map[string]interface{}{"type": "object"}},
+			map[string]interface{}{"name": "exit", "inputSchema": map[string]interface{}{"type": "object"}},
+		}})
+	case "tools/call":
+		if params.Meta.ProgressToken != nil {
+			write(map[string]interface{}{"jsonrpc": "2.0", "method": "notifications/progress", "params": map[string]interface{}{
+				"progressToken": params.Meta.ProgressToken, "progress": 1, "total": 2, "message": "working",
+			}})
+		}
+		switch params.Name {
+		case "echo":
+			reply(map[string]interface{}{"content": []interface{}{map[string]interface{}{"type": "text", "text": params.Arguments["text"]}}})
+		case "slow":
+			// Never answers; the client must cancel
+		case "stats":
+			f.mu.Lock()
+			text := strings.Join(f.cancelled, ",")
+			f.mu.Unlock()
+			reply(map[string]interface{}{"content": []interface{}{map[string]interface{}{"type": "text", "text": text}}})
+		case "exit":
+			os.Exit(0)
+		}
+	case "notifications/cancelled":
+		f.mu.Lock()
+		f.cancelled = append(f.cancelled, string(params.RequestID))
+		f.mu.Unlock()
+	case "resources/list":
+		reply(map[string]interface{}{"resources": []interface{}{
+			map[string]interface{}{"uri": "fake://readme", "name": "readme", "mimeType": "text/markdown"},
+		}})
+	case "resources/read":
+		reply(map[string]interface{}{"contents": []interface{}{
+			map[string]interface{}{"uri": params.URI, "mimeType": "text/markdown", "text": "# Fake"},
+		}})
+	case "prompts/list":
+		reply(map[string]interface{}{"prompts": []interface{}{
+			map[string]interface{}{"name": "greet", "arguments": []interface{}{map[string]interface{}{"name": "who", "required": true}}},
+		}})
+	case "prompts/get":
+		var args struct {
+			Arguments map[string]string `json:"arguments"`
+		}
+		_ = json.Unmarshal(req.Params, &args)
+		reply(map[string]interface{}{"messages": []interface{}{
+			map[string]interface{}{"role": "user", "content": map[string]interface{}{"type": "text", "text": "Hello " + args.Arguments["who"]}},
+		}})
+	}
+}
+
+func runFakeStdioUpstream() {
+	f := &fakeUpstream{}
+	var mu sync.Mutex
+	write := func(v interface{}) {
+		data, _ := json.Marshal(v)
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(os.Stdout, "%s\n", data)
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req fakeRequest
+		if json.Unmarshal(scanner.Bytes(), &req) == nil {
+			f.handle(&req, write)
+		}
+	}
+}
+
+func connectStdio(t *testing.T) *upstream.Proxy {
+	t.Helper()
+	proxy := upstream.NewProxy("test", "1.0", zerolog.Nop())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	require.NoError(t, proxy.Connect(ctx, upstream.Options{
+		Name:      "fake",
+		Transport: "stdio",
+		Stdio: upstream.StdioOptions{
+			Command: os.Args[0],
+			Env:     []string{fakeUpstreamEnv + "=1"},
+		},
+		Timeout: 5 * time.Second,
+	}))
+	t.Cleanup(func() { _ = proxy.Close() })
+	return proxy
+}
+
+func findTool(t *testing.T, proxy *upstream.Proxy, name string) *entities.Tool {
+	t.Helper()
+	for _, tool := range proxy.Tools() {
+		if tool.Name().String() == name {
+			return tool
+		}
+	}
+	t.Fatalf("tool %s not found", name)
+	return nil
+}
+
+func TestProxy_StdioReexportsNamespacedFeatures(t *testing.T) {
+	proxy := connectStdio(t)
+
+	var names []string
+	for _, tool := range proxy.Tools() {
+		names = append(names, tool.Name().String())
+		assert.Equal(t, "upstream", tool.Category())
+	}
+	assert.Equal(t, []string{"fake__echo", "fake__slow", "fake__stats", "fake__exit"}, names)
+
+	resources := proxy.Resources()
+	require.Len(t, resources, 1)
+	assert.Equal(t, "fake://readme", resources[0].URI().String())
+	content, err := resources[0].Read()
+	require.NoError(t, err)
+	assert.Equal(t, "# Fake", content.Text)
+
+	prompts := proxy.Prompts()
+	require.Len(t, prompts, 1)
+	assert.Equal(t, "fake__greet", prompts[0].Name().String())
+	require.Len(t, prompts[0].RequiredArguments(), 1)
+	messages, err := prompts[0].Generate(map[string]string{"who": "ops"})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello ops", messages.Messages[0].Content.Text)
+}
+
+func TestProxy_StdioForwardsProgress(t *testing.T) {
+	proxy := connectStdio(t)
+
+	var updates []entities.ToolProgress
+	ctx := entities.WithProgressReporter(context.Background(), func(update entities.ToolProgress) {
+		updates = append(updates, update)
+	})
+
+	result, err := findTool(t, proxy, "fake__echo").ExecuteContext(ctx, map[string]interface{}{"text": "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "hi", result.Content[0].Text)
+	require.Len(t, updates, 1)
+	assert.Equal(t, entities.ToolProgress{Progress: 1, Total: 2, Message: "working"}, updates[0])
+}
+
+func TestProxy_StdioForwardsCancellation(t *testing.T) {
+	proxy := connectStdio(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err := findTool(t, proxy, "fake__slow").ExecuteContext(ctx, map[string]interface{}{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	result, err := findTool(t, proxy, "fake__stats").ExecuteContext(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Content[0].Text, "upstream should have received notifications/cancelled")
+}
+
+func TestProxy_StdioProcessExitFailsCalls(t *testing.T) {
+	proxy := connectStdio(t)
+
+	_, err := findTool(t, proxy, "fake__exit").ExecuteContext(context.Background(), map[string]interface{}{})
+	assert.ErrorIs(t, err, upstream.ErrClientClosed)
+
+	_, err = findTool(t, proxy, "fake__echo").ExecuteContext(context.Background(), map[string]interface{}{"text": "x"})
+	assert.ErrorIs(t, err, upstream.ErrClientClosed)
+}
+
+func TestProxy_HTTPStreamableTransport(t *testing.T) {
+	t.Setenv("TFO_TEST_UPSTREAM_TOKEN", "secret")
+
+	f := &fakeUpstream{}
+	var mu sync.Mutex
+	var authHeaders, sessionHeaders []string
+	deleted := false
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		sessionHeaders = append(sessionHeaders, r.Header.Get("Mcp-Session-Id"))
+		if r.Method == http.MethodDelete {
+			deleted = true
+		}
+		mu.Unlock()
+		if r.Method == http.MethodDelete {
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		var req fakeRequest
+		require.NoError(t, json.Unmarshal(body, &req))
+		if req.ID == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		var out [][]byte
+		f.handle(&req, func(v interface{}) {
+			data, _ := json.Marshal(v)
+			out = append(out, data)
+		})
+		if req.Method == "initialize" {
+			w.Header().Set("Mcp-Session-Id", "session-1")
+		}
+
+		// Tool calls stream over SSE; everything else is plain JSON
+		if req.Method == "tools/call" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, data := range out {
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(out[0])
+	}))
+	defer srv.Close()
+
+	proxy := upstream.NewProxy("test", "1.0", zerolog.Nop())
+	require.NoError(t, proxy.Connect(context.Background(), upstream.Options{
+		Name:      "remote",
+		Transport: "http",
+		HTTP: upstream.HTTPOptions{
+			URL:     srv.URL,
+			Headers: map[string]string{"authorization": "Bearer ${TFO_TEST_UPSTREAM_TOKEN}"},
+		},
+	}))
+
+	var updates []entities.ToolProgress
+	ctx := entities.WithProgressReporter(context.Background(), func(update entities.ToolProgress) {
+		updates = append(updates, update)
+	})
+	result, err := findTool(t, proxy, "remote__echo").ExecuteContext(ctx, map[string]interface{}{"text": "over http"})
+	require.NoError(t, err)
+	assert.Equal(t, "over http", result.Content[0].Text)
+	assert.Len(t, updates, 1)
+
+	require.NoError(t, proxy.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.True(t, deleted, "session should be terminated on close")
+	for _, header := range authHeaders {
+		assert.Equal(t, "Bearer secret", header)
+	}
+	assert.Empty(t, sessionHeaders[0])
+	for _, header := range sessionHeaders[1:] {
+		assert.Equal(t, "session-1", header)
+	}
+}
+
+func TestProxy_ConnectErrors(t *testing.T) {
+	proxy := upstream.NewProxy("test", "1.0", zerolog.Nop())
+	defer func() { _ = proxy.Close() }()
+
+	err := proxy.Connect(context.Background(), upstream.Options{Name: "bad", Transport: "carrier-pigeon"})
+	assert.ErrorIs(t, err, upstream.ErrUnknownTransport)
+
+	err = proxy.Connect(context.Background(), upstream.Options{
+		Name:      "missing",
+		Transport: "stdio",
+		Stdio:     upstream.StdioOptions{Command: "/nonexistent/tfo-upstream"},
+	})
+	assert.Error(t, err)
+	assert.Empty(t, proxy.Tools())
+}
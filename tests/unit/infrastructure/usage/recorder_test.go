@@ -0,0 +1,136 @@
+package usage_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/repositories"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/usage"
+)
+
+// batchedRepository counts the flushes of an in-memory repository
+type batchedRepository struct {
+	*persistence.InMemoryLLMUsageRepository
+	mu      sync.Mutex
+	flushes int
+}
+
+func (r *batchedRepository) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushes++
+	return nil
+}
+
+func (r *batchedRepository) flushCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.flushes
+}
+
+func call(model vo.Model, input, output int) *entities.LLMUsage {
+	u := entities.NewLLMUsage(model, model)
+	u.InputTokens, u.OutputTokens = input, output
+	return u
+}
+
+func total(t *testing.T, repo repositories.ILLMUsageRepository, filter repositories.LLMUsageFilter) *repositories.LLMUsageSummary {
+	t.Helper()
+	summaries, err := repo.Summarize(context.Background(), filter)
+	require.NoError(t, err)
+	if len(summaries) == 0 {
+		return &repositories.LLMUsageSummary{}
+	}
+	return summaries[0]
+}
+
+func TestRecorder_PricesAndWrites(t *testing.T) {
+	repo := &batchedRepository{InMemoryLLMUsageRepository: persistence.NewInMemoryLLMUsageRepository(100)}
+	recorder := usage.NewRecorder(repo, vo.DefaultPriceTable(), usage.Options{BufferSize: 10, FlushInterval: time.Hour, OrganizationID: "acme"}, zerolog.Nop())
+	recorder.Start()
+
+	recorder.RecordUsage(context.Background(), call(vo.ModelClaudeOpus47, 1_000_000, 100_000))
+	tenant := call(vo.ModelGPT54, 1000, 0)
+	tenant.OrganizationID = "globex"
+	recorder.RecordUsage(context.Background(), tenant)
+	recorder.RecordUsage(context.Background(), call("unknown-model", 1000, 1000))
+	require.NoError(t, recorder.Close())
+	assert.Equal(t, 1, repo.flushCount(), "close flushes batched records")
+
+	acme := total(t, repo, repositories.LLMUsageFilter{OrganizationID: "acme"})
+	assert.Equal(t, int64(2), acme.Requests, "calls outside an organization default to the configured one")
+	assert.InDelta(t, 5.0+2.5, acme.CostUSD, 1e-9)
+	assert.Equal(t, int64(1), acme.UnpricedRequests)
+
+	globex := total(t, repo, repositories.LLMUsageFilter{OrganizationID: "globex"})
+	assert.Equal(t, int64(1), globex.Requests)
+	assert.InDelta(t, 0.0025, globex.CostUSD, 1e-9)
+
+	// Records after close are ignored
+	recorder.RecordUsage(context.Background(), call(vo.ModelGPT54, 1, 1))
+	require.NoError(t, recorder.Close())
+	assert.Equal(t, int64(3), total(t, repo, repositories.LLMUsageFilter{}).Requests)
+}
+
+func TestRecorder_Price(t *testing.T) {
+	prices := vo.DefaultPriceTable().Override("acme-2026", map[vo.Model]vo.ModelPrice{
+		vo.ModelClaudeSonnet46: {Input: 2, Output: 10, CacheRead: 0.2, CacheWrite: 2.5},
+	})
+	recorder := usage.NewRecorder(persistence.NewInMemoryLLMUsageRepository(1), prices, usage.Options{}, zerolog.Nop())
+
+	u := call(vo.ModelClaudeSonnet46, 1_000_000, 1_000_000)
+	u.CacheReadTokens, u.CacheWriteTokens = 1_000_000, 1_000_000
+	recorder.Price(u)
+	assert.InDelta(t, 2+10+0.2+2.5, u.CostUSD, 1e-9)
+	assert.Equal(t, "acme-2026", u.PricingVersion)
+
+	local := call("ollama/llama3.2", 1000, 1000)
+	recorder.Price(local)
+	assert.Zero(t, local.CostUSD)
+	assert.Equal(t, "acme-2026", local.PricingVersion, "local models are priced, at zero")
+
+	unknown := call("unknown-model", 1000, 1000)
+	recorder.Price(unknown)
+	assert.Zero(t, unknown.CostUSD)
+	assert.Empty(t, unknown.PricingVersion)
+}
+
+func TestRecorder_DropsWhenBufferFull(t *testing.T) {
+	repo := persistence.NewInMemoryLLMUsageRepository(100)
+	// Not started, so nothing drains the buffer
+	recorder := usage.NewRecorder(repo, vo.DefaultPriceTable(), usage.Options{BufferSize: 2}, zerolog.Nop())
+
+	for i := 0; i < 5; i++ {
+		recorder.RecordUsage(context.Background(), call(vo.ModelGPT54, 1, 1))
+	}
+	assert.Equal(t, int64(3), recorder.Dropped())
+
+	recorder.Start()
+	require.NoError(t, recorder.Close())
+	assert.Equal(t, int64(2), total(t, repo, repositories.LLMUsageFilter{}).Requests)
+}
+
+func TestRecorder_TruncatesErrors(t *testing.T) {
+	repo := persistence.NewInMemoryLLMUsageRepository(100)
+	recorder := usage.NewRecorder(repo, vo.DefaultPriceTable(), usage.Options{BufferSize: 1}, zerolog.Nop())
+	recorder.Start()
+
+	failed := call(vo.ModelGPT54, 0, 0)
+	failed.Status = entities.LLMCallError
+	failed.ErrorMessage = strings.Repeat("x", 5000)
+	recorder.RecordUsage(context.Background(), failed)
+	require.NoError(t, recorder.Close())
+
+	assert.Len(t, failed.ErrorMessage, 1024)
+	assert.Equal(t, int64(1), total(t, repo, repositories.LLMUsageFilter{}).Errors)
+}
@@ -0,0 +1,243 @@
+package server_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/server"
+)
+
+type noopPublisher struct{}
+
+func (noopPublisher) Publish(ctx context.Context, event interface{}) error { return nil }
+
+// stdioHarness drives a server over in-memory pipes
+type stdioHarness struct {
+	in     *io.PipeWriter
+	out    *bufio.Scanner
+	done   chan error
+	cancel context.CancelFunc
+
+	initResult map[string]interface{}
+}
+
+func newStdioHarness(t *testing.T, tools ...*entities.Tool) *stdioHarness {
+	t.Helper()
+	return newStdioHarnessWith(t, nil, tools...)
+}
+
+// newStdioHarnessWith builds a server, letting configure attach extra handlers before it starts
+func newStdioHarnessWith(t *testing.T, configure func(*server.Server, *handlers.ToolHandler), tools ...*entities.Tool) *stdioHarness {
+	t.Helper()
+
+	sessionRepo := persistence.NewInMemorySessionRepository()
+	toolRepo := persistence.NewInMemoryToolRepository()
+	for _, tool := range tools {
+		require.NoError(t, toolRepo.Register(context.Background(), tool))
+	}
+
+	cfg := config.DefaultConfig()
+	toolHandler := handlers.NewToolHandler(sessionRepo, toolRepo, noopPublisher{})
+	srv := server.NewServer(
+		cfg,
+		zerolog.Nop(),
+		handlers.NewSessionHandler(sessionRepo, noopPublisher{}),
+		toolHandler,
+		nil,
+	)
+	if configure != nil {
+		configure(srv, toolHandler)
+	}
+
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	srv.SetIO(inReader, outWriter)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &stdioHarness{
+		in:     inWriter,
+		out:    bufio.NewScanner(outReader),
+		done:   make(chan error, 1),
+		cancel: cancel,
+	}
+	go func() {
+		h.done <- srv.Run(ctx)
+		_ = outWriter.Close()
+	}()
+	t.Cleanup(func() {
+		cancel()
+		_ = inWriter.Close()
+	})
+
+	h.send(t, 1, "initialize", map[string]interface{}{
+		"protocolVersion": vo.CurrentMCPProtocolVersion,
+		"clientInfo":      map[string]interface{}{"name": "test", "version": "1.0"},
+	})
+	h.initResult = h.read(t)
+
+	return h
+}
+
+func (h *stdioHarness) send(t *testing.T, id interface{}, method string, params interface{}) {
+	t.Helper()
+	msg := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+	if id != nil {
+		msg["id"] = id
+	}
+	data, err := json.Marshal(msg)
+	require.NoError(t, err)
+	_, err = fmt.Fprintf(h.in, "%s\n", data)
+	require.NoError(t, err)
+}
+
+func (h *stdioHarness) read(t *testing.T) map[string]interface{} {
+	t.Helper()
+	lines := make(chan string, 1)
+	go func() {
+		if h.out.Scan() {
+			lines <- h.out.Text()
+		}
+		close(lines)
+	}()
+	select {
+	case line, ok := <-lines:
+		require.True(t, ok, "server closed output")
+		var msg map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &msg))
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for server output")
+		return nil
+	}
+}
+
+func newBlockingTool(t *testing.T) *entities.Tool {
+	t.Helper()
+	name, _ := vo.NewToolName("blocking")
+	desc, _ := vo.NewToolDescription("Blocks until cancelled")
+	tool, err := entities.NewTool(name, desc, &entities.JSONSchema{Type: "object"})
+	require.NoError(t, err)
+	tool.SetContextHandler(func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		entities.ReportProgress(ctx, entities.ToolProgress{Progress: 1, Message: "started"})
+		<-ctx.Done()
+		return entities.NewTextToolResult("finished"), nil
+	})
+	return tool
+}
+
+func TestStdio_ToolProgressAndCancellation(t *testing.T) {
+	h := newStdioHarness(t, newBlockingTool(t))
+
+	h.send(t, 2, "tools/call", map[string]interface{}{
+		"name":      "blocking",
+		"arguments": map[string]interface{}{},
+		"_meta":     map[string]interface{}{"progressToken": "tok-1"},
+	})
+
+	progress := h.read(t)
+	assert.Equal(t, "notifications/progress", progress["method"])
+	params := progress["params"].(map[string]interface{})
+	assert.Equal(t, "tok-1", params["progressToken"])
+	assert.Equal(t, float64(1), params["progress"])
+	assert.Equal(t, "started", params["message"])
+
+	// The loop keeps serving requests while the tool runs
+	h.send(t, 3, "ping", map[string]interface{}{})
+	pong := h.read(t)
+	assert.Equal(t, float64(3), pong["id"])
+
+	h.send(t, nil, "notifications/cancelled", map[string]interface{}{"requestId": 2, "reason": "user abort"})
+
+	// No response is sent for the cancelled request
+	h.send(t, 4, "ping", map[string]interface{}{})
+	next := h.read(t)
+	assert.Equal(t, float64(4), next["id"])
+
+	require.NoError(t, h.in.Close())
+	select {
+	case err := <-h.done:
+		assert.ErrorIs(t, err, io.EOF)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+	assert.False(t, h.out.Scan(), "unexpected output after cancellation")
+}
+
+func TestStdio_ToolCallWithoutProgressToken(t *testing.T) {
+	name, _ := vo.NewToolName("quick")
+	desc, _ := vo.NewToolDescription("Returns immediately")
+	tool, err := entities.NewTool(name, desc, &entities.JSONSchema{Type: "object"})
+	require.NoError(t, err)
+	tool.SetContextHandler(func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		entities.ReportProgress(ctx, entities.ToolProgress{Progress: 1, Message: "ignored"})
+		return entities.NewTextToolResult("done"), nil
+	})
+	h := newStdioHarness(t, tool)
+
+	h.send(t, 2, "tools/call", map[string]interface{}{"name": "quick", "arguments": map[string]interface{}{}})
+
+	resp := h.read(t)
+	assert.Equal(t, float64(2), resp["id"])
+	result := resp["result"].(map[string]interface{})
+	content := result["content"].([]interface{})
+	assert.Equal(t, "done", content[0].(map[string]interface{})["text"])
+}
+
+func TestStdio_RegisteredResourcesAndPrompts(t *testing.T) {
+	uri, err := vo.NewResourceURI("upstream://docs/readme")
+	require.NoError(t, err)
+	resource, err := entities.NewResource(uri, "docs__readme")
+	require.NoError(t, err)
+	resource.SetReader(func(uri string) (*entities.ResourceContent, error) {
+		return &entities.ResourceContent{URI: uri, Text: "proxied"}, nil
+	})
+
+	name, err := vo.NewToolName("docs__summarize")
+	require.NoError(t, err)
+	prompt, err := entities.NewPrompt(name, "Summarize the docs")
+	require.NoError(t, err)
+
+	h := newStdioHarnessWith(t, func(srv *server.Server, _ *handlers.ToolHandler) {
+		srv.RegisterResource(resource)
+		srv.RegisterPrompt(prompt)
+	})
+
+	h.send(t, 2, "resources/read", map[string]interface{}{"uri": "upstream://docs/readme"})
+	read := h.read(t)["result"].(map[string]interface{})
+	contents := read["contents"].([]interface{})
+	assert.Equal(t, "proxied", contents[0].(map[string]interface{})["text"])
+
+	h.send(t, 3, "prompts/list", map[string]interface{}{})
+	list := h.read(t)["result"].(map[string]interface{})
+	prompts := list["prompts"].([]interface{})
+	require.Len(t, prompts, 1)
+	assert.Equal(t, "docs__summarize", prompts[0].(map[string]interface{})["name"])
+}
+
+func TestStdio_InitializeReportsLLMProviders(t *testing.T) {
+	h := newStdioHarnessWith(t, func(srv *server.Server, _ *handlers.ToolHandler) {
+		srv.SetLLMProviders([]string{"anthropic", "ollama"})
+	})
+
+	caps := h.initResult["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	llm := caps["experimental"].(map[string]interface{})["llm"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"anthropic", "ollama"}, llm["providers"])
+
+	h = newStdioHarness(t)
+	caps = h.initResult["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	assert.NotContains(t, caps, "experimental")
+}
@@ -0,0 +1,129 @@
+package server_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/queue"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/server"
+)
+
+func newTaskStdioHarness(t *testing.T, tools ...*entities.Tool) *stdioHarness {
+	t.Helper()
+	return newStdioHarnessWith(t, func(srv *server.Server, toolHandler *handlers.ToolHandler) {
+		pool := queue.NewWorkerPool(2, 10)
+		dispatcher := queue.NewToolTaskDispatcher(pool)
+		taskHandler := handlers.NewTaskHandler(toolHandler, dispatcher, handlers.TaskOptions{})
+		dispatcher.Handle(taskHandler.RunTask)
+		pool.Start(context.Background())
+		t.Cleanup(func() { _ = pool.Close() })
+		srv.SetTaskHandler(taskHandler)
+	}, tools...)
+}
+
+func newGatedTool(t *testing.T, release <-chan struct{}) *entities.Tool {
+	t.Helper()
+	name, _ := vo.NewToolName("gated")
+	desc, _ := vo.NewToolDescription("Returns once released")
+	tool, err := entities.NewTool(name, desc, &entities.JSONSchema{Type: "object"})
+	require.NoError(t, err)
+	tool.SetContextHandler(func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		select {
+		case <-release:
+			return entities.NewTextToolResult("released"), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+	return tool
+}
+
+func TestStdio_TaskAugmentedToolCall(t *testing.T) {
+	release := make(chan struct{})
+	h := newTaskStdioHarness(t, newGatedTool(t, release))
+
+	caps := h.initResult["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	require.Contains(t, caps, "tasks")
+
+	h.send(t, 2, "tools/call", map[string]interface{}{
+		"name":      "gated",
+		"arguments": map[string]interface{}{},
+		"task":      map[string]interface{}{"ttl": 60000},
+	})
+	resp := h.read(t)
+	assert.Equal(t, float64(2), resp["id"])
+	task := resp["result"].(map[string]interface{})["task"].(map[string]interface{})
+	taskID := task["taskId"].(string)
+	assert.Equal(t, "working", task["status"])
+	assert.Equal(t, float64(60000), task["ttl"])
+
+	h.send(t, 3, "tasks/get", map[string]interface{}{"taskId": taskID})
+	got := h.read(t)
+	assert.Equal(t, "working", got["result"].(map[string]interface{})["status"])
+
+	// tasks/result blocks without stalling other requests
+	h.send(t, 4, "tasks/result", map[string]interface{}{"taskId": taskID})
+	h.send(t, 5, "tasks/list", map[string]interface{}{})
+	list := h.read(t)
+	assert.Equal(t, float64(5), list["id"])
+	assert.Len(t, list["result"].(map[string]interface{})["tasks"], 1)
+
+	close(release)
+	result := h.read(t)
+	assert.Equal(t, float64(4), result["id"])
+	body := result["result"].(map[string]interface{})
+	assert.Equal(t, "released", body["content"].([]interface{})[0].(map[string]interface{})["text"])
+	related := body["_meta"].(map[string]interface{})["io.modelcontextprotocol/related-task"].(map[string]interface{})
+	assert.Equal(t, taskID, related["taskId"])
+
+	h.send(t, 6, "tasks/cancel", map[string]interface{}{"taskId": taskID})
+	cancelErr := h.read(t)
+	assert.Equal(t, float64(vo.ErrorCodeInvalidParams), cancelErr["error"].(map[string]interface{})["code"])
+}
+
+func TestStdio_TaskCancel(t *testing.T) {
+	h := newTaskStdioHarness(t, newGatedTool(t, make(chan struct{})))
+
+	h.send(t, 2, "tools/call", map[string]interface{}{"name": "gated", "task": map[string]interface{}{}})
+	taskID := h.read(t)["result"].(map[string]interface{})["task"].(map[string]interface{})["taskId"].(string)
+
+	h.send(t, 3, "tasks/cancel", map[string]interface{}{"taskId": taskID})
+	cancelled := h.read(t)
+	assert.Equal(t, "cancelled", cancelled["result"].(map[string]interface{})["status"])
+
+	h.send(t, 4, "tasks/result", map[string]interface{}{"taskId": taskID})
+	result := h.read(t)
+	assert.Equal(t, float64(vo.ErrorCodeCancelled), result["error"].(map[string]interface{})["code"])
+
+	h.send(t, 5, "tasks/get", map[string]interface{}{"taskId": "missing"})
+	missing := h.read(t)
+	assert.Equal(t, float64(vo.ErrorCodeInvalidParams), missing["error"].(map[string]interface{})["code"])
+}
+
+func TestStdio_TasksWithoutTaskHandler(t *testing.T) {
+	h := newStdioHarness(t, newGatedTool(t, closedChannel()))
+
+	caps := h.initResult["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	assert.NotContains(t, caps, "tasks")
+
+	// The task parameter is ignored and the call runs inline
+	h.send(t, 2, "tools/call", map[string]interface{}{"name": "gated", "task": map[string]interface{}{}})
+	resp := h.read(t)
+	assert.Contains(t, resp["result"], "content")
+
+	h.send(t, 3, "tasks/list", map[string]interface{}{})
+	list := h.read(t)
+	assert.Equal(t, float64(vo.ErrorCodeMethodNotFound), list["error"].(map[string]interface{})["code"])
+}
+
+func closedChannel() <-chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
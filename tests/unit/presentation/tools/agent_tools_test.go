@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/commands"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	appsvc "github.com/telemetryflow/telemetryflow-go-mcp/internal/application/services"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+	builtin "github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/tools"
+)
+
+// scriptedAgentLLM answers each request with the next scripted response; streams replay it as
+// one text delta per word
+type scriptedAgentLLM struct {
+	mu        sync.Mutex
+	responses []*services.ClaudeResponse
+	requests  []*services.ClaudeRequest
+}
+
+func (s *scriptedAgentLLM) CreateMessage(ctx context.Context, req *services.ClaudeRequest) (*services.ClaudeResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+	if len(s.responses) == 0 {
+		return nil, errors.New("no scripted response")
+	}
+	response := s.responses[0]
+	s.responses = s.responses[1:]
+	return response, nil
+}
+func (s *scriptedAgentLLM) CreateMessageStream(ctx context.Context, req *services.ClaudeRequest) (<-chan *services.ClaudeStreamEvent, error) {
+	response, err := s.CreateMessage(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	events := []*services.ClaudeStreamEvent{{Type: "message_start", Message: &services.ClaudeResponse{ServedBy: response.ServedBy}}}
+	for i, block := range response.Content {
+		events = append(events, &services.ClaudeStreamEvent{Type: "content_block_start", Index: i, ContentBlock: &entities.ContentBlock{Type: block.Type}})
+		for _, word := range strings.SplitAfter(block.Text, " ") {
+			events = append(events, &services.ClaudeStreamEvent{Type: "content_block_delta", Index: i, Delta: &services.ClaudeDelta{Type: "text_delta", Text: word}})
+		}
+		events = append(events, &services.ClaudeStreamEvent{Type: "content_block_stop", Index: i})
+	}
+	events = append(events, &services.ClaudeStreamEvent{Type: "message_delta", Delta: &services.ClaudeDelta{StopReason: "end_turn"}, Usage: response.Usage})
+
+	ch := make(chan *services.ClaudeStreamEvent, len(events))
+	for _, event := range events {
+		ch <- event
+	}
+	close(ch)
+	return ch, nil
+}
+func (s *scriptedAgentLLM) CountTokens(ctx context.Context, req *services.ClaudeRequest) (int, error) {
+	return 0, nil
+}
+func (s *scriptedAgentLLM) ValidateRequest(req *services.ClaudeRequest) error { return nil }
+
+// newAgentFixture wires analyze_telemetry to a real tool handler, so the agent's tool calls take
+// the same path as client calls
+func newAgentFixture(t *testing.T, llm *scriptedAgentLLM, allowed ...string) (*handlers.ToolHandler, vo.SessionID) {
+	t.Helper()
+	ctx := context.Background()
+
+	sessionRepo := persistence.NewInMemorySessionRepository()
+	toolRepo := persistence.NewInMemoryToolRepository()
+	session := aggregates.NewSession()
+	require.NoError(t, sessionRepo.Save(ctx, session))
+
+	toolHandler := handlers.NewToolHandler(sessionRepo, toolRepo, nopPublisher{})
+	agent := appsvc.NewAgentService(llm, toolHandler, appsvc.AgentOptions{
+		MaxIterations: 3, TokenBudget: 10000, MaxParallelTools: 2, AllowedTools: allowed,
+	})
+
+	registry := builtin.NewToolRegistry(nil)
+	registry.SetAgent(agent, vo.ModelClaudeSonnet46, time.Minute)
+	for _, tool := range registry.GetTools() {
+		require.NoError(t, toolRepo.Register(ctx, tool))
+	}
+	return toolHandler, session.ID()
+}
+
+func TestAnalyzeTelemetry(t *testing.T) {
+	llm := &scriptedAgentLLM{responses: []*services.ClaudeResponse{
+		{Content: []entities.ContentBlock{
+			{Type: vo.ContentTypeToolUse, ID: "t1", Name: "echo", Input: map[string]interface{}{"message": "p99 412ms"}},
+		}, Usage: &services.ClaudeUsage{InputTokens: 100, OutputTokens: 20}},
+		{Content: []entities.ContentBlock{
+			{Type: vo.ContentTypeText, Text: "Checkout p99 is 412ms."},
+		}, Usage: &services.ClaudeUsage{InputTokens: 150, OutputTokens: 30}, ServedBy: vo.ModelClaudeSonnet46},
+	}}
+	toolHandler, sessionID := newAgentFixture(t, llm, "echo")
+
+	result, err := toolHandler.HandleExecuteTool(context.Background(), &commands.ExecuteToolCommand{
+		SessionID: sessionID,
+		Name:      "analyze_telemetry",
+		Arguments: map[string]interface{}{"question": "How is checkout latency?", "context_type": "metrics"},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+	assert.Equal(t, "Checkout p99 is 412ms.", result.Content[0].Text)
+	assert.Equal(t, string(vo.ModelClaudeSonnet46), result.Meta["model"])
+
+	run, ok := result.Meta["agent"].(*appsvc.AgentResult)
+	require.True(t, ok)
+	assert.Equal(t, 2, run.Iterations)
+	require.Len(t, run.ToolCalls, 1)
+	assert.Equal(t, "echo", run.ToolCalls[0].Tool)
+	assert.False(t, run.ToolCalls[0].IsError)
+
+	// Only allowed tools are offered, and the echo result reaches the model
+	require.Len(t, llm.requests, 2)
+	require.Len(t, llm.requests[0].Tools, 1)
+	assert.Equal(t, "echo", llm.requests[0].Tools[0].Name)
+	assert.True(t, strings.Contains(llm.requests[0].SystemPrompt.String(), "collect_telemetry_context"))
+	toolResult := llm.requests[1].Messages[2].Content[0]
+	assert.Equal(t, vo.ContentTypeToolResult, toolResult.Type)
+	assert.Contains(t, toolResult.Content, "p99 412ms")
+}
+
+func TestAnalyzeTelemetry_StopsAtLimit(t *testing.T) {
+	responses := make([]*services.ClaudeResponse, 5)
+	for i := range responses {
+		responses[i] = &services.ClaudeResponse{Content: []entities.ContentBlock{
+			{Type: vo.ContentTypeText, Text: "Still looking"},
+			{Type: vo.ContentTypeToolUse, ID: "t", Name: "echo", Input: map[string]interface{}{"message": "x"}},
+		}}
+	}
+	toolHandler, sessionID := newAgentFixture(t, &scriptedAgentLLM{responses: responses}, "echo")
+
+	result, err := toolHandler.HandleExecuteTool(context.Background(), &commands.ExecuteToolCommand{
+		SessionID: sessionID,
+		Name:      "analyze_telemetry",
+		Arguments: map[string]interface{}{"question": "Loop", "max_iterations": float64(2)},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, appsvc.ErrAgentMaxIterations.Error())
+	assert.Contains(t, result.Content[0].Text, "Still looking")
+	assert.Equal(t, 2, result.Meta["agent"].(*appsvc.AgentResult).Iterations)
+}
+
+func TestAnalyzeTelemetry_InputErrors(t *testing.T) {
+	toolHandler, sessionID := newAgentFixture(t, &scriptedAgentLLM{}, "echo")
+
+	for name, input := range map[string]map[string]interface{}{
+		"missing question":     {},
+		"invalid context type": {"question": "q", "context_type": "weather"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, err := toolHandler.HandleExecuteTool(context.Background(), &commands.ExecuteToolCommand{
+				SessionID: sessionID, Name: "analyze_telemetry", Arguments: input,
+			})
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/resources"
+	builtin "github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/tools"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestClaudeConversation_Attachments(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "latency.png"), pngHeader, 0o600))
+	outside := filepath.Join(t.TempDir(), "secret.pdf")
+	require.NoError(t, os.WriteFile(outside, []byte("%PDF-1.7"), 0o600))
+
+	uri, err := vo.NewResourceURI("upstream://reports/weekly")
+	require.NoError(t, err)
+	resource, err := entities.NewResource(uri, "reports__weekly")
+	require.NoError(t, err)
+	resource.SetReader(func(uri string) (*entities.ResourceContent, error) {
+		return &entities.ResourceContent{URI: uri, MimeType: "application/pdf", Blob: base64.StdEncoding.EncodeToString([]byte("%PDF-1.7"))}, nil
+	})
+
+	llm := &scriptedAgentLLM{responses: []*services.ClaudeResponse{textResponse("p99 doubled at 14:00.")}}
+	registry := builtin.NewToolRegistry(llm)
+	registry.SetResourceHandler(resources.NewResourceHandler([]string{dir}, resources.DefaultMaxFileSize))
+	registry.RegisterResource(resource)
+	tool, _ := registry.GetTool("claude_conversation")
+
+	result, err := tool.ExecuteContext(context.Background(), map[string]interface{}{
+		"message": "What changed?",
+		"context": "service: checkout",
+		"attachments": []interface{}{
+			map[string]interface{}{"data": base64.StdEncoding.EncodeToString(pngHeader), "media_type": "image/png"},
+			map[string]interface{}{"path": filepath.Join(dir, "latency.png")},
+			map[string]interface{}{"uri": "upstream://reports/weekly", "name": "weekly.pdf"},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+
+	// Attachments sit between the cached context and the question
+	content := llm.requests[0].Messages[0].Content
+	require.Len(t, content, 5)
+	assert.Equal(t, "service: checkout", content[0].Text)
+	assert.Equal(t, vo.ContentTypeImage, content[1].Type)
+	assert.Equal(t, "image/png", content[1].Source.MediaType)
+	assert.Equal(t, content[1].Source, content[2].Source)
+	assert.Equal(t, vo.ContentTypeDocument, content[3].Type)
+	assert.Equal(t, "weekly.pdf", content[3].Name)
+	assert.Equal(t, "What changed?", content[4].Text)
+
+	for name, attachment := range map[string]map[string]interface{}{
+		"outside the allowed paths": {"path": outside},
+		"unknown resource":          {"uri": "upstream://reports/missing"},
+		"two sources":               {"path": outside, "uri": "upstream://reports/weekly"},
+		"not base64":                {"data": "%%%", "media_type": "image/png"},
+		"unsupported type":          {"data": base64.StdEncoding.EncodeToString([]byte("plain text")), "media_type": "text/plain"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, err := tool.ExecuteContext(context.Background(), map[string]interface{}{
+				"message": "What changed?", "attachments": []interface{}{attachment},
+			})
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+		})
+	}
+	assert.Len(t, llm.requests, 1, "refused attachments never reach the model")
+}
+
+func TestConversationTools_Attachments(t *testing.T) {
+	llm := &scriptedAgentLLM{responses: []*services.ClaudeResponse{textResponse("Error rate spiked at 14:00.")}}
+	f := newConversationFixture(t, llm, handlers.ConversationLimits{})
+	image := map[string]interface{}{"data": base64.StdEncoding.EncodeToString(pngHeader), "media_type": "image/png"}
+
+	id := decodeConversation(t, f.call(t, 0, "start_conversation", nil)).ID
+	reply := f.call(t, 0, "send_conversation_message", map[string]interface{}{
+		"conversation_id": id, "message": "What does this show?", "attachments": []interface{}{image},
+	})
+	require.False(t, reply.IsError, "%v", reply.Content)
+	require.Len(t, llm.requests, 1)
+	assert.Equal(t, vo.ContentTypeImage, llm.requests[0].Messages[0].Content[0].Type)
+
+	conversation := decodeConversation(t, f.call(t, 0, "get_conversation", map[string]interface{}{"conversation_id": id}))
+	assert.Equal(t, "image", conversation.Messages[0].Content[0].Type)
+	assert.Equal(t, "image/png", conversation.Messages[0].Content[0].MediaType)
+
+	// A model without vision refuses the attachment and the conversation is left as it was
+	id = decodeConversation(t, f.call(t, 0, "start_conversation", map[string]interface{}{"model": "deepseek-chat"})).ID
+	reply = f.call(t, 0, "send_conversation_message", map[string]interface{}{
+		"conversation_id": id, "message": "What does this show?", "attachments": []interface{}{image},
+	})
+	assert.True(t, reply.IsError)
+	assert.Contains(t, reply.Content[0].Text, "does not accept image input")
+	assert.Len(t, llm.requests, 1)
+	assert.Equal(t, 0, decodeConversation(t, f.call(t, 0, "get_conversation", map[string]interface{}{"conversation_id": id})).MessageCount)
+}
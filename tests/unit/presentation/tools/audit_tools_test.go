@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+	builtin "github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/tools"
+)
+
+func newAuditRegistry(t *testing.T) (*builtin.ToolRegistry, *persistence.InMemoryToolExecutionRepository) {
+	t.Helper()
+	repo := persistence.NewInMemoryToolExecutionRepository(100)
+	registry := builtin.NewToolRegistry(nil)
+	registry.SetAuditHandler(handlers.NewAuditHandler(repo))
+	return registry, repo
+}
+
+func searchAuditTrail(t *testing.T, registry *builtin.ToolRegistry, input map[string]interface{}) *entities.ToolResult {
+	t.Helper()
+	tool, ok := registry.GetTool("search_audit_trail")
+	require.True(t, ok, "search_audit_trail is registered")
+	result, err := tool.ExecuteContext(context.Background(), input)
+	require.NoError(t, err)
+	return result
+}
+
+func TestSearchAuditTrail(t *testing.T) {
+	registry, repo := newAuditRegistry(t)
+	ctx := context.Background()
+
+	old := entities.NewToolExecution("", "query_logs", nil)
+	old.ExecutedAt = time.Now().Add(-2 * time.Hour)
+	failed := entities.NewToolExecution("", "query_metrics", map[string]interface{}{"query": "up"})
+	failed.Status = entities.ToolExecutionError
+	failed.ErrorMessage = "backend unavailable"
+	failed.APIKeyID = "key-1"
+	require.NoError(t, repo.Save(ctx, old))
+	require.NoError(t, repo.Save(ctx, failed))
+
+	result := searchAuditTrail(t, registry, map[string]interface{}{"since": "1h"})
+	require.False(t, result.IsError, resultText(t, result))
+
+	var body struct {
+		Count      int                      `json:"count"`
+		Executions []map[string]interface{} `json:"executions"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &body))
+	require.Equal(t, 1, body.Count)
+	assert.Equal(t, failed.ID, body.Executions[0]["id"])
+	assert.Equal(t, "error", body.Executions[0]["status"])
+	assert.Equal(t, "backend unavailable", body.Executions[0]["error"])
+	assert.Equal(t, "key-1", body.Executions[0]["apiKeyId"])
+
+	result = searchAuditTrail(t, registry, map[string]interface{}{"tool": "query_logs", "limit": float64(5)})
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &body))
+	require.Equal(t, 1, body.Count)
+	assert.Equal(t, old.ID, body.Executions[0]["id"])
+}
+
+func TestSearchAuditTrail_InvalidInput(t *testing.T) {
+	registry, _ := newAuditRegistry(t)
+
+	for _, input := range []map[string]interface{}{
+		{"since": "yesterday"},
+		{"until": "-1h"},
+		{"status": "exploded"},
+		{"since": "1h", "until": "2h"},
+	} {
+		result := searchAuditTrail(t, registry, input)
+		assert.True(t, result.IsError, "input %v", input)
+	}
+}
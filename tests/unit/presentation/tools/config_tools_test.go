@@ -0,0 +1,127 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+	builtin "github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/tools"
+)
+
+func TestConfigToolSet_Sync(t *testing.T) {
+	ctx := context.Background()
+	repo := persistence.NewInMemoryToolRepository()
+	srv, _ := newHTTPToolServer(t, http.StatusOK, `{"ok": true}`)
+
+	for _, tool := range builtin.NewToolRegistry(nil).GetTools() {
+		require.NoError(t, repo.Register(ctx, tool))
+	}
+	builtinCount, err := repo.Count(ctx)
+	require.NoError(t, err)
+
+	httpTool := func(name, description string) config.HTTPToolConfig {
+		return config.HTTPToolConfig{Name: name, Description: description, URL: srv.URL, ResponsePath: "$.ok"}
+	}
+	find := func(name string) *entities.Tool {
+		toolName, err := vo.NewToolName(name)
+		require.NoError(t, err)
+		tool, err := repo.FindByName(ctx, toolName)
+		require.NoError(t, err)
+		return tool
+	}
+	sync := func(set *builtin.ConfigToolSet, cfg *config.Config) error {
+		tools, err := builtin.BuildConfigTools(cfg, repo, nil)
+		require.NoError(t, err)
+		return set.Sync(ctx, repo, tools)
+	}
+
+	set := builtin.NewConfigToolSet()
+	require.NoError(t, sync(set, &config.Config{
+		HTTPTools: []config.HTTPToolConfig{httpTool("alpha", "first"), httpTool("beta", "second")},
+		Pipelines: []config.PipelineConfig{{
+			Name:        "check_alpha",
+			Description: "Call alpha and echo the result",
+			Steps: []config.PipelineStepConfig{
+				{ID: "call", Tool: "alpha"},
+				{ID: "say", Tool: "echo", DependsOn: []string{"call"}, Arguments: map[string]interface{}{"message": "alpha={{.steps.call.text}}"}},
+			},
+		}},
+	}))
+	assert.Equal(t, []string{"alpha", "beta", "check_alpha"}, set.Names())
+
+	// Pipelines resolve their steps through the repository, including other config tools
+	result, err := find("check_alpha").ExecuteContext(ctx, nil)
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Contains(t, resultText(t, result), "alpha=true")
+
+	// Reload: alpha changes, beta and the pipeline are removed, gamma is added
+	require.NoError(t, sync(set, &config.Config{
+		HTTPTools: []config.HTTPToolConfig{httpTool("alpha", "updated"), httpTool("gamma", "third")},
+	}))
+	assert.Equal(t, []string{"alpha", "gamma"}, set.Names())
+	assert.Equal(t, "updated", find("alpha").Description().String())
+	assert.Nil(t, find("beta"))
+	assert.Nil(t, find("check_alpha"))
+
+	count, err := repo.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, builtinCount+2, count)
+
+	// Built-in tool names are never replaced
+	err = sync(set, &config.Config{
+		HTTPTools: []config.HTTPToolConfig{httpTool("alpha", "updated"), httpTool("read_file", "shadow")},
+	})
+	assert.ErrorIs(t, err, builtin.ErrToolNameTaken)
+	assert.Equal(t, []string{"alpha"}, set.Names())
+	assert.NotEqual(t, "shadow", find("read_file").Description().String())
+}
+
+func TestBuildConfigTools_ReportsInvalidDefinitions(t *testing.T) {
+	cfg := &config.Config{
+		HTTPTools: []config.HTTPToolConfig{
+			{Name: "good", Description: "ok", URL: "https://example.com"},
+			{Name: "bad", Description: "broken", URL: "https://example.com/{{"},
+		},
+		Pipelines: []config.PipelineConfig{{Name: "empty", Description: "no steps"}},
+	}
+
+	tools, err := builtin.BuildConfigTools(cfg, persistence.NewInMemoryToolRepository(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad")
+	assert.Contains(t, err.Error(), "empty")
+	require.Len(t, tools, 1)
+	assert.Equal(t, "good", tools[0].Name().String())
+}
+
+func TestConfigToolSet_Load(t *testing.T) {
+	ctx := context.Background()
+	repo := persistence.NewInMemoryToolRepository()
+	for _, tool := range builtin.NewToolRegistry(nil).GetTools() {
+		require.NoError(t, repo.Register(ctx, tool))
+	}
+
+	// Tools that fail to build or clash with a built-in are reported; the rest are registered
+	set := builtin.NewConfigToolSet()
+	err := set.Load(ctx, &config.Config{
+		HTTPTools: []config.HTTPToolConfig{
+			{Name: "good", Description: "ok", URL: "https://example.com"},
+			{Name: "bad", Description: "broken", URL: "https://example.com/{{"},
+			{Name: "read_file", Description: "shadow", URL: "https://example.com"},
+		},
+	}, repo)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad")
+	assert.ErrorIs(t, err, builtin.ErrToolNameTaken)
+	assert.Equal(t, []string{"good"}, set.Names())
+
+	require.NoError(t, set.Load(ctx, &config.Config{}, repo))
+	assert.Empty(t, set.Names())
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+	builtin "github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/tools"
+)
+
+func TestToolRegistry_SetLocalModels(t *testing.T) {
+	registry := builtin.NewToolRegistry(nil)
+	original, ok := registry.GetTool("claude_conversation")
+	require.True(t, ok)
+	catalog := original.InputSchema().Properties["model"].Enum
+	assert.Contains(t, catalog, "claude-opus-4-7")
+
+	models := []vo.Model{"local/llama-3.1-8b", "ollama/qwen3:8b"}
+	tool, changed := registry.SetLocalModels(models)
+	require.True(t, changed)
+	assert.NotSame(t, original, tool)
+
+	enum := tool.InputSchema().Properties["model"].Enum
+	assert.Equal(t, len(catalog)+2, len(enum))
+	assert.Equal(t, []interface{}{"local/llama-3.1-8b", "ollama/qwen3:8b"}, enum[len(catalog):])
+
+	current, _ := registry.GetTool("claude_conversation")
+	assert.Same(t, tool, current)
+
+	// The original catalog is left untouched
+	assert.Len(t, original.InputSchema().Properties["model"].Enum, len(catalog))
+
+	_, changed = registry.SetLocalModels([]vo.Model{"local/llama-3.1-8b", "ollama/qwen3:8b"})
+	assert.False(t, changed)
+
+	tool, changed = registry.SetLocalModels(nil)
+	require.True(t, changed)
+	assert.Len(t, tool.InputSchema().Properties["model"].Enum, len(catalog))
+}
+
+func TestToolRegistry_SetModelAliases(t *testing.T) {
+	registry := builtin.NewToolRegistry(nil)
+	registry.SetModelAliases([]vo.Model{"analyst-default"})
+	registry.SetLocalModels([]vo.Model{"ollama/qwen3:8b"})
+
+	tool, ok := registry.GetTool("claude_conversation")
+	require.True(t, ok)
+	enum := tool.InputSchema().Properties["model"].Enum
+	assert.Equal(t, "analyst-default", enum[0])
+	assert.Equal(t, "ollama/qwen3:8b", enum[len(enum)-1])
+}
+
+// localModels is a LocalModelLister with a fixed answer
+type localModels struct {
+	models []vo.Model
+	err    error
+}
+
+func (l *localModels) LocalModels(context.Context) ([]vo.Model, error) {
+	return l.models, l.err
+}
+
+func TestToolRegistry_RefreshLocalModels(t *testing.T) {
+	ctx := context.Background()
+	registry := builtin.NewToolRegistry(nil)
+	repo := persistence.NewInMemoryToolRepository()
+	lister := &localModels{models: []vo.Model{"ollama/qwen3:8b"}}
+
+	changed, err := registry.RefreshLocalModels(ctx, lister, repo)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	name, _ := vo.NewToolName("claude_conversation")
+	registered, err := repo.FindByName(ctx, name)
+	require.NoError(t, err)
+	require.NotNil(t, registered)
+	assert.Contains(t, registered.InputSchema().Properties["model"].Enum, "ollama/qwen3:8b")
+
+	changed, err = registry.RefreshLocalModels(ctx, lister, repo)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// A failed discovery keeps the models offered so far
+	lister.err = errors.New("connection refused")
+	changed, err = registry.RefreshLocalModels(ctx, lister, repo)
+	assert.Error(t, err)
+	assert.False(t, changed)
+	tool, _ := registry.GetTool("claude_conversation")
+	assert.Contains(t, tool.InputSchema().Properties["model"].Enum, "ollama/qwen3:8b")
+}
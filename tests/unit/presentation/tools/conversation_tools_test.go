@@ -0,0 +1,224 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/commands"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/dto"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	appsvc "github.com/telemetryflow/telemetryflow-go-mcp/internal/application/services"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+	builtin "github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/tools"
+)
+
+// conversationFixture wires the conversation tools to real handlers and in-memory repositories
+type conversationFixture struct {
+	toolHandler *handlers.ToolHandler
+	sessions    []vo.SessionID
+}
+
+func newConversationFixture(t *testing.T, llm *scriptedAgentLLM, limits handlers.ConversationLimits) *conversationFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	sessionRepo := persistence.NewInMemorySessionRepository()
+	conversationRepo := persistence.NewInMemoryConversationRepository()
+	toolRepo := persistence.NewInMemoryToolRepository()
+	f := &conversationFixture{}
+	for range 2 {
+		session := aggregates.NewSession()
+		require.NoError(t, sessionRepo.Save(ctx, session))
+		f.sessions = append(f.sessions, session.ID())
+	}
+
+	f.toolHandler = handlers.NewToolHandler(sessionRepo, toolRepo, nopPublisher{})
+	conversationHandler := handlers.NewConversationHandler(sessionRepo, conversationRepo, llm, nopPublisher{})
+	conversationHandler.SetLimits(limits)
+	agent := appsvc.NewAgentService(llm, f.toolHandler, appsvc.AgentOptions{
+		MaxIterations: 3, MaxParallelTools: 1, AllowedTools: []string{"echo"},
+	})
+	conversationHandler.SetAgent(agent)
+
+	registry := builtin.NewToolRegistry(nil)
+	registry.SetAgent(agent, vo.DefaultModel, time.Minute)
+	registry.SetConversationHandler(conversationHandler)
+	for _, tool := range registry.GetTools() {
+		require.NoError(t, toolRepo.Register(ctx, tool))
+	}
+	return f
+}
+
+// call runs a tool for the given session through the tool handler, as the server does
+func (f *conversationFixture) call(t *testing.T, session int, name string, input map[string]interface{}) *entities.ToolResult {
+	t.Helper()
+	result, err := f.toolHandler.HandleExecuteTool(context.Background(), &commands.ExecuteToolCommand{
+		SessionID: f.sessions[session],
+		Name:      name,
+		Arguments: input,
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func decodeConversation(t *testing.T, result *entities.ToolResult) dto.ConversationDTO {
+	t.Helper()
+	require.False(t, result.IsError, "%v", result.Content)
+	var conversation dto.ConversationDTO
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &conversation))
+	return conversation
+}
+
+func textResponse(text string) *services.ClaudeResponse {
+	return &services.ClaudeResponse{Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: text}}}
+}
+
+func TestConversationTools_MultiTurn(t *testing.T) {
+	llm := &scriptedAgentLLM{responses: []*services.ClaudeResponse{
+		textResponse("p99 latency is 412ms."),
+		textResponse("It rose after the 14:00 deploy."),
+	}}
+	f := newConversationFixture(t, llm, handlers.ConversationLimits{})
+
+	started := decodeConversation(t, f.call(t, 0, "start_conversation", map[string]interface{}{
+		"context_type":  "metrics",
+		"system_prompt": "Answer in one sentence.",
+		"max_tokens":    float64(1024),
+	}))
+	assert.Equal(t, "metrics", started.ContextType)
+	assert.Contains(t, started.SystemPrompt, "Answer in one sentence.")
+	assert.True(t, started.IsActive)
+	id := started.ID
+
+	reply := f.call(t, 0, "send_conversation_message", map[string]interface{}{"conversation_id": id, "message": "How is latency?"})
+	require.False(t, reply.IsError, "%v", reply.Content)
+	assert.Equal(t, "p99 latency is 412ms.", reply.Content[0].Text)
+	assert.Equal(t, 2, reply.Meta["message_count"])
+
+	reply = f.call(t, 0, "send_conversation_message", map[string]interface{}{"conversation_id": id, "message": "Why?"})
+	require.False(t, reply.IsError, "%v", reply.Content)
+	assert.Equal(t, "It rose after the 14:00 deploy.", reply.Content[0].Text)
+
+	// The second request carries the whole history and the conversation settings
+	require.Len(t, llm.requests, 2)
+	assert.Len(t, llm.requests[1].Messages, 3)
+	assert.Equal(t, 1024, llm.requests[1].MaxTokens)
+	assert.Contains(t, llm.requests[1].SystemPrompt.String(), "Answer in one sentence.")
+
+	conversation := decodeConversation(t, f.call(t, 0, "get_conversation", map[string]interface{}{"conversation_id": id, "offset": float64(2)}))
+	assert.Equal(t, 4, conversation.MessageCount)
+	require.Len(t, conversation.Messages, 2)
+	assert.Equal(t, "user", conversation.Messages[0].Role)
+	assert.Equal(t, "Why?", conversation.Messages[0].Content[0].Text)
+
+	list := f.call(t, 0, "list_conversations", nil)
+	require.False(t, list.IsError)
+	var listed struct {
+		Conversations []dto.ConversationDTO `json:"conversations"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(list.Content[0].Text), &listed))
+	require.Len(t, listed.Conversations, 1)
+	assert.Equal(t, id, listed.Conversations[0].ID)
+	assert.Empty(t, listed.Conversations[0].Messages)
+
+	closed := decodeConversation(t, f.call(t, 0, "close_conversation", map[string]interface{}{"conversation_id": id}))
+	assert.False(t, closed.IsActive)
+	reply = f.call(t, 0, "send_conversation_message", map[string]interface{}{"conversation_id": id, "message": "More?"})
+	assert.True(t, reply.IsError)
+}
+
+func TestConversationTools_RunTools(t *testing.T) {
+	llm := &scriptedAgentLLM{responses: []*services.ClaudeResponse{
+		{Content: []entities.ContentBlock{
+			{Type: vo.ContentTypeToolUse, ID: "t1", Name: "echo", Input: map[string]interface{}{"message": "42 errors"}},
+		}},
+		textResponse("There were 42 errors."),
+	}}
+	f := newConversationFixture(t, llm, handlers.ConversationLimits{})
+	id := decodeConversation(t, f.call(t, 0, "start_conversation", nil)).ID
+
+	reply := f.call(t, 0, "send_conversation_message", map[string]interface{}{
+		"conversation_id": id, "message": "How many errors?", "run_tools": true,
+	})
+	require.False(t, reply.IsError, "%v", reply.Content)
+	assert.Equal(t, "There were 42 errors.", reply.Content[0].Text)
+	assert.Equal(t, 4, reply.Meta["message_count"])
+	run, ok := reply.Meta["agent"].(*appsvc.AgentResult)
+	require.True(t, ok)
+	require.Len(t, run.ToolCalls, 1)
+	assert.Equal(t, "echo", run.ToolCalls[0].Tool)
+}
+
+func TestConversationTools_Reasoning(t *testing.T) {
+	reasoned := func(thinking, text string) *services.ClaudeResponse {
+		return &services.ClaudeResponse{Content: []entities.ContentBlock{
+			{Type: vo.ContentTypeThinking, Thinking: thinking, Signature: "sig"},
+			{Type: vo.ContentTypeText, Text: text},
+		}}
+	}
+	llm := &scriptedAgentLLM{responses: []*services.ClaudeResponse{
+		reasoned("Errors rose with latency.", "A bad deploy."),
+		reasoned("The deploy was at 14:00.", "Roll it back."),
+	}}
+	f := newConversationFixture(t, llm, handlers.ConversationLimits{})
+
+	invalid := f.call(t, 0, "start_conversation", map[string]interface{}{"reasoning_budget_tokens": float64(100)})
+	assert.True(t, invalid.IsError)
+
+	started := decodeConversation(t, f.call(t, 0, "start_conversation", map[string]interface{}{"reasoning_effort": "high"}))
+	require.NotNil(t, started.Reasoning)
+	assert.Equal(t, vo.ReasoningEffortHigh, started.Reasoning.Effort)
+
+	// Reasoning is stripped unless the call asks for it
+	reply := f.call(t, 0, "send_conversation_message", map[string]interface{}{"conversation_id": started.ID, "message": "Why?"})
+	require.False(t, reply.IsError, "%v", reply.Content)
+	assert.Equal(t, "A bad deploy.", reply.Content[0].Text)
+	assert.NotContains(t, reply.Meta, "reasoning")
+
+	reply = f.call(t, 0, "send_conversation_message", map[string]interface{}{
+		"conversation_id": started.ID, "message": "Fix?", "include_reasoning": true,
+	})
+	require.False(t, reply.IsError, "%v", reply.Content)
+	assert.Equal(t, "The deploy was at 14:00.", reply.Meta["reasoning"])
+
+	// Every request asks for reasoning and carries the earlier thinking for the model
+	require.Len(t, llm.requests, 2)
+	assert.Equal(t, &vo.Reasoning{Effort: vo.ReasoningEffortHigh}, llm.requests[1].Reasoning)
+	assert.Equal(t, vo.ContentTypeThinking, llm.requests[1].Messages[1].Content[0].Type)
+
+	conversation := decodeConversation(t, f.call(t, 0, "get_conversation", map[string]interface{}{"conversation_id": started.ID}))
+	require.Len(t, conversation.Messages, 4)
+	assert.Len(t, conversation.Messages[1].Content, 1, "get_conversation leaves reasoning out")
+	assert.Equal(t, "text", conversation.Messages[1].Content[0].Type)
+}
+
+func TestConversationTools_SessionIsolationAndLimits(t *testing.T) {
+	f := newConversationFixture(t, &scriptedAgentLLM{}, handlers.ConversationLimits{MaxConversations: 1})
+	id := decodeConversation(t, f.call(t, 0, "start_conversation", nil)).ID
+
+	// Another session cannot see or use the conversation
+	for _, name := range []string{"get_conversation", "send_conversation_message", "close_conversation"} {
+		result := f.call(t, 1, name, map[string]interface{}{"conversation_id": id, "message": "hi"})
+		assert.True(t, result.IsError, name)
+		assert.Contains(t, result.Content[0].Text, handlers.ErrConversationNotFound.Error(), name)
+	}
+
+	result := f.call(t, 0, "start_conversation", nil)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, handlers.ErrMaxConversations.Error())
+
+	result = f.call(t, 0, "start_conversation", map[string]interface{}{"model": "gpt-99"})
+	assert.True(t, result.IsError)
+
+	result = f.call(t, 0, "get_conversation", map[string]interface{}{"conversation_id": "not-an-id"})
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,185 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	builtin "github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/tools"
+)
+
+func executeEditFile(t *testing.T, input map[string]interface{}) *entities.ToolResult {
+	t.Helper()
+	registry := builtin.NewToolRegistry(nil)
+	tool, ok := registry.GetTool("edit_file")
+	require.True(t, ok)
+	result, err := tool.Execute(input)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	return result
+}
+
+func readTempFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestEditFile_SearchReplace(t *testing.T) {
+	t.Run("replaces unique match", func(t *testing.T) {
+		path := writeTempFile(t, "config.yaml", []byte("level: info\nformat: json\n"))
+
+		result := executeEditFile(t, map[string]interface{}{
+			"path": path,
+			"edits": []interface{}{
+				map[string]interface{}{"old_text": "level: info", "new_text": "level: debug"},
+			},
+		})
+
+		assert.False(t, result.IsError)
+		assert.Equal(t, "level: debug\nformat: json\n", readTempFile(t, path))
+		assert.Contains(t, result.Content[0].Text, "-level: info\n+level: debug\n")
+		assert.Equal(t, true, result.Meta["changed"])
+	})
+
+	t.Run("rejects ambiguous match", func(t *testing.T) {
+		path := writeTempFile(t, "dup.txt", []byte("a\na\n"))
+
+		result := executeEditFile(t, map[string]interface{}{
+			"path":  path,
+			"edits": []interface{}{map[string]interface{}{"old_text": "a", "new_text": "b"}},
+		})
+
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].Text, "matches 2 times")
+		assert.Equal(t, "a\na\n", readTempFile(t, path))
+	})
+
+	t.Run("replace_all replaces every match", func(t *testing.T) {
+		path := writeTempFile(t, "dup.txt", []byte("a\na\n"))
+
+		result := executeEditFile(t, map[string]interface{}{
+			"path":  path,
+			"edits": []interface{}{map[string]interface{}{"old_text": "a", "new_text": "b", "replace_all": true}},
+		})
+
+		assert.False(t, result.IsError)
+		assert.Equal(t, "b\nb\n", readTempFile(t, path))
+	})
+
+	t.Run("missing match leaves file untouched", func(t *testing.T) {
+		path := writeTempFile(t, "plain.txt", []byte("hello\n"))
+
+		result := executeEditFile(t, map[string]interface{}{
+			"path": path,
+			"edits": []interface{}{
+				map[string]interface{}{"old_text": "hello", "new_text": "hi"},
+				map[string]interface{}{"old_text": "absent", "new_text": "x"},
+			},
+		})
+
+		assert.True(t, result.IsError)
+		assert.Equal(t, "hello\n", readTempFile(t, path))
+	})
+}
+
+func TestEditFile_Patch(t *testing.T) {
+	original := "one\ntwo\nthree\nfour\nfive\nsix\nseven\n"
+
+	t.Run("applies exact hunk", func(t *testing.T) {
+		path := writeTempFile(t, "numbers.txt", []byte(original))
+		patch := "--- a/numbers.txt\n+++ b/numbers.txt\n@@ -2,3 +2,3 @@\n two\n-three\n+THREE\n four\n"
+
+		result := executeEditFile(t, map[string]interface{}{"path": path, "patch": patch})
+
+		assert.False(t, result.IsError)
+		assert.Equal(t, "one\ntwo\nTHREE\nfour\nfive\nsix\nseven\n", readTempFile(t, path))
+	})
+
+	t.Run("applies hunk at shifted offset", func(t *testing.T) {
+		path := writeTempFile(t, "numbers.txt", []byte("zero\n"+original))
+		patch := "@@ -5,3 +5,3 @@\n five\n-six\n+SIX\n seven\n"
+
+		result := executeEditFile(t, map[string]interface{}{"path": path, "patch": patch})
+
+		assert.False(t, result.IsError)
+		assert.Equal(t, "zero\none\ntwo\nthree\nfour\nfive\nSIX\nseven\n", readTempFile(t, path))
+	})
+
+	t.Run("fuzz tolerates drifted context", func(t *testing.T) {
+		path := writeTempFile(t, "numbers.txt", []byte(original))
+		patch := "@@ -2,3 +2,3 @@\n TWO\n-three\n+THREE\n four\n"
+
+		strict := executeEditFile(t, map[string]interface{}{"path": path, "patch": patch, "fuzz": float64(0)})
+		assert.True(t, strict.IsError)
+
+		result := executeEditFile(t, map[string]interface{}{"path": path, "patch": patch})
+		assert.False(t, result.IsError)
+		assert.Equal(t, "one\ntwo\nTHREE\nfour\nfive\nsix\nseven\n", readTempFile(t, path))
+	})
+
+	t.Run("rejects patch without hunks", func(t *testing.T) {
+		path := writeTempFile(t, "numbers.txt", []byte(original))
+		result := executeEditFile(t, map[string]interface{}{"path": path, "patch": "not a diff"})
+		assert.True(t, result.IsError)
+	})
+}
+
+func TestEditFile_DryRunAndBackup(t *testing.T) {
+	t.Run("dry run returns diff without writing", func(t *testing.T) {
+		path := writeTempFile(t, "app.conf", []byte("port=8080\n"))
+
+		result := executeEditFile(t, map[string]interface{}{
+			"path":    path,
+			"dry_run": true,
+			"edits":   []interface{}{map[string]interface{}{"old_text": "8080", "new_text": "9090"}},
+		})
+
+		assert.False(t, result.IsError)
+		assert.Contains(t, result.Content[0].Text, "+port=9090")
+		assert.Equal(t, "port=8080\n", readTempFile(t, path))
+	})
+
+	t.Run("backup keeps original content", func(t *testing.T) {
+		path := writeTempFile(t, "app.conf", []byte("port=8080\n"))
+
+		result := executeEditFile(t, map[string]interface{}{
+			"path":   path,
+			"backup": true,
+			"edits":  []interface{}{map[string]interface{}{"old_text": "8080", "new_text": "9090"}},
+		})
+
+		assert.False(t, result.IsError)
+		assert.Equal(t, "port=9090\n", readTempFile(t, path))
+		assert.Equal(t, "port=8080\n", readTempFile(t, path+".bak"))
+	})
+}
+
+func TestEditFile_PreservesModeAndSymlink(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "run.sh")
+	require.NoError(t, os.WriteFile(path, []byte("echo old\n"), 0600))
+	require.NoError(t, os.Chmod(path, 0750))
+	link := filepath.Join(dir, "link.sh")
+	require.NoError(t, os.Symlink(path, link))
+
+	result := executeEditFile(t, map[string]interface{}{
+		"path":  link,
+		"edits": []interface{}{map[string]interface{}{"old_text": "old", "new_text": "new"}},
+	})
+
+	assert.False(t, result.IsError)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0750), info.Mode().Perm())
+	assert.Equal(t, "echo new\n", readTempFile(t, path))
+
+	linkInfo, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.True(t, linkInfo.Mode()&os.ModeSymlink != 0)
+}
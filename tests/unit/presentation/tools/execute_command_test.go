@@ -0,0 +1,319 @@
+//go:build !windows
+
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	builtin "github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/tools"
+)
+
+func executeCommand(t *testing.T, policy config.CommandPolicyConfig, input map[string]interface{}) *entities.ToolResult {
+	t.Helper()
+	registry := builtin.NewToolRegistry(nil)
+	registry.SetCommandPolicy(policy)
+	tool, ok := registry.GetTool("execute_command")
+	require.True(t, ok)
+	result, err := tool.Execute(input)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	return result
+}
+
+func TestExecuteCommand_SeparateStreams(t *testing.T) {
+	result := executeCommand(t, config.DefaultCommandPolicy(), map[string]interface{}{
+		"command": "echo out; echo err >&2; exit 3",
+	})
+
+	assert.False(t, result.IsError)
+	assert.Equal(t, 3, result.Meta["exit_code"])
+	assert.Equal(t, "out\n", result.Meta["stdout"])
+	assert.Equal(t, "err\n", result.Meta["stderr"])
+	assert.Contains(t, result.Content[0].Text, "Exit code: 3")
+	assert.Contains(t, result.Content[0].Text, "STDERR:\nerr")
+}
+
+func TestExecuteCommand_EnvironmentAllowlist(t *testing.T) {
+	t.Setenv("TFO_TEST_SECRET", "hunter2")
+	t.Setenv("TFO_TEST_PUBLIC", "visible")
+
+	policy := config.DefaultCommandPolicy()
+	policy.EnvAllowlist = []string{"PATH", "TFO_TEST_PUB*"}
+
+	result := executeCommand(t, policy, map[string]interface{}{
+		"command": "echo \"[$TFO_TEST_SECRET][$TFO_TEST_PUBLIC]\"",
+	})
+
+	assert.Equal(t, "[][visible]\n", result.Meta["stdout"])
+}
+
+func TestExecuteCommand_CommandPatterns(t *testing.T) {
+	policy := config.DefaultCommandPolicy()
+	policy.AllowedCommands = []string{"echo", "grep", "/usr/bin/*"}
+	policy.DeniedCommands = []string{"rm"}
+
+	tests := []struct {
+		name    string
+		command string
+		allowed bool
+	}{
+		{"allowed pipeline", "echo hello | grep hell", true},
+		{"allowed by path glob", "/usr/bin/printf hi", true},
+		{"runner allowed by path glob", "/usr/bin/env true", false},
+		{"not in allowlist", "cat /etc/passwd", false},
+		{"denied after operator", "echo hi && rm -rf /tmp/x", false},
+		{"prefix assignment skipped", "FOO=1 cat file", false},
+		{"command substitution", "echo $(cat /etc/passwd)", false},
+		{"backticks in double quotes", "echo \"`id`\"", false},
+		{"quoted operator is literal", "echo 'a && rm'", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := executeCommand(t, policy, map[string]interface{}{"command": tt.command})
+			if tt.allowed {
+				assert.NotContains(t, result.Content[0].Text, "not allowed")
+				assert.NotContains(t, result.Content[0].Text, "denied")
+			} else {
+				assert.True(t, result.IsError)
+			}
+		})
+	}
+}
+
+func TestExecuteCommand_DenyListBypasses(t *testing.T) {
+	policy := config.DefaultCommandPolicy()
+	policy.DeniedCommands = []string{"rm"}
+
+	denied := map[string]string{
+		"variable as command":        "X=rm; $X -rf /nonexistent/tfo",
+		"quoted variable":            "X=rm; \"$X\" -rf /nonexistent/tfo",
+		"glob as command":            "/bin/r? -rf /nonexistent/tfo",
+		"brace expansion":            "{rm,-rf,/nonexistent/tfo}",
+		"eval":                       "eval rm -rf /nonexistent/tfo",
+		"env":                        "env rm -rf /nonexistent/tfo",
+		"command builtin":            "command rm -rf /nonexistent/tfo",
+		"exec":                       "exec rm -rf /nonexistent/tfo",
+		"time":                       "time -p rm -rf /nonexistent/tfo",
+		"xargs":                      "echo /nonexistent/tfo | xargs rm -rf",
+		"nested sh":                  "sh -c 'rm -rf /nonexistent/tfo'",
+		"nested bash by path":        "/bin/bash -c 'rm -rf /nonexistent/tfo'",
+		"backticks":                  "echo `rm -rf /nonexistent/tfo`",
+		"dollar substitution":        "echo $(rm -rf /nonexistent/tfo)",
+		"brace group":                "{ rm -rf /nonexistent/tfo; }",
+		"if condition":               "if rm -rf /nonexistent/tfo; then echo; fi",
+		"negation":                   "! rm -rf /nonexistent/tfo",
+		"function body":              "function f { rm -rf /nonexistent/tfo; }",
+		"leading redirection":        ">/dev/null rm -rf /nonexistent/tfo",
+		"leading redirection target": "2> /dev/null rm -rf /nonexistent/tfo",
+		"leading &> redirection":     "&>/dev/null rm -rf /nonexistent/tfo",
+		"line continuation":          "r\\\nm -rf /nonexistent/tfo",
+		"quoted line continuation":   "\"r\\\nm\" -rf /nonexistent/tfo",
+		"find -exec":                 "find /nonexistent/tfo -exec rm -rf {} \\;",
+		"find -execdir":              "find /nonexistent/tfo -execdir rm -rf {} +",
+	}
+	for name, command := range denied {
+		t.Run(name, func(t *testing.T) {
+			result := executeCommand(t, policy, map[string]interface{}{"command": command})
+			assert.True(t, result.IsError)
+			assert.NotContains(t, result.Meta, "exit_code", "the command must not run")
+		})
+	}
+
+	allowed := map[string]string{
+		"name as argument":     "echo rm",
+		"stderr redirection":   "echo hi >&2",
+		"fd duplication":       "echo hi 2>&1 | cat >&2",
+		"variable as argument": "for f in hi; do echo $f >&2; done",
+		"if statement":         "if true; then echo hi >&2; fi",
+		"find without -exec":   "find /nonexistent/tfo -name x 2>/dev/null; echo hi >&2",
+	}
+	for name, command := range allowed {
+		t.Run(name, func(t *testing.T) {
+			result := executeCommand(t, policy, map[string]interface{}{"command": command})
+			require.False(t, result.IsError, "%v", result.Content)
+			assert.Equal(t, 0, result.Meta["exit_code"])
+		})
+	}
+}
+
+func TestExecuteCommand_DirectExecution(t *testing.T) {
+	policy := config.DefaultCommandPolicy()
+	policy.UseShell = false
+
+	result := executeCommand(t, policy, map[string]interface{}{"command": "echo 'a b' c"})
+	assert.Equal(t, "a b c\n", result.Meta["stdout"])
+
+	result = executeCommand(t, policy, map[string]interface{}{"command": "echo a | cat"})
+	assert.True(t, result.IsError)
+}
+
+func TestExecuteCommand_AllowedDirs(t *testing.T) {
+	root := t.TempDir()
+	inside := filepath.Join(root, "project")
+	require.NoError(t, os.Mkdir(inside, 0750))
+
+	policy := config.DefaultCommandPolicy()
+	policy.AllowedDirs = []string{root}
+
+	result := executeCommand(t, policy, map[string]interface{}{"command": "pwd", "working_dir": inside})
+	assert.False(t, result.IsError)
+	resolved, err := filepath.EvalSymlinks(inside)
+	require.NoError(t, err)
+	assert.Equal(t, resolved+"\n", result.Meta["stdout"])
+
+	result = executeCommand(t, policy, map[string]interface{}{"command": "pwd", "working_dir": filepath.Join(inside, "..", "..")})
+	assert.True(t, result.IsError)
+
+	result = executeCommand(t, policy, map[string]interface{}{"command": "pwd"})
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	require.NoError(t, err)
+	assert.Equal(t, resolvedRoot+"\n", result.Meta["stdout"])
+
+	// The shell may not leave the allowed directories
+	for _, command := range []string{"cd / && pwd", "pwd; pushd /", "echo; popd", "if cd /; then pwd; fi"} {
+		result = executeCommand(t, policy, map[string]interface{}{"command": command})
+		assert.True(t, result.IsError, command)
+		assert.Contains(t, result.Content[0].Text, "changing directory is not allowed", command)
+	}
+
+	policy.AllowedDirs = nil
+	result = executeCommand(t, policy, map[string]interface{}{"command": "cd / && pwd"})
+	assert.Equal(t, "/\n", result.Meta["stdout"])
+}
+
+func TestExecuteCommand_OutputCap(t *testing.T) {
+	policy := config.DefaultCommandPolicy()
+	policy.MaxOutputBytes = 10
+
+	result := executeCommand(t, policy, map[string]interface{}{
+		"command": "printf '%0100d' 0",
+	})
+
+	assert.Equal(t, "0000000000", result.Meta["stdout"])
+	assert.Equal(t, int64(100), result.Meta["stdout_bytes"])
+	assert.Equal(t, true, result.Meta["stdout_truncated"])
+	assert.Contains(t, result.Content[0].Text, "stdout truncated")
+}
+
+func TestExecuteCommand_TimeoutKillsProcessGroup(t *testing.T) {
+	policy := config.DefaultCommandPolicy()
+	policy.MaxTimeout = time.Second
+
+	start := time.Now()
+	result := executeCommand(t, policy, map[string]interface{}{
+		"command": "sleep 30 & sleep 30; wait",
+		"timeout": float64(10),
+	})
+
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.True(t, result.IsError)
+	assert.Equal(t, true, result.Meta["timed_out"])
+	assert.Contains(t, result.Content[0].Text, "timed out after 1s")
+}
+
+func TestToolRegistry_SetCommandPolicyTimeout(t *testing.T) {
+	registry := builtin.NewToolRegistry(nil)
+	tool, ok := registry.GetTool("execute_command")
+	require.True(t, ok)
+	defaultTimeout := tool.Timeout()
+
+	policy := config.DefaultCommandPolicy()
+	policy.MaxTimeout = time.Second
+	registry.SetCommandPolicy(policy)
+	assert.Less(t, tool.Timeout(), defaultTimeout)
+
+	// A policy without a bound falls back to the default one
+	policy.MaxTimeout = 0
+	registry.SetCommandPolicy(policy)
+	assert.Equal(t, defaultTimeout, tool.Timeout())
+}
+
+func TestExecuteCommand_StreamsProgress(t *testing.T) {
+	policy := config.DefaultCommandPolicy()
+	policy.ProgressInterval = 20 * time.Millisecond
+
+	registry := builtin.NewToolRegistry(nil)
+	registry.SetCommandPolicy(policy)
+	tool, ok := registry.GetTool("execute_command")
+	require.True(t, ok)
+
+	var mu sync.Mutex
+	var updates []entities.ToolProgress
+	ctx := entities.WithProgressReporter(context.Background(), func(update entities.ToolProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		updates = append(updates, update)
+	})
+
+	result, err := tool.ExecuteContext(ctx, map[string]interface{}{
+		"command": "echo one; echo two >&2; sleep 0.2; printf three",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "one\nthree", result.Meta["stdout"])
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.GreaterOrEqual(t, len(updates), 2, "expected output split across notifications")
+
+	var messages []string
+	last := 0.0
+	for _, update := range updates {
+		assert.Greater(t, update.Progress, last)
+		last = update.Progress
+		messages = append(messages, update.Message)
+	}
+	streamed := strings.Join(messages, "\n")
+	assert.Contains(t, streamed, "one")
+	assert.Contains(t, streamed, "[stderr] two")
+	assert.Contains(t, streamed, "three")
+	assert.Equal(t, 3.0, last)
+}
+
+func TestExecuteCommand_CancellationKillsProcess(t *testing.T) {
+	registry := builtin.NewToolRegistry(nil)
+	tool, ok := registry.GetTool("execute_command")
+	require.True(t, ok)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	start := time.Now()
+	result, err := tool.ExecuteContext(ctx, map[string]interface{}{"command": "sleep 30"})
+	require.NoError(t, err)
+
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.True(t, result.IsError)
+	assert.Equal(t, true, result.Meta["cancelled"])
+}
+
+func TestExecuteCommand_BackgroundTaskCancel(t *testing.T) {
+	f := newTaskToolsFixture(t)
+
+	started := f.call(t, "start_background_task", map[string]interface{}{
+		"tool":      "execute_command",
+		"arguments": map[string]interface{}{"command": "sleep 30"},
+	})
+	taskID := taskMeta(t, started)["taskId"].(string)
+
+	// A zero wait reports the running task without blocking
+	pending := f.call(t, "get_task_result", map[string]interface{}{"task_id": taskID})
+	assert.Equal(t, "working", taskMeta(t, pending)["status"])
+
+	start := time.Now()
+	cancelled := f.call(t, "cancel_task", map[string]interface{}{"task_id": taskID})
+	assert.False(t, cancelled.IsError)
+	assert.Equal(t, "cancelled", taskMeta(t, cancelled)["status"])
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
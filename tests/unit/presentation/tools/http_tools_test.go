@@ -0,0 +1,233 @@
+package tools
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	builtin "github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/tools"
+)
+
+// capturedRequest records what the fake endpoint received
+type capturedRequest struct {
+	method  string
+	path    string
+	query   string
+	headers http.Header
+	body    string
+}
+
+func newHTTPToolServer(t *testing.T, status int, body string) (*httptest.Server, *capturedRequest) {
+	t.Helper()
+	captured := &capturedRequest{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		captured.method = r.Method
+		captured.path = r.URL.EscapedPath()
+		captured.query = r.URL.RawQuery
+		captured.headers = r.Header.Clone()
+		captured.body = string(data)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, captured
+}
+
+func resultText(t *testing.T, result *entities.ToolResult) string {
+	t.Helper()
+	require.NotNil(t, result)
+	require.NotEmpty(t, result.Content)
+	return result.Content[0].Text
+}
+
+func TestHTTPTool_RendersRequest(t *testing.T) {
+	srv, captured := newHTTPToolServer(t, http.StatusOK, `{"id": 42}`)
+	t.Setenv("HTTP_TOOL_TEST_TOKEN", "s3cret")
+
+	tool, err := builtin.NewHTTPTool(config.HTTPToolConfig{
+		Name:        "create_ticket",
+		Description: "Create a ticket",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"project":  map[string]interface{}{"type": "string"},
+				"title":    map[string]interface{}{"type": "string"},
+				"priority": map[string]interface{}{"type": "string"},
+			},
+			"required": []interface{}{"project", "title"},
+		},
+		Method:  "post",
+		URL:     srv.URL + "/projects/{{.project}}/tickets",
+		Query:   map[string]string{"priority": "{{.priority}}", "source": "mcp"},
+		Headers: map[string]string{"Authorization": "Bearer ${HTTP_TOOL_TEST_TOKEN}"},
+		Body:    `{"title": {{json .title}}}`,
+	}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "http", tool.Category())
+	assert.Contains(t, tool.Tags(), "http")
+	assert.Equal(t, []string{"project", "title"}, tool.InputSchema().Required)
+
+	result, err := tool.ExecuteContext(context.Background(), map[string]interface{}{
+		"project": "ops/core team",
+		"title":   `Disk "full"`,
+	})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.JSONEq(t, `{"id": 42}`, resultText(t, result))
+	assert.Equal(t, http.StatusOK, result.Meta["status_code"])
+
+	assert.Equal(t, http.MethodPost, captured.method)
+	assert.Equal(t, "/projects/ops%2Fcore%20team/tickets", captured.path)
+	assert.Equal(t, "source=mcp", captured.query, "empty query values are dropped")
+	assert.Equal(t, "Bearer s3cret", captured.headers.Get("Authorization"))
+	assert.Equal(t, "application/json", captured.headers.Get("Content-Type"))
+	assert.JSONEq(t, `{"title": "Disk \"full\""}`, captured.body)
+}
+
+func TestHTTPTool_ResponsePath(t *testing.T) {
+	payload := `{
+		"data": {
+			"services": [
+				{"name": "api", "status": "up", "latency": 12.5},
+				{"name": "db", "status": "down", "latency": 80}
+			]
+		}
+	}`
+	srv, _ := newHTTPToolServer(t, http.StatusOK, payload)
+
+	tests := []struct {
+		path     string
+		expected string
+		isJSON   bool
+	}{
+		{"$.data.services[0].name", "api", false},
+		{"$.data.services[-1].status", "down", false},
+		{"$['data']['services'][1]['latency']", "80", true},
+		{"$.data.services[*].name", `["api", "db"]`, true},
+		{"$..status", `["up", "down"]`, true},
+		{"$.data.services[0]", `{"name": "api", "status": "up", "latency": 12.5}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			tool, err := builtin.NewHTTPTool(config.HTTPToolConfig{
+				Name:         "status",
+				Description:  "Service status",
+				URL:          srv.URL,
+				ResponsePath: tt.path,
+			}, nil)
+			require.NoError(t, err)
+
+			result, err := tool.ExecuteContext(context.Background(), nil)
+			require.NoError(t, err)
+			require.False(t, result.IsError, resultText(t, result))
+			if tt.isJSON {
+				assert.JSONEq(t, tt.expected, resultText(t, result))
+			} else {
+				assert.Equal(t, tt.expected, resultText(t, result))
+			}
+		})
+	}
+
+	t.Run("no match", func(t *testing.T) {
+		tool, err := builtin.NewHTTPTool(config.HTTPToolConfig{
+			Name: "status", Description: "Service status", URL: srv.URL, ResponsePath: "$.data.missing",
+		}, nil)
+		require.NoError(t, err)
+		result, err := tool.ExecuteContext(context.Background(), nil)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, resultText(t, result), "matched nothing")
+	})
+}
+
+func TestHTTPTool_ErrorStatus(t *testing.T) {
+	srv, _ := newHTTPToolServer(t, http.StatusNotFound, `{"error": "no such project"}`)
+
+	tool, err := builtin.NewHTTPTool(config.HTTPToolConfig{
+		Name: "get_project", Description: "Get a project", URL: srv.URL, ResponsePath: "$.name",
+	}, nil)
+	require.NoError(t, err)
+
+	result, err := tool.ExecuteContext(context.Background(), nil)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "HTTP 404")
+	assert.Contains(t, resultText(t, result), "no such project")
+	assert.Equal(t, http.StatusNotFound, result.Meta["status_code"])
+}
+
+func TestHTTPTool_InvalidDefinitions(t *testing.T) {
+	tests := []struct {
+		name string
+		def  config.HTTPToolConfig
+	}{
+		{"bad name", config.HTTPToolConfig{Name: "1tool", Description: "x", URL: "http://x"}},
+		{"bad url template", config.HTTPToolConfig{Name: "t", Description: "x", URL: "http://x/{{.id"}},
+		{"bad body template", config.HTTPToolConfig{Name: "t", Description: "x", URL: "http://x", Body: "{{"}},
+		{"bad response path", config.HTTPToolConfig{Name: "t", Description: "x", URL: "http://x", ResponsePath: "$.a["}},
+		{"bad schema", config.HTTPToolConfig{Name: "t", Description: "x", URL: "http://x", InputSchema: map[string]interface{}{"type": 5}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := builtin.NewHTTPTool(tt.def, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestHTTPTool_EscapesNonScalarURLArguments(t *testing.T) {
+	srv, captured := newHTTPToolServer(t, http.StatusOK, `{}`)
+
+	tool, err := builtin.NewHTTPTool(config.HTTPToolConfig{
+		Name: "get_incident", Description: "Get an incident", URL: srv.URL + "/api/incidents/{{.id}}/notes",
+	}, nil)
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		id   interface{}
+		path string
+	}{
+		"array":  {[]interface{}{"../../admin/delete?x="}, "/api/incidents/%5B%22..%2F..%2Fadmin%2Fdelete%3Fx%3D%22%5D/notes"},
+		"object": {map[string]interface{}{"a": "#frag"}, "/api/incidents/%7B%22a%22%3A%22%23frag%22%7D/notes"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, err := tool.ExecuteContext(context.Background(), map[string]interface{}{"id": tc.id})
+			require.NoError(t, err)
+			require.False(t, result.IsError, resultText(t, result))
+			assert.Equal(t, tc.path, captured.path)
+			assert.Empty(t, captured.query)
+		})
+	}
+}
+
+func TestHTTPTool_UnknownArgumentInTemplate(t *testing.T) {
+	srv, _ := newHTTPToolServer(t, http.StatusOK, `{}`)
+
+	tool, err := builtin.NewHTTPTool(config.HTTPToolConfig{
+		Name: "get_item", Description: "Get an item", URL: srv.URL + "/items/{{.id}}",
+	}, nil)
+	require.NoError(t, err)
+
+	result, err := tool.ExecuteContext(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestHTTPTool_Timeout(t *testing.T) {
+	tool, err := builtin.NewHTTPTool(config.HTTPToolConfig{
+		Name: "slow", Description: "Slow call", URL: "http://localhost", Timeout: 5 * time.Second,
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, tool.Timeout())
+}
@@ -0,0 +1,174 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/commands"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/services"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	builtin "github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/tools"
+)
+
+// progressRecorder collects the progress notifications sent during a call
+type progressRecorder struct {
+	mu      sync.Mutex
+	updates []entities.ToolProgress
+}
+
+func (p *progressRecorder) context(ctx context.Context) context.Context {
+	return entities.WithProgressReporter(ctx, func(update entities.ToolProgress) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.updates = append(p.updates, update)
+	})
+}
+
+func (p *progressRecorder) text() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var sb strings.Builder
+	for _, update := range p.updates {
+		sb.WriteString(update.Message)
+	}
+	return sb.String()
+}
+
+// stalledLLM streams one delta and then waits for the caller to cancel
+type stalledLLM struct {
+	scriptedAgentLLM
+	aborted chan struct{}
+}
+
+func (s *stalledLLM) CreateMessageStream(ctx context.Context, req *services.ClaudeRequest) (<-chan *services.ClaudeStreamEvent, error) {
+	ch := make(chan *services.ClaudeStreamEvent)
+	go func() {
+		defer close(ch)
+		defer close(s.aborted)
+		for _, event := range []*services.ClaudeStreamEvent{
+			{Type: "content_block_start", ContentBlock: &entities.ContentBlock{Type: vo.ContentTypeText}},
+			{Type: "content_block_delta", Delta: &services.ClaudeDelta{Type: "text_delta", Text: "Looking"}},
+		} {
+			select {
+			case ch <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+		<-ctx.Done()
+	}()
+	return ch, nil
+}
+
+func TestClaudeConversation_Streams(t *testing.T) {
+	llm := &scriptedAgentLLM{responses: []*services.ClaudeResponse{{
+		Content:  []entities.ContentBlock{{Type: vo.ContentTypeText, Text: "Error rate is back under 0.1%."}},
+		Usage:    &services.ClaudeUsage{InputTokens: 40, OutputTokens: 12},
+		ServedBy: vo.ModelClaudeSonnet46,
+	}}}
+	tool, ok := builtin.NewToolRegistry(llm).GetTool("claude_conversation")
+	require.True(t, ok)
+
+	progress := &progressRecorder{}
+	result, err := tool.ExecuteContext(progress.context(context.Background()), map[string]interface{}{"message": "Error rate?"})
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+
+	require.Len(t, llm.requests, 1)
+	assert.True(t, llm.requests[0].Stream)
+	assert.Equal(t, "Error rate is back under 0.1%.", result.Content[0].Text)
+	assert.Equal(t, result.Content[0].Text, progress.text())
+	assert.Equal(t, float64(len(result.Content[0].Text)), progress.updates[len(progress.updates)-1].Progress)
+
+	assert.Equal(t, string(vo.ModelClaudeSonnet46), result.Meta["model"])
+	assert.Equal(t, "end_turn", result.Meta["stop_reason"])
+	assert.Equal(t, &services.ClaudeUsage{InputTokens: 40, OutputTokens: 12}, result.Meta["usage"])
+}
+
+func TestClaudeConversation_NoProgressTokenDoesNotStream(t *testing.T) {
+	llm := &scriptedAgentLLM{responses: []*services.ClaudeResponse{textResponse("Done.")}}
+	tool, _ := builtin.NewToolRegistry(llm).GetTool("claude_conversation")
+
+	result, err := tool.ExecuteContext(context.Background(), map[string]interface{}{"message": "Hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "Done.", result.Content[0].Text)
+	assert.False(t, llm.requests[0].Stream)
+}
+
+func TestClaudeConversation_ContextComesFirstAndIsCached(t *testing.T) {
+	llm := &scriptedAgentLLM{responses: []*services.ClaudeResponse{textResponse("Checkout waits on the database.")}}
+	tool, _ := builtin.NewToolRegistry(llm).GetTool("claude_conversation")
+
+	result, err := tool.ExecuteContext(context.Background(), map[string]interface{}{
+		"message": "Why is checkout slow?",
+		"context": "## Current Context\n{\"p99_ms\": 840}",
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+
+	content := llm.requests[0].Messages[0].Content
+	require.Len(t, content, 2)
+	assert.Equal(t, "## Current Context\n{\"p99_ms\": 840}", content[0].Text)
+	assert.True(t, content[0].CacheBreakpoint)
+	assert.Equal(t, "Why is checkout slow?", content[1].Text)
+	assert.False(t, content[1].CacheBreakpoint)
+}
+
+func TestClaudeConversation_CancelAbortsStream(t *testing.T) {
+	llm := &stalledLLM{aborted: make(chan struct{})}
+	tool, _ := builtin.NewToolRegistry(llm).GetTool("claude_conversation")
+
+	// The client cancels once the first text arrives
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = entities.WithProgressReporter(ctx, func(entities.ToolProgress) { cancel() })
+
+	result, err := tool.ExecuteContext(ctx, map[string]interface{}{"message": "Investigate"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.True(t, errors.Is(ctx.Err(), context.Canceled))
+
+	select {
+	case <-llm.aborted:
+	case <-time.After(time.Second):
+		t.Fatal("upstream stream was not aborted")
+	}
+}
+
+func TestSendConversationMessage_Streams(t *testing.T) {
+	llm := &scriptedAgentLLM{responses: []*services.ClaudeResponse{{
+		Content: []entities.ContentBlock{{Type: vo.ContentTypeText, Text: "Disk usage peaked at 91%."}},
+		Usage:   &services.ClaudeUsage{InputTokens: 25, OutputTokens: 9},
+	}}}
+	f := newConversationFixture(t, llm, handlers.ConversationLimits{})
+	id := decodeConversation(t, f.call(t, 0, "start_conversation", nil)).ID
+
+	progress := &progressRecorder{}
+	result, err := f.toolHandler.HandleExecuteTool(progress.context(context.Background()), &commands.ExecuteToolCommand{
+		SessionID: f.sessions[0],
+		Name:      "send_conversation_message",
+		Arguments: map[string]interface{}{"conversation_id": id, "message": "Disk?"},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+
+	assert.True(t, llm.requests[0].Stream)
+	assert.Equal(t, "Disk usage peaked at 91%.", result.Content[0].Text)
+	assert.Equal(t, result.Content[0].Text, progress.text())
+	assert.Equal(t, 2, result.Meta["message_count"])
+	assert.Equal(t, &services.ClaudeUsage{InputTokens: 25, OutputTokens: 9}, result.Meta["usage"])
+
+	// The streamed reply is stored like any other
+	conversation := decodeConversation(t, f.call(t, 0, "get_conversation", map[string]interface{}{"conversation_id": id}))
+	require.Len(t, conversation.Messages, 2)
+	assert.Equal(t, "Disk usage peaked at 91%.", conversation.Messages[1].Content[0].Text)
+}
@@ -0,0 +1,505 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/commands"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/config"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+	builtin "github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/tools"
+)
+
+// fakeTools is a tool lookup over test tools that records the arguments of every call
+type fakeTools struct {
+	mu    sync.Mutex
+	tools map[string]*entities.Tool
+	calls map[string][]map[string]interface{}
+}
+
+func newFakeTools() *fakeTools {
+	return &fakeTools{tools: make(map[string]*entities.Tool), calls: make(map[string][]map[string]interface{})}
+}
+
+func (f *fakeTools) add(t *testing.T, name string, schema *entities.JSONSchema, handler entities.ContextToolHandler) {
+	t.Helper()
+	toolName, err := vo.NewToolName(name)
+	require.NoError(t, err)
+	desc, err := vo.NewToolDescription("test tool " + name)
+	require.NoError(t, err)
+	if schema == nil {
+		schema = &entities.JSONSchema{Type: "object"}
+	}
+	tool, err := entities.NewTool(toolName, desc, schema)
+	require.NoError(t, err)
+	tool.SetContextHandler(func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		f.mu.Lock()
+		f.calls[name] = append(f.calls[name], input)
+		f.mu.Unlock()
+		return handler(ctx, input)
+	})
+	f.tools[name] = tool
+}
+
+func (f *fakeTools) lookup(ctx context.Context, name string) (*entities.Tool, error) {
+	tool, ok := f.tools[name]
+	if !ok {
+		return nil, fmt.Errorf("tool %s not found", name)
+	}
+	return tool, nil
+}
+
+func (f *fakeTools) callsTo(name string) []map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[name]
+}
+
+func textTool(text string) entities.ContextToolHandler {
+	return func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		return entities.NewTextToolResult(text), nil
+	}
+}
+
+func failingTool(message string) entities.ContextToolHandler {
+	return func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		return entities.NewErrorToolResult(errors.New(message)), nil
+	}
+}
+
+func stepTraces(t *testing.T, result *entities.ToolResult) map[string]builtin.StepTrace {
+	t.Helper()
+	traces, ok := result.Meta["steps"].([]builtin.StepTrace)
+	require.True(t, ok, "result meta should carry step traces")
+	byID := make(map[string]builtin.StepTrace, len(traces))
+	for _, trace := range traces {
+		byID[trace.ID] = trace
+	}
+	return byID
+}
+
+func TestPipeline_DataFlowsBetweenSteps(t *testing.T) {
+	fake := newFakeTools()
+	fake.add(t, "fetch_metrics", nil, textTool(`{"service": "checkout", "error_rate": 0.12}`))
+	fake.add(t, "fetch_owner", nil, textTool("team-payments"))
+	fake.add(t, "summarize", &entities.JSONSchema{
+		Type: "object",
+		Properties: map[string]*entities.JSONSchema{
+			"message": {Type: "string"},
+			"limit":   {Type: "integer"},
+		},
+	}, textTool("summary done"))
+
+	tool, err := builtin.NewPipelineTool(config.PipelineConfig{
+		Name:        "triage",
+		Description: "Triage a service",
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"service": map[string]interface{}{"type": "string"}},
+		},
+		Steps: []config.PipelineStepConfig{
+			{ID: "metrics", Tool: "fetch_metrics", Arguments: map[string]interface{}{"service": "{{.input.service}}"}},
+			{ID: "owner", Tool: "fetch_owner"},
+			{
+				ID:        "summary",
+				Tool:      "summarize",
+				DependsOn: []string{"metrics", "owner"},
+				Arguments: map[string]interface{}{
+					"message": "{{.steps.metrics.json.service}} owned by {{.steps.owner.text}} has error rate {{.steps.metrics.json.error_rate}}",
+					"limit":   "{{len .steps}}",
+					"tags":    []interface{}{"static", "{{.input.service}}"},
+				},
+			},
+		},
+		Output: "{{.steps.summary.text}} ({{.steps.owner.status}})",
+	}, fake.lookup)
+	require.NoError(t, err)
+	assert.Equal(t, "pipeline", tool.Category())
+
+	result, err := tool.ExecuteContext(context.Background(), map[string]interface{}{"service": "checkout"})
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Equal(t, "summary done (ok)", resultText(t, result))
+
+	assert.Equal(t, []map[string]interface{}{{"service": "checkout"}}, fake.callsTo("fetch_metrics"))
+	summaryCalls := fake.callsTo("summarize")
+	require.Len(t, summaryCalls, 1)
+	assert.Equal(t, "checkout owned by team-payments has error rate 0.12", summaryCalls[0]["message"])
+	assert.Equal(t, float64(2), summaryCalls[0]["limit"], "templated values are converted to the schema type")
+	assert.Equal(t, []interface{}{"static", "checkout"}, summaryCalls[0]["tags"])
+
+	traces := stepTraces(t, result)
+	for _, id := range []string{"metrics", "owner", "summary"} {
+		assert.Equal(t, builtin.StepStatusOK, traces[id].Status, id)
+		assert.Equal(t, 1, traces[id].Attempts, id)
+	}
+}
+
+func TestPipeline_IndependentStepsRunConcurrently(t *testing.T) {
+	fake := newFakeTools()
+	var running, peak int32
+	slow := func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return entities.NewTextToolResult("ok"), nil
+	}
+	fake.add(t, "slow_a", nil, slow)
+	fake.add(t, "slow_b", nil, slow)
+
+	tool, err := builtin.NewPipelineTool(config.PipelineConfig{
+		Name:        "fan_out",
+		Description: "Fan out",
+		Steps: []config.PipelineStepConfig{
+			{ID: "a", Tool: "slow_a"},
+			{ID: "b", Tool: "slow_b"},
+		},
+	}, fake.lookup)
+	require.NoError(t, err)
+
+	result, err := tool.ExecuteContext(context.Background(), nil)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&peak))
+}
+
+func TestPipeline_ConditionalSteps(t *testing.T) {
+	fake := newFakeTools()
+	fake.add(t, "quick_check", nil, textTool("quick"))
+	fake.add(t, "deep_scan", nil, textTool("deep"))
+
+	tool, err := builtin.NewPipelineTool(config.PipelineConfig{
+		Name:        "scan",
+		Description: "Scan",
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"mode": map[string]interface{}{"type": "string"}},
+		},
+		Steps: []config.PipelineStepConfig{
+			{ID: "quick", Tool: "quick_check"},
+			{ID: "deep", Tool: "deep_scan", DependsOn: []string{"quick"}, When: `{{eq .input.mode "full"}}`},
+		},
+	}, fake.lookup)
+	require.NoError(t, err)
+
+	result, err := tool.ExecuteContext(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, "quick", resultText(t, result), "the last successful step provides the default output")
+	assert.Equal(t, builtin.StepStatusSkipped, stepTraces(t, result)["deep"].Status)
+	assert.Empty(t, fake.callsTo("deep_scan"))
+
+	result, err = tool.ExecuteContext(context.Background(), map[string]interface{}{"mode": "full"})
+	require.NoError(t, err)
+	assert.Equal(t, "deep", resultText(t, result))
+}
+
+func TestPipeline_FailureHandling(t *testing.T) {
+	fake := newFakeTools()
+	fake.add(t, "broken", nil, failingTool("backend unavailable"))
+	fake.add(t, "report", nil, textTool("reported"))
+
+	t.Run("fail aborts the pipeline", func(t *testing.T) {
+		tool, err := builtin.NewPipelineTool(config.PipelineConfig{
+			Name:        "strict",
+			Description: "Strict",
+			Steps: []config.PipelineStepConfig{
+				{ID: "fetch", Tool: "broken"},
+				{ID: "notify", Tool: "report", DependsOn: []string{"fetch"}},
+			},
+		}, fake.lookup)
+		require.NoError(t, err)
+
+		result, err := tool.ExecuteContext(context.Background(), nil)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, resultText(t, result), "fetch")
+		assert.Contains(t, resultText(t, result), "backend unavailable")
+
+		traces := stepTraces(t, result)
+		assert.Equal(t, builtin.StepStatusFailed, traces["fetch"].Status)
+		assert.Equal(t, builtin.StepStatusCancelled, traces["notify"].Status)
+	})
+
+	t.Run("continue lets dependents react", func(t *testing.T) {
+		tool, err := builtin.NewPipelineTool(config.PipelineConfig{
+			Name:        "lenient",
+			Description: "Lenient",
+			Steps: []config.PipelineStepConfig{
+				{ID: "fetch", Tool: "broken", OnError: "continue"},
+				{
+					ID:        "notify",
+					Tool:      "report",
+					DependsOn: []string{"fetch"},
+					When:      `{{eq .steps.fetch.status "failed"}}`,
+					Arguments: map[string]interface{}{"reason": "{{.steps.fetch.error}}"},
+				},
+			},
+		}, fake.lookup)
+		require.NoError(t, err)
+
+		result, err := tool.ExecuteContext(context.Background(), nil)
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+		assert.Equal(t, "reported", resultText(t, result))
+		calls := fake.callsTo("report")
+		require.NotEmpty(t, calls)
+		assert.Contains(t, calls[len(calls)-1]["reason"], "backend unavailable")
+	})
+
+	t.Run("unknown tool fails the step", func(t *testing.T) {
+		tool, err := builtin.NewPipelineTool(config.PipelineConfig{
+			Name:        "missing",
+			Description: "Missing",
+			Steps:       []config.PipelineStepConfig{{ID: "a", Tool: "does_not_exist"}},
+		}, fake.lookup)
+		require.NoError(t, err)
+
+		result, err := tool.ExecuteContext(context.Background(), nil)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, resultText(t, result), "not found")
+	})
+}
+
+func TestPipeline_Retries(t *testing.T) {
+	fake := newFakeTools()
+	var calls int32
+	fake.add(t, "flaky", nil, func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return nil, errors.New("temporary failure")
+		}
+		return entities.NewTextToolResult("recovered"), nil
+	})
+
+	tool, err := builtin.NewPipelineTool(config.PipelineConfig{
+		Name:        "retrying",
+		Description: "Retrying",
+		Steps:       []config.PipelineStepConfig{{ID: "call", Tool: "flaky", Retries: 2}},
+	}, fake.lookup)
+	require.NoError(t, err)
+
+	result, err := tool.ExecuteContext(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "recovered", resultText(t, result))
+	assert.Equal(t, 3, stepTraces(t, result)["call"].Attempts)
+}
+
+func TestPipeline_ReportsProgress(t *testing.T) {
+	fake := newFakeTools()
+	fake.add(t, "step_tool", nil, func(ctx context.Context, input map[string]interface{}) (*entities.ToolResult, error) {
+		entities.ReportProgress(ctx, entities.ToolProgress{Progress: 1, Total: 2, Message: "halfway"})
+		return entities.NewTextToolResult("ok"), nil
+	})
+
+	tool, err := builtin.NewPipelineTool(config.PipelineConfig{
+		Name:        "progress",
+		Description: "Progress",
+		Steps: []config.PipelineStepConfig{
+			{ID: "first", Tool: "step_tool"},
+			{ID: "second", Tool: "step_tool", DependsOn: []string{"first"}},
+		},
+	}, fake.lookup)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var updates []entities.ToolProgress
+	ctx := entities.WithProgressReporter(context.Background(), func(update entities.ToolProgress) {
+		mu.Lock()
+		updates = append(updates, update)
+		mu.Unlock()
+	})
+
+	_, err = tool.ExecuteContext(ctx, nil)
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, updates, 4)
+	for i := 1; i < len(updates); i++ {
+		assert.Greater(t, updates[i].Progress, updates[i-1].Progress)
+		assert.Equal(t, float64(2), updates[i].Total)
+	}
+	assert.Equal(t, "first: halfway", updates[0].Message)
+	assert.Equal(t, "step first (step_tool) ok", updates[1].Message)
+	assert.Equal(t, float64(2), updates[3].Progress)
+}
+
+func TestPipeline_TracesSteps(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	fake := newFakeTools()
+	fake.add(t, "ok_tool", nil, textTool("ok"))
+	fake.add(t, "bad_tool", nil, failingTool("boom"))
+
+	tool, err := builtin.NewPipelineTool(config.PipelineConfig{
+		Name:        "traced",
+		Description: "Traced",
+		Steps: []config.PipelineStepConfig{
+			{ID: "good", Tool: "ok_tool"},
+			{ID: "bad", Tool: "bad_tool", DependsOn: []string{"good"}, OnError: "continue"},
+		},
+	}, fake.lookup)
+	require.NoError(t, err)
+
+	_, err = tool.ExecuteContext(context.Background(), nil)
+	require.NoError(t, err)
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	require.Contains(t, spans, "pipeline traced")
+	require.Contains(t, spans, "pipeline.step good")
+	require.Contains(t, spans, "pipeline.step bad")
+
+	root := spans["pipeline traced"].SpanContext().SpanID()
+	assert.Equal(t, root, spans["pipeline.step good"].Parent().SpanID())
+	assert.Equal(t, "Error", spans["pipeline.step bad"].Status().Code.String())
+}
+
+func TestPipeline_NestingIsLimited(t *testing.T) {
+	tools := make(map[string]*entities.Tool)
+	lookup := func(ctx context.Context, name string) (*entities.Tool, error) {
+		return tools[name], nil
+	}
+	for name, next := range map[string]string{"ping_pipeline": "pong_pipeline", "pong_pipeline": "ping_pipeline"} {
+		tool, err := builtin.NewPipelineTool(config.PipelineConfig{
+			Name:        name,
+			Description: "Recursive",
+			Steps:       []config.PipelineStepConfig{{ID: "next", Tool: next}},
+		}, lookup)
+		require.NoError(t, err)
+		tools[name] = tool
+	}
+
+	result, err := tools["ping_pipeline"].ExecuteContext(context.Background(), nil)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "nested too deeply")
+}
+
+// stepAuditor keeps the names of the audited tool calls
+type stepAuditor struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (a *stepAuditor) Record(_ context.Context, execution *entities.ToolExecution) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls = append(a.calls, execution.ToolName+":"+string(execution.Status))
+}
+
+func TestPipeline_StepsRunThroughToolHandler(t *testing.T) {
+	ctx := context.Background()
+	sessionRepo := persistence.NewInMemorySessionRepository()
+	toolRepo := persistence.NewInMemoryToolRepository()
+	session := aggregates.NewSession()
+	require.NoError(t, sessionRepo.Save(ctx, session))
+
+	fake := newFakeTools()
+	fake.add(t, "fetch_metrics", nil, textTool("p99 412ms"))
+	fake.add(t, "delete_metrics", nil, textTool("deleted"))
+	fake.tools["delete_metrics"].Disable()
+	for _, tool := range fake.tools {
+		require.NoError(t, toolRepo.Register(ctx, tool))
+	}
+	for name, step := range map[string]string{"fetch_pipeline": "fetch_metrics", "delete_pipeline": "delete_metrics"} {
+		tool, err := builtin.NewPipelineTool(config.PipelineConfig{
+			Name:        name,
+			Description: "Runs " + step,
+			Steps:       []config.PipelineStepConfig{{ID: "step", Tool: step}},
+		}, builtin.RepositoryLookup(toolRepo))
+		require.NoError(t, err)
+		require.NoError(t, toolRepo.Register(ctx, tool))
+	}
+
+	auditor := &stepAuditor{}
+	toolHandler := handlers.NewToolHandler(sessionRepo, toolRepo, nopPublisher{})
+	toolHandler.SetAuditor(auditor, handlers.AuditOptions{})
+	execute := func(name string) *entities.ToolResult {
+		result, err := toolHandler.HandleExecuteTool(ctx, &commands.ExecuteToolCommand{SessionID: session.ID(), Name: name})
+		require.NoError(t, err)
+		return result
+	}
+
+	result := execute("fetch_pipeline")
+	require.False(t, result.IsError, "%v", result.Content)
+	assert.Equal(t, []string{"fetch_metrics:success", "fetch_pipeline:success"}, auditor.calls, "steps are audited like client calls")
+
+	result = execute("delete_pipeline")
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "disabled")
+	assert.Empty(t, fake.callsTo("delete_metrics"), "disabled tools do not run as steps")
+}
+
+func TestPipeline_InvalidDefinitions(t *testing.T) {
+	step := config.PipelineStepConfig{ID: "a", Tool: "echo"}
+	tests := []struct {
+		name string
+		def  config.PipelineConfig
+	}{
+		{"no steps", config.PipelineConfig{Name: "p", Description: "x"}},
+		{"bad argument template", config.PipelineConfig{Name: "p", Description: "x", Steps: []config.PipelineStepConfig{
+			{ID: "a", Tool: "echo", Arguments: map[string]interface{}{"message": "{{.input"}},
+		}}},
+		{"bad when template", config.PipelineConfig{Name: "p", Description: "x", Steps: []config.PipelineStepConfig{
+			{ID: "a", Tool: "echo", When: "{{if}}"},
+		}}},
+		{"bad output template", config.PipelineConfig{Name: "p", Description: "x", Output: "{{", Steps: []config.PipelineStepConfig{step}}},
+		{"cycle", config.PipelineConfig{Name: "p", Description: "x", Steps: []config.PipelineStepConfig{
+			{ID: "a", Tool: "echo", DependsOn: []string{"b"}},
+			{ID: "b", Tool: "echo", DependsOn: []string{"a"}},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := builtin.NewPipelineTool(tt.def, newFakeTools().lookup)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestToolRegistry_InvestigateTelemetryPipeline(t *testing.T) {
+	registry := builtin.NewToolRegistry(nil)
+	tool, ok := registry.GetTool("investigate_telemetry")
+	require.True(t, ok)
+	assert.Equal(t, "telemetry", tool.Category())
+	assert.Contains(t, tool.Tags(), "pipeline")
+	assert.ElementsMatch(t, []string{"organization_id", "context_type", "question"}, tool.InputSchema().Required)
+
+	// Without a context collector the first step fails and the pipeline reports it
+	result, err := tool.ExecuteContext(context.Background(), map[string]interface{}{
+		"organization_id": "org-1",
+		"context_type":    "metrics",
+		"question":        "Why is latency up?",
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "collect")
+}
@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/resources"
+	builtin "github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/tools"
+)
+
+func executeReadFile(t *testing.T, registry *builtin.ToolRegistry, input map[string]interface{}) *entities.ToolResult {
+	t.Helper()
+	tool, ok := registry.GetTool("read_file")
+	require.True(t, ok)
+	result, err := tool.Execute(input)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	return result
+}
+
+func writeTempFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+func TestReadFile_WholeFile(t *testing.T) {
+	registry := builtin.NewToolRegistry(nil)
+	path := writeTempFile(t, "hello.txt", []byte("Hello, World!"))
+
+	result := executeReadFile(t, registry, map[string]interface{}{"path": path})
+
+	assert.False(t, result.IsError)
+	require.Len(t, result.Content, 1)
+	assert.Equal(t, "Hello, World!", result.Content[0].Text)
+	assert.Equal(t, int64(13), result.Meta["total_size"])
+	assert.Equal(t, false, result.Meta["truncated"])
+}
+
+func TestReadFile_LineRange(t *testing.T) {
+	registry := builtin.NewToolRegistry(nil)
+	path := writeTempFile(t, "lines.txt", []byte("one\ntwo\nthree\nfour\nfive\n"))
+
+	result := executeReadFile(t, registry, map[string]interface{}{
+		"path":   path,
+		"offset": float64(2),
+		"limit":  float64(2),
+	})
+
+	assert.False(t, result.IsError)
+	assert.Equal(t, "two\nthree\n", result.Content[0].Text)
+	assert.Equal(t, 2, result.Meta["lines_read"])
+	assert.Equal(t, 4, result.Meta["next_offset"])
+
+	t.Run("offset beyond end of file", func(t *testing.T) {
+		result := executeReadFile(t, registry, map[string]interface{}{
+			"path":   path,
+			"offset": float64(10),
+		})
+		assert.True(t, result.IsError)
+	})
+}
+
+func TestReadFile_ByteRange(t *testing.T) {
+	registry := builtin.NewToolRegistry(nil)
+	path := writeTempFile(t, "bytes.txt", []byte("0123456789"))
+
+	result := executeReadFile(t, registry, map[string]interface{}{
+		"path":        path,
+		"byte_offset": float64(3),
+		"byte_length": float64(4),
+	})
+
+	assert.False(t, result.IsError)
+	assert.Equal(t, "3456", result.Content[0].Text)
+	assert.Equal(t, int64(4), result.Meta["bytes_read"])
+}
+
+func TestReadFile_MaxSizeGuard(t *testing.T) {
+	registry := builtin.NewToolRegistry(nil)
+	registry.SetResourceHandler(resources.NewResourceHandler(nil, 8))
+	path := writeTempFile(t, "big.log", []byte(strings.Repeat("line\n", 10)))
+
+	result := executeReadFile(t, registry, map[string]interface{}{"path": path})
+
+	assert.False(t, result.IsError)
+	assert.Equal(t, "line\nlin", result.Content[0].Text)
+	assert.Equal(t, true, result.Meta["truncated"])
+	assert.Equal(t, int64(50), result.Meta["total_size"])
+	require.Len(t, result.Content, 2)
+	assert.Contains(t, result.Content[1].Text, "truncated")
+}
+
+func TestReadFile_Encodings(t *testing.T) {
+	registry := builtin.NewToolRegistry(nil)
+
+	t.Run("latin1", func(t *testing.T) {
+		path := writeTempFile(t, "latin1.txt", []byte{'c', 'a', 'f', 0xe9})
+		result := executeReadFile(t, registry, map[string]interface{}{"path": path, "encoding": "latin1"})
+		assert.False(t, result.IsError)
+		assert.Equal(t, "café", result.Content[0].Text)
+	})
+
+	t.Run("utf-16 with BOM", func(t *testing.T) {
+		path := writeTempFile(t, "utf16.txt", []byte{0xff, 0xfe, 'h', 0, 'i', 0})
+		result := executeReadFile(t, registry, map[string]interface{}{"path": path, "encoding": "utf-16"})
+		assert.False(t, result.IsError)
+		assert.Equal(t, "hi", result.Content[0].Text)
+	})
+
+	t.Run("unsupported encoding", func(t *testing.T) {
+		path := writeTempFile(t, "plain.txt", []byte("text"))
+		result := executeReadFile(t, registry, map[string]interface{}{"path": path, "encoding": "ebcdic"})
+		assert.True(t, result.IsError)
+	})
+}
+
+func TestReadFile_Binary(t *testing.T) {
+	registry := builtin.NewToolRegistry(nil)
+
+	t.Run("image returned as image content", func(t *testing.T) {
+		data := []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a, 0, 0}
+		path := writeTempFile(t, "pixel.png", data)
+		result := executeReadFile(t, registry, map[string]interface{}{"path": path})
+		assert.False(t, result.IsError)
+		assert.Equal(t, "image", result.Content[0].Type)
+		assert.Equal(t, "image/png", result.Content[0].MimeType)
+		assert.Equal(t, base64.StdEncoding.EncodeToString(data), result.Content[0].Data)
+	})
+
+	t.Run("unknown binary returned as blob", func(t *testing.T) {
+		data := []byte{0x00, 0x01, 0x02, 0x03}
+		path := writeTempFile(t, "data.bin", data)
+		result := executeReadFile(t, registry, map[string]interface{}{"path": path})
+		assert.False(t, result.IsError)
+		assert.Equal(t, "resource", result.Content[0].Type)
+		assert.Equal(t, "application/octet-stream", result.Content[0].MimeType)
+		assert.Equal(t, base64.StdEncoding.EncodeToString(data), result.Content[0].Blob)
+	})
+}
@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/commands"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/aggregates"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/queue"
+	builtin "github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/tools"
+)
+
+type nopPublisher struct{}
+
+func (nopPublisher) Publish(ctx context.Context, event interface{}) error { return nil }
+
+// taskToolsFixture wires the task tools to a real tool handler and worker pool
+type taskToolsFixture struct {
+	registry    *builtin.ToolRegistry
+	toolHandler *handlers.ToolHandler
+	ctx         context.Context
+}
+
+func newTaskToolsFixture(t *testing.T) *taskToolsFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	sessionRepo := persistence.NewInMemorySessionRepository()
+	toolRepo := persistence.NewInMemoryToolRepository()
+	session := aggregates.NewSession()
+	require.NoError(t, sessionRepo.Save(ctx, session))
+
+	toolHandler := handlers.NewToolHandler(sessionRepo, toolRepo, nopPublisher{})
+	pool := queue.NewWorkerPool(1, 10)
+	dispatcher := queue.NewToolTaskDispatcher(pool)
+	taskHandler := handlers.NewTaskHandler(toolHandler, dispatcher, handlers.TaskOptions{})
+	dispatcher.Handle(taskHandler.RunTask)
+	pool.Start(ctx)
+	t.Cleanup(func() { _ = pool.Close() })
+
+	registry := builtin.NewToolRegistry(nil)
+	registry.SetTaskHandler(taskHandler)
+	for _, tool := range registry.GetTools() {
+		require.NoError(t, toolRepo.Register(ctx, tool))
+	}
+
+	return &taskToolsFixture{
+		registry:    registry,
+		toolHandler: toolHandler,
+		ctx:         handlers.WithSessionID(ctx, session.ID()),
+	}
+}
+
+// call runs a tool through the tool handler, as the server does
+func (f *taskToolsFixture) call(t *testing.T, name string, input map[string]interface{}) *entities.ToolResult {
+	t.Helper()
+	sessionID, _ := handlers.SessionIDFromContext(f.ctx)
+	result, err := f.toolHandler.HandleExecuteTool(context.Background(), &commands.ExecuteToolCommand{
+		SessionID: sessionID,
+		Name:      name,
+		Arguments: input,
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func taskMeta(t *testing.T, result *entities.ToolResult) map[string]interface{} {
+	t.Helper()
+	task, ok := result.Meta["task"].(map[string]interface{})
+	require.True(t, ok, "result has no task metadata: %v", result.Content)
+	return task
+}
+
+func TestTaskTools_Registered(t *testing.T) {
+	registry := builtin.NewToolRegistry(nil)
+	_, ok := registry.GetTool("start_background_task")
+	assert.False(t, ok, "task tools need a task handler")
+
+	f := newTaskToolsFixture(t)
+	for _, name := range []string{"start_background_task", "get_task_status", "get_task_result", "cancel_task"} {
+		_, ok := f.registry.GetTool(name)
+		assert.True(t, ok, name)
+	}
+}
+
+func TestTaskTools_StartAndFetchResult(t *testing.T) {
+	f := newTaskToolsFixture(t)
+
+	started := f.call(t, "start_background_task", map[string]interface{}{
+		"tool":      "echo",
+		"arguments": map[string]interface{}{"message": "hello from the background"},
+	})
+	require.False(t, started.IsError, started.Content)
+	taskID := taskMeta(t, started)["taskId"].(string)
+	assert.Contains(t, started.Content[0].Text, `"tool": "echo"`)
+
+	result := f.call(t, "get_task_result", map[string]interface{}{"task_id": taskID, "wait_seconds": float64(5)})
+	assert.False(t, result.IsError)
+	assert.Equal(t, "hello from the background", result.Content[0].Text)
+	assert.Equal(t, "completed", taskMeta(t, result)["status"])
+
+	status := f.call(t, "get_task_status", map[string]interface{}{"task_id": taskID})
+	assert.Equal(t, "completed", taskMeta(t, status)["status"])
+
+	cancelled := f.call(t, "cancel_task", map[string]interface{}{"task_id": taskID})
+	assert.True(t, cancelled.IsError)
+}
+
+func TestTaskTools_InputErrors(t *testing.T) {
+	f := newTaskToolsFixture(t)
+
+	tests := []struct {
+		name  string
+		tool  string
+		input map[string]interface{}
+	}{
+		{"missing tool", "start_background_task", map[string]interface{}{}},
+		{"unknown tool", "start_background_task", map[string]interface{}{"tool": "does_not_exist"}},
+		{"task tool recursion", "start_background_task", map[string]interface{}{"tool": "get_task_status"}},
+		{"missing task id", "get_task_status", map[string]interface{}{}},
+		{"unknown task", "get_task_result", map[string]interface{}{"task_id": "missing"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := f.call(t, tt.tool, tt.input)
+			assert.True(t, result.IsError)
+		})
+	}
+
+	// Without session context the tools refuse to run
+	tool, ok := f.registry.GetTool("get_task_status")
+	require.True(t, ok)
+	result, err := tool.ExecuteContext(context.Background(), map[string]interface{}{"task_id": "x"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/application/handlers"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/entities"
+	vo "github.com/telemetryflow/telemetryflow-go-mcp/internal/domain/valueobjects"
+	"github.com/telemetryflow/telemetryflow-go-mcp/internal/infrastructure/persistence"
+	builtin "github.com/telemetryflow/telemetryflow-go-mcp/internal/presentation/tools"
+)
+
+func getLLMUsage(t *testing.T, registry *builtin.ToolRegistry, input map[string]interface{}) *entities.ToolResult {
+	t.Helper()
+	tool, ok := registry.GetTool("get_llm_usage")
+	require.True(t, ok, "get_llm_usage is registered")
+	result, err := tool.ExecuteContext(context.Background(), input)
+	require.NoError(t, err)
+	return result
+}
+
+func TestGetLLMUsage(t *testing.T) {
+	repo := persistence.NewInMemoryLLMUsageRepository(100)
+	registry := builtin.NewToolRegistry(nil)
+	registry.SetUsageHandler(handlers.NewUsageHandler(repo))
+
+	ctx := context.Background()
+	old := entities.NewLLMUsage(vo.ModelGPT54, vo.ModelGPT54)
+	old.CalledAt = time.Now().Add(-48 * time.Hour)
+	old.CostUSD = 1
+	recent := entities.NewLLMUsage("analyst-default", vo.ModelClaudeOpus47)
+	recent.SessionID = "s1"
+	recent.InputTokens, recent.OutputTokens, recent.CacheReadTokens = 1000, 200, 5000
+	recent.CostUSD = 0.0125
+	recent.PricingVersion = vo.PricingVersion
+	recent.Latency = 1500 * time.Millisecond
+	require.NoError(t, repo.Save(ctx, old))
+	require.NoError(t, repo.Save(ctx, recent))
+
+	result := getLLMUsage(t, registry, map[string]interface{}{"since": "24h", "group_by": "model"})
+	require.False(t, result.IsError, resultText(t, result))
+
+	var body struct {
+		Total   map[string]interface{}   `json:"total"`
+		GroupBy string                   `json:"groupBy"`
+		Groups  []map[string]interface{} `json:"groups"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &body))
+	assert.Equal(t, float64(1), body.Total["requests"])
+	assert.Equal(t, 0.0125, body.Total["costUsd"])
+	assert.Equal(t, float64(5000), body.Total["cacheReadTokens"])
+	assert.InDelta(t, 5000.0/6000, body.Total["cacheHitRate"], 1e-9)
+	assert.Equal(t, float64(1500), body.Total["avgLatencyMs"])
+	assert.Equal(t, "model", body.GroupBy)
+	require.Len(t, body.Groups, 1)
+	assert.Equal(t, vo.ModelClaudeOpus47.String(), body.Groups[0]["model"])
+	assert.NotContains(t, body.Groups[0], "unpricedRequests")
+
+	// Without group_by only the total is reported
+	result = getLLMUsage(t, registry, map[string]interface{}{})
+	body.Groups = nil
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &body))
+	assert.Equal(t, float64(2), body.Total["requests"])
+	assert.Equal(t, float64(1), body.Total["unpricedRequests"])
+	assert.Nil(t, body.Groups)
+}
+
+func TestGetLLMUsage_InvalidInput(t *testing.T) {
+	registry := builtin.NewToolRegistry(nil)
+	registry.SetUsageHandler(handlers.NewUsageHandler(persistence.NewInMemoryLLMUsageRepository(1)))
+
+	for _, input := range []map[string]interface{}{
+		{"since": "yesterday"},
+		{"group_by": "tenant"},
+		{"since": "1h", "until": "2h"},
+	} {
+		result := getLLMUsage(t, registry, input)
+		assert.True(t, result.IsError, "input %v", input)
+	}
+}
+
+// fixedBudgetUsage reports the same usage for every budget
+type fixedBudgetUsage entities.BudgetUsage
+
+func (u fixedBudgetUsage) Usage(ctx context.Context, budget *entities.LLMBudget) (entities.BudgetUsage, error) {
+	return entities.BudgetUsage(u), nil
+}
+
+func TestGetLLMBudget(t *testing.T) {
+	repo := persistence.NewInMemoryLLMBudgetRepository()
+	ctx := context.Background()
+	for _, org := range []string{"acme", "globex"} {
+		b := entities.NewLLMBudget(org, entities.BudgetMonthly)
+		b.CostLimitUSD = 500
+		require.NoError(t, repo.Save(ctx, b))
+	}
+	registry := builtin.NewToolRegistry(nil)
+	registry.SetBudgetHandler(handlers.NewBudgetHandler(repo, fixedBudgetUsage{Tokens: 1000, CostUSD: 125}))
+
+	tool, ok := registry.GetTool("get_llm_budget")
+	require.True(t, ok, "get_llm_budget is registered")
+
+	var body struct {
+		Budgets []map[string]interface{} `json:"budgets"`
+	}
+	scoped := entities.WithUsageScope(ctx, entities.UsageScope{OrganizationID: "acme"})
+	result, err := tool.ExecuteContext(scoped, map[string]interface{}{})
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &body))
+	require.Len(t, body.Budgets, 1, "defaults to the organization of the session")
+	assert.Equal(t, "acme", body.Budgets[0]["organizationId"])
+	assert.Equal(t, "monthly", body.Budgets[0]["period"])
+	assert.Equal(t, float64(500), body.Budgets[0]["costLimitUsd"])
+	assert.Equal(t, 0.25, body.Budgets[0]["used"])
+	assert.NotContains(t, body.Budgets[0], "tokenLimit")
+
+	result, err = tool.ExecuteContext(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &body))
+	assert.Len(t, body.Budgets, 2, "lists every organization outside a session")
+}